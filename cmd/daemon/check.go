@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/config"
+	"github.com/genricoloni/synest/internal/executor"
+	"github.com/genricoloni/synest/internal/monitor"
+	"go.uber.org/zap"
+)
+
+// runCheckConfig implements `synest check-config`: it validates the config file,
+// the environment and the components they select, prints every problem found
+// and returns the process exit code
+func runCheckConfig(stdout, stderr io.Writer) int {
+	if err := checkConfig(); err != nil {
+		printProblems(stderr, err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "configuration OK (%s)\n", config.FilePath())
+	return 0
+}
+
+// checkConfig runs the validation shared by check-config and daemon startup.
+// Components are constructed without starting them, so a missing setter binary
+// or an unknown monitor backend is reported along with the config values.
+func checkConfig() error {
+	cfg, err := config.Check(zap.NewNop())
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	if _, err := config.Logging(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := config.DisabledSubsystems(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := monitor.NewMonitor(zap.NewNop(), cfg, clock.New()); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := executor.NewExecutor(zap.NewNop(), cfg); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// printProblems writes one configuration problem per line
+func printProblems(w io.Writer, err error) {
+	fmt.Fprintln(w, "invalid configuration:")
+	for _, line := range strings.Split(err.Error(), "\n") {
+		fmt.Fprintf(w, "  - %s\n", line)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"image/jpeg"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/genricoloni/synest/internal/config"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/processor"
+	"go.uber.org/zap"
+)
+
+// compareTileWidth is the width of each rendering on the contact sheet
+const compareTileWidth = 640
+
+// runCompare implements `synest compare`: it renders the same artwork in several
+// modes with the current settings and saves them side by side in one image.
+// Returns the process exit code.
+func runCompare(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	modes := fs.String("modes", "", "comma-separated modes to compare (default: all)")
+	artPath := fs.String("art", "", "artwork image to render (required)")
+	outPath := fs.String("out", "compare.jpg", "contact sheet to write")
+	width := fs.Int("width", 1920, "rendering width")
+	height := fs.Int("height", 1080, "rendering height")
+	artist := fs.String("artist", "", "artist seeding the generative mode")
+	title := fs.String("title", "", "title seeding the generative mode")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: synest compare --art cover.jpg [--modes blur,generative] [--out compare.jpg]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *artPath == "" || *width <= 0 || *height <= 0 {
+		fs.Usage()
+		return 2
+	}
+
+	art, err := os.ReadFile(*artPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to read artwork: %v\n", err)
+		return 1
+	}
+
+	res := &domain.ScreenResolution{Width: *width, Height: *height}
+	proc := processor.NewBlurProcessor(zap.NewNop(), res, config.NewAppConfig(zap.NewNop()))
+
+	selected := proc.Modes()
+	if *modes != "" {
+		selected = strings.Split(*modes, ",")
+	}
+
+	meta := domain.MediaMetadata{Artist: *artist, Title: *title}
+	tiles := make([]processor.Tile, 0, len(selected))
+	for _, mode := range selected {
+		mode = strings.TrimSpace(mode)
+		if !slices.Contains(proc.Modes(), mode) {
+			fmt.Fprintf(stderr, "unknown mode %q (available: %s)\n", mode, strings.Join(proc.Modes(), ", "))
+			return 2
+		}
+
+		img, err := proc.Render(context.Background(), art, meta, mode)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", mode, err)
+			return 1
+		}
+		tiles = append(tiles, processor.Tile{Label: mode, Image: img})
+		fmt.Fprintf(stdout, "rendered %s\n", mode)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, processor.ContactSheet(tiles, compareTileWidth), &jpeg.Options{Quality: 90}); err != nil {
+		fmt.Fprintf(stderr, "failed to encode contact sheet: %v\n", err)
+		return 1
+	}
+	if err := os.WriteFile(*outPath, buf.Bytes(), 0644); err != nil {
+		fmt.Fprintf(stderr, "failed to write contact sheet: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "contact sheet written to %s\n", *outPath)
+	return 0
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// daemonFlags are the command-line options of the daemon
+type daemonFlags struct {
+	mode        string
+	outputDir   string
+	logLevel    string
+	configPath  string
+	dryRun      bool
+	once        bool
+	checkConfig bool
+}
+
+// parseFlags parses the daemon command line. flag.ErrHelp is returned for --help.
+func parseFlags(args []string, stderr io.Writer) (daemonFlags, error) {
+	var f daemonFlags
+	fs := flag.NewFlagSet("synest", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&f.mode, "mode", "", "wallpaper mode: blur, generative, waveform or auto (overrides SYNEST_MODE)")
+	fs.StringVar(&f.outputDir, "output-dir", "", "directory for generated wallpapers (overrides SYNEST_OUTPUT_DIR)")
+	fs.StringVar(&f.logLevel, "log-level", "", "log level: debug, info, warn or error (overrides SYNEST_LOG_LEVEL)")
+	fs.StringVar(&f.configPath, "config", "", "config file to read (overrides SYNEST_CONFIG)")
+	fs.BoolVar(&f.dryRun, "dry-run", false, "generate wallpapers without setting them or running the on-applied hook")
+	fs.BoolVar(&f.once, "once", false, "exit after the first wallpaper is set, leaving it on screen")
+	fs.BoolVar(&f.checkConfig, "check-config", false, "validate the configuration and exit")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: synest [flags]")
+		fmt.Fprintln(stderr, "       synest simulate|compare|check-config [flags]")
+		fmt.Fprintln(stderr, "\nFlags take precedence over environment variables and the config file.")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return f, err
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return f, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if f.logLevel != "" {
+		if _, err := zapcore.ParseLevel(f.logLevel); err != nil {
+			return f, fmt.Errorf("invalid --log-level: %w", err)
+		}
+	}
+	return f, nil
+}
+
+// export hands the configuration flags to the usual resolution chain through
+// their environment variables, so they also survive config reloads
+func (f daemonFlags) export() error {
+	for name, value := range map[string]string{
+		"SYNEST_MODE":       f.mode,
+		"SYNEST_OUTPUT_DIR": f.outputDir,
+		"SYNEST_LOG_LEVEL":  f.logLevel,
+		"SYNEST_CONFIG":     f.configPath,
+	} {
+		if value == "" {
+			continue
+		}
+		if err := os.Setenv(name, value); err != nil {
+			return fmt.Errorf("failed to apply flag for %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// options returns the graph changes requested by --dry-run and --once
+func (f daemonFlags) options() fx.Option {
+	if !f.dryRun && !f.once {
+		return fx.Options()
+	}
+	return fx.Decorate(func(exec domain.Executor, logger *zap.Logger, shutdowner fx.Shutdowner) domain.Executor {
+		return &flagExecutor{
+			Executor:   exec,
+			logger:     logger,
+			shutdowner: shutdowner,
+			dryRun:     f.dryRun,
+			once:       f.once,
+		}
+	})
+}
+
+// flagExecutor wraps the executor for --dry-run, which never touches the desktop,
+// and --once, which shuts the daemon down after the first wallpaper and keeps it
+// on screen instead of restoring the original one
+type flagExecutor struct {
+	domain.Executor
+	logger     *zap.Logger
+	shutdowner fx.Shutdowner
+	dryRun     bool
+	once       bool
+	done       atomic.Bool // Set after the first wallpaper with --once
+}
+
+// SetWallpaper implements domain.Executor
+func (e *flagExecutor) SetWallpaper(ctx context.Context, imagePath string) error {
+	if e.done.Load() {
+		e.logger.Info("Keeping the generated wallpaper", zap.String("skipped", imagePath))
+		return nil
+	}
+
+	if e.dryRun {
+		e.logger.Info("Dry run, wallpaper not set", zap.String("path", imagePath))
+	} else if err := e.Executor.SetWallpaper(ctx, imagePath); err != nil {
+		return err
+	}
+
+	if e.once && e.done.CompareAndSwap(false, true) {
+		e.logger.Info("Wallpaper set once, shutting down")
+		if err := e.shutdowner.Shutdown(); err != nil {
+			e.logger.Error("Failed to shut down", zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// Ready implements domain.Readier. A dry run does not need the setter.
+func (e *flagExecutor) Ready(ctx context.Context) error {
+	if r, ok := e.Executor.(domain.Readier); ok && !e.dryRun {
+		return r.Ready(ctx)
+	}
+	return nil
+}
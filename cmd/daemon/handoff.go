@@ -0,0 +1,31 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+
+	"go.uber.org/zap"
+)
+
+// reexec replaces the process with the synest binary on disk, which may be a
+// newer version, keeping the PID so systemd keeps tracking the daemon. It only
+// returns by exiting, with an error status for systemd to restart the daemon.
+func reexec(logger *zap.Logger) {
+	// The path the daemon was started with, /proc/self/exe points to the
+	// replaced file after an upgrade
+	binary, err := exec.LookPath(os.Args[0])
+	if err != nil {
+		binary, err = os.Executable()
+	}
+	if err != nil {
+		logger.Error("Failed to find the synest binary", zap.Error(err))
+		os.Exit(1)
+	}
+
+	logger.Info("Restarting", zap.String("binary", binary))
+	_ = logger.Sync()
+	err = syscall.Exec(binary, os.Args, os.Environ())
+	logger.Error("Failed to restart", zap.String("binary", binary), zap.Error(err))
+	os.Exit(1)
+}
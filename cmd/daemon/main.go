@@ -2,99 +2,348 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/genricoloni/synest/internal/breaker"
+	"github.com/genricoloni/synest/internal/clock"
 	"github.com/genricoloni/synest/internal/config"
 	"github.com/genricoloni/synest/internal/domain"
 	"github.com/genricoloni/synest/internal/engine"
 	"github.com/genricoloni/synest/internal/executor"
 	"github.com/genricoloni/synest/internal/fetcher"
+	"github.com/genricoloni/synest/internal/history"
+	"github.com/genricoloni/synest/internal/hook"
+	"github.com/genricoloni/synest/internal/ipc"
+	"github.com/genricoloni/synest/internal/lifecycle"
+	"github.com/genricoloni/synest/internal/logfile"
+	"github.com/genricoloni/synest/internal/lograte"
 	"github.com/genricoloni/synest/internal/monitor"
+	"github.com/genricoloni/synest/internal/network"
+	"github.com/genricoloni/synest/internal/phone"
+	"github.com/genricoloni/synest/internal/power"
 	"github.com/genricoloni/synest/internal/processor"
+	"github.com/genricoloni/synest/internal/readiness"
+	"github.com/genricoloni/synest/internal/screensaver"
+	"github.com/genricoloni/synest/internal/status"
+	"github.com/genricoloni/synest/internal/theme"
+	"github.com/genricoloni/synest/internal/upload"
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxevent"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 // AppOptions definisce il grafo delle dipendenze dell'applicazione.
 // Esportandolo, possiamo testare che il grafo sia valido senza lanciare il main.
-var AppOptions = fx.Options(
-	// Logger configuration
-	fx.WithLogger(func(log *zap.Logger) fxevent.Logger {
-		return &fxevent.ZapLogger{Logger: log}
-	}),
-
-	// Provide dependencies (Qui aggiungerai monitor.NewMprisMonitor, etc.)
-	fx.Provide(
-		newLogger,
-		monitor.NewScreenResolution, // Detects screen resolution at startup
-		fx.Annotate(
-			config.NewAppConfig,
-			fx.As(new(domain.Config)),
-		),
-		fx.Annotate(
-			monitor.NewMprisMonitor,
-			fx.As(new(domain.Monitor)),
-		),
-		fx.Annotate(
-			fetcher.NewHTTPFetcher,
-			fx.As(new(domain.Fetcher)),
-		),
-		fx.Annotate(
-			processor.NewBlurProcessor,
-			fx.As(new(domain.ImageProcessor)),
-			fx.As(new(domain.Processor)),
-		),
-		fx.Annotate(
-			executor.NewExecutor,
-			fx.As(new(domain.Executor)),
+var AppOptions = appOptions(nil)
+
+// startTimeout bounds application startup, including the wait for external services
+const startTimeout = 2 * time.Minute
+
+// appOptions assembles the dependency graph, leaving out the disabled subsystems
+func appOptions(disabled map[string]bool) fx.Option {
+	return fx.Options(
+		// Logger configuration
+		fx.WithLogger(func(log *zap.Logger) fxevent.Logger {
+			return &fxevent.ZapLogger{Logger: log}
+		}),
+
+		// Provide dependencies
+		fx.Provide(
+			clock.New, // Real time, tests of timing logic use clock.Fake
+			newLogger,
+			monitor.NewScreenResolution, // Detects screen resolution at startup
+			fx.Annotate(
+				config.NewAppConfig,
+				fx.As(fx.Self()),
+				fx.As(new(domain.Config)),
+			),
+			monitor.NewMonitor,          // Backend selected by SYNEST_MONITOR
+			monitor.NewPlayerController, // Playback commands from synestctl
+			fx.Annotate(
+				breaker.NewSet, // Fails calls fast while an external provider keeps failing
+				fx.As(fx.Self()),
+				fx.As(new(domain.ProviderSource)),
+			),
+			fx.Annotate(
+				newFetcher,
+				fx.As(new(domain.Fetcher)),
+				fx.As(new(domain.BandwidthCounter)),
+			),
+			fx.Annotate(
+				lifecycle.NewManager, // Rebuilds the processor and setter after a config reload
+				fx.As(fx.Self()),
+				fx.As(new(domain.Reloader)),
+			),
+			fx.Annotate(
+				newProcessor,
+				fx.As(new(domain.ImageProcessor)),
+				fx.As(new(domain.Processor)),
+				fx.As(new(domain.PaletteSource)),
+			),
+			newExecutor, // Wallpaper setter, or saving only until one is found
+			fx.Annotate(
+				newReporter, // Status file, wrapped to upload, push and publish the theme of each wallpaper
+				fx.As(new(domain.Reporter)),
+				fx.As(new(ipc.ThemeSource)),
+			),
+			fx.Annotate(
+				power.NewDetector, // Battery saver, following SYNEST_BATTERY_SAVER
+				fx.As(new(domain.PowerSource)),
+			),
+			fx.Annotate(
+				network.NewDetector, // Metered mode, following SYNEST_METERED
+				fx.As(new(domain.MeteredSource)),
+			),
+			fx.Annotate(
+				engine.NewEngine, // Orchestrator
+				fx.As(fx.Self()),
+				fx.As(new(ipc.Controller)),
+			),
+			ipc.NewServer, // Control interface on the session bus
 		),
-		engine.NewEngine, // Orchestrator
-	),
 
-	// Lifecycle hooks
-	fx.Invoke(registerHooks),
-)
+		// Optional subsystems, replaced by no-ops when disabled
+		subsystems(disabled),
+
+		// Lifecycle hooks
+		fx.Invoke(registerHooks),
+		fx.StartTimeout(startTimeout),
+	)
+}
 
 func main() {
-	app := fx.New(AppOptions)
+	// Offline subcommands run without the daemon dependency graph
+	if len(os.Args) > 1 && os.Args[1] == "simulate" {
+		os.Exit(runSimulate(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "compare" {
+		os.Exit(runCompare(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "check-config" {
+		os.Exit(runCheckConfig(os.Stdout, os.Stderr))
+	}
+
+	flags, err := parseFlags(os.Args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if err := flags.export(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if flags.checkConfig {
+		os.Exit(runCheckConfig(os.Stdout, os.Stderr))
+	}
+
+	// Fail fast on configuration problems instead of running with defaults
+	if err := checkConfig(); err != nil {
+		printProblems(os.Stderr, err)
+		os.Exit(2)
+	}
+	disabled, _ := config.DisabledSubsystems() // Validated by checkConfig
+	if flags.dryRun {
+		// The hook would act on a wallpaper that was never applied
+		disabled[config.SubsystemHook] = true
+	}
+
+	var eng *engine.Engine
+	var logger *zap.Logger
+	app := fx.New(appOptions(disabled), flags.options(), fx.Populate(&eng, &logger))
 
 	// Handle graceful shutdown
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
+	// SIGHUP, sent by ExecReload of the systemd unit, replaces the daemon with
+	// the binary on disk, e.g. after an upgrade
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+
 	// Start the application
 	if err := app.Start(ctx); err != nil {
+		var running *ipc.AlreadyRunningError
+		if errors.As(err, &running) {
+			fmt.Fprintln(os.Stderr, running)
+			os.Exit(1)
+		}
 		panic(err)
 	}
 
-	// Wait for interrupt signal
-	<-ctx.Done()
+	// Wait for interrupt signal, or the shutdown requested by --once
+	restart := false
+	select {
+	case <-ctx.Done():
+	case <-app.Done():
+	case <-reload:
+		restart = true
+		if err := eng.HandOff(); err != nil {
+			logger.Error("Failed to hand off the wallpaper state, the original wallpaper shows until the restart", zap.Error(err))
+		}
+	}
 
 	// Stop the application gracefully
 	if err := app.Stop(context.Background()); err != nil {
 		panic(err)
 	}
+	if restart {
+		reexec(logger)
+	}
 }
 
-// newLogger creates a new zap logger instance
-func newLogger() (*zap.Logger, error) {
-	logger, err := zap.NewProduction()
+// newLogger creates the zap logger with the level, encoder and log file from the
+// configuration. Repeated warnings and errors are written once per minute, so
+// persistent failures do not flood the journal.
+func newLogger(clk clock.Clock) (*zap.Logger, error) {
+	settings, err := config.Logging()
 	if err != nil {
 		return nil, err
 	}
-	return logger, nil
+
+	encoderCfg := zap.NewProductionEncoderConfig()
+	var encoder zapcore.Encoder
+	if settings.Format == config.LogFormatConsole {
+		encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+		encoderCfg.EncodeLevel = zapcore.CapitalLevelEncoder
+		encoder = zapcore.NewConsoleEncoder(encoderCfg)
+	} else {
+		encoder = zapcore.NewJSONEncoder(encoderCfg)
+	}
+
+	sink := zapcore.Lock(os.Stderr)
+	if settings.File != "" {
+		file, err := logfile.Open(settings.File, settings.MaxSize, settings.MaxBackups)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		sink = zapcore.NewMultiWriteSyncer(sink, file)
+	}
+
+	// Same sampling as zap's production preset
+	core := zapcore.NewSamplerWithOptions(zapcore.NewCore(encoder, sink, settings.Level), time.Second, 100, 100)
+	return zap.New(lograte.NewCore(core, time.Minute, clk),
+		zap.AddCaller(),
+		zap.AddStacktrace(zapcore.ErrorLevel),
+		zap.ErrorOutput(zapcore.Lock(os.Stderr)),
+	), nil
 }
 
-// registerHooks sets up application lifecycle hooks
-func registerHooks(lc fx.Lifecycle, logger *zap.Logger, eng *engine.Engine, mon domain.Monitor) {
+// newReporter records pipeline outcomes in the status file, uploads and pushes
+// every applied wallpaper when enabled and publishes its palette for player themes
+func newReporter(lc fx.Lifecycle, logger *zap.Logger, cfg domain.Config, notifier domain.Notifier, providers domain.ProviderSource, palettes domain.PaletteSource, metered domain.MeteredSource, breakers *breaker.Set) *theme.Publisher {
+	reporter := history.NewRecorder(logger, cfg, status.NewReporter(logger, cfg, notifier, providers))
+	uploader := upload.NewUploader(logger, cfg, reporter, palettes, metered, breakers)
+	pusher := phone.NewPusher(logger, cfg, uploader, metered, breakers)
+	lc.Append(fx.Hook{OnStop: func(ctx context.Context) error {
+		return errors.Join(pusher.Stop(ctx), uploader.Stop(ctx))
+	}})
+	return theme.NewPublisher(logger, cfg, pusher, palettes)
+}
+
+// newFetcher downloads artwork, with a breaker per host, or reads it from disk
+// for file:// URLs, after the pre-fetch hooks. Downloads are cached on disk
+// unless SYNEST_ART_CACHE bypasses the cache.
+func newFetcher(logger *zap.Logger, cfg domain.Config, clk clock.Clock, breakers *breaker.Set, metered domain.MeteredSource) *hook.Fetcher {
+	dispatcher := fetcher.NewDispatcher(fetcher.NewHTTPFetcher(logger, cfg, metered), fetcher.NewFileFetcher(logger))
+	var f domain.Fetcher = breaker.NewFetcher(dispatcher, breakers)
+	if cfg.GetArtCache().Enabled {
+		// Hits skip the breaker, cached artwork stays available while its host is down
+		f = fetcher.NewCache(logger, cfg, clk, f)
+	}
+	return hook.NewFetcher(logger, cfg, f)
+}
+
+// newProcessor constructs the image processor, rebuilt when a reload changes the
+// settings it reads once
+func newProcessor(logger *zap.Logger, res *domain.ScreenResolution, cfg domain.Config, manager *lifecycle.Manager) *lifecycle.Processor {
+	build := func() (domain.Processor, error) {
+		return processor.NewBlurProcessor(logger, res, cfg), nil
+	}
+	initial, _ := build()
+	return lifecycle.NewProcessor(manager, initial, func() string { return processor.SettingsKey(cfg) }, build)
+}
+
+// newExecutor constructs the wallpaper setter. Without one the daemon still runs:
+// wallpapers are saved only, and the setter is probed for in the background.
+// A reload changing the setter settings constructs it again. Every wallpaper set
+// is mirrored to the e-ink display when one is configured.
+func newExecutor(lc fx.Lifecycle, logger *zap.Logger, cfg domain.Config, clk clock.Clock, reporter domain.Reporter, manager *lifecycle.Manager) domain.Executor {
+	build := func() (domain.Executor, error) {
+		return executor.NewExecutor(logger, cfg)
+	}
+	initial, err := build()
+	if err != nil {
+		logger.Warn("No wallpaper setter available, saving wallpapers only", zap.Error(err))
+		initial = executor.NewDegraded(logger, err, build, clk, reporter)
+	}
+
+	exec := lifecycle.NewExecutor(manager, initial, func() string { return executor.SettingsKey(cfg) }, build, reporter)
+	eink := executor.NewEInk(logger, cfg, exec)
 	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			exec.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			err := eink.Stop(ctx)
+			exec.Stop()
+			return err
+		},
+	})
+	return eink
+}
+
+// hookParams are the components started and stopped with the application
+type hookParams struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Logger    *zap.Logger
+	Config    domain.Config
+	Engine    *engine.Engine
+	Monitor   domain.Monitor
+	Executor  domain.Executor
+	Server    *ipc.Server
+	Watcher   *config.Watcher      `optional:"true"` // Nil when hot reload is disabled
+	Saver     *screensaver.Watcher `optional:"true"` // Nil when the screensaver subsystem is disabled
+	Screen    *domain.ScreenResolution
+	Clock     clock.Clock
+	Bandwidth domain.BandwidthCounter
+}
+
+// registerHooks sets up application lifecycle hooks
+func registerHooks(p hookParams) {
+	logger, eng, mon, server, watcher, saver := p.Logger, p.Engine, p.Monitor, p.Server, p.Watcher, p.Saver
+	p.Lifecycle.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
 			logger.Info("Starting Synest Daemon...")
 
-			// 1. Start the MPRIS monitor (event producer)
+			// 0. Wait for services that may start after synest at login
+			checks := append(readiness.Of("session bus", mon), readiness.Of("wallpaper setter", p.Executor)...)
+			if err := readiness.Wait(ctx, logger, p.Config.GetReadyTimeout(), checks...); err != nil {
+				return err
+			}
+
+			// 1. Take the bus name, a second daemon would fight over the wallpaper.
+			// Without a bus there is no other daemon to detect.
+			var running *ipc.AlreadyRunningError
+			if err := server.Claim(ctx); errors.As(err, &running) {
+				return err
+			} else if err != nil {
+				logger.Warn("Could not check for another running daemon", zap.Error(err))
+			}
+
+			// 2. Start the monitor (event producer)
 			// Runs in goroutine because monitor.Start is blocking
 			go func() {
 				if err := mon.Start(ctx); err != nil && ctx.Err() == nil {
@@ -102,16 +351,55 @@ func registerHooks(lc fx.Lifecycle, logger *zap.Logger, eng *engine.Engine, mon
 				}
 			}()
 
-			// 2. Start the Engine (event consumer and orchestrator)
+			// 3. Start the Engine (event consumer and orchestrator)
 			if err := eng.Start(ctx); err != nil {
 				return err
 			}
 
+			// Rendering at the default size until a display shows up, e.g. early at login
+			if p.Screen.Fallback {
+				go monitor.AwaitDisplay(ctx, logger, p.Config, p.Clock, eng.Resize)
+			}
+
+			// 4. Watch the config file, changes are applied without a restart
+			if watcher != nil {
+				if err := watcher.Start(ctx); err != nil {
+					logger.Warn("Config hot reload unavailable", zap.Error(err))
+				}
+			}
+
+			// 5. Suspend updates while the session is locked
+			if saver != nil {
+				if err := saver.Start(ctx, eng.SetLocked); err != nil {
+					logger.Warn("Screen saver detection unavailable", zap.Error(err))
+				}
+			}
+
+			// 6. Accept commands from synestctl, the daemon works without them
+			if err := server.Start(ctx); err != nil {
+				logger.Warn("Control interface unavailable", zap.Error(err))
+			}
+
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
 			logger.Info("Shutting down Synest Daemon...")
 
+			if err := server.Stop(); err != nil {
+				logger.Warn("Failed to stop control interface", zap.Error(err))
+			}
+
+			if watcher != nil {
+				if err := watcher.Stop(); err != nil {
+					logger.Warn("Failed to stop config watcher", zap.Error(err))
+				}
+			}
+			if saver != nil {
+				if err := saver.Stop(); err != nil {
+					logger.Warn("Failed to stop screen saver watcher", zap.Error(err))
+				}
+			}
+
 			// 1. Stop the engine and restore original wallpaper
 			if err := eng.Stop(ctx); err != nil {
 				logger.Error("Failed to stop engine", zap.Error(err))
@@ -123,6 +411,13 @@ func registerHooks(lc fx.Lifecycle, logger *zap.Logger, eng *engine.Engine, mon
 				logger.Error("Failed to stop monitor", zap.Error(err))
 				return err
 			}
+			if counter, ok := mon.(domain.EventCounter); ok {
+				stats := counter.EventStats()
+				logger.Info("Media events replaced while the engine was busy",
+					zap.Uint64("coalesced", stats.Coalesced),
+					zap.Uint64("dropped", stats.Dropped))
+			}
+			logger.Info("Artwork downloaded this session", zap.Uint64("bytes", p.Bandwidth.BytesFetched()))
 
 			return nil
 		},
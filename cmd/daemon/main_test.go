@@ -1,9 +1,21 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/config"
+	"github.com/genricoloni/synest/internal/domain"
 	"go.uber.org/fx"
+	"go.uber.org/zap"
 )
 
 // TestAppGraphValidity verifies that the dependency graph is resolvable.
@@ -23,9 +35,31 @@ func TestAppGraphValidity(t *testing.T) {
 	}
 }
 
+// TestAppGraphValidity_DisabledSubsystems verifies the graph still resolves
+// with every optional subsystem left out
+func TestAppGraphValidity_DisabledSubsystems(t *testing.T) {
+	disabled := make(map[string]bool)
+	for _, name := range config.Subsystems {
+		disabled[name] = true
+	}
+
+	if err := fx.ValidateApp(appOptions(disabled)); err != nil {
+		t.Errorf("Dependency graph is not valid: %v", err)
+	}
+}
+
+// TestAppGraphValidity_Flags verifies the graph resolves with the executor
+// wrapped for --dry-run and --once
+func TestAppGraphValidity_Flags(t *testing.T) {
+	flags := daemonFlags{dryRun: true, once: true}
+	if err := fx.ValidateApp(appOptions(nil), flags.options()); err != nil {
+		t.Errorf("Dependency graph is not valid: %v", err)
+	}
+}
+
 // TestNewLogger specifically verifies the logger configuration
 func TestNewLogger(t *testing.T) {
-	logger, err := newLogger()
+	logger, err := newLogger(clock.New())
 	if err != nil {
 		t.Fatalf("Failed to create logger: %v", err)
 	}
@@ -36,9 +70,38 @@ func TestNewLogger(t *testing.T) {
 	logger.Info("Test logger initialization")
 }
 
+// TestNewLogger_File verifies log lines also land in the configured log file
+func TestNewLogger_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "synest.log")
+	t.Setenv("SYNEST_CONFIG", filepath.Join(t.TempDir(), "missing.yaml"))
+	t.Setenv("SYNEST_LOG_FILE", path)
+	t.Setenv("SYNEST_LOG_FORMAT", "console")
+
+	logger, err := newLogger(clock.New())
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.Info("Written to the log file")
+	logger.Debug("Below the level")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Log file not written: %v", err)
+	}
+	if !strings.Contains(string(data), "INFO") || !strings.Contains(string(data), "Written to the log file") {
+		t.Errorf("Expected a console line in the log file, got %q", data)
+	}
+	if strings.Contains(string(data), "Below the level") {
+		t.Errorf("Expected debug lines to be dropped at the info level, got %q", data)
+	}
+}
+
 // TestEndToEndStartup (Optional) tries a real startup/stop in a controlled environment
 // We use fx.NopLogger to avoid cluttering test output
 func TestEndToEndStartup(t *testing.T) {
+	// The test environment has no session bus or display to wait for
+	t.Setenv("SYNEST_READY_TIMEOUT", "0")
+
 	app := fx.New(
 		AppOptions,
 		fx.NopLogger, // Silence Fx logs during tests
@@ -54,3 +117,167 @@ func TestEndToEndStartup(t *testing.T) {
 		t.Fatalf("App failed to stop: %v", err)
 	}
 }
+
+// TestRunCheckConfig verifies invalid values are reported instead of replaced by defaults
+func TestRunCheckConfig(t *testing.T) {
+	t.Setenv("SYNEST_CONFIG", t.TempDir()+"/config.yaml")
+	t.Setenv("SYNEST_ON_PAUSE", "blink")
+
+	var stdout, stderr bytes.Buffer
+	if code := runCheckConfig(&stdout, &stderr); code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), `SYNEST_ON_PAUSE="blink": must be keep, dim or restore`) {
+		t.Errorf("expected the invalid value in the output, got:\n%s", stderr.String())
+	}
+}
+
+// TestRunCompare renders a generated cover in two modes into a contact sheet
+func TestRunCompare(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SYNEST_CONFIG", filepath.Join(dir, "config.yaml"))
+
+	cover := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	for i := range cover.Pix {
+		cover.Pix[i] = uint8(i)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, cover); err != nil {
+		t.Fatal(err)
+	}
+	artPath := filepath.Join(dir, "cover.png")
+	if err := os.WriteFile(artPath, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outPath := filepath.Join(dir, "compare.jpg")
+	var stdout, stderr bytes.Buffer
+	args := []string{"--art", artPath, "--modes", "blur,generative", "--out", outPath, "--width", "320", "--height", "180"}
+	if code := runCompare(args, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if _, err := os.Stat(outPath); err != nil {
+		t.Errorf("contact sheet not written: %v", err)
+	}
+
+	if code := runCompare([]string{"--art", artPath, "--modes", "sepia"}, &stdout, &stderr); code != 2 {
+		t.Errorf("expected exit code 2 for an unknown mode, got %d", code)
+	}
+}
+
+// TestRunSimulate runs the simulate subcommand against the bundled example script,
+// with its own defaults whatever the daemon environment
+func TestRunSimulate(t *testing.T) {
+	t.Setenv("SYNEST_DEBOUNCE_BROWSING", "7s")
+	var stdout, stderr bytes.Buffer
+	code := runSimulate([]string{"--script", "../../examples/simulate.yaml", "--measure", "--min-interval", "0s"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	for _, want := range []string{"browsing 2s, min interval 0s, dedup true", "wallpapers generated:  2"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, stdout.String())
+		}
+	}
+
+	if code := runSimulate(nil, &stdout, &stderr); code != 2 {
+		t.Errorf("expected exit code 2 without --script, got %d", code)
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+		check         func(t *testing.T, f daemonFlags)
+	}{
+		{
+			name: "No Flags",
+			check: func(t *testing.T, f daemonFlags) {
+				if f != (daemonFlags{}) {
+					t.Errorf("expected no options, got %+v", f)
+				}
+			},
+		},
+		{
+			name: "All Flags",
+			args: []string{"--mode", "generative", "--output-dir", "/tmp/out", "--log-level", "debug",
+				"--config", "/tmp/c.yaml", "--dry-run", "--once"},
+			check: func(t *testing.T, f daemonFlags) {
+				want := daemonFlags{mode: "generative", outputDir: "/tmp/out", logLevel: "debug",
+					configPath: "/tmp/c.yaml", dryRun: true, once: true}
+				if f != want {
+					t.Errorf("expected %+v, got %+v", want, f)
+				}
+			},
+		},
+		{name: "Invalid Log Level", args: []string{"--log-level", "loud"}, expectedError: "invalid --log-level"},
+		{name: "Unknown Flag", args: []string{"--fast"}, expectedError: "flag provided but not defined"},
+		{name: "Positional Argument", args: []string{"start"}, expectedError: `unexpected argument "start"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := parseFlags(tt.args, io.Discard)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.check(t, f)
+		})
+	}
+}
+
+// TestFlagExecutor verifies that --once stops after the first wallpaper and keeps
+// it on screen, and that --dry-run never reaches the setter
+func TestFlagExecutor(t *testing.T) {
+	inner := &recordingExecutor{}
+	shutdowner := &countingShutdowner{}
+	exec := &flagExecutor{Executor: inner, logger: zap.NewNop(), shutdowner: shutdowner, once: true}
+
+	for _, path := range []string{"/tmp/wall.jpg", "/tmp/original.jpg"} {
+		if err := exec.SetWallpaper(t.Context(), path); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(inner.set) != 1 || inner.set[0] != "/tmp/wall.jpg" {
+		t.Errorf("expected only the first wallpaper to be set, got %v", inner.set)
+	}
+	if shutdowner.calls != 1 {
+		t.Errorf("expected one shutdown, got %d", shutdowner.calls)
+	}
+
+	dry := &flagExecutor{Executor: inner, logger: zap.NewNop(), shutdowner: shutdowner, dryRun: true}
+	if err := dry.SetWallpaper(t.Context(), "/tmp/dry.jpg"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(inner.set) != 1 {
+		t.Errorf("dry run reached the setter: %v", inner.set)
+	}
+}
+
+type recordingExecutor struct {
+	domain.Executor
+	set []string
+}
+
+func (e *recordingExecutor) SetWallpaper(ctx context.Context, imagePath string) error {
+	e.set = append(e.set, imagePath)
+	return nil
+}
+
+type countingShutdowner struct {
+	calls int
+}
+
+func (s *countingShutdowner) Shutdown(...fx.ShutdownOption) error {
+	s.calls++
+	return nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/genricoloni/synest/internal/config"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/engine"
+)
+
+// runSimulate implements `synest simulate`: it replays a script of media events
+// through the engine scheduler in virtual time and reports the wallpaper updates
+// that would have happened. Returns the process exit code.
+func runSimulate(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	scriptPath := fs.String("script", "", "YAML file with the scripted events (required)")
+	measure := fs.Bool("measure", false, "print only the summary instead of every decision")
+	// Defaults are the daemon's, the simulation does not read its environment or config file
+	debounce := fs.Duration("debounce", config.DefaultDebounce, "quiet period after an event before the wallpaper is updated")
+	strategy := fs.String("strategy", domain.DebounceTrailing, "debounce strategy: trailing, leading or token_bucket")
+	burst := fs.Int("burst", config.DefaultBurst, "updates in a row allowed by the token bucket strategy")
+	browsing := fs.Duration("browsing", config.DefaultBrowsing, "debounce used while skipping through tracks (0 disables)")
+	minInterval := fs.Duration("min-interval", 0, "minimum interval between updates")
+	dedup := fs.Bool("dedup", false, "skip updates for the track already on screen")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: synest simulate --script events.yaml [--measure] [--debounce 1s] [--strategy leading] [--burst 3] [--browsing 2s] [--min-interval 30s] [--dedup]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *scriptPath == "" {
+		fs.Usage()
+		return 2
+	}
+
+	f, err := os.Open(*scriptPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to open script: %v\n", err)
+		return 1
+	}
+	defer f.Close()
+
+	script, err := engine.LoadScript(f)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", *scriptPath, err)
+		return 1
+	}
+
+	// Precedence: command-line flags > script config > flag defaults
+	policy := script.Config.Apply(engine.Policy{
+		Debounce:    *debounce,
+		Strategy:    *strategy,
+		Burst:       *burst,
+		Browsing:    *browsing,
+		MinInterval: *minInterval,
+		Dedup:       *dedup,
+	})
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "debounce":
+			policy.Debounce = *debounce
+		case "strategy":
+			policy.Strategy = *strategy
+		case "burst":
+			policy.Burst = *burst
+		case "browsing":
+			policy.Browsing = *browsing
+		case "min-interval":
+			policy.MinInterval = *minInterval
+		case "dedup":
+			policy.Dedup = *dedup
+		}
+	})
+
+	switch policy.Strategy {
+	case domain.DebounceTrailing, domain.DebounceLeading, domain.DebounceTokenBucket:
+	default:
+		fmt.Fprintf(stderr, "unknown debounce strategy %q\n", policy.Strategy)
+		return 2
+	}
+	if policy.Burst < 1 {
+		fmt.Fprintln(stderr, "burst must be at least 1")
+		return 2
+	}
+
+	report := engine.Simulate(script.Events, policy)
+	if !*measure {
+		for _, step := range report.Steps {
+			fmt.Fprintf(stdout, "%10s  %-11s  %s - %s\n",
+				step.At.Round(time.Millisecond), step.Decision, step.Meta.Artist, step.Meta.Title)
+		}
+		fmt.Fprintln(stdout)
+	}
+	printReport(stdout, report)
+	return 0
+}
+
+// printReport writes the simulation summary
+func printReport(w io.Writer, report engine.SimulationReport) {
+	fmt.Fprintf(w, "Policy: debounce %s (%s), browsing %s, min interval %s, dedup %t\n",
+		report.Policy.Debounce, strategyName(report.Policy), report.Policy.Browsing, report.Policy.MinInterval, report.Policy.Dedup)
+	fmt.Fprintf(w, "Simulated %s with %d events\n", report.Duration.Round(time.Millisecond), report.Events)
+	fmt.Fprintf(w, "  wallpapers generated:  %d\n", report.Generated)
+	fmt.Fprintf(w, "  superseded events:     %d\n", report.Superseded)
+	fmt.Fprintf(w, "  skipped (duplicate):   %d\n", report.Duplicates)
+	fmt.Fprintf(w, "  skipped (not playing): %d\n", report.NotPlaying)
+}
+
+// strategyName describes the debounce strategy, with the burst size of the token bucket
+func strategyName(policy engine.Policy) string {
+	if policy.Strategy == domain.DebounceTokenBucket {
+		return fmt.Sprintf("%s, burst %d", policy.Strategy, policy.Burst)
+	}
+	return policy.Strategy
+}
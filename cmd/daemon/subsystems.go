@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+
+	"github.com/genricoloni/synest/internal/breaker"
+	"github.com/genricoloni/synest/internal/config"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/enrichment"
+	"github.com/genricoloni/synest/internal/hook"
+	"github.com/genricoloni/synest/internal/notify"
+	"github.com/genricoloni/synest/internal/screensaver"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// subsystems provides the optional subsystems. A disabled one is never constructed:
+// its interface is satisfied by a no-op and its goroutines and connections are skipped.
+func subsystems(disabled map[string]bool) fx.Option {
+	var providers []any
+
+	if disabled[config.SubsystemNotifications] {
+		providers = append(providers, fx.Annotate(func() nopNotifier { return nopNotifier{} }, fx.As(new(domain.Notifier))))
+	} else {
+		providers = append(providers, fx.Annotate(notify.NewDesktopNotifier, fx.As(new(domain.Notifier))))
+	}
+
+	if disabled[config.SubsystemEnrichment] {
+		providers = append(providers, fx.Annotate(func() nopEnricher { return nopEnricher{} }, fx.As(new(domain.Enricher))))
+	} else {
+		providers = append(providers, fx.Annotate(newEnricher, fx.As(new(domain.Enricher))))
+	}
+
+	if disabled[config.SubsystemHook] {
+		providers = append(providers, fx.Annotate(func() nopHook { return nopHook{} }, fx.As(new(domain.AppliedHook))))
+	} else {
+		providers = append(providers, fx.Annotate(hook.NewRunner, fx.As(new(domain.AppliedHook))))
+	}
+
+	// Without a watcher the config is only read at startup
+	if !disabled[config.SubsystemHotReload] {
+		providers = append(providers, config.NewWatcher)
+	}
+
+	// Without a screen saver watcher wallpapers are rendered while the session is locked
+	if !disabled[config.SubsystemScreensaver] {
+		providers = append(providers, screensaver.NewWatcher)
+	}
+
+	return fx.Provide(providers...)
+}
+
+// newEnricher looks up audio features on Spotify, behind a breaker
+func newEnricher(logger *zap.Logger, cfg domain.Config, breakers *breaker.Set, metered domain.MeteredSource) *breaker.Enricher {
+	return breaker.NewEnricher(enrichment.NewSpotifyEnricher(logger, cfg, metered), breakers, "spotify audio features")
+}
+
+// nopNotifier drops notifications when they are disabled
+type nopNotifier struct{}
+
+func (nopNotifier) Notify(ctx context.Context, summary, body, image string) error {
+	return nil
+}
+
+// nopEnricher never finds audio features when enrichment is disabled
+type nopEnricher struct{}
+
+func (nopEnricher) AudioFeatures(ctx context.Context, meta domain.MediaMetadata) (*domain.AudioFeatures, error) {
+	return nil, nil
+}
+
+// nopHook ignores applied wallpapers when the hook is disabled
+type nopHook struct{}
+
+func (nopHook) Applied(ctx context.Context, wallpaperPath string, meta domain.MediaMetadata) error {
+	return nil
+}
@@ -0,0 +1,357 @@
+// Command synestctl manages a synest installation: exporting and importing
+// its configuration and state, inspecting the daemon status and history, and
+// controlling the running daemon.
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/genricoloni/synest/internal/bundle"
+	"github.com/genricoloni/synest/internal/config"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/history"
+	"github.com/genricoloni/synest/internal/ipc"
+	"github.com/genricoloni/synest/internal/paths"
+	"github.com/genricoloni/synest/internal/status"
+	"go.uber.org/zap"
+)
+
+// controlTimeout bounds calls to the running daemon
+const controlTimeout = 10 * time.Second
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run dispatches a subcommand and returns the process exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+
+	var err error
+	switch args[0] {
+	case "export":
+		err = runExport(args[1:], stdout, stderr)
+	case "import":
+		err = runImport(args[1:], stdin, stdout, stderr)
+	case "status":
+		err = runStatus(args[1:], stdout, stderr)
+	case "mode":
+		err = runMode(args[1:], stdout, stderr)
+	case "private":
+		err = runPrivate(args[1:], stdout, stderr)
+	case "history":
+		err = runHistory(args[1:], stdout, stderr)
+	case domain.PlayerPlayPause, domain.PlayerNext, domain.PlayerPrevious:
+		err = runPlayer(args[0], args[1:], stderr)
+	case "help", "-h", "--help":
+		usage(stdout)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n", args[0])
+		usage(stderr)
+		return 2
+	}
+
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "synestctl %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, `Usage: synestctl <command> [flags]
+
+Commands:
+  export   Write a configuration and state bundle (tar.zst) to stdout
+  import   Restore a bundle from a file or stdin
+  status   Show the last wallpaper update and the last error
+  mode     Switch the wallpaper mode of the running daemon
+  private  Switch private mode (off, on or freeze) until the next restart
+  history  List the kept wallpapers, or apply one again with: history apply <id>
+
+  play-pause, next, previous
+           Control the player whose track is on screen`)
+}
+
+// runExport writes the bundle to stdout
+func runExport(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	redact := flags.Bool("redact", false, "mask secrets (for attaching the bundle to bug reports)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	files := map[string][]byte{
+		bundle.ConfigFile: bundle.ConfigEnv(os.Environ(), *redact),
+	}
+	// Most settings live in the config file, which is optional
+	data, err := os.ReadFile(config.FilePath())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read the config file: %w", err)
+	}
+	if err == nil {
+		if files[bundle.ConfigYAMLFile], err = bundle.ConfigYAML(data, *redact); err != nil {
+			return err
+		}
+	}
+
+	// Buffer the archive so a failure never leaves a truncated bundle on stdout
+	var buf bytes.Buffer
+	if err := bundle.Write(&buf, files, *redact); err != nil {
+		return err
+	}
+	_, err = buf.WriteTo(stdout)
+	return err
+}
+
+// runImport extracts a bundle into the configuration directory
+func runImport(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	dir := flags.String("dir", defaultConfigDir(), "directory to restore the bundle into")
+	force := flags.Bool("force", false, "overwrite existing files")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	in := stdin
+	if flags.NArg() > 0 && flags.Arg(0) != "-" {
+		f, err := os.Open(flags.Arg(0))
+		if err != nil {
+			return fmt.Errorf("failed to open bundle: %w", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	b, err := bundle.Read(in)
+	if err != nil {
+		return err
+	}
+	if b.Manifest.Redacted {
+		fmt.Fprintln(stderr, "warning: bundle was exported with --redact, secrets must be set again")
+	}
+
+	// Check everything first so an import never stops halfway
+	for name := range b.Files {
+		target := filepath.Join(*dir, filepath.FromSlash(name))
+		if _, err := os.Stat(target); err == nil && !*force {
+			return fmt.Errorf("%s already exists (use --force to overwrite)", target)
+		} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to check %s: %w", target, err)
+		}
+	}
+
+	for _, name := range b.Manifest.Files {
+		data, ok := b.Files[name]
+		if !ok {
+			return fmt.Errorf("bundle is missing %s listed in its manifest", name)
+		}
+		target := filepath.Join(*dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+		}
+		if err := os.WriteFile(target, data, 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", target, err)
+		}
+		fmt.Fprintf(stdout, "restored %s\n", target)
+	}
+	return nil
+}
+
+// runStatus prints the status file written by the daemon
+func runStatus(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("status", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	file := flags.String("file", "", "status file (default: status.json in the state directory)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	path := *file
+	if path == "" {
+		path = status.Path(config.NewAppConfig(zap.NewNop()).GetStateDir())
+	}
+
+	s, err := status.Load(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no status at %s, has the daemon run yet?", path)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "updated:      %s\n", s.UpdatedAt.Format(time.RFC3339))
+	if s.Setter != nil {
+		fmt.Fprintf(stdout, "setter:       %s (%s)\n", s.Setter.Name, s.Setter.Reason)
+	}
+	if s.LastSuccess != nil {
+		fmt.Fprintf(stdout, "last success: %s\n", s.LastSuccess.Format(time.RFC3339))
+		fmt.Fprintf(stdout, "wallpaper:    %s\n", s.Wallpaper)
+	}
+	if s.Track != nil {
+		fmt.Fprintf(stdout, "track:        %s - %s\n", s.Track.Artist, s.Track.Title)
+		if s.Track.Player != "" {
+			fmt.Fprintf(stdout, "player:       %s\n", s.Track.Player)
+		}
+		if s.Track.URL != "" {
+			fmt.Fprintf(stdout, "source:       %s (%s)\n", s.Track.URL, s.Track.Source)
+		}
+	}
+	if s.LastError != nil {
+		fmt.Fprintf(stdout, "last error:   %s (%s) %s\n",
+			s.LastError.Time.Format(time.RFC3339), s.LastError.Step, s.LastError.Message)
+	}
+	fmt.Fprintf(stdout, "failures:     %d in a row\n", s.ConsecutiveFailures)
+	// Only providers whose calls are paused are worth a line
+	for _, p := range s.Providers {
+		if p.State == domain.BreakerClosed {
+			continue
+		}
+		line := fmt.Sprintf("provider:     %s %s after %d failures", p.Name, p.State, p.Failures)
+		if p.RetryAt != nil && p.State == domain.BreakerOpen {
+			line += ", next try " + p.RetryAt.Format(time.RFC3339)
+		}
+		fmt.Fprintln(stdout, line)
+	}
+	return nil
+}
+
+// runMode asks the running daemon to switch its wallpaper mode
+func runMode(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("mode", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: synestctl mode <blur|generative|waveform|auto>")
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("expected exactly one mode")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), controlTimeout)
+	defer cancel()
+	if err := ipc.SetMode(ctx, flags.Arg(0)); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "mode set to %s\n", flags.Arg(0))
+	return nil
+}
+
+// runPrivate asks the running daemon to switch private mode
+func runPrivate(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("private", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: synestctl private <off|on|freeze>")
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("expected exactly one private mode")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), controlTimeout)
+	defer cancel()
+	if err := ipc.SetPrivate(ctx, flags.Arg(0)); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "private mode set to %s\n", flags.Arg(0))
+	return nil
+}
+
+// runPlayer asks the running daemon to forward a playback command to the player
+// driving the wallpaper
+func runPlayer(command string, args []string, stderr io.Writer) error {
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: synestctl %s\n", command)
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return errors.New("expected no arguments")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), controlTimeout)
+	defer cancel()
+	return ipc.ControlPlayer(ctx, command)
+}
+
+// runHistory lists the wallpaper history, newest first, or asks the running
+// daemon to apply a wallpaper from it again
+func runHistory(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("history", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	dir := flags.String("dir", "", "history directory (default: history in the output directory)")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: synestctl history [--dir DIR] [apply <id>]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() > 0 {
+		if flags.Arg(0) != "apply" || flags.NArg() != 2 {
+			flags.Usage()
+			return errors.New("expected apply <id>")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), controlTimeout)
+		defer cancel()
+		if err := ipc.ApplyHistory(ctx, flags.Arg(1)); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "applied %s\n", flags.Arg(1))
+		return nil
+	}
+
+	path := *dir
+	if path == "" {
+		path = history.Dir(config.NewAppConfig(zap.NewNop()).GetOutputDir())
+	}
+	entries, err := history.Load(path)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("no wallpapers in %s, is the history enabled (SYNEST_HISTORY_MAX_COUNT)?", path)
+	}
+	for _, entry := range entries {
+		track := "(private)"
+		if entry.Artist != "" || entry.Title != "" {
+			track = entry.Artist + " - " + entry.Title
+		}
+		fmt.Fprintf(stdout, "%s  %s  %s\n", entry.ID, entry.CreatedAt.Local().Format(time.DateTime), track)
+	}
+	return nil
+}
+
+// defaultConfigDir returns the per-user synest configuration directory
+func defaultConfigDir() string {
+	return paths.ConfigDir()
+}
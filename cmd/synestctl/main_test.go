@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/genricoloni/synest/internal/bundle"
+	"github.com/genricoloni/synest/internal/history"
+)
+
+// TestExportImport verifies a bundle exported by synestctl can be restored,
+// with the environment and the config file
+func TestExportImport(t *testing.T) {
+	t.Setenv("SYNEST_MODE", "generative")
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("spotify:\n  refresh_token: AQDr3fr3sh\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SYNEST_CONFIG", configPath)
+
+	var bundleData, stderr bytes.Buffer
+	if code := run([]string{"export"}, nil, &bundleData, &stderr); code != 0 {
+		t.Fatalf("export failed with code %d: %s", code, stderr.String())
+	}
+
+	dir := t.TempDir()
+	var stdout bytes.Buffer
+	if code := run([]string{"import", "--dir", dir}, bytes.NewReader(bundleData.Bytes()), &stdout, &stderr); code != 0 {
+		t.Fatalf("import failed with code %d: %s", code, stderr.String())
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "config.env"))
+	if err != nil {
+		t.Fatalf("config not restored: %v", err)
+	}
+	if !strings.Contains(string(data), `SYNEST_MODE="generative"`) {
+		t.Errorf("unexpected config:\n%s", data)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "config.yaml")); err != nil || !strings.Contains(string(data), "refresh_token: AQDr3fr3sh") {
+		t.Errorf("config file not restored (%v):\n%s", err, data)
+	}
+
+	// A second import must not overwrite without --force
+	stderr.Reset()
+	if code := run([]string{"import", "--dir", dir}, bytes.NewReader(bundleData.Bytes()), &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit code 1 when overwriting, got %d", code)
+	}
+	if code := run([]string{"import", "--dir", dir, "--force"}, bytes.NewReader(bundleData.Bytes()), &stdout, &stderr); code != 0 {
+		t.Errorf("expected --force import to succeed, got %d: %s", code, stderr.String())
+	}
+}
+
+// TestExport_Redact verifies secrets of the config file stay out of redacted bundles
+func TestExport_Redact(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("spotify:\n  refresh_token: AQDr3fr3sh\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SYNEST_CONFIG", configPath)
+
+	var bundleData, stderr bytes.Buffer
+	if code := run([]string{"export", "--redact"}, nil, &bundleData, &stderr); code != 0 {
+		t.Fatalf("export failed with code %d: %s", code, stderr.String())
+	}
+	b, err := bundle.Read(&bundleData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := string(b.Files["config.yaml"]); strings.Contains(data, "AQDr3fr3sh") || !strings.Contains(data, "refresh_token: REDACTED") {
+		t.Errorf("refresh token not masked:\n%s", data)
+	}
+}
+
+func TestRun_UnknownCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"frobnicate"}, nil, &stdout, &stderr); code != 2 {
+		t.Errorf("expected exit code 2, got %d", code)
+	}
+}
+
+func TestRun_ModeRequiresName(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"mode"}, nil, &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit code 1, got %d", code)
+	}
+}
+
+func TestRun_PrivateRequiresMode(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"private"}, nil, &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit code 1, got %d", code)
+	}
+}
+
+func TestRun_PlayerTakesNoArguments(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"next", "spotify"}, nil, &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit code 1, got %d", code)
+	}
+}
+
+func TestRun_History(t *testing.T) {
+	dir := t.TempDir()
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"history", "--dir", dir}, nil, &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit code 1 for an empty history, got %d", code)
+	}
+
+	index := `[{"id": "20240101-120000.000", "path": "/tmp/a.jpg", "artist": "Artist", "title": "Song", "size": 1, "created_at": "2024-01-01T12:00:00Z"}]`
+	if err := os.WriteFile(filepath.Join(dir, history.IndexFile), []byte(index), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stdout.Reset()
+	if code := run([]string{"history", "--dir", dir}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if out := stdout.String(); !strings.Contains(out, "20240101-120000.000") || !strings.Contains(out, "Artist - Song") {
+		t.Errorf("unexpected listing:\n%s", out)
+	}
+
+	if code := run([]string{"history", "apply"}, nil, &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit code 1 without an ID, got %d", code)
+	}
+}
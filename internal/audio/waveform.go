@@ -0,0 +1,223 @@
+package audio
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hajimehoshi/go-mp3"
+)
+
+const (
+	readChunkSize     = 32 * 1024
+	bytesPerSample    = 2 // Decoders below always produce 16-bit little-endian PCM
+	mp3Channels       = 2 // go-mp3 always outputs interleaved stereo
+	wavFormatPCM      = 1
+	wavFormatExtended = 0xFFFE
+)
+
+var (
+	// ErrNotLocal is returned for media URLs that do not point to a local file (e.g. streams)
+	ErrNotLocal = errors.New("media is not a local file")
+	// ErrUnsupportedFormat is returned for audio files that cannot be decoded
+	ErrUnsupportedFormat = errors.New("unsupported audio format")
+)
+
+// pcmStream is a decoded stream of interleaved 16-bit little-endian samples
+type pcmStream struct {
+	reader   io.Reader
+	channels int
+	frames   int64 // Total number of frames (one sample per channel)
+}
+
+// LocalPath resolves a xesam:url value to a local filesystem path.
+// Remote URLs and streams return ErrNotLocal.
+func LocalPath(mediaURL string) (string, error) {
+	if mediaURL == "" {
+		return "", ErrNotLocal
+	}
+
+	u, err := url.Parse(mediaURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid media url: %w", err)
+	}
+	if u.Scheme != "file" || (u.Host != "" && u.Host != "localhost") {
+		return "", ErrNotLocal
+	}
+
+	// url.Parse already percent-decodes the path
+	return u.Path, nil
+}
+
+// Peaks decodes the audio file at path and returns n peak amplitudes normalized to 0..1.
+// Only uncompressed WAV (16-bit PCM) and MP3 files are supported.
+func Peaks(ctx context.Context, path string, n int) ([]float64, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid peak count: %d", n)
+	}
+
+	f, err := os.Open(path) // #nosec G304 -- path comes from the player's own metadata
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audio file: %w", err)
+	}
+	defer f.Close()
+
+	var stream *pcmStream
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".wav", ".wave":
+		stream, err = newWAVStream(f)
+	case ".mp3":
+		stream, err = newMP3Stream(f)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return computePeaks(ctx, stream, n)
+}
+
+// computePeaks reduces the stream to n buckets holding the maximum absolute amplitude
+func computePeaks(ctx context.Context, stream *pcmStream, n int) ([]float64, error) {
+	if stream.frames <= 0 || stream.channels <= 0 {
+		return nil, fmt.Errorf("audio stream is empty")
+	}
+
+	peaks := make([]float64, n)
+	frameSize := stream.channels * bytesPerSample
+	buf := make([]byte, readChunkSize-readChunkSize%frameSize)
+
+	var frame int64
+	var loudest float64
+	for frame < stream.frames {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		read, err := io.ReadFull(stream.reader, buf)
+		for off := 0; off+frameSize <= read; off += frameSize {
+			var amplitude float64
+			for ch := 0; ch < stream.channels; ch++ {
+				sample := int16(binary.LittleEndian.Uint16(buf[off+ch*bytesPerSample:]))
+				amplitude = max(amplitude, abs(float64(sample)/32768))
+			}
+
+			bucket := int(frame * int64(n) / stream.frames)
+			if bucket >= n {
+				bucket = n - 1 // Decoders may produce a few frames beyond the announced length
+			}
+			peaks[bucket] = max(peaks[bucket], amplitude)
+			loudest = max(loudest, amplitude)
+			frame++
+		}
+
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode audio: %w", err)
+		}
+	}
+
+	// Normalize so quiet masters still produce a visible waveform
+	if loudest > 0 {
+		for i := range peaks {
+			peaks[i] /= loudest
+		}
+	}
+
+	return peaks, nil
+}
+
+// newMP3Stream decodes an MP3 file using a pure Go decoder
+func newMP3Stream(f *os.File) (*pcmStream, error) {
+	dec, err := mp3.NewDecoder(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedFormat, err)
+	}
+
+	return &pcmStream{
+		reader:   dec,
+		channels: mp3Channels,
+		frames:   dec.Length() / (mp3Channels * bytesPerSample),
+	}, nil
+}
+
+// newWAVStream parses the RIFF header of a WAV file and positions the reader at the sample data
+func newWAVStream(f *os.File) (*pcmStream, error) {
+	r := bufio.NewReader(f)
+
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, fmt.Errorf("%w: truncated wav header", ErrUnsupportedFormat)
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("%w: not a RIFF/WAVE file", ErrUnsupportedFormat)
+	}
+
+	var channels, bitsPerSample uint16
+	var haveFormat bool
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return nil, fmt.Errorf("%w: missing data chunk", ErrUnsupportedFormat)
+		}
+		id := string(chunk[0:4])
+		size := int64(binary.LittleEndian.Uint32(chunk[4:8]))
+
+		switch id {
+		case "fmt ":
+			var format [16]byte
+			if size < int64(len(format)) {
+				return nil, fmt.Errorf("%w: short fmt chunk", ErrUnsupportedFormat)
+			}
+			if _, err := io.ReadFull(r, format[:]); err != nil {
+				return nil, fmt.Errorf("%w: truncated fmt chunk", ErrUnsupportedFormat)
+			}
+			audioFormat := binary.LittleEndian.Uint16(format[0:2])
+			channels = binary.LittleEndian.Uint16(format[2:4])
+			bitsPerSample = binary.LittleEndian.Uint16(format[14:16])
+			if audioFormat != wavFormatPCM && audioFormat != wavFormatExtended {
+				return nil, fmt.Errorf("%w: wav encoding %d", ErrUnsupportedFormat, audioFormat)
+			}
+			if _, err := r.Discard(int(size - int64(len(format)) + size%2)); err != nil {
+				return nil, fmt.Errorf("%w: truncated fmt chunk", ErrUnsupportedFormat)
+			}
+			haveFormat = true
+
+		case "data":
+			if !haveFormat {
+				return nil, fmt.Errorf("%w: data chunk before fmt chunk", ErrUnsupportedFormat)
+			}
+			if bitsPerSample != 8*bytesPerSample || channels == 0 {
+				return nil, fmt.Errorf("%w: %d-bit wav with %d channels", ErrUnsupportedFormat, bitsPerSample, channels)
+			}
+			return &pcmStream{
+				reader:   io.LimitReader(r, size),
+				channels: int(channels),
+				frames:   size / int64(int(channels)*bytesPerSample),
+			}, nil
+
+		default:
+			// Skip unknown chunks (LIST, fact, ...), which are padded to an even size
+			if _, err := r.Discard(int(size + size%2)); err != nil {
+				return nil, fmt.Errorf("%w: truncated %q chunk", ErrUnsupportedFormat, id)
+			}
+		}
+	}
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
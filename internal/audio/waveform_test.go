@@ -0,0 +1,176 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalPath(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		expected    string
+		expectedErr error
+	}{
+		{
+			name:     "Local File",
+			url:      "file:///home/user/Music/track.mp3",
+			expected: "/home/user/Music/track.mp3",
+		},
+		{
+			name:     "Percent Encoded",
+			url:      "file:///home/user/My%20Music/caf%C3%A9.wav",
+			expected: "/home/user/My Music/café.wav",
+		},
+		{
+			name:     "Localhost Host",
+			url:      "file://localhost/tmp/track.wav",
+			expected: "/tmp/track.wav",
+		},
+		{
+			name:        "HTTP Stream",
+			url:         "https://stream.example.com/radio.mp3",
+			expectedErr: ErrNotLocal,
+		},
+		{
+			name:        "Spotify URI",
+			url:         "spotify:track:4uLU6hMCjMI75M1A2tKUQC",
+			expectedErr: ErrNotLocal,
+		},
+		{
+			name:        "Empty",
+			url:         "",
+			expectedErr: ErrNotLocal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LocalPath(tt.url)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPeaks_WAV(t *testing.T) {
+	// One second of stereo audio whose amplitude ramps up linearly
+	path := writeTestWAV(t, 8000, 2, func(i, total int) float64 {
+		return float64(i) / float64(total)
+	})
+
+	peaks, err := Peaks(context.Background(), path, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(peaks) != 10 {
+		t.Fatalf("expected 10 peaks, got %d", len(peaks))
+	}
+
+	for i := 1; i < len(peaks); i++ {
+		if peaks[i] < peaks[i-1] {
+			t.Errorf("expected increasing peaks, got %v", peaks)
+			break
+		}
+	}
+	if math.Abs(peaks[len(peaks)-1]-1) > 0.01 {
+		t.Errorf("expected loudest bucket normalized to 1, got %f", peaks[len(peaks)-1])
+	}
+}
+
+func TestPeaks_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	notWAV := filepath.Join(dir, "fake.wav")
+	if err := os.WriteFile(notWAV, []byte("definitely not riff"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	flac := filepath.Join(dir, "track.flac")
+	if err := os.WriteFile(flac, []byte("fLaC"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "Missing File", path: filepath.Join(dir, "missing.wav")},
+		{name: "Invalid WAV Header", path: notWAV},
+		{name: "Unsupported Extension", path: flac},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Peaks(context.Background(), tt.path, 10); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestPeaks_ContextCancelled(t *testing.T) {
+	path := writeTestWAV(t, 8000, 1, func(int, int) float64 { return 0.5 })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := Peaks(ctx, path, 10); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+// writeTestWAV writes a 16-bit PCM WAV file of a 440Hz tone whose amplitude is
+// given by envelope(frame, totalFrames)
+func writeTestWAV(t *testing.T, frames, channels int, envelope func(i, total int) float64) string {
+	t.Helper()
+
+	data := new(bytes.Buffer)
+	for i := 0; i < frames; i++ {
+		sample := int16(envelope(i, frames) * 32767 * math.Sin(2*math.Pi*440*float64(i)/8000))
+		for ch := 0; ch < channels; ch++ {
+			_ = binary.Write(data, binary.LittleEndian, sample)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("RIFF")
+	_ = binary.Write(buf, binary.LittleEndian, uint32(36+data.Len()))
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	_ = binary.Write(buf, binary.LittleEndian, uint32(16))
+	_ = binary.Write(buf, binary.LittleEndian, uint16(1))               // PCM
+	_ = binary.Write(buf, binary.LittleEndian, uint16(channels))        // Channels
+	_ = binary.Write(buf, binary.LittleEndian, uint32(8000))            // Sample rate
+	_ = binary.Write(buf, binary.LittleEndian, uint32(8000*channels*2)) // Byte rate
+	_ = binary.Write(buf, binary.LittleEndian, uint16(channels*2))      // Block align
+	_ = binary.Write(buf, binary.LittleEndian, uint16(16))              // Bits per sample
+	buf.WriteString("LIST")                                             // Unknown chunk that must be skipped
+	_ = binary.Write(buf, binary.LittleEndian, uint32(3))               // Odd size, padded to 4
+	buf.Write([]byte{1, 2, 3, 0})
+	buf.WriteString("data")
+	_ = binary.Write(buf, binary.LittleEndian, uint32(data.Len()))
+	buf.Write(data.Bytes())
+
+	path := filepath.Join(t.TempDir(), "tone.wav")
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		t.Fatalf("failed to write test wav: %v", err)
+	}
+	return path
+}
@@ -0,0 +1,161 @@
+// Package breaker wraps calls to external providers, such as artwork hosts and
+// enrichment APIs, in circuit breakers: once a provider keeps failing, calls to
+// it fail at once instead of adding its timeout to every track change, and a
+// single trial call is let through now and then to see if it recovered.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	failureThreshold = 3                // Failed calls in a row opening the breaker
+	openFor          = 30 * time.Second // Wait before the first trial call
+	maxOpenFor       = 10 * time.Minute // Longest wait, it doubles after every failed trial
+)
+
+// ErrOpen is returned without calling a provider whose breaker is open
+var ErrOpen = errors.New("provider unavailable")
+
+// Breaker guards the calls to one provider. It is safe for concurrent use.
+type Breaker struct {
+	name   string
+	logger *zap.Logger
+	clock  clock.Clock
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	wait     time.Duration // Wait of the next opening
+	retryAt  time.Time
+	trial    bool // The trial call of a half-open breaker is in flight
+}
+
+func newBreaker(name string, logger *zap.Logger, clk clock.Clock) *Breaker {
+	return &Breaker{name: name, logger: logger, clock: clk, state: domain.BreakerClosed, wait: openFor}
+}
+
+// Call runs fn unless the breaker is open, and records its outcome. Failures
+// of calls whose ctx was cancelled are not held against the provider.
+func (b *Breaker) Call(ctx context.Context, fn func() error) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+	err := fn()
+	b.record(ctx, err)
+	return err
+}
+
+// allow lets a call through when the breaker is closed, or as the trial call
+// once an open breaker waited long enough
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case domain.BreakerOpen:
+		if wait := clock.Until(b.clock, b.retryAt); wait > 0 {
+			return fmt.Errorf("%s: %w, next try in %s", b.name, ErrOpen, wait.Round(time.Second))
+		}
+		b.state = domain.BreakerHalfOpen
+		fallthrough
+	case domain.BreakerHalfOpen:
+		if b.trial {
+			return fmt.Errorf("%s: %w, a trial call is in flight", b.name, ErrOpen)
+		}
+		b.trial = true
+	}
+	return nil
+}
+
+// record updates the breaker with the outcome of a call
+func (b *Breaker) record(ctx context.Context, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	trial := b.trial
+	b.trial = false
+
+	if err != nil && ctx.Err() != nil {
+		return // Cancelled by the caller, a later call tries again
+	}
+	if err == nil {
+		if b.state != domain.BreakerClosed {
+			b.logger.Info("Provider is available again", zap.String("provider", b.name))
+		}
+		b.state, b.failures, b.wait, b.retryAt = domain.BreakerClosed, 0, openFor, time.Time{}
+		return
+	}
+
+	b.failures++
+	if !trial && b.failures < failureThreshold {
+		return
+	}
+	b.state = domain.BreakerOpen
+	b.retryAt = b.clock.Now().Add(b.wait)
+	b.logger.Warn("Provider keeps failing, calls to it are paused",
+		zap.String("provider", b.name),
+		zap.Int("failures", b.failures),
+		zap.Duration("retryIn", b.wait),
+		zap.Error(err))
+	b.wait = min(2*b.wait, maxOpenFor)
+}
+
+// snapshot returns the state of the breaker
+func (b *Breaker) snapshot() domain.ProviderState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return domain.ProviderState{Name: b.name, State: b.state, Failures: b.failures, RetryAt: b.retryAt}
+}
+
+// Set holds the breakers of every provider, created on their first call. It
+// implements domain.ProviderSource.
+type Set struct {
+	logger *zap.Logger
+	clock  clock.Clock
+
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+// NewSet creates a set without breakers
+func NewSet(logger *zap.Logger, clk clock.Clock) *Set {
+	return &Set{logger: logger, clock: clk, breakers: make(map[string]*Breaker)}
+}
+
+// Get returns the breaker of the named provider
+func (s *Set) Get(name string) *Breaker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.breakers[name]
+	if !ok {
+		b = newBreaker(name, s.logger, s.clock)
+		s.breakers[name] = b
+	}
+	return b
+}
+
+// Providers implements domain.ProviderSource
+func (s *Set) Providers() []domain.ProviderState {
+	s.mu.Lock()
+	breakers := make([]*Breaker, 0, len(s.breakers))
+	for _, b := range s.breakers {
+		breakers = append(breakers, b)
+	}
+	s.mu.Unlock()
+
+	states := make([]domain.ProviderState, len(breakers))
+	for i, b := range breakers {
+		states[i] = b.snapshot()
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states
+}
@@ -0,0 +1,104 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// TestBreaker verifies a failing provider is skipped once the breaker opens,
+// tried again after the wait, and the wait doubles when the trial fails
+func TestBreaker(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	set := NewSet(zap.NewNop(), clk)
+	b := set.Get("spotify")
+
+	calls := 0
+	down := errors.New("connection refused")
+	call := func(result error) error {
+		return b.Call(context.Background(), func() error {
+			calls++
+			return result
+		})
+	}
+
+	for range failureThreshold {
+		if err := call(down); !errors.Is(err, down) {
+			t.Fatalf("expected the provider error, got %v", err)
+		}
+	}
+	if err := call(nil); !errors.Is(err, ErrOpen) {
+		t.Fatalf("expected the breaker to be open, got %v", err)
+	}
+	if calls != failureThreshold {
+		t.Errorf("expected %d calls to reach the provider, got %d", failureThreshold, calls)
+	}
+
+	// The trial fails, the next one waits twice as long
+	clk.Advance(openFor)
+	if err := call(down); !errors.Is(err, down) {
+		t.Fatalf("expected the trial call to reach the provider, got %v", err)
+	}
+	clk.Advance(openFor)
+	if err := call(nil); !errors.Is(err, ErrOpen) {
+		t.Fatalf("expected the breaker to stay open, got %v", err)
+	}
+
+	clk.Advance(openFor)
+	if err := call(nil); err != nil {
+		t.Fatalf("expected the trial call to succeed, got %v", err)
+	}
+	states := set.Providers()
+	if len(states) != 1 || states[0].State != domain.BreakerClosed || states[0].Failures != 0 {
+		t.Errorf("expected a closed breaker after the recovery, got %+v", states)
+	}
+}
+
+// TestBreaker_Cancelled verifies calls cancelled by the caller do not count
+// as provider failures
+func TestBreaker_Cancelled(t *testing.T) {
+	b := NewSet(zap.NewNop(), clock.NewFake(time.Now())).Get("artwork i.scdn.co")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for range failureThreshold + 1 {
+		err := b.Call(ctx, func() error { return ctx.Err() })
+		if errors.Is(err, ErrOpen) {
+			t.Fatal("expected cancelled calls to keep the breaker closed")
+		}
+	}
+}
+
+// fakeFetcher fails for the artwork of down.example
+type fakeFetcher struct {
+	calls int
+}
+
+func (f *fakeFetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
+	f.calls++
+	if url == "https://down.example/art.jpg" {
+		return nil, errors.New("unexpected status code: 503")
+	}
+	return []byte("image"), nil
+}
+
+// TestFetcher verifies breakers are kept per artwork host
+func TestFetcher(t *testing.T) {
+	inner := &fakeFetcher{}
+	f := NewFetcher(inner, NewSet(zap.NewNop(), clock.NewFake(time.Now())))
+
+	for range failureThreshold + 2 {
+		_, _ = f.Fetch(context.Background(), "https://down.example/art.jpg")
+	}
+	if inner.calls != failureThreshold {
+		t.Errorf("expected %d downloads from the failing host, got %d", failureThreshold, inner.calls)
+	}
+	if _, err := f.Fetch(context.Background(), "https://up.example/art.jpg"); err != nil {
+		t.Errorf("expected another host to be fetched, got %v", err)
+	}
+}
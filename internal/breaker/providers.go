@@ -0,0 +1,67 @@
+package breaker
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+// Fetcher is a domain.Fetcher with a breaker per artwork host, so one host
+// being down does not slow down tracks whose artwork comes from another
+type Fetcher struct {
+	inner    domain.Fetcher
+	breakers *Set
+}
+
+// NewFetcher wraps inner with the breakers of set
+func NewFetcher(inner domain.Fetcher, set *Set) *Fetcher {
+	return &Fetcher{inner: inner, breakers: set}
+}
+
+// Fetch implements domain.Fetcher. URLs without a host, such as local files,
+// are fetched without a breaker.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return f.inner.Fetch(ctx, rawURL)
+	}
+
+	var data []byte
+	err = f.breakers.Get("artwork "+u.Host).Call(ctx, func() error {
+		var err error
+		data, err = f.inner.Fetch(ctx, rawURL)
+		return err
+	})
+	return data, err
+}
+
+// BytesFetched implements domain.BandwidthCounter when the wrapped fetcher does
+func (f *Fetcher) BytesFetched() uint64 {
+	if counter, ok := f.inner.(domain.BandwidthCounter); ok {
+		return counter.BytesFetched()
+	}
+	return 0
+}
+
+// Enricher is a domain.Enricher guarded by a breaker
+type Enricher struct {
+	inner   domain.Enricher
+	breaker *Breaker
+}
+
+// NewEnricher wraps inner with the breaker of the provider name in set
+func NewEnricher(inner domain.Enricher, set *Set, name string) *Enricher {
+	return &Enricher{inner: inner, breaker: set.Get(name)}
+}
+
+// AudioFeatures implements domain.Enricher
+func (e *Enricher) AudioFeatures(ctx context.Context, meta domain.MediaMetadata) (*domain.AudioFeatures, error) {
+	var features *domain.AudioFeatures
+	err := e.breaker.Call(ctx, func() error {
+		var err error
+		features, err = e.inner.AudioFeatures(ctx, meta)
+		return err
+	})
+	return features, err
+}
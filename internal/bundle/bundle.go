@@ -0,0 +1,266 @@
+// Package bundle reads and writes synest state bundles: a zstd-compressed tar
+// archive with a manifest, used to migrate between machines and attach to bug reports.
+package bundle
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// FormatVersion is incremented on incompatible layout changes
+	FormatVersion = 1
+
+	// ConfigFile holds the SYNEST_* configuration as an environment file
+	ConfigFile = "config.env"
+	// ConfigYAMLFile is the config file, restored next to ConfigFile
+	ConfigYAMLFile = "config.yaml"
+
+	manifestFile = "manifest.json"
+	maxFileSize  = 64 * 1024 * 1024 // Per entry, guards against corrupt or hostile archives
+	redacted     = "REDACTED"
+)
+
+// secretKeys are masked when exporting with redaction (e.g. for bug reports)
+var secretKeys = map[string]bool{
+	"SYNEST_SPOTIFY_CLIENT_SECRET": true,
+	"SYNEST_SPOTIFY_REFRESH_TOKEN": true,
+	"SYNEST_UPLOAD_SECRET":         true,
+	"SYNEST_PHONE_TOKEN":           true,
+	"SYNEST_MPD_PASSWORD":          true,
+}
+
+// secretFields are the config file fields masked with redaction, by path
+var secretFields = [][]string{
+	{"spotify", "client_secret"},
+	{"spotify", "refresh_token"},
+	{"upload", "secret"},
+	{"phone", "token"},
+	{"monitor", "mpd", "password"},
+}
+
+// Manifest describes the bundle contents
+type Manifest struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Redacted  bool      `json:"redacted"`
+	Files     []string  `json:"files"`
+}
+
+// Bundle is a decoded state bundle
+type Bundle struct {
+	Manifest Manifest
+	Files    map[string][]byte
+}
+
+// Write encodes files into a bundle. File names are slash-separated relative paths.
+func Write(w io.Writer, files map[string][]byte, redactedSecrets bool) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if !validName(name) {
+			return fmt.Errorf("invalid file name in bundle: %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	manifest, err := json.MarshalIndent(Manifest{
+		Version:   FormatVersion,
+		CreatedAt: time.Now().UTC(),
+		Redacted:  redactedSecrets,
+		Files:     names,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	zw, err := zstd.NewWriter(w)
+	if err != nil {
+		return fmt.Errorf("failed to create zstd writer: %w", err)
+	}
+	tw := tar.NewWriter(zw)
+
+	// Manifest first, so readers can reject unknown versions early
+	if err := writeEntry(tw, manifestFile, manifest); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := writeEntry(tw, name, files[name]); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish compression: %w", err)
+	}
+	return nil
+}
+
+// Read decodes a bundle, validating the manifest and entry names
+func Read(r io.Reader) (*Bundle, error) {
+	zr, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd reader: %w", err)
+	}
+	defer zr.Close()
+
+	b := &Bundle{Files: make(map[string][]byte)}
+	tr := tar.NewReader(zr)
+	first := true
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		isManifest := first && hdr.Name == manifestFile
+		if hdr.Typeflag != tar.TypeReg || (!isManifest && !validName(hdr.Name)) {
+			return nil, fmt.Errorf("unexpected entry in bundle: %q", hdr.Name)
+		}
+		if hdr.Size > maxFileSize {
+			return nil, fmt.Errorf("entry %s exceeds %d bytes", hdr.Name, maxFileSize)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", hdr.Name, err)
+		}
+
+		if first {
+			if !isManifest {
+				return nil, fmt.Errorf("bundle does not start with a manifest")
+			}
+			if err := json.Unmarshal(data, &b.Manifest); err != nil {
+				return nil, fmt.Errorf("failed to decode manifest: %w", err)
+			}
+			if b.Manifest.Version != FormatVersion {
+				return nil, fmt.Errorf("unsupported bundle version %d (expected %d)", b.Manifest.Version, FormatVersion)
+			}
+			first = false
+			continue
+		}
+		b.Files[hdr.Name] = data
+	}
+
+	if first {
+		return nil, fmt.Errorf("bundle is empty")
+	}
+	return b, nil
+}
+
+// ConfigEnv renders the SYNEST_* variables of environ ("KEY=value" entries) as an
+// environment file, sorted by key. Secrets are masked when redact is set.
+func ConfigEnv(environ []string, redact bool) []byte {
+	var lines []string
+	for _, entry := range environ {
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok || !strings.HasPrefix(key, "SYNEST_") {
+			continue
+		}
+		if redact && secretKeys[key] && value != "" {
+			value = redacted
+		}
+		lines = append(lines, key+"="+strconv.Quote(value))
+	}
+	sort.Strings(lines)
+
+	var sb strings.Builder
+	sb.WriteString("# synest configuration, load with `set -a; . config.env` or systemd EnvironmentFile=\n")
+	for _, line := range lines {
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+	}
+	return []byte(sb.String())
+}
+
+// ConfigYAML returns the config file for the bundle. Secrets are masked when
+// redact is set, keeping the comments and the order of the file.
+func ConfigYAML(data []byte, redact bool) ([]byte, error) {
+	if !redact {
+		return data, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return data, nil // Empty or only comments
+	}
+	for _, path := range secretFields {
+		if value := lookup(doc.Content[0], path); value != nil && value.Kind == yaml.ScalarNode && value.Value != "" {
+			value.Value, value.Style, value.Tag = redacted, 0, "!!str"
+		}
+	}
+
+	var buf strings.Builder
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to encode config file: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode config file: %w", err)
+	}
+	return []byte(buf.String()), nil
+}
+
+// lookup returns the value at path in a YAML mapping, nil when it is not set
+func lookup(node *yaml.Node, path []string) *yaml.Node {
+	for _, key := range path {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				next = node.Content[i+1]
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+func writeEntry(tw *tar.Writer, name string, data []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0600, // State may contain credentials
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("failed to write %s header: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// validName accepts clean relative paths that cannot escape the import directory
+func validName(name string) bool {
+	return name != "" &&
+		name != manifestFile &&
+		!strings.HasPrefix(name, "/") &&
+		path.Clean(name) == name &&
+		name != ".." && !strings.HasPrefix(name, "../")
+}
@@ -0,0 +1,196 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestWriteRead_RoundTrip(t *testing.T) {
+	files := map[string][]byte{
+		ConfigFile:        []byte("SYNEST_MODE=\"blur\"\n"),
+		"state/notes.txt": []byte("hello"),
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, files, true); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	b, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+
+	if b.Manifest.Version != FormatVersion || !b.Manifest.Redacted {
+		t.Errorf("unexpected manifest: %+v", b.Manifest)
+	}
+	if strings.Join(b.Manifest.Files, ",") != "config.env,state/notes.txt" {
+		t.Errorf("unexpected manifest files: %v", b.Manifest.Files)
+	}
+	for name, data := range files {
+		if !bytes.Equal(b.Files[name], data) {
+			t.Errorf("%s: expected %q, got %q", name, data, b.Files[name])
+		}
+	}
+}
+
+func TestWrite_InvalidName(t *testing.T) {
+	for _, name := range []string{"../escape", "/etc/passwd", "a/../b", "manifest.json", ""} {
+		if err := Write(&bytes.Buffer{}, map[string][]byte{name: nil}, false); err == nil {
+			t.Errorf("expected error for file name %q", name)
+		}
+	}
+}
+
+func TestRead_Invalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		entries       map[string]string // Written in sorted order by rawBundle
+		expectedError string
+	}{
+		{
+			name:          "Missing Manifest",
+			entries:       map[string]string{"config.env": "x"},
+			expectedError: "does not start with a manifest",
+		},
+		{
+			name:          "Unsupported Version",
+			entries:       map[string]string{"manifest.json": `{"version": 99}`},
+			expectedError: "unsupported bundle version 99",
+		},
+		{
+			name:          "Path Traversal",
+			entries:       map[string]string{"manifest.json": `{"version": 1}`, "z/../../evil": "x"},
+			expectedError: "unexpected entry",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(bytes.NewReader(rawBundle(t, tt.entries)))
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Fatalf("expected error containing '%s', got %v", tt.expectedError, err)
+			}
+		})
+	}
+
+	if _, err := Read(strings.NewReader("not a bundle")); err == nil {
+		t.Error("expected error for garbage input")
+	}
+}
+
+func TestConfigEnv(t *testing.T) {
+	environ := []string{
+		"HOME=/home/user",
+		"SYNEST_SPOTIFY_CLIENT_SECRET=s3cr3t",
+		"SYNEST_SPOTIFY_REFRESH_TOKEN=AQDr3fr3sh",
+		"SYNEST_MODE=blur",
+		"SYNEST_NORMALIZE=channel, remaster",
+	}
+
+	full := string(ConfigEnv(environ, false))
+	for _, want := range []string{`SYNEST_MODE="blur"`, `SYNEST_NORMALIZE="channel, remaster"`, `SYNEST_SPOTIFY_CLIENT_SECRET="s3cr3t"`} {
+		if !strings.Contains(full, want) {
+			t.Errorf("missing %s in:\n%s", want, full)
+		}
+	}
+	if strings.Contains(full, "HOME") {
+		t.Errorf("non-synest variable exported:\n%s", full)
+	}
+	if strings.Index(full, "SYNEST_MODE") > strings.Index(full, "SYNEST_NORMALIZE") {
+		t.Errorf("variables are not sorted:\n%s", full)
+	}
+
+	redacted := string(ConfigEnv(environ, true))
+	for _, secret := range []string{"s3cr3t", "AQDr3fr3sh"} {
+		if strings.Contains(redacted, secret) {
+			t.Errorf("secret %s not redacted:\n%s", secret, redacted)
+		}
+	}
+	if !strings.Contains(redacted, `SYNEST_SPOTIFY_REFRESH_TOKEN="REDACTED"`) {
+		t.Errorf("refresh token not masked:\n%s", redacted)
+	}
+}
+
+func TestConfigYAML(t *testing.T) {
+	data := []byte(`mode: blur
+# Account of the spotify backend
+spotify:
+  client_id: abc
+  client_secret: s3cr3t
+  refresh_token: AQDr3fr3sh
+upload:
+  secret: ""
+monitor:
+  mpd:
+    address: localhost:6600
+    password: hunter2
+`)
+
+	full, err := ConfigYAML(data, false)
+	if err != nil || !bytes.Equal(full, data) {
+		t.Fatalf("expected the file unchanged, got %v:\n%s", err, full)
+	}
+
+	masked, err := ConfigYAML(data, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, secret := range []string{"s3cr3t", "AQDr3fr3sh", "hunter2"} {
+		if bytes.Contains(masked, []byte(secret)) {
+			t.Errorf("secret %s not redacted:\n%s", secret, masked)
+		}
+	}
+	for _, want := range []string{"client_id: abc", "refresh_token: REDACTED", `secret: ""`, "address: localhost:6600", "# Account of the spotify backend"} {
+		if !bytes.Contains(masked, []byte(want)) {
+			t.Errorf("missing %s in:\n%s", want, masked)
+		}
+	}
+
+	if _, err := ConfigYAML([]byte("spotify: [unclosed"), true); err == nil {
+		t.Error("expected an error for an invalid file")
+	}
+}
+
+// rawBundle builds a bundle bypassing Write's validation
+func rawBundle(t *testing.T, entries map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw, err := zstd.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw := tar.NewWriter(zw)
+
+	names := []string{"manifest.json"}
+	for name := range entries {
+		if name != "manifest.json" {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		data, ok := entries[name]
+		if !ok {
+			continue
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,52 @@
+// Package clock abstracts the passage of time, so timing logic such as
+// debouncing, rate limiting and idle timers can be tested deterministically.
+// Production code uses New, tests drive a Fake.
+package clock
+
+import "time"
+
+// Clock tells the time and creates timers
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// NewTimer creates a timer that sends the current time on its channel after d
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single-shot timer, like time.Timer
+type Timer interface {
+	// C returns the channel the time is sent on when the timer fires
+	C() <-chan time.Time
+	// Stop prevents the timer from firing. It reports whether the timer was active.
+	Stop() bool
+	// Reset changes the timer to fire after d. It reports whether the timer was active.
+	Reset(d time.Duration) bool
+}
+
+// New returns the system clock
+func New() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+// Until returns the duration until t according to c
+func Until(c Clock, t time.Time) time.Duration {
+	return t.Sub(c.Now())
+}
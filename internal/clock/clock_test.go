@@ -0,0 +1,67 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := NewFake(start)
+
+	early := clk.NewTimer(time.Second)
+	late := clk.NewTimer(time.Minute)
+	stopped := clk.NewTimer(time.Second)
+	if !stopped.Stop() {
+		t.Error("expected Stop to report an active timer")
+	}
+
+	clk.Advance(30 * time.Second)
+	select {
+	case at := <-early.C():
+		if want := start.Add(time.Second); !at.Equal(want) {
+			t.Errorf("expected tick at %s, got %s", want, at)
+		}
+	default:
+		t.Fatal("expected the due timer to fire")
+	}
+	select {
+	case <-late.C():
+		t.Fatal("timer fired before its deadline")
+	case <-stopped.C():
+		t.Fatal("stopped timer fired")
+	default:
+	}
+	if got := clk.Now(); !got.Equal(start.Add(30 * time.Second)) {
+		t.Errorf("expected time to advance by 30s, got %s", got)
+	}
+
+	// Reset restarts the countdown from the current fake time
+	late.Reset(10 * time.Second)
+	clk.Advance(9 * time.Second)
+	select {
+	case <-late.C():
+		t.Fatal("reset timer fired early")
+	default:
+	}
+	clk.Advance(time.Second)
+	select {
+	case <-late.C():
+	default:
+		t.Fatal("expected the reset timer to fire")
+	}
+}
+
+func TestFake_BlockUntil(t *testing.T) {
+	clk := NewFake(time.Time{})
+	armed := make(chan struct{})
+	go func() {
+		timer := clk.NewTimer(time.Second)
+		close(armed)
+		<-timer.C()
+	}()
+
+	clk.BlockUntil(1)
+	<-armed
+	clk.Advance(time.Second)
+}
@@ -0,0 +1,133 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a Clock that only moves when told to. Timers fire during Advance,
+// in order of their deadline. It is safe for concurrent use.
+type Fake struct {
+	mu     sync.Mutex
+	cond   *sync.Cond // Broadcast whenever the set of active timers changes
+	now    time.Time
+	timers []*fakeTimer // Active timers
+}
+
+// NewFake returns a fake clock set to start
+func NewFake(start time.Time) *Fake {
+	f := &Fake{now: start}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now returns the fake time
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// NewTimer creates a timer firing once the fake time reaches now + d
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	t := &fakeTimer{clock: f, c: make(chan time.Time, 1)}
+	t.Reset(d)
+	return t
+}
+
+// Advance moves the fake time forward by d, firing every timer that becomes due
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	end := f.now.Add(d)
+	for {
+		next := f.nextDue(end)
+		if next == nil {
+			break
+		}
+		f.now = next.deadline
+		f.remove(next)
+		select {
+		case next.c <- f.now:
+		default: // Unread tick, like time.Timer the channel holds at most one
+		}
+	}
+	f.now = end
+	f.cond.Broadcast()
+}
+
+// BlockUntil waits until at least n timers are active, so a test can advance
+// the clock once the code under test has armed its timers
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.timers) < n {
+		f.cond.Wait()
+	}
+}
+
+// nextDue returns the active timer with the earliest deadline up to end
+func (f *Fake) nextDue(end time.Time) *fakeTimer {
+	var next *fakeTimer
+	for _, t := range f.timers {
+		if !t.deadline.After(end) && (next == nil || t.deadline.Before(next.deadline)) {
+			next = t
+		}
+	}
+	return next
+}
+
+// remove deactivates a timer and reports whether it was active
+func (f *Fake) remove(t *fakeTimer) bool {
+	for i, active := range f.timers {
+		if active == t {
+			f.timers = append(f.timers[:i], f.timers[i+1:]...)
+			f.cond.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTimer struct {
+	clock    *Fake
+	c        chan time.Time
+	deadline time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+// Stop deactivates the timer and drains an unread tick, like time.Timer since Go 1.23
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.stop()
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	active := t.stop()
+	t.deadline = t.clock.now.Add(d)
+	if d <= 0 {
+		// Due at once, fire without waiting for the next Advance
+		t.c <- t.clock.now
+		return active
+	}
+	t.clock.timers = append(t.clock.timers, t)
+	t.clock.cond.Broadcast()
+	return active
+}
+
+func (t *fakeTimer) stop() bool {
+	active := t.clock.remove(t)
+	select {
+	case <-t.c:
+	default:
+	}
+	return active
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// problems collects configuration values that were rejected. Each one is logged
+// as a warning and replaced by its fallback; Check reports them all as errors.
+type problems struct {
+	logger *zap.Logger
+	errs   []error
+}
+
+// invalid records that value was rejected for name and what is used instead
+func (p *problems) invalid(name, value, fallback string, reason error) {
+	p.logger.Warn("Invalid "+name+" value, "+fallback,
+		zap.String("value", value),
+		zap.Error(reason))
+	p.errs = append(p.errs, fmt.Errorf("%s=%q: %w", name, value, reason))
+}
+
+// Check loads the configuration like NewAppConfig, but fails instead of falling
+// back to defaults: an unreadable or invalid config file and every invalid
+// environment value are reported, one per line
+func Check(logger *zap.Logger) (*AppConfig, error) {
+	configFile := FilePath()
+	file, err := loadFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", configFile, err)
+	}
+
+	p := &problems{logger: logger}
+	s := resolve(p, configFile, file)
+	if len(p.errs) > 0 {
+		return nil, errors.Join(p.errs...)
+	}
+	return newAppConfig(logger, configFile, s), nil
+}
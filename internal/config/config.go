@@ -1,63 +1,1488 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/monitor/players"
+	"github.com/genricoloni/synest/internal/paths"
 	"go.uber.org/zap"
 )
 
+// Debounce defaults, shared with the simulation of the scheduler
+const (
+	DefaultDebounce = 500 * time.Millisecond
+	DefaultBurst    = 3               // Updates the token bucket allows in a row
+	DefaultBrowsing = 2 * time.Second // Debounce while skipping through tracks
+)
+
 const (
-	defaultOutputDir = "/tmp/synest"
 	defaultMode      = "blur"
+	defaultMonitor   = "auto"
+	defaultSetter    = "auto"
+	defaultSeparator = ", "
+	defaultHeartbeat = 30 * time.Second
+	defaultGrace     = 3 * time.Second // Time a vanished player has to come back before playback counts as stopped
+	defaultPosition  = 5 * time.Second // Position polls correcting the estimate between Seeked signals
+	defaultRotate    = 5 * time.Minute
+	defaultSpotify   = 5 * time.Second // Playback state polls of the spotify monitor backend
+
+	defaultBatteryDebounce = 5 * time.Second // Debounce while saving power, skipping through tracks renders less
+
+	defaultHistorySizeMB = 200
+
+	defaultBlurRadius    = 15.0
+	defaultCoverSize     = 0.40 // Cover size as a fraction of the screen height
+	defaultParticles     = 1200 // Flow field strokes of the generative mode
+	defaultShapes        = 7    // Soft circles of the generative mode
+	defaultBars          = 160  // Waveform bars across the screen
+	defaultBarHeight     = 0.70 // Max waveform bar height as a fraction of the screen height
+	defaultJPEGQuality   = 90
+	defaultTrimTolerance = 24 // Channel levels a border pixel may stray, enough for JPEG artifacts
+	defaultBadgeSize     = 48 // Pixels, a launcher-sized icon
+	defaultReplaySpeed   = 1  // Recorded events are played back in real time
+	defaultMPDAddress    = "localhost:6600"
+	defaultUploadRegion  = "us-east-1"
+	defaultEInkWidth     = 800 // Pixels, a common 7.5" panel
+	defaultEInkHeight    = 480
+	defaultEInkLevels    = 2
+	defaultFetchTimeout  = 10 * time.Second
+	defaultArtCacheTTL   = 30 * 24 * time.Hour
+	defaultArtCacheSize  = 100 // MiB, a few thousand covers
+
+	defaultReadyTimeout = 30 * time.Second
+	maxReadyTimeout     = time.Minute // Must fit in the daemon start timeout
+
+	defaultSlideshowAfter    = 5 * time.Minute
+	defaultSlideshowInterval = 10 * time.Minute
 )
 
-// AppConfig holds application configuration
+// AppConfig holds application configuration. It can be reloaded at runtime,
+// so every getter reads the latest settings.
 type AppConfig struct {
-	logger    *zap.Logger
-	outputDir string
-	mode      string
+	logger  *zap.Logger
+	path    string                   // Config file location, empty when unknown
+	current atomic.Pointer[settings] // Replaced as a whole on reload
+	changes chan struct{}            // Signaled after a successful reload
+	private atomic.Pointer[string]   // Private mode switched at runtime, nil follows the settings
 }
 
-// NewAppConfig creates a new application configuration instance
+// settings is an immutable snapshot of the resolved configuration
+type settings struct {
+	outputDir           string
+	stateDir            string
+	mode                string
+	autoGenres          map[string]string
+	deterministic       bool
+	modeConfig          domain.ModeConfig
+	jpegQuality         int
+	coverThumbnails     bool
+	backgroundFilter    string
+	coverFilter         string
+	coverAspect         string
+	trimBorders         bool
+	trimTolerance       int
+	playerBadge         string
+	playerBadgeSize     int
+	scene               string
+	fetchTimeout        time.Duration
+	artCache            domain.ArtCache
+	spotifyClientID     string
+	spotifyClientSecret string
+	spotifyRefresh      string
+	spotifyPoll         time.Duration
+	playerQuirks        map[string]string
+	artSettleDelays     map[string]time.Duration
+	normalizeRules      []string
+	artistSeparator     string
+	playerAllow         []string
+	playerDeny          []string
+	playerPolicy        string
+	playerPriority      []string
+	playerPin           string
+	debounce            time.Duration
+	debounceStrategy    string
+	debounceBurst       int
+	debounceBrowsing    time.Duration
+	minInterval         time.Duration
+	dedup               bool
+	skipKinds           []string
+	notifyErrors        bool
+	onAppliedCommand    string
+	customCommand       string
+	setterMonitor       string
+	pauseBehavior       string
+	quitBehavior        string
+	quietHours          []domain.QuietWindow
+	quietBehavior       string
+	batterySaver        string
+	batteryDebounce     time.Duration
+	batteryCheapRender  bool
+	metered             string
+	historyMaxCount     int
+	preFetchHooks       []string
+	postProcessHooks    []string
+	historyMaxSize      int64
+	monitorBackend      string
+	monitorPlayer       string
+	monitorRecord       string
+	monitorReplay       string
+	replaySpeed         int
+	mpd                 domain.MPD
+	setter              string
+	delivery            string
+	multiDisplay        string
+	transition          domain.Transition
+	upload              domain.Upload
+	phonePush           domain.PhonePush
+	eink                domain.EInk
+	privateMode         string
+	privatePlayers      []string
+	heartbeat           time.Duration
+	restartGrace        time.Duration
+	positionInterval    time.Duration
+	readyTimeout        time.Duration
+	rotateAfter         time.Duration
+	rotateInterval      time.Duration
+	slideshowDir        string
+	slideshowAfter      time.Duration
+	slideshowInterval   time.Duration
+}
+
+// NewAppConfig creates a new application configuration instance.
+// Values come from the config file (see FilePath) and the built-in defaults,
+// environment variables override both.
 func NewAppConfig(logger *zap.Logger) *AppConfig {
-	// Read from environment variables or use defaults
-	outputDir := os.Getenv("SYNEST_OUTPUT_DIR")
-	if outputDir == "" {
-		outputDir = defaultOutputDir
+	configFile := FilePath()
+	file, err := loadFile(configFile)
+	if err != nil {
+		logger.Warn("Ignoring config file",
+			zap.String("path", configFile),
+			zap.Error(err))
+		file = &fileConfig{}
 	}
 
-	mode := os.Getenv("SYNEST_MODE")
-	if mode == "" {
+	return newAppConfig(logger, configFile, resolve(&problems{logger: logger}, configFile, file))
+}
+
+// newAppConfig creates a configuration holding the resolved settings
+func newAppConfig(logger *zap.Logger, path string, s *settings) *AppConfig {
+	c := &AppConfig{
+		logger:  logger,
+		path:    path,
+		changes: make(chan struct{}, 1),
+	}
+	c.current.Store(s)
+	return c
+}
+
+// Reload reads the config file and the environment again. When the file is
+// invalid, the current configuration is kept and the error is returned.
+func (c *AppConfig) Reload() error {
+	file, err := loadFile(c.path)
+	if err != nil {
+		return err
+	}
+	s := resolve(&problems{logger: c.logger}, c.path, file)
+	// A private mode switched at runtime lasts until the configured one changes
+	if s.privateMode != c.current.Swap(s).privateMode {
+		c.private.Store(nil)
+	}
+
+	// A pending signal already tells the listener to read the latest settings
+	select {
+	case c.changes <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Changes returns a channel signaled after every successful reload
+func (c *AppConfig) Changes() <-chan struct{} {
+	return c.changes
+}
+
+// Path returns the location of the config file
+func (c *AppConfig) Path() string {
+	return c.path
+}
+
+// resolve combines environment variables, the config file and the defaults.
+// Invalid values are recorded in p and replaced by their defaults.
+func resolve(p *problems, configFile string, file *fileConfig) *settings {
+	// Read from environment variables or use defaults
+	outputDir := envOr("SYNEST_OUTPUT_DIR", stringOr(file.OutputDir, paths.CacheDir()))
+	stateDir := envOr("SYNEST_STATE_DIR", stringOr(file.StateDir, paths.StateDir()))
+	mode := strings.ToLower(envOr("SYNEST_MODE", stringOr(file.Mode, defaultMode)))
+	switch mode {
+	case domain.ModeBlur, domain.ModeGenerative, domain.ModeWaveform, domain.ModeAuto:
+	default:
+		p.invalid("SYNEST_MODE", mode, "using "+defaultMode,
+			fmt.Errorf("must be %s, %s, %s or %s", domain.ModeBlur, domain.ModeGenerative, domain.ModeWaveform, domain.ModeAuto))
 		mode = defaultMode
 	}
 
-	// Expand path if it contains ~ or environment variables
-	outputDir = os.ExpandEnv(outputDir)
-	if len(outputDir) > 0 && outputDir[0] == '~' {
-		home, err := os.UserHomeDir()
+	// Per-genre overrides of the auto mode, e.g. "ambient=generative,jazz=blur"
+	autoGenres := lowercaseKeys(file.Auto.Genres, strings.ToLower)
+	if value := os.Getenv("SYNEST_AUTO_GENRES"); value != "" {
+		parsed, err := parsePlayerValues(value)
 		if err == nil {
-			outputDir = filepath.Join(home, outputDir[1:])
+			err = validateGenreModes(parsed)
+		}
+		if err != nil {
+			p.invalid("SYNEST_AUTO_GENRES", value, "ignoring genre rules", err)
+		} else {
+			autoGenres = parsed
+		}
+	}
+
+	outputDir = expandPath(outputDir)
+	stateDir = expandPath(stateDir)
+
+	// Deterministic rendering is opt-in (reproducible output for tests and shared setups)
+	deterministic := parseBoolEnv(p, "SYNEST_DETERMINISTIC", valueOr(file.Deterministic, false))
+
+	// Rendering and download parameters are only set from the config file.
+	// The processor section sets what modes share, the modes section overrides it per mode.
+	blurRadius := valueOr(file.Processor.BlurRadius, defaultBlurRadius)
+	coverSize := valueOr(file.Processor.CoverSize, defaultCoverSize)
+	modeConfig := domain.ModeConfig{
+		Blur: domain.BlurConfig{
+			BlurRadius: valueOr(file.Modes.Blur.BlurRadius, blurRadius),
+			CoverSize:  valueOr(file.Modes.Blur.CoverSize, coverSize),
+			MaxHeight:  valueOr(file.Modes.Blur.MaxHeight, 0),
+		},
+		Generative: domain.GenerativeConfig{
+			Particles: valueOr(file.Modes.Generative.Particles, defaultParticles),
+			Shapes:    valueOr(file.Modes.Generative.Shapes, defaultShapes),
+			MaxHeight: valueOr(file.Modes.Generative.MaxHeight, 0),
+		},
+		Waveform: domain.WaveformConfig{
+			Bars:       valueOr(file.Modes.Waveform.Bars, defaultBars),
+			Height:     valueOr(file.Modes.Waveform.Height, defaultBarHeight),
+			BlurRadius: valueOr(file.Modes.Waveform.BlurRadius, blurRadius),
+			CoverSize:  valueOr(file.Modes.Waveform.CoverSize, coverSize),
+			MaxHeight:  valueOr(file.Modes.Waveform.MaxHeight, 0),
+		},
+	}
+	jpegQuality := valueOr(file.Processor.JPEGQuality, defaultJPEGQuality)
+	coverThumbnails := parseBoolEnv(p, "SYNEST_COVER_THUMBNAILS", valueOr(file.Processor.CoverThumbnails, false))
+	backgroundFilter := parseFilterEnv(p, "SYNEST_FILTER_BACKGROUND", stringOr(file.Processor.BackgroundFilter, domain.FilterLanczos))
+	coverFilter := parseFilterEnv(p, "SYNEST_FILTER_COVER", stringOr(file.Processor.CoverFilter, domain.FilterLanczos))
+	fetchTimeout := valueOr(file.Fetcher.Timeout, defaultFetchTimeout)
+	artCache := domain.ArtCache{
+		Enabled: parseBoolEnv(p, "SYNEST_ART_CACHE", valueOr(file.Fetcher.Cache, true)),
+		Dir:     filepath.Join(paths.CacheDir(), "art"),
+		TTL:     parseDurationEnv(p, "SYNEST_ART_CACHE_TTL", valueOr(file.Fetcher.CacheTTL, defaultArtCacheTTL)),
+		MaxSize: int64(parseIntEnv(p, "SYNEST_ART_CACHE_MAX_SIZE_MB", valueOr(file.Fetcher.CacheMaxSizeMB, defaultArtCacheSize), 1, maxArtCacheSize)) << 20,
+	}
+
+	coverAspect := strings.ToLower(strings.TrimSpace(envOr("SYNEST_COVER_ASPECT", file.Processor.CoverAspect)))
+	switch coverAspect {
+	case "":
+		coverAspect = domain.CoverAspectPreserve
+	case domain.CoverAspectPreserve, domain.CoverAspectSquare, domain.CoverAspectTrim:
+	default:
+		p.invalid("SYNEST_COVER_ASPECT", coverAspect, "using "+domain.CoverAspectPreserve,
+			fmt.Errorf("must be %s, %s or %s", domain.CoverAspectPreserve, domain.CoverAspectSquare, domain.CoverAspectTrim))
+		coverAspect = domain.CoverAspectPreserve
+	}
+	trimBorders := parseBoolEnv(p, "SYNEST_TRIM_BORDERS", valueOr(file.Processor.TrimBorders, false))
+	trimTolerance := parseIntEnv(p, "SYNEST_TRIM_TOLERANCE", valueOr(file.Processor.TrimTolerance, defaultTrimTolerance), 0, maxTrimTolerance)
+
+	playerBadge := strings.ToLower(strings.TrimSpace(envOr("SYNEST_PLAYER_BADGE", file.Processor.PlayerBadge)))
+	if playerBadge == "" {
+		playerBadge = domain.BadgeOff
+	} else if !slices.Contains(domain.Badges, playerBadge) {
+		p.invalid("SYNEST_PLAYER_BADGE", playerBadge, "using "+domain.BadgeOff,
+			fmt.Errorf("must be one of %s", strings.Join(domain.Badges, ", ")))
+		playerBadge = domain.BadgeOff
+	}
+	playerBadgeSize := parseIntEnv(p, "SYNEST_PLAYER_BADGE_SIZE", valueOr(file.Processor.PlayerBadgeSize, defaultBadgeSize), minBadgeSize, maxBadgeSize)
+
+	// Scene-only output is validated against the setter when the executor is constructed
+	scene := strings.ToLower(strings.TrimSpace(envOr("SYNEST_SCENE", file.Processor.Scene)))
+	switch scene {
+	case "":
+		scene = domain.SceneOff
+	case domain.SceneOff, domain.SceneAlongside, domain.SceneOnly:
+	default:
+		p.invalid("SYNEST_SCENE", scene, "using "+domain.SceneOff,
+			fmt.Errorf("must be %s, %s or %s", domain.SceneOff, domain.SceneAlongside, domain.SceneOnly))
+		scene = domain.SceneOff
+	}
+
+	// Spotify credentials are optional and enable audio-features enrichment
+	spotifyClientID := envOr("SYNEST_SPOTIFY_CLIENT_ID", file.Spotify.ClientID)
+	spotifyClientSecret := envOr("SYNEST_SPOTIFY_CLIENT_SECRET", file.Spotify.ClientSecret)
+	// A user refresh token enables the spotify monitor backend, polling the Web API
+	spotifyRefresh := envOr("SYNEST_SPOTIFY_REFRESH_TOKEN", file.Spotify.RefreshToken)
+	spotifyPoll := parseDurationEnv(p, "SYNEST_SPOTIFY_POLL_INTERVAL", valueOr(file.Spotify.PollInterval, defaultSpotify))
+	if spotifyPoll == 0 {
+		p.invalid("SYNEST_SPOTIFY_POLL_INTERVAL", "0", "using default", errors.New("must be positive"))
+		spotifyPoll = defaultSpotify
+	}
+
+	// Per-player quirks adapter selection, e.g. "chromium=firefox,spotify=none"
+	playerQuirks := lowercaseKeys(file.Monitor.PlayerQuirks, strings.ToLower)
+	if value := os.Getenv("SYNEST_PLAYER_QUIRKS"); value != "" {
+		parsed, err := parsePlayerValues(value)
+		if err != nil {
+			p.invalid("SYNEST_PLAYER_QUIRKS", value, "using defaults", err)
+		} else {
+			playerQuirks = parsed
+		}
+	}
+
+	// Per-player settle delays override the adapter defaults ("spotify=0" disables)
+	artSettleDelays := lowercaseKeys(file.Monitor.ArtSettleDelays, func(d time.Duration) time.Duration { return d })
+	if value := os.Getenv("SYNEST_ART_SETTLE_DELAYS"); value != "" {
+		parsed, err := parsePlayerDurations(value)
+		if err != nil {
+			p.invalid("SYNEST_ART_SETTLE_DELAYS", value, "using defaults", err)
+		} else {
+			artSettleDelays = parsed
+		}
+	}
+
+	// Text normalization rules, "none" disables them (nil keeps the defaults)
+	var normalizeRules []string
+	if file.Monitor.Normalize != nil {
+		normalizeRules = parseRules(strings.Join(file.Monitor.Normalize, ","))
+	}
+	if value := os.Getenv("SYNEST_NORMALIZE"); value != "" {
+		normalizeRules = parseRules(value)
+	}
+
+	// Separator between artists in captions, kept verbatim so it can carry spaces
+	artistSeparator := envOr("SYNEST_ARTIST_SEPARATOR", valueOr(file.Monitor.ArtistSeparator, defaultSeparator))
+
+	// Player allow and deny patterns, kept case-sensitive for regular expressions
+	playerAllow := parsePatternsEnv(p, "SYNEST_PLAYERS_ALLOW", file.Players.Allow)
+	playerDeny := parsePatternsEnv(p, "SYNEST_PLAYERS_DENY", file.Players.Deny)
+
+	// Driving player among several playing ones: a policy, its ranking and an optional pin
+	playerPolicy := strings.ToLower(strings.TrimSpace(envOr("SYNEST_PLAYER_POLICY", file.Players.Policy)))
+	switch playerPolicy {
+	case "":
+		playerPolicy = domain.PlayerPolicyRecent
+	case domain.PlayerPolicyRecent, domain.PlayerPolicyPriority:
+	default:
+		p.invalid("SYNEST_PLAYER_POLICY", playerPolicy, "using "+domain.PlayerPolicyRecent,
+			fmt.Errorf("must be %s or %s", domain.PlayerPolicyRecent, domain.PlayerPolicyPriority))
+		playerPolicy = domain.PlayerPolicyRecent
+	}
+	playerPriority := parsePatternsEnv(p, "SYNEST_PLAYER_PRIORITY", file.Players.Priority)
+	playerPin := strings.TrimSpace(envOr("SYNEST_PLAYER_PIN", file.Players.Pin))
+	if err := players.Validate([]string{playerPin}); err != nil {
+		p.invalid("SYNEST_PLAYER_PIN", playerPin, "using the config file value", err)
+		playerPin = file.Players.Pin
+	}
+
+	// Update policy: debounce quiet period, minimum interval between updates, dedup
+	debounce := parseDurationEnv(p, "SYNEST_DEBOUNCE", valueOr(file.Engine.Debounce, DefaultDebounce))
+	debounceStrategy := strings.ToLower(strings.TrimSpace(envOr("SYNEST_DEBOUNCE_STRATEGY", file.Engine.DebounceStrategy)))
+	switch debounceStrategy {
+	case "":
+		debounceStrategy = domain.DebounceTrailing
+	case domain.DebounceTrailing, domain.DebounceLeading, domain.DebounceTokenBucket:
+	default:
+		p.invalid("SYNEST_DEBOUNCE_STRATEGY", debounceStrategy, "using "+domain.DebounceTrailing,
+			fmt.Errorf("must be %s, %s or %s", domain.DebounceTrailing, domain.DebounceLeading, domain.DebounceTokenBucket))
+		debounceStrategy = domain.DebounceTrailing
+	}
+	debounceBurst := parseIntEnv(p, "SYNEST_DEBOUNCE_BURST", valueOr(file.Engine.DebounceBurst, DefaultBurst), 1, maxBurst)
+	debounceBrowsing := parseDurationEnv(p, "SYNEST_DEBOUNCE_BROWSING", valueOr(file.Engine.DebounceBrowsing, DefaultBrowsing))
+	minInterval := parseDurationEnv(p, "SYNEST_MIN_INTERVAL", valueOr(file.Engine.MinInterval, 0))
+	heartbeat := parseDurationEnv(p, "SYNEST_HEARTBEAT", valueOr(file.Monitor.Heartbeat, defaultHeartbeat))
+	restartGrace := parseDurationEnv(p, "SYNEST_RESTART_GRACE", valueOr(file.Monitor.RestartGrace, defaultGrace))
+	positionInterval := parseDurationEnv(p, "SYNEST_POSITION_INTERVAL", valueOr(file.Monitor.PositionInterval, defaultPosition))
+	// Startup waits this long for the session bus, the setter daemon and the display
+	readyTimeout := parseDurationEnv(p, "SYNEST_READY_TIMEOUT", valueOr(file.ReadyTimeout, defaultReadyTimeout))
+	if readyTimeout > maxReadyTimeout {
+		p.invalid("SYNEST_READY_TIMEOUT", readyTimeout.String(), "using maximum",
+			fmt.Errorf("must be at most %s", maxReadyTimeout))
+		readyTimeout = maxReadyTimeout
+	}
+	rotateAfter := parseDurationEnv(p, "SYNEST_ROTATE_AFTER", valueOr(file.Engine.RotateAfter, 0))
+	rotateInterval := parseDurationEnv(p, "SYNEST_ROTATE_INTERVAL", valueOr(file.Engine.RotateInterval, defaultRotate))
+	if rotateInterval == 0 {
+		p.invalid("SYNEST_ROTATE_INTERVAL", "0", "using default", errors.New("must be positive"))
+		rotateInterval = defaultRotate
+	}
+	dedup := parseBoolEnv(p, "SYNEST_DEDUP", valueOr(file.Engine.Dedup, false))
+
+	// Ads and podcasts can be kept off the wallpaper, e.g. "ad,podcast"
+	skipKinds := parseList(strings.Join(file.Engine.SkipKinds, ","))
+	if value := os.Getenv("SYNEST_SKIP_KINDS"); value != "" {
+		skipKinds = parseList(value)
+	}
+	skipKinds = slices.DeleteFunc(skipKinds, func(kind string) bool {
+		if kind != domain.KindAd && kind != domain.KindPodcast {
+			p.invalid("SYNEST_SKIP_KINDS", kind, "ignoring it",
+				fmt.Errorf("must be %s or %s", domain.KindAd, domain.KindPodcast))
+			return true
+		}
+		return false
+	})
+
+	// Desktop notifications for repeated failures are opt-in
+	notifyErrors := parseBoolEnv(p, "SYNEST_NOTIFY_ERRORS", valueOr(file.NotifyErrors, false))
+
+	// Shell command run once a new wallpaper is confirmed on screen
+	onAppliedCommand := strings.TrimSpace(envOr("SYNEST_ON_APPLIED", file.Executor.OnApplied))
+
+	pauseBehavior := strings.ToLower(envOr("SYNEST_ON_PAUSE", file.Engine.OnPause))
+	switch pauseBehavior {
+	case "":
+		pauseBehavior = domain.PauseKeep
+	case domain.PauseKeep, domain.PauseDim, domain.PauseRestore:
+	default:
+		p.invalid("SYNEST_ON_PAUSE", pauseBehavior, "keeping wallpaper on pause",
+			fmt.Errorf("must be %s, %s or %s", domain.PauseKeep, domain.PauseDim, domain.PauseRestore))
+		pauseBehavior = domain.PauseKeep
+	}
+
+	quitBehavior := strings.ToLower(envOr("SYNEST_ON_QUIT", file.Engine.OnQuit))
+	switch quitBehavior {
+	case "":
+		quitBehavior = domain.QuitKeep
+	case domain.QuitKeep, domain.QuitRestore:
+	default:
+		p.invalid("SYNEST_ON_QUIT", quitBehavior, "keeping wallpaper on quit",
+			fmt.Errorf("must be %s or %s", domain.QuitKeep, domain.QuitRestore))
+		quitBehavior = domain.QuitKeep
+	}
+
+	// Quiet hours are consulted by the engine before every update
+	quietHours, _ := parseQuietWindows(file.QuietHours.Windows) // Validated when the file was loaded
+	if value := os.Getenv("SYNEST_QUIET_HOURS"); value != "" {
+		if windows, err := parseQuietWindows(strings.Split(value, ",")); err != nil {
+			p.invalid("SYNEST_QUIET_HOURS", value, "no quiet hours", err)
+			quietHours = nil
+		} else {
+			quietHours = windows
+		}
+	}
+	quietBehavior := strings.ToLower(strings.TrimSpace(envOr("SYNEST_QUIET_BEHAVIOR", file.QuietHours.Behavior)))
+	switch quietBehavior {
+	case "":
+		quietBehavior = domain.QuietSkip
+	case domain.QuietSkip, domain.QuietDim:
+	default:
+		p.invalid("SYNEST_QUIET_BEHAVIOR", quietBehavior, "skipping updates during quiet hours",
+			fmt.Errorf("must be %s or %s", domain.QuietSkip, domain.QuietDim))
+		quietBehavior = domain.QuietSkip
+	}
+
+	// Backend names are validated when the monitor is constructed
+	monitorBackend := strings.ToLower(strings.TrimSpace(envOr("SYNEST_MONITOR", file.Monitor.Backend)))
+	if monitorBackend == "" {
+		monitorBackend = defaultMonitor
+	}
+
+	// Single MPRIS player subscribed to, the signals of the others never reach the daemon
+	monitorPlayer, err := players.BusName(strings.TrimSpace(envOr("SYNEST_MONITOR_PLAYER", file.Monitor.Player)))
+	if err != nil {
+		p.invalid("SYNEST_MONITOR_PLAYER", os.Getenv("SYNEST_MONITOR_PLAYER"), "using the config file value", err)
+		monitorPlayer, _ = players.BusName(strings.TrimSpace(file.Monitor.Player))
+	}
+
+	// Recording and replay files are opened when the monitor is constructed
+	monitorRecord := strings.TrimSpace(envOr("SYNEST_MONITOR_RECORD", file.Monitor.Record))
+	if monitorRecord != "" {
+		monitorRecord = expandPath(monitorRecord)
+	}
+	monitorReplay := strings.TrimSpace(envOr("SYNEST_MONITOR_REPLAY", file.Monitor.Replay))
+	if monitorReplay != "" {
+		monitorReplay = expandPath(monitorReplay)
+	}
+	replaySpeed := parseIntEnv(p, "SYNEST_MONITOR_REPLAY_SPEED", valueOr(file.Monitor.ReplaySpeed, defaultReplaySpeed), 0, maxReplaySpeed)
+
+	// The mpd backend connects when the monitor starts, failures are retried there
+	mpd := domain.MPD{
+		Address:  strings.TrimSpace(envOr("SYNEST_MPD_ADDRESS", file.Monitor.MPD.Address)),
+		Password: envOr("SYNEST_MPD_PASSWORD", file.Monitor.MPD.Password),
+		MusicDir: strings.TrimSpace(envOr("SYNEST_MPD_MUSIC_DIR", file.Monitor.MPD.MusicDir)),
+	}
+	if mpd.Address == "" {
+		mpd.Address = defaultMPDAddress
+	}
+	if mpd.MusicDir != "" {
+		mpd.MusicDir = expandPath(mpd.MusicDir)
+	}
+
+	// Setter names are validated when the executor is constructed
+	setter := strings.ToLower(strings.TrimSpace(envOr("SYNEST_SETTER", stringOr(file.Executor.Backend, file.Executor.Setter))))
+	if setter == "" {
+		setter = defaultSetter
+	}
+
+	// User-defined setter command, parsed when the executor is constructed
+	customCommand := strings.TrimSpace(envOr("SYNEST_CUSTOM_COMMAND", file.Executor.CustomCommand))
+	setterMonitor := strings.TrimSpace(envOr("SYNEST_SETTER_MONITOR", file.Executor.Monitor))
+
+	// In-memory delivery is validated against the setter when the executor is constructed
+	delivery := strings.ToLower(strings.TrimSpace(envOr("SYNEST_DELIVERY", file.Executor.Delivery)))
+	switch delivery {
+	case "":
+		delivery = domain.DeliveryFile
+	case domain.DeliveryFile, domain.DeliveryMemfd:
+	default:
+		p.invalid("SYNEST_DELIVERY", delivery, "writing wallpapers to disk",
+			fmt.Errorf("must be %s or %s", domain.DeliveryFile, domain.DeliveryMemfd))
+		delivery = domain.DeliveryFile
+	}
+
+	// Spanning is validated against the setter when the executor is constructed
+	multiDisplay := strings.ToLower(strings.TrimSpace(envOr("SYNEST_MULTI_DISPLAY", file.Executor.MultiDisplay)))
+	switch multiDisplay {
+	case "":
+		multiDisplay = domain.MultiDisplayPrimary
+	case domain.MultiDisplayPrimary, domain.MultiDisplaySpan:
+	default:
+		p.invalid("SYNEST_MULTI_DISPLAY", multiDisplay, "rendering for the primary display",
+			fmt.Errorf("must be %s or %s", domain.MultiDisplayPrimary, domain.MultiDisplaySpan))
+		multiDisplay = domain.MultiDisplayPrimary
+	}
+
+	// Transitions are validated against the setter when the executor is constructed
+	transition := domain.Transition{
+		Type:     strings.ToLower(strings.TrimSpace(envOr("SYNEST_TRANSITION", file.Executor.Transition.Type))),
+		Position: strings.ToLower(strings.TrimSpace(envOr("SYNEST_TRANSITION_POS", file.Executor.Transition.Position))),
+		Duration: parseDurationEnv(p, "SYNEST_TRANSITION_DURATION", valueOr(file.Executor.Transition.Duration, 0)),
+	}
+
+	// Uploads are configured as a whole, a rejected part disables them
+	upload := domain.Upload{
+		Kind:   strings.ToLower(strings.TrimSpace(envOr("SYNEST_UPLOAD", file.Upload.Kind))),
+		URL:    strings.TrimSpace(envOr("SYNEST_UPLOAD_URL", file.Upload.URL)),
+		Region: strings.TrimSpace(envOr("SYNEST_UPLOAD_REGION", stringOr(file.Upload.Region, defaultUploadRegion))),
+		User:   envOr("SYNEST_UPLOAD_USER", file.Upload.User),
+		Secret: envOr("SYNEST_UPLOAD_SECRET", file.Upload.Secret),
+	}
+	switch upload.Kind {
+	case "":
+		upload.Kind = domain.UploadOff
+	case domain.UploadOff:
+	case domain.UploadS3, domain.UploadWebDAV:
+		if err := checkHTTPURL(upload.URL); err != nil {
+			p.invalid("SYNEST_UPLOAD_URL", upload.URL, "not uploading", err)
+			upload.Kind = domain.UploadOff
+		}
+	default:
+		p.invalid("SYNEST_UPLOAD", upload.Kind, "not uploading",
+			fmt.Errorf("must be %s, %s or %s", domain.UploadOff, domain.UploadS3, domain.UploadWebDAV))
+		upload.Kind = domain.UploadOff
+	}
+
+	// Like uploads, a rejected push setting disables the push
+	phonePush := domain.PhonePush{
+		Kind:   strings.ToLower(strings.TrimSpace(envOr("SYNEST_PHONE_PUSH", file.Phone.Push))),
+		Target: strings.TrimSpace(envOr("SYNEST_PHONE_TARGET", file.Phone.Target)),
+		Token:  envOr("SYNEST_PHONE_TOKEN", file.Phone.Token),
+	}
+	phonePush.Aspect, _ = parseAspect(file.Phone.Aspect) // Validated when the file was loaded
+	if value := os.Getenv("SYNEST_PHONE_ASPECT"); value != "" {
+		if aspect, err := parseAspect(value); err != nil {
+			p.invalid("SYNEST_PHONE_ASPECT", value, "pushing the wallpaper as is", err)
+			phonePush.Aspect = 0
+		} else {
+			phonePush.Aspect = aspect
+		}
+	}
+	switch phonePush.Kind {
+	case "":
+		phonePush.Kind = domain.PushOff
+	case domain.PushOff:
+	case domain.PushKDEConnect:
+		if phonePush.Target == "" {
+			p.invalid("SYNEST_PHONE_TARGET", phonePush.Target, "not pushing", errors.New("must name the KDE Connect device"))
+			phonePush.Kind = domain.PushOff
+		}
+	case domain.PushNtfy:
+		if err := checkHTTPURL(phonePush.Target); err != nil {
+			p.invalid("SYNEST_PHONE_TARGET", phonePush.Target, "not pushing", err)
+			phonePush.Kind = domain.PushOff
 		}
+	default:
+		p.invalid("SYNEST_PHONE_PUSH", phonePush.Kind, "not pushing",
+			fmt.Errorf("must be %s, %s or %s", domain.PushOff, domain.PushKDEConnect, domain.PushNtfy))
+		phonePush.Kind = domain.PushOff
+	}
+
+	// The e-ink sink reads its settings for every wallpaper, they apply without a rebuild
+	eink := domain.EInk{
+		Command: strings.TrimSpace(envOr("SYNEST_EINK_COMMAND", file.Executor.EInk.Command)),
+		Width:   parseIntEnv(p, "SYNEST_EINK_WIDTH", valueOr(file.Executor.EInk.Width, defaultEInkWidth), minEInkSize, maxEInkSize),
+		Height:  parseIntEnv(p, "SYNEST_EINK_HEIGHT", valueOr(file.Executor.EInk.Height, defaultEInkHeight), minEInkSize, maxEInkSize),
+		Levels:  parseIntEnv(p, "SYNEST_EINK_LEVELS", valueOr(file.Executor.EInk.Levels, defaultEInkLevels), minEInkLevels, maxEInkLevels),
+	}
+
+	// The battery saver is consulted by the engine before every update
+	batterySaver := strings.ToLower(strings.TrimSpace(envOr("SYNEST_BATTERY_SAVER", file.Battery.Saver)))
+	switch batterySaver {
+	case "":
+		batterySaver = domain.BatterySaverAuto
+	case domain.BatterySaverAuto, domain.BatterySaverOn, domain.BatterySaverOff:
+	default:
+		p.invalid("SYNEST_BATTERY_SAVER", batterySaver, "saving power on battery",
+			fmt.Errorf("must be %s, %s or %s", domain.BatterySaverAuto, domain.BatterySaverOn, domain.BatterySaverOff))
+		batterySaver = domain.BatterySaverAuto
+	}
+	batteryDebounce := parseDurationEnv(p, "SYNEST_BATTERY_DEBOUNCE", valueOr(file.Battery.Debounce, defaultBatteryDebounce))
+	batteryCheapRender := parseBoolEnv(p, "SYNEST_BATTERY_CHEAP_RENDER", valueOr(file.Battery.CheapRender, true))
+
+	// The metered mode is consulted by the fetcher and the enricher on every call
+	metered := strings.ToLower(strings.TrimSpace(envOr("SYNEST_METERED", file.Network.Metered)))
+	switch metered {
+	case "":
+		metered = domain.MeteredAuto
+	case domain.MeteredAuto, domain.MeteredOn, domain.MeteredOff:
+	default:
+		p.invalid("SYNEST_METERED", metered, "saving data on metered connections",
+			fmt.Errorf("must be %s, %s or %s", domain.MeteredAuto, domain.MeteredOn, domain.MeteredOff))
+		metered = domain.MeteredAuto
 	}
 
-	logger.Info("Configuration loaded",
+	// The history is opt-in, its size limit only applies once it is enabled
+	historyMaxCount := parseIntEnv(p, "SYNEST_HISTORY_MAX_COUNT", valueOr(file.History.MaxCount, 0), 0, maxHistory)
+	historyMaxSizeMB := parseIntEnv(p, "SYNEST_HISTORY_MAX_SIZE_MB", valueOr(file.History.MaxSizeMB, defaultHistorySizeMB), 0, maxHistorySize)
+
+	// Pipeline hooks are only read from the file, commands do not fit a list variable
+	preFetchHooks := slices.Clone(file.Hooks.PreFetch)
+	postProcessHooks := slices.Clone(file.Hooks.PostProcess)
+
+	// Private mode is switched at runtime by editing the config file, or over the bus
+	privateMode := strings.ToLower(strings.TrimSpace(envOr("SYNEST_PRIVATE", file.Private.Mode)))
+	switch privateMode {
+	case "":
+		privateMode = domain.PrivateOff
+	case domain.PrivateOff, domain.PrivateOn, domain.PrivateFreeze:
+	default:
+		p.invalid("SYNEST_PRIVATE", privateMode, "private mode disabled",
+			fmt.Errorf("must be %s, %s or %s", domain.PrivateOff, domain.PrivateOn, domain.PrivateFreeze))
+		privateMode = domain.PrivateOff
+	}
+	var privatePlayers []string
+	if file.Private.Players != nil {
+		privatePlayers = parseList(strings.Join(file.Private.Players, ","))
+	}
+	if value := os.Getenv("SYNEST_PRIVATE_PLAYERS"); value != "" {
+		privatePlayers = parseList(value)
+	}
+
+	// The slideshow is enabled by pointing it at a directory of images
+	slideshowDir := envOr("SYNEST_SLIDESHOW_DIR", file.Engine.SlideshowDir)
+	if slideshowDir != "" {
+		slideshowDir = expandPath(slideshowDir)
+	}
+	slideshowAfter := parseDurationEnv(p, "SYNEST_SLIDESHOW_AFTER", valueOr(file.Engine.SlideshowAfter, defaultSlideshowAfter))
+	slideshowInterval := parseDurationEnv(p, "SYNEST_SLIDESHOW_INTERVAL", valueOr(file.Engine.SlideshowInterval, defaultSlideshowInterval))
+	if slideshowInterval == 0 {
+		p.invalid("SYNEST_SLIDESHOW_INTERVAL", "0", "using default", errors.New("must be positive"))
+		slideshowInterval = defaultSlideshowInterval
+	}
+
+	p.logger.Info("Configuration loaded",
+		zap.String("configFile", configFile),
 		zap.String("outputDir", outputDir),
-		zap.String("mode", mode))
+		zap.String("stateDir", stateDir),
+		zap.String("mode", mode),
+		zap.Bool("deterministic", deterministic),
+		zap.Float64("blurRadius", blurRadius),
+		zap.Float64("coverSize", coverSize),
+		zap.Int("jpegQuality", jpegQuality),
+		zap.Bool("coverThumbnails", coverThumbnails),
+		zap.String("backgroundFilter", backgroundFilter),
+		zap.String("coverFilter", coverFilter),
+		zap.String("coverAspect", coverAspect),
+		zap.Bool("trimBorders", trimBorders),
+		zap.Int("trimTolerance", trimTolerance),
+		zap.String("playerBadge", playerBadge),
+		zap.Int("playerBadgeSize", playerBadgeSize),
+		zap.String("scene", scene),
+		zap.Duration("fetchTimeout", fetchTimeout),
+		zap.Bool("artCache", artCache.Enabled),
+		zap.Duration("artCacheTTL", artCache.TTL),
+		zap.Int64("artCacheMaxSize", artCache.MaxSize),
+		zap.String("onPause", pauseBehavior),
+		zap.String("onQuit", quitBehavior),
+		zap.Int("quietWindows", len(quietHours)),
+		zap.String("quietBehavior", quietBehavior),
+		zap.String("batterySaver", batterySaver),
+		zap.Duration("batteryDebounce", batteryDebounce),
+		zap.Bool("batteryCheapRender", batteryCheapRender),
+		zap.String("metered", metered),
+		zap.Int("historyMaxCount", historyMaxCount),
+		zap.Int("historyMaxSizeMB", historyMaxSizeMB),
+		zap.Int("preFetchHooks", len(preFetchHooks)),
+		zap.Int("postProcessHooks", len(postProcessHooks)),
+		zap.String("monitor", monitorBackend),
+		zap.String("monitorPlayer", monitorPlayer),
+		zap.String("monitorRecord", monitorRecord),
+		zap.String("monitorReplay", monitorReplay),
+		zap.Int("replaySpeed", replaySpeed),
+		zap.String("mpdAddress", mpd.Address),
+		zap.String("mpdMusicDir", mpd.MusicDir),
+		zap.String("setter", setter),
+		zap.String("customCommand", customCommand),
+		zap.String("delivery", delivery),
+		zap.String("multiDisplay", multiDisplay),
+		zap.String("transition", transition.Type),
+		zap.String("transitionPos", transition.Position),
+		zap.Duration("transitionDuration", transition.Duration),
+		zap.String("upload", upload.Kind),
+		zap.String("uploadURL", upload.URL),
+		zap.String("phonePush", phonePush.Kind),
+		zap.Float64("phoneAspect", phonePush.Aspect),
+		zap.String("einkCommand", eink.Command),
+		zap.Int("einkWidth", eink.Width),
+		zap.Int("einkHeight", eink.Height),
+		zap.Int("einkLevels", eink.Levels),
+		zap.String("private", privateMode),
+		zap.String("playerPolicy", playerPolicy),
+		zap.String("playerPin", playerPin),
+		zap.Duration("heartbeat", heartbeat),
+		zap.Duration("restartGrace", restartGrace),
+		zap.Duration("positionInterval", positionInterval),
+		zap.Duration("readyTimeout", readyTimeout),
+		zap.Duration("rotateAfter", rotateAfter),
+		zap.String("slideshowDir", slideshowDir),
+		zap.Duration("debounce", debounce),
+		zap.String("debounceStrategy", debounceStrategy),
+		zap.Int("debounceBurst", debounceBurst),
+		zap.Duration("debounceBrowsing", debounceBrowsing),
+		zap.Duration("minInterval", minInterval),
+		zap.Bool("dedup", dedup),
+		zap.Strings("skipKinds", skipKinds),
+		zap.Bool("spotify", spotifyClientID != "" && spotifyClientSecret != ""),
+		zap.Bool("spotifyAccount", spotifyRefresh != ""))
 
-	return &AppConfig{
-		logger:    logger,
-		outputDir: outputDir,
-		mode:      mode,
+	return &settings{
+		outputDir:           outputDir,
+		stateDir:            stateDir,
+		mode:                mode,
+		autoGenres:          autoGenres,
+		deterministic:       deterministic,
+		modeConfig:          modeConfig,
+		jpegQuality:         jpegQuality,
+		coverThumbnails:     coverThumbnails,
+		backgroundFilter:    backgroundFilter,
+		coverFilter:         coverFilter,
+		coverAspect:         coverAspect,
+		trimBorders:         trimBorders,
+		trimTolerance:       trimTolerance,
+		playerBadge:         playerBadge,
+		playerBadgeSize:     playerBadgeSize,
+		scene:               scene,
+		fetchTimeout:        fetchTimeout,
+		artCache:            artCache,
+		spotifyClientID:     spotifyClientID,
+		spotifyClientSecret: spotifyClientSecret,
+		spotifyRefresh:      spotifyRefresh,
+		spotifyPoll:         spotifyPoll,
+		playerQuirks:        playerQuirks,
+		artSettleDelays:     artSettleDelays,
+		normalizeRules:      normalizeRules,
+		artistSeparator:     artistSeparator,
+		playerAllow:         playerAllow,
+		playerDeny:          playerDeny,
+		playerPolicy:        playerPolicy,
+		playerPriority:      playerPriority,
+		playerPin:           playerPin,
+		debounce:            debounce,
+		debounceStrategy:    debounceStrategy,
+		debounceBurst:       debounceBurst,
+		debounceBrowsing:    debounceBrowsing,
+		minInterval:         minInterval,
+		dedup:               dedup,
+		skipKinds:           skipKinds,
+		notifyErrors:        notifyErrors,
+		onAppliedCommand:    onAppliedCommand,
+		customCommand:       customCommand,
+		setterMonitor:       setterMonitor,
+		pauseBehavior:       pauseBehavior,
+		quitBehavior:        quitBehavior,
+		quietHours:          quietHours,
+		quietBehavior:       quietBehavior,
+		batterySaver:        batterySaver,
+		batteryDebounce:     batteryDebounce,
+		batteryCheapRender:  batteryCheapRender,
+		metered:             metered,
+		historyMaxCount:     historyMaxCount,
+		preFetchHooks:       preFetchHooks,
+		postProcessHooks:    postProcessHooks,
+		historyMaxSize:      int64(historyMaxSizeMB) << 20,
+		monitorBackend:      monitorBackend,
+		monitorPlayer:       monitorPlayer,
+		monitorRecord:       monitorRecord,
+		monitorReplay:       monitorReplay,
+		replaySpeed:         replaySpeed,
+		mpd:                 mpd,
+		setter:              setter,
+		delivery:            delivery,
+		multiDisplay:        multiDisplay,
+		transition:          transition,
+		upload:              upload,
+		phonePush:           phonePush,
+		eink:                eink,
+		privateMode:         privateMode,
+		privatePlayers:      privatePlayers,
+		heartbeat:           heartbeat,
+		restartGrace:        restartGrace,
+		positionInterval:    positionInterval,
+		readyTimeout:        readyTimeout,
+		rotateAfter:         rotateAfter,
+		rotateInterval:      rotateInterval,
+		slideshowDir:        slideshowDir,
+		slideshowAfter:      slideshowAfter,
+		slideshowInterval:   slideshowInterval,
 	}
 }
 
 // GetMode returns the current wallpaper generation mode
 func (c *AppConfig) GetMode() string {
-	return c.mode
+	return c.current.Load().mode
+}
+
+// GetAutoGenres returns the per-genre mode overrides of the auto mode
+func (c *AppConfig) GetAutoGenres() map[string]string {
+	return c.current.Load().autoGenres
 }
 
 // GetOutputDir returns the directory for generated wallpapers
 func (c *AppConfig) GetOutputDir() string {
-	return c.outputDir
+	return c.current.Load().outputDir
+}
+
+// GetStateDir returns the directory for the daemon status
+func (c *AppConfig) GetStateDir() string {
+	return c.current.Load().stateDir
+}
+
+// GetReadyTimeout returns how long startup waits for external services
+func (c *AppConfig) GetReadyTimeout() time.Duration {
+	return c.current.Load().readyTimeout
+}
+
+// GetDeterministic reports whether rendering must be reproducible
+func (c *AppConfig) GetDeterministic() bool {
+	return c.current.Load().deterministic
+}
+
+// GetModeConfig returns the settings of every generation mode
+func (c *AppConfig) GetModeConfig() domain.ModeConfig {
+	return c.current.Load().modeConfig
+}
+
+// GetJPEGQuality returns the quality of the encoded wallpaper
+func (c *AppConfig) GetJPEGQuality() int {
+	return c.current.Load().jpegQuality
+}
+
+// GetCoverThumbnails reports whether cover thumbnails are exported
+func (c *AppConfig) GetCoverThumbnails() bool {
+	return c.current.Load().coverThumbnails
+}
+
+// GetBackgroundFilter returns the resampling filter of the background fill
+func (c *AppConfig) GetBackgroundFilter() string {
+	return c.current.Load().backgroundFilter
+}
+
+// GetCoverFilter returns the resampling filter of the sharp cover
+func (c *AppConfig) GetCoverFilter() string {
+	return c.current.Load().coverFilter
+}
+
+// GetCoverAspect returns how non-square artwork is shaped before compositing
+func (c *AppConfig) GetCoverAspect() string {
+	return c.current.Load().coverAspect
+}
+
+// GetTrimBorders reports whether solid borders are trimmed from the artwork
+func (c *AppConfig) GetTrimBorders() bool {
+	return c.current.Load().trimBorders
+}
+
+// GetTrimTolerance returns how far a border pixel may stray from the border color
+func (c *AppConfig) GetTrimTolerance() int {
+	return c.current.Load().trimTolerance
+}
+
+// GetPlayerBadge returns the corner showing the icon of the player, or off
+func (c *AppConfig) GetPlayerBadge() string {
+	return c.current.Load().playerBadge
+}
+
+// GetPlayerBadgeSize returns the size of the player icon badge in pixels
+func (c *AppConfig) GetPlayerBadgeSize() int {
+	return c.current.Load().playerBadgeSize
+}
+
+// GetScene returns whether the scene description of the composition is written
+func (c *AppConfig) GetScene() string {
+	return c.current.Load().scene
+}
+
+// GetFetchTimeout returns the timeout for artwork downloads
+func (c *AppConfig) GetFetchTimeout() time.Duration {
+	return c.current.Load().fetchTimeout
+}
+
+// GetArtCache returns the on-disk cache of downloaded artwork
+func (c *AppConfig) GetArtCache() domain.ArtCache {
+	return c.current.Load().artCache
+}
+
+// GetSpotifyCredentials returns the Spotify API client ID and secret
+func (c *AppConfig) GetSpotifyCredentials() (clientID, clientSecret string) {
+	current := c.current.Load()
+	return current.spotifyClientID, current.spotifyClientSecret
+}
+
+// GetSpotifyRefreshToken returns the OAuth refresh token of the followed Spotify account
+func (c *AppConfig) GetSpotifyRefreshToken() string {
+	return c.current.Load().spotifyRefresh
+}
+
+// GetSpotifyPollInterval returns how often the spotify monitor backend polls
+func (c *AppConfig) GetSpotifyPollInterval() time.Duration {
+	return c.current.Load().spotifyPoll
+}
+
+// GetPlayerQuirks returns the per-player quirks adapter overrides
+func (c *AppConfig) GetPlayerQuirks() map[string]string {
+	return c.current.Load().playerQuirks
+}
+
+// GetArtSettleDelays returns the per-player metadata settle delay overrides
+func (c *AppConfig) GetArtSettleDelays() map[string]time.Duration {
+	return c.current.Load().artSettleDelays
+}
+
+// GetNormalizeRules returns the configured text normalization rules
+func (c *AppConfig) GetNormalizeRules() []string {
+	return c.current.Load().normalizeRules
+}
+
+// GetArtistSeparator returns the separator placed between artists in captions
+func (c *AppConfig) GetArtistSeparator() string {
+	return c.current.Load().artistSeparator
+}
+
+// GetPlayerAllow returns the patterns of players to follow
+func (c *AppConfig) GetPlayerAllow() []string {
+	return c.current.Load().playerAllow
+}
+
+// GetPlayerDeny returns the patterns of players to ignore
+func (c *AppConfig) GetPlayerDeny() []string {
+	return c.current.Load().playerDeny
+}
+
+// GetPlayerPolicy returns how the driving player is chosen among playing ones
+func (c *AppConfig) GetPlayerPolicy() string {
+	return c.current.Load().playerPolicy
+}
+
+// GetPlayerPriority returns the player patterns ranked by the priority policy
+func (c *AppConfig) GetPlayerPriority() []string {
+	return c.current.Load().playerPriority
+}
+
+// GetPlayerPin returns the pattern of the pinned player, empty when none is pinned
+func (c *AppConfig) GetPlayerPin() string {
+	return c.current.Load().playerPin
+}
+
+// GetDebounce returns the quiet period required before updating the wallpaper
+func (c *AppConfig) GetDebounce() time.Duration {
+	return c.current.Load().debounce
+}
+
+// GetDebounceBrowsing returns the debounce used while the user skips through tracks
+func (c *AppConfig) GetDebounceBrowsing() time.Duration {
+	return c.current.Load().debounceBrowsing
+}
+
+// GetMinInterval returns the minimum time between two wallpaper updates
+func (c *AppConfig) GetMinInterval() time.Duration {
+	return c.current.Load().minInterval
+}
+
+// GetDebounceStrategy returns how bursts of events are debounced
+func (c *AppConfig) GetDebounceStrategy() string {
+	return c.current.Load().debounceStrategy
+}
+
+// GetDebounceBurst returns how many updates the token bucket allows in a row
+func (c *AppConfig) GetDebounceBurst() int {
+	return c.current.Load().debounceBurst
+}
+
+// GetDedup reports whether updates for the track already on screen are skipped
+func (c *AppConfig) GetDedup() bool {
+	return c.current.Load().dedup
+}
+
+// GetSkipKinds returns the media kinds that never update the wallpaper
+func (c *AppConfig) GetSkipKinds() []string {
+	return c.current.Load().skipKinds
+}
+
+// GetNotifyErrors reports whether repeated failures trigger a desktop notification
+func (c *AppConfig) GetNotifyErrors() bool {
+	return c.current.Load().notifyErrors
+}
+
+// GetOnAppliedCommand returns the shell command run after a verified wallpaper change
+func (c *AppConfig) GetOnAppliedCommand() string {
+	return c.current.Load().onAppliedCommand
+}
+
+// GetPauseBehavior returns what happens to the wallpaper while paused
+func (c *AppConfig) GetPauseBehavior() string {
+	return c.current.Load().pauseBehavior
+}
+
+// GetQuitBehavior returns what happens to the wallpaper once the last player quits
+func (c *AppConfig) GetQuitBehavior() string {
+	return c.current.Load().quitBehavior
+}
+
+// GetMonitorBackend returns the name of the monitor backend
+func (c *AppConfig) GetMonitorBackend() string {
+	return c.current.Load().monitorBackend
+}
+
+// GetMonitorPlayer returns the bus name of the single MPRIS player subscribed to
+func (c *AppConfig) GetMonitorPlayer() string {
+	return c.current.Load().monitorPlayer
+}
+
+// GetMonitorRecord returns the file every monitor event is appended to, empty for none
+func (c *AppConfig) GetMonitorRecord() string {
+	return c.current.Load().monitorRecord
+}
+
+// GetMonitorReplay returns the recording played back by the replay backend
+func (c *AppConfig) GetMonitorReplay() string {
+	return c.current.Load().monitorReplay
+}
+
+// GetReplaySpeed returns how many times faster than recorded events are played back, 0 without waiting
+func (c *AppConfig) GetReplaySpeed() int {
+	return c.current.Load().replaySpeed
+}
+
+// GetMPD returns the server followed by the mpd backend
+func (c *AppConfig) GetMPD() domain.MPD {
+	return c.current.Load().mpd
+}
+
+// GetSetter returns the forced wallpaper setter, or "auto"
+func (c *AppConfig) GetSetter() string {
+	return c.current.Load().setter
+}
+
+// GetCustomCommand returns the user-defined setter command template
+func (c *AppConfig) GetCustomCommand() string {
+	return c.current.Load().customCommand
+}
+
+// GetSetterMonitor returns the output substituted for {monitor} in the custom command
+func (c *AppConfig) GetSetterMonitor() string {
+	return c.current.Load().setterMonitor
+}
+
+// GetDelivery returns how wallpapers reach the setter
+func (c *AppConfig) GetDelivery() string {
+	return c.current.Load().delivery
+}
+
+// GetQuietHours returns the daily windows during which the wallpaper is quiet
+func (c *AppConfig) GetQuietHours() []domain.QuietWindow {
+	return c.current.Load().quietHours
+}
+
+// GetQuietBehavior returns what happens during quiet hours
+func (c *AppConfig) GetQuietBehavior() string {
+	return c.current.Load().quietBehavior
+}
+
+// GetBatterySaver returns when the battery saver applies
+func (c *AppConfig) GetBatterySaver() string {
+	return c.current.Load().batterySaver
+}
+
+// GetBatteryDebounce returns the debounce period while saving power
+func (c *AppConfig) GetBatteryDebounce() time.Duration {
+	return c.current.Load().batteryDebounce
+}
+
+// GetBatteryCheapRender returns whether rendering takes cheaper paths while saving power
+func (c *AppConfig) GetBatteryCheapRender() bool {
+	return c.current.Load().batteryCheapRender
+}
+
+// GetMetered returns when downloads are kept to a minimum
+func (c *AppConfig) GetMetered() string {
+	return c.current.Load().metered
+}
+
+// GetPreFetchHooks returns the shell commands rewriting the artwork URL, in order
+func (c *AppConfig) GetPreFetchHooks() []string {
+	return c.current.Load().preFetchHooks
+}
+
+// GetPostProcessHooks returns the shell commands transforming the wallpaper image, in order
+func (c *AppConfig) GetPostProcessHooks() []string {
+	return c.current.Load().postProcessHooks
+}
+
+// GetHistoryMaxCount returns how many generated wallpapers are kept in the history
+func (c *AppConfig) GetHistoryMaxCount() int {
+	return c.current.Load().historyMaxCount
+}
+
+// GetHistoryMaxSize returns the total size the history may take, in bytes
+func (c *AppConfig) GetHistoryMaxSize() int64 {
+	return c.current.Load().historyMaxSize
+}
+
+// GetMultiDisplay returns how the wallpaper is laid out across several displays
+func (c *AppConfig) GetMultiDisplay() string {
+	return c.current.Load().multiDisplay
+}
+
+// GetTransition returns the animation played by the setter on wallpaper changes
+func (c *AppConfig) GetTransition() domain.Transition {
+	return c.current.Load().transition
+}
+
+// GetUpload returns the remote storage wallpapers are uploaded to
+func (c *AppConfig) GetUpload() domain.Upload {
+	return c.current.Load().upload
+}
+
+// GetPhonePush returns how wallpapers are pushed to a phone
+func (c *AppConfig) GetPhonePush() domain.PhonePush {
+	return c.current.Load().phonePush
+}
+
+// GetEInk returns the secondary display wallpapers are mirrored to
+func (c *AppConfig) GetEInk() domain.EInk {
+	return c.current.Load().eink
+}
+
+// GetPrivateMode returns the private mode switched at runtime, or else the configured one
+func (c *AppConfig) GetPrivateMode() string {
+	if mode := c.private.Load(); mode != nil {
+		return *mode
+	}
+	return c.current.Load().privateMode
+}
+
+// SetPrivateMode implements domain.PrivateSwitch. The mode lasts until the
+// daemon restarts or the private mode in the configuration changes.
+func (c *AppConfig) SetPrivateMode(mode string) error {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	switch mode {
+	case domain.PrivateOff, domain.PrivateOn, domain.PrivateFreeze:
+	default:
+		return fmt.Errorf("unknown private mode %q (available: %s, %s, %s)", mode, domain.PrivateOff, domain.PrivateOn, domain.PrivateFreeze)
+	}
+	c.private.Store(&mode)
+	return nil
+}
+
+// GetPrivatePlayers returns the players private mode applies to (empty = all)
+func (c *AppConfig) GetPrivatePlayers() []string {
+	return c.current.Load().privatePlayers
+}
+
+// GetHeartbeatInterval returns how often the active player is probed (0 = never)
+func (c *AppConfig) GetHeartbeatInterval() time.Duration {
+	return c.current.Load().heartbeat
+}
+
+// GetRestartGrace returns how long a vanished player may take to reappear
+func (c *AppConfig) GetRestartGrace() time.Duration {
+	return c.current.Load().restartGrace
+}
+
+// GetPositionInterval returns how often the playback position is polled (0 = never)
+func (c *AppConfig) GetPositionInterval() time.Duration {
+	return c.current.Load().positionInterval
+}
+
+// GetRotateAfter returns the track length from which the wallpaper rotates (0 = never)
+func (c *AppConfig) GetRotateAfter() time.Duration {
+	return c.current.Load().rotateAfter
+}
+
+// GetRotateInterval returns how long each variation stays on screen
+func (c *AppConfig) GetRotateInterval() time.Duration {
+	return c.current.Load().rotateInterval
+}
+
+// GetSlideshowDir returns the directory cycled through while idle (empty = disabled)
+func (c *AppConfig) GetSlideshowDir() string {
+	return c.current.Load().slideshowDir
+}
+
+// GetSlideshowAfter returns how long nothing must play before the slideshow starts
+func (c *AppConfig) GetSlideshowAfter() time.Duration {
+	return c.current.Load().slideshowAfter
+}
+
+// GetSlideshowInterval returns how long each slideshow image stays on screen
+func (c *AppConfig) GetSlideshowInterval() time.Duration {
+	return c.current.Load().slideshowInterval
+}
+
+// expandPath expands environment variables and a leading ~ in a path
+func expandPath(path string) string {
+	path = os.ExpandEnv(path)
+	if len(path) > 0 && path[0] == '~' {
+		home, err := os.UserHomeDir()
+		if err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// envOr returns the value of an environment variable, or def when it is unset
+func envOr(name, def string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return def
+}
+
+// parseBoolEnv reads a boolean from an environment variable,
+// falling back to def when it is unset or invalid
+func parseBoolEnv(p *problems, name string, def bool) bool {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		p.invalid(name, value, "ignoring", errors.New("must be true or false"))
+		return def
+	}
+	return parsed
+}
+
+// parseDurationEnv reads a non-negative duration from an environment variable,
+// falling back to def when it is unset or invalid
+func parseDurationEnv(p *problems, name string, def time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed < 0 {
+		p.invalid(name, value, "using default "+def.String(), errors.New("must be a non-negative duration such as 30s"))
+		return def
+	}
+	return parsed
+}
+
+// parseIntEnv reads an integer in [min, max] from an environment variable,
+// falling back to def when it is unset or invalid
+func parseIntEnv(p *problems, name string, def, min, max int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < min || parsed > max {
+		p.invalid(name, value, "using default "+strconv.Itoa(def), fmt.Errorf("must be an integer in [%d, %d]", min, max))
+		return def
+	}
+	return parsed
+}
+
+// parseFilterEnv reads a resampling filter from an environment variable,
+// falling back to def when it is unset or unknown
+func parseFilterEnv(p *problems, name, def string) string {
+	value := strings.ToLower(strings.TrimSpace(os.Getenv(name)))
+	if value == "" {
+		return strings.ToLower(def)
+	}
+	if !slices.Contains(domain.Filters, value) {
+		p.invalid(name, value, "using "+def, fmt.Errorf("must be one of %s", strings.Join(domain.Filters, ", ")))
+		return strings.ToLower(def)
+	}
+	return value
+}
+
+// parseList splits a comma-separated list, lowercasing entries and skipping empty ones
+func parseList(value string) []string {
+	result := []string{}
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.ToLower(strings.TrimSpace(entry)); entry != "" {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// parseQuietWindows parses daily windows written as "22:00-08:00"
+func parseQuietWindows(values []string) ([]domain.QuietWindow, error) {
+	var windows []domain.QuietWindow
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		start, end, ok := strings.Cut(value, "-")
+		if !ok {
+			return nil, fmt.Errorf("quiet window %q must look like 22:00-08:00", value)
+		}
+		from, err := parseClock(start)
+		if err != nil {
+			return nil, fmt.Errorf("quiet window %q: %w", value, err)
+		}
+		to, err := parseClock(end)
+		if err != nil {
+			return nil, fmt.Errorf("quiet window %q: %w", value, err)
+		}
+		if from == to {
+			return nil, fmt.Errorf("quiet window %q is empty", value)
+		}
+		windows = append(windows, domain.QuietWindow{Start: from, End: to})
+	}
+	return windows, nil
+}
+
+// parseAspect parses an aspect ratio written as "9:19.5" into width over height,
+// an empty value is 0
+func parseAspect(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	w, h, ok := strings.Cut(value, ":")
+	if !ok {
+		return 0, fmt.Errorf("aspect %q must look like 9:19.5", value)
+	}
+	width, errW := strconv.ParseFloat(strings.TrimSpace(w), 64)
+	height, errH := strconv.ParseFloat(strings.TrimSpace(h), 64)
+	if errW != nil || errH != nil || !(width > 0) || !(height > 0) || math.IsInf(width, 0) || math.IsInf(height, 0) {
+		return 0, fmt.Errorf("aspect %q must be two positive numbers", value)
+	}
+	return width / height, nil
+}
+
+// parseClock parses a time of day written as "HH:MM" into minutes since midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, use HH:MM", strings.TrimSpace(value))
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// parsePatternsEnv reads a comma-separated list of player patterns, keeping
+// their case. Invalid patterns fall back to def.
+func parsePatternsEnv(p *problems, name string, def []string) []string {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	patterns := []string{}
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			patterns = append(patterns, entry)
+		}
+	}
+	if err := players.Validate(patterns); err != nil {
+		p.invalid(name, value, "using the config file value", err)
+		return def
+	}
+	return patterns
+}
+
+// parseRules parses a comma-separated list of normalization rules,
+// where "none" disables normalization
+func parseRules(value string) []string {
+	rules := parseList(value)
+	if len(rules) == 1 && rules[0] == "none" {
+		return []string{}
+	}
+	return rules
+}
+
+// lowercaseKeys copies a per-player map from the config file, lowercasing
+// player names and converting values with fn. nil stays nil.
+func lowercaseKeys[T any](values map[string]T, fn func(T) T) map[string]T {
+	if values == nil {
+		return nil
+	}
+	result := make(map[string]T, len(values))
+	for player, value := range values {
+		result[strings.ToLower(strings.TrimSpace(player))] = fn(value)
+	}
+	return result
+}
+
+// parsePlayerValues parses a comma-separated list of player=value pairs,
+// e.g. "chromium=firefox,vlc=none". Player names and values are lowercased.
+func parsePlayerValues(value string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		player, playerValue, ok := strings.Cut(entry, "=")
+		player = strings.ToLower(strings.TrimSpace(player))
+		playerValue = strings.ToLower(strings.TrimSpace(playerValue))
+		if !ok || player == "" || playerValue == "" {
+			return nil, fmt.Errorf("invalid entry %q, expected player=value", entry)
+		}
+		result[player] = playerValue
+	}
+	return result, nil
+}
+
+// parsePlayerDurations parses a comma-separated list of player=duration pairs,
+// e.g. "spotify=1s,vlc=250ms"
+func parsePlayerDurations(value string) (map[string]time.Duration, error) {
+	values, err := parsePlayerValues(value)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]time.Duration, len(values))
+	for player, rawDuration := range values {
+		duration, err := time.ParseDuration(rawDuration)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration for %s: %w", player, err)
+		}
+		if duration < 0 {
+			return nil, fmt.Errorf("negative duration for %s", player)
+		}
+		result[player] = duration
+	}
+	return result, nil
 }
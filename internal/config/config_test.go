@@ -0,0 +1,119 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+func TestParsePlayerDurations(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expected      map[string]time.Duration
+		expectedError string
+	}{
+		{
+			name:     "Single Player",
+			input:    "spotify=1s",
+			expected: map[string]time.Duration{"spotify": time.Second},
+		},
+		{
+			name:     "Multiple Players With Spaces",
+			input:    " Spotify = 1500ms , vlc=0 ,",
+			expected: map[string]time.Duration{"spotify": 1500 * time.Millisecond, "vlc": 0},
+		},
+		{
+			name:          "Error - Missing Duration",
+			input:         "spotify",
+			expectedError: "expected player=value",
+		},
+		{
+			name:          "Error - Invalid Duration",
+			input:         "spotify=soon",
+			expectedError: "invalid duration for spotify",
+		},
+		{
+			name:          "Error - Negative Duration",
+			input:         "spotify=-1s",
+			expectedError: "negative duration",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parsePlayerDurations(tt.input)
+
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing '%s', got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(result) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, result)
+			}
+			for player, delay := range tt.expected {
+				if result[player] != delay {
+					t.Errorf("%s: expected %v, got %v", player, delay, result[player])
+				}
+			}
+		})
+	}
+}
+
+func TestParsePlayerValues(t *testing.T) {
+	result, err := parsePlayerValues("Chromium=Firefox, vlc = none")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result["chromium"] != "firefox" || result["vlc"] != "none" || len(result) != 2 {
+		t.Errorf("unexpected result: %v", result)
+	}
+
+	if _, err := parsePlayerValues("spotify="); err == nil {
+		t.Error("expected error for empty value")
+	}
+}
+
+func TestParseList(t *testing.T) {
+	result := parseList(" Channel, ,remaster,")
+	if len(result) != 2 || result[0] != "channel" || result[1] != "remaster" {
+		t.Errorf("unexpected result: %v", result)
+	}
+}
+
+func TestParseAspect(t *testing.T) {
+	for value, want := range map[string]float64{"": 0, "9:19.5": 9 / 19.5, " 3 : 4 ": 0.75} {
+		if got, err := parseAspect(value); err != nil || got != want {
+			t.Errorf("parseAspect(%q) = %v, %v; expected %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"9x16", "0:16", "9:-16", "a:b"} {
+		if _, err := parseAspect(value); err == nil {
+			t.Errorf("expected error for %q", value)
+		}
+	}
+}
+
+func TestParseQuietWindows(t *testing.T) {
+	result, err := parseQuietWindows([]string{"22:00-08:00", " 12:30 - 13:00 "})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != 2 || result[0] != (domain.QuietWindow{Start: 1320, End: 480}) || result[1] != (domain.QuietWindow{Start: 750, End: 780}) {
+		t.Errorf("unexpected result: %v", result)
+	}
+
+	for _, value := range []string{"22:00", "25:00-08:00", "08:00-08:00"} {
+		if _, err := parseQuietWindows([]string{value}); err == nil {
+			t.Errorf("expected error for %q", value)
+		}
+	}
+}
@@ -0,0 +1,585 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/monitor/players"
+	"github.com/genricoloni/synest/internal/paths"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the config file inside the synest config directory
+const FileName = "config.yaml"
+
+// Upper bounds of rendering settings, larger values only slow rendering down
+const (
+	maxBlurRadius    = 100
+	maxParticles     = 20000
+	maxShapes        = 100
+	maxWaveformBars  = 2000
+	maxModeHeight    = 8640 // Render height cap of a mode, 16K
+	maxBurst         = 100
+	maxHistory       = 1000
+	maxHistorySize   = 100_000 // MiB
+	maxTrimTolerance = 255     // Channel levels, any color counts as border
+	minBadgeSize     = 16      // Pixels
+	maxBadgeSize     = 512
+	maxReplaySpeed   = 1000    // Times faster than recorded
+	maxArtCacheSize  = 100_000 // MiB
+	minEInkSize      = 16      // Pixels, per side
+	maxEInkSize      = 4096
+	minEInkLevels    = 2 // Black and white
+	maxEInkLevels    = 256
+)
+
+// fileConfig mirrors the config file. Pointer and empty values mean the option
+// is not set in the file, so the built-in default applies.
+type fileConfig struct {
+	OutputDir     string `yaml:"output_dir"`
+	StateDir      string `yaml:"state_dir"`
+	Mode          string `yaml:"mode"`
+	Deterministic *bool  `yaml:"deterministic"`
+	NotifyErrors  *bool  `yaml:"notify_errors"`
+
+	Auto struct {
+		Genres map[string]string `yaml:"genres"`
+	} `yaml:"auto"`
+
+	// ReadyTimeout bounds the wait for external services at startup
+	ReadyTimeout *time.Duration `yaml:"ready_timeout"`
+
+	// Disable lists optional subsystems to leave out, read once at startup
+	Disable []string `yaml:"disable"`
+
+	// Log configures the logger, read once at startup
+	Log struct {
+		Level      string `yaml:"level"`
+		Format     string `yaml:"format"`
+		File       string `yaml:"file"`
+		MaxSizeMB  *int   `yaml:"max_size_mb"`
+		MaxBackups *int   `yaml:"max_backups"`
+	} `yaml:"log"`
+
+	// QuietHours are daily windows during which the wallpaper stays put or dims
+	QuietHours struct {
+		Windows  []string `yaml:"windows"`
+		Behavior string   `yaml:"behavior"`
+	} `yaml:"quiet_hours"`
+
+	// Battery holds the rules applied while saving power
+	Battery struct {
+		Saver       string         `yaml:"saver"`
+		Debounce    *time.Duration `yaml:"debounce"`
+		CheapRender *bool          `yaml:"cheap_render"`
+	} `yaml:"battery"`
+
+	// Network holds the rules applied on metered connections
+	Network struct {
+		Metered string `yaml:"metered"`
+	} `yaml:"network"`
+
+	// Hooks are shell commands run in order inside the pipeline
+	Hooks struct {
+		PreFetch    []string `yaml:"pre_fetch"`
+		PostProcess []string `yaml:"post_process"`
+	} `yaml:"hooks"`
+
+	// Upload mirrors each wallpaper put on screen to remote storage
+	Upload struct {
+		Kind   string `yaml:"kind"`
+		URL    string `yaml:"url"`
+		Region string `yaml:"region"`
+		User   string `yaml:"user"`
+		Secret string `yaml:"secret"`
+	} `yaml:"upload"`
+
+	// Phone pushes each wallpaper put on screen to a phone
+	Phone struct {
+		Push   string `yaml:"push"`
+		Target string `yaml:"target"`
+		Token  string `yaml:"token"`
+		Aspect string `yaml:"aspect"`
+	} `yaml:"phone"`
+
+	// History keeps the last generated wallpapers instead of overwriting them
+	History struct {
+		MaxCount  *int `yaml:"max_count"`
+		MaxSizeMB *int `yaml:"max_size_mb"`
+	} `yaml:"history"`
+
+	Private struct {
+		Mode    string   `yaml:"mode"`
+		Players []string `yaml:"players"`
+	} `yaml:"private"`
+
+	Processor struct {
+		BlurRadius      *float64 `yaml:"blur_radius"`
+		CoverSize       *float64 `yaml:"cover_size"`
+		JPEGQuality     *int     `yaml:"jpeg_quality"`
+		CoverThumbnails *bool    `yaml:"cover_thumbnails"`
+
+		// Resampling filters, per scaling stage
+		BackgroundFilter string `yaml:"background_filter"`
+		CoverFilter      string `yaml:"cover_filter"`
+
+		// CoverAspect shapes non-square artwork: preserve, square or trim
+		CoverAspect string `yaml:"cover_aspect"`
+
+		// Solid borders baked into the artwork, trimmed before scaling
+		TrimBorders   *bool `yaml:"trim_borders"`
+		TrimTolerance *int  `yaml:"trim_tolerance"`
+
+		// PlayerBadge is the corner showing the player icon: off, top-left, top-right, bottom-left or bottom-right
+		PlayerBadge     string `yaml:"player_badge"`
+		PlayerBadgeSize *int   `yaml:"player_badge_size"`
+
+		// Scene writes a JSON description of the composition: off, alongside or only
+		Scene string `yaml:"scene"`
+	} `yaml:"processor"`
+
+	// Modes holds per-mode settings, overriding the processor section for that mode
+	Modes struct {
+		Blur struct {
+			BlurRadius *float64 `yaml:"blur_radius"`
+			CoverSize  *float64 `yaml:"cover_size"`
+			MaxHeight  *int     `yaml:"max_height"`
+		} `yaml:"blur"`
+		Generative struct {
+			Particles *int `yaml:"particles"`
+			Shapes    *int `yaml:"shapes"`
+			MaxHeight *int `yaml:"max_height"`
+		} `yaml:"generative"`
+		Waveform struct {
+			Bars       *int     `yaml:"bars"`
+			Height     *float64 `yaml:"height"`
+			BlurRadius *float64 `yaml:"blur_radius"`
+			CoverSize  *float64 `yaml:"cover_size"`
+			MaxHeight  *int     `yaml:"max_height"`
+		} `yaml:"waveform"`
+	} `yaml:"modes"`
+
+	Fetcher struct {
+		Timeout        *time.Duration `yaml:"timeout"`
+		Cache          *bool          `yaml:"cache"`
+		CacheTTL       *time.Duration `yaml:"cache_ttl"`
+		CacheMaxSizeMB *int           `yaml:"cache_max_size_mb"`
+	} `yaml:"fetcher"`
+
+	Executor struct {
+		Backend   string `yaml:"backend"`
+		Setter    string `yaml:"setter"` // Older name of backend
+		OnApplied string `yaml:"on_applied"`
+		Delivery  string `yaml:"delivery"`
+
+		MultiDisplay string `yaml:"multi_display"`
+
+		CustomCommand string `yaml:"custom_command"`
+		Monitor       string `yaml:"monitor"`
+
+		Transition struct {
+			Type     string         `yaml:"type"`
+			Position string         `yaml:"position"`
+			Duration *time.Duration `yaml:"duration"`
+		} `yaml:"transition"`
+
+		EInk struct {
+			Command string `yaml:"command"`
+			Width   *int   `yaml:"width"`
+			Height  *int   `yaml:"height"`
+			Levels  *int   `yaml:"levels"`
+		} `yaml:"eink"`
+	} `yaml:"executor"`
+
+	Players struct {
+		Allow    []string `yaml:"allow"`
+		Deny     []string `yaml:"deny"`
+		Policy   string   `yaml:"policy"`
+		Priority []string `yaml:"priority"`
+		Pin      string   `yaml:"pin"`
+	} `yaml:"players"`
+
+	Monitor struct {
+		Backend          string                   `yaml:"backend"`
+		Player           string                   `yaml:"player"`
+		Heartbeat        *time.Duration           `yaml:"heartbeat"`
+		RestartGrace     *time.Duration           `yaml:"restart_grace"`
+		PositionInterval *time.Duration           `yaml:"position_interval"`
+		PlayerQuirks     map[string]string        `yaml:"player_quirks"`
+		ArtSettleDelays  map[string]time.Duration `yaml:"art_settle_delays"`
+		Normalize        []string                 `yaml:"normalize"`
+		ArtistSeparator  *string                  `yaml:"artist_separator"`
+
+		// Server followed by the mpd backend
+		MPD struct {
+			Address  string `yaml:"address"`
+			Password string `yaml:"password"`
+			MusicDir string `yaml:"music_dir"`
+		} `yaml:"mpd"`
+
+		// Record appends every event to a JSONL file, the replay backend plays one back
+		Record      string `yaml:"record"`
+		Replay      string `yaml:"replay"`
+		ReplaySpeed *int   `yaml:"replay_speed"`
+	} `yaml:"monitor"`
+
+	Engine struct {
+		Debounce          *time.Duration `yaml:"debounce"`
+		DebounceStrategy  string         `yaml:"debounce_strategy"`
+		DebounceBurst     *int           `yaml:"debounce_burst"`
+		DebounceBrowsing  *time.Duration `yaml:"debounce_browsing"`
+		MinInterval       *time.Duration `yaml:"min_interval"`
+		Dedup             *bool          `yaml:"dedup"`
+		SkipKinds         []string       `yaml:"skip_kinds"`
+		OnPause           string         `yaml:"on_pause"`
+		OnQuit            string         `yaml:"on_quit"`
+		RotateAfter       *time.Duration `yaml:"rotate_after"`
+		RotateInterval    *time.Duration `yaml:"rotate_interval"`
+		SlideshowDir      string         `yaml:"slideshow_dir"`
+		SlideshowAfter    *time.Duration `yaml:"slideshow_after"`
+		SlideshowInterval *time.Duration `yaml:"slideshow_interval"`
+	} `yaml:"engine"`
+
+	Spotify struct {
+		ClientID     string         `yaml:"client_id"`
+		ClientSecret string         `yaml:"client_secret"`
+		RefreshToken string         `yaml:"refresh_token"`
+		PollInterval *time.Duration `yaml:"poll_interval"`
+	} `yaml:"spotify"`
+}
+
+// FilePath returns the config file location: SYNEST_CONFIG when set,
+// otherwise config.yaml in the synest config directory
+func FilePath() string {
+	if path := os.Getenv("SYNEST_CONFIG"); path != "" {
+		return expandPath(path)
+	}
+	return filepath.Join(paths.ConfigDir(), FileName)
+}
+
+// loadFile reads and validates the config file. A missing file is not an
+// error and yields an empty configuration.
+func loadFile(path string) (*fileConfig, error) {
+	file := &fileConfig{}
+	if path == "" {
+		return file, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// Unknown keys are rejected so typos do not silently fall back to defaults
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	if err := file.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file: %w", err)
+	}
+	return file, nil
+}
+
+// validate rejects values that are out of range
+func (f *fileConfig) validate() error {
+	durations := []struct {
+		name  string
+		value *time.Duration
+	}{
+		{"ready_timeout", f.ReadyTimeout},
+		{"fetcher.timeout", f.Fetcher.Timeout},
+		{"fetcher.cache_ttl", f.Fetcher.CacheTTL},
+		{"monitor.heartbeat", f.Monitor.Heartbeat},
+		{"monitor.restart_grace", f.Monitor.RestartGrace},
+		{"monitor.position_interval", f.Monitor.PositionInterval},
+		{"executor.transition.duration", f.Executor.Transition.Duration},
+		{"engine.debounce", f.Engine.Debounce},
+		{"engine.debounce_browsing", f.Engine.DebounceBrowsing},
+		{"engine.min_interval", f.Engine.MinInterval},
+		{"engine.rotate_after", f.Engine.RotateAfter},
+		{"engine.rotate_interval", f.Engine.RotateInterval},
+		{"engine.slideshow_after", f.Engine.SlideshowAfter},
+		{"engine.slideshow_interval", f.Engine.SlideshowInterval},
+		{"battery.debounce", f.Battery.Debounce},
+		{"spotify.poll_interval", f.Spotify.PollInterval},
+	}
+	for _, d := range durations {
+		if d.value != nil && *d.value < 0 {
+			return fmt.Errorf("%s must not be negative", d.name)
+		}
+	}
+	for player, delay := range f.Monitor.ArtSettleDelays {
+		if delay < 0 {
+			return fmt.Errorf("monitor.art_settle_delays: negative duration for %s", player)
+		}
+	}
+
+	if f.Executor.Backend != "" && f.Executor.Setter != "" && !strings.EqualFold(f.Executor.Backend, f.Executor.Setter) {
+		return fmt.Errorf("executor.backend and executor.setter disagree, set only executor.backend")
+	}
+
+	switch strings.ToLower(f.Engine.DebounceStrategy) {
+	case "", domain.DebounceTrailing, domain.DebounceLeading, domain.DebounceTokenBucket:
+	default:
+		return fmt.Errorf("engine.debounce_strategy must be %s, %s or %s",
+			domain.DebounceTrailing, domain.DebounceLeading, domain.DebounceTokenBucket)
+	}
+
+	if f.Log.Level != "" {
+		if _, err := zapcore.ParseLevel(f.Log.Level); err != nil {
+			return fmt.Errorf("log.level: %w", err)
+		}
+	}
+	switch strings.ToLower(f.Log.Format) {
+	case "", LogFormatJSON, LogFormatConsole:
+	default:
+		return fmt.Errorf("log.format must be %s or %s", LogFormatJSON, LogFormatConsole)
+	}
+
+	if _, err := parseQuietWindows(f.QuietHours.Windows); err != nil {
+		return fmt.Errorf("quiet_hours.windows: %w", err)
+	}
+	switch strings.ToLower(f.QuietHours.Behavior) {
+	case "", domain.QuietSkip, domain.QuietDim:
+	default:
+		return fmt.Errorf("quiet_hours.behavior must be %s or %s", domain.QuietSkip, domain.QuietDim)
+	}
+
+	switch strings.ToLower(f.Battery.Saver) {
+	case "", domain.BatterySaverAuto, domain.BatterySaverOn, domain.BatterySaverOff:
+	default:
+		return fmt.Errorf("battery.saver must be %s, %s or %s", domain.BatterySaverAuto, domain.BatterySaverOn, domain.BatterySaverOff)
+	}
+	switch strings.ToLower(f.Network.Metered) {
+	case "", domain.MeteredAuto, domain.MeteredOn, domain.MeteredOff:
+	default:
+		return fmt.Errorf("network.metered must be %s, %s or %s", domain.MeteredAuto, domain.MeteredOn, domain.MeteredOff)
+	}
+
+	switch strings.ToLower(f.Processor.Scene) {
+	case "", domain.SceneOff, domain.SceneAlongside, domain.SceneOnly:
+	default:
+		return fmt.Errorf("processor.scene must be %s, %s or %s", domain.SceneOff, domain.SceneAlongside, domain.SceneOnly)
+	}
+
+	switch strings.ToLower(f.Upload.Kind) {
+	case "", domain.UploadOff:
+	case domain.UploadS3, domain.UploadWebDAV:
+		if err := checkHTTPURL(f.Upload.URL); err != nil {
+			return fmt.Errorf("upload.url: %w", err)
+		}
+	default:
+		return fmt.Errorf("upload.kind must be %s, %s or %s", domain.UploadOff, domain.UploadS3, domain.UploadWebDAV)
+	}
+
+	switch strings.ToLower(f.Phone.Push) {
+	case "", domain.PushOff:
+	case domain.PushKDEConnect:
+		if strings.TrimSpace(f.Phone.Target) == "" {
+			return fmt.Errorf("phone.target must name the KDE Connect device")
+		}
+	case domain.PushNtfy:
+		if err := checkHTTPURL(f.Phone.Target); err != nil {
+			return fmt.Errorf("phone.target: %w", err)
+		}
+	default:
+		return fmt.Errorf("phone.push must be %s, %s or %s", domain.PushOff, domain.PushKDEConnect, domain.PushNtfy)
+	}
+	if _, err := parseAspect(f.Phone.Aspect); err != nil {
+		return fmt.Errorf("phone.aspect: %w", err)
+	}
+
+	switch strings.ToLower(f.Engine.OnQuit) {
+	case "", domain.QuitKeep, domain.QuitRestore:
+	default:
+		return fmt.Errorf("engine.on_quit must be %s or %s", domain.QuitKeep, domain.QuitRestore)
+	}
+
+	for _, kind := range f.Engine.SkipKinds {
+		switch strings.ToLower(strings.TrimSpace(kind)) {
+		case domain.KindAd, domain.KindPodcast:
+		default:
+			return fmt.Errorf("engine.skip_kinds: %q must be %s or %s", kind, domain.KindAd, domain.KindPodcast)
+		}
+	}
+
+	switch strings.ToLower(f.Private.Mode) {
+	case "", domain.PrivateOff, domain.PrivateOn, domain.PrivateFreeze:
+	default:
+		return fmt.Errorf("private.mode must be off, on or freeze")
+	}
+
+	for _, chain := range []struct {
+		name     string
+		commands []string
+	}{
+		{"hooks.pre_fetch", f.Hooks.PreFetch},
+		{"hooks.post_process", f.Hooks.PostProcess},
+	} {
+		if slices.ContainsFunc(chain.commands, func(command string) bool { return strings.TrimSpace(command) == "" }) {
+			return fmt.Errorf("%s: empty command", chain.name)
+		}
+	}
+
+	if err := validateGenreModes(f.Auto.Genres); err != nil {
+		return fmt.Errorf("auto.genres: %w", err)
+	}
+
+	if _, err := players.NewFilter(f.Players.Allow, f.Players.Deny); err != nil {
+		return fmt.Errorf("players: %w", err)
+	}
+	switch strings.ToLower(f.Players.Policy) {
+	case "", domain.PlayerPolicyRecent, domain.PlayerPolicyPriority:
+	default:
+		return fmt.Errorf("players.policy must be %s or %s", domain.PlayerPolicyRecent, domain.PlayerPolicyPriority)
+	}
+	if _, err := players.NewPriority(f.Players.Priority); err != nil {
+		return fmt.Errorf("players.priority: %w", err)
+	}
+	if err := players.Validate([]string{f.Players.Pin}); err != nil {
+		return fmt.Errorf("players.pin: %w", err)
+	}
+	if _, err := players.BusName(strings.TrimSpace(f.Monitor.Player)); err != nil {
+		return fmt.Errorf("monitor.player: %w", err)
+	}
+
+	blurRadii := []struct {
+		name  string
+		value *float64
+	}{
+		{"processor.blur_radius", f.Processor.BlurRadius},
+		{"modes.blur.blur_radius", f.Modes.Blur.BlurRadius},
+		{"modes.waveform.blur_radius", f.Modes.Waveform.BlurRadius},
+	}
+	for _, r := range blurRadii {
+		if r.value != nil && (*r.value < 0 || *r.value > maxBlurRadius) {
+			return fmt.Errorf("%s must be in [0, %d]", r.name, maxBlurRadius)
+		}
+	}
+	fractions := []struct {
+		name  string
+		value *float64
+	}{
+		{"processor.cover_size", f.Processor.CoverSize},
+		{"modes.blur.cover_size", f.Modes.Blur.CoverSize},
+		{"modes.waveform.cover_size", f.Modes.Waveform.CoverSize},
+		{"modes.waveform.height", f.Modes.Waveform.Height},
+	}
+	for _, r := range fractions {
+		if r.value != nil && (*r.value <= 0 || *r.value > 1) {
+			return fmt.Errorf("%s must be in (0, 1]", r.name)
+		}
+	}
+	counts := []struct {
+		name     string
+		value    *int
+		min, max int
+	}{
+		{"modes.generative.particles", f.Modes.Generative.Particles, 0, maxParticles},
+		{"modes.generative.shapes", f.Modes.Generative.Shapes, 0, maxShapes},
+		{"modes.waveform.bars", f.Modes.Waveform.Bars, 1, maxWaveformBars},
+		{"modes.blur.max_height", f.Modes.Blur.MaxHeight, 0, maxModeHeight},
+		{"modes.generative.max_height", f.Modes.Generative.MaxHeight, 0, maxModeHeight},
+		{"modes.waveform.max_height", f.Modes.Waveform.MaxHeight, 0, maxModeHeight},
+		{"engine.debounce_burst", f.Engine.DebounceBurst, 1, maxBurst},
+		{"history.max_count", f.History.MaxCount, 0, maxHistory},
+		{"history.max_size_mb", f.History.MaxSizeMB, 0, maxHistorySize},
+		{"log.max_size_mb", f.Log.MaxSizeMB, 1, maxLogSize},
+		{"log.max_backups", f.Log.MaxBackups, 0, maxLogBackups},
+		{"processor.trim_tolerance", f.Processor.TrimTolerance, 0, maxTrimTolerance},
+		{"processor.player_badge_size", f.Processor.PlayerBadgeSize, minBadgeSize, maxBadgeSize},
+		{"monitor.replay_speed", f.Monitor.ReplaySpeed, 0, maxReplaySpeed},
+		{"fetcher.cache_max_size_mb", f.Fetcher.CacheMaxSizeMB, 1, maxArtCacheSize},
+		{"executor.eink.width", f.Executor.EInk.Width, minEInkSize, maxEInkSize},
+		{"executor.eink.height", f.Executor.EInk.Height, minEInkSize, maxEInkSize},
+		{"executor.eink.levels", f.Executor.EInk.Levels, minEInkLevels, maxEInkLevels},
+	}
+	for _, c := range counts {
+		if c.value != nil && (*c.value < c.min || *c.value > c.max) {
+			return fmt.Errorf("%s must be in [%d, %d]", c.name, c.min, c.max)
+		}
+	}
+	for name, filter := range map[string]string{
+		"processor.background_filter": f.Processor.BackgroundFilter,
+		"processor.cover_filter":      f.Processor.CoverFilter,
+	} {
+		if filter != "" && !slices.Contains(domain.Filters, strings.ToLower(filter)) {
+			return fmt.Errorf("%s must be one of %s", name, strings.Join(domain.Filters, ", "))
+		}
+	}
+	switch strings.ToLower(f.Processor.CoverAspect) {
+	case "", domain.CoverAspectPreserve, domain.CoverAspectSquare, domain.CoverAspectTrim:
+	default:
+		return fmt.Errorf("processor.cover_aspect must be %s, %s or %s", domain.CoverAspectPreserve, domain.CoverAspectSquare, domain.CoverAspectTrim)
+	}
+	if badge := strings.ToLower(f.Processor.PlayerBadge); badge != "" && !slices.Contains(domain.Badges, badge) {
+		return fmt.Errorf("processor.player_badge must be one of %s", strings.Join(domain.Badges, ", "))
+	}
+	if f.Processor.JPEGQuality != nil && (*f.Processor.JPEGQuality < 1 || *f.Processor.JPEGQuality > 100) {
+		return fmt.Errorf("processor.jpeg_quality must be in [1, 100]")
+	}
+	if f.ReadyTimeout != nil && *f.ReadyTimeout > maxReadyTimeout {
+		return fmt.Errorf("ready_timeout must be at most %s", maxReadyTimeout)
+	}
+	if f.Fetcher.Timeout != nil && *f.Fetcher.Timeout == 0 {
+		return fmt.Errorf("fetcher.timeout must be positive")
+	}
+	return nil
+}
+
+// validateGenreModes rejects genre rules selecting an unknown mode, or auto itself
+func validateGenreModes(genres map[string]string) error {
+	for genre, mode := range genres {
+		switch strings.ToLower(mode) {
+		case domain.ModeBlur, domain.ModeGenerative, domain.ModeWaveform:
+		default:
+			return fmt.Errorf("mode %q for genre %s must be %s, %s or %s",
+				mode, genre, domain.ModeBlur, domain.ModeGenerative, domain.ModeWaveform)
+		}
+	}
+	return nil
+}
+
+// checkHTTPURL rejects URLs that are not absolute http(s) URLs
+func checkHTTPURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("must be an http or https URL")
+	}
+	return nil
+}
+
+// valueOr returns the value set in the config file, or def when it is unset
+func valueOr[T any](value *T, def T) T {
+	if value == nil {
+		return def
+	}
+	return *value
+}
+
+// stringOr returns the string set in the config file, or def when it is empty
+func stringOr(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
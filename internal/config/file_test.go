@@ -0,0 +1,449 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedError string
+		check         func(t *testing.T, file *fileConfig)
+	}{
+		{
+			name: "Sections",
+			content: `
+mode: generative
+processor:
+  blur_radius: 20
+fetcher:
+  timeout: 3s
+monitor:
+  player_quirks:
+    Chromium: firefox
+`,
+			check: func(t *testing.T, file *fileConfig) {
+				if file.Mode != "generative" || valueOr(file.Processor.BlurRadius, 0) != 20 {
+					t.Errorf("unexpected values: %+v", file)
+				}
+				if valueOr(file.Fetcher.Timeout, 0) != 3*time.Second {
+					t.Errorf("expected 3s timeout, got %v", file.Fetcher.Timeout)
+				}
+				if file.Monitor.PlayerQuirks["Chromium"] != "firefox" {
+					t.Errorf("unexpected player quirks: %v", file.Monitor.PlayerQuirks)
+				}
+			},
+		},
+		{
+			name: "Empty File",
+			check: func(t *testing.T, file *fileConfig) {
+				if file.Processor.BlurRadius != nil || file.Mode != "" {
+					t.Errorf("expected nothing set, got %+v", file)
+				}
+			},
+		},
+		{
+			name:          "Error - Unknown Key",
+			content:       "processor:\n  blur_raduis: 20\n",
+			expectedError: "field blur_raduis not found",
+		},
+		{
+			name:          "Error - Cover Size Out Of Range",
+			content:       "processor:\n  cover_size: 1.5\n",
+			expectedError: "processor.cover_size",
+		},
+		{
+			name:          "Error - JPEG Quality Out Of Range",
+			content:       "processor:\n  jpeg_quality: 0\n",
+			expectedError: "processor.jpeg_quality",
+		},
+		{
+			name:          "Error - Invalid Monitor Player",
+			content:       "monitor:\n  player: \"firefox.*\"\n",
+			expectedError: "monitor.player: \"firefox.*\" is not a player bus name",
+		},
+		{
+			name:          "Error - Unknown Badge Corner",
+			content:       "processor:\n  player_badge: center\n",
+			expectedError: "processor.player_badge must be one of off, top-left, top-right, bottom-left, bottom-right",
+		},
+		{
+			name:          "Error - Unknown Metered Mode",
+			content:       "network:\n  metered: sometimes\n",
+			expectedError: "network.metered must be auto, on or off",
+		},
+		{
+			name:          "Error - Unknown Quit Behavior",
+			content:       "engine:\n  on_quit: blank\n",
+			expectedError: "engine.on_quit must be keep or restore",
+		},
+		{
+			name:          "Error - Unknown Scene Output",
+			content:       "processor:\n  scene: json\n",
+			expectedError: "processor.scene must be off, alongside or only",
+		},
+		{
+			name:          "Error - Unknown Upload Kind",
+			content:       "upload:\n  kind: ftp\n",
+			expectedError: "upload.kind must be off, s3 or webdav",
+		},
+		{
+			name:          "Error - Upload Without URL",
+			content:       "upload:\n  kind: webdav\n",
+			expectedError: "upload.url: must be an http or https URL",
+		},
+		{
+			name:          "Error - Phone Push Without Device",
+			content:       "phone:\n  push: kdeconnect\n",
+			expectedError: "phone.target must name the KDE Connect device",
+		},
+		{
+			name:          "Error - Invalid Phone Aspect",
+			content:       "phone:\n  aspect: 9x16\n",
+			expectedError: "phone.aspect: aspect \"9x16\" must look like 9:19.5",
+		},
+		{
+			name:          "Error - E-Ink Levels Out Of Range",
+			content:       "executor:\n  eink:\n    levels: 1\n",
+			expectedError: "executor.eink.levels must be in [2, 256]",
+		},
+		{
+			name:          "Error - Art Cache Size Out Of Range",
+			content:       "fetcher:\n  cache_max_size_mb: 0\n",
+			expectedError: "fetcher.cache_max_size_mb must be in [1, 100000]",
+		},
+		{
+			name:          "Error - Empty Hook Command",
+			content:       "hooks:\n  post_process:\n    - magick - -flop jpg:-\n    - \"\"\n",
+			expectedError: "hooks.post_process: empty command",
+		},
+		{
+			name:          "Error - Conflicting Setter",
+			content:       "executor:\n  backend: swww\n  setter: feh\n",
+			expectedError: "executor.backend and executor.setter disagree",
+		},
+		{
+			name:          "Error - Mode Setting Out Of Range",
+			content:       "modes:\n  waveform:\n    bars: 0\n",
+			expectedError: "modes.waveform.bars must be in [1, 2000]",
+		},
+		{
+			name:          "Error - Mode Height Out Of Range",
+			content:       "modes:\n  generative:\n    max_height: -1\n",
+			expectedError: "modes.generative.max_height must be in [0, 8640]",
+		},
+		{
+			name:          "Error - Replay Speed Out Of Range",
+			content:       "monitor:\n  replay_speed: 5000\n",
+			expectedError: "monitor.replay_speed must be in [0, 1000]",
+		},
+		{
+			name:          "Error - Invalid Genre Mode",
+			content:       "auto:\n  genres:\n    ambient: auto\n",
+			expectedError: "auto.genres: mode \"auto\" for genre ambient",
+		},
+		{
+			name:          "Error - Invalid Player Pattern",
+			content:       "players:\n  deny: [\"/chrom(/\"]\n",
+			expectedError: "players: invalid denied player",
+		},
+		{
+			name:          "Error - Negative Duration",
+			content:       "engine:\n  debounce: -1s\n",
+			expectedError: "engine.debounce must not be negative",
+		},
+		{
+			name:          "Error - Unknown Debounce Strategy",
+			content:       "engine:\n  debounce_strategy: throttle\n",
+			expectedError: "engine.debounce_strategy must be trailing, leading or token_bucket",
+		},
+		{
+			name:          "Error - Unknown Skipped Kind",
+			content:       "engine:\n  skip_kinds: [ad, audiobook]\n",
+			expectedError: `engine.skip_kinds: "audiobook" must be ad or podcast`,
+		},
+		{
+			name:          "Error - Invalid Private Mode",
+			content:       "private:\n  mode: hidden\n",
+			expectedError: "private.mode",
+		},
+		{
+			name:          "Error - Unknown Log Format",
+			content:       "log:\n  format: xml\n",
+			expectedError: "log.format must be json or console",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), FileName)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			file, err := loadFile(path)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing '%s', got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.check(t, file)
+		})
+	}
+
+	if _, err := loadFile(filepath.Join(t.TempDir(), "missing.yaml")); err != nil {
+		t.Errorf("expected a missing file to be ignored, got %v", err)
+	}
+}
+
+// TestNewAppConfig_Precedence verifies environment variables override the
+// config file, which overrides the defaults
+func TestNewAppConfig_Precedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	content := "mode: generative\nprocessor:\n  jpeg_quality: 80\nengine:\n  debounce: 2s\n  min_interval: 1m\n  dedup: true\nexecutor:\n  backend: FEH\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SYNEST_CONFIG", path)
+	t.Setenv("SYNEST_DEBOUNCE", "1s")
+
+	cfg := NewAppConfig(zap.NewNop())
+
+	if cfg.GetMode() != "generative" || cfg.GetJPEGQuality() != 80 || !cfg.GetDedup() || cfg.GetMinInterval() != time.Minute {
+		t.Errorf("expected file values, got mode=%s quality=%d dedup=%v minInterval=%v", cfg.GetMode(), cfg.GetJPEGQuality(), cfg.GetDedup(), cfg.GetMinInterval())
+	}
+	if cfg.GetSetter() != "feh" {
+		t.Errorf("expected the forced backend, got %q", cfg.GetSetter())
+	}
+	if cfg.GetDebounce() != time.Second {
+		t.Errorf("expected the environment to override the file, got debounce %v", cfg.GetDebounce())
+	}
+	if cfg.GetModeConfig().Blur.BlurRadius != defaultBlurRadius || cfg.GetFetchTimeout() != defaultFetchTimeout {
+		t.Errorf("expected defaults for unset options, got blur %v timeout %v", cfg.GetModeConfig().Blur.BlurRadius, cfg.GetFetchTimeout())
+	}
+}
+
+// TestModeConfig verifies mode sections override the shared processor settings
+// for their mode only
+func TestModeConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	content := "processor:\n  blur_radius: 20\nmodes:\n  blur:\n    cover_size: 0.5\n  waveform:\n    blur_radius: 40\n  generative:\n    shapes: 0\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SYNEST_CONFIG", path)
+
+	modes := NewAppConfig(zap.NewNop()).GetModeConfig()
+
+	if modes.Blur.BlurRadius != 20 || modes.Blur.CoverSize != 0.5 {
+		t.Errorf("unexpected blur settings: %+v", modes.Blur)
+	}
+	if modes.Waveform.BlurRadius != 40 || modes.Waveform.CoverSize != defaultCoverSize || modes.Waveform.Bars != defaultBars {
+		t.Errorf("unexpected waveform settings: %+v", modes.Waveform)
+	}
+	if modes.Generative.Shapes != 0 || modes.Generative.Particles != defaultParticles {
+		t.Errorf("unexpected generative settings: %+v", modes.Generative)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	t.Setenv("SYNEST_CONFIG", filepath.Join(t.TempDir(), FileName))
+
+	if _, err := Check(zap.NewNop()); err != nil {
+		t.Fatalf("expected defaults to be valid, got %v", err)
+	}
+
+	t.Setenv("SYNEST_MODE", "sepia")
+	t.Setenv("SYNEST_DEBOUNCE", "soon")
+	_, err := Check(zap.NewNop())
+	if err == nil {
+		t.Fatal("expected invalid values to be reported")
+	}
+	for _, want := range []string{`SYNEST_MODE="sepia"`, `SYNEST_DEBOUNCE="soon"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %s in %q", want, err)
+		}
+	}
+
+	// NewAppConfig keeps running with the defaults
+	if mode := NewAppConfig(zap.NewNop()).GetMode(); mode != defaultMode {
+		t.Errorf("expected fallback mode %s, got %s", defaultMode, mode)
+	}
+}
+
+func TestDisabledSubsystems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte("disable: [Hook, hot_reload]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SYNEST_CONFIG", path)
+
+	disabled, err := DisabledSubsystems()
+	if err != nil || len(disabled) != 2 || !disabled[SubsystemHook] || !disabled[SubsystemHotReload] {
+		t.Errorf("expected hook and hot_reload from the file, got %v (%v)", disabled, err)
+	}
+
+	t.Setenv("SYNEST_DISABLE", "notifications")
+	if disabled, err := DisabledSubsystems(); err != nil || len(disabled) != 1 || !disabled[SubsystemNotifications] {
+		t.Errorf("expected the environment to override the file, got %v (%v)", disabled, err)
+	}
+
+	t.Setenv("SYNEST_DISABLE", "metrics")
+	if _, err := DisabledSubsystems(); err == nil {
+		t.Error("expected an error for an unknown subsystem")
+	}
+}
+
+func TestLogging(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	data := "log:\n  level: debug\n  format: console\n  file: /tmp/synest.log\n  max_size_mb: 5\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SYNEST_CONFIG", path)
+
+	settings, err := Logging()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := LogSettings{Level: zapcore.DebugLevel, Format: LogFormatConsole, File: "/tmp/synest.log", MaxSize: 5 << 20, MaxBackups: defaultLogMaxBackups}
+	if settings != want {
+		t.Errorf("expected %+v from the file, got %+v", want, settings)
+	}
+
+	t.Setenv("SYNEST_LOG_LEVEL", "warn")
+	t.Setenv("SYNEST_LOG_MAX_BACKUPS", "0")
+	if settings, err := Logging(); err != nil || settings.Level != zapcore.WarnLevel || settings.MaxBackups != 0 {
+		t.Errorf("expected the environment to override the file, got %+v (%v)", settings, err)
+	}
+
+	t.Setenv("SYNEST_LOG_FORMAT", "xml")
+	t.Setenv("SYNEST_LOG_MAX_SIZE_MB", "0")
+	_, err = Logging()
+	for _, want := range []string{"SYNEST_LOG_FORMAT", "SYNEST_LOG_MAX_SIZE_MB"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected an error naming %s, got %v", want, err)
+		}
+	}
+}
+
+func TestLoadFile_Example(t *testing.T) {
+	if _, err := loadFile("../../examples/config.yaml"); err != nil {
+		t.Fatalf("example config is invalid: %v", err)
+	}
+}
+
+func TestAppConfig_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("mode: blur\n")
+	t.Setenv("SYNEST_CONFIG", path)
+	t.Setenv("SYNEST_MODE", "")
+
+	cfg := NewAppConfig(zap.NewNop())
+	write("mode: generative\nprocessor:\n  blur_radius: 30\n")
+	if err := cfg.Reload(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if cfg.GetMode() != "generative" || cfg.GetModeConfig().Blur.BlurRadius != 30 {
+		t.Errorf("expected reloaded values, got mode=%s blur=%v", cfg.GetMode(), cfg.GetModeConfig().Blur.BlurRadius)
+	}
+	select {
+	case <-cfg.Changes():
+	default:
+		t.Error("expected a change notification")
+	}
+
+	write("mode: [broken\n")
+	if err := cfg.Reload(); err == nil {
+		t.Error("expected an error for an invalid file")
+	}
+	if cfg.GetMode() != "generative" {
+		t.Errorf("expected the previous configuration to be kept, got mode=%s", cfg.GetMode())
+	}
+}
+
+func TestAppConfig_SetPrivateMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("private:\n  mode: off\n")
+	t.Setenv("SYNEST_CONFIG", path)
+	t.Setenv("SYNEST_PRIVATE", "")
+
+	cfg := NewAppConfig(zap.NewNop())
+	if err := cfg.SetPrivateMode("sometimes"); err == nil {
+		t.Error("expected an unknown private mode to be rejected")
+	}
+	if err := cfg.SetPrivateMode(" On "); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.GetPrivateMode() != domain.PrivateOn {
+		t.Errorf("expected the switched private mode, got %s", cfg.GetPrivateMode())
+	}
+
+	// Reloading an unchanged private mode keeps the switch, changing it ends it
+	write("mode: generative\nprivate:\n  mode: off\n")
+	if err := cfg.Reload(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if cfg.GetPrivateMode() != domain.PrivateOn {
+		t.Errorf("expected the switch to survive the reload, got %s", cfg.GetPrivateMode())
+	}
+	write("private:\n  mode: freeze\n")
+	if err := cfg.Reload(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if cfg.GetPrivateMode() != domain.PrivateFreeze {
+		t.Errorf("expected the configured private mode, got %s", cfg.GetPrivateMode())
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	t.Setenv("SYNEST_CONFIG", path)
+	t.Setenv("SYNEST_MODE", "")
+
+	cfg := NewAppConfig(zap.NewNop())
+	watcher := NewWatcher(zap.NewNop(), cfg)
+	if err := watcher.Start(context.Background()); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	defer watcher.Stop()
+
+	if err := os.WriteFile(path, []byte("mode: waveform\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-cfg.Changes():
+		if cfg.GetMode() != "waveform" {
+			t.Errorf("expected mode from the new file, got %s", cfg.GetMode())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("config was not reloaded after the file changed")
+	}
+}
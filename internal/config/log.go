@@ -0,0 +1,86 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Log encoders
+const (
+	LogFormatJSON    = "json"    // One JSON object per line, for journald and log shippers
+	LogFormatConsole = "console" // Human-readable lines
+)
+
+// Log file defaults and bounds
+const (
+	defaultLogMaxSize    = 10 // MiB
+	defaultLogMaxBackups = 3
+	maxLogSize           = 1000 // MiB
+	maxLogBackups        = 100
+)
+
+// LogSettings configure the logger
+type LogSettings struct {
+	Level      zapcore.Level
+	Format     string // LogFormatJSON or LogFormatConsole
+	File       string // Log file written along with stderr, empty for none
+	MaxSize    int64  // Bytes written before the log file is rotated
+	MaxBackups int    // Rotated log files kept
+}
+
+// Logging returns the log settings from the SYNEST_LOG_* variables, or else from
+// the log section of the config file. The logger is built before the rest of the
+// configuration, so they are read once at startup and invalid values are an
+// error rather than a fallback nobody would see.
+func Logging() (LogSettings, error) {
+	file, err := loadFile(FilePath())
+	if err != nil {
+		// An invalid file is reported when the configuration is loaded
+		file = &fileConfig{}
+	}
+	var errs []error
+
+	level := envOr("SYNEST_LOG_LEVEL", stringOr(file.Log.Level, "info"))
+	settings := LogSettings{
+		Format:     strings.ToLower(envOr("SYNEST_LOG_FORMAT", stringOr(file.Log.Format, LogFormatJSON))),
+		File:       expandPath(envOr("SYNEST_LOG_FILE", file.Log.File)),
+		MaxBackups: valueOr(file.Log.MaxBackups, defaultLogMaxBackups),
+	}
+	if settings.Level, err = zapcore.ParseLevel(level); err != nil {
+		errs = append(errs, fmt.Errorf("SYNEST_LOG_LEVEL=%q: %w", level, err))
+	}
+	switch settings.Format {
+	case LogFormatJSON, LogFormatConsole:
+	default:
+		errs = append(errs, fmt.Errorf("SYNEST_LOG_FORMAT=%q: must be %s or %s", settings.Format, LogFormatJSON, LogFormatConsole))
+	}
+
+	maxSize := valueOr(file.Log.MaxSizeMB, defaultLogMaxSize)
+	for _, c := range []struct {
+		name     string
+		value    *int
+		min, max int
+	}{
+		{"SYNEST_LOG_MAX_SIZE_MB", &maxSize, 1, maxLogSize},
+		{"SYNEST_LOG_MAX_BACKUPS", &settings.MaxBackups, 0, maxLogBackups},
+	} {
+		value := os.Getenv(c.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < c.min || parsed > c.max {
+			errs = append(errs, fmt.Errorf("%s=%q: must be an integer in [%d, %d]", c.name, value, c.min, c.max))
+			continue
+		}
+		*c.value = parsed
+	}
+	settings.MaxSize = int64(maxSize) << 20
+
+	return settings, errors.Join(errs...)
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Optional subsystems that can be left out of the daemon
+const (
+	SubsystemNotifications = "notifications" // Desktop notifications of repeated failures
+	SubsystemEnrichment    = "enrichment"    // Spotify audio features for mood grading
+	SubsystemHook          = "hook"          // On-applied shell command
+	SubsystemHotReload     = "hot_reload"    // Config file watcher
+	SubsystemScreensaver   = "screensaver"   // Updates suspended while the session is locked
+)
+
+// Subsystems lists the optional subsystems, in documentation order
+var Subsystems = []string{SubsystemNotifications, SubsystemEnrichment, SubsystemHook, SubsystemHotReload, SubsystemScreensaver}
+
+// DisabledSubsystems returns the subsystems disabled by SYNEST_DISABLE, or else by
+// the disable list of the config file. It is read once before the daemon is
+// assembled, so changes need a restart. Unknown names are an error.
+func DisabledSubsystems() (map[string]bool, error) {
+	var names []string
+	if value := os.Getenv("SYNEST_DISABLE"); value != "" {
+		names = parseList(value)
+	} else if file, err := loadFile(FilePath()); err == nil {
+		// An invalid file is reported when the configuration is loaded
+		names = parseList(strings.Join(file.Disable, ","))
+	}
+
+	disabled := make(map[string]bool, len(names))
+	for _, name := range names {
+		if !slices.Contains(Subsystems, name) {
+			return nil, fmt.Errorf("unknown subsystem %q (valid: %v)", name, Subsystems)
+		}
+		disabled[name] = true
+	}
+	return disabled, nil
+}
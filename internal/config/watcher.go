@@ -0,0 +1,100 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// reloadDelay groups the several events editors produce when saving a file
+const reloadDelay = 250 * time.Millisecond
+
+// Watcher reloads the configuration when its file changes
+type Watcher struct {
+	logger  *zap.Logger
+	cfg     *AppConfig
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+}
+
+// NewWatcher creates a watcher for the config file of cfg
+func NewWatcher(logger *zap.Logger, cfg *AppConfig) *Watcher {
+	return &Watcher{logger: logger, cfg: cfg}
+}
+
+// Start watches the directory of the config file. Editors often replace the
+// file instead of writing it in place, so the directory is watched rather than
+// the file itself. A missing directory disables hot reload.
+func (w *Watcher) Start(ctx context.Context) error {
+	path := w.cfg.Path()
+	if path == "" {
+		return nil
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+	if err := fsw.Add(filepath.Dir(path)); err != nil {
+		_ = fsw.Close()
+		w.logger.Info("Config directory not watchable, hot reload disabled",
+			zap.String("dir", filepath.Dir(path)),
+			zap.Error(err))
+		return nil
+	}
+
+	w.watcher = fsw
+	w.done = make(chan struct{})
+	go w.run(filepath.Clean(path))
+	return nil
+}
+
+// run reloads the configuration once the file has been quiet for reloadDelay
+func (w *Watcher) run(path string) {
+	defer close(w.done)
+
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != path || event.Op == fsnotify.Chmod {
+				continue
+			}
+			timer.Reset(reloadDelay)
+
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			w.logger.Warn("Config watcher error", zap.Error(err))
+
+		case <-timer.C:
+			if err := w.cfg.Reload(); err != nil {
+				w.logger.Warn("Config file change ignored, keeping current configuration",
+					zap.String("path", path),
+					zap.Error(err))
+				continue
+			}
+			w.logger.Info("Configuration reloaded", zap.String("path", path))
+		}
+	}
+}
+
+// Stop stops watching the config file
+func (w *Watcher) Stop() error {
+	if w.watcher == nil {
+		return nil
+	}
+	err := w.watcher.Close()
+	<-w.done
+	return err
+}
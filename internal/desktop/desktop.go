@@ -0,0 +1,145 @@
+// Package desktop reads the freedesktop desktop entries of installed
+// applications, to show players with their own name and icon.
+package desktop
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Icon returns the icon of the desktop entry named entry, e.g. "spotify" for
+// spotify.desktop: an icon theme name or an absolute path. It is empty when
+// the entry is not installed or has no icon.
+func Icon(entry string) string {
+	if entry == "" || strings.ContainsAny(entry, `/\`) {
+		return ""
+	}
+	for _, dir := range DataDirs() {
+		if icon, ok := readIcon(filepath.Join(dir, "applications", entry+".desktop")); ok {
+			return icon
+		}
+	}
+	return ""
+}
+
+// IconFile returns the PNG file of the icon of the desktop entry named entry,
+// closest to size pixels: the smallest not below size, else the largest. Theme
+// names are looked up in the hicolor theme, where applications install their
+// icons, then in pixmaps. It is empty when no PNG icon is found.
+func IconFile(entry string, size int) string {
+	icon := Icon(entry)
+	if filepath.IsAbs(icon) {
+		if strings.EqualFold(filepath.Ext(icon), ".png") && isFile(icon) {
+			return icon
+		}
+		return ""
+	}
+	if icon == "" || strings.ContainsAny(icon, `/\`) {
+		return ""
+	}
+	icon = strings.TrimSuffix(icon, ".png")
+
+	var bases []string
+	if home, err := os.UserHomeDir(); err == nil {
+		bases = append(bases, filepath.Join(home, ".icons"))
+	}
+	for _, dir := range DataDirs() {
+		bases = append(bases, filepath.Join(dir, "icons"))
+	}
+	for _, base := range bases {
+		if path := themeIcon(filepath.Join(base, "hicolor"), icon, size); path != "" {
+			return path
+		}
+	}
+	for _, dir := range DataDirs() {
+		if path := filepath.Join(dir, "pixmaps", icon+".png"); isFile(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+// themeIcon returns the PNG of icon in the fixed-size directories of a theme
+// closest to size, empty when the theme has none
+func themeIcon(theme, icon string, size int) string {
+	dirs, err := os.ReadDir(theme)
+	if err != nil {
+		return ""
+	}
+
+	best, bestSize := "", 0
+	for _, dir := range dirs {
+		width, height, found := strings.Cut(dir.Name(), "x")
+		n, err := strconv.Atoi(width)
+		if !found || err != nil || height != width {
+			continue // scalable, or a scaled directory such as 48x48@2
+		}
+		path := filepath.Join(theme, dir.Name(), "apps", icon+".png")
+		if !isFile(path) {
+			continue
+		}
+		// Prefer scaling down from the smallest large enough icon
+		if best == "" || (n >= size && (bestSize < size || n < bestSize)) || (n < size && bestSize < size && n > bestSize) {
+			best, bestSize = path, n
+		}
+	}
+	return best
+}
+
+// isFile reports whether path is a regular file
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// DataDirs returns the XDG data directories, most important first:
+// $XDG_DATA_HOME (~/.local/share) then $XDG_DATA_DIRS (/usr/local/share:/usr/share)
+func DataDirs() []string {
+	var dirs []string
+	if home := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(home) {
+		dirs = append(dirs, home)
+	} else if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".local", "share"))
+	}
+
+	system := os.Getenv("XDG_DATA_DIRS")
+	if system == "" {
+		system = "/usr/local/share:/usr/share"
+	}
+	for _, dir := range filepath.SplitList(system) {
+		if filepath.IsAbs(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// readIcon returns the Icon key of the [Desktop Entry] group of a desktop
+// file, ok is false when the file cannot be read
+func readIcon(path string) (icon string, ok bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer file.Close()
+
+	group := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			group = line[1 : len(line)-1]
+			continue
+		}
+		if group != "Desktop Entry" {
+			continue
+		}
+		if key, value, found := strings.Cut(line, "="); found && strings.TrimSpace(key) == "Icon" {
+			return strings.TrimSpace(value), true
+		}
+	}
+	return "", true
+}
@@ -0,0 +1,89 @@
+package desktop
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIcon(t *testing.T) {
+	home, system := t.TempDir(), t.TempDir()
+	t.Setenv("XDG_DATA_HOME", home)
+	t.Setenv("XDG_DATA_DIRS", system)
+
+	write := func(dir, name, content string) {
+		t.Helper()
+		apps := filepath.Join(dir, "applications")
+		if err := os.MkdirAll(apps, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(apps, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(system, "spotify.desktop", "[Desktop Entry]\nName=Spotify\nIcon=spotify-client\n")
+	write(system, "vlc.desktop", "[Desktop Entry]\nName=VLC\nIcon=vlc\n")
+	write(home, "vlc.desktop", "[Desktop Action new]\nIcon=window-new\n[Desktop Entry]\nIcon = /opt/vlc/vlc.png\n")
+	write(system, "noicon.desktop", "[Desktop Entry]\nName=No Icon\n")
+
+	tests := []struct {
+		entry    string
+		expected string
+	}{
+		{"spotify", "spotify-client"},
+		{"vlc", "/opt/vlc/vlc.png"}, // The user entry overrides the system one
+		{"noicon", ""},
+		{"missing", ""},
+		{"../spotify", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Icon(tt.entry); got != tt.expected {
+			t.Errorf("Icon(%q): expected %q, got %q", tt.entry, tt.expected, got)
+		}
+	}
+}
+
+func TestIconFile(t *testing.T) {
+	home, system := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", home)
+	t.Setenv("XDG_DATA_DIRS", system)
+
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hicolor := filepath.Join(system, "icons", "hicolor")
+	write(filepath.Join(system, "applications", "spotify.desktop"), "[Desktop Entry]\nIcon=spotify-client\n")
+	for _, dir := range []string{"16x16", "32x32", "128x128", "scalable"} {
+		write(filepath.Join(hicolor, dir, "apps", "spotify-client.png"), "png")
+	}
+	write(filepath.Join(system, "applications", "mpv.desktop"), "[Desktop Entry]\nIcon=mpv\n")
+	write(filepath.Join(system, "pixmaps", "mpv.png"), "png")
+	write(filepath.Join(system, "applications", "vlc.desktop"), "[Desktop Entry]\nIcon="+filepath.Join(system, "vlc.svg")+"\n")
+	write(filepath.Join(system, "vlc.svg"), "svg")
+
+	tests := []struct {
+		entry    string
+		size     int
+		expected string
+	}{
+		{"spotify", 24, filepath.Join(hicolor, "32x32", "apps", "spotify-client.png")},
+		{"spotify", 32, filepath.Join(hicolor, "32x32", "apps", "spotify-client.png")},
+		{"spotify", 512, filepath.Join(hicolor, "128x128", "apps", "spotify-client.png")},
+		{"mpv", 48, filepath.Join(system, "pixmaps", "mpv.png")},
+		{"vlc", 48, ""}, // Only PNG icons can be drawn
+		{"missing", 48, ""},
+	}
+	for _, tt := range tests {
+		if got := IconFile(tt.entry, tt.size); got != tt.expected {
+			t.Errorf("IconFile(%q, %d): expected %q, got %q", tt.entry, tt.size, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "context"
+
+type modeKey struct{}
+
+type trackKey struct{}
+
+// WithMode returns a copy of ctx carrying the wallpaper mode of the pipeline run
+func WithMode(ctx context.Context, mode string) context.Context {
+	return context.WithValue(ctx, modeKey{}, mode)
+}
+
+// ModeOf returns the wallpaper mode carried by ctx, empty when there is none
+func ModeOf(ctx context.Context) string {
+	mode, _ := ctx.Value(modeKey{}).(string)
+	return mode
+}
+
+// WithTrack returns a copy of ctx carrying the track of the pipeline run
+func WithTrack(ctx context.Context, meta MediaMetadata) context.Context {
+	return context.WithValue(ctx, trackKey{}, meta)
+}
+
+// TrackOf returns the track carried by ctx, ok is false when there is none
+func TrackOf(ctx context.Context) (meta MediaMetadata, ok bool) {
+	meta, ok = ctx.Value(trackKey{}).(MediaMetadata)
+	return meta, ok
+}
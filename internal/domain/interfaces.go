@@ -1,6 +1,9 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Monitor defines the interface for monitoring media playback events
 // Implementations should handle D-Bus/MPRIS communication
@@ -21,9 +24,27 @@ type Monitor interface {
 // Implementations should handle album art transformations
 type Processor interface {
 	// Generate creates a wallpaper from album art data
-	// mode specifies the processing type (e.g., "blur", "gradient", "lyrics")
+	// ctx carries cancellation and the run-scoped logger
+	// imgData may be empty for modes that do not use the artwork (e.g., "generative")
+	// meta carries the track information for modes that are seeded by it,
+	// a paused status renders the wallpaper dimmed
+	// mode specifies the processing type (e.g., "blur", "generative")
 	// Returns the file path to the generated wallpaper or an error
-	Generate(imgData []byte, mode string) (string, error)
+	Generate(ctx context.Context, imgData []byte, meta MediaMetadata, mode string) (string, error)
+
+	// Variations returns how many distinct renderings of the same track a mode
+	// offers through MediaMetadata.Variation (1 = no variations)
+	Variations(mode string) int
+
+	// Modes returns the names of the registered generation modes
+	Modes() []string
+}
+
+// Resizer is implemented by processors whose output size can change at runtime,
+// e.g. once a display shows up after startup
+type Resizer interface {
+	// Resize renders later wallpapers at res. It must not run during a Generate call.
+	Resize(res ScreenResolution)
 }
 
 // ImageProcessor defines the interface for in-memory image processing
@@ -41,6 +62,99 @@ type Fetcher interface {
 	Fetch(ctx context.Context, url string) ([]byte, error)
 }
 
+// Enricher defines the interface for looking up additional track information
+type Enricher interface {
+	// AudioFeatures returns mood analysis for the track
+	// Returns nil without error when the provider is not configured or does not know the track
+	AudioFeatures(ctx context.Context, meta MediaMetadata) (*AudioFeatures, error)
+}
+
+// Notifier defines the interface for user-facing desktop notifications
+type Notifier interface {
+	// Notify shows a notification with the given summary and body. image is an
+	// icon theme name or an absolute path shown with it, empty for none.
+	Notify(ctx context.Context, summary, body, image string) error
+}
+
+// Reporter defines the interface for surfacing pipeline outcomes to the user
+// Implementations must not block the pipeline for long
+type Reporter interface {
+	// Success records a completed wallpaper update and the track it was rendered from
+	Success(ctx context.Context, wallpaperPath string, meta MediaMetadata)
+
+	// Failure records a failed pipeline step (e.g. "fetch", "generate", "set")
+	Failure(ctx context.Context, step string, err error)
+
+	// SetterSelected records the wallpaper setter in use and why it was chosen
+	SetterSelected(name, reason string)
+}
+
+// PaletteSource defines the interface for reading the colors of generated wallpapers
+type PaletteSource interface {
+	// Palette returns the dominant colors of the last generated wallpaper as
+	// #rrggbb, most frequent first (empty before the first one)
+	Palette() []string
+}
+
+// PositionSource defines the interface for following the playback position
+type PositionSource interface {
+	// Position returns the estimated playback position and the length of the last
+	// reported track, ok is false when no track was reported
+	Position() (position, length time.Duration, ok bool)
+}
+
+// EventCounter defines the interface for monitors that coalesce or drop events
+// while the consumer falls behind
+type EventCounter interface {
+	// EventStats returns the events coalesced and dropped since startup
+	EventStats() EventStats
+}
+
+// PlayerEventSource defines the interface for monitors reporting players that
+// appear and vanish
+type PlayerEventSource interface {
+	// PlayerEvents returns a read-only channel that emits the player lifecycle
+	// events. It is never closed.
+	PlayerEvents() <-chan PlayerEvent
+}
+
+// ProviderSource defines the interface for reading the state of external providers
+type ProviderSource interface {
+	// Providers returns the state of every provider called so far, sorted by name
+	Providers() []ProviderState
+}
+
+// PlayerController defines the interface for controlling the player whose track is on screen
+type PlayerController interface {
+	// Control sends a playback command (PlayerPlayPause, PlayerNext or PlayerPrevious) to the player
+	Control(ctx context.Context, command string) error
+}
+
+// PowerSource defines the interface for detecting when power should be saved
+type PowerSource interface {
+	// SavePower reports whether the battery saver applies now
+	SavePower(ctx context.Context) bool
+}
+
+// MeteredSource defines the interface for detecting when downloads should be kept to a minimum
+type MeteredSource interface {
+	// Metered reports whether the metered mode applies now
+	Metered(ctx context.Context) bool
+}
+
+// BandwidthCounter defines the interface for fetchers counting what they download
+type BandwidthCounter interface {
+	// BytesFetched returns the bytes downloaded since startup
+	BytesFetched() uint64
+}
+
+// AppliedHook defines the interface for actions that must only run once the
+// new wallpaper is confirmed on screen
+type AppliedHook interface {
+	// Applied is called after the wallpaper change has been verified
+	Applied(ctx context.Context, wallpaperPath string, meta MediaMetadata) error
+}
+
 // Executor defines the interface for executing system commands
 type Executor interface {
 	// SetWallpaper sets the desktop wallpaper to the specified image path
@@ -49,6 +163,31 @@ type Executor interface {
 	// GetCurrentWallpaper retrieves the path to the currently set wallpaper
 	// Returns an error if the operation is not supported or fails
 	GetCurrentWallpaper(ctx context.Context) (string, error)
+
+	// Setter returns the name of the wallpaper setter in use and why it was selected
+	Setter() (name, reason string)
+}
+
+// PrivateSwitch is implemented by configurations whose private mode can be
+// switched at runtime, e.g. from synestctl
+type PrivateSwitch interface {
+	// SetPrivateMode overrides the configured mode: PrivateOff, PrivateOn or PrivateFreeze
+	SetPrivateMode(mode string) error
+}
+
+// Readier is implemented by components that depend on an external service which
+// may start after synest, such as the session bus or a setter daemon
+type Readier interface {
+	// Ready returns nil once the service can be used
+	Ready(ctx context.Context) error
+}
+
+// Reloader is implemented by pipeline components that read some settings only
+// once and are rebuilt when a configuration reload changes them
+type Reloader interface {
+	// Reload rebuilds the component when its settings changed, keeping the
+	// current one when that fails. It is only called between pipeline runs.
+	Reload(ctx context.Context)
 }
 
 // Config defines the interface for application configuration
@@ -56,6 +195,251 @@ type Config interface {
 	// GetMode returns the current wallpaper generation mode
 	GetMode() string
 
+	// GetAutoGenres returns per-genre mode overrides for ModeAuto, keyed by lowercase genre
+	GetAutoGenres() map[string]string
+
 	// GetOutputDir returns the directory for generated wallpapers
 	GetOutputDir() string
+
+	// GetStateDir returns the directory for state kept across runs, such as the status file
+	GetStateDir() string
+
+	// GetReadyTimeout returns how long startup waits for external services (0 = no wait)
+	GetReadyTimeout() time.Duration
+
+	// GetDeterministic reports whether rendering must be reproducible
+	// When true, identical inputs always produce byte-identical wallpapers
+	GetDeterministic() bool
+
+	// GetModeConfig returns the settings of every generation mode
+	GetModeConfig() ModeConfig
+
+	// GetJPEGQuality returns the quality (1-100) of the encoded wallpaper
+	GetJPEGQuality() int
+
+	// GetCoverThumbnails reports whether square crops of the cover are exported next to the wallpaper
+	GetCoverThumbnails() bool
+
+	// GetBackgroundFilter returns the resampling filter scaling the cover to fill the background (one of Filters)
+	GetBackgroundFilter() string
+
+	// GetCoverFilter returns the resampling filter scaling the sharp cover and its thumbnails (one of Filters)
+	GetCoverFilter() string
+
+	// GetCoverAspect returns how non-square artwork is shaped before compositing (CoverAspectPreserve, CoverAspectSquare or CoverAspectTrim)
+	GetCoverAspect() string
+
+	// GetTrimBorders reports whether solid borders are trimmed from the artwork before scaling
+	GetTrimBorders() bool
+
+	// GetTrimTolerance returns how far, in 8-bit channel levels, a border pixel may stray from the border color
+	GetTrimTolerance() int
+
+	// GetPlayerBadge returns the corner showing the icon of the player, BadgeOff for none (one of Badges)
+	GetPlayerBadge() string
+
+	// GetPlayerBadgeSize returns the size of the player icon badge in pixels
+	GetPlayerBadgeSize() int
+
+	// GetScene returns whether the scene description of the composition is written
+	// (SceneOff, SceneAlongside or SceneOnly)
+	GetScene() string
+
+	// GetFetchTimeout returns the timeout for artwork downloads
+	GetFetchTimeout() time.Duration
+
+	// GetArtCache returns the on-disk cache of downloaded artwork
+	GetArtCache() ArtCache
+
+	// GetSpotifyCredentials returns the Spotify API client ID and secret
+	// Both are empty when Spotify integration is not configured
+	GetSpotifyCredentials() (clientID, clientSecret string)
+
+	// GetSpotifyRefreshToken returns the OAuth refresh token of the Spotify account
+	// followed by the spotify monitor backend, empty when not configured
+	GetSpotifyRefreshToken() string
+
+	// GetSpotifyPollInterval returns how often the spotify monitor backend polls
+	// the playback state of the account
+	GetSpotifyPollInterval() time.Duration
+
+	// GetPlayerQuirks maps player IDs (e.g. "chromium") to the quirks adapter to use
+	// instead of the default one ("none" disables quirks for that player)
+	GetPlayerQuirks() map[string]string
+
+	// GetArtSettleDelays returns, per player (e.g. "spotify"), how long to wait after a
+	// metadata change before re-reading it, overriding the quirks adapter default
+	GetArtSettleDelays() map[string]time.Duration
+
+	// GetNormalizeRules returns the text normalization rules to apply, in order
+	// nil selects the default rules, an empty list disables normalization
+	GetNormalizeRules() []string
+
+	// GetArtistSeparator returns the separator placed between artists in captions
+	GetArtistSeparator() string
+
+	// GetPlayerAllow returns the patterns of players to follow (empty = all players)
+	GetPlayerAllow() []string
+
+	// GetPlayerDeny returns the patterns of players to ignore, deny wins over allow
+	GetPlayerDeny() []string
+
+	// GetPlayerPolicy returns how the driving player is chosen while several are
+	// playing (PlayerPolicyRecent or PlayerPolicyPriority)
+	GetPlayerPolicy() string
+
+	// GetPlayerPriority returns the player patterns ranked by PlayerPolicyPriority, most preferred first
+	GetPlayerPriority() []string
+
+	// GetPlayerPin returns the pattern of the player that alone drives the wallpaper
+	// while it runs, whatever the policy (empty = none)
+	GetPlayerPin() string
+
+	// GetDebounce returns the quiet period required after a media event before updating
+	GetDebounce() time.Duration
+
+	// GetDebounceStrategy returns how bursts of events are debounced (DebounceTrailing, DebounceLeading or DebounceTokenBucket)
+	GetDebounceStrategy() string
+
+	// GetDebounceBurst returns how many updates the token bucket strategy allows in a row
+	GetDebounceBurst() int
+
+	// GetDebounceBrowsing returns the longer debounce used while the user skips through tracks (0 = never)
+	GetDebounceBrowsing() time.Duration
+
+	// GetMinInterval returns the minimum time between two wallpaper updates (0 = no limit)
+	GetMinInterval() time.Duration
+
+	// GetDedup reports whether updates for the track already on screen are skipped
+	GetDedup() bool
+
+	// GetSkipKinds returns the media kinds (KindAd, KindPodcast) whose events
+	// never update the wallpaper
+	GetSkipKinds() []string
+
+	// GetNotifyErrors reports whether repeated pipeline failures trigger a desktop notification
+	GetNotifyErrors() bool
+
+	// GetOnAppliedCommand returns the shell command run after a verified wallpaper change (empty = none)
+	GetOnAppliedCommand() string
+	// GetPreFetchHooks returns the shell commands rewriting the artwork URL before it is fetched, in order
+	GetPreFetchHooks() []string
+	// GetPostProcessHooks returns the shell commands transforming the wallpaper image after processing, in order
+	GetPostProcessHooks() []string
+
+	// GetPauseBehavior returns what happens to the wallpaper while paused (PauseKeep, PauseDim or PauseRestore)
+	GetPauseBehavior() string
+	// GetQuitBehavior returns what happens to the wallpaper once the last player quits (QuitKeep or QuitRestore)
+	GetQuitBehavior() string
+
+	// GetMonitorBackend returns the name of the monitor backend ("auto" by default)
+	GetMonitorBackend() string
+
+	// GetMonitorPlayer returns the bus name of the only MPRIS player the monitor
+	// subscribes to, empty to follow every player
+	GetMonitorPlayer() string
+
+	// GetMonitorRecord returns the JSONL file every monitor event is appended to, empty for none
+	GetMonitorRecord() string
+
+	// GetMPD returns the server followed by the mpd monitor backend
+	GetMPD() MPD
+
+	// GetMonitorReplay returns the recording the replay monitor backend plays back
+	GetMonitorReplay() string
+
+	// GetReplaySpeed returns how many times faster than recorded the replay backend
+	// plays events back, 0 to play them without waiting
+	GetReplaySpeed() int
+
+	// GetSetter returns the forced wallpaper setter, or "auto" to detect one
+	GetSetter() string
+
+	// GetCustomCommand returns the user-defined setter command, with {path} and {monitor} placeholders (empty = none)
+	GetCustomCommand() string
+
+	// GetSetterMonitor returns the output name substituted for {monitor} in the custom command
+	GetSetterMonitor() string
+
+	// GetHeartbeatInterval returns how often the active player is checked for liveness (0 = disabled)
+	GetHeartbeatInterval() time.Duration
+
+	// GetRestartGrace returns how long a vanished player may take to reappear before playback counts as stopped (0 = at once)
+	GetRestartGrace() time.Duration
+
+	// GetPositionInterval returns how often the playback position of the active player is polled (0 = only on Seeked signals)
+	GetPositionInterval() time.Duration
+
+	// GetRotateAfter returns the track length from which the wallpaper rotates among variations (0 = never)
+	GetRotateAfter() time.Duration
+
+	// GetRotateInterval returns how long each variation stays on screen during rotation
+	GetRotateInterval() time.Duration
+
+	// GetSlideshowDir returns the directory of images cycled through while nothing plays (empty = disabled)
+	GetSlideshowDir() string
+
+	// GetSlideshowAfter returns how long nothing must play before the slideshow starts
+	GetSlideshowAfter() time.Duration
+
+	// GetSlideshowInterval returns how long each slideshow image stays on screen
+	GetSlideshowInterval() time.Duration
+
+	// GetQuietHours returns the daily windows during which the wallpaper is quiet
+	// (empty = never)
+	GetQuietHours() []QuietWindow
+
+	// GetQuietBehavior returns what happens during quiet hours (QuietSkip or QuietDim)
+	GetQuietBehavior() string
+
+	// GetBatterySaver returns when the battery saver applies
+	// (BatterySaverAuto, BatterySaverOn or BatterySaverOff)
+	GetBatterySaver() string
+
+	// GetBatteryDebounce returns the debounce period while saving power,
+	// used when longer than the normal one
+	GetBatteryDebounce() time.Duration
+
+	// GetBatteryCheapRender returns whether rendering takes cheaper paths while saving power
+	GetBatteryCheapRender() bool
+
+	// GetMetered returns when downloads are kept to a minimum
+	// (MeteredAuto, MeteredOn or MeteredOff)
+	GetMetered() string
+
+	// GetHistoryMaxCount returns how many generated wallpapers are kept in the history (0 = none)
+	GetHistoryMaxCount() int
+
+	// GetHistoryMaxSize returns the total size the history may take, in bytes (0 = no limit)
+	GetHistoryMaxSize() int64
+
+	// GetDelivery returns how wallpapers reach the setter (DeliveryFile or DeliveryMemfd)
+	GetDelivery() string
+
+	// GetTransition returns the animation played by the setter on every wallpaper change
+	GetTransition() Transition
+
+	// GetUpload returns the remote storage each wallpaper put on screen is uploaded to
+	GetUpload() Upload
+
+	// GetPhonePush returns how each wallpaper put on screen is pushed to a phone
+	GetPhonePush() PhonePush
+
+	// GetEInk returns the secondary display every wallpaper set is mirrored to
+	GetEInk() EInk
+
+	// GetMultiDisplay returns how the wallpaper is laid out across several
+	// displays (MultiDisplayPrimary or MultiDisplaySpan)
+	GetMultiDisplay() string
+
+	// GetPrivateMode returns the private mode (PrivateOff, PrivateOn or PrivateFreeze)
+	GetPrivateMode() string
+
+	// GetPrivatePlayers returns the players or sources (see MediaMetadata.Source)
+	// private mode applies to (empty = all players)
+	GetPrivatePlayers() []string
+
+	// Changes returns a channel signaled after the configuration was reloaded,
+	// so values read once at startup can be refreshed
+	Changes() <-chan struct{}
 }
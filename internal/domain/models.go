@@ -1,5 +1,15 @@
 package domain
 
+import (
+	"errors"
+	"image"
+	"time"
+)
+
+// ErrOutputUnavailable is wrapped by Processor errors when the wallpaper cannot be
+// written to the output directory, e.g. because the disk is full or read-only
+var ErrOutputUnavailable = errors.New("output directory unavailable")
+
 // PlayerStatus represents the current state of the media player
 type PlayerStatus string
 
@@ -12,22 +22,500 @@ const (
 	StatusStopped PlayerStatus = "Stopped"
 )
 
+// LoopStatus represents the loop setting of the media player
+type LoopStatus string
+
+const (
+	// LoopNone plays until the end of the playlist
+	LoopNone LoopStatus = "None"
+	// LoopTrack repeats the current track
+	LoopTrack LoopStatus = "Track"
+	// LoopPlaylist repeats the whole playlist
+	LoopPlaylist LoopStatus = "Playlist"
+)
+
+// Media kinds, see MediaMetadata.Kind
+const (
+	// KindMusic is a music track, the kind of most events
+	KindMusic = ""
+	// KindAd is an advertisement inserted by the player between tracks
+	KindAd = "ad"
+	// KindPodcast is a podcast episode
+	KindPodcast = "podcast"
+)
+
+// Wallpaper generation modes
+const (
+	// ModeBlur renders a blurred background with the sharp cover centered on top
+	ModeBlur = "blur"
+	// ModeGenerative renders procedural art seeded by the track metadata,
+	// using the artwork only (when available) to tint the result
+	ModeGenerative = "generative"
+	// ModeWaveform renders the waveform of local audio files behind the cover,
+	// falling back to ModeBlur for streams
+	ModeWaveform = "waveform"
+	// ModeAuto picks one of the other modes per track from the artwork
+	// characteristics, or from per-genre rules
+	ModeAuto = "auto"
+)
+
+// Behaviors when playback is paused
+const (
+	// PauseKeep leaves the wallpaper untouched while paused
+	PauseKeep = "keep"
+	// PauseDim re-renders the current wallpaper dimmed and desaturated while paused,
+	// restoring it on resume
+	PauseDim = "dim"
+	// PauseRestore shows the wallpaper captured at startup while paused,
+	// restoring the track's wallpaper on resume
+	PauseRestore = "restore"
+)
+
+// Behaviors when the last player quits
+const (
+	// QuitKeep leaves the wallpaper of the last track on screen
+	QuitKeep = "keep"
+	// QuitRestore shows the wallpaper captured at startup until a player plays again
+	QuitRestore = "restore"
+)
+
+// Behaviors during quiet hours
+const (
+	// QuietSkip leaves the wallpaper untouched until the quiet hours end,
+	// then shows the track playing at that time
+	QuietSkip = "skip"
+	// QuietDim keeps updating the wallpaper with a darker rendering
+	QuietDim = "dim"
+)
+
+// Battery saver modes, when rendering switches to cheaper settings
+const (
+	// BatterySaverAuto saves power while the machine runs on battery
+	BatterySaverAuto = "auto"
+	// BatterySaverOn always saves power
+	BatterySaverOn = "on"
+	// BatterySaverOff never saves power
+	BatterySaverOff = "off"
+)
+
+// Metered modes, when downloads are kept to a minimum
+const (
+	// MeteredAuto saves data while NetworkManager reports a metered connection
+	MeteredAuto = "auto"
+	// MeteredOn always saves data
+	MeteredOn = "on"
+	// MeteredOff never saves data
+	MeteredOff = "off"
+)
+
+// Resampling filters used to scale images, from fastest to sharpest
+const (
+	// FilterNearest copies the nearest pixel, keeping pixel art crisp
+	FilterNearest = "nearest"
+	// FilterBox averages the covered pixels
+	FilterBox = "box"
+	// FilterLinear interpolates bilinearly
+	FilterLinear = "linear"
+	// FilterCatmullRom is a sharp cubic filter, cheaper than Lanczos
+	FilterCatmullRom = "catmull-rom"
+	// FilterLanczos is the sharpest and slowest filter
+	FilterLanczos = "lanczos"
+)
+
+// Filters lists the resampling filters, in documentation order
+var Filters = []string{FilterNearest, FilterBox, FilterLinear, FilterCatmullRom, FilterLanczos}
+
+// Handling of non-square artwork before it is composited
+const (
+	// CoverAspectPreserve keeps the artwork as is
+	CoverAspectPreserve = "preserve"
+	// CoverAspectSquare center-crops the artwork to a square
+	CoverAspectSquare = "square"
+	// CoverAspectTrim removes uniform borders, such as letterboxing, keeping the rest as is
+	// (the same as preserve with border trimming enabled)
+	CoverAspectTrim = "trim"
+)
+
+// Scene output, a JSON description of the composition for external renderers
+const (
+	// SceneOff only renders the wallpaper image
+	SceneOff = "off"
+	// SceneAlongside renders the wallpaper image and writes the scene next to it
+	SceneAlongside = "alongside"
+	// SceneOnly writes the scene instead of rendering the image, and hands its
+	// path to the custom setter command
+	SceneOnly = "only"
+)
+
+// Corner of the wallpaper holding the player icon badge
+const (
+	// BadgeOff draws no badge
+	BadgeOff = "off"
+	// BadgeTopLeft draws the badge in the top-left corner
+	BadgeTopLeft = "top-left"
+	// BadgeTopRight draws the badge in the top-right corner
+	BadgeTopRight = "top-right"
+	// BadgeBottomLeft draws the badge in the bottom-left corner
+	BadgeBottomLeft = "bottom-left"
+	// BadgeBottomRight draws the badge in the bottom-right corner
+	BadgeBottomRight = "bottom-right"
+)
+
+// Badges lists the badge positions, in documentation order
+var Badges = []string{BadgeOff, BadgeTopLeft, BadgeTopRight, BadgeBottomLeft, BadgeBottomRight}
+
+// Playback commands forwarded to the player whose track is on screen
+const (
+	// PlayerPlayPause toggles between playing and paused
+	PlayerPlayPause = "play-pause"
+	// PlayerNext skips to the next track
+	PlayerNext = "next"
+	// PlayerPrevious goes back to the previous track
+	PlayerPrevious = "previous"
+)
+
+// Policies choosing the player that drives the wallpaper while several are playing
+const (
+	// PlayerPolicyRecent follows the player that last started playing or changed track
+	PlayerPolicyRecent = "recent"
+	// PlayerPolicyPriority follows the playing player ranked first in the priority
+	// list, falling back to PlayerPolicyRecent between players of the same rank
+	PlayerPolicyPriority = "priority"
+)
+
+// QuietWindow is a daily time window, in local time, during which the quiet
+// hours behavior applies
+type QuietWindow struct {
+	Start int // Minutes since midnight
+	End   int // Minutes since midnight, before Start when the window spans midnight
+}
+
+// Debounce strategies, how bursts of media events turn into wallpaper updates
+const (
+	// DebounceTrailing updates once events have been quiet for the debounce period
+	DebounceTrailing = "trailing"
+	// DebounceLeading updates at once on the first event after a quiet period,
+	// then like DebounceTrailing for the rest of the burst
+	DebounceLeading = "leading"
+	// DebounceTokenBucket updates at once while tokens are left, refilling one
+	// token per debounce period up to the burst size
+	DebounceTokenBucket = "token_bucket"
+)
+
+// Private modes, for listening that should not be recorded or shown
+const (
+	// PrivateOff handles every track normally
+	PrivateOff = "off"
+	// PrivateOn still updates the wallpaper, but skips notifications and the
+	// on-applied hook, and keeps track names out of the logs
+	PrivateOn = "on"
+	// PrivateFreeze leaves the wallpaper untouched until private mode is turned off
+	PrivateFreeze = "freeze"
+)
+
+// How generated wallpapers are handed to the setter
+const (
+	// DeliveryFile writes the wallpaper to the output directory
+	DeliveryFile = "file"
+	// DeliveryMemfd keeps the wallpaper in an anonymous in-memory file (Linux only),
+	// passed to setters that read the image once as a /proc/<pid>/fd path
+	DeliveryMemfd = "memfd"
+)
+
+// Remote storage each wallpaper put on screen is mirrored to
+const (
+	// UploadOff keeps wallpapers on this machine
+	UploadOff = "off"
+	// UploadS3 uploads to an S3-compatible bucket
+	UploadS3 = "s3"
+	// UploadWebDAV uploads to a WebDAV collection
+	UploadWebDAV = "webdav"
+)
+
+// Upload configures the upload sink. User and Secret are the access key ID and
+// the secret access key for S3, the user name and password for WebDAV.
+type Upload struct {
+	Kind   string // UploadOff, UploadS3 or UploadWebDAV
+	URL    string // Bucket or collection, with the prefix of the uploaded files
+	Region string // Signing region of S3
+	User   string
+	Secret string
+}
+
+// MPD configures the mpd monitor backend, following a Music Player Daemon
+type MPD struct {
+	Address  string // host:port, or the path of a unix socket
+	Password string // Empty when the server needs none
+	MusicDir string // Music directory of the server, empty when it is not local: tracks then have no file URL nor artwork
+}
+
+// Services pushing each wallpaper put on screen to a phone
+const (
+	// PushOff pushes nothing
+	PushOff = "off"
+	// PushKDEConnect shares the wallpaper with a paired device through kdeconnect-cli
+	PushKDEConnect = "kdeconnect"
+	// PushNtfy publishes the wallpaper as the attachment of an ntfy notification
+	PushNtfy = "ntfy"
+)
+
+// PhonePush configures the phone companion push
+type PhonePush struct {
+	Kind   string  // PushOff, PushKDEConnect or PushNtfy
+	Target string  // KDE Connect device name, or ntfy topic URL
+	Token  string  // ntfy access token, empty for public topics
+	Aspect float64 // Width over height of the pushed image, 0 pushes the wallpaper as is
+}
+
+// EInk configures the secondary display sink: every wallpaper set is also
+// converted to dithered grayscale and handed to Command, e.g. a helper driving
+// an e-ink panel over SPI, serial or USB
+type EInk struct {
+	Command string // Run with {path} replaced by the converted image, empty disables the sink
+	Width   int    // Resolution of the display, in pixels
+	Height  int
+	Levels  int // Gray levels the display shows, 2 for black and white
+}
+
+// ArtCache configures the on-disk cache of downloaded artwork
+type ArtCache struct {
+	Enabled bool          // False fetches every artwork again
+	Dir     string        // Directory holding one file per URL, named by its SHA-256
+	TTL     time.Duration // Age after which an artwork is downloaded again, 0 keeps it until evicted
+	MaxSize int64         // Bytes kept at most, the least recently used artwork is evicted first
+}
+
+// Transition is the animation the setter plays when the wallpaper changes.
+// Empty fields keep the setter's own default.
+type Transition struct {
+	Type     string        // Animation, e.g. "grow" or "wipe"
+	Position string        // Where the animation starts, e.g. "top-right" or "1800,40"
+	Duration time.Duration // Length of the animation, 0 for the default
+}
+
+// How one wallpaper is laid out when several displays are connected
+const (
+	// MultiDisplayPrimary renders for the primary display and lets the setter
+	// scale the image on the others
+	MultiDisplayPrimary = "primary"
+	// MultiDisplaySpan renders a single image sized to the virtual desktop, with
+	// a full composition inside each display, for setters that cover every
+	// display with one image
+	MultiDisplaySpan = "span"
+)
+
 // MediaMetadata contains information about the currently playing media
 type MediaMetadata struct {
+	// Player identifies the source player (e.g. "spotify"), empty when unknown
+	Player string
+	// PlayerName is the bus name of the source player, e.g.
+	// "org.mpris.MediaPlayer2.firefox.instance_1_42", empty when unknown
+	PlayerName string
+	// PlayerIdentity is the friendly name of the player (MPRIS Identity), e.g.
+	// "Spotify", empty when the player reports none
+	PlayerIdentity string
+	// PlayerDesktopEntry names the desktop entry of the player without the
+	// .desktop suffix (MPRIS DesktopEntry), e.g. "spotify", empty when unknown
+	PlayerDesktopEntry string
+	// Backend is the monitor backend that reported the event (e.g. "mpris") when
+	// several run together, empty otherwise
+	Backend string
+	// TrackID is the player's identifier of the track (mpris:trackid), empty when
+	// the player sends none. Some players reuse one ID for every track.
+	TrackID string
 	// Title of the currently playing track
 	Title string
-	// Artist name
+	// Artist is the main artist name
 	Artist string
+	// Artists lists all credited artists, starting with the main one (may be empty)
+	Artists []string
+	// ArtistDisplay joins all artists with the configured separator for captions,
+	// empty when the monitor did not build it
+	ArtistDisplay string
 	// Album name
 	Album string
+	// AlbumArtist is the main artist of the album (xesam:albumArtist), empty when
+	// unknown. It differs from Artist on compilations.
+	AlbumArtist string
+	// Genres reported by the player (xesam:genre, may be empty)
+	Genres []string
+	// Year the track was released (xesam:contentCreated), 0 when unknown
+	Year int
+	// DiscNumber and TrackNumber locate the track on its album (xesam:discNumber,
+	// xesam:trackNumber), 0 when unknown
+	DiscNumber  int
+	TrackNumber int
 	// ArtUrl is the URL or local path to the album artwork
 	ArtUrl string
+	// URL is the location of the media itself (xesam:url), a file:// URL for local files
+	URL string
+	// Source names the origin of URL, e.g. "spotify", "bandcamp", "youtube" or "file",
+	// empty when unknown
+	Source string
+	// Kind classifies the media as KindMusic, KindAd or KindPodcast
+	Kind string
+	// Length is the track duration (mpris:length), 0 when unknown
+	Length time.Duration
+	// Position is the playback position when the event was emitted, 0 for a new track
+	Position time.Duration
 	// Status is the current playback status
 	Status PlayerStatus
+	// Volume of the player, 1.0 being full volume, nil when unknown
+	Volume *float64
+	// Shuffle reports whether the player plays the playlist in random order, nil when unknown
+	Shuffle *bool
+	// Loop is the loop setting of the player, empty when unknown
+	Loop LoopStatus
+	// Features holds audio analysis from an enrichment provider, nil when unavailable
+	Features *AudioFeatures
+	// Variation selects an alternative rendering of the same track (0 = default),
+	// used to rotate the wallpaper during long tracks
+	Variation int
+	// Quiet asks for the darker rendering used during quiet hours
+	Quiet bool
+	// LowPower asks for a cheaper rendering, e.g. while on battery
+	LowPower bool
+}
+
+// AllArtists returns every credited artist, or just Artist for players that
+// report a single name
+func (m MediaMetadata) AllArtists() []string {
+	if len(m.Artists) > 0 {
+		return m.Artists
+	}
+	if m.Artist != "" {
+		return []string{m.Artist}
+	}
+	return nil
+}
+
+// PlayerDisplay returns the name shown to the user for the player: its
+// Identity, or else its ID
+func (m MediaMetadata) PlayerDisplay() string {
+	if m.PlayerIdentity != "" {
+		return m.PlayerIdentity
+	}
+	return m.Player
+}
+
+// DisplayArtist returns the artists as shown in captions: ArtistDisplay, or
+// Artist when no display string was built
+func (m MediaMetadata) DisplayArtist() string {
+	if m.ArtistDisplay != "" {
+		return m.ArtistDisplay
+	}
+	return m.Artist
+}
+
+// Player lifecycle events
+const (
+	// PlayerAppeared is sent when a player connects to the bus
+	PlayerAppeared = "appeared"
+	// PlayerVanished is sent when a player leaves the bus
+	PlayerVanished = "vanished"
+)
+
+// PlayerEvent reports a player that appeared or vanished
+type PlayerEvent struct {
+	Kind       string // PlayerAppeared or PlayerVanished
+	PlayerName string // Bus name of the player, e.g. "org.mpris.MediaPlayer2.spotify"
+	Players    int    // Players still running after the event, across every backend
+	// Backend is the monitor backend that reported the event when several run
+	// together, empty otherwise
+	Backend string
+}
+
+// EventStats counts the events a monitor did not hand over because newer ones
+// replaced them while the consumer was busy
+type EventStats struct {
+	// Coalesced events were replaced by a newer event of the same player
+	Coalesced uint64
+	// Dropped events were discarded while too many players had events waiting
+	Dropped uint64
+}
+
+// Circuit breaker states of an external provider
+const (
+	// BreakerClosed lets calls through, the provider answers
+	BreakerClosed = "closed"
+	// BreakerOpen fails calls at once, the provider kept failing
+	BreakerOpen = "open"
+	// BreakerHalfOpen lets one trial call through to see if the provider recovered
+	BreakerHalfOpen = "half-open"
+)
+
+// ProviderState describes the circuit breaker of an external provider, such as
+// an artwork host or an enrichment API
+type ProviderState struct {
+	Name     string
+	State    string    // BreakerClosed, BreakerOpen or BreakerHalfOpen
+	Failures int       // Failed calls in a row
+	RetryAt  time.Time // When an open breaker lets a trial call through, zero otherwise
+}
+
+// AudioFeatures describes the mood of a track as reported by an analysis provider
+type AudioFeatures struct {
+	// Energy is a perceptual measure of intensity (0.0-1.0)
+	Energy float64
+	// Valence describes musical positiveness, high values sound happier (0.0-1.0)
+	Valence float64
+	// Tempo is the estimated tempo in beats per minute
+	Tempo float64
+}
+
+// ModeConfig holds the settings of each generation mode, from the modes section of
+// the config file. Values a mode leaves unset are already resolved to the shared
+// processor settings or the built-in defaults.
+type ModeConfig struct {
+	Blur       BlurConfig
+	Generative GenerativeConfig
+	Waveform   WaveformConfig
+}
+
+// BlurConfig holds the settings of ModeBlur
+type BlurConfig struct {
+	// BlurRadius is the Gaussian blur radius applied to the background
+	BlurRadius float64
+	// CoverSize is the size of the centered cover as a fraction of the screen height
+	CoverSize float64
+	// MaxHeight caps the render height in pixels, the result is scaled up to the
+	// screen (0 = the cap declared by the mode, if any)
+	MaxHeight int
+}
+
+// GenerativeConfig holds the settings of ModeGenerative
+type GenerativeConfig struct {
+	// Particles is the number of flow field strokes
+	Particles int
+	// Shapes is the number of soft circles layered on the gradient
+	Shapes int
+	// MaxHeight caps the render height in pixels, the result is scaled up to the
+	// screen (0 = the cap declared by the mode, if any)
+	MaxHeight int
+}
+
+// WaveformConfig holds the settings of ModeWaveform
+type WaveformConfig struct {
+	// Bars is the number of bars drawn across the screen
+	Bars int
+	// Height is the maximum bar height as a fraction of the screen height
+	Height float64
+	// BlurRadius is the Gaussian blur radius applied to the background
+	BlurRadius float64
+	// CoverSize is the size of the centered cover as a fraction of the screen height
+	CoverSize float64
+	// MaxHeight caps the render height in pixels, the result is scaled up to the
+	// screen (0 = the cap declared by the mode, if any)
+	MaxHeight int
 }
 
 // ScreenResolution holds the display dimensions
 type ScreenResolution struct {
 	Width  int
 	Height int
+	// Outputs are the display areas within the spanned image, relative to its
+	// top-left corner. Empty unless one image spans several displays.
+	Outputs []image.Rectangle
+	// Fallback reports a default size used because no display was detected
+	Fallback bool
 }
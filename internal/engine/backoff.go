@@ -0,0 +1,32 @@
+package engine
+
+import "time"
+
+const (
+	outputBackoffMin = 30 * time.Second
+	outputBackoffMax = 10 * time.Minute
+)
+
+// outputBackoff spaces out attempts to write wallpapers while the output
+// directory is unavailable, doubling the wait after every failed attempt
+type outputBackoff struct {
+	delay   time.Duration
+	retryAt time.Time
+}
+
+// fail records a failed write and returns when the next attempt is allowed
+func (b *outputBackoff) fail(now time.Time) time.Time {
+	b.delay = min(max(2*b.delay, outputBackoffMin), outputBackoffMax)
+	b.retryAt = now.Add(b.delay)
+	return b.retryAt
+}
+
+// reset clears the backoff after a successful write
+func (b *outputBackoff) reset() {
+	*b = outputBackoff{}
+}
+
+// blocked reports whether attempts are still suspended at now
+func (b *outputBackoff) blocked(now time.Time) bool {
+	return now.Before(b.retryAt)
+}
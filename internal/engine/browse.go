@@ -0,0 +1,44 @@
+package engine
+
+import "time"
+
+const (
+	browseChanges = 3               // Track changes that make the user count as browsing...
+	browseWindow  = 5 * time.Second // ...when they happen within this window
+	browseSettle  = time.Minute     // Stable playback after which browsing ends
+)
+
+// browseDetector tells when the user is clearly browsing through tracks, so the
+// scheduler can lengthen the debounce while skipping and keep updates snappy once
+// playback is stable again. Like the scheduler it has no timers of its own.
+type browseDetector struct {
+	changes  []time.Time // Track changes within the last browseWindow
+	track    string      // Track key of the last event
+	changed  time.Time   // When the track last changed
+	browsing bool
+}
+
+// event records an event for the given track at now and reports whether the
+// user is browsing. Events of the track already playing (pause, late artwork)
+// are not track changes.
+func (b *browseDetector) event(key string, now time.Time) bool {
+	if b.browsing && now.Sub(b.changed) >= browseSettle {
+		b.browsing = false
+	}
+	if key == b.track {
+		return b.browsing
+	}
+	b.track, b.changed = key, now
+
+	recent := b.changes[:0]
+	for _, at := range b.changes {
+		if now.Sub(at) < browseWindow {
+			recent = append(recent, at)
+		}
+	}
+	b.changes = append(recent, now)
+	if len(b.changes) >= browseChanges {
+		b.browsing = true
+	}
+	return b.browsing
+}
@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBrowseDetector(t *testing.T) {
+	type event struct {
+		at    time.Duration
+		track string
+	}
+	tests := []struct {
+		name     string
+		events   []event
+		browsing bool
+	}{
+		{"Single Change", []event{{0, "A"}}, false},
+		{"Three Quick Changes", []event{{0, "A"}, {time.Second, "B"}, {2 * time.Second, "C"}}, true},
+		{"Changes Too Far Apart", []event{{0, "A"}, {3 * time.Second, "B"}, {6 * time.Second, "C"}}, false},
+		{"Same Track Events", []event{{0, "A"}, {time.Second, "A"}, {2 * time.Second, "A"}}, false},
+		{"Still Browsing Within A Minute", []event{{0, "A"}, {time.Second, "B"}, {2 * time.Second, "C"}, {50 * time.Second, "D"}}, true},
+		{"Stable For A Minute", []event{{0, "A"}, {time.Second, "B"}, {2 * time.Second, "C"}, {62 * time.Second, "D"}}, false},
+		{"Stable Track Paused", []event{{0, "A"}, {time.Second, "B"}, {2 * time.Second, "C"}, {2 * time.Minute, "C"}}, false},
+	}
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b browseDetector
+			var browsing bool
+			for _, e := range tt.events {
+				browsing = b.event(e.track, start.Add(e.at))
+			}
+			if browsing != tt.browsing {
+				t.Errorf("expected browsing=%v, got %v", tt.browsing, browsing)
+			}
+		})
+	}
+}
+
+// TestSimulate_AdaptiveDebounce verifies the debounce is lengthened while skipping
+// through tracks and shortened again once playback is stable
+func TestSimulate_AdaptiveDebounce(t *testing.T) {
+	track := func(at time.Duration, title string) ScriptEvent {
+		return ScriptEvent{At: at, Artist: "Artist", Title: title}
+	}
+	policy := Policy{Debounce: 500 * time.Millisecond, Browsing: 2 * time.Second}
+	events := []ScriptEvent{
+		track(0, "A"),
+		track(time.Second, "B"),
+		track(2*time.Second, "C"), // Third change within 5s
+		track(2*time.Minute, "D"), // After a minute of stable playback
+	}
+
+	report := Simulate(events, policy)
+
+	expected := []time.Duration{500 * time.Millisecond, 1500 * time.Millisecond, 4 * time.Second, 2*time.Minute + 500*time.Millisecond}
+	if len(report.Steps) != len(expected) {
+		t.Fatalf("expected %d steps, got %d", len(expected), len(report.Steps))
+	}
+	for i, step := range report.Steps {
+		if step.At != expected[i] {
+			t.Errorf("step %d (%s): expected at %v, got %v", i, step.Meta.Title, expected[i], step.At)
+		}
+	}
+
+	policy.Browsing = 0
+	if report := Simulate(events, policy); report.Steps[2].At != 2500*time.Millisecond {
+		t.Errorf("expected a fixed debounce without browsing, got step at %v", report.Steps[2].At)
+	}
+}
@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+// debouncer decides when an event becomes due, before MinInterval is applied.
+// Like the scheduler it has no timers of its own: callers pass the current time.
+type debouncer interface {
+	// event records an event at now and returns when the pending event becomes due
+	event(now time.Time) time.Time
+	// fired records that a wallpaper update ran at now
+	fired(now time.Time)
+}
+
+// newDebouncer returns the strategy selected by the policy, trailing by default
+func newDebouncer(policy Policy) debouncer {
+	switch policy.Strategy {
+	case domain.DebounceLeading:
+		return &leadingDebouncer{period: policy.Debounce}
+	case domain.DebounceTokenBucket:
+		return newTokenBucket(policy.Debounce, policy.Burst)
+	default:
+		return trailingDebouncer{period: policy.Debounce}
+	}
+}
+
+// trailingDebouncer waits until events have been quiet for the whole period
+type trailingDebouncer struct {
+	period time.Duration
+}
+
+func (d trailingDebouncer) event(now time.Time) time.Time {
+	return now.Add(d.period)
+}
+
+func (d trailingDebouncer) fired(time.Time) {}
+
+// leadingDebouncer lets the first event after a quiet period through at once,
+// so a single track change shows without delay, and debounces the rest of the burst
+type leadingDebouncer struct {
+	period time.Duration
+	last   time.Time // Previous event
+	seen   bool      // Whether there was a previous event
+}
+
+func (d *leadingDebouncer) event(now time.Time) time.Time {
+	quiet := !d.seen || now.Sub(d.last) >= d.period
+	d.last, d.seen = now, true
+	if quiet {
+		return now
+	}
+	return now.Add(d.period)
+}
+
+func (d *leadingDebouncer) fired(time.Time) {}
+
+// tokenBucket lets events through at once while tokens are left. Each update
+// takes a token and one token comes back per period, up to burst tokens.
+// Tokens are counted as refill time, one period per token, to keep them exact.
+type tokenBucket struct {
+	period time.Duration
+	limit  time.Duration // Credit of a full bucket
+	credit time.Duration
+	filled time.Time // When credit was last brought up to date
+}
+
+func newTokenBucket(period time.Duration, burst int) *tokenBucket {
+	limit := period * time.Duration(max(burst, 1))
+	return &tokenBucket{period: period, limit: limit, credit: limit}
+}
+
+// refill adds the credit earned since the last refill
+func (b *tokenBucket) refill(now time.Time) {
+	if earned := now.Sub(b.filled); earned > 0 {
+		b.credit = min(b.limit, b.credit+min(earned, b.limit))
+	}
+	b.filled = now
+}
+
+func (b *tokenBucket) event(now time.Time) time.Time {
+	b.refill(now)
+	if b.credit >= b.period {
+		return now
+	}
+	// Due once the missing part of a token has been earned
+	return now.Add(b.period - b.credit)
+}
+
+func (b *tokenBucket) fired(now time.Time) {
+	b.refill(now)
+	b.credit = max(b.credit-b.period, 0)
+}
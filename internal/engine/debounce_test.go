@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+// TestDebouncer drives each strategy with a virtual clock: every step is an event
+// at the given offset, fired at its due time when fire is set
+func TestDebouncer(t *testing.T) {
+	type step struct {
+		at   time.Duration
+		due  time.Duration
+		fire bool
+	}
+	const period = time.Second
+
+	tests := []struct {
+		name   string
+		policy Policy
+		steps  []step
+	}{
+		{
+			name:   "Trailing Waits For Quiet",
+			policy: Policy{Debounce: period, Strategy: domain.DebounceTrailing},
+			steps: []step{
+				{at: 0, due: time.Second},
+				{at: 300 * time.Millisecond, due: 1300 * time.Millisecond, fire: true},
+				{at: 5 * time.Second, due: 6 * time.Second},
+			},
+		},
+		{
+			name:   "Unknown Strategy Is Trailing",
+			policy: Policy{Debounce: period},
+			steps:  []step{{at: 0, due: time.Second}},
+		},
+		{
+			name:   "Leading Passes First Event Of A Burst",
+			policy: Policy{Debounce: period, Strategy: domain.DebounceLeading},
+			steps: []step{
+				{at: 0, due: 0, fire: true},
+				{at: 200 * time.Millisecond, due: 1200 * time.Millisecond},
+				{at: 700 * time.Millisecond, due: 1700 * time.Millisecond, fire: true},
+				{at: 3 * time.Second, due: 3 * time.Second},
+			},
+		},
+		{
+			name:   "Token Bucket Allows A Burst Then Refills",
+			policy: Policy{Debounce: period, Strategy: domain.DebounceTokenBucket, Burst: 2},
+			steps: []step{
+				{at: 0, due: 0, fire: true},
+				{at: 100 * time.Millisecond, due: 100 * time.Millisecond, fire: true},
+				// Bucket empty: 200ms of refill earned, 800ms to go for a token
+				{at: 200 * time.Millisecond, due: time.Second, fire: true},
+				// The refill after the third update is spent again
+				{at: 1500 * time.Millisecond, due: 2 * time.Second},
+			},
+		},
+		{
+			name:   "Token Bucket Without Period Never Waits",
+			policy: Policy{Strategy: domain.DebounceTokenBucket, Burst: 1},
+			steps: []step{
+				{at: 0, due: 0, fire: true},
+				{at: 0, due: 0, fire: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			d := newDebouncer(tt.policy)
+
+			for i, s := range tt.steps {
+				due := d.event(start.Add(s.at)).Sub(start)
+				if due != s.due {
+					t.Fatalf("step %d: expected due at %s, got %s", i, s.due, due)
+				}
+				if s.fire {
+					d.fired(start.Add(due))
+				}
+			}
+		})
+	}
+}
+
+// TestSimulate_Strategies compares strategies on the same rapid skipping
+func TestSimulate_Strategies(t *testing.T) {
+	events := []ScriptEvent{
+		{At: 0, Title: "A"},
+		{At: 200 * time.Millisecond, Title: "B"},
+		{At: 400 * time.Millisecond, Title: "C"},
+	}
+
+	tests := []struct {
+		strategy  string
+		generated int
+		firstAt   time.Duration
+	}{
+		{strategy: domain.DebounceTrailing, generated: 1, firstAt: 1400 * time.Millisecond},
+		{strategy: domain.DebounceLeading, generated: 2, firstAt: 0},
+		{strategy: domain.DebounceTokenBucket, generated: 3, firstAt: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			report := Simulate(events, Policy{Debounce: time.Second, Strategy: tt.strategy, Burst: 3})
+			if report.Generated != tt.generated {
+				t.Errorf("generated: expected %d, got %d", tt.generated, report.Generated)
+			}
+			if len(report.Steps) == 0 || report.Steps[0].At != tt.firstAt {
+				t.Errorf("first step: expected at %s, got %+v", tt.firstAt, report.Steps)
+			}
+		})
+	}
+}
@@ -2,22 +2,53 @@ package engine
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/genricoloni/synest/internal/clock"
 	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/handoff"
+	"github.com/genricoloni/synest/internal/history"
+	"github.com/genricoloni/synest/internal/logctx"
+	"github.com/genricoloni/synest/internal/privacy"
 	"go.uber.org/zap"
 )
 
 // Engine orchestrates the wallpaper generation pipeline.
 // It listens to media events, fetches artwork, processes it, and sets the wallpaper.
 type Engine struct {
-	logger            *zap.Logger
-	cfg               domain.Config
-	monitor           domain.Monitor
-	fetcher           domain.Fetcher
-	processor         domain.Processor
-	executor          domain.Executor
-	originalWallpaper string // Path to wallpaper captured at startup
+	logger    *zap.Logger
+	cfg       domain.Config
+	monitor   domain.Monitor
+	fetcher   domain.Fetcher
+	enricher  domain.Enricher
+	processor domain.Processor
+	executor  domain.Executor
+	reporter  domain.Reporter
+	hook      domain.AppliedHook
+	power     domain.PowerSource // Tells when to save power, e.g. on battery
+	reloader  domain.Reloader    // Rebuilds the processor and executor after a configuration reload
+	clock     clock.Clock        // Drives debouncing, rotation, the slideshow and backoff
+	state     state              // Guarded state shared with Stop and Snapshot
+	output    outputBackoff      // Throttles updates while the output directory cannot be written
+	work      *workQueue         // Wallpaper work run by the loop, manual requests first
+	locked    atomic.Bool        // Session locked or screen saver active, nothing is rendered
+	unlocked  chan struct{}      // Signaled when the session is unlocked
+	handedOff atomic.Bool        // Wallpaper state saved for the next daemon, Stop leaves the wallpaper on screen
+	pinned    string             // Fingerprint of the track whose pinned wallpaper the previous daemon handed over, used by the loop
+}
+
+// composition holds the inputs of a rendered wallpaper, so it can be rendered
+// again for another playback status or variation without fetching or enriching
+type composition struct {
+	imgData []byte
+	meta    domain.MediaMetadata
+	mode    string
 }
 
 // NewEngine creates a new orchestration engine
@@ -26,16 +57,30 @@ func NewEngine(
 	cfg domain.Config,
 	mon domain.Monitor,
 	fetch domain.Fetcher,
+	enrich domain.Enricher,
 	proc domain.Processor,
 	exec domain.Executor,
+	reporter domain.Reporter,
+	hook domain.AppliedHook,
+	power domain.PowerSource,
+	reloader domain.Reloader,
+	clk clock.Clock,
 ) *Engine {
 	return &Engine{
 		logger:    logger,
 		cfg:       cfg,
 		monitor:   mon,
 		fetcher:   fetch,
+		enricher:  enrich,
 		processor: proc,
 		executor:  exec,
+		reporter:  reporter,
+		hook:      hook,
+		power:     power,
+		reloader:  reloader,
+		clock:     clk,
+		work:      newWorkQueue(),
+		unlocked:  make(chan struct{}, 1),
 	}
 }
 
@@ -44,9 +89,18 @@ func NewEngine(
 func (e *Engine) Start(ctx context.Context) error {
 	e.logger.Info("Engine starting...")
 
-	// Try to capture current wallpaper before we start changing it
-	if wallpaper, err := e.executor.GetCurrentWallpaper(ctx); err == nil {
-		e.originalWallpaper = wallpaper
+	setter, reason := e.executor.Setter()
+	e.reporter.SetterSelected(setter, reason)
+
+	// Try to capture current wallpaper before we start changing it. After a
+	// handoff it is our own, the previous daemon knows the original one.
+	wallpaper, err := e.executor.GetCurrentWallpaper(ctx)
+	if e.resumeHandoff(wallpaper, err) {
+		e.logger.Info("Resumed the wallpaper state of the previous daemon",
+			zap.String("original", e.state.original()),
+			zap.String("mode", e.state.mode()))
+	} else if err == nil {
+		e.state.setOriginal(wallpaper)
 		e.logger.Info("Captured original wallpaper for restoration",
 			zap.String("path", wallpaper))
 	} else {
@@ -59,19 +113,100 @@ func (e *Engine) Start(ctx context.Context) error {
 }
 
 // runLoop is the main event processing loop with debouncing.
-// Debouncing prevents excessive wallpaper updates when users skip through tracks quickly.
+// Debouncing prevents excessive wallpaper updates when users skip through tracks quickly;
+// the scheduler additionally enforces the minimum update interval and deduplication.
 func (e *Engine) runLoop(ctx context.Context) {
 	events := e.monitor.Events()
 
-	// Debouncing: wait for 500ms of silence before processing
-	// This prevents generating wallpapers for every track during rapid skipping
-	debounceDuration := 500 * time.Millisecond
-	timer := time.NewTimer(debounceDuration)
+	savingPower := e.power.SavePower(ctx)
+	policy := e.policy(savingPower)
+	sched := newScheduler(policy)
+	e.logger.Info("Update policy",
+		zap.Bool("savingPower", savingPower),
+		zap.Duration("debounce", policy.Debounce),
+		zap.String("strategy", policy.Strategy),
+		zap.Duration("browsing", policy.Browsing),
+		zap.Duration("minInterval", policy.MinInterval),
+		zap.Bool("dedup", policy.Dedup))
+
+	timer := e.clock.NewTimer(policy.Debounce)
 	timer.Stop() // Start with stopped timer
 
-	var pendingMeta *domain.MediaMetadata
+	// Rotates long tracks among variations, stopped while nothing qualifies
+	rotation := e.clock.NewTimer(time.Hour)
+	rotation.Stop()
+	scheduleRotation := func() {
+		if interval := e.rotationInterval(); interval > 0 {
+			rotation.Reset(interval)
+		} else {
+			rotation.Stop()
+		}
+	}
+
+	// Starts the slideshow once nothing has played for a while, then advances it
+	var slides *slideshow
+	playing := false
+	idle := e.clock.NewTimer(time.Hour)
+	idle.Stop()
+	setSlideshow := func(dir string) {
+		switch {
+		case dir == "":
+			slides = nil
+			idle.Stop()
+		case slides == nil || slides.dir != dir:
+			enabled := slides != nil
+			slides = &slideshow{dir: dir}
+			if !enabled && !playing {
+				idle.Reset(e.cfg.GetSlideshowAfter())
+			}
+		}
+	}
+	setSlideshow(e.cfg.GetSlideshowDir())
+
+	// Wakes up when quiet hours start or end. A track that starts while updates
+	// are skipped is deferred and shown once they end.
+	var deferred *domain.MediaMetadata
+	quiet := e.clock.NewTimer(time.Hour)
+	quiet.Stop()
+	scheduleQuiet := func() {
+		if _, next := quietAt(e.cfg.GetQuietHours(), e.clock.Now()); !next.IsZero() {
+			quiet.Reset(clock.Until(e.clock, next))
+		} else {
+			quiet.Stop()
+		}
+	}
+	scheduleQuiet()
+	flushDeferred := func() bool {
+		if deferred == nil || e.quietBehavior() == domain.QuietSkip || e.locked.Load() {
+			return false
+		}
+		if playing && e.showDeferred(ctx, *deferred) {
+			scheduleRotation()
+		}
+		deferred = nil
+		return true
+	}
+
+	// Brings back the original wallpaper once the last player quit and did not
+	// restart within the grace
+	var lifecycle <-chan domain.PlayerEvent
+	if src, ok := e.monitor.(domain.PlayerEventSource); ok {
+		lifecycle = src.PlayerEvents()
+	}
+	quit := e.clock.NewTimer(time.Hour)
+	quit.Stop()
+
+	// A mode set at runtime lasts until the configured mode itself changes
+	configuredMode := e.cfg.GetMode()
 
 	for {
+		// Waiting work runs before the next event is taken, manual requests first
+		for j, ok := e.work.pop(); ok; j, ok = e.work.pop() {
+			if j.run(ctx) {
+				scheduleRotation()
+			}
+		}
+
 		select {
 		case <-ctx.Done():
 			e.logger.Info("Engine loop stopped")
@@ -82,82 +217,669 @@ func (e *Engine) runLoop(ctx context.Context) {
 				e.logger.Info("Monitor events channel closed")
 				return
 			}
-			e.logger.Debug("Event received, debouncing...",
-				zap.String("title", meta.Title),
-				zap.String("artist", meta.Artist))
+			// Ads and podcasts may be kept off the wallpaper, the track before stays
+			if meta.Kind != domain.KindMusic && slices.Contains(e.cfg.GetSkipKinds(), meta.Kind) {
+				e.logger.Debug("Skipping event, its kind does not update the wallpaper",
+					zap.String("kind", meta.Kind),
+					zap.String("status", string(meta.Status)))
+				continue
+			}
+			e.logger.Debug("Event received, debouncing...", e.trackFields(meta, "title")...)
+
+			// The battery debounce applies from the first event after unplugging
+			if saving := e.power.SavePower(ctx); saving != savingPower {
+				savingPower = saving
+				policy = e.policy(savingPower)
+				sched.setPolicy(policy)
+				e.logger.Info("Power source changed",
+					zap.Bool("savingPower", savingPower),
+					zap.Duration("debounce", policy.Debounce))
+			}
+
+			// Save the latest event and reset the timer to when it becomes due
+			browsing := sched.isBrowsing()
+			due := sched.push(meta, e.clock.Now())
+			timer.Reset(clock.Until(e.clock, due))
+			if sched.isBrowsing() != browsing {
+				e.logger.Debug("Browsing through tracks, adapting debounce", zap.Bool("browsing", !browsing))
+			}
+			playing = meta.Status == domain.StatusPlaying
 
-			// Save the latest event and reset the debounce timer
-			pendingMeta = &meta
-			timer.Reset(debounceDuration)
+			if slides != nil {
+				if playing {
+					idle.Stop()
+				} else if !e.state.slideshowActive() {
+					idle.Reset(e.cfg.GetSlideshowAfter())
+				}
+			}
 
-		case <-timer.C:
+		case <-timer.C():
 			// Timer expired: user stopped skipping, process the last event
-			if pendingMeta != nil {
-				e.processMetadata(ctx, *pendingMeta)
-				pendingMeta = nil
+			meta, decision, ok := sched.pop(e.clock.Now())
+			if !ok {
+				continue
+			}
+			e.work.push(job{key: "track", priority: priorityAutomatic, run: func(ctx context.Context) bool {
+				if e.locked.Load() {
+					if decision != decisionNotPlaying {
+						deferred = &meta
+					}
+					e.logger.Info("Session locked, wallpaper update deferred",
+						zap.String("status", string(meta.Status)))
+					return false
+				}
+				if e.quietBehavior() == domain.QuietSkip {
+					if decision != decisionNotPlaying {
+						deferred = &meta
+					}
+					e.logger.Info("Quiet hours, wallpaper update deferred",
+						zap.String("status", string(meta.Status)))
+					return false
+				}
+				deferred = nil
+				if e.keepPinned(meta, decision) {
+					return false
+				}
+				return e.apply(ctx, meta, decision)
+			}})
+
+		case <-e.work.ready:
+			// Queued from another goroutine, run at the top of the loop
+
+		case ev := <-lifecycle:
+			e.logger.Debug("Player lifecycle event",
+				zap.String("event", ev.Kind),
+				zap.String("player", ev.PlayerName),
+				zap.Int("players", ev.Players))
+			if ev.Kind == domain.PlayerVanished && ev.Players == 0 {
+				quit.Reset(e.cfg.GetRestartGrace())
+			} else {
+				quit.Stop()
+			}
+
+		case <-quit.C():
+			e.work.push(job{key: "quit", priority: priorityAutomatic, run: e.quit})
+
+		case <-e.unlocked:
+			// The latest track deferred while locked goes on screen
+			if !e.locked.Load() {
+				flushDeferred()
+			}
+
+		case <-rotation.C():
+			e.rotate(ctx)
+			scheduleRotation()
+
+		case <-idle.C():
+			if e.quietBehavior() == domain.QuietSkip {
+				idle.Reset(e.cfg.GetSlideshowInterval())
+				continue
+			}
+			if e.showSlide(ctx, slides) {
+				idle.Reset(e.cfg.GetSlideshowInterval())
+			}
+
+		case <-quiet.C():
+			scheduleQuiet()
+			e.logger.Info("Quiet hours changed", zap.Bool("quiet", e.quietBehavior() != ""))
+			if flushDeferred() {
+				continue
+			}
+			// The wallpaper on screen switches to or from its darker rendering
+			if last, _ := e.state.current(); last != nil && last.meta.Quiet != (e.quietBehavior() == domain.QuietDim) {
+				e.refresh(ctx)
 			}
+
+		case <-e.cfg.Changes():
+			// Settings read once by the loop are refreshed, the rest is read on use
+			policy = e.policy(savingPower)
+			sched.setPolicy(policy)
+			if mode := e.cfg.GetMode(); mode != configuredMode {
+				configuredMode = mode
+				e.state.setMode("")
+			}
+			e.logger.Info("Configuration reloaded",
+				zap.String("mode", e.mode()),
+				zap.Duration("debounce", policy.Debounce),
+				zap.String("strategy", policy.Strategy),
+				zap.Duration("minInterval", policy.MinInterval),
+				zap.Bool("dedup", policy.Dedup))
+			setSlideshow(e.cfg.GetSlideshowDir())
+			scheduleQuiet()
+			// Components reading settings once are rebuilt between runs, never during one
+			e.reloader.Reload(ctx)
+			if !flushDeferred() {
+				e.refresh(ctx)
+			}
+			scheduleRotation()
+		}
+	}
+}
+
+// apply acts on a due event as the scheduler decided. It reports whether a new
+// track went on screen.
+func (e *Engine) apply(ctx context.Context, meta domain.MediaMetadata, decision decision) bool {
+	switch decision {
+	case decisionGenerate:
+		if !e.resume(ctx, meta) {
+			e.processMetadata(ctx, meta)
+			return true
+		}
+	case decisionDuplicate:
+		if !e.resume(ctx, meta) {
+			e.logger.Debug("Track already on screen, skipping wallpaper update", e.trackFields(meta, "track")...)
+		}
+	case decisionNotPlaying:
+		if !e.pause(ctx, meta) {
+			e.logger.Info("Music paused or stopped, skipping wallpaper update",
+				zap.String("status", string(meta.Status)))
 		}
 	}
+	return false
+}
+
+// trackFields names the track in a log line, with its title under key. Private
+// tracks only log that they are private.
+func (e *Engine) trackFields(meta domain.MediaMetadata, key string) []zap.Field {
+	if privacy.ModeFor(e.cfg, meta) != domain.PrivateOff {
+		return []zap.Field{zap.Bool("private", true)}
+	}
+	return []zap.Field{zap.String(key, meta.Title), zap.String("artist", meta.Artist)}
 }
 
 // processMetadata handles the complete wallpaper generation pipeline for a single track
 func (e *Engine) processMetadata(ctx context.Context, meta domain.MediaMetadata) {
+	// Every log line of this run, in all components, carries the run ID and track fingerprint
+	logger := e.logger.With(
+		zap.String("run", logctx.NewRunID()),
+		zap.String("fingerprint", trackFingerprint(meta)))
+	ctx = logctx.WithLogger(ctx, logger)
+
 	// Skip if music is paused or stopped
 	if meta.Status != domain.StatusPlaying {
-		e.logger.Info("Music paused or stopped, skipping wallpaper update",
+		logger.Info("Music paused or stopped, skipping wallpaper update",
 			zap.String("status", string(meta.Status)))
 		return
 	}
 
-	// Skip if no artwork URL is available
-	if meta.ArtUrl == "" {
-		e.logger.Warn("No artwork URL found",
-			zap.String("track", meta.Title),
-			zap.String("artist", meta.Artist))
+	// Private tracks keep their names out of the logs, notifications and hooks
+	private := privacy.ModeFor(e.cfg, meta)
+	if private == domain.PrivateFreeze {
+		logger.Info("Private mode, wallpaper frozen")
 		return
 	}
-
-	e.logger.Info("Processing wallpaper",
+	trackFields := []zap.Field{
 		zap.String("track", meta.Title),
 		zap.String("artist", meta.Artist),
-		zap.String("album", meta.Album))
+		zap.String("album", meta.Album),
+	}
+	if private == domain.PrivateOn {
+		ctx = privacy.WithPrivate(ctx)
+		trackFields = []zap.Field{zap.Bool("private", true)}
+	}
+
+	mode := e.mode()
+	ctx = domain.WithTrack(domain.WithMode(ctx, mode), meta)
+
+	// Skip if no artwork URL is available (generative and auto modes can render without it)
+	artworkOptional := mode == domain.ModeGenerative || mode == domain.ModeAuto
+	if meta.ArtUrl == "" && !artworkOptional {
+		logger.Warn("No artwork URL found", trackFields...)
+		return
+	}
+
+	// The failure was already reported, retrying every track would only repeat it
+	if e.output.blocked(e.clock.Now()) {
+		logger.Debug("Output directory unavailable, skipping wallpaper update",
+			zap.Time("retryAt", e.output.retryAt))
+		return
+	}
+
+	logger.Info("Processing wallpaper", trackFields...)
 
 	// 1. Fetch artwork
-	imgData, err := e.fetcher.Fetch(ctx, meta.ArtUrl)
+	var imgData []byte
+	if meta.ArtUrl != "" {
+		data, err := e.fetcher.Fetch(ctx, meta.ArtUrl)
+		switch {
+		case err == nil:
+			imgData = data
+		case artworkOptional:
+			// Artwork only tints generative art, render with the seeded palette instead
+			logger.Warn("Failed to fetch artwork, using seeded palette", zap.Error(err))
+		default:
+			logger.Error("Failed to fetch artwork", zap.Error(err))
+			e.reporter.Failure(ctx, "fetch", err)
+			return
+		}
+	}
+
+	// Optional: mood analysis for color grading, failures never block the pipeline
+	features, err := e.enricher.AudioFeatures(ctx, meta)
 	if err != nil {
-		e.logger.Error("Failed to fetch artwork", zap.Error(err))
-		return
+		logger.Warn("Failed to fetch audio features, skipping mood grading", zap.Error(err))
 	}
+	meta.Features = features
+	e.renderHints(ctx, &meta)
 
 	// 2. Process image and save to disk
-	mode := e.cfg.GetMode()
-	wallpaperPath, err := e.processor.Generate(imgData, mode)
+	wallpaperPath, err := e.processor.Generate(ctx, imgData, meta, mode)
 	if err != nil {
-		e.logger.Error("Failed to generate wallpaper", zap.Error(err))
+		logger.Error("Failed to generate wallpaper", zap.Error(err))
+		e.reporter.Failure(ctx, "generate", err)
+		e.outputFailed(logger, err)
 		return
 	}
+	e.output.reset()
 
 	// 3. Set wallpaper
 	if err := e.executor.SetWallpaper(ctx, wallpaperPath); err != nil {
-		e.logger.Error("Failed to set wallpaper", zap.Error(err))
+		logger.Error("Failed to set wallpaper", zap.Error(err))
+		e.reporter.Failure(ctx, "set", err)
+		return
+	}
+
+	// 4. Verify the change before acknowledging it
+	if err := e.verify(ctx, wallpaperPath); err != nil {
+		logger.Error("Wallpaper change not applied", zap.Error(err))
+		e.reporter.Failure(ctx, "verify", err)
 		return
 	}
+	e.reporter.Success(ctx, wallpaperPath, meta)
+	e.state.applied(&composition{imgData: imgData, meta: meta, mode: mode}, wallpaperPath, false, e.clock.Now())
 
-	e.logger.Info("Wallpaper updated successfully",
+	logger.Info("Wallpaper updated successfully",
 		zap.String("path", wallpaperPath),
 		zap.String("mode", mode))
+
+	// 5. Acknowledge the change to user scripts, failures only affect the hook
+	if private == domain.PrivateOn {
+		return
+	}
+	if err := e.hook.Applied(ctx, wallpaperPath, meta); err != nil {
+		logger.Warn("On-applied hook failed", zap.Error(err))
+	}
+}
+
+// pause dims the wallpaper or brings back the original one when the track on
+// screen is paused and the pause behavior asks for it. It reports whether the
+// event was handled.
+func (e *Engine) pause(ctx context.Context, meta domain.MediaMetadata) bool {
+	behavior := e.cfg.GetPauseBehavior()
+	if behavior == domain.PauseKeep || meta.Status != domain.StatusPaused {
+		return false
+	}
+	last, dimmed := e.state.current()
+	if last == nil || dimmed || e.state.originalShown() || trackKey(last.meta) != trackKey(meta) {
+		return false
+	}
+
+	if behavior == domain.PauseRestore {
+		return e.showOriginal(ctx, "paused")
+	}
+	next := *last
+	next.meta.Status = domain.StatusPaused
+	return e.rerender(ctx, &next)
+}
+
+// showOriginal puts the wallpaper captured at startup back on screen, for the
+// given reason, until the track plays again. It reports whether it was applied.
+func (e *Engine) showOriginal(ctx context.Context, reason string) bool {
+	original := e.state.original()
+	if original == "" {
+		e.logger.Info("No original wallpaper to restore", zap.String("reason", reason))
+		return false
+	}
+	if err := e.executor.SetWallpaper(ctx, original); err != nil {
+		e.logger.Error("Failed to restore original wallpaper", zap.String("reason", reason), zap.Error(err))
+		return false
+	}
+
+	e.state.showOriginal(e.clock.Now())
+	e.logger.Info("Original wallpaper restored", zap.String("reason", reason), zap.String("path", original))
+	return true
+}
+
+// quit brings back the original wallpaper once the last player quit, when the
+// quit behavior asks for it. Updates skipped by the lock or quiet hours leave
+// the wallpaper as is. It reports whether it was applied.
+func (e *Engine) quit(ctx context.Context) bool {
+	if e.cfg.GetQuitBehavior() != domain.QuitRestore || e.locked.Load() || e.quietBehavior() == domain.QuietSkip {
+		return false
+	}
+	last, _ := e.state.current()
+	if last == nil || e.state.originalShown() || e.state.slideshowActive() {
+		return false
+	}
+	return e.showOriginal(ctx, "player quit")
+}
+
+// resume restores the wallpaper of the track on screen when its playback resumes,
+// after it was dimmed, replaced by the original wallpaper, the slideshow or a
+// wallpaper from the history.
+// It reports whether the event was handled.
+func (e *Engine) resume(ctx context.Context, meta domain.MediaMetadata) bool {
+	last, dimmed := e.state.current()
+	covered := dimmed || e.state.slideshowActive() || e.state.originalShown() || e.state.historyShown()
+	if last == nil || !covered || trackKey(last.meta) != trackKey(meta) {
+		return false
+	}
+	next := *last
+	next.meta.Status = domain.StatusPlaying
+	return e.rerender(ctx, &next)
+}
+
+// rotationInterval returns when the wallpaper on screen should move to its next
+// variation, or 0 when it does not rotate: rotation is disabled, the track is
+// not long enough or the mode offers no variations
+func (e *Engine) rotationInterval() time.Duration {
+	threshold := e.cfg.GetRotateAfter()
+	last, _ := e.state.current()
+	if threshold <= 0 || last == nil || last.meta.Length < threshold {
+		return 0
+	}
+	if e.processor.Variations(last.mode) < 2 {
+		return 0
+	}
+	return e.cfg.GetRotateInterval()
+}
+
+// rotate re-renders the wallpaper on screen with its next variation.
+// A dimmed wallpaper is left alone until playback resumes.
+func (e *Engine) rotate(ctx context.Context) {
+	last, dimmed := e.state.current()
+	if last == nil || dimmed || e.state.slideshowActive() || e.state.originalShown() ||
+		e.state.historyShown() || e.quietBehavior() == domain.QuietSkip || e.locked.Load() {
+		return
+	}
+	next := *last
+	next.meta.Variation = (last.meta.Variation + 1) % e.processor.Variations(last.mode)
+	e.rerender(ctx, &next)
+}
+
+// refresh renders the track on screen again after a configuration reload, so a
+// new mode or new rendering settings show up without waiting for the next track
+func (e *Engine) refresh(ctx context.Context) {
+	last, _ := e.state.current()
+	if last == nil || e.state.slideshowActive() || e.state.originalShown() ||
+		e.state.historyShown() || e.quietBehavior() == domain.QuietSkip {
+		return
+	}
+	next := *last
+	next.mode = e.mode()
+	e.rerender(ctx, &next)
+}
+
+// mode returns the mode set at runtime, or else the configured one
+func (e *Engine) mode() string {
+	if mode := e.state.mode(); mode != "" {
+		return mode
+	}
+	return e.cfg.GetMode()
+}
+
+// SetMode switches the wallpaper mode without a restart and re-renders the
+// wallpaper on screen with it. The mode lasts until the daemon restarts or the
+// mode in the configuration changes. It is safe to call from any goroutine.
+func (e *Engine) SetMode(mode string) error {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if !slices.Contains(e.processor.Modes(), mode) {
+		return fmt.Errorf("unknown mode %q (available: %s)", mode, strings.Join(e.processor.Modes(), ", "))
+	}
+	e.state.setMode(mode)
+
+	// A waiting switch is replaced, it would re-render with the latest mode anyway
+	e.work.push(job{key: "mode", priority: priorityManual, run: func(ctx context.Context) bool {
+		e.logger.Info("Mode switched", zap.String("mode", e.mode()))
+		e.refresh(ctx)
+		return true
+	}})
+	return nil
+}
+
+// SetPrivate switches private mode without a restart. It applies from the next
+// track, the wallpaper on screen stays. It is safe to call from any goroutine.
+func (e *Engine) SetPrivate(mode string) error {
+	private, ok := e.cfg.(domain.PrivateSwitch)
+	if !ok {
+		return errors.New("private mode can only be changed in the config file")
+	}
+	if err := private.SetPrivateMode(mode); err != nil {
+		return err
+	}
+	e.logger.Info("Private mode switched", zap.String("private", e.cfg.GetPrivateMode()))
+	return nil
+}
+
+// Resize renders wallpapers at a new screen resolution, starting with the one on
+// screen. It is safe to call from any goroutine.
+func (e *Engine) Resize(res domain.ScreenResolution) {
+	resizer, ok := e.processor.(domain.Resizer)
+	if !ok {
+		return
+	}
+	e.work.push(job{key: "resize", priority: priorityAutomatic, run: func(ctx context.Context) bool {
+		resizer.Resize(res)
+		e.logger.Info("Screen resolution changed, rendering again",
+			zap.Int("width", res.Width),
+			zap.Int("height", res.Height))
+		e.refresh(ctx)
+		return true
+	}})
+}
+
+// SetLocked suspends wallpaper updates while the session is locked or the screen
+// saver is active, nobody sees the wallpaper then. The latest track is shown
+// once unlocked. It is safe to call from any goroutine.
+func (e *Engine) SetLocked(locked bool) {
+	if e.locked.Swap(locked) == locked {
+		return
+	}
+	e.logger.Info("Session lock changed", zap.Bool("locked", locked))
+	if !locked {
+		select {
+		case e.unlocked <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// ApplyHistory puts a wallpaper from the history back on screen, ahead of any
+// waiting track update. It stays until the next update for the track. It is
+// safe to call from any goroutine.
+func (e *Engine) ApplyHistory(id string) error {
+	entries, err := history.Load(history.Dir(e.cfg.GetOutputDir()))
+	if err != nil {
+		return err
+	}
+	entry, ok := history.Find(entries, id)
+	if !ok {
+		return fmt.Errorf("no wallpaper %q in the history", id)
+	}
+
+	e.work.push(job{key: "history", priority: priorityManual, run: func(ctx context.Context) bool {
+		if err := e.executor.SetWallpaper(ctx, entry.Path); err != nil {
+			e.logger.Error("Failed to apply wallpaper from history", zap.Error(err))
+			return false
+		}
+		e.state.showHistory(entry.Path, e.clock.Now())
+		e.logger.Info("Wallpaper applied from history", zap.String("id", entry.ID))
+		return false
+	}})
+	return nil
+}
+
+// showSlide puts the next slideshow image on screen. It reports whether the
+// slideshow should continue; it stops when the directory has no usable images.
+func (e *Engine) showSlide(ctx context.Context, slides *slideshow) bool {
+	imagePath, err := slides.nextImage()
+	if err != nil {
+		e.logger.Warn("Slideshow stopped", zap.Error(err))
+		return false
+	}
+
+	if err := e.executor.SetWallpaper(ctx, imagePath); err != nil {
+		e.logger.Error("Failed to set slideshow image", zap.Error(err))
+		return true // Try the next image later
+	}
+
+	e.state.slide(imagePath, e.clock.Now())
+	e.logger.Info("Slideshow image set", zap.String("path", imagePath))
+	return true
+}
+
+// rerender renders a variant of the wallpaper on screen (another playback status
+// or variation) from the cached inputs. It reports whether it was applied.
+func (e *Engine) rerender(ctx context.Context, next *composition) bool {
+	logger := e.logger.With(
+		zap.String("run", logctx.NewRunID()),
+		zap.String("fingerprint", trackFingerprint(next.meta)))
+	ctx = logctx.WithLogger(ctx, logger)
+
+	switch privacy.ModeFor(e.cfg, next.meta) {
+	case domain.PrivateFreeze:
+		return false
+	case domain.PrivateOn:
+		ctx = privacy.WithPrivate(ctx)
+	}
+	if e.output.blocked(e.clock.Now()) {
+		return false
+	}
+	e.renderHints(ctx, &next.meta)
+	wallpaperPath, err := e.processor.Generate(ctx, next.imgData, next.meta, next.mode)
+	if err != nil {
+		logger.Error("Failed to re-render wallpaper", zap.Error(err))
+		e.outputFailed(logger, err)
+		return false
+	}
+	e.output.reset()
+	if err := e.executor.SetWallpaper(ctx, wallpaperPath); err != nil {
+		logger.Error("Failed to set wallpaper", zap.Error(err))
+		return false
+	}
+
+	e.state.applied(next, wallpaperPath, next.meta.Status == domain.StatusPaused, e.clock.Now())
+	logger.Info("Wallpaper re-rendered",
+		zap.String("status", string(next.meta.Status)),
+		zap.Int("variation", next.meta.Variation))
+	return true
+}
+
+// renderHints asks the processor for the darker rendering during quiet hours
+// and for the cheaper one while saving power
+func (e *Engine) renderHints(ctx context.Context, meta *domain.MediaMetadata) {
+	meta.Quiet = e.quietBehavior() == domain.QuietDim
+	meta.LowPower = e.cfg.GetBatteryCheapRender() && e.power.SavePower(ctx)
+}
+
+// outputFailed throttles further updates when err means the output directory cannot be written
+func (e *Engine) outputFailed(logger *zap.Logger, err error) {
+	if !errors.Is(err, domain.ErrOutputUnavailable) {
+		return
+	}
+	retryAt := e.output.fail(e.clock.Now())
+	logger.Warn("Output directory unavailable, pausing wallpaper updates",
+		zap.Time("retryAt", retryAt))
+}
+
+// verify checks that the setter reports the new wallpaper as current.
+// Setters that cannot be queried are trusted once their command succeeded.
+func (e *Engine) verify(ctx context.Context, wallpaperPath string) error {
+	current, err := e.executor.GetCurrentWallpaper(ctx)
+	if err != nil {
+		logctx.Logger(ctx, e.logger).Debug("Wallpaper setter cannot be queried, skipping verification",
+			zap.Error(err))
+		return nil
+	}
+	if filepath.Clean(current) != filepath.Clean(wallpaperPath) {
+		return fmt.Errorf("setter reports %s as current wallpaper instead of %s", current, wallpaperPath)
+	}
+	return nil
+}
+
+// HandOff saves the wallpaper state for the daemon replacing this one, and
+// leaves the wallpaper on screen when the engine stops instead of restoring the
+// original one. It is safe to call from any goroutine.
+func (e *Engine) HandOff() error {
+	st := e.state.snapshot()
+	saved := handoff.State{
+		Original:  st.OriginalWallpaper,
+		Wallpaper: st.Wallpaper,
+		Mode:      e.state.mode(),
+		WrittenAt: e.clock.Now(),
+	}
+	if st.History && st.Track != nil {
+		saved.Pinned = trackFingerprint(*st.Track)
+	}
+	if err := handoff.Write(handoff.Path(e.cfg.GetStateDir()), saved); err != nil {
+		return err
+	}
+	e.handedOff.Store(true)
+	e.logger.Info("Wallpaper state handed off", zap.String("wallpaper", saved.Wallpaper))
+	return nil
+}
+
+// resumeHandoff takes over the wallpaper state saved by the previous daemon. It
+// reports false when there is none, or when the wallpaper on screen changed
+// since, e.g. the user set another one in between.
+func (e *Engine) resumeHandoff(current string, currentErr error) bool {
+	saved, err := handoff.Take(handoff.Path(e.cfg.GetStateDir()))
+	if err != nil {
+		e.logger.Warn("Ignoring the handoff of the previous daemon", zap.Error(err))
+		return false
+	}
+	if saved == nil {
+		return false
+	}
+	if currentErr == nil && filepath.Clean(current) != filepath.Clean(saved.Wallpaper) {
+		e.logger.Info("Wallpaper changed since the handoff, ignoring it",
+			zap.String("handedOff", saved.Wallpaper),
+			zap.String("current", current))
+		return false
+	}
+
+	e.state.setOriginal(saved.Original)
+	e.state.setMode(saved.Mode)
+	if saved.Pinned != "" {
+		e.state.showHistory(saved.Wallpaper, e.clock.Now())
+		e.pinned = saved.Pinned
+	}
+	return true
+}
+
+// keepPinned reports whether meta is the track whose wallpaper from the history
+// was handed over by the previous daemon, which then stays on screen. The first
+// update ends the pin either way, as it would have in the previous daemon.
+func (e *Engine) keepPinned(meta domain.MediaMetadata, d decision) bool {
+	pinned := e.pinned
+	e.pinned = ""
+	if pinned == "" || d == decisionNotPlaying || trackFingerprint(meta) != pinned {
+		return false
+	}
+	e.logger.Info("Keeping the wallpaper from the history handed over by the previous daemon")
+	return true
+}
+
+// Snapshot returns a copy of the engine state. It is safe to call from any goroutine.
+func (e *Engine) Snapshot() Status {
+	return e.state.snapshot()
 }
 
 // Stop gracefully stops the engine and restores the original wallpaper
 func (e *Engine) Stop(ctx context.Context) error {
 	e.logger.Info("Engine stopping...")
 
+	if e.handedOff.Load() {
+		e.logger.Info("Leaving the wallpaper on screen for the next daemon")
+		return nil
+	}
+
 	// Restore original wallpaper if we captured one
-	if e.originalWallpaper != "" {
+	if original := e.state.original(); original != "" {
 		e.logger.Info("Restoring original wallpaper",
-			zap.String("path", e.originalWallpaper))
+			zap.String("path", original))
 
-		if err := e.executor.SetWallpaper(ctx, e.originalWallpaper); err != nil {
+		if err := e.executor.SetWallpaper(ctx, original); err != nil {
 			e.logger.Error("Failed to restore original wallpaper", zap.Error(err))
 			return err
 		}
@@ -0,0 +1,1100 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/history"
+	"github.com/genricoloni/synest/internal/logctx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestProcessMetadata_RunContext verifies that every pipeline step logs with the
+// run ID and track fingerprint of the run it belongs to
+func TestProcessMetadata_RunContext(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	steps := &fakePipeline{}
+	eng := NewEngine(zap.New(core), &mockConfig{mode: domain.ModeBlur}, nil, steps, steps, steps, steps, steps, steps, steps, steps, clock.New())
+
+	meta := domain.MediaMetadata{Title: "Song", Artist: "Artist", ArtUrl: "https://example.com/a.jpg", Status: domain.StatusPlaying}
+	eng.processMetadata(context.Background(), meta)
+	eng.processMetadata(context.Background(), meta)
+
+	runs := map[string]int{}
+	for _, entry := range logs.All() {
+		fields := entry.ContextMap()
+		run, _ := fields["run"].(string)
+		if run == "" {
+			t.Errorf("log %q has no run ID", entry.Message)
+			continue
+		}
+		if fields["fingerprint"] != trackFingerprint(meta) {
+			t.Errorf("log %q: expected fingerprint %s, got %v", entry.Message, trackFingerprint(meta), fields["fingerprint"])
+		}
+		runs[run]++
+	}
+
+	if len(runs) != 2 {
+		t.Fatalf("expected 2 distinct runs, got %v", runs)
+	}
+	for run, count := range runs {
+		// Engine start and end lines plus one line from each of the 4 components
+		if count != 6 {
+			t.Errorf("run %s: expected 6 log lines, got %d", run, count)
+		}
+	}
+}
+
+// TestProcessMetadata_AppliedHook verifies the hook only runs once the setter
+// reports the new wallpaper as current
+func TestProcessMetadata_AppliedHook(t *testing.T) {
+	tests := []struct {
+		name            string
+		stale           bool
+		expectedApplied int
+	}{
+		{name: "Verified Change", expectedApplied: 1},
+		{name: "Setter Kept Old Wallpaper", stale: true, expectedApplied: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := &fakePipeline{stale: tt.stale}
+			eng := NewEngine(zap.NewNop(), &mockConfig{mode: domain.ModeBlur}, nil, steps, steps, steps, steps, steps, steps, steps, steps, clock.New())
+
+			meta := domain.MediaMetadata{Title: "Song", Artist: "Artist", ArtUrl: "https://example.com/a.jpg", Status: domain.StatusPlaying}
+			eng.processMetadata(context.Background(), meta)
+
+			if steps.applied != tt.expectedApplied {
+				t.Errorf("expected %d hook runs, got %d", tt.expectedApplied, steps.applied)
+			}
+			if tt.stale && steps.failedStep != "verify" {
+				t.Errorf("expected verify failure to be reported, got %q", steps.failedStep)
+			}
+		})
+	}
+}
+
+// TestPrivateMode verifies private tracks still update the wallpaper without
+// running the hook, and leave it untouched when frozen. Their names never reach
+// the logs.
+func TestPrivateMode(t *testing.T) {
+	tests := []struct {
+		name              string
+		privateMode       string
+		expectedGenerated int
+		expectedApplied   int
+	}{
+		{name: "Off", privateMode: domain.PrivateOff, expectedGenerated: 1, expectedApplied: 1},
+		{name: "On", privateMode: domain.PrivateOn, expectedGenerated: 1, expectedApplied: 0},
+		{name: "Freeze", privateMode: domain.PrivateFreeze, expectedGenerated: 0, expectedApplied: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := &fakePipeline{}
+			cfg := &mockConfig{mode: domain.ModeBlur, privateMode: tt.privateMode}
+			core, logs := observer.New(zap.DebugLevel)
+			eng := NewEngine(zap.New(core), cfg, nil, steps, steps, steps, steps, steps, steps, steps, steps, clock.New())
+
+			meta := domain.MediaMetadata{Title: "Song", Artist: "Artist", ArtUrl: "https://example.com/a.jpg", Status: domain.StatusPlaying}
+			eng.processMetadata(context.Background(), meta)
+			eng.apply(context.Background(), meta, decisionDuplicate)
+
+			if len(steps.generated) != tt.expectedGenerated {
+				t.Errorf("expected %d renderings, got %d", tt.expectedGenerated, len(steps.generated))
+			}
+			if steps.applied != tt.expectedApplied {
+				t.Errorf("expected %d hook runs, got %d", tt.expectedApplied, steps.applied)
+			}
+
+			named := 0
+			for _, entry := range logs.All() {
+				for _, value := range entry.ContextMap() {
+					if value == "Song" || value == "Artist" {
+						named++
+					}
+				}
+			}
+			if private := tt.privateMode != domain.PrivateOff; private == (named > 0) {
+				t.Errorf("expected private %v, the track was named %d times in the logs", private, named)
+			}
+		})
+	}
+}
+
+// TestPauseDim verifies the paused track is re-rendered dimmed from the cached
+// inputs and restored on resume, without running the fetch step again
+func TestPauseDim(t *testing.T) {
+	tests := []struct {
+		name          string
+		pauseBehavior string
+		expected      []domain.PlayerStatus
+	}{
+		{
+			name:          "Dim",
+			pauseBehavior: domain.PauseDim,
+			expected:      []domain.PlayerStatus{domain.StatusPlaying, domain.StatusPaused, domain.StatusPlaying},
+		},
+		{
+			name:          "Keep",
+			pauseBehavior: domain.PauseKeep,
+			expected:      []domain.PlayerStatus{domain.StatusPlaying},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := &fakePipeline{}
+			cfg := &mockConfig{mode: domain.ModeBlur, pauseBehavior: tt.pauseBehavior}
+			eng := NewEngine(zap.NewNop(), cfg, nil, steps, steps, steps, steps, steps, steps, steps, steps, clock.New())
+
+			playing := domain.MediaMetadata{Title: "Song", Artist: "Artist", ArtUrl: "https://example.com/a.jpg", Status: domain.StatusPlaying}
+			paused := playing
+			paused.Status = domain.StatusPaused
+			other := playing
+			other.Title = "Other"
+			other.Status = domain.StatusPaused
+
+			eng.processMetadata(context.Background(), playing)
+			eng.pause(context.Background(), other) // Not the track on screen
+			eng.pause(context.Background(), paused)
+			eng.pause(context.Background(), paused) // Already dimmed
+			eng.resume(context.Background(), playing)
+			eng.resume(context.Background(), playing) // Already restored
+
+			if len(steps.generated) != len(tt.expected) {
+				t.Fatalf("expected renderings %v, got %v", tt.expected, steps.generated)
+			}
+			for i := range tt.expected {
+				if steps.generated[i] != tt.expected[i] {
+					t.Errorf("expected renderings %v, got %v", tt.expected, steps.generated)
+					break
+				}
+			}
+		})
+	}
+}
+
+// TestPauseRestore verifies the original wallpaper is shown while the track on
+// screen is paused and the track is rendered again on resume
+func TestPauseRestore(t *testing.T) {
+	steps := &fakePipeline{}
+	cfg := &mockConfig{mode: domain.ModeBlur, pauseBehavior: domain.PauseRestore, rotateAfter: time.Minute}
+	eng := NewEngine(zap.NewNop(), cfg, nil, steps, steps, steps, steps, steps, steps, steps, steps, clock.New())
+	eng.state.setOriginal("/home/user/original.png")
+
+	playing := domain.MediaMetadata{Title: "Song", Artist: "Artist", ArtUrl: "https://example.com/a.jpg", Length: time.Hour, Status: domain.StatusPlaying}
+	paused := playing
+	paused.Status = domain.StatusPaused
+
+	eng.processMetadata(context.Background(), playing)
+	if !eng.pause(context.Background(), paused) {
+		t.Fatal("expected the pause to be handled")
+	}
+	if steps.current != "/home/user/original.png" || !eng.Snapshot().Restored {
+		t.Fatalf("expected the original wallpaper while paused, got %s", steps.current)
+	}
+	eng.pause(context.Background(), paused) // Already restored
+	eng.rotate(context.Background())        // Must not cover the original wallpaper
+
+	if !eng.resume(context.Background(), playing) {
+		t.Fatal("expected the resume to be handled")
+	}
+	if steps.current != "/tmp/wallpaper.jpg" || eng.Snapshot().Restored {
+		t.Errorf("expected the track wallpaper after resume, got %s", steps.current)
+	}
+	expected := []domain.PlayerStatus{domain.StatusPlaying, domain.StatusPlaying}
+	if !slices.Equal(steps.generated, expected) {
+		t.Errorf("expected renderings %v, got %v", expected, steps.generated)
+	}
+}
+
+// TestSnapshot verifies snapshots are deep copies that can be taken while the
+// event loop updates the state (run with -race)
+func TestSnapshot(t *testing.T) {
+	steps := &fakePipeline{}
+	cfg := &mockConfig{mode: domain.ModeBlur, pauseBehavior: domain.PauseDim}
+	eng := NewEngine(zap.NewNop(), cfg, nil, steps, steps, steps, steps, steps, steps, steps, steps, clock.New())
+
+	if got := eng.Snapshot(); got.Track != nil || got.Wallpaper != "" {
+		t.Fatalf("expected empty snapshot before the first update, got %+v", got)
+	}
+
+	playing := domain.MediaMetadata{Title: "Song", Artist: "A", Artists: []string{"A", "B"}, Genres: []string{"Rock"}, ArtUrl: "https://example.com/a.jpg", Status: domain.StatusPlaying}
+	volume, shuffle := 0.5, true
+	playing.Volume, playing.Shuffle = &volume, &shuffle
+	eng.processMetadata(context.Background(), playing)
+
+	paused := playing
+	paused.Status = domain.StatusPaused
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			eng.pause(context.Background(), paused)
+			eng.resume(context.Background(), playing)
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		_ = eng.Snapshot()
+	}
+	<-done
+
+	snap := eng.Snapshot()
+	if snap.Track == nil || snap.Track.Title != "Song" || snap.Wallpaper != "/tmp/wallpaper.jpg" || snap.Mode != domain.ModeBlur {
+		t.Fatalf("unexpected snapshot: %+v", snap)
+	}
+	if snap.Paused {
+		t.Error("expected wallpaper restored after the last resume")
+	}
+
+	snap.Track.Artists[1] = "Changed"
+	snap.Track.Genres[0] = "Changed"
+	*snap.Track.Volume, *snap.Track.Shuffle = 1, false
+	if got := eng.Snapshot().Track; got.Artists[1] != "B" || got.Genres[0] != "Rock" || *got.Volume != 0.5 || !*got.Shuffle {
+		t.Error("modifying a snapshot changed the engine state")
+	}
+}
+
+// TestRotation verifies long tracks cycle through the mode's variations
+func TestRotation(t *testing.T) {
+	tests := []struct {
+		name       string
+		length     time.Duration
+		rotates    bool
+		variations []int
+	}{
+		{name: "Long Track", length: time.Hour, rotates: true, variations: []int{0, 1, 2, 0}},
+		{name: "Short Track", length: 3 * time.Minute, rotates: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := &fakePipeline{}
+			cfg := &mockConfig{mode: domain.ModeBlur, rotateAfter: 20 * time.Minute}
+			eng := NewEngine(zap.NewNop(), cfg, nil, steps, steps, steps, steps, steps, steps, steps, steps, clock.New())
+
+			meta := domain.MediaMetadata{Title: "Set", Artist: "DJ", ArtUrl: "https://example.com/a.jpg", Length: tt.length, Status: domain.StatusPlaying}
+			eng.processMetadata(context.Background(), meta)
+
+			if got := eng.rotationInterval() > 0; got != tt.rotates {
+				t.Fatalf("expected rotation %v, got %v", tt.rotates, got)
+			}
+			if !tt.rotates {
+				return
+			}
+
+			for i := 0; i < 3; i++ {
+				eng.rotate(context.Background())
+			}
+			if !slices.Equal(steps.variations, tt.variations) {
+				t.Errorf("expected variations %v, got %v", tt.variations, steps.variations)
+			}
+		})
+	}
+}
+
+// TestSlideshow verifies slides cycle through the directory and the track
+// wallpaper returns when its playback resumes
+func TestSlideshow(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.png", "a.jpg", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	steps := &fakePipeline{}
+	eng := NewEngine(zap.NewNop(), &mockConfig{mode: domain.ModeBlur}, nil, steps, steps, steps, steps, steps, steps, steps, steps, clock.New())
+
+	playing := domain.MediaMetadata{Title: "Song", Artist: "Artist", ArtUrl: "https://example.com/a.jpg", Status: domain.StatusPlaying}
+	eng.processMetadata(context.Background(), playing)
+
+	slides := &slideshow{dir: dir}
+	var shown []string
+	for i := 0; i < 3; i++ {
+		if !eng.showSlide(context.Background(), slides) {
+			t.Fatal("expected slideshow to continue")
+		}
+		shown = append(shown, filepath.Base(steps.current))
+	}
+	if expected := []string{"a.jpg", "b.png", "a.jpg"}; !slices.Equal(shown, expected) {
+		t.Errorf("expected slides %v, got %v", expected, shown)
+	}
+	if !eng.Snapshot().Slideshow {
+		t.Error("expected snapshot to report the slideshow")
+	}
+
+	if !eng.resume(context.Background(), playing) {
+		t.Fatal("expected resume to restore the track wallpaper")
+	}
+	if steps.current != "/tmp/wallpaper.jpg" || eng.Snapshot().Slideshow {
+		t.Errorf("expected track wallpaper after resume, got %q", steps.current)
+	}
+
+	if eng.showSlide(context.Background(), &slideshow{dir: t.TempDir()}) {
+		t.Error("expected slideshow to stop without images")
+	}
+}
+
+// TestOutputBackoff verifies an unwritable output directory suspends updates
+// instead of failing again for every track
+func TestOutputBackoff(t *testing.T) {
+	steps := &fakePipeline{renderErr: fmt.Errorf("write: %w", domain.ErrOutputUnavailable)}
+	eng := NewEngine(zap.NewNop(), &mockConfig{mode: domain.ModeBlur}, nil, steps, steps, steps, steps, steps, steps, steps, steps, clock.New())
+
+	meta := domain.MediaMetadata{Title: "Song", Artist: "Artist", ArtUrl: "https://example.com/a.jpg", Status: domain.StatusPlaying}
+	eng.processMetadata(context.Background(), meta)
+	eng.processMetadata(context.Background(), meta)
+	if len(steps.generated) != 1 {
+		t.Fatalf("expected 1 attempt while the output is unavailable, got %d", len(steps.generated))
+	}
+	if eng.output.delay != outputBackoffMin {
+		t.Errorf("expected %v backoff, got %v", outputBackoffMin, eng.output.delay)
+	}
+
+	// Retry once the backoff expired, the delay doubles after another failure
+	eng.output.retryAt = time.Time{}
+	eng.processMetadata(context.Background(), meta)
+	if eng.output.delay != 2*outputBackoffMin {
+		t.Errorf("expected %v backoff, got %v", 2*outputBackoffMin, eng.output.delay)
+	}
+
+	eng.output.retryAt = time.Time{}
+	steps.renderErr = nil
+	eng.processMetadata(context.Background(), meta)
+	if steps.current != "/tmp/wallpaper.jpg" || eng.output.delay != 0 {
+		t.Errorf("expected recovery after a successful write, got wallpaper %q delay %v", steps.current, eng.output.delay)
+	}
+}
+
+// TestRefresh verifies a reloaded configuration re-renders the track on screen
+func TestRefresh(t *testing.T) {
+	steps := &fakePipeline{}
+	cfg := &mockConfig{mode: domain.ModeBlur}
+	eng := NewEngine(zap.NewNop(), cfg, nil, steps, steps, steps, steps, steps, steps, steps, steps, clock.New())
+
+	eng.refresh(context.Background()) // Nothing on screen yet
+
+	meta := domain.MediaMetadata{Title: "Song", Artist: "Artist", ArtUrl: "https://example.com/a.jpg", Status: domain.StatusPlaying}
+	eng.processMetadata(context.Background(), meta)
+	cfg.mode = domain.ModeGenerative
+	eng.refresh(context.Background())
+
+	if expected := []string{domain.ModeBlur, domain.ModeGenerative}; !slices.Equal(steps.modes, expected) {
+		t.Errorf("expected renderings %v, got %v", expected, steps.modes)
+	}
+	if eng.Snapshot().Mode != domain.ModeGenerative {
+		t.Errorf("expected state to record the new mode, got %s", eng.Snapshot().Mode)
+	}
+}
+
+// TestResize verifies a new screen resolution is applied on the loop and the
+// track on screen is rendered again at it
+func TestResize(t *testing.T) {
+	steps := &fakePipeline{}
+	eng := NewEngine(zap.NewNop(), &mockConfig{mode: domain.ModeBlur}, nil, steps, steps, steps, steps, steps, steps, steps, steps, clock.New())
+
+	meta := domain.MediaMetadata{Title: "Song", Artist: "Artist", ArtUrl: "https://example.com/a.jpg", Status: domain.StatusPlaying}
+	eng.processMetadata(context.Background(), meta)
+
+	eng.Resize(domain.ScreenResolution{Width: 2560, Height: 1440})
+	if len(steps.resized) != 0 {
+		t.Fatal("expected the resize to wait for the loop")
+	}
+	j, ok := eng.work.pop()
+	if !ok {
+		t.Fatal("expected a job to be queued for the loop")
+	}
+	j.run(context.Background())
+
+	if len(steps.resized) != 1 || steps.resized[0].Width != 2560 {
+		t.Errorf("expected the processor to be resized to 2560x1440, got %v", steps.resized)
+	}
+	if len(steps.modes) != 2 {
+		t.Errorf("expected the track on screen to be rendered again, got %d renderings", len(steps.modes))
+	}
+}
+
+// TestSetMode verifies a mode set at runtime is validated, used for the next
+// renderings and dropped once the configured mode changes
+func TestSetMode(t *testing.T) {
+	steps := &fakePipeline{}
+	cfg := &mockConfig{mode: domain.ModeBlur}
+	eng := NewEngine(zap.NewNop(), cfg, nil, steps, steps, steps, steps, steps, steps, steps, steps, clock.New())
+
+	if err := eng.SetMode("sepia"); err == nil {
+		t.Error("expected an unknown mode to be rejected")
+	}
+	if err := eng.SetMode(" Generative "); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if j, ok := eng.work.pop(); !ok || j.priority != priorityManual {
+		t.Error("expected a manual job to be queued for the loop")
+	}
+
+	meta := domain.MediaMetadata{Title: "Song", Artist: "Artist", ArtUrl: "https://example.com/a.jpg", Status: domain.StatusPlaying}
+	eng.processMetadata(context.Background(), meta)
+	if eng.Snapshot().Mode != domain.ModeGenerative {
+		t.Errorf("expected the runtime mode to be used, got %s", eng.Snapshot().Mode)
+	}
+
+	eng.state.setMode("") // What the loop does when the configured mode changes
+	eng.refresh(context.Background())
+	if expected := []string{domain.ModeGenerative, domain.ModeBlur}; !slices.Equal(steps.modes, expected) {
+		t.Errorf("expected renderings %v, got %v", expected, steps.modes)
+	}
+}
+
+func TestSetPrivate(t *testing.T) {
+	steps := &fakePipeline{}
+	eng := NewEngine(zap.NewNop(), &mockConfig{}, nil, steps, steps, steps, steps, steps, steps, steps, steps, clock.New())
+	if err := eng.SetPrivate(domain.PrivateOn); err == nil {
+		t.Error("expected an error for a configuration that cannot be switched")
+	}
+
+	cfg := &switchConfig{mockConfig: &mockConfig{}}
+	eng = NewEngine(zap.NewNop(), cfg, nil, steps, steps, steps, steps, steps, steps, steps, steps, clock.New())
+	if err := eng.SetPrivate(domain.PrivateFreeze); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.privateMode != domain.PrivateFreeze {
+		t.Errorf("expected private mode %s, got %q", domain.PrivateFreeze, cfg.privateMode)
+	}
+}
+
+// TestRunLoop_Debounce drives the event loop with a fake clock: each event is
+// rendered once the debounce period has passed, not a moment earlier
+func TestRunLoop_Debounce(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	mon := &fakeMonitor{events: make(chan domain.MediaMetadata)}
+	steps := &loopPipeline{set: make(chan string, 1)}
+	eng := NewEngine(zap.NewNop(), &mockConfig{mode: domain.ModeBlur, debounce: time.Second}, mon, steps, steps, steps, steps, steps, steps, steps, steps, clk)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		eng.runLoop(ctx)
+		close(done)
+	}()
+
+	for _, title := range []string{"A", "B"} {
+		mon.events <- domain.MediaMetadata{Title: title, Artist: "Artist", ArtUrl: "https://example.com/" + title, Status: domain.StatusPlaying}
+		clk.BlockUntil(1) // Debounce timer armed
+
+		clk.Advance(time.Second - time.Millisecond)
+		select {
+		case <-steps.set:
+			t.Fatalf("%s: wallpaper set before the debounce period", title)
+		default:
+		}
+
+		clk.Advance(time.Millisecond)
+		select {
+		case <-steps.set:
+		case <-time.After(time.Second):
+			t.Fatalf("%s: wallpaper not set after the debounce period", title)
+		}
+	}
+
+	cancel()
+	<-done
+	if len(steps.generated) != 2 {
+		t.Errorf("expected 2 renderings, got %d", len(steps.generated))
+	}
+}
+
+// TestRunLoop_MinInterval verifies a track change within the minimum interval
+// waits until the interval since the previous update has passed
+func TestRunLoop_MinInterval(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	mon := &fakeMonitor{events: make(chan domain.MediaMetadata)}
+	steps := &loopPipeline{set: make(chan string, 1)}
+	cfg := &mockConfig{mode: domain.ModeBlur, debounce: time.Second, minInterval: 10 * time.Second}
+	eng := NewEngine(zap.NewNop(), cfg, mon, steps, steps, steps, steps, steps, steps, steps, steps, clk)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		eng.runLoop(ctx)
+		close(done)
+	}()
+
+	mon.events <- domain.MediaMetadata{Title: "A", Artist: "Artist", ArtUrl: "https://example.com/A", Status: domain.StatusPlaying}
+	clk.BlockUntil(1)
+	clk.Advance(time.Second)
+	select {
+	case <-steps.set:
+	case <-time.After(time.Second):
+		t.Fatal("A: wallpaper not set after the debounce period")
+	}
+
+	// B is debounced at 2s, but only due 10s after A was rendered at 1s
+	mon.events <- domain.MediaMetadata{Title: "B", Artist: "Artist", ArtUrl: "https://example.com/B", Status: domain.StatusPlaying}
+	clk.BlockUntil(1)
+	clk.Advance(10*time.Second - time.Millisecond)
+	select {
+	case <-steps.set:
+		t.Fatal("B: wallpaper set within the minimum interval")
+	default:
+	}
+
+	clk.Advance(time.Millisecond)
+	select {
+	case <-steps.set:
+	case <-time.After(time.Second):
+		t.Fatal("B: wallpaper not set after the minimum interval")
+	}
+
+	cancel()
+	<-done
+}
+
+// TestRunLoop_SkipKinds verifies an ad neither renders nor replaces the track
+// on screen when ads are skipped
+func TestRunLoop_SkipKinds(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	mon := &fakeMonitor{events: make(chan domain.MediaMetadata)}
+	steps := &loopPipeline{set: make(chan string, 1)}
+	cfg := &mockConfig{mode: domain.ModeBlur, debounce: time.Second, skipKinds: []string{domain.KindAd}}
+	eng := NewEngine(zap.NewNop(), cfg, mon, steps, steps, steps, steps, steps, steps, steps, steps, clk)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		eng.runLoop(ctx)
+		close(done)
+	}()
+
+	mon.events <- domain.MediaMetadata{Title: "Song", Artist: "Artist", ArtUrl: "https://example.com/song", Status: domain.StatusPlaying}
+	clk.BlockUntil(1)
+	clk.Advance(time.Second)
+	select {
+	case <-steps.set:
+	case <-time.After(time.Second):
+		t.Fatal("wallpaper not set for the track")
+	}
+
+	// The second send returns once the loop is done with the first
+	ad := domain.MediaMetadata{Title: "Advertisement", ArtUrl: "https://example.com/ad", Kind: domain.KindAd, Status: domain.StatusPlaying}
+	mon.events <- ad
+	mon.events <- ad
+	clk.Advance(time.Second)
+	select {
+	case path := <-steps.set:
+		t.Fatalf("wallpaper %s set for an ad", path)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel()
+	<-done
+	if len(steps.generated) != 1 {
+		t.Errorf("expected only the track to be rendered, got %d renderings", len(steps.generated))
+	}
+	if last, _ := eng.state.current(); last == nil || last.meta.Title != "Song" {
+		t.Error("expected the track to stay on screen")
+	}
+}
+
+// TestRunLoop_ConfigReload verifies a configuration reload rebuilds the
+// components before the wallpaper on screen is rendered again with them
+func TestRunLoop_ConfigReload(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	mon := &fakeMonitor{events: make(chan domain.MediaMetadata)}
+	steps := &loopPipeline{set: make(chan string, 1)}
+	cfg := &mockConfig{mode: domain.ModeBlur, debounce: time.Second, changes: make(chan struct{})}
+	eng := NewEngine(zap.NewNop(), cfg, mon, steps, steps, steps, steps, steps, steps, steps, steps, clk)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		eng.runLoop(ctx)
+		close(done)
+	}()
+
+	mon.events <- domain.MediaMetadata{Title: "Song", Artist: "Artist", ArtUrl: "https://example.com/song", Status: domain.StatusPlaying}
+	clk.BlockUntil(1)
+	clk.Advance(time.Second)
+	for _, step := range []string{"track", "reload"} {
+		select {
+		case <-steps.set:
+		case <-time.After(time.Second):
+			t.Fatalf("wallpaper not set for the %s", step)
+		}
+		if step == "track" {
+			cfg.changes <- struct{}{}
+		}
+	}
+
+	cancel()
+	<-done
+	if len(steps.reloads) != 1 || steps.reloads[0] != 1 || len(steps.generated) != 2 {
+		t.Errorf("expected one reload between the two renderings, got reloads after %v of %d", steps.reloads, len(steps.generated))
+	}
+}
+
+// TestBatterySaver verifies the longer debounce and the cheaper rendering apply
+// only while saving power
+func TestBatterySaver(t *testing.T) {
+	steps := &fakePipeline{}
+	cfg := &mockConfig{
+		mode:               domain.ModeBlur,
+		debounce:           time.Second,
+		batteryDebounce:    5 * time.Second,
+		batteryCheapRender: true,
+	}
+	eng := NewEngine(zap.NewNop(), cfg, nil, steps, steps, steps, steps, steps, steps, steps, steps, clock.New())
+	meta := domain.MediaMetadata{Title: "Song", Artist: "Artist", ArtUrl: "https://example.com/a.jpg", Status: domain.StatusPlaying}
+
+	if got := eng.policy(false).Debounce; got != time.Second {
+		t.Errorf("expected a 1s debounce on AC, got %v", got)
+	}
+	if got := eng.policy(true).Debounce; got != 5*time.Second {
+		t.Errorf("expected a 5s debounce on battery, got %v", got)
+	}
+
+	eng.processMetadata(context.Background(), meta)
+	steps.saving = true
+	eng.processMetadata(context.Background(), domain.MediaMetadata{Title: "Other", Artist: "Artist", ArtUrl: "https://example.com/b.jpg", Status: domain.StatusPlaying})
+
+	if len(steps.lowPower) != 2 || steps.lowPower[0] || !steps.lowPower[1] {
+		t.Errorf("expected a full rendering then a cheap one, got %v", steps.lowPower)
+	}
+}
+
+// TestSessionLock verifies nothing is rendered while the session is locked and
+// the track that started meanwhile is shown once unlocked
+func TestSessionLock(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	mon := &fakeMonitor{events: make(chan domain.MediaMetadata)}
+	steps := &loopPipeline{set: make(chan string, 1)}
+	eng := NewEngine(zap.NewNop(), &mockConfig{mode: domain.ModeBlur, debounce: time.Second}, mon, steps, steps, steps, steps, steps, steps, steps, steps, clk)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		eng.runLoop(ctx)
+		close(done)
+	}()
+
+	eng.SetLocked(true)
+	mon.events <- domain.MediaMetadata{Title: "Song", Artist: "Artist", ArtUrl: "https://example.com/a.jpg", Status: domain.StatusPlaying}
+	clk.BlockUntil(1) // Debounce timer armed
+	clk.Advance(time.Second)
+	select {
+	case <-steps.set:
+		t.Fatal("wallpaper set while the session is locked")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	eng.SetLocked(false)
+	select {
+	case <-steps.set:
+	case <-time.After(time.Second):
+		t.Fatal("deferred track not shown once unlocked")
+	}
+
+	cancel()
+	<-done
+	if len(steps.generated) != 1 {
+		t.Errorf("expected 1 rendering, got %d", len(steps.generated))
+	}
+}
+
+// TestPlayerQuit verifies the original wallpaper comes back once the last player
+// quit and did not restart within the grace
+func TestPlayerQuit(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	mon := &lifecycleMonitor{fakeMonitor: fakeMonitor{events: make(chan domain.MediaMetadata)}, players: make(chan domain.PlayerEvent)}
+	steps := &loopPipeline{set: make(chan string, 1)}
+	cfg := &mockConfig{mode: domain.ModeBlur, debounce: time.Second, quitBehavior: domain.QuitRestore, restartGrace: 3 * time.Second}
+	eng := NewEngine(zap.NewNop(), cfg, mon, steps, steps, steps, steps, steps, steps, steps, steps, clk)
+	eng.state.setOriginal("/home/user/original.png")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		eng.runLoop(ctx)
+		close(done)
+	}()
+
+	mon.events <- domain.MediaMetadata{Title: "Song", Artist: "Artist", ArtUrl: "https://example.com/a.jpg", Status: domain.StatusPlaying}
+	clk.BlockUntil(1)
+	clk.Advance(time.Second)
+	<-steps.set
+
+	// The player restarts within the grace, each send waits for the previous event
+	mon.players <- domain.PlayerEvent{Kind: domain.PlayerVanished, Players: 0}
+	mon.players <- domain.PlayerEvent{Kind: domain.PlayerAppeared, Players: 1}
+	mon.players <- domain.PlayerEvent{Kind: domain.PlayerVanished, Players: 1}
+	clk.Advance(3 * time.Second)
+	select {
+	case path := <-steps.set:
+		t.Fatalf("wallpaper set to %s although the player restarted", path)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	mon.players <- domain.PlayerEvent{Kind: domain.PlayerVanished, Players: 0}
+	clk.BlockUntil(1)
+	clk.Advance(3 * time.Second)
+	select {
+	case path := <-steps.set:
+		if path != "/home/user/original.png" {
+			t.Errorf("expected the original wallpaper, got %s", path)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("original wallpaper not restored once the last player quit")
+	}
+
+	cancel()
+	<-done
+	if !eng.Snapshot().Restored {
+		t.Error("expected the snapshot to report the original wallpaper")
+	}
+}
+
+// TestApplyHistory verifies a wallpaper from the history goes on screen through
+// the work queue and the track comes back when it resumes
+func TestApplyHistory(t *testing.T) {
+	dir := t.TempDir()
+	index := `[{"id": "20240101-120000.000", "path": "/tmp/history.jpg", "size": 1, "created_at": "2024-01-01T12:00:00Z"}]`
+	if err := os.MkdirAll(history.Dir(dir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(history.Dir(dir), history.IndexFile), []byte(index), 0644); err != nil {
+		t.Fatal(err)
+	}
+	steps := &fakePipeline{}
+	cfg := &mockConfig{mode: domain.ModeBlur, outputDir: dir}
+	eng := NewEngine(zap.NewNop(), cfg, nil, steps, steps, steps, steps, steps, steps, steps, steps, clock.New())
+	meta := domain.MediaMetadata{Title: "Song", Artist: "Artist", ArtUrl: "https://example.com/a.jpg", Status: domain.StatusPlaying}
+	eng.processMetadata(context.Background(), meta)
+
+	if err := eng.ApplyHistory("missing"); err == nil {
+		t.Error("expected an unknown ID to be rejected")
+	}
+	if err := eng.ApplyHistory("20240101-120000.000"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	j, ok := eng.work.pop()
+	if !ok || j.priority != priorityManual {
+		t.Fatal("expected a manual job to be queued for the loop")
+	}
+	j.run(context.Background())
+	if steps.current != "/tmp/history.jpg" || !eng.Snapshot().History {
+		t.Errorf("expected the history wallpaper on screen, got %s", steps.current)
+	}
+
+	if !eng.resume(context.Background(), meta) || eng.Snapshot().History {
+		t.Error("expected the track to come back on resume")
+	}
+}
+
+// TestHandOff verifies the next daemon takes over the original wallpaper, the
+// runtime mode and a pinned wallpaper, and the wallpaper stays on screen between them
+func TestHandOff(t *testing.T) {
+	cfg := &mockConfig{mode: domain.ModeBlur, stateDir: t.TempDir()}
+	steps := &fakePipeline{}
+	eng := NewEngine(zap.NewNop(), cfg, nil, steps, steps, steps, steps, steps, steps, steps, steps, clock.New())
+	meta := domain.MediaMetadata{Title: "Song", Artist: "Artist", ArtUrl: "https://example.com/a.jpg", Status: domain.StatusPlaying}
+	eng.state.setOriginal("/home/user/bg.png")
+	eng.state.setMode(domain.ModeGenerative)
+	eng.processMetadata(context.Background(), meta)
+	eng.state.showHistory("/tmp/history.jpg", time.Now())
+
+	if err := eng.HandOff(); err != nil {
+		t.Fatalf("HandOff failed: %v", err)
+	}
+	if err := eng.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if steps.current != "/tmp/wallpaper.jpg" {
+		t.Errorf("expected the wallpaper left on screen, got %s", steps.current)
+	}
+
+	next := NewEngine(zap.NewNop(), cfg, nil, steps, steps, steps, steps, steps, steps, steps, steps, clock.New())
+	if !next.resumeHandoff("/tmp/history.jpg", nil) {
+		t.Fatal("expected the handoff to be taken over")
+	}
+	if next.state.original() != "/home/user/bg.png" || next.mode() != domain.ModeGenerative {
+		t.Errorf("expected the original wallpaper and mode handed over, got %s and %s", next.state.original(), next.mode())
+	}
+	if !next.keepPinned(meta, decisionGenerate) || next.keepPinned(meta, decisionGenerate) {
+		t.Error("expected the pinned wallpaper kept for the first update of its track only")
+	}
+	if next.resumeHandoff("/tmp/history.jpg", nil) {
+		t.Error("expected the handoff to be taken over once")
+	}
+}
+
+func TestTrackFingerprint(t *testing.T) {
+	a := domain.MediaMetadata{Title: "Song", Artist: "Artist"}
+	b := domain.MediaMetadata{Title: "Song", Artist: "Artist", Status: domain.StatusPaused}
+	c := domain.MediaMetadata{Title: "Other", Artist: "Artist"}
+
+	if trackFingerprint(a) != trackFingerprint(b) {
+		t.Error("fingerprint should not depend on playback status")
+	}
+	if trackFingerprint(a) == trackFingerprint(c) {
+		t.Error("different tracks should have different fingerprints")
+	}
+	if len(trackFingerprint(a)) != 12 {
+		t.Errorf("expected 12 hex characters, got %q", trackFingerprint(a))
+	}
+}
+
+// fakePipeline implements every pipeline step, logging through the run context
+type fakePipeline struct {
+	stale      bool // SetWallpaper succeeds without changing the current wallpaper
+	current    string
+	applied    int
+	failedStep string
+	generated  []domain.PlayerStatus // Playback status of every rendering
+	variations []int                 // Variation of every rendering
+	renderErr  error                 // Returned by Generate when set
+	modes      []string              // Mode of every rendering
+	quiet      []bool                // Quiet flag of every rendering
+	lowPower   []bool                // LowPower flag of every rendering
+	saving     bool                  // Reported by SavePower
+	resized    []domain.ScreenResolution
+	reloads    []int // Renderings done before every reload
+}
+
+func (f *fakePipeline) Reload(ctx context.Context) {
+	f.reloads = append(f.reloads, len(f.generated))
+}
+
+func (f *fakePipeline) Resize(res domain.ScreenResolution) {
+	f.resized = append(f.resized, res)
+}
+
+func (f *fakePipeline) SavePower(ctx context.Context) bool {
+	return f.saving
+}
+
+func (f *fakePipeline) Variations(mode string) int {
+	return 3
+}
+
+func (f *fakePipeline) Modes() []string {
+	return []string{domain.ModeBlur, domain.ModeGenerative}
+}
+
+func (f *fakePipeline) Fetch(ctx context.Context, url string) ([]byte, error) {
+	logctx.Logger(ctx, zap.NewNop()).Debug("fetch")
+	return []byte("image"), nil
+}
+
+func (f *fakePipeline) AudioFeatures(ctx context.Context, meta domain.MediaMetadata) (*domain.AudioFeatures, error) {
+	logctx.Logger(ctx, zap.NewNop()).Debug("enrich")
+	return nil, nil
+}
+
+func (f *fakePipeline) Generate(ctx context.Context, imgData []byte, meta domain.MediaMetadata, mode string) (string, error) {
+	logctx.Logger(ctx, zap.NewNop()).Debug("generate")
+	f.generated = append(f.generated, meta.Status)
+	f.variations = append(f.variations, meta.Variation)
+	f.modes = append(f.modes, mode)
+	f.quiet = append(f.quiet, meta.Quiet)
+	f.lowPower = append(f.lowPower, meta.LowPower)
+	if f.renderErr != nil {
+		return "", f.renderErr
+	}
+	return "/tmp/wallpaper.jpg", nil
+}
+
+func (f *fakePipeline) SetWallpaper(ctx context.Context, imagePath string) error {
+	logctx.Logger(ctx, zap.NewNop()).Debug("set")
+	if !f.stale {
+		f.current = imagePath
+	}
+	return nil
+}
+
+func (f *fakePipeline) GetCurrentWallpaper(ctx context.Context) (string, error) {
+	return f.current, nil
+}
+
+func (f *fakePipeline) Setter() (name, reason string) {
+	return "fake", "test"
+}
+
+func (f *fakePipeline) Success(ctx context.Context, wallpaperPath string, meta domain.MediaMetadata) {
+}
+
+func (f *fakePipeline) SetterSelected(name, reason string) {}
+
+func (f *fakePipeline) Failure(ctx context.Context, step string, err error) {
+	f.failedStep = step
+}
+
+func (f *fakePipeline) Applied(ctx context.Context, wallpaperPath string, meta domain.MediaMetadata) error {
+	f.applied++
+	return nil
+}
+
+// loopPipeline reports every wallpaper set, so tests can follow the event loop
+type loopPipeline struct {
+	fakePipeline
+	set chan string
+}
+
+func (l *loopPipeline) SetWallpaper(ctx context.Context, imagePath string) error {
+	if err := l.fakePipeline.SetWallpaper(ctx, imagePath); err != nil {
+		return err
+	}
+	l.set <- imagePath
+	return nil
+}
+
+// fakeMonitor hands the events of a test to the engine
+type fakeMonitor struct {
+	events chan domain.MediaMetadata
+}
+
+func (m *fakeMonitor) Start(ctx context.Context) error { return nil }
+
+func (m *fakeMonitor) Stop(ctx context.Context) error { return nil }
+
+func (m *fakeMonitor) Events() <-chan domain.MediaMetadata { return m.events }
+
+// lifecycleMonitor also hands the player lifecycle events of a test to the engine
+type lifecycleMonitor struct {
+	fakeMonitor
+	players chan domain.PlayerEvent
+}
+
+func (m *lifecycleMonitor) PlayerEvents() <-chan domain.PlayerEvent { return m.players }
+
+// mockConfig implements the parts of domain.Config used by the engine.
+// Other getters are promoted from the nil embedded interface and must not be called.
+type mockConfig struct {
+	domain.Config
+	mode               string
+	pauseBehavior      string
+	quitBehavior       string
+	restartGrace       time.Duration
+	rotateAfter        time.Duration
+	privateMode        string
+	debounce           time.Duration
+	minInterval        time.Duration
+	quietHours         []domain.QuietWindow
+	quietBehavior      string
+	batteryDebounce    time.Duration
+	batteryCheapRender bool
+	outputDir          string
+	stateDir           string
+	skipKinds          []string
+	changes            chan struct{}
+}
+
+func (m *mockConfig) GetOutputDir() string {
+	return m.outputDir
+}
+
+func (m *mockConfig) GetStateDir() string {
+	return m.stateDir
+}
+
+func (m *mockConfig) GetBatteryDebounce() time.Duration {
+	return m.batteryDebounce
+}
+
+func (m *mockConfig) GetBatteryCheapRender() bool {
+	return m.batteryCheapRender
+}
+
+func (m *mockConfig) GetQuietHours() []domain.QuietWindow {
+	return m.quietHours
+}
+
+func (m *mockConfig) GetQuietBehavior() string {
+	return m.quietBehavior
+}
+
+func (m *mockConfig) GetDebounce() time.Duration {
+	return m.debounce
+}
+
+func (m *mockConfig) GetDebounceStrategy() string {
+	return domain.DebounceTrailing
+}
+
+func (m *mockConfig) GetDebounceBurst() int {
+	return 1
+}
+
+func (m *mockConfig) GetDebounceBrowsing() time.Duration {
+	return 0
+}
+
+func (m *mockConfig) GetMinInterval() time.Duration {
+	return m.minInterval
+}
+
+func (m *mockConfig) GetDedup() bool {
+	return false
+}
+
+func (m *mockConfig) GetSkipKinds() []string {
+	return m.skipKinds
+}
+
+func (m *mockConfig) GetSlideshowDir() string {
+	return ""
+}
+
+func (m *mockConfig) Changes() <-chan struct{} {
+	return m.changes
+}
+
+func (m *mockConfig) GetMode() string {
+	return m.mode
+}
+
+func (m *mockConfig) GetPauseBehavior() string {
+	return m.pauseBehavior
+}
+
+func (m *mockConfig) GetQuitBehavior() string {
+	return m.quitBehavior
+}
+
+func (m *mockConfig) GetRestartGrace() time.Duration {
+	return m.restartGrace
+}
+
+func (m *mockConfig) GetRotateAfter() time.Duration {
+	return m.rotateAfter
+}
+
+func (m *mockConfig) GetRotateInterval() time.Duration {
+	return 5 * time.Minute
+}
+
+func (m *mockConfig) GetPrivateMode() string {
+	return m.privateMode
+}
+
+func (m *mockConfig) GetPrivatePlayers() []string {
+	return nil
+}
+
+// switchConfig is a mockConfig whose private mode can be switched at runtime
+type switchConfig struct {
+	*mockConfig
+}
+
+func (c *switchConfig) SetPrivateMode(mode string) error {
+	c.privateMode = mode
+	return nil
+}
@@ -0,0 +1,39 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+// Policy controls when metadata events turn into wallpaper updates
+type Policy struct {
+	Debounce    time.Duration // Quiet period required after the last event, or token refill period
+	Strategy    string        // Debounce strategy, see domain.DebounceTrailing
+	Burst       int           // Updates in a row allowed by the token bucket strategy
+	Browsing    time.Duration // Debounce while the user skips through tracks, when longer than Debounce
+	MinInterval time.Duration // Minimum time between two wallpaper updates
+	Dedup       bool          // Skip updates for the track already on screen
+}
+
+// PolicyFromConfig reads the update policy from the application configuration
+func PolicyFromConfig(cfg domain.Config) Policy {
+	return Policy{
+		Debounce:    cfg.GetDebounce(),
+		Strategy:    cfg.GetDebounceStrategy(),
+		Burst:       cfg.GetDebounceBurst(),
+		Browsing:    cfg.GetDebounceBrowsing(),
+		MinInterval: cfg.GetMinInterval(),
+		Dedup:       cfg.GetDedup(),
+	}
+}
+
+// policy returns the update policy, with the battery debounce while saving
+// power when it is the longer one
+func (e *Engine) policy(savingPower bool) Policy {
+	policy := PolicyFromConfig(e.cfg)
+	if savingPower {
+		policy.Debounce = max(policy.Debounce, e.cfg.GetBatteryDebounce())
+	}
+	return policy
+}
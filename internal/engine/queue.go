@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// priority orders the wallpaper work waiting in the queue
+type priority int
+
+const (
+	priorityAutomatic priority = iota // Track events
+	priorityManual                    // Requests over IPC and re-applies the user asked for
+	priorities
+)
+
+// job is a unit of wallpaper work for the event loop. A job replaces a waiting
+// one with the same key, so repeated requests coalesce.
+type job struct {
+	key      string
+	priority priority
+	run      func(ctx context.Context) bool // Reports whether a new wallpaper went on screen
+}
+
+// workQueue hands wallpaper work to the event loop, its single consumer, so a
+// manual request and an automatic event never race to apply wallpapers.
+// Manual work goes first, work of the same priority in arrival order.
+// It is safe to push from any goroutine.
+type workQueue struct {
+	mu    sync.Mutex
+	jobs  [priorities][]job
+	ready chan struct{} // Signaled after a push
+}
+
+func newWorkQueue() *workQueue {
+	return &workQueue{ready: make(chan struct{}, 1)}
+}
+
+// push queues a job, or replaces the waiting job with the same key
+func (q *workQueue) push(j job) {
+	q.mu.Lock()
+	jobs := q.jobs[j.priority]
+	if i := slices.IndexFunc(jobs, func(w job) bool { return w.key == j.key }); i >= 0 {
+		jobs[i] = j
+	} else {
+		q.jobs[j.priority] = append(jobs, j)
+	}
+	q.mu.Unlock()
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// pop takes the next job, highest priority first. ok is false when the queue is empty.
+func (q *workQueue) pop() (j job, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for p := priorities - 1; p >= 0; p-- {
+		if jobs := q.jobs[p]; len(jobs) > 0 {
+			j = jobs[0]
+			q.jobs[p] = slices.Delete(jobs, 0, 1)
+			return j, true
+		}
+	}
+	return job{}, false
+}
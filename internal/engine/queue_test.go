@@ -0,0 +1,39 @@
+package engine
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+// TestWorkQueue verifies manual work goes first, work of the same priority in
+// arrival order, and a job replaces the waiting one with the same key
+func TestWorkQueue(t *testing.T) {
+	q := newWorkQueue()
+	var ran []string
+	push := func(key string, p priority, name string) {
+		q.push(job{key: key, priority: p, run: func(context.Context) bool {
+			ran = append(ran, name)
+			return false
+		}})
+	}
+
+	push("track", priorityAutomatic, "track A")
+	push("mode", priorityManual, "mode blur")
+	push("reapply", priorityManual, "reapply")
+	push("mode", priorityManual, "mode generative") // Replaces the waiting switch
+	push("track", priorityAutomatic, "track B")     // Replaces the waiting track
+
+	select {
+	case <-q.ready:
+	default:
+		t.Error("expected the consumer to be signaled")
+	}
+
+	for j, ok := q.pop(); ok; j, ok = q.pop() {
+		j.run(context.Background())
+	}
+	if expected := []string{"mode generative", "reapply", "track B"}; !slices.Equal(ran, expected) {
+		t.Errorf("expected %v, got %v", expected, ran)
+	}
+}
@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+// quietAt reports whether now falls within one of the quiet windows, and when
+// that may next change: the end of the current window or the start of the next
+// one. next is zero without windows.
+func quietAt(windows []domain.QuietWindow, now time.Time) (quiet bool, next time.Time) {
+	y, m, d := now.Date()
+	for _, w := range windows {
+		length := w.End - w.Start
+		if length <= 0 {
+			length += 24 * 60 // Spans midnight
+		}
+		// Yesterday's window may still be running past midnight
+		for day := -1; day <= 1; day++ {
+			start := time.Date(y, m, d+day, 0, w.Start, 0, 0, now.Location())
+			end := time.Date(y, m, d+day, 0, w.Start+length, 0, 0, now.Location())
+			var boundary time.Time
+			switch {
+			case !now.Before(start) && now.Before(end):
+				quiet = true
+				boundary = end
+			case start.After(now):
+				boundary = start
+			default:
+				continue
+			}
+			if next.IsZero() || boundary.Before(next) {
+				next = boundary
+			}
+		}
+	}
+	return quiet, next
+}
+
+// quietBehavior returns the quiet hours behavior in effect now, or "" outside quiet hours
+func (e *Engine) quietBehavior() string {
+	if quiet, _ := quietAt(e.cfg.GetQuietHours(), e.clock.Now()); quiet {
+		return e.cfg.GetQuietBehavior()
+	}
+	return ""
+}
+
+// showDeferred puts the track deferred during quiet hours on screen, unless it
+// already is. It reports whether the wallpaper changed.
+func (e *Engine) showDeferred(ctx context.Context, meta domain.MediaMetadata) bool {
+	if e.resume(ctx, meta) {
+		return true
+	}
+	if last, _ := e.state.current(); last != nil && trackKey(last.meta) == trackKey(meta) {
+		return false
+	}
+	e.processMetadata(ctx, meta)
+	return true
+}
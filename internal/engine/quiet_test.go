@@ -0,0 +1,108 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+func TestQuietAt(t *testing.T) {
+	night := []domain.QuietWindow{{Start: 22 * 60, End: 8 * 60}}
+	lunch := []domain.QuietWindow{{Start: 12 * 60, End: 13*60 + 30}}
+	day := func(hour, minute int) time.Time {
+		return time.Date(2024, 1, 1, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name          string
+		windows       []domain.QuietWindow
+		now           time.Time
+		expectedQuiet bool
+		expectedNext  time.Time
+	}{
+		{"No windows", nil, day(23, 0), false, time.Time{}},
+		{"Before midnight", night, day(23, 0), true, day(32, 0)},
+		{"After midnight", night, day(7, 59), true, day(8, 0)},
+		{"Daytime", night, day(12, 0), false, day(22, 0)},
+		{"Window start", lunch, day(12, 0), true, day(13, 30)},
+		{"Window end", lunch, day(13, 30), false, day(36, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quiet, next := quietAt(tt.windows, tt.now)
+			if quiet != tt.expectedQuiet || !next.Equal(tt.expectedNext) {
+				t.Errorf("expected quiet=%v until %v, got quiet=%v until %v", tt.expectedQuiet, tt.expectedNext, quiet, next)
+			}
+		})
+	}
+}
+
+// TestQuietHours_Skip verifies a track starting during quiet hours is only
+// shown once they end
+func TestQuietHours_Skip(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC))
+	mon := &fakeMonitor{events: make(chan domain.MediaMetadata)}
+	steps := &loopPipeline{set: make(chan string, 1)}
+	cfg := &mockConfig{
+		mode:          domain.ModeBlur,
+		debounce:      time.Second,
+		quietHours:    []domain.QuietWindow{{Start: 22 * 60, End: 8 * 60}},
+		quietBehavior: domain.QuietSkip,
+	}
+	eng := NewEngine(zap.NewNop(), cfg, mon, steps, steps, steps, steps, steps, steps, steps, steps, clk)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		eng.runLoop(ctx)
+		close(done)
+	}()
+
+	mon.events <- domain.MediaMetadata{Title: "Song", Artist: "Artist", ArtUrl: "https://example.com/a.jpg", Status: domain.StatusPlaying}
+	clk.BlockUntil(2) // Quiet hours and debounce timers armed
+	clk.Advance(time.Second)
+	select {
+	case <-steps.set:
+		t.Fatal("wallpaper set during quiet hours")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	clk.Advance(9 * time.Hour) // Quiet hours end at 08:00
+	select {
+	case <-steps.set:
+	case <-time.After(time.Second):
+		t.Fatal("deferred track not shown once quiet hours ended")
+	}
+
+	cancel()
+	<-done
+	if len(steps.generated) != 1 {
+		t.Errorf("expected 1 rendering, got %d", len(steps.generated))
+	}
+}
+
+// TestQuietHours_Dim verifies tracks are rendered darker during quiet hours only
+func TestQuietHours_Dim(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC))
+	steps := &fakePipeline{}
+	cfg := &mockConfig{
+		mode:          domain.ModeBlur,
+		quietHours:    []domain.QuietWindow{{Start: 22 * 60, End: 8 * 60}},
+		quietBehavior: domain.QuietDim,
+	}
+	eng := NewEngine(zap.NewNop(), cfg, nil, steps, steps, steps, steps, steps, steps, steps, steps, clk)
+	meta := domain.MediaMetadata{Title: "Song", Artist: "Artist", ArtUrl: "https://example.com/a.jpg", Status: domain.StatusPlaying}
+
+	eng.processMetadata(context.Background(), meta)
+	clk.Advance(9 * time.Hour)
+	eng.refresh(context.Background())
+
+	if len(steps.quiet) != 2 || !steps.quiet[0] || steps.quiet[1] {
+		t.Errorf("expected a dark rendering then a normal one, got %v", steps.quiet)
+	}
+}
@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+// decision is the outcome for a pending event once it becomes due
+type decision int
+
+const (
+	decisionGenerate   decision = iota // Run the pipeline
+	decisionNotPlaying                 // Paused or stopped, nothing to render
+	decisionDuplicate                  // Same track as the current wallpaper
+)
+
+func (d decision) String() string {
+	switch d {
+	case decisionGenerate:
+		return "generate"
+	case decisionNotPlaying:
+		return "not-playing"
+	case decisionDuplicate:
+		return "duplicate"
+	default:
+		return "unknown"
+	}
+}
+
+// scheduler holds the pending event and decides when and whether it is processed.
+// It has no timers of its own: callers pass the current time, so the same logic
+// drives the real engine loop and the accelerated simulation.
+type scheduler struct {
+	policy    Policy
+	debouncer debouncer
+	browse    *browseDetector // Nil unless the policy lengthens the debounce while browsing
+	browsing  debouncer       // Takes over from debouncer while the user is browsing
+	pending   *domain.MediaMetadata
+	debounced time.Time // When the pending event is due according to the debouncer
+	lastRun   time.Time // Zero until the first update
+	lastTrack string    // Track key of the current wallpaper
+}
+
+func newScheduler(policy Policy) *scheduler {
+	s := &scheduler{}
+	s.setPolicy(policy)
+	return s
+}
+
+// setPolicy switches to a new policy, starting its debouncers afresh
+func (s *scheduler) setPolicy(policy Policy) {
+	s.policy = policy
+	s.debouncer = newDebouncer(policy)
+	s.browse, s.browsing = nil, nil
+	if policy.Browsing > policy.Debounce {
+		browsing := policy
+		browsing.Debounce = policy.Browsing
+		s.browse, s.browsing = &browseDetector{}, newDebouncer(browsing)
+	}
+}
+
+// push records an event, replacing any pending one, and returns when it becomes due
+func (s *scheduler) push(meta domain.MediaMetadata, now time.Time) time.Time {
+	s.pending = &meta
+	s.debounced = s.debouncer.event(now)
+	// Both debouncers see every event, so either can take over with its state up to date
+	if s.browse != nil {
+		due := s.browsing.event(now)
+		if s.browse.event(trackKey(meta), now) {
+			s.debounced = due
+		}
+	}
+	return s.deadline()
+}
+
+// isBrowsing reports whether the debounce is lengthened because the user is browsing
+func (s *scheduler) isBrowsing() bool {
+	return s.browse != nil && s.browse.browsing
+}
+
+// deadline returns when the pending event becomes due: when the debouncer lets
+// it through and no sooner than MinInterval after the previous update
+func (s *scheduler) deadline() time.Time {
+	due := s.debounced
+	if !s.lastRun.IsZero() {
+		if earliest := s.lastRun.Add(s.policy.MinInterval); earliest.After(due) {
+			due = earliest
+		}
+	}
+	return due
+}
+
+// hasPending reports whether an event is waiting to be processed
+func (s *scheduler) hasPending() bool {
+	return s.pending != nil
+}
+
+// pop takes the pending event and decides what to do with it.
+// ok is false when no event is pending.
+func (s *scheduler) pop(now time.Time) (meta domain.MediaMetadata, d decision, ok bool) {
+	if s.pending == nil {
+		return domain.MediaMetadata{}, 0, false
+	}
+	meta = *s.pending
+	s.pending = nil
+
+	if meta.Status != domain.StatusPlaying {
+		return meta, decisionNotPlaying, true
+	}
+
+	key := trackKey(meta)
+	if s.policy.Dedup && key == s.lastTrack {
+		return meta, decisionDuplicate, true
+	}
+
+	s.lastRun = now
+	s.lastTrack = key
+	s.debouncer.fired(now)
+	if s.browsing != nil {
+		s.browsing.fired(now)
+	}
+	return meta, decisionGenerate, true
+}
+
+// trackKey identifies what a wallpaper is rendered from
+func trackKey(meta domain.MediaMetadata) string {
+	return strings.Join(meta.AllArtists(), "\x01") + "\x00" + meta.Title + "\x00" + meta.Album + "\x00" + meta.ArtUrl
+}
+
+// trackFingerprint returns a short stable hash of the track, for correlating logs
+// of the same track across runs without logging the full metadata each time
+func trackFingerprint(meta domain.MediaMetadata) string {
+	sum := sha256.Sum256([]byte(trackKey(meta)))
+	return hex.EncodeToString(sum[:6])
+}
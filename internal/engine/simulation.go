@@ -0,0 +1,172 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// Script is a list of timed metadata events, with optional policy overrides
+type Script struct {
+	Config ScriptConfig  `yaml:"config"`
+	Events []ScriptEvent `yaml:"events"`
+}
+
+// ScriptConfig overrides parts of the update policy for a simulation
+type ScriptConfig struct {
+	Debounce    *time.Duration `yaml:"debounce"`
+	Strategy    string         `yaml:"debounce_strategy"`
+	Burst       *int           `yaml:"debounce_burst"`
+	Browsing    *time.Duration `yaml:"debounce_browsing"`
+	MinInterval *time.Duration `yaml:"min_interval"`
+	Dedup       *bool          `yaml:"dedup"`
+}
+
+// ScriptEvent is a metadata event emitted At the given offset from the start
+type ScriptEvent struct {
+	At     time.Duration `yaml:"at"`
+	Title  string        `yaml:"title"`
+	Artist string        `yaml:"artist"`
+	Album  string        `yaml:"album"`
+	ArtUrl string        `yaml:"art_url"`
+	Status string        `yaml:"status"` // Playing (default), Paused or Stopped
+}
+
+// LoadScript parses a YAML simulation script
+func LoadScript(r io.Reader) (*Script, error) {
+	var script Script
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&script); err != nil {
+		return nil, fmt.Errorf("failed to parse script: %w", err)
+	}
+
+	switch script.Config.Strategy {
+	case "", domain.DebounceTrailing, domain.DebounceLeading, domain.DebounceTokenBucket:
+	default:
+		return nil, fmt.Errorf("unknown debounce strategy %q", script.Config.Strategy)
+	}
+	if script.Config.Burst != nil && *script.Config.Burst < 1 {
+		return nil, fmt.Errorf("debounce burst must be at least 1")
+	}
+
+	for i, event := range script.Events {
+		if event.At < 0 {
+			return nil, fmt.Errorf("event %d: negative offset %s", i, event.At)
+		}
+		switch domain.PlayerStatus(event.Status) {
+		case "", domain.StatusPlaying, domain.StatusPaused, domain.StatusStopped:
+		default:
+			return nil, fmt.Errorf("event %d: unknown status %q", i, event.Status)
+		}
+	}
+	return &script, nil
+}
+
+// Apply returns the policy with the script overrides applied
+func (c ScriptConfig) Apply(policy Policy) Policy {
+	if c.Debounce != nil {
+		policy.Debounce = *c.Debounce
+	}
+	if c.Strategy != "" {
+		policy.Strategy = c.Strategy
+	}
+	if c.Burst != nil {
+		policy.Burst = *c.Burst
+	}
+	if c.Browsing != nil {
+		policy.Browsing = *c.Browsing
+	}
+	if c.MinInterval != nil {
+		policy.MinInterval = *c.MinInterval
+	}
+	if c.Dedup != nil {
+		policy.Dedup = *c.Dedup
+	}
+	return policy
+}
+
+// SimulationStep records what the engine did with a due event
+type SimulationStep struct {
+	At       time.Duration
+	Decision string
+	Meta     domain.MediaMetadata
+}
+
+// SimulationReport summarizes a simulation run
+type SimulationReport struct {
+	Policy     Policy
+	Duration   time.Duration // Offset of the last step or event
+	Events     int
+	Generated  int
+	Duplicates int
+	NotPlaying int
+	Superseded int // Events replaced by a newer one before becoming due
+	Steps      []SimulationStep
+}
+
+// Simulate runs the scheduler against the scripted events in virtual time,
+// without fetching or rendering anything
+func Simulate(events []ScriptEvent, policy Policy) SimulationReport {
+	sorted := make([]ScriptEvent, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].At < sorted[j].At })
+
+	var start time.Time // Virtual clock origin, only offsets are reported
+	s := newScheduler(policy)
+	report := SimulationReport{Policy: policy, Events: len(sorted)}
+
+	fire := func() {
+		due := s.deadline()
+		meta, d, _ := s.pop(due)
+		offset := due.Sub(start)
+
+		report.Steps = append(report.Steps, SimulationStep{At: offset, Decision: d.String(), Meta: meta})
+		report.Duration = max(report.Duration, offset)
+		switch d {
+		case decisionGenerate:
+			report.Generated++
+		case decisionDuplicate:
+			report.Duplicates++
+		case decisionNotPlaying:
+			report.NotPlaying++
+		}
+	}
+
+	for _, event := range sorted {
+		at := start.Add(event.At)
+		// A timer due at or before this event fires first
+		if s.hasPending() && !s.deadline().After(at) {
+			fire()
+		}
+		if s.hasPending() {
+			report.Superseded++
+		}
+		s.push(event.metadata(), at)
+		report.Duration = max(report.Duration, event.At)
+	}
+	if s.hasPending() {
+		fire()
+	}
+
+	return report
+}
+
+// metadata converts the scripted event to the monitor's event type
+func (e ScriptEvent) metadata() domain.MediaMetadata {
+	status := domain.PlayerStatus(e.Status)
+	if status == "" {
+		status = domain.StatusPlaying
+	}
+	return domain.MediaMetadata{
+		Title:  e.Title,
+		Artist: e.Artist,
+		Album:  e.Album,
+		ArtUrl: e.ArtUrl,
+		Status: status,
+	}
+}
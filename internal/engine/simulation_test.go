@@ -0,0 +1,175 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSimulate(t *testing.T) {
+	track := func(at time.Duration, title string) ScriptEvent {
+		return ScriptEvent{At: at, Artist: "Artist", Title: title}
+	}
+
+	tests := []struct {
+		name       string
+		policy     Policy
+		events     []ScriptEvent
+		generated  int
+		duplicates int
+		notPlaying int
+		superseded int
+		firstAt    time.Duration
+	}{
+		{
+			name:       "Debounce Collapses Rapid Skipping",
+			policy:     Policy{Debounce: 500 * time.Millisecond},
+			events:     []ScriptEvent{track(0, "A"), track(200*time.Millisecond, "B"), track(400*time.Millisecond, "C")},
+			generated:  1,
+			superseded: 2,
+			firstAt:    900 * time.Millisecond,
+		},
+		{
+			name:      "Events After Debounce Are Separate Updates",
+			policy:    Policy{Debounce: 500 * time.Millisecond},
+			events:    []ScriptEvent{track(0, "A"), track(500*time.Millisecond, "B")},
+			generated: 2,
+			firstAt:   500 * time.Millisecond,
+		},
+		{
+			name:       "Min Interval Delays And Merges Updates",
+			policy:     Policy{Debounce: 100 * time.Millisecond, MinInterval: time.Minute},
+			events:     []ScriptEvent{track(0, "A"), track(10*time.Second, "B"), track(20*time.Second, "C")},
+			generated:  2,
+			superseded: 1,
+			firstAt:    100 * time.Millisecond,
+		},
+		{
+			name:       "Dedup Skips Same Track",
+			policy:     Policy{Debounce: 100 * time.Millisecond, Dedup: true},
+			events:     []ScriptEvent{track(0, "A"), track(time.Second, "A"), track(2*time.Second, "B")},
+			generated:  2,
+			duplicates: 1,
+			firstAt:    100 * time.Millisecond,
+		},
+		{
+			name:   "Paused Track Does Not Reset Dedup",
+			policy: Policy{Debounce: 100 * time.Millisecond, Dedup: true},
+			events: []ScriptEvent{
+				track(0, "A"),
+				{At: time.Second, Artist: "Artist", Title: "A", Status: "Paused"},
+				track(2*time.Second, "A"),
+			},
+			generated:  1,
+			duplicates: 1,
+			notPlaying: 1,
+			firstAt:    100 * time.Millisecond,
+		},
+		{
+			name:      "Unsorted Events",
+			policy:    Policy{Debounce: 100 * time.Millisecond},
+			events:    []ScriptEvent{track(time.Second, "B"), track(0, "A")},
+			generated: 2,
+			firstAt:   100 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Simulate(tt.events, tt.policy)
+
+			if report.Generated != tt.generated {
+				t.Errorf("generated: expected %d, got %d", tt.generated, report.Generated)
+			}
+			if report.Duplicates != tt.duplicates {
+				t.Errorf("duplicates: expected %d, got %d", tt.duplicates, report.Duplicates)
+			}
+			if report.NotPlaying != tt.notPlaying {
+				t.Errorf("not playing: expected %d, got %d", tt.notPlaying, report.NotPlaying)
+			}
+			if report.Superseded != tt.superseded {
+				t.Errorf("superseded: expected %d, got %d", tt.superseded, report.Superseded)
+			}
+			if len(report.Steps) == 0 || report.Steps[0].At != tt.firstAt {
+				t.Errorf("first step: expected at %s, got %+v", tt.firstAt, report.Steps)
+			}
+		})
+	}
+}
+
+// TestSimulate_MinIntervalTiming verifies a delayed update fires when the interval expires
+func TestSimulate_MinIntervalTiming(t *testing.T) {
+	report := Simulate(
+		[]ScriptEvent{{At: 0, Title: "A"}, {At: 5 * time.Second, Title: "B"}},
+		Policy{Debounce: time.Second, MinInterval: 30 * time.Second},
+	)
+
+	if len(report.Steps) != 2 {
+		t.Fatalf("expected 2 steps, got %+v", report.Steps)
+	}
+	// First update at 1s, so the next one may not happen before 31s
+	if got := report.Steps[1].At; got != 31*time.Second {
+		t.Errorf("expected second update at 31s, got %s", got)
+	}
+}
+
+func TestLoadScript(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expectedError string
+	}{
+		{
+			name: "Valid Script",
+			input: `
+config:
+  debounce: 2s
+  dedup: true
+events:
+  - at: 1m30s
+    artist: Queen
+    title: Bohemian Rhapsody
+    status: Paused
+`,
+		},
+		{
+			name:          "Error - Unknown Field",
+			input:         "events:\n  - at: 1s\n    singer: Queen\n",
+			expectedError: "field singer not found",
+		},
+		{
+			name:          "Error - Invalid Duration",
+			input:         "events:\n  - at: soon\n",
+			expectedError: "failed to parse script",
+		},
+		{
+			name:          "Error - Unknown Status",
+			input:         "events:\n  - at: 1s\n    status: playing\n",
+			expectedError: `unknown status "playing"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, err := LoadScript(strings.NewReader(tt.input))
+
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing '%s', got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			policy := script.Config.Apply(Policy{Debounce: time.Second, MinInterval: time.Minute})
+			if policy.Debounce != 2*time.Second || policy.MinInterval != time.Minute || !policy.Dedup {
+				t.Errorf("unexpected policy: %+v", policy)
+			}
+			if len(script.Events) != 1 || script.Events[0].At != 90*time.Second {
+				t.Errorf("unexpected events: %+v", script.Events)
+			}
+		})
+	}
+}
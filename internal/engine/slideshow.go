@@ -0,0 +1,40 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// slideshowExtensions are the image types shown by the slideshow
+var slideshowExtensions = map[string]bool{".jpg": true, ".jpeg": true, ".png": true}
+
+// slideshow cycles through the images of a directory in name order. The directory
+// is listed again for every slide, so images can be added while it runs.
+type slideshow struct {
+	dir  string
+	next int
+}
+
+// nextImage returns the path of the next image, wrapping around at the end
+func (s *slideshow) nextImage() (string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read slideshow directory: %w", err)
+	}
+
+	var images []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && slideshowExtensions[strings.ToLower(filepath.Ext(entry.Name()))] {
+			images = append(images, entry.Name())
+		}
+	}
+	if len(images) == 0 {
+		return "", fmt.Errorf("no images in %s", s.dir)
+	}
+
+	name := images[s.next%len(images)]
+	s.next = (s.next + 1) % len(images)
+	return filepath.Join(s.dir, name), nil
+}
@@ -0,0 +1,184 @@
+package engine
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+// Status is a point-in-time copy of the engine state. It shares no memory with
+// the engine, so it can be handed to other goroutines and kept around.
+type Status struct {
+	OriginalWallpaper string                // Wallpaper captured at startup, restored on exit
+	Wallpaper         string                // Wallpaper on screen, empty until the first update
+	Track             *domain.MediaMetadata // Track the wallpaper was rendered from, nil until the first update
+	Mode              string                // Mode the wallpaper was rendered with
+	Paused            bool                  // Wallpaper on screen is the dimmed rendering of a paused track
+	Slideshow         bool                  // Wallpaper on screen is a slideshow image
+	Restored          bool                  // Original wallpaper is shown while the track is paused
+	History           bool                  // Wallpaper on screen was applied again from the history
+	UpdatedAt         time.Time             // Zero until the first update
+}
+
+// state holds the engine state that outlives a single pipeline run. The event
+// loop is the main writer, but SetMode, Stop and Snapshot use it from other
+// goroutines, so every access goes through mu.
+type state struct {
+	mu                sync.RWMutex
+	originalWallpaper string
+	last              *composition // Never modified once stored
+	wallpaper         string
+	dimmed            bool
+	slideshow         bool
+	restored          bool // Original wallpaper on screen while the track is paused
+	history           bool // Wallpaper from the history on screen
+	updatedAt         time.Time
+	modeOverride      string // Mode set at runtime, empty to follow the configuration
+}
+
+// setOriginal records the wallpaper captured at startup
+func (s *state) setOriginal(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.originalWallpaper = path
+}
+
+// original returns the wallpaper captured at startup
+func (s *state) original() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.originalWallpaper
+}
+
+// applied records a wallpaper that is now on screen
+func (s *state) applied(c *composition, wallpaperPath string, dimmed bool, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.last = c
+	s.wallpaper = wallpaperPath
+	s.dimmed = dimmed
+	s.slideshow = false
+	s.restored = false
+	s.history = false
+	s.updatedAt = now
+}
+
+// slide records a slideshow image that is now on screen. The last composition
+// is kept, so the track can be restored cheaply when it resumes.
+func (s *state) slide(imagePath string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.wallpaper = imagePath
+	s.dimmed = false
+	s.slideshow = true
+	s.restored = false
+	s.history = false
+	s.updatedAt = now
+}
+
+// showHistory records a wallpaper from the history that is now on screen. The
+// last composition is kept to restore the track when it resumes.
+func (s *state) showHistory(path string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.wallpaper = path
+	s.dimmed = false
+	s.slideshow = false
+	s.restored = false
+	s.history = true
+	s.updatedAt = now
+}
+
+// showOriginal records that the original wallpaper is back on screen while the
+// track is paused or after its player quit. The last composition is kept to
+// restore the track on resume.
+func (s *state) showOriginal(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.wallpaper = s.originalWallpaper
+	s.dimmed = false
+	s.slideshow = false
+	s.restored = true
+	s.history = false
+	s.updatedAt = now
+}
+
+// originalShown reports whether the original wallpaper is on screen in place of the last track
+func (s *state) originalShown() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.restored
+}
+
+// slideshowActive reports whether a slideshow image is on screen
+func (s *state) slideshowActive() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.slideshow
+}
+
+// historyShown reports whether a wallpaper from the history is on screen
+func (s *state) historyShown() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.history
+}
+
+// current returns the inputs of the wallpaper on screen and whether it is dimmed
+func (s *state) current() (*composition, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.last, s.dimmed
+}
+
+// setMode records the mode set at runtime, empty to follow the configuration
+func (s *state) setMode(mode string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.modeOverride = mode
+}
+
+// mode returns the mode set at runtime, empty when none is
+func (s *state) mode() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.modeOverride
+}
+
+// snapshot returns a deep copy of the state
+func (s *state) snapshot() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st := Status{
+		OriginalWallpaper: s.originalWallpaper,
+		Wallpaper:         s.wallpaper,
+		Paused:            s.dimmed,
+		Slideshow:         s.slideshow,
+		Restored:          s.restored,
+		History:           s.history,
+		UpdatedAt:         s.updatedAt,
+	}
+	if s.last != nil {
+		track := s.last.meta
+		track.Artists = slices.Clone(track.Artists)
+		track.Genres = slices.Clone(track.Genres)
+		if track.Features != nil {
+			features := *track.Features
+			track.Features = &features
+		}
+		if track.Volume != nil {
+			volume := *track.Volume
+			track.Volume = &volume
+		}
+		if track.Shuffle != nil {
+			shuffle := *track.Shuffle
+			track.Shuffle = &shuffle
+		}
+		st.Track = &track
+		st.Mode = s.last.mode
+	}
+	return st
+}
@@ -0,0 +1,194 @@
+package enrichment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/logctx"
+	"go.uber.org/zap"
+)
+
+const (
+	spotifyTokenURL = "https://accounts.spotify.com/api/token"
+	spotifyAPIURL   = "https://api.spotify.com/v1"
+	tokenExpirySlop = 30 * time.Second // Refresh tokens slightly before they expire
+)
+
+// SpotifyEnricher retrieves audio features from the Spotify Web API using the
+// client credentials flow. It is a no-op when no credentials are configured,
+// and skips lookups on metered connections.
+type SpotifyEnricher struct {
+	logger       *zap.Logger
+	client       *http.Client
+	metered      domain.MeteredSource
+	clientID     string
+	clientSecret string
+	tokenURL     string
+	apiURL       string
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewSpotifyEnricher creates a new Spotify audio-features enricher
+func NewSpotifyEnricher(logger *zap.Logger, cfg domain.Config, metered domain.MeteredSource) *SpotifyEnricher {
+	clientID, clientSecret := cfg.GetSpotifyCredentials()
+	if clientID == "" || clientSecret == "" {
+		logger.Debug("Spotify credentials not configured, mood grading disabled")
+	}
+
+	return &SpotifyEnricher{
+		logger:       logger,
+		client:       &http.Client{Timeout: 5 * time.Second},
+		metered:      metered,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		tokenURL:     spotifyTokenURL,
+		apiURL:       spotifyAPIURL,
+	}
+}
+
+// AudioFeatures returns energy, valence and tempo for Spotify tracks
+func (s *SpotifyEnricher) AudioFeatures(ctx context.Context, meta domain.MediaMetadata) (*domain.AudioFeatures, error) {
+	if s.clientID == "" || s.clientSecret == "" {
+		return nil, nil
+	}
+
+	trackID := spotifyTrackID(meta.URL)
+	if trackID == "" {
+		return nil, nil // Not a Spotify track
+	}
+	if s.metered.Metered(ctx) {
+		logctx.Logger(ctx, s.logger).Debug("Metered connection, skipping audio features")
+		return nil, nil
+	}
+
+	token, err := s.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.apiURL+"/audio-features/"+url.PathEscape(trackID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil // Track has no analysis (local files, podcasts)
+	case http.StatusUnauthorized:
+		s.invalidateToken()
+		return nil, fmt.Errorf("spotify rejected access token")
+	default:
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Energy  float64 `json:"energy"`
+		Valence float64 `json:"valence"`
+		Tempo   float64 `json:"tempo"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode audio features: %w", err)
+	}
+
+	logctx.Logger(ctx, s.logger).Debug("Audio features fetched",
+		zap.String("track", trackID),
+		zap.Float64("energy", body.Energy),
+		zap.Float64("valence", body.Valence),
+		zap.Float64("tempo", body.Tempo))
+
+	return &domain.AudioFeatures{
+		Energy:  body.Energy,
+		Valence: body.Valence,
+		Tempo:   body.Tempo,
+	}, nil
+}
+
+// accessToken returns a cached app token, requesting a new one when expired
+func (s *SpotifyEnricher) accessToken(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Now().Before(s.tokenExpiry) {
+		return s.token, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.SetBasicAuth(s.clientID, s.clientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request failed with status code: %d", resp.StatusCode)
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if body.AccessToken == "" {
+		return "", fmt.Errorf("token response did not contain an access token")
+	}
+
+	s.token = body.AccessToken
+	s.tokenExpiry = time.Now().Add(time.Duration(body.ExpiresIn)*time.Second - tokenExpirySlop)
+	return s.token, nil
+}
+
+// invalidateToken drops the cached token so the next call requests a fresh one
+func (s *SpotifyEnricher) invalidateToken() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = ""
+}
+
+// spotifyTrackID extracts the track ID from a Spotify web URL or URI.
+// Returns an empty string for non-Spotify media.
+func spotifyTrackID(mediaURL string) string {
+	if id, ok := strings.CutPrefix(mediaURL, "spotify:track:"); ok {
+		return id
+	}
+
+	u, err := url.Parse(mediaURL)
+	if err != nil || u.Host != "open.spotify.com" {
+		return ""
+	}
+
+	// Path looks like /track/<id>, optionally with a locale prefix (/intl-it/track/<id>)
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] == "track" {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
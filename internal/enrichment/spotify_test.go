@@ -0,0 +1,192 @@
+package enrichment
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+func TestSpotifyEnricher_AudioFeatures(t *testing.T) {
+	tests := []struct {
+		name           string
+		clientID       string
+		url            string
+		featuresStatus int
+		featuresBody   string
+		expectedError  string
+		expected       *domain.AudioFeatures
+	}{
+		{
+			name:           "Success - Web URL",
+			clientID:       "id",
+			url:            "https://open.spotify.com/track/abc123",
+			featuresStatus: http.StatusOK,
+			featuresBody:   `{"energy":0.8,"valence":0.3,"tempo":128.5}`,
+			expected:       &domain.AudioFeatures{Energy: 0.8, Valence: 0.3, Tempo: 128.5},
+		},
+		{
+			name:           "Success - URI",
+			clientID:       "id",
+			url:            "spotify:track:abc123",
+			featuresStatus: http.StatusOK,
+			featuresBody:   `{"energy":0.1,"valence":0.9,"tempo":90}`,
+			expected:       &domain.AudioFeatures{Energy: 0.1, Valence: 0.9, Tempo: 90},
+		},
+		{
+			name:     "Not Configured",
+			clientID: "",
+			url:      "https://open.spotify.com/track/abc123",
+		},
+		{
+			name:     "Not A Spotify Track",
+			clientID: "id",
+			url:      "file:///home/user/song.mp3",
+		},
+		{
+			name:           "Track Without Analysis",
+			clientID:       "id",
+			url:            "spotify:track:abc123",
+			featuresStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Error - Server Error",
+			clientID:       "id",
+			url:            "spotify:track:abc123",
+			featuresStatus: http.StatusInternalServerError,
+			expectedError:  "unexpected status code: 500",
+		},
+		{
+			name:           "Error - Invalid JSON",
+			clientID:       "id",
+			url:            "spotify:track:abc123",
+			featuresStatus: http.StatusOK,
+			featuresBody:   `{not json`,
+			expectedError:  "failed to decode audio features",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/token":
+					if id, secret, ok := r.BasicAuth(); !ok || id != "id" || secret != "secret" {
+						w.WriteHeader(http.StatusUnauthorized)
+						return
+					}
+					_, _ = w.Write([]byte(`{"access_token":"tok","expires_in":3600}`))
+				case r.URL.Path == "/audio-features/abc123":
+					if r.Header.Get("Authorization") != "Bearer tok" {
+						w.WriteHeader(http.StatusUnauthorized)
+						return
+					}
+					w.WriteHeader(tt.featuresStatus)
+					_, _ = w.Write([]byte(tt.featuresBody))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			enricher := newTestEnricher(server.URL, tt.clientID)
+			features, err := enricher.AudioFeatures(context.Background(), domain.MediaMetadata{URL: tt.url})
+
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing '%s', got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.expected == nil {
+				if features != nil {
+					t.Errorf("expected no features, got %+v", features)
+				}
+				return
+			}
+			if features == nil || *features != *tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, features)
+			}
+		})
+	}
+}
+
+// TestSpotifyEnricher_TokenCaching verifies the access token is reused across lookups
+func TestSpotifyEnricher_TokenCaching(t *testing.T) {
+	var tokenRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			tokenRequests.Add(1)
+			_, _ = w.Write([]byte(`{"access_token":"tok","expires_in":3600}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"energy":0.5,"valence":0.5,"tempo":120}`))
+	}))
+	defer server.Close()
+
+	enricher := newTestEnricher(server.URL, "id")
+	for i := 0; i < 3; i++ {
+		if _, err := enricher.AudioFeatures(context.Background(), domain.MediaMetadata{URL: "spotify:track:x"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if got := tokenRequests.Load(); got != 1 {
+		t.Errorf("expected 1 token request, got %d", got)
+	}
+}
+
+func TestSpotifyTrackID(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC", "4uLU6hMCjMI75M1A2tKUQC"},
+		{"https://open.spotify.com/intl-it/track/4uLU6hMCjMI75M1A2tKUQC?si=x", "4uLU6hMCjMI75M1A2tKUQC"},
+		{"spotify:track:4uLU6hMCjMI75M1A2tKUQC", "4uLU6hMCjMI75M1A2tKUQC"},
+		{"https://open.spotify.com/episode/123", ""},
+		{"https://example.com/track/123", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := spotifyTrackID(tt.input); got != tt.expected {
+			t.Errorf("spotifyTrackID(%q): expected %q, got %q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func newTestEnricher(serverURL, clientID string) *SpotifyEnricher {
+	enricher := NewSpotifyEnricher(zap.NewNop(), &mockConfig{clientID: clientID, clientSecret: "secret"}, unmetered{})
+	enricher.tokenURL = serverURL + "/token"
+	enricher.apiURL = serverURL
+	return enricher
+}
+
+// mockConfig implements the parts of domain.Config used by the enricher.
+// Other getters are promoted from the nil embedded interface and must not be called.
+type mockConfig struct {
+	domain.Config
+	clientID     string
+	clientSecret string
+}
+
+func (m *mockConfig) GetSpotifyCredentials() (clientID, clientSecret string) {
+	return m.clientID, m.clientSecret
+}
+
+// unmetered is a connection without a data cap
+type unmetered struct{}
+
+func (unmetered) Metered(ctx context.Context) bool {
+	return false
+}
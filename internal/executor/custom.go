@@ -0,0 +1,108 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Placeholders of executor.custom_command
+const (
+	placeholderPath    = "{path}"
+	placeholderMonitor = "{monitor}"
+)
+
+// parseTemplate splits a custom command into its binary and arguments like a
+// POSIX shell would, honouring single quotes, double quotes and backslash
+// escapes. The command is never run through a shell, so placeholders expanded
+// later stay a single argument whatever they contain.
+func parseTemplate(template string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune // Quote character of the open quoted section, 0 outside quotes
+		escaped bool
+	)
+
+	for _, r := range template {
+		switch {
+		case escaped:
+			// Inside double quotes a backslash only escapes what the shell escapes there
+			if quote == '"' && !strings.ContainsRune(`"\$`+"`", r) {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	if strings.Contains(words[0], placeholderPath) || strings.Contains(words[0], placeholderMonitor) {
+		return nil, fmt.Errorf("the program name cannot be a placeholder")
+	}
+	return words, nil
+}
+
+// customArgs parses the custom command and fills in {monitor}, leaving {path}
+// for each wallpaper. It returns the binary and its arguments.
+func customArgs(template, monitor string) (string, []string, error) {
+	words, err := parseTemplate(template)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid executor.custom_command: %w", err)
+	}
+
+	hasPath := false
+	for i, word := range words[1:] {
+		if strings.Contains(word, placeholderMonitor) {
+			if monitor == "" {
+				return "", nil, fmt.Errorf("executor.custom_command uses %s but executor.monitor is not set", placeholderMonitor)
+			}
+			word = strings.ReplaceAll(word, placeholderMonitor, monitor)
+		}
+		hasPath = hasPath || strings.Contains(word, placeholderPath)
+		words[i+1] = word
+	}
+	if !hasPath {
+		return "", nil, fmt.Errorf("executor.custom_command must contain %s", placeholderPath)
+	}
+
+	return words[0], words[1:], nil
+}
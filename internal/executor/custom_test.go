@@ -0,0 +1,62 @@
+package executor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTemplate(t *testing.T) {
+	tests := []struct {
+		name          string
+		template      string
+		expected      []string
+		expectedError string
+	}{
+		{name: "Plain", template: "wpaperd-set {path} --output {monitor}", expected: []string{"wpaperd-set", "{path}", "--output", "{monitor}"}},
+		{name: "Extra Whitespace", template: "  wbg\t {path}  ", expected: []string{"wbg", "{path}"}},
+		{name: "Single Quotes", template: `sh -c 'xwallpaper --zoom "$1"' _ {path}`, expected: []string{"sh", "-c", `xwallpaper --zoom "$1"`, "_", "{path}"}},
+		{name: "Double Quotes", template: `set-bg "{path}" "a \"b\" \n"`, expected: []string{"set-bg", "{path}", `a "b" \n`}},
+		{name: "Escaped Space", template: `my\ script {path}`, expected: []string{"my script", "{path}"}},
+		{name: "Empty Quotes", template: `cmd "" {path}`, expected: []string{"cmd", "", "{path}"}},
+		{name: "Empty", template: "   ", expectedError: "empty command"},
+		{name: "Unterminated", template: `cmd "{path}`, expectedError: "unterminated \" quote"},
+		{name: "Trailing Backslash", template: `cmd {path} \`, expectedError: "trailing backslash"},
+		{name: "Placeholder Program", template: "{path} --set", expectedError: "program name cannot be a placeholder"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words, err := parseTemplate(tt.template)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing '%s', got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(words, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, words)
+			}
+		})
+	}
+}
+
+func TestCustomArgs(t *testing.T) {
+	binary, args, err := customArgs("wpaperd-set {path} --output {monitor}", "DP-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if binary != "wpaperd-set" || !reflect.DeepEqual(args, []string{"{path}", "--output", "DP-1"}) {
+		t.Errorf("unexpected command: %s %q", binary, args)
+	}
+
+	if _, _, err := customArgs("wpaperd-set {path} --output {monitor}", ""); err == nil || !strings.Contains(err.Error(), "executor.monitor is not set") {
+		t.Errorf("expected missing monitor error, got %v", err)
+	}
+	if _, _, err := customArgs("xwallpaper --zoom", ""); err == nil || !strings.Contains(err.Error(), "must contain {path}") {
+		t.Errorf("expected missing path error, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/logctx"
+	"go.uber.org/zap"
+)
+
+// probeInterval is how often a degraded executor looks for a wallpaper setter again
+const probeInterval = 30 * time.Second
+
+// SetterNone is the setter name reported while wallpapers are only saved
+const SetterNone = "none"
+
+// Degraded stands in for a wallpaper setter that could not be constructed, so
+// the daemon still runs: wallpapers are only saved to the output directory while
+// the setter is probed for in the background. Once it is found, every call goes to it.
+type Degraded struct {
+	logger   *zap.Logger
+	cause    error // Why no setter could be constructed
+	probe    func() (domain.Executor, error)
+	clock    clock.Clock
+	reporter domain.Reporter
+
+	mu     sync.RWMutex
+	setter domain.Executor // Nil until a probe succeeds
+	cancel context.CancelFunc
+	done   chan struct{} // Closed when the probe loop returns
+}
+
+// NewDegraded creates an executor saving wallpapers only. probe constructs the
+// real setter, cause is the error of the first attempt.
+func NewDegraded(logger *zap.Logger, cause error, probe func() (domain.Executor, error), clk clock.Clock, reporter domain.Reporter) *Degraded {
+	return &Degraded{
+		logger:   logger,
+		cause:    cause,
+		probe:    probe,
+		clock:    clk,
+		reporter: reporter,
+	}
+}
+
+// Start probes for a setter every probeInterval until one is found or Stop is called
+func (d *Degraded) Start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.done = make(chan struct{})
+	go d.run(ctx)
+}
+
+// Stop ends probing
+func (d *Degraded) Stop() {
+	d.mu.RLock()
+	cancel, done := d.cancel, d.done
+	d.mu.RUnlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+func (d *Degraded) run(ctx context.Context) {
+	defer close(d.done)
+
+	timer := d.clock.NewTimer(probeInterval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C():
+			if d.tryProbe(ctx) {
+				return
+			}
+			timer.Reset(probeInterval)
+		}
+	}
+}
+
+// tryProbe constructs the setter and, when it depends on a daemon, checks that
+// the daemon is up. It reports whether the setter is now in use.
+func (d *Degraded) tryProbe(ctx context.Context) bool {
+	setter, err := d.probe()
+	if err == nil {
+		if r, ok := setter.(domain.Readier); ok {
+			err = r.Ready(ctx)
+		}
+	}
+	if err != nil {
+		d.logger.Debug("Wallpaper setter still unavailable", zap.Error(err))
+		return false
+	}
+
+	d.mu.Lock()
+	d.setter = setter
+	d.mu.Unlock()
+
+	name, reason := setter.Setter()
+	d.logger.Info("Wallpaper setter available, wallpapers are applied again",
+		zap.String("name", name),
+		zap.String("reason", reason))
+	d.reporter.SetterSelected(name, reason)
+	return true
+}
+
+func (d *Degraded) current() domain.Executor {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.setter
+}
+
+// Setter returns the setter once found, SetterNone with the cause before
+func (d *Degraded) Setter() (name, reason string) {
+	if setter := d.current(); setter != nil {
+		return setter.Setter()
+	}
+	return SetterNone, "saving wallpapers only: " + d.cause.Error()
+}
+
+// SetWallpaper applies the wallpaper once a setter is found, it is only saved before
+func (d *Degraded) SetWallpaper(ctx context.Context, imagePath string) error {
+	if setter := d.current(); setter != nil {
+		return setter.SetWallpaper(ctx, imagePath)
+	}
+	logctx.Logger(ctx, d.logger).Debug("No wallpaper setter, wallpaper saved only", zap.String("path", imagePath))
+	return nil
+}
+
+// GetCurrentWallpaper queries the setter once found
+func (d *Degraded) GetCurrentWallpaper(ctx context.Context) (string, error) {
+	if setter := d.current(); setter != nil {
+		return setter.GetCurrentWallpaper(ctx)
+	}
+	return "", errors.New("no wallpaper setter available")
+}
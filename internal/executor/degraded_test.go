@@ -0,0 +1,94 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// fakeSetter records the wallpapers it sets
+type fakeSetter struct {
+	mu  sync.Mutex
+	set []string
+}
+
+func (f *fakeSetter) SetWallpaper(ctx context.Context, imagePath string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.set = append(f.set, imagePath)
+	return nil
+}
+
+func (f *fakeSetter) GetCurrentWallpaper(ctx context.Context) (string, error) {
+	return "", errors.New("not supported")
+}
+
+func (f *fakeSetter) Setter() (name, reason string) {
+	return "swww", "detected"
+}
+
+// setterReporter records the setters reported to the status
+type setterReporter struct {
+	domain.Reporter
+	selected chan string
+}
+
+func (r *setterReporter) SetterSelected(name, reason string) {
+	r.selected <- name
+}
+
+// TestDegraded verifies wallpapers are saved only until a probe finds a setter,
+// which is then used and reported
+func TestDegraded(t *testing.T) {
+	clk := clock.NewFake(time.Unix(0, 0))
+	reporter := &setterReporter{selected: make(chan string, 1)}
+	setter := &fakeSetter{}
+	probes := 0
+	probe := func() (domain.Executor, error) {
+		probes++
+		if probes == 1 {
+			return nil, errors.New("no wallpaper setter found")
+		}
+		return setter, nil
+	}
+	d := NewDegraded(zap.NewNop(), errors.New("no wallpaper setter found"), probe, clk, reporter)
+
+	if name, reason := d.Setter(); name != SetterNone || !strings.Contains(reason, "no wallpaper setter found") {
+		t.Errorf("expected the save-only setter with its cause, got %q (%s)", name, reason)
+	}
+	if err := d.SetWallpaper(context.Background(), "/tmp/a.png"); err != nil {
+		t.Fatalf("expected saving only to succeed, got %v", err)
+	}
+
+	d.Start()
+	defer d.Stop()
+	clk.BlockUntil(1)
+	clk.Advance(probeInterval) // Still missing
+	clk.BlockUntil(1)
+	clk.Advance(probeInterval)
+
+	select {
+	case name := <-reporter.selected:
+		if name != "swww" {
+			t.Errorf("expected swww to be reported, got %q", name)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timeout: the found setter was not reported")
+	}
+	if name, _ := d.Setter(); name != "swww" {
+		t.Errorf("expected swww in use, got %q", name)
+	}
+	if err := d.SetWallpaper(context.Background(), "/tmp/b.png"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(setter.set) != 1 || setter.set[0] != "/tmp/b.png" {
+		t.Errorf("expected only the wallpaper after the probe to be set, got %v", setter.set)
+	}
+}
@@ -0,0 +1,197 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/jpeg" // Wallpapers are JPEG
+	"image/png"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/disintegration/imaging"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/latest"
+	"github.com/genricoloni/synest/internal/paths"
+	"go.uber.org/zap"
+)
+
+const (
+	// EInkFilename is the converted image written to the output directory
+	EInkFilename = "eink.png"
+
+	einkTimeout   = 2 * time.Minute // E-ink panels take seconds to refresh, helpers may retry
+	einkWaitDelay = 2 * time.Second // Bounds how long a cancelled helper may keep its output open
+)
+
+// EInk wraps the wallpaper setter with a secondary display sink: every wallpaper
+// set is also converted to dithered grayscale at the resolution of the display
+// and handed to the command of executor.eink. The conversion and the command run
+// in the background, only the latest wallpaper waits for a slow display.
+type EInk struct {
+	domain.Executor
+	logger *zap.Logger
+	cfg    domain.Config
+	worker *latest.Worker[[]byte]
+}
+
+// NewEInk wraps setter with the sink configured in cfg
+func NewEInk(logger *zap.Logger, cfg domain.Config, setter domain.Executor) *EInk {
+	e := &EInk{
+		Executor: setter,
+		logger:   logger,
+		cfg:      cfg,
+	}
+	e.worker = latest.New(logger, "e-ink update", e.run)
+	return e
+}
+
+// Stop ends the command in flight and discards the queued wallpaper
+func (e *EInk) Stop(ctx context.Context) error {
+	return e.worker.Stop(ctx)
+}
+
+// Ready implements domain.Readier with the wrapped setter
+func (e *EInk) Ready(ctx context.Context) error {
+	if r, ok := e.Executor.(domain.Readier); ok {
+		return r.Ready(ctx)
+	}
+	return nil
+}
+
+// SetWallpaper sets the wallpaper with the wrapped setter, then queues it for
+// the secondary display. Scenes written instead of a wallpaper are not sent.
+func (e *EInk) SetWallpaper(ctx context.Context, imagePath string) error {
+	if err := e.Executor.SetWallpaper(ctx, imagePath); err != nil {
+		return err
+	}
+	if e.cfg.GetEInk().Command == "" || e.cfg.GetScene() == domain.SceneOnly {
+		return nil
+	}
+
+	data, err := os.ReadFile(imagePath)
+	if err != nil {
+		e.logger.Warn("Failed to read the wallpaper for the e-ink display", zap.Error(err))
+		return nil
+	}
+	e.worker.Queue(data)
+	return nil
+}
+
+// run sends a queued wallpaper to the display, in the worker
+func (e *EInk) run(ctx context.Context, data []byte) {
+	if err := e.send(ctx, data); err != nil && ctx.Err() == nil {
+		e.logger.Warn("Failed to update the e-ink display", zap.Error(err))
+	}
+}
+
+// send converts the wallpaper, writes it to the output directory and runs the command
+func (e *EInk) send(ctx context.Context, data []byte) error {
+	settings := e.cfg.GetEInk()
+	if settings.Command == "" {
+		return nil
+	}
+	words, err := parseTemplate(settings.Command)
+	if err != nil {
+		return fmt.Errorf("invalid executor.eink.command: %w", err)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to decode the wallpaper: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, convertEInk(src, settings)); err != nil {
+		return fmt.Errorf("failed to encode the e-ink image: %w", err)
+	}
+	path, err := e.write(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	hasPath := false
+	args := make([]string, len(words)-1)
+	for i, word := range words[1:] {
+		hasPath = hasPath || strings.Contains(word, placeholderPath)
+		args[i] = strings.ReplaceAll(word, placeholderPath, path)
+	}
+	if !hasPath {
+		return fmt.Errorf("executor.eink.command must contain %s", placeholderPath)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, einkTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, words[0], args...)
+	cmd.WaitDelay = einkWaitDelay
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w (output: %s)", words[0], err, strings.TrimSpace(string(output)))
+	}
+	e.logger.Debug("E-ink display updated", zap.String("path", path))
+	return nil
+}
+
+// write replaces the converted image in the output directory and returns its absolute path
+func (e *EInk) write(data []byte) (string, error) {
+	dir := e.cfg.GetOutputDir()
+	if err := paths.Ensure(dir); err != nil {
+		return "", err
+	}
+	path, err := filepath.Abs(filepath.Join(dir, EInkFilename))
+	if err != nil {
+		return "", err
+	}
+	// Replaced atomically, a helper still reading the previous image is not disturbed
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return "", errors.Join(err, os.Remove(tmp))
+	}
+	return path, nil
+}
+
+// convertEInk crops the wallpaper to fill the display, centered, and dithers it
+// to the gray levels of the display
+func convertEInk(src image.Image, settings domain.EInk) *image.Gray {
+	filled := imaging.Fill(src, settings.Width, settings.Height, imaging.Center, imaging.Lanczos)
+	gray := image.NewGray(filled.Bounds())
+	draw.Draw(gray, gray.Bounds(), filled, filled.Bounds().Min, draw.Src)
+	dither(gray, settings.Levels)
+	return gray
+}
+
+// dither reduces img to levels evenly spaced grays in place, spreading the
+// quantization error over the neighbors (Floyd-Steinberg)
+func dither(img *image.Gray, levels int) {
+	b := img.Bounds()
+	width := b.Dx()
+	step := 255 / float64(levels-1)
+	// Errors carried to the current and the next row, with a pixel of margin on both sides
+	cur := make([]float64, width+2)
+	next := make([]float64, width+2)
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := 0; x < width; x++ {
+			i := img.PixOffset(b.Min.X+x, y)
+			v := float64(img.Pix[i]) + cur[x+1]
+			q := math.Round(min(max(v, 0), 255)/step) * step
+			img.Pix[i] = uint8(q)
+
+			diff := v - q
+			cur[x+2] += diff * 7 / 16
+			next[x] += diff * 3 / 16
+			next[x+1] += diff * 5 / 16
+			next[x+2] += diff * 1 / 16
+		}
+		cur, next = next, cur
+		clear(next)
+	}
+}
@@ -0,0 +1,113 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// TestDither verifies a flat gray is spread over the available levels, keeping
+// its average brightness
+func TestDither(t *testing.T) {
+	for _, levels := range []int{2, 4} {
+		img := image.NewGray(image.Rect(0, 0, 64, 64))
+		for i := range img.Pix {
+			img.Pix[i] = 100
+		}
+		dither(img, levels)
+
+		step := 255 / (levels - 1)
+		sum := 0
+		for _, v := range img.Pix {
+			if int(v)%step != 0 {
+				t.Fatalf("levels %d: gray %d is not one of the levels", levels, v)
+			}
+			sum += int(v)
+		}
+		if mean := sum / len(img.Pix); mean < 95 || mean > 105 {
+			t.Errorf("levels %d: expected a mean close to 100, got %d", levels, mean)
+		}
+	}
+}
+
+// TestEInk verifies the wallpaper is set, then converted to the display
+// resolution and handed to the command
+func TestEInk(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the command is a POSIX cp")
+	}
+	dir := t.TempDir()
+	copied := filepath.Join(dir, "copied.png")
+	cfg := &einkConfig{outputDir: dir, eink: domain.EInk{Command: "cp {path} " + copied, Width: 40, Height: 30, Levels: 2}}
+	setter := &fakeSetter{}
+	sink := NewEInk(zap.NewNop(), cfg, setter)
+	defer sink.Stop(context.Background())
+
+	var buf bytes.Buffer
+	src := image.NewRGBA(image.Rect(0, 0, 160, 90))
+	for i := range src.Pix {
+		src.Pix[i] = 180
+	}
+	if err := jpeg.Encode(&buf, src, nil); err != nil {
+		t.Fatal(err)
+	}
+	wallpaper := filepath.Join(dir, "wallpaper.jpg")
+	if err := os.WriteFile(wallpaper, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.SetWallpaper(context.Background(), wallpaper); err != nil {
+		t.Fatalf("SetWallpaper failed: %v", err)
+	}
+	if len(setter.set) != 1 {
+		t.Errorf("expected the wallpaper set, got %v", setter.set)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, err := os.ReadFile(copied)
+		if err == nil {
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("converted image does not decode: %v", err)
+			}
+			if img.Bounds().Dx() != 40 || img.Bounds().Dy() != 30 || img.ColorModel() != color.GrayModel {
+				t.Errorf("expected a 40x30 gray image, got %v in %T", img.Bounds(), img)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the command did not receive the converted image")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// einkConfig implements the parts of domain.Config used by the e-ink sink
+type einkConfig struct {
+	domain.Config
+	outputDir string
+	eink      domain.EInk
+}
+
+func (c *einkConfig) GetEInk() domain.EInk {
+	return c.eink
+}
+
+func (c *einkConfig) GetScene() string {
+	return domain.SceneOff
+}
+
+func (c *einkConfig) GetOutputDir() string {
+	return c.outputDir
+}
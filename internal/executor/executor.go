@@ -0,0 +1,20 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+// SetterAuto selects the wallpaper setter from the environment
+const SetterAuto = "auto"
+
+// SetterCustom selects the user-defined command of executor.custom_command
+const SetterCustom = "custom"
+
+// SettingsKey identifies the settings an executor reads when it is constructed,
+// it is constructed again when they change
+func SettingsKey(cfg domain.Config) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%+v|%s|%s", cfg.GetSetter(), cfg.GetCustomCommand(), cfg.GetSetterMonitor(),
+		cfg.GetDelivery(), cfg.GetTransition(), cfg.GetMultiDisplay(), cfg.GetScene())
+}
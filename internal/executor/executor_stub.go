@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/genricoloni/synest/internal/domain"
 	"go.uber.org/zap"
 )
 
@@ -16,11 +17,31 @@ type StubExecutor struct {
 }
 
 // NewExecutor creates a stub executor for unsupported platforms
-func NewExecutor(logger *zap.Logger) (*StubExecutor, error) {
+func NewExecutor(logger *zap.Logger, cfg domain.Config) (*StubExecutor, error) {
+	if setter := cfg.GetSetter(); setter != "" && setter != SetterAuto {
+		return nil, fmt.Errorf("wallpaper setter %q is not available on this platform", setter)
+	}
+	if cfg.GetCustomCommand() != "" {
+		return nil, fmt.Errorf("custom wallpaper commands are not available on this platform")
+	}
+	if cfg.GetDelivery() == domain.DeliveryMemfd {
+		return nil, fmt.Errorf("memfd delivery is not available on this platform")
+	}
+	if cfg.GetScene() == domain.SceneOnly {
+		return nil, fmt.Errorf("scene output only is not available on this platform")
+	}
+	if cfg.GetMultiDisplay() == domain.MultiDisplaySpan {
+		return nil, fmt.Errorf("spanning one image across displays is not available on this platform")
+	}
 	logger.Warn("Wallpaper setting is not yet implemented for this platform")
 	return &StubExecutor{logger: logger}, nil
 }
 
+// Setter returns the wallpaper setter in use
+func (e *StubExecutor) Setter() (name, reason string) {
+	return "none", "platform not supported"
+}
+
 // SetWallpaper returns an error indicating the platform is not supported
 func (e *StubExecutor) SetWallpaper(ctx context.Context, imagePath string) error {
 	return fmt.Errorf("wallpaper setting not implemented for this platform (macOS/BSD support coming soon)")
@@ -8,8 +8,14 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
 
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/logctx"
 	"go.uber.org/zap"
 )
 
@@ -19,21 +25,34 @@ type WallpaperCommand struct {
 	Binary  string
 	Args    []string // %s will be replaced with image path
 	UsesURI bool     // If true, path will be prefixed with file://
+	ReadsFD bool     // Reads the image once, so an in-memory /proc/<pid>/fd path works
+	Probe   []string // Arguments of a command that succeeds once the setter daemon is up
+	Custom  bool     // User-defined: Args hold {path} instead of %s and may start child processes
+
+	Transitions bool // Accepts the swww transition arguments, see transitionArgs
+
+	SpanArgs  []string // Args that stretch one image across all displays, nil if the setter sets each display separately
+	SpanSetup []string // Arguments of a command run once before the first spanned wallpaper
 }
 
+// customWaitDelay bounds how long a cancelled custom command may keep its output open
+const customWaitDelay = 2 * time.Second
+
 var (
 	// Ordered list of wallpaper commands to try (highest priority first)
 	wallpaperCommands = []WallpaperCommand{
 		// Hyprland - swww (recommended)
-		{Name: "swww", Binary: "swww", Args: []string{"img", "%s"}},
+		{Name: "swww", Binary: "swww", Args: []string{"img", "%s"}, ReadsFD: true, Probe: []string{"query"}, Transitions: true},
 		// Hyprland - hyprpaper
-		{Name: "hyprpaper", Binary: "hyprctl", Args: []string{"hyprpaper", "wallpaper", ",%s"}},
+		{Name: "hyprpaper", Binary: "hyprctl", Args: []string{"hyprpaper", "wallpaper", ",%s"}, Probe: []string{"hyprpaper", "listloaded"}},
 		// swaybg (Sway/Wayland)
 		{Name: "swaybg", Binary: "swaybg", Args: []string{"-i", "%s", "-m", "fill"}},
 		// GNOME (dark theme)
-		{Name: "gnome", Binary: "gsettings", Args: []string{"set", "org.gnome.desktop.background", "picture-uri-dark", "file://%s"}, UsesURI: true},
+		{Name: "gnome", Binary: "gsettings", Args: []string{"set", "org.gnome.desktop.background", "picture-uri-dark", "file://%s"}, UsesURI: true,
+			SpanArgs: []string{"set", "org.gnome.desktop.background", "picture-uri-dark", "file://%s"}, SpanSetup: []string{"set", "org.gnome.desktop.background", "picture-options", "spanned"}},
 		// Generic X11 - feh
-		{Name: "feh", Binary: "feh", Args: []string{"--bg-fill", "%s"}},
+		{Name: "feh", Binary: "feh", Args: []string{"--bg-fill", "%s"}, ReadsFD: true,
+			SpanArgs: []string{"--no-xinerama", "--bg-fill", "%s"}},
 		// Generic X11 - nitrogen
 		{Name: "nitrogen", Binary: "nitrogen", Args: []string{"--set-zoom-fill", "%s"}},
 	}
@@ -43,33 +62,178 @@ var (
 type LinuxExecutor struct {
 	logger  *zap.Logger
 	command WallpaperCommand
+	reason  string // Why the command was selected
+
+	spanSetup []string    // Pending setup of the spanned layout, see WallpaperCommand.SpanSetup
+	spanReady atomic.Bool // Whether spanSetup succeeded
 }
 
-// NewExecutor creates a new platform-specific wallpaper executor (Linux implementation)
-func NewExecutor(logger *zap.Logger) (*LinuxExecutor, error) {
-	cmd := detectCommand(logger)
-	if cmd.Binary == "" {
-		return nil, fmt.Errorf("no supported wallpaper command found on this system")
+// NewExecutor creates a new platform-specific wallpaper executor (Linux implementation).
+// The setter named in the configuration is used as is, "auto" detects one unless
+// a custom command is configured.
+func NewExecutor(logger *zap.Logger, cfg domain.Config) (*LinuxExecutor, error) {
+	cmd, reason, err := selectCommand(logger, cfg.GetSetter(), cfg.GetCustomCommand(), cfg.GetSetterMonitor())
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDelivery(cmd, cfg.GetDelivery()); err != nil {
+		return nil, err
+	}
+	if err := checkScene(cmd, cfg.GetScene()); err != nil {
+		return nil, err
+	}
+	if cmd, err = transitionCommand(cmd, cfg.GetTransition()); err != nil {
+		return nil, err
+	}
+	spanned := cfg.GetMultiDisplay() == domain.MultiDisplaySpan
+	if spanned {
+		if cmd, err = spanCommand(cmd); err != nil {
+			return nil, err
+		}
 	}
 
-	logger.Info("Wallpaper setter detected",
+	logger.Info("Wallpaper setter selected",
 		zap.String("name", cmd.Name),
-		zap.String("binary", cmd.Binary))
+		zap.String("binary", cmd.Binary),
+		zap.String("reason", reason),
+		zap.Bool("spanned", spanned))
 
-	return &LinuxExecutor{
+	e := &LinuxExecutor{
 		logger:  logger,
 		command: cmd,
-	}, nil
+		reason:  reason,
+	}
+	if spanned {
+		e.spanSetup = cmd.SpanSetup
+	}
+	return e, nil
 }
 
 // NewLinuxExecutor is deprecated, use NewExecutor instead
 // Kept for backward compatibility
-func NewLinuxExecutor(logger *zap.Logger) (*LinuxExecutor, error) {
-	return NewExecutor(logger)
+func NewLinuxExecutor(logger *zap.Logger, cfg domain.Config) (*LinuxExecutor, error) {
+	return NewExecutor(logger, cfg)
+}
+
+// selectCommand returns the custom command, the configured setter, or the detected
+// one for "auto", along with the reason it was chosen
+func selectCommand(logger *zap.Logger, setter, custom, monitor string) (WallpaperCommand, string, error) {
+	if setter == SetterCustom || (custom != "" && (setter == "" || setter == SetterAuto)) {
+		if custom == "" {
+			return WallpaperCommand{}, "", fmt.Errorf("wallpaper setter %q needs executor.custom_command", SetterCustom)
+		}
+		binary, args, err := customArgs(custom, monitor)
+		if err != nil {
+			return WallpaperCommand{}, "", err
+		}
+		if !commandExists(binary) {
+			return WallpaperCommand{}, "", fmt.Errorf("custom wallpaper command %s is not installed", binary)
+		}
+		return WallpaperCommand{Name: SetterCustom, Binary: binary, Args: args, Custom: true}, "custom command configured", nil
+	}
+	if custom != "" {
+		return WallpaperCommand{}, "", fmt.Errorf("executor.custom_command is set but wallpaper setter %q is forced, use %s or %s", setter, SetterAuto, SetterCustom)
+	}
+
+	if setter == "" || setter == SetterAuto {
+		cmd, reason := detectCommand(logger)
+		if cmd.Binary == "" {
+			return WallpaperCommand{}, "", fmt.Errorf("no supported wallpaper command found on this system")
+		}
+		return cmd, reason, nil
+	}
+
+	for _, cmd := range wallpaperCommands {
+		if cmd.Name != setter {
+			continue
+		}
+		if !commandExists(cmd.Binary) {
+			return WallpaperCommand{}, "", fmt.Errorf("wallpaper setter %q is forced by configuration but %s is not installed", setter, cmd.Binary)
+		}
+		return cmd, "forced by configuration", nil
+	}
+
+	return WallpaperCommand{}, "", fmt.Errorf("unknown wallpaper setter %q (available: %s)", setter, strings.Join(SetterNames(), ", "))
+}
+
+// checkDelivery rejects in-memory delivery for setters that keep re-reading the
+// image path, which would break once the in-memory file is replaced
+func checkDelivery(cmd WallpaperCommand, delivery string) error {
+	if delivery == domain.DeliveryMemfd && !cmd.ReadsFD {
+		return fmt.Errorf("wallpaper setter %s does not support memfd delivery, use swww or feh", cmd.Name)
+	}
+	return nil
+}
+
+// checkScene rejects scene-only output for the setters that expect an image,
+// only a custom command knows what to do with the scene
+func checkScene(cmd WallpaperCommand, scene string) error {
+	if scene == domain.SceneOnly && !cmd.Custom {
+		return fmt.Errorf("scene output only needs a custom command, wallpaper setter %s expects an image", cmd.Name)
+	}
+	return nil
+}
+
+// transitionCommand adds the configured transition to the setter arguments.
+// Only swww animates wallpaper changes, custom commands take their own flags.
+func transitionCommand(cmd WallpaperCommand, t domain.Transition) (WallpaperCommand, error) {
+	if t == (domain.Transition{}) {
+		return cmd, nil
+	}
+	if !cmd.Transitions {
+		return WallpaperCommand{}, fmt.Errorf("wallpaper setter %s does not support transitions, use swww or add them to a custom command", cmd.Name)
+	}
+	args, err := transitionArgs(t)
+	if err != nil {
+		return WallpaperCommand{}, err
+	}
+	cmd.Args = append(slices.Clone(cmd.Args), args...)
+	return cmd, nil
+}
+
+// spanCommand switches the setter to its spanning arguments. Setters that set
+// each display separately would repeat the whole virtual desktop on every one.
+// Custom commands are trusted to span on their own.
+func spanCommand(cmd WallpaperCommand) (WallpaperCommand, error) {
+	if cmd.Custom {
+		return cmd, nil
+	}
+	if cmd.SpanArgs == nil {
+		return WallpaperCommand{}, fmt.Errorf("wallpaper setter %s cannot span one image across displays, use gnome, feh or a custom command", cmd.Name)
+	}
+	cmd.Args = cmd.SpanArgs
+	return cmd, nil
+}
+
+// Ready implements domain.Readier: it succeeds once the setter daemon answers.
+// Setters without a daemon are always ready.
+func (e *LinuxExecutor) Ready(ctx context.Context) error {
+	if len(e.command.Probe) == 0 {
+		return nil
+	}
+	output, err := exec.CommandContext(ctx, e.command.Binary, e.command.Probe...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s daemon not responding: %w (output: %s)", e.command.Name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// SetterNames returns the names accepted by SYNEST_SETTER and executor.backend
+func SetterNames() []string {
+	names := []string{SetterAuto, SetterCustom}
+	for _, cmd := range wallpaperCommands {
+		names = append(names, cmd.Name)
+	}
+	return names
+}
+
+// Setter returns the selected wallpaper command and why it was selected
+func (e *LinuxExecutor) Setter() (name, reason string) {
+	return e.command.Name, e.reason
 }
 
 // detectCommand analyzes the environment to choose the best wallpaper command
-func detectCommand(logger *zap.Logger) WallpaperCommand {
+func detectCommand(logger *zap.Logger) (WallpaperCommand, string) {
 	// Check environment variables for hints
 	desktop := os.Getenv("XDG_CURRENT_DESKTOP")
 	session := os.Getenv("XDG_SESSION_TYPE")
@@ -87,7 +251,7 @@ func detectCommand(logger *zap.Logger) WallpaperCommand {
 		// Running on Hyprland - prefer swww or hyprpaper
 		for _, cmd := range wallpaperCommands {
 			if (cmd.Name == "swww" || cmd.Name == "hyprpaper") && commandExists(cmd.Binary) {
-				return cmd
+				return cmd, "HYPRLAND_INSTANCE_SIGNATURE set"
 			}
 		}
 	}
@@ -96,7 +260,7 @@ func detectCommand(logger *zap.Logger) WallpaperCommand {
 		// GNOME desktop
 		for _, cmd := range wallpaperCommands {
 			if cmd.Name == "gnome" && commandExists(cmd.Binary) {
-				return cmd
+				return cmd, "XDG_CURRENT_DESKTOP=" + desktop
 			}
 		}
 	}
@@ -105,7 +269,7 @@ func detectCommand(logger *zap.Logger) WallpaperCommand {
 		// Wayland session - prefer Wayland-native tools
 		for _, cmd := range wallpaperCommands {
 			if (cmd.Name == "swww" || cmd.Name == "swaybg") && commandExists(cmd.Binary) {
-				return cmd
+				return cmd, "Wayland session"
 			}
 		}
 	}
@@ -114,11 +278,11 @@ func detectCommand(logger *zap.Logger) WallpaperCommand {
 	for _, cmd := range wallpaperCommands {
 		if commandExists(cmd.Binary) {
 			logger.Info("Using fallback wallpaper command", zap.String("name", cmd.Name))
-			return cmd
+			return cmd, "first setter found in PATH"
 		}
 	}
 
-	return WallpaperCommand{} // No command found
+	return WallpaperCommand{}, "" // No command found
 }
 
 // commandExists checks if a binary exists in PATH
@@ -130,40 +294,67 @@ func commandExists(binary string) bool {
 // SetWallpaper sets the desktop wallpaper to the specified image
 func (e *LinuxExecutor) SetWallpaper(ctx context.Context, imagePath string) error {
 	// Build command arguments
+	placeholder := "%s" // For GNOME the template already includes file://
+	if e.command.Custom {
+		placeholder = placeholderPath
+	}
 	args := make([]string, len(e.command.Args))
 	for i, arg := range e.command.Args {
-		if strings.Contains(arg, "%s") {
-			path := imagePath
-			if e.command.UsesURI {
-				// GNOME requires file:// URI
-				path = imagePath // %s template already includes file://
-			}
-			args[i] = strings.ReplaceAll(arg, "%s", path)
-		} else {
-			args[i] = arg
-		}
+		args[i] = strings.ReplaceAll(arg, placeholder, imagePath)
 	}
 
-	e.logger.Debug("Setting wallpaper",
+	logger := logctx.Logger(ctx, e.logger)
+	if err := e.setupSpan(ctx); err != nil {
+		return err
+	}
+	logger.Debug("Setting wallpaper",
 		zap.String("command", e.command.Binary),
 		zap.Strings("args", args),
 		zap.String("path", imagePath))
 
 	// Execute command
 	cmd := exec.CommandContext(ctx, e.command.Binary, args...)
+	if e.command.Custom {
+		killGroupOnCancel(cmd)
+	}
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to set wallpaper with %s: %w (output: %s)",
 			e.command.Name, err, string(output))
 	}
 
-	e.logger.Info("Wallpaper set successfully",
+	logger.Info("Wallpaper set successfully",
 		zap.String("command", e.command.Name),
 		zap.String("path", imagePath))
 
 	return nil
 }
 
+// setupSpan runs the setter's span setup until it succeeds once,
+// so the spanned image is not scaled onto each display
+func (e *LinuxExecutor) setupSpan(ctx context.Context) error {
+	if len(e.spanSetup) == 0 || e.spanReady.Load() {
+		return nil
+	}
+	output, err := exec.CommandContext(ctx, e.command.Binary, e.spanSetup...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to span wallpaper with %s: %w (output: %s)",
+			e.command.Name, err, string(output))
+	}
+	e.spanReady.Store(true)
+	return nil
+}
+
+// killGroupOnCancel runs cmd in its own process group and kills the whole group
+// when the context is cancelled, so scripts do not leave their children behind
+func killGroupOnCancel(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = customWaitDelay
+}
+
 // GetCurrentWallpaper retrieves the path to the currently set wallpaper
 func (e *LinuxExecutor) GetCurrentWallpaper(ctx context.Context) (string, error) {
 	switch e.command.Name {
@@ -173,7 +364,7 @@ func (e *LinuxExecutor) GetCurrentWallpaper(ctx context.Context) (string, error)
 		return "", fmt.Errorf("hyprpaper does not support querying current wallpaper")
 	case "gnome":
 		return e.getCurrentWallpaperGnome(ctx)
-	case "feh", "swaybg", "nitrogen":
+	case "feh", "swaybg", "nitrogen", SetterCustom:
 		// These tools don't provide easy ways to query current wallpaper
 		return "", fmt.Errorf("%s does not support querying current wallpaper", e.command.Name)
 	default:
@@ -0,0 +1,169 @@
+//go:build linux
+// +build linux
+
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+func TestSelectCommand(t *testing.T) {
+	// Only feh is installed
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "feh"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "")
+	t.Setenv("XDG_CURRENT_DESKTOP", "")
+	t.Setenv("XDG_SESSION_TYPE", "x11")
+	t.Setenv("WAYLAND_DISPLAY", "")
+
+	tests := []struct {
+		name           string
+		setter         string
+		custom         string
+		expectedName   string
+		expectedReason string
+		expectedError  string
+	}{
+		{name: "Auto", setter: SetterAuto, expectedName: "feh", expectedReason: "first setter found in PATH"},
+		{name: "Forced", setter: "feh", expectedName: "feh", expectedReason: "forced by configuration"},
+		{name: "Forced But Missing", setter: "swww", expectedError: `"swww" is forced by configuration but swww is not installed`},
+		{name: "Unknown", setter: "xsetroot", expectedError: `unknown wallpaper setter "xsetroot"`},
+		{name: "Custom Command", setter: SetterAuto, custom: "feh --bg-scale {path}", expectedName: SetterCustom, expectedReason: "custom command configured"},
+		{name: "Custom Without Command", setter: SetterCustom, expectedError: "needs executor.custom_command"},
+		{name: "Custom Missing Binary", setter: SetterCustom, custom: "wbg {path}", expectedError: "wbg is not installed"},
+		{name: "Custom With Forced Setter", setter: "feh", custom: "feh {path}", expectedError: `wallpaper setter "feh" is forced`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, reason, err := selectCommand(zap.NewNop(), tt.setter, tt.custom, "")
+
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing '%s', got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cmd.Name != tt.expectedName || reason != tt.expectedReason {
+				t.Errorf("expected %s (%s), got %s (%s)", tt.expectedName, tt.expectedReason, cmd.Name, reason)
+			}
+		})
+	}
+}
+
+func TestCheckDelivery(t *testing.T) {
+	swww := WallpaperCommand{Name: "swww", ReadsFD: true}
+	gnome := WallpaperCommand{Name: "gnome"}
+
+	if err := checkDelivery(swww, domain.DeliveryMemfd); err != nil {
+		t.Errorf("expected swww to accept memfd delivery, got %v", err)
+	}
+	if err := checkDelivery(gnome, domain.DeliveryFile); err != nil {
+		t.Errorf("expected file delivery to always work, got %v", err)
+	}
+	if err := checkDelivery(gnome, domain.DeliveryMemfd); err == nil || !strings.Contains(err.Error(), "does not support memfd") {
+		t.Errorf("expected gnome to reject memfd delivery, got %v", err)
+	}
+}
+
+func TestCheckScene(t *testing.T) {
+	custom := WallpaperCommand{Name: "custom", Custom: true}
+	swww := WallpaperCommand{Name: "swww"}
+
+	if err := checkScene(custom, domain.SceneOnly); err != nil {
+		t.Errorf("expected a custom command to accept scenes, got %v", err)
+	}
+	if err := checkScene(swww, domain.SceneAlongside); err != nil {
+		t.Errorf("expected scenes alongside the wallpaper to always work, got %v", err)
+	}
+	if err := checkScene(swww, domain.SceneOnly); err == nil || !strings.Contains(err.Error(), "needs a custom command") {
+		t.Errorf("expected swww to reject scene output only, got %v", err)
+	}
+}
+
+func TestSpanCommand(t *testing.T) {
+	feh, err := spanCommand(WallpaperCommand{Name: "feh", Args: []string{"--bg-fill", "%s"}, SpanArgs: []string{"--no-xinerama", "--bg-fill", "%s"}})
+	if err != nil || feh.Args[0] != "--no-xinerama" {
+		t.Errorf("expected feh to span with --no-xinerama, got %v (%v)", feh.Args, err)
+	}
+	if _, err := spanCommand(WallpaperCommand{Name: "custom", Custom: true}); err != nil {
+		t.Errorf("expected custom commands to be trusted to span, got %v", err)
+	}
+	if _, err := spanCommand(WallpaperCommand{Name: "swww"}); err == nil || !strings.Contains(err.Error(), "cannot span") {
+		t.Errorf("expected swww to reject spanning, got %v", err)
+	}
+}
+
+func TestTransitionCommand(t *testing.T) {
+	swww := WallpaperCommand{Name: "swww", Args: []string{"img", "%s"}, Transitions: true}
+	grow := domain.Transition{Type: "grow", Position: "top-right", Duration: 1500 * time.Millisecond}
+
+	cmd, err := transitionCommand(swww, grow)
+	expected := "img %s --transition-type grow --transition-pos top-right --transition-duration 1.5"
+	if err != nil || strings.Join(cmd.Args, " ") != expected {
+		t.Errorf("expected %q, got %v (%v)", expected, cmd.Args, err)
+	}
+	if len(swww.Args) != 2 {
+		t.Errorf("expected the setter arguments to be left alone, got %v", swww.Args)
+	}
+	if cmd, err := transitionCommand(swww, domain.Transition{Position: "1800,40"}); err != nil || cmd.Args[3] != "1800,40" {
+		t.Errorf("expected a pixel position, got %v (%v)", cmd.Args, err)
+	}
+	if _, err := transitionCommand(WallpaperCommand{Name: "feh"}, domain.Transition{}); err != nil {
+		t.Errorf("expected no transition to suit any setter, got %v", err)
+	}
+	if _, err := transitionCommand(WallpaperCommand{Name: "feh"}, grow); err == nil || !strings.Contains(err.Error(), "does not support transitions") {
+		t.Errorf("expected feh to reject transitions, got %v", err)
+	}
+	for _, bad := range []domain.Transition{{Type: "spin"}, {Position: "corner"}, {Position: "10,"}} {
+		if _, err := transitionCommand(swww, bad); err == nil {
+			t.Errorf("expected %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestSetWallpaper_Custom(t *testing.T) {
+	// The script records each argument on its own line
+	dir := t.TempDir()
+	record := filepath.Join(dir, "args")
+	script := filepath.Join(dir, "set-bg")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nprintf '%s\\n' \"$@\" > "+record+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+":/usr/bin:/bin")
+
+	cmd, _, err := selectCommand(zap.NewNop(), SetterAuto, "set-bg --file={path} --output {monitor}", "HDMI-A-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	e := &LinuxExecutor{logger: zap.NewNop(), command: cmd}
+
+	// Spaces and shell syntax in the path stay part of one argument
+	path := "/tmp/my covers/$(reboot); it's.jpg"
+	if err := e.SetWallpaper(context.Background(), path); err != nil {
+		t.Fatalf("SetWallpaper failed: %v", err)
+	}
+
+	got, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "--file=" + path + "\n--output\nHDMI-A-1\n"
+	if string(got) != expected {
+		t.Errorf("expected arguments %q, got %q", expected, got)
+	}
+}
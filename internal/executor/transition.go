@@ -0,0 +1,48 @@
+package executor
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+// transitionTypes are the animations of swww img --transition-type
+var transitionTypes = []string{
+	"none", "simple", "fade", "left", "right", "top", "bottom",
+	"wipe", "wave", "grow", "center", "any", "outer", "random",
+}
+
+// transitionAliases are the named positions of swww img --transition-pos
+var transitionAliases = []string{
+	"center", "top", "left", "right", "bottom",
+	"top-left", "top-right", "bottom-left", "bottom-right",
+}
+
+// transitionCoordinates matches an explicit position: pixels, or fractions of the screen
+var transitionCoordinates = regexp.MustCompile(`^\d+(\.\d+)?,\d+(\.\d+)?$`)
+
+// transitionArgs validates a transition and returns the swww arguments playing it,
+// nil when every field keeps the default
+func transitionArgs(t domain.Transition) ([]string, error) {
+	var args []string
+	if t.Type != "" {
+		if !slices.Contains(transitionTypes, t.Type) {
+			return nil, fmt.Errorf("unknown transition %q (available: %s)", t.Type, strings.Join(transitionTypes, ", "))
+		}
+		args = append(args, "--transition-type", t.Type)
+	}
+	if t.Position != "" {
+		if !slices.Contains(transitionAliases, t.Position) && !transitionCoordinates.MatchString(t.Position) {
+			return nil, fmt.Errorf("invalid transition position %q: use x,y or one of %s", t.Position, strings.Join(transitionAliases, ", "))
+		}
+		args = append(args, "--transition-pos", t.Position)
+	}
+	if t.Duration > 0 {
+		args = append(args, "--transition-duration", strconv.FormatFloat(t.Duration.Seconds(), 'f', -1, 64))
+	}
+	return args, nil
+}
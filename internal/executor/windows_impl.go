@@ -7,6 +7,8 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/logctx"
 	"go.uber.org/zap"
 )
 
@@ -16,14 +18,34 @@ type WindowsExecutor struct {
 }
 
 // NewExecutor creates a new platform-specific wallpaper executor (Windows implementation)
-func NewExecutor(logger *zap.Logger) (*WindowsExecutor, error) {
+func NewExecutor(logger *zap.Logger, cfg domain.Config) (*WindowsExecutor, error) {
+	if setter := cfg.GetSetter(); setter != "" && setter != SetterAuto {
+		return nil, fmt.Errorf("wallpaper setter %q is not available on Windows", setter)
+	}
+	if cfg.GetCustomCommand() != "" {
+		return nil, fmt.Errorf("custom wallpaper commands are not available on Windows")
+	}
+	if cfg.GetDelivery() == domain.DeliveryMemfd {
+		return nil, fmt.Errorf("memfd delivery is not available on Windows")
+	}
+	if cfg.GetScene() == domain.SceneOnly {
+		return nil, fmt.Errorf("scene output only is not available on Windows")
+	}
+	if cfg.GetMultiDisplay() == domain.MultiDisplaySpan {
+		return nil, fmt.Errorf("spanning one image across displays is not available on Windows")
+	}
 	logger.Info("Windows wallpaper setter initialized")
 	return &WindowsExecutor{logger: logger}, nil
 }
 
+// Setter returns the wallpaper setter in use
+func (e *WindowsExecutor) Setter() (name, reason string) {
+	return "windows", "only setter on this platform"
+}
+
 // SetWallpaper sets the desktop wallpaper using Windows API
 func (e *WindowsExecutor) SetWallpaper(ctx context.Context, imagePath string) error {
-	e.logger.Info("Setting wallpaper", zap.String("path", imagePath))
+	logctx.Logger(ctx, e.logger).Info("Setting wallpaper", zap.String("path", imagePath))
 
 	// TODO: Implement Windows wallpaper setting
 	// Options:
@@ -0,0 +1,204 @@
+package fetcher
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/logctx"
+	"github.com/genricoloni/synest/internal/paths"
+	"go.uber.org/zap"
+)
+
+// cacheEntry is an artwork kept in the cache directory
+type cacheEntry struct {
+	size    int64
+	fetched time.Time // Download time, the TTL counts from it
+	used    time.Time // Last hit, the least recently used entry is evicted first
+}
+
+// Cache keeps downloaded artwork on disk, one file per URL named by its
+// SHA-256, so players reusing artwork URLs (Spotify does for every track of an
+// album) do not download it again. Local files are not cached.
+type Cache struct {
+	inner   domain.Fetcher
+	logger  *zap.Logger
+	clock   clock.Clock
+	dir     string
+	ttl     time.Duration
+	maxSize int64
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+	size    int64 // Bytes of all entries
+}
+
+// NewCache wraps inner with the cache configured in cfg. Artwork already in the
+// cache directory is kept, ordered by download time until it is used again.
+func NewCache(logger *zap.Logger, cfg domain.Config, clk clock.Clock, inner domain.Fetcher) *Cache {
+	settings := cfg.GetArtCache()
+	c := &Cache{
+		inner:   inner,
+		logger:  logger,
+		clock:   clk,
+		dir:     settings.Dir,
+		ttl:     settings.TTL,
+		maxSize: settings.MaxSize,
+		entries: make(map[string]*cacheEntry),
+	}
+	c.load()
+	return c
+}
+
+// load indexes the cache directory, removing files left by interrupted writes
+func (c *Cache) load() {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			c.logger.Warn("Failed to read the artwork cache", zap.Error(err))
+		}
+		return
+	}
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		if strings.HasSuffix(file.Name(), ".tmp") {
+			os.Remove(filepath.Join(c.dir, file.Name()))
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		c.entries[file.Name()] = &cacheEntry{size: info.Size(), fetched: info.ModTime(), used: info.ModTime()}
+		c.size += info.Size()
+	}
+	c.evict("")
+}
+
+// Fetch implements domain.Fetcher, returning the cached artwork of http(s) URLs
+// while it is fresh and downloading it otherwise
+func (c *Cache) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	if u, err := url.Parse(rawURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return c.inner.Fetch(ctx, rawURL)
+	}
+	sum := sha256.Sum256([]byte(rawURL))
+	key := hex.EncodeToString(sum[:])
+
+	if data := c.lookup(key); data != nil {
+		logctx.Logger(ctx, c.logger).Debug("Artwork found in cache", zap.String("url", rawURL))
+		return data, nil
+	}
+	data, err := c.inner.Fetch(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.store(key, data); err != nil {
+		logctx.Logger(ctx, c.logger).Warn("Failed to cache artwork", zap.Error(err))
+	}
+	return data, nil
+}
+
+// lookup returns the cached artwork of key, nil when it is missing or expired
+func (c *Cache) lookup(key string) []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	now := c.clock.Now()
+	if c.ttl > 0 && now.Sub(entry.fetched) >= c.ttl {
+		c.remove(key)
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(c.dir, key))
+	if err != nil {
+		// Removed behind our back, e.g. by a cache cleaner
+		c.remove(key)
+		return nil
+	}
+	entry.used = now
+	return data
+}
+
+// store writes the artwork of key and evicts the least recently used artwork
+// beyond the size limit. Artwork larger than the limit is not cached.
+func (c *Cache) store(key string, data []byte) error {
+	size := int64(len(data))
+	if size > c.maxSize {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := paths.Ensure(c.dir); err != nil {
+		return err
+	}
+	path := filepath.Join(c.dir, key)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return errors.Join(err, os.Remove(tmp))
+	}
+
+	if old, ok := c.entries[key]; ok {
+		c.size -= old.size
+	}
+	now := c.clock.Now()
+	c.entries[key] = &cacheEntry{size: size, fetched: now, used: now}
+	c.size += size
+	c.evict(key)
+	return nil
+}
+
+// evict removes the least recently used entries, but keep, until the cache
+// fits in its size limit. Must be called with c.mu held.
+func (c *Cache) evict(keep string) {
+	for c.size > c.maxSize {
+		oldest := ""
+		for key, entry := range c.entries {
+			if key != keep && (oldest == "" || entry.used.Before(c.entries[oldest].used)) {
+				oldest = key
+			}
+		}
+		if oldest == "" {
+			return
+		}
+		c.remove(oldest)
+	}
+}
+
+// remove deletes the entry of key and its file. Must be called with c.mu held.
+func (c *Cache) remove(key string) {
+	if entry, ok := c.entries[key]; ok {
+		c.size -= entry.size
+		delete(c.entries, key)
+	}
+	if err := os.Remove(filepath.Join(c.dir, key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		c.logger.Warn("Failed to remove cached artwork", zap.Error(err))
+	}
+}
+
+// BytesFetched implements domain.BandwidthCounter with the downloads of the
+// wrapped fetcher, cache hits are free
+func (c *Cache) BytesFetched() uint64 {
+	if counter, ok := c.inner.(domain.BandwidthCounter); ok {
+		return counter.BytesFetched()
+	}
+	return 0
+}
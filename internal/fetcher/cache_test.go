@@ -0,0 +1,72 @@
+package fetcher
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+func TestCache_Fetch(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	dir := t.TempDir()
+	cfg := &mockConfig{artCache: domain.ArtCache{Enabled: true, Dir: dir, TTL: time.Hour, MaxSize: 10}}
+	inner := &recordingFetcher{}
+	cache := NewCache(zap.NewNop(), cfg, clk, inner)
+
+	fetch := func(url string) {
+		t.Helper()
+		if data, err := cache.Fetch(context.Background(), url); err != nil || string(data) != "image" {
+			t.Fatalf("Fetch(%s) = %q, %v", url, data, err)
+		}
+	}
+	expectDownloads := func(want int) {
+		t.Helper()
+		if len(inner.urls) != want {
+			t.Fatalf("expected %d downloads, got %v", want, inner.urls)
+		}
+	}
+
+	// The second fetch of a URL is a hit
+	fetch("https://i.scdn.co/image/a")
+	fetch("https://i.scdn.co/image/a")
+	expectDownloads(1)
+
+	// Local files are read every time
+	fetch("file:///home/user/cover.jpg")
+	fetch("file:///home/user/cover.jpg")
+	expectDownloads(3)
+
+	// Expired artwork is downloaded again
+	clk.Advance(time.Hour)
+	fetch("https://i.scdn.co/image/a")
+	expectDownloads(4)
+
+	// Two 5 bytes covers fit, a third one evicts the least recently used
+	clk.Advance(time.Minute)
+	fetch("https://i.scdn.co/image/b")
+	clk.Advance(time.Minute)
+	fetch("https://i.scdn.co/image/a")
+	clk.Advance(time.Minute)
+	fetch("https://i.scdn.co/image/c")
+	expectDownloads(6)
+	fetch("https://i.scdn.co/image/a")
+	expectDownloads(6)
+	fetch("https://i.scdn.co/image/b")
+	expectDownloads(7)
+
+	// A restarted daemon finds the cached artwork, aged by the time of its file
+	files, err := os.ReadDir(dir)
+	if err != nil || len(files) != 2 {
+		t.Fatalf("expected 2 cached files, got %d (%v)", len(files), err)
+	}
+	restarted := NewCache(zap.NewNop(), cfg, clock.NewFake(time.Now()), inner)
+	if _, err := restarted.Fetch(context.Background(), "https://i.scdn.co/image/b"); err != nil {
+		t.Fatal(err)
+	}
+	expectDownloads(7)
+}
@@ -0,0 +1,49 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+// Dispatcher hands each artwork URL to the fetcher of its scheme: local files
+// are read from disk, http(s) URLs are downloaded
+type Dispatcher struct {
+	http domain.Fetcher
+	file domain.Fetcher
+}
+
+// NewDispatcher dispatches http(s) URLs to httpFetcher and local artwork to fileFetcher
+func NewDispatcher(httpFetcher, fileFetcher domain.Fetcher) *Dispatcher {
+	return &Dispatcher{http: httpFetcher, file: fileFetcher}
+}
+
+// Fetch implements domain.Fetcher
+func (d *Dispatcher) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	if filepath.IsAbs(rawURL) {
+		return d.file.Fetch(ctx, rawURL)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid artwork URL: %w", err)
+	}
+	switch u.Scheme {
+	case "file":
+		return d.file.Fetch(ctx, rawURL)
+	case "http", "https":
+		return d.http.Fetch(ctx, rawURL)
+	default:
+		return nil, fmt.Errorf("unsupported protocol: %s", u.Scheme)
+	}
+}
+
+// BytesFetched implements domain.BandwidthCounter with the downloads, local files are free
+func (d *Dispatcher) BytesFetched() uint64 {
+	if counter, ok := d.http.(domain.BandwidthCounter); ok {
+		return counter.BytesFetched()
+	}
+	return 0
+}
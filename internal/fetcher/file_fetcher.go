@@ -0,0 +1,91 @@
+package fetcher
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/genricoloni/synest/internal/logctx"
+	"go.uber.org/zap"
+)
+
+// _sniffLen is how much of a file is read to detect its type
+const _sniffLen = 512
+
+// FileFetcher reads artwork that players keep on disk, reported as file:// URLs
+// (VLC, mpv, Lollypop) or as plain absolute paths
+type FileFetcher struct {
+	logger *zap.Logger
+}
+
+// NewFileFetcher creates a new fetcher of local artwork
+func NewFileFetcher(logger *zap.Logger) *FileFetcher {
+	return &FileFetcher{logger: logger}
+}
+
+// Fetch reads the image at the given file:// URL or path. Percent-encoded
+// characters of URLs, such as %20 for spaces, are decoded.
+func (f *FileFetcher) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	path, err := localPath(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open artwork: %w", err)
+	}
+	defer file.Close()
+
+	// Files carry no content type, it is sniffed from their first bytes
+	head := make([]byte, _sniffLen)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("failed to read artwork: %w", err)
+	}
+	head = head[:n]
+	data, err := readImageBody(http.DetectContentType(head), io.MultiReader(bytes.NewReader(head), file), _maxImageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	logctx.Logger(ctx, f.logger).Debug("Image read successfully", zap.Int("bytes", len(data)), zap.String("path", path))
+	return data, nil
+}
+
+// localPath returns the path on disk of a file:// URL, or of a plain absolute path
+func localPath(rawURL string) (string, error) {
+	if filepath.IsAbs(rawURL) {
+		return rawURL, nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid artwork URL: %w", err)
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported protocol: %s", u.Scheme)
+	}
+	if u.Host != "" && u.Host != "localhost" {
+		return "", fmt.Errorf("artwork on another host: %s", u.Host)
+	}
+	// Path is already percent-decoded; file:///C:/cover.jpg names C:\cover.jpg on Windows
+	path := u.Path
+	if len(path) > 2 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	path = filepath.FromSlash(path)
+	if !filepath.IsAbs(path) || strings.ContainsRune(path, 0) {
+		return "", fmt.Errorf("invalid artwork path: %q", u.Path)
+	}
+	return path, nil
+}
@@ -0,0 +1,101 @@
+package fetcher
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestFileFetcher_Fetch(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "folder name")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	cover := filepath.Join(dir, "cover #1.png")
+	if err := os.WriteFile(cover, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	notes := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(notes, []byte("not an image"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fileURL := (&url.URL{Scheme: "file", Path: filepath.ToSlash(cover)}).String()
+
+	tests := []struct {
+		name          string
+		url           string
+		expectedError string
+	}{
+		{name: "Success - Percent-Encoded URL", url: fileURL},
+		{name: "Success - Localhost URL", url: strings.Replace(fileURL, "file://", "file://localhost", 1)},
+		{name: "Success - Plain Path", url: cover},
+		{name: "Error - Not An Image", url: notes, expectedError: "url is not an image"},
+		{name: "Error - Missing File", url: cover + ".missing", expectedError: "failed to open artwork"},
+		{name: "Error - Remote Host", url: "file://server/share/cover.jpg", expectedError: "artwork on another host"},
+	}
+
+	f := NewFileFetcher(zap.NewNop())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := f.Fetch(context.Background(), tt.url)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(data, buf.Bytes()) {
+				t.Errorf("expected %d bytes, got %d", buf.Len(), len(data))
+			}
+		})
+	}
+}
+
+func TestDispatcher_Fetch(t *testing.T) {
+	httpFetcher := &recordingFetcher{}
+	fileFetcher := &recordingFetcher{}
+	d := NewDispatcher(httpFetcher, fileFetcher)
+
+	for _, rawURL := range []string{"https://i.scdn.co/image/ab67", "http://localhost/cover.jpg"} {
+		if _, err := d.Fetch(context.Background(), rawURL); err != nil {
+			t.Errorf("unexpected error for %s: %v", rawURL, err)
+		}
+	}
+	for _, rawURL := range []string{"file:///home/user/folder%20name/cover.jpg", "/home/user/cover.jpg"} {
+		if _, err := d.Fetch(context.Background(), rawURL); err != nil {
+			t.Errorf("unexpected error for %s: %v", rawURL, err)
+		}
+	}
+	if len(httpFetcher.urls) != 2 || len(fileFetcher.urls) != 2 {
+		t.Errorf("expected 2 downloads and 2 local reads, got %v and %v", httpFetcher.urls, fileFetcher.urls)
+	}
+
+	if _, err := d.Fetch(context.Background(), "ftp://example.com/cover.jpg"); err == nil || !strings.Contains(err.Error(), "unsupported protocol") {
+		t.Errorf("expected an unsupported protocol error, got %v", err)
+	}
+}
+
+// recordingFetcher records the URLs it was asked for
+type recordingFetcher struct {
+	urls []string
+}
+
+func (r *recordingFetcher) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	r.urls = append(r.urls, rawURL)
+	return []byte("image"), nil
+}
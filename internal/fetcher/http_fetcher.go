@@ -4,37 +4,56 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"strings"
-	"time"
+	"sync"
+	"sync/atomic"
 
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/logctx"
 	"go.uber.org/zap"
 )
 
-const _maxImageSize = 10 * 1024 * 1024 // 10 MB
+const (
+	_maxImageSize        = 10 * 1024 * 1024 // 10 MB
+	_maxMeteredImageSize = 2 * 1024 * 1024  // 2 MB, on metered connections
+)
 
 // HTTPFetcher handles downloading image data from HTTP/HTTPS URLs
 type HTTPFetcher struct {
-	logger *zap.Logger
-	client *http.Client
+	logger  *zap.Logger
+	client  *http.Client
+	metered domain.MeteredSource
+	fetched atomic.Uint64 // Bytes downloaded since startup
+
+	mu       sync.Mutex
+	lastURL  string // Artwork kept for the next track of the same album
+	lastData []byte
 }
 
 // NewHTTPFetcher creates a new HTTP-based fetcher instance
-func NewHTTPFetcher(logger *zap.Logger) *HTTPFetcher {
+func NewHTTPFetcher(logger *zap.Logger, cfg domain.Config, metered domain.MeteredSource) *HTTPFetcher {
 	return &HTTPFetcher{
 		logger: logger,
 		client: &http.Client{
-			Timeout: 10 * time.Second, // Essential to prevent blocking the daemon
+			Timeout: cfg.GetFetchTimeout(), // Essential to prevent blocking the daemon
 		},
+		metered: metered,
 	}
 }
 
-// Fetch downloads image data from the given URL
+// Fetch downloads image data from the given URL. On metered connections the
+// last artwork is reused when the URL did not change, and large images are rejected.
 func (f *HTTPFetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
-	// Future enhancement: validate protocol
-	// if !strings.HasPrefix(url, "http") {
-	//     return nil, errors.New("unsupported protocol")
-	// }
+	limit := int64(_maxImageSize)
+	if f.metered.Metered(ctx) {
+		if data := f.cached(url); data != nil {
+			logctx.Logger(ctx, f.logger).Debug("Metered connection, reusing the last artwork", zap.String("url", url))
+			return data, nil
+		}
+		limit = _maxMeteredImageSize
+	}
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -53,18 +72,65 @@ func (f *HTTPFetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
-	// Validazione Content-Type
-    if !strings.HasPrefix(resp.Header.Get("Content-Type"), "image/") {
-        return nil, fmt.Errorf("url is not an image: %s", resp.Header.Get("Content-Type"))
-    }
+	data, err := readImageBody(resp.Header.Get("Content-Type"), &countingReader{r: resp.Body, n: &f.fetched}, limit)
+	if err != nil {
+		return nil, err
+	}
 
-	limitReader := io.LimitReader(resp.Body, _maxImageSize)
+	f.mu.Lock()
+	f.lastURL, f.lastData = url, data
+	f.mu.Unlock()
 
-	data, err := io.ReadAll(limitReader)
+	logctx.Logger(ctx, f.logger).Debug("Image fetched successfully", zap.Int("bytes", len(data)), zap.String("url", url))
+	return data, nil
+}
+
+// BytesFetched implements domain.BandwidthCounter
+func (f *HTTPFetcher) BytesFetched() uint64 {
+	return f.fetched.Load()
+}
+
+// cached returns the last artwork when it was fetched from url, nil otherwise
+func (f *HTTPFetcher) cached(url string) []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if url != f.lastURL {
+		return nil
+	}
+	return f.lastData
+}
+
+// countingReader adds the bytes read through it to n
+type countingReader struct {
+	r io.Reader
+	n *atomic.Uint64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(uint64(n))
+	return n, err
+}
+
+// readImageBody validates the declared content type and reads at most limit bytes.
+// Oversized bodies are rejected instead of truncated, so partial images never reach the processor.
+func readImageBody(contentType string, body io.Reader, limit int64) ([]byte, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.HasPrefix(mediaType, "image/") {
+		return nil, fmt.Errorf("url is not an image: %s", contentType)
+	}
+
+	// Read one extra byte to detect bodies over the limit
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read body: %w", err)
 	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("image exceeds %d bytes", limit)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty image body")
+	}
 
-	f.logger.Debug("Image fetched successfully", zap.Int("bytes", len(data)), zap.String("url", url))
 	return data, nil
 }
@@ -1,6 +1,7 @@
 package fetcher
 
 import (
+	"bytes"
 	"context"
 	"net/http"
 	"net/http/httptest"
@@ -8,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/genricoloni/synest/internal/domain"
 	"go.uber.org/zap"
 )
 
@@ -45,12 +47,31 @@ func TestHTTPFetcher_Fetch(t *testing.T) {
 		{
 			name:        "Error - Response Too Large",
 			contentType: "image/png",
-			// Generate a body exceeding 10MB (simulated for the test with a reduced limit if necessary,
-			// but here we use the real fetcher logic)
-			responseBody:   []byte(strings.Repeat("a", 11*1024*1024)),
+			// Generate a body exceeding the 10MB limit: it must be rejected, not truncated,
+			// otherwise a partial image would reach the processor
+			responseBody:  []byte(strings.Repeat("a", 11*1024*1024)),
+			statusCode:    http.StatusOK,
+			expectedError: "image exceeds",
+		},
+		{
+			name:           "Success - Exactly At Limit",
+			contentType:    "image/png",
+			responseBody:   []byte(strings.Repeat("a", 10*1024*1024)),
 			statusCode:     http.StatusOK,
-			expectedError:  "",               // io.ReadAll stops at the limit, does not return error by default but truncated data
-			expectedLength: 10 * 1024 * 1024, // Limit enforced in the code
+			expectedLength: 10 * 1024 * 1024,
+		},
+		{
+			name:           "Success - Content Type With Parameters",
+			contentType:    "IMAGE/JPEG; charset=binary",
+			responseBody:   []byte("fake-image-data"),
+			statusCode:     http.StatusOK,
+			expectedLength: 15,
+		},
+		{
+			name:          "Error - Empty Body",
+			contentType:   "image/jpeg",
+			statusCode:    http.StatusOK,
+			expectedError: "empty image body",
 		},
 		{
 			name: "Error - Context Cancelled",
@@ -83,7 +104,7 @@ func TestHTTPFetcher_Fetch(t *testing.T) {
 			}
 			defer cancel()
 
-			fetcher := NewHTTPFetcher(zap.NewNop())
+			fetcher := NewHTTPFetcher(zap.NewNop(), &mockConfig{timeout: 10 * time.Second}, new(fakeMetered))
 			data, err := fetcher.Fetch(ctx, server.URL)
 
 			// Verify error
@@ -108,3 +129,87 @@ func TestHTTPFetcher_Fetch(t *testing.T) {
 		})
 	}
 }
+
+// TestHTTPFetcher_Metered verifies the downloaded bytes are counted, and that a
+// metered connection reuses the last artwork and rejects large images
+func TestHTTPFetcher_Metered(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "image/jpeg")
+		if r.URL.Path == "/large.jpg" {
+			_, _ = w.Write([]byte(strings.Repeat("a", 3*1024*1024)))
+			return
+		}
+		_, _ = w.Write([]byte("fake-image-data"))
+	}))
+	defer server.Close()
+
+	metered := fakeMetered(false)
+	fetcher := NewHTTPFetcher(zap.NewNop(), &mockConfig{timeout: 10 * time.Second}, &metered)
+	if _, err := fetcher.Fetch(context.Background(), server.URL+"/album.jpg"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := fetcher.BytesFetched(); got != 15 {
+		t.Errorf("expected 15 bytes fetched, got %d", got)
+	}
+
+	metered = true
+	data, err := fetcher.Fetch(context.Background(), server.URL+"/album.jpg")
+	if err != nil || string(data) != "fake-image-data" {
+		t.Fatalf("expected the last artwork, got %q, %v", data, err)
+	}
+	if requests != 1 {
+		t.Errorf("expected the last artwork to be reused, got %d requests", requests)
+	}
+	if _, err := fetcher.Fetch(context.Background(), server.URL+"/large.jpg"); err == nil || !strings.Contains(err.Error(), "image exceeds") {
+		t.Errorf("expected a large image to be rejected, got %v", err)
+	}
+}
+
+// FuzzReadImageBody checks content-type validation and size limiting on arbitrary input.
+// Run with: go test ./internal/fetcher -fuzz FuzzReadImageBody
+func FuzzReadImageBody(f *testing.F) {
+	f.Add("image/jpeg", []byte("fake-image-data"))
+	f.Add("image/png; charset=binary", []byte{0x89, 'P', 'N', 'G'})
+	f.Add("text/html", []byte("<html>"))
+	f.Add("", []byte{})
+	f.Add("image/", []byte{0})
+	f.Add(";;;image/jpeg", []byte("x"))
+
+	f.Fuzz(func(t *testing.T, contentType string, body []byte) {
+		data, err := readImageBody(contentType, bytes.NewReader(body), _maxImageSize)
+		if err != nil {
+			return
+		}
+		if len(data) == 0 || len(data) > _maxImageSize {
+			t.Errorf("accepted body of invalid size %d", len(data))
+		}
+		if !bytes.Equal(data, body) {
+			t.Error("accepted body was altered")
+		}
+	})
+}
+
+// mockConfig implements the parts of domain.Config used by the fetcher.
+// Other getters are promoted from the nil embedded interface and must not be called.
+type mockConfig struct {
+	domain.Config
+	timeout  time.Duration
+	artCache domain.ArtCache
+}
+
+func (m *mockConfig) GetFetchTimeout() time.Duration {
+	return m.timeout
+}
+
+func (m *mockConfig) GetArtCache() domain.ArtCache {
+	return m.artCache
+}
+
+// fakeMetered is a connection whose cost is set by the test
+type fakeMetered bool
+
+func (f *fakeMetered) Metered(ctx context.Context) bool {
+	return bool(*f)
+}
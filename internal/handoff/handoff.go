@@ -0,0 +1,86 @@
+// Package handoff carries the wallpaper state over to the next daemon when it
+// replaces the running one, e.g. after an upgrade, so the original wallpaper
+// never shows up in between.
+package handoff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/genricoloni/synest/internal/paths"
+)
+
+const (
+	// FileName is the handoff file written to the state directory
+	FileName = "handoff.json"
+
+	// formatVersion is raised when a field changes meaning. Daemons read older
+	// formats and ignore newer ones, which they cannot know how to read.
+	formatVersion = 1
+)
+
+// State is the wallpaper state handed over to the next daemon
+type State struct {
+	Version   int       `json:"version"`
+	Original  string    `json:"original_wallpaper,omitempty"` // Wallpaper captured before synest changed it
+	Wallpaper string    `json:"wallpaper,omitempty"`          // Wallpaper on screen
+	Mode      string    `json:"mode,omitempty"`               // Mode set at runtime, empty to follow the configuration
+	Pinned    string    `json:"pinned,omitempty"`             // Fingerprint of the track whose wallpaper from the history is on screen
+	WrittenAt time.Time `json:"written_at"`
+}
+
+// Path returns the handoff file location for a state directory
+func Path(stateDir string) string {
+	return filepath.Join(stateDir, FileName)
+}
+
+// Write saves s atomically, so a daemon starting at any time reads either the
+// whole state or none
+func Write(path string, s State) error {
+	s.Version = formatVersion
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode handoff: %w", err)
+	}
+
+	if err := paths.Ensure(filepath.Dir(path)); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write handoff: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace handoff: %w", err)
+	}
+	return nil
+}
+
+// Take reads and removes the handoff file, so it is used once. It returns nil
+// without error when there is none.
+func Take(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read handoff: %w", err)
+	}
+	if err := os.Remove(path); err != nil {
+		return nil, fmt.Errorf("failed to remove handoff: %w", err)
+	}
+
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to decode handoff: %w", err)
+	}
+	if s.Version > formatVersion {
+		return nil, fmt.Errorf("handoff format %d is newer than %d", s.Version, formatVersion)
+	}
+	return &s, nil
+}
@@ -0,0 +1,40 @@
+package handoff
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTake verifies a written state is read back once, and states written by
+// a newer daemon are refused
+func TestTake(t *testing.T) {
+	path := Path(t.TempDir())
+
+	if s, err := Take(path); s != nil || err != nil {
+		t.Fatalf("expected no handoff, got %+v, %v", s, err)
+	}
+
+	written := State{Original: "/home/user/bg.png", Wallpaper: "/tmp/synest/a.jpg", Mode: "generative", WrittenAt: time.Now().UTC()}
+	if err := Write(path, written); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	s, err := Take(path)
+	if err != nil || s == nil {
+		t.Fatalf("expected the handoff, got %v", err)
+	}
+	if s.Original != written.Original || s.Wallpaper != written.Wallpaper || s.Mode != written.Mode || s.Version != formatVersion {
+		t.Errorf("expected %+v, got %+v", written, s)
+	}
+	if s, _ := Take(path); s != nil {
+		t.Error("expected the handoff to be used once")
+	}
+
+	if err := os.WriteFile(path, []byte(`{"version": 99, "wallpaper": "/tmp/x.jpg"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Take(path); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("expected a newer format to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+// Package history keeps the last generated wallpapers on disk instead of
+// overwriting them, so earlier ones can be listed and applied again.
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/paths"
+	"github.com/genricoloni/synest/internal/privacy"
+	"go.uber.org/zap"
+)
+
+const (
+	// DirName is the history directory inside the output directory
+	DirName = "history"
+	// IndexFile lists the wallpapers kept in the history directory
+	IndexFile = "index.json"
+)
+
+// Entry is a wallpaper kept in the history. Field names are a stable API.
+type Entry struct {
+	ID        string    `json:"id"`
+	Path      string    `json:"path"`
+	Artist    string    `json:"artist,omitempty"` // Empty for private tracks
+	Title     string    `json:"title,omitempty"`
+	Size      int64     `json:"size"` // Bytes on disk
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Dir returns the history directory for an output directory
+func Dir(outputDir string) string {
+	return filepath.Join(outputDir, DirName)
+}
+
+// Load reads the history kept in dir, newest first. A history that was never
+// written is empty.
+func Load(dir string) ([]Entry, error) {
+	data, err := os.ReadFile(filepath.Join(dir, IndexFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid history index: %w", err)
+	}
+	return entries, nil
+}
+
+// Find returns the entry with the given ID
+func Find(entries []Entry, id string) (Entry, bool) {
+	for _, entry := range entries {
+		if entry.ID == id {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Recorder implements domain.Reporter: it forwards every outcome to the wrapped
+// reporter and keeps a copy of each wallpaper confirmed on screen, pruning the
+// oldest ones beyond the configured limits
+type Recorder struct {
+	domain.Reporter
+	logger *zap.Logger
+	cfg    domain.Config
+
+	mu sync.Mutex // Serializes updates of the history directory
+}
+
+// NewRecorder creates a recorder writing to the history directory in the output directory
+func NewRecorder(logger *zap.Logger, cfg domain.Config, inner domain.Reporter) *Recorder {
+	return &Recorder{Reporter: inner, logger: logger, cfg: cfg}
+}
+
+// Success records the update with the wrapped reporter, then adds the wallpaper
+// to the history when it is enabled. Private tracks are kept without their
+// artist and title. Scenes written instead of a wallpaper are not kept.
+func (r *Recorder) Success(ctx context.Context, wallpaperPath string, meta domain.MediaMetadata) {
+	r.Reporter.Success(ctx, wallpaperPath, meta)
+
+	if r.cfg.GetHistoryMaxCount() <= 0 || r.cfg.GetScene() == domain.SceneOnly {
+		return
+	}
+	var artist, title string
+	if !privacy.Private(ctx) {
+		artist, title = meta.Artist, meta.Title
+	}
+	if err := r.record(wallpaperPath, artist, title, time.Now()); err != nil {
+		r.logger.Warn("Failed to keep wallpaper in history", zap.Error(err))
+	}
+}
+
+// record copies the wallpaper into the history and prunes it
+func (r *Recorder) record(wallpaperPath, artist, title string, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	dir := Dir(r.cfg.GetOutputDir())
+	entries, err := Load(dir)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(wallpaperPath)
+	if err != nil {
+		return err
+	}
+	if err := paths.Ensure(dir); err != nil {
+		return err
+	}
+
+	// IDs sort by time and stay unique when two wallpapers land in the same millisecond
+	base := now.UTC().Format("20060102-150405.000")
+	id := base
+	for n := 2; ; n++ {
+		if _, taken := Find(entries, id); !taken {
+			break
+		}
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+	// Wallpapers are always JPEG, in-memory delivery paths have no extension to keep
+	entry := Entry{
+		ID:        id,
+		Path:      filepath.Join(dir, id+".jpg"),
+		Artist:    artist,
+		Title:     title,
+		Size:      int64(len(data)),
+		CreatedAt: now,
+	}
+	if err := os.WriteFile(entry.Path, data, 0644); err != nil {
+		return err
+	}
+
+	kept, removed := prune(append([]Entry{entry}, entries...), r.cfg.GetHistoryMaxCount(), r.cfg.GetHistoryMaxSize())
+	for _, old := range removed {
+		if err := os.Remove(old.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			r.logger.Warn("Failed to prune history", zap.String("path", old.Path), zap.Error(err))
+		}
+	}
+	return save(dir, kept)
+}
+
+// prune keeps the newest entries within maxCount and maxSize bytes (0 for no
+// size limit). The newest entry is always kept.
+func prune(entries []Entry, maxCount int, maxSize int64) (kept, removed []Entry) {
+	var total int64
+	for i, entry := range entries {
+		total += entry.Size
+		if i > 0 && (i >= maxCount || (maxSize > 0 && total > maxSize)) {
+			return entries[:i], entries[i:]
+		}
+	}
+	return entries, nil
+}
+
+// save writes the index atomically, so readers never see a partial file
+func save(dir string, entries []Entry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, IndexFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
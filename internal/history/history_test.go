@@ -0,0 +1,132 @@
+package history
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/privacy"
+	"go.uber.org/zap"
+)
+
+func TestRecorder(t *testing.T) {
+	dir := t.TempDir()
+	wallpaper := filepath.Join(dir, "current_wallpaper.jpg")
+	inner := &fakeReporter{}
+	recorder := NewRecorder(zap.NewNop(), &mockConfig{outputDir: dir, maxCount: 2}, inner)
+
+	for _, title := range []string{"One", "Two", "Three"} {
+		if err := os.WriteFile(wallpaper, []byte(title), 0644); err != nil {
+			t.Fatal(err)
+		}
+		recorder.Success(context.Background(), wallpaper, domain.MediaMetadata{Artist: "Artist", Title: title})
+	}
+	ctx := privacy.WithPrivate(context.Background())
+	recorder.Success(ctx, wallpaper, domain.MediaMetadata{Artist: "Artist", Title: "Secret"})
+
+	if inner.successes != 4 {
+		t.Errorf("expected every success to reach the wrapped reporter, got %d", inner.successes)
+	}
+
+	entries, err := Load(Dir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Title != "" || entries[1].Title != "Three" {
+		t.Errorf("expected the private wallpaper then Three, got %+v", entries)
+	}
+	if entries[0].ID == entries[1].ID {
+		t.Error("expected unique IDs")
+	}
+	files, _ := filepath.Glob(filepath.Join(Dir(dir), "*.jpg"))
+	if len(files) != 2 {
+		t.Errorf("expected pruned wallpapers to be deleted, got %v", files)
+	}
+	if data, _ := os.ReadFile(entries[1].Path); string(data) != "Three" {
+		t.Errorf("expected a copy of the wallpaper, got %q", data)
+	}
+}
+
+func TestRecorder_Disabled(t *testing.T) {
+	dir := t.TempDir()
+	recorder := NewRecorder(zap.NewNop(), &mockConfig{outputDir: dir}, &fakeReporter{})
+	recorder.Success(context.Background(), filepath.Join(dir, "missing.jpg"), domain.MediaMetadata{})
+
+	if _, err := os.Stat(Dir(dir)); !os.IsNotExist(err) {
+		t.Errorf("expected no history directory while disabled, got %v", err)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	entries := func(sizes ...int64) []Entry {
+		var out []Entry
+		for _, size := range sizes {
+			out = append(out, Entry{Size: size, CreatedAt: time.Now()})
+		}
+		return out
+	}
+	tests := []struct {
+		name     string
+		entries  []Entry
+		maxCount int
+		maxSize  int64
+		kept     int
+	}{
+		{"Within Limits", entries(10, 10), 5, 100, 2},
+		{"Count", entries(10, 10, 10), 2, 0, 2},
+		{"Size", entries(40, 40, 40), 10, 100, 2},
+		{"Newest Kept Over Size", entries(200, 10), 10, 100, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, removed := prune(tt.entries, tt.maxCount, tt.maxSize)
+			if len(kept) != tt.kept || len(kept)+len(removed) != len(tt.entries) {
+				t.Errorf("expected %d kept, got %d kept and %d removed", tt.kept, len(kept), len(removed))
+			}
+		})
+	}
+}
+
+// fakeReporter counts the outcomes forwarded by the recorder
+type fakeReporter struct {
+	successes int
+}
+
+func (f *fakeReporter) Success(ctx context.Context, wallpaperPath string, meta domain.MediaMetadata) {
+	f.successes++
+}
+
+func (f *fakeReporter) Failure(ctx context.Context, step string, err error) {}
+
+func (f *fakeReporter) SetterSelected(name, reason string) {}
+
+// mockConfig implements the parts of domain.Config used by the recorder
+type mockConfig struct {
+	domain.Config
+	outputDir string
+	maxCount  int
+	maxSize   int64
+}
+
+func (m *mockConfig) GetOutputDir() string {
+	return m.outputDir
+}
+
+func (m *mockConfig) GetHistoryMaxCount() int {
+	return m.maxCount
+}
+
+func (m *mockConfig) GetHistoryMaxSize() int64 {
+	return m.maxSize
+}
+
+func (m *mockConfig) GetScene() string {
+	return domain.SceneOff
+}
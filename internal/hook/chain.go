@@ -0,0 +1,133 @@
+package hook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // Outputs of post-process hooks are checked to be images
+	_ "image/png"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/logctx"
+	"github.com/genricoloni/synest/internal/privacy"
+	"go.uber.org/zap"
+)
+
+// stepTimeout bounds each command of a pipeline hook chain, which runs on
+// every wallpaper update
+const stepTimeout = 10 * time.Second
+
+// RewriteURL passes the artwork URL through the pre-fetch commands in order:
+// each reads the URL on its stdin and prints the one to fetch. A command that
+// fails or prints nothing is skipped, the next one gets the URL it was given.
+func RewriteURL(ctx context.Context, logger *zap.Logger, commands []string, url string, meta domain.MediaMetadata) string {
+	rewritten := runChain(ctx, logger, "pre-fetch", commands, []byte(url+"\n"), meta, func(output []byte) ([]byte, error) {
+		next := strings.TrimSpace(string(output))
+		if next == "" || strings.ContainsAny(next, "\r\n") {
+			return nil, errors.New("expected a single URL")
+		}
+		return []byte(next + "\n"), nil
+	})
+	return strings.TrimSpace(string(rewritten))
+}
+
+// ProcessImage passes the encoded wallpaper through the post-process commands
+// in order: each reads the image on its stdin and writes the new one on its
+// stdout. A command that fails or does not write an image is skipped, the next
+// one gets the image it was given.
+func ProcessImage(ctx context.Context, logger *zap.Logger, commands []string, data []byte, meta domain.MediaMetadata) []byte {
+	return runChain(ctx, logger, "post-process", commands, data, meta, func(output []byte) ([]byte, error) {
+		if _, _, err := image.DecodeConfig(bytes.NewReader(output)); err != nil {
+			return nil, fmt.Errorf("expected a JPEG or PNG image: %w", err)
+		}
+		return output, nil
+	})
+}
+
+// runChain runs commands in order, each reading the accepted output of the
+// previous one. Private tracks are kept out of the environment of the commands.
+func runChain(ctx context.Context, logger *zap.Logger, stage string, commands []string, input []byte, meta domain.MediaMetadata, accept func([]byte) ([]byte, error)) []byte {
+	if len(commands) == 0 {
+		return input
+	}
+	logger = logctx.Logger(ctx, logger)
+	env := os.Environ()
+	if !privacy.Private(ctx) {
+		env = append(env, trackEnv(meta)...)
+	}
+
+	for i, command := range commands {
+		output, err := runStep(ctx, command, input, env)
+		if err == nil {
+			output, err = accept(output)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return input
+			}
+			logger.Warn("Pipeline hook failed, skipping it",
+				zap.String("stage", stage),
+				zap.Int("step", i+1),
+				zap.String("command", command),
+				zap.Error(err))
+			continue
+		}
+		input = output
+	}
+	return input
+}
+
+// runStep runs one command of a chain with input on its stdin and returns its stdout
+func runStep(ctx context.Context, command string, input []byte, env []string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, stepTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := shellCommand(ctx, command)
+	cmd.Env = env
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("command failed: %w (output: %s)", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// Fetcher rewrites the artwork URL with the pre-fetch hooks, read on every
+// fetch so reloads apply, before handing it to the wrapped fetcher
+type Fetcher struct {
+	inner  domain.Fetcher
+	logger *zap.Logger
+	cfg    domain.Config
+}
+
+// NewFetcher wraps inner with the pre-fetch hooks
+func NewFetcher(logger *zap.Logger, cfg domain.Config, inner domain.Fetcher) *Fetcher {
+	return &Fetcher{inner: inner, logger: logger, cfg: cfg}
+}
+
+// Fetch implements domain.Fetcher, the hooks get the track carried by ctx
+func (f *Fetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
+	if commands := f.cfg.GetPreFetchHooks(); len(commands) > 0 {
+		meta, _ := domain.TrackOf(ctx)
+		if rewritten := RewriteURL(ctx, f.logger, commands, url, meta); rewritten != url {
+			logctx.Logger(ctx, f.logger).Debug("Artwork URL rewritten by the pre-fetch hooks")
+			url = rewritten
+		}
+	}
+	return f.inner.Fetch(ctx, url)
+}
+
+// BytesFetched implements domain.BandwidthCounter when the wrapped fetcher does
+func (f *Fetcher) BytesFetched() uint64 {
+	if counter, ok := f.inner.(domain.BandwidthCounter); ok {
+		return counter.BytesFetched()
+	}
+	return 0
+}
@@ -0,0 +1,71 @@
+//go:build !windows
+// +build !windows
+
+package hook
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// TestRewriteURL verifies the pre-fetch commands run in order with the track in
+// their environment, and failing or silent ones are skipped
+func TestRewriteURL(t *testing.T) {
+	meta := domain.MediaMetadata{Title: "Song", Source: "spotify"}
+	commands := []string{
+		`sed 's/300x300/1200x1200/'`,
+		"exit 1",
+		"true",
+		`printf '%s?source=%s\n' "$(cat)" "$SYNEST_SOURCE"`,
+	}
+
+	got := RewriteURL(context.Background(), zap.NewNop(), commands, "https://example.com/300x300.jpg", meta)
+	if got != "https://example.com/1200x1200.jpg?source=spotify" {
+		t.Errorf("unexpected URL %q", got)
+	}
+}
+
+// TestProcessImage verifies the post-process commands receive the image on
+// stdin and outputs that are not images are discarded
+func TestProcessImage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	got := ProcessImage(context.Background(), zap.NewNop(), []string{"cat", "echo garbage"}, data, domain.MediaMetadata{})
+	if !bytes.Equal(got, data) {
+		t.Error("expected the image of the last command that wrote one")
+	}
+}
+
+// fakeFetcher records the URL it was asked for
+type fakeFetcher struct {
+	url string
+}
+
+func (f *fakeFetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
+	f.url = url
+	return []byte("image"), nil
+}
+
+// TestFetcher verifies the URL is rewritten with the track carried by ctx
+func TestFetcher(t *testing.T) {
+	inner := &fakeFetcher{}
+	f := NewFetcher(zap.NewNop(), &mockConfig{preFetch: []string{`echo "https://covers.example/$SYNEST_ALBUM.jpg"`}}, inner)
+
+	ctx := domain.WithTrack(context.Background(), domain.MediaMetadata{Album: "Album"})
+	if _, err := f.Fetch(ctx, "https://example.com/a.jpg"); err != nil {
+		t.Fatal(err)
+	}
+	if inner.url != "https://covers.example/Album.jpg" {
+		t.Errorf("unexpected URL %q", inner.url)
+	}
+}
@@ -0,0 +1,81 @@
+// Package hook runs user commands in response to wallpaper changes.
+package hook
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/logctx"
+	"go.uber.org/zap"
+)
+
+// appliedTimeout bounds the on-applied command, which runs in the pipeline.
+// Long-running scripts should detach themselves.
+const appliedTimeout = 30 * time.Second
+
+// Runner implements domain.AppliedHook by running the configured shell command
+type Runner struct {
+	logger  *zap.Logger
+	command string
+}
+
+// NewRunner creates a hook runner from the configuration
+func NewRunner(logger *zap.Logger, cfg domain.Config) *Runner {
+	command := cfg.GetOnAppliedCommand()
+	if command != "" {
+		logger.Info("On-applied hook configured", zap.String("command", command))
+	}
+	return &Runner{logger: logger, command: command}
+}
+
+// Applied runs the on-applied command, if any, with the new wallpaper and the
+// track in its environment
+func (r *Runner) Applied(ctx context.Context, wallpaperPath string, meta domain.MediaMetadata) error {
+	if r.command == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, appliedTimeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, r.command)
+	cmd.Env = append(os.Environ(), "SYNEST_WALLPAPER="+wallpaperPath)
+	cmd.Env = append(cmd.Env, trackEnv(meta)...)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("command failed: %w (output: %s)", err, string(output))
+	}
+
+	logctx.Logger(ctx, r.logger).Debug("On-applied hook completed", zap.String("command", r.command))
+	return nil
+}
+
+// trackEnv describes the track to a command through its environment. Playback
+// options the player does not report are passed empty.
+func trackEnv(meta domain.MediaMetadata) []string {
+	var volume, shuffle string
+	if meta.Volume != nil {
+		volume = strconv.FormatFloat(*meta.Volume, 'f', 2, 64)
+	}
+	if meta.Shuffle != nil {
+		shuffle = strconv.FormatBool(*meta.Shuffle)
+	}
+	return []string{
+		"SYNEST_TITLE=" + meta.Title,
+		"SYNEST_ARTIST=" + meta.Artist,
+		"SYNEST_ARTISTS=" + meta.DisplayArtist(),
+		"SYNEST_ALBUM=" + meta.Album,
+		"SYNEST_URL=" + meta.URL,
+		"SYNEST_SOURCE=" + meta.Source,
+		"SYNEST_PLAYER=" + meta.PlayerName,
+		"SYNEST_TRACK_ID=" + meta.TrackID,
+		"SYNEST_VOLUME=" + volume,
+		"SYNEST_SHUFFLE=" + shuffle,
+		"SYNEST_LOOP=" + string(meta.Loop),
+	}
+}
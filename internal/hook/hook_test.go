@@ -0,0 +1,85 @@
+//go:build !windows
+// +build !windows
+
+package hook
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+func TestRunner_Applied(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	volume, shuffle := 0.5, true
+	meta := domain.MediaMetadata{Title: "Song", Artist: "Artist", Album: "Album", Volume: &volume, Shuffle: &shuffle}
+
+	tests := []struct {
+		name          string
+		command       string
+		expectedError string
+		expectedOut   string
+	}{
+		{name: "No Command Is A No-Op"},
+		{
+			name:        "Environment Passed",
+			command:     `printf '%s|%s|%s|%s|%s|%s' "$SYNEST_WALLPAPER" "$SYNEST_ARTIST" "$SYNEST_TITLE" "$SYNEST_VOLUME" "$SYNEST_SHUFFLE" "$SYNEST_LOOP" > ` + out,
+			expectedOut: "/tmp/wallpaper.jpg|Artist|Song|0.50|true|",
+		},
+		{
+			name:          "Failing Command",
+			command:       "echo broken >&2; exit 3",
+			expectedError: "broken",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = os.Remove(out)
+			runner := NewRunner(zap.NewNop(), &mockConfig{command: tt.command})
+			err := runner.Applied(context.Background(), "/tmp/wallpaper.jpg", meta)
+
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing '%s', got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.expectedOut != "" {
+				data, err := os.ReadFile(out)
+				if err != nil {
+					t.Fatalf("hook did not run: %v", err)
+				}
+				if string(data) != tt.expectedOut {
+					t.Errorf("expected %q, got %q", tt.expectedOut, data)
+				}
+			}
+		})
+	}
+}
+
+// mockConfig implements the parts of domain.Config used by the runner.
+// Other getters are promoted from the nil embedded interface and must not be called.
+type mockConfig struct {
+	domain.Config
+	command  string
+	preFetch []string
+}
+
+func (m *mockConfig) GetPreFetchHooks() []string {
+	return m.preFetch
+}
+
+func (m *mockConfig) GetOnAppliedCommand() string {
+	return m.command
+}
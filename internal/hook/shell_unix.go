@@ -0,0 +1,14 @@
+//go:build !windows
+// +build !windows
+
+package hook
+
+import (
+	"context"
+	"os/exec"
+)
+
+// shellCommand runs command through the POSIX shell
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "/bin/sh", "-c", command)
+}
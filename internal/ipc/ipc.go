@@ -0,0 +1,52 @@
+// Package ipc exposes the running daemon on the session bus, so tools such as
+// synestctl can control it without editing the config file.
+package ipc
+
+import (
+	"fmt"
+
+	"github.com/genricoloni/synest/internal/theme"
+)
+
+const (
+	// BusName is the well-known name the daemon owns on the session bus
+	BusName = "io.github.genricoloni.Synest"
+	// ObjectPath is the path of the daemon object
+	ObjectPath = "/io/github/genricoloni/Synest"
+	// Interface is the D-Bus interface of the daemon object
+	Interface = "io.github.genricoloni.Synest1"
+)
+
+// AlreadyRunningError reports that another daemon owns BusName. Two daemons
+// would fight over the wallpaper, so the second one exits.
+type AlreadyRunningError struct {
+	PID uint32 // Process of the running daemon, 0 when the bus does not tell
+}
+
+func (e *AlreadyRunningError) Error() string {
+	if e.PID == 0 {
+		return "synest is already running"
+	}
+	return fmt.Sprintf("synest is already running (pid %d)", e.PID)
+}
+
+// Controller is the part of the engine reachable over the bus
+type Controller interface {
+	// SetMode switches the wallpaper mode and re-renders the wallpaper on screen
+	SetMode(mode string) error
+	// SetPrivate switches private mode: off, on or freeze
+	SetPrivate(mode string) error
+	// ApplyHistory puts the wallpaper with the given history ID back on screen
+	ApplyHistory(id string) error
+}
+
+// ThemeSource provides the theme published as properties of the daemon object
+// and the wallpapers announced with the WallpaperApplied signal
+type ThemeSource interface {
+	// Current returns the theme of the wallpaper on screen
+	Current() theme.Theme
+	// Changes is signaled after every new theme
+	Changes() <-chan struct{}
+	// Applied delivers every wallpaper confirmed on screen
+	Applied() <-chan theme.Applied
+}
@@ -0,0 +1,305 @@
+//go:build linux
+// +build linux
+
+package ipc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/theme"
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+	"go.uber.org/zap"
+)
+
+// introspection describes the daemon object to tools such as busctl
+const introspection = `
+<node>
+	<interface name="` + Interface + `">
+		<method name="SetMode">
+			<arg direction="in" type="s" name="mode"/>
+		</method>
+		<method name="SetPrivate">
+			<arg direction="in" type="s" name="mode"/>
+		</method>
+		<method name="ApplyHistory">
+			<arg direction="in" type="s" name="id"/>
+		</method>
+		<method name="ControlPlayer">
+			<arg direction="in" type="s" name="command"/>
+		</method>
+		<signal name="WallpaperApplied">
+			<arg type="a{sv}" name="info"/>
+		</signal>
+		<property name="ThemeVersion" type="i" access="read">
+			<annotation name="org.freedesktop.DBus.Property.EmitsChangedSignal" value="const"/>
+		</property>
+		<property name="Wallpaper" type="s" access="read"/>
+		<property name="Palette" type="as" access="read"/>
+	</interface>` + prop.IntrospectDataString + introspect.IntrospectDeclarationString + `
+</node>`
+
+// playerTimeout bounds a playback command forwarded to a player
+const playerTimeout = 5 * time.Second
+
+// Server publishes a Controller on the session bus
+type Server struct {
+	logger  *zap.Logger
+	ctrl    Controller
+	players domain.PlayerController
+	themes  ThemeSource
+	conn    *dbus.Conn
+	props   *prop.Properties
+	done    chan struct{} // Closed by Stop to end the theme updates and announcements
+}
+
+// NewServer creates a server for ctrl, players and themes. Nothing is published until Start.
+func NewServer(logger *zap.Logger, ctrl Controller, players domain.PlayerController, themes ThemeSource) *Server {
+	return &Server{logger: logger, ctrl: ctrl, players: players, themes: themes}
+}
+
+// Claim connects to the session bus and takes BusName, so only one daemon runs
+// per session. It returns an *AlreadyRunningError when another process owns it.
+func (s *Server) Claim(ctx context.Context) error {
+	conn, err := dbus.ConnectSessionBus(dbus.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("session bus connection failed: %w", err)
+	}
+
+	reply, err := conn.RequestName(BusName, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("failed to request %s: %w", BusName, err)
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		running := &AlreadyRunningError{}
+		// Best effort, the message is still clear without the PID
+		_ = conn.BusObject().CallWithContext(ctx, "org.freedesktop.DBus.GetConnectionUnixProcessID", 0, BusName).Store(&running.PID)
+		_ = conn.Close()
+		return running
+	}
+
+	s.conn = conn
+	return nil
+}
+
+// Start exports the daemon object on the connection of Claim, claiming BusName
+// first if Claim was not called or failed. The name is kept when exporting
+// fails, so a second daemon is still refused.
+func (s *Server) Start(ctx context.Context) error {
+	if s.conn == nil {
+		if err := s.Claim(ctx); err != nil {
+			return err
+		}
+	}
+	conn := s.conn
+
+	if err := conn.Export(&object{s}, ObjectPath, Interface); err != nil {
+		return fmt.Errorf("failed to export %s: %w", ObjectPath, err)
+	}
+	// Changes are announced with a single PropertiesChanged signal, see publishTheme
+	current := s.themes.Current()
+	props, err := prop.Export(conn, ObjectPath, prop.Map{Interface: {
+		"ThemeVersion": {Value: int32(current.Version), Emit: prop.EmitConst},
+		"Wallpaper":    {Value: current.Wallpaper, Emit: prop.EmitFalse},
+		"Palette":      {Value: current.Palette, Emit: prop.EmitFalse},
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to export properties: %w", err)
+	}
+	if err := conn.Export(introspect.Introspectable(introspection), ObjectPath, introspect.IntrospectData.Name); err != nil {
+		return fmt.Errorf("failed to export introspection data: %w", err)
+	}
+
+	s.props = props
+	s.done = make(chan struct{})
+	go s.watchThemes()
+	s.logger.Info("Control interface available", zap.String("name", BusName))
+	return nil
+}
+
+// Stop releases the bus name and closes the connection
+func (s *Server) Stop() error {
+	if s.conn == nil {
+		return nil
+	}
+	if s.done != nil {
+		close(s.done)
+	}
+	return s.conn.Close()
+}
+
+// watchThemes publishes every new theme and announces every applied wallpaper until Stop
+func (s *Server) watchThemes() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.themes.Changes():
+			if err := s.publishTheme(s.themes.Current()); err != nil {
+				s.logger.Warn("Failed to publish theme", zap.Error(err))
+			}
+		case applied := <-s.themes.Applied():
+			if err := s.conn.Emit(ObjectPath, Interface+".WallpaperApplied", appliedInfo(applied)); err != nil {
+				s.logger.Warn("Failed to announce wallpaper", zap.Error(err))
+			}
+		}
+	}
+}
+
+// appliedInfo is the payload of the WallpaperApplied signal. Keys are a stable
+// API, new ones may be added. Track keys are left out for private tracks, and
+// empty or unknown values are omitted.
+func appliedInfo(a theme.Applied) map[string]dbus.Variant {
+	info := map[string]dbus.Variant{
+		"wallpaper":  dbus.MakeVariant(a.Wallpaper),
+		"mode":       dbus.MakeVariant(a.Mode),
+		"palette":    dbus.MakeVariant(a.Palette),
+		"private":    dbus.MakeVariant(a.Private),
+		"applied_at": dbus.MakeVariant(a.At.UnixMicro()), // Microseconds since the Unix epoch
+	}
+	if a.Private {
+		return info
+	}
+
+	track := a.Track
+	for key, value := range map[string]string{
+		"player":          track.Player,
+		"player_name":     track.PlayerName,
+		"player_identity": track.PlayerIdentity,
+		"desktop_entry":   track.PlayerDesktopEntry,
+		"track_id":        track.TrackID,
+		"title":           track.Title,
+		"artist":          track.Artist,
+		"album":           track.Album,
+		"album_artist":    track.AlbumArtist,
+		"art_url":         track.ArtUrl,
+		"url":             track.URL,
+		"source":          track.Source,
+	} {
+		if value != "" {
+			info[key] = dbus.MakeVariant(value)
+		}
+	}
+	if artists := track.AllArtists(); len(artists) > 0 {
+		info["artists"] = dbus.MakeVariant(artists)
+	}
+	if len(track.Genres) > 0 {
+		info["genres"] = dbus.MakeVariant(track.Genres)
+	}
+	for key, value := range map[string]int{
+		"year":         track.Year,
+		"disc_number":  track.DiscNumber,
+		"track_number": track.TrackNumber,
+	} {
+		if value > 0 {
+			info[key] = dbus.MakeVariant(int32(value))
+		}
+	}
+	if track.Length > 0 {
+		info["length"] = dbus.MakeVariant(track.Length.Microseconds()) // Like mpris:length
+	}
+	return info
+}
+
+// publishTheme updates the theme properties and announces them in one
+// PropertiesChanged signal, so clients never see a palette of another wallpaper
+func (s *Server) publishTheme(t theme.Theme) error {
+	s.props.SetMust(Interface, "Wallpaper", t.Wallpaper)
+	s.props.SetMust(Interface, "Palette", t.Palette)
+	return s.conn.Emit(ObjectPath, "org.freedesktop.DBus.Properties.PropertiesChanged", Interface,
+		map[string]dbus.Variant{
+			"Wallpaper": dbus.MakeVariant(t.Wallpaper),
+			"Palette":   dbus.MakeVariant(t.Palette),
+		}, []string{})
+}
+
+// object is the exported daemon object. Its methods are called by godbus.
+type object struct {
+	s *Server
+}
+
+// SetMode implements the D-Bus SetMode method
+func (o *object) SetMode(mode string) *dbus.Error {
+	if err := o.s.ctrl.SetMode(mode); err != nil {
+		return dbus.MakeFailedError(err)
+	}
+	o.s.logger.Info("Mode set over D-Bus", zap.String("mode", mode))
+	return nil
+}
+
+// SetPrivate implements the D-Bus SetPrivate method
+func (o *object) SetPrivate(mode string) *dbus.Error {
+	if err := o.s.ctrl.SetPrivate(mode); err != nil {
+		return dbus.MakeFailedError(err)
+	}
+	o.s.logger.Info("Private mode set over D-Bus", zap.String("private", mode))
+	return nil
+}
+
+// ApplyHistory implements the D-Bus ApplyHistory method
+func (o *object) ApplyHistory(id string) *dbus.Error {
+	if err := o.s.ctrl.ApplyHistory(id); err != nil {
+		return dbus.MakeFailedError(err)
+	}
+	o.s.logger.Info("History wallpaper requested over D-Bus", zap.String("id", id))
+	return nil
+}
+
+// ControlPlayer implements the D-Bus ControlPlayer method
+func (o *object) ControlPlayer(command string) *dbus.Error {
+	ctx, cancel := context.WithTimeout(context.Background(), playerTimeout)
+	defer cancel()
+	if err := o.s.players.Control(ctx, command); err != nil {
+		return dbus.MakeFailedError(err)
+	}
+	o.s.logger.Info("Player command sent over D-Bus", zap.String("command", command))
+	return nil
+}
+
+// SetMode asks the running daemon to switch to mode
+func SetMode(ctx context.Context, mode string) error {
+	return call(ctx, "SetMode", mode)
+}
+
+// SetPrivate asks the running daemon to switch private mode
+func SetPrivate(ctx context.Context, mode string) error {
+	return call(ctx, "SetPrivate", mode)
+}
+
+// ApplyHistory asks the running daemon to put a wallpaper from its history back on screen
+func ApplyHistory(ctx context.Context, id string) error {
+	return call(ctx, "ApplyHistory", id)
+}
+
+// ControlPlayer asks the running daemon to send a playback command to the player on screen
+func ControlPlayer(ctx context.Context, command string) error {
+	return call(ctx, "ControlPlayer", command)
+}
+
+// call invokes a method of the running daemon
+func call(ctx context.Context, method string, args ...any) error {
+	conn, err := dbus.ConnectSessionBus(dbus.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("session bus connection failed: %w", err)
+	}
+	defer conn.Close()
+
+	call := conn.Object(BusName, ObjectPath).CallWithContext(ctx, Interface+"."+method, 0, args...)
+	if call.Err != nil {
+		return callError(call.Err)
+	}
+	return nil
+}
+
+// callError turns a failed call into a message fit for the command line
+func callError(err error) error {
+	if dbusErr, ok := err.(dbus.Error); ok && dbusErr.Name == "org.freedesktop.DBus.Error.ServiceUnknown" {
+		return fmt.Errorf("synest is not running")
+	}
+	return err
+}
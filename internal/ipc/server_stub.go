@@ -0,0 +1,57 @@
+//go:build !linux
+// +build !linux
+
+package ipc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// Server stub for platforms without a session bus
+type Server struct {
+	logger *zap.Logger
+}
+
+// NewServer creates a stub server
+func NewServer(logger *zap.Logger, ctrl Controller, players domain.PlayerController, themes ThemeSource) *Server {
+	return &Server{logger: logger}
+}
+
+// Claim does nothing, there is no bus to detect another daemon on
+func (s *Server) Claim(ctx context.Context) error {
+	return nil
+}
+
+// Start returns an error indicating the control interface is not supported on this platform
+func (s *Server) Start(ctx context.Context) error {
+	return fmt.Errorf("the control interface is only supported on Linux systems")
+}
+
+// Stop does nothing
+func (s *Server) Stop() error {
+	return nil
+}
+
+// SetMode returns an error indicating the control interface is not supported on this platform
+func SetMode(ctx context.Context, mode string) error {
+	return fmt.Errorf("the control interface is only supported on Linux systems")
+}
+
+// SetPrivate returns an error indicating the control interface is not supported on this platform
+func SetPrivate(ctx context.Context, mode string) error {
+	return fmt.Errorf("the control interface is only supported on Linux systems")
+}
+
+// ApplyHistory returns an error indicating the control interface is not supported on this platform
+func ApplyHistory(ctx context.Context, id string) error {
+	return fmt.Errorf("the control interface is only supported on Linux systems")
+}
+
+// ControlPlayer returns an error indicating the control interface is not supported on this platform
+func ControlPlayer(ctx context.Context, command string) error {
+	return fmt.Errorf("the control interface is only supported on Linux systems")
+}
@@ -0,0 +1,94 @@
+// Package latest runs background jobs of which only the newest matters, such as
+// mirroring the wallpaper to a slow sink: a job queued while another one waits
+// replaces it, so the sink never falls behind the screen.
+package latest
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Worker runs queued jobs one at a time in a goroutine of its own. At most one
+// job waits while another runs, the latest queued. It is safe for concurrent use.
+type Worker[T any] struct {
+	logger *zap.Logger
+	name   string // Names the jobs in logs, e.g. "upload"
+	run    func(ctx context.Context, job T)
+	jobs   chan T
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{} // Closed once the goroutine returned
+	once   sync.Once
+}
+
+// New starts a worker running each queued job with run until Stop. The ctx
+// passed to run is cancelled by Stop.
+func New[T any](logger *zap.Logger, name string, run func(ctx context.Context, job T)) *Worker[T] {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &Worker[T]{
+		logger: logger,
+		name:   name,
+		run:    run,
+		jobs:   make(chan T, 1),
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go w.loop()
+	return w
+}
+
+// Queue replaces the job waiting to run with job. It never blocks.
+func (w *Worker[T]) Queue(job T) {
+	for {
+		select {
+		case w.jobs <- job:
+			return
+		default:
+		}
+		select {
+		case <-w.jobs:
+			w.logger.Debug("Skipped a queued job, a newer one replaced it", zap.String("job", w.name))
+		default:
+		}
+	}
+}
+
+// Pending removes the job waiting to run and returns it, false when none waits
+func (w *Worker[T]) Pending() (T, bool) {
+	select {
+	case job := <-w.jobs:
+		return job, true
+	default:
+		var zero T
+		return zero, false
+	}
+}
+
+// loop runs the queued jobs until Stop
+func (w *Worker[T]) loop() {
+	defer close(w.done)
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case job := <-w.jobs:
+			w.run(w.ctx, job)
+		}
+	}
+}
+
+// Stop cancels the job in flight, waits for it to return and leaves the queued
+// one waiting. Jobs queued afterwards are never run.
+func (w *Worker[T]) Stop(ctx context.Context) error {
+	w.once.Do(w.cancel)
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
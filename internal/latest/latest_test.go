@@ -0,0 +1,73 @@
+package latest
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// TestWorker_LatestWins verifies jobs queued while one runs are replaced by
+// the newest, which runs once the slow job returned
+func TestWorker_LatestWins(t *testing.T) {
+	started := make(chan int, 4)
+	release := make(chan struct{})
+	ran := make(chan int, 4)
+	w := New(zap.NewNop(), "test", func(ctx context.Context, job int) {
+		started <- job
+		if job == 1 {
+			<-release
+		}
+		ran <- job
+	})
+	defer w.Stop(context.Background())
+
+	w.Queue(1)
+	if job := <-started; job != 1 {
+		t.Fatalf("expected job 1 started, got %d", job)
+	}
+	w.Queue(2)
+	w.Queue(3)
+	close(release)
+
+	var got []int
+	for len(got) < 2 {
+		select {
+		case job := <-ran:
+			got = append(got, job)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out, ran %v", got)
+		}
+	}
+	if !slices.Equal(got, []int{1, 3}) {
+		t.Errorf("expected jobs 1 and 3 to run, got %v", got)
+	}
+}
+
+// TestWorker_Stop verifies Stop cancels the job in flight and leaves later
+// jobs queued without running them
+func TestWorker_Stop(t *testing.T) {
+	started := make(chan struct{})
+	w := New(zap.NewNop(), "test", func(ctx context.Context, job int) {
+		close(started)
+		<-ctx.Done()
+	})
+	w.Queue(1)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := w.Stop(ctx); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	w.Queue(2)
+	if job, ok := w.Pending(); !ok || job != 2 {
+		t.Errorf("expected job 2 pending, got %d (%v)", job, ok)
+	}
+	if _, ok := w.Pending(); ok {
+		t.Error("expected no job pending once taken")
+	}
+}
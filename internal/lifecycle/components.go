@@ -0,0 +1,130 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+// Processor is a domain.Processor rebuilt by a Manager when the settings it
+// reads once change
+type Processor struct {
+	slot *slot[domain.Processor]
+}
+
+// NewProcessor wraps initial, built from the settings identified by key, and
+// registers it with m
+func NewProcessor(m *Manager, initial domain.Processor, key func() string, build func() (domain.Processor, error)) *Processor {
+	p := &Processor{slot: newSlot("processor", initial, key, build)}
+	m.add(p.slot)
+	return p
+}
+
+// Generate implements domain.Processor
+func (p *Processor) Generate(ctx context.Context, imgData []byte, meta domain.MediaMetadata, mode string) (string, error) {
+	proc, done := p.slot.use()
+	defer done()
+	return proc.Generate(ctx, imgData, meta, mode)
+}
+
+// Variations implements domain.Processor
+func (p *Processor) Variations(mode string) int {
+	proc, done := p.slot.use()
+	defer done()
+	return proc.Variations(mode)
+}
+
+// Modes implements domain.Processor
+func (p *Processor) Modes() []string {
+	proc, done := p.slot.use()
+	defer done()
+	return proc.Modes()
+}
+
+// Process implements domain.ImageProcessor when the current processor does
+func (p *Processor) Process(ctx context.Context, imageData []byte) ([]byte, error) {
+	proc, done := p.slot.use()
+	defer done()
+	if img, ok := proc.(domain.ImageProcessor); ok {
+		return img.Process(ctx, imageData)
+	}
+	return nil, errors.New("the processor cannot transform images")
+}
+
+// Palette implements domain.PaletteSource, empty when the current processor has none
+func (p *Processor) Palette() []string {
+	proc, done := p.slot.use()
+	defer done()
+	if src, ok := proc.(domain.PaletteSource); ok {
+		return src.Palette()
+	}
+	return nil
+}
+
+// Resize implements domain.Resizer when the current processor does
+func (p *Processor) Resize(res domain.ScreenResolution) {
+	proc, done := p.slot.use()
+	defer done()
+	if r, ok := proc.(domain.Resizer); ok {
+		r.Resize(res)
+	}
+}
+
+// Executor is a domain.Executor rebuilt by a Manager when the setter settings
+// change. The new setter is reported.
+type Executor struct {
+	slot *slot[domain.Executor]
+}
+
+// NewExecutor wraps initial, built from the settings identified by key, and
+// registers it with m
+func NewExecutor(m *Manager, initial domain.Executor, key func() string, build func() (domain.Executor, error), reporter domain.Reporter) *Executor {
+	e := &Executor{slot: newSlot("wallpaper setter", initial, key, build)}
+	e.slot.swapped = func(exec domain.Executor) {
+		reporter.SetterSelected(exec.Setter())
+	}
+	m.add(e.slot)
+	return e
+}
+
+// Start starts the current setter when it runs in the background
+func (e *Executor) Start() {
+	e.slot.start()
+}
+
+// Stop stops the current setter when it runs in the background
+func (e *Executor) Stop() {
+	e.slot.stop()
+}
+
+// Ready implements domain.Readier for setters depending on a daemon
+func (e *Executor) Ready(ctx context.Context) error {
+	exec, done := e.slot.use()
+	defer done()
+	if r, ok := exec.(domain.Readier); ok {
+		return r.Ready(ctx)
+	}
+	return nil
+}
+
+// SetWallpaper implements domain.Executor
+func (e *Executor) SetWallpaper(ctx context.Context, imagePath string) error {
+	exec, done := e.slot.use()
+	defer done()
+	return exec.SetWallpaper(ctx, imagePath)
+}
+
+// GetCurrentWallpaper implements domain.Executor
+func (e *Executor) GetCurrentWallpaper(ctx context.Context) (string, error) {
+	exec, done := e.slot.use()
+	defer done()
+	return exec.GetCurrentWallpaper(ctx)
+}
+
+// Setter implements domain.Executor
+func (e *Executor) Setter() (name, reason string) {
+	exec, done := e.slot.use()
+	defer done()
+	return exec.Setter()
+}
@@ -0,0 +1,169 @@
+// Package lifecycle rebuilds pipeline components that read some settings only
+// once, when a configuration reload changes those settings. Every changed
+// component is rebuilt first, then all of them are swapped in once the calls in
+// flight on the old ones returned, and the old ones are stopped.
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// starter and stopper are implemented by components running in the
+// background, such as the probe of a degraded executor
+type starter interface{ Start() }
+type stopper interface{ Stop() }
+
+// Manager implements domain.Reloader for the components registered with it.
+// They are swapped together, or none is when one of them cannot be rebuilt,
+// since several components read the same settings.
+type Manager struct {
+	logger *zap.Logger
+
+	mu         sync.Mutex
+	components []component
+}
+
+// NewManager creates a manager without components
+func NewManager(logger *zap.Logger) *Manager {
+	return &Manager{logger: logger}
+}
+
+// add registers a component
+func (m *Manager) add(c component) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.components = append(m.components, c)
+}
+
+// Reload implements domain.Reloader: it rebuilds the components whose settings
+// changed and swaps them in. It must only be called between pipeline runs.
+func (m *Manager) Reload(ctx context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var changes []*staged
+	for _, c := range m.components {
+		next, err := c.stage()
+		if err != nil {
+			m.logger.Warn("Keeping the current pipeline components, rebuilding them with the new settings failed",
+				zap.Error(err))
+			return
+		}
+		if next != nil {
+			changes = append(changes, next)
+		}
+	}
+
+	for _, next := range changes {
+		if c, ok := next.component.(starter); ok {
+			c.Start()
+		}
+	}
+	for _, next := range changes {
+		old := next.swap()
+		if c, ok := old.(stopper); ok {
+			c.Stop()
+		}
+		m.logger.Info("Pipeline component rebuilt with the new settings", zap.String("component", next.name))
+	}
+	for _, next := range changes {
+		next.swapped()
+	}
+}
+
+// component is a slot whose rebuild can be staged
+type component interface {
+	stage() (*staged, error)
+}
+
+// staged is a component rebuilt with the new settings, not in use yet
+type staged struct {
+	name      string
+	component any
+	swap      func() (old any) // Puts the component in use, waiting for calls in flight on the old one
+	swapped   func()           // Runs once every component was swapped
+}
+
+// slot holds the component of type T in use and the settings it was built with
+type slot[T any] struct {
+	name    string
+	key     func() string     // Identifies the settings the component is built from
+	build   func() (T, error) // Constructs a component from the current settings
+	swapped func(T)           // Called with the new component after a swap, may be nil
+
+	mu      sync.RWMutex
+	current T
+	built   string // key of the current component
+}
+
+func newSlot[T any](name string, initial T, key func() string, build func() (T, error)) *slot[T] {
+	return &slot[T]{
+		name:    name,
+		key:     key,
+		build:   build,
+		current: initial,
+		built:   key(),
+	}
+}
+
+// use returns the component in use and the function ending its use. The
+// component is not swapped before every use ended.
+func (s *slot[T]) use() (T, func()) {
+	s.mu.RLock()
+	return s.current, s.mu.RUnlock
+}
+
+// stage rebuilds the component when its settings changed, it returns nil when
+// they did not
+func (s *slot[T]) stage() (*staged, error) {
+	key := s.key()
+	s.mu.RLock()
+	built := s.built
+	s.mu.RUnlock()
+	if key == built {
+		return nil, nil
+	}
+
+	next, err := s.build()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.name, err)
+	}
+	return &staged{
+		name:      s.name,
+		component: next,
+		swap: func() any {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			old := s.current
+			s.current, s.built = next, key
+			return old
+		},
+		swapped: func() {
+			if s.swapped != nil {
+				s.swapped(next)
+			}
+		},
+	}, nil
+}
+
+// start starts the component in use if it runs in the background
+func (s *slot[T]) start() {
+	current, done := s.use()
+	defer done()
+	if c, ok := any(current).(starter); ok {
+		c.Start()
+	}
+}
+
+// stop stops the component in use if it runs in the background
+func (s *slot[T]) stop() {
+	current, done := s.use()
+	defer done()
+	if c, ok := any(current).(stopper); ok {
+		c.Stop()
+	}
+}
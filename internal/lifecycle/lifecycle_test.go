@@ -0,0 +1,128 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// fakeExecutor is a setter recording whether it runs
+type fakeExecutor struct {
+	name    string
+	started bool
+	stopped bool
+}
+
+func (f *fakeExecutor) SetWallpaper(ctx context.Context, imagePath string) error { return nil }
+func (f *fakeExecutor) GetCurrentWallpaper(ctx context.Context) (string, error)  { return "", nil }
+func (f *fakeExecutor) Setter() (string, string)                                 { return f.name, "" }
+func (f *fakeExecutor) Start()                                                   { f.started = true }
+func (f *fakeExecutor) Stop()                                                    { f.stopped = true }
+
+// fakeProcessor is a processor telling which settings it was built with
+type fakeProcessor struct {
+	domain.Processor
+	delivery string
+}
+
+func (f *fakeProcessor) Modes() []string { return []string{f.delivery} }
+
+// fakeReporter records the setters selected
+type fakeReporter struct {
+	domain.Reporter
+	setters []string
+}
+
+func (f *fakeReporter) SetterSelected(name, reason string) {
+	f.setters = append(f.setters, name)
+}
+
+// settings are the values a reload changes
+type settings struct {
+	setter   string
+	delivery string
+	fail     bool // The setter cannot be constructed
+}
+
+func newPipeline(cfg *settings, reporter domain.Reporter) (*Manager, *Processor, *Executor) {
+	m := NewManager(zap.NewNop())
+	buildProc := func() (domain.Processor, error) {
+		return &fakeProcessor{delivery: cfg.delivery}, nil
+	}
+	buildExec := func() (domain.Executor, error) {
+		if cfg.fail {
+			return nil, errors.New("setter not found")
+		}
+		return &fakeExecutor{name: cfg.setter}, nil
+	}
+	initialProc, _ := buildProc()
+	initialExec, _ := buildExec()
+	proc := NewProcessor(m, initialProc, func() string { return cfg.delivery }, buildProc)
+	exec := NewExecutor(m, initialExec, func() string { return cfg.setter + cfg.delivery }, buildExec, reporter)
+	return m, proc, exec
+}
+
+// current returns the setter in use
+func current(exec *Executor) *fakeExecutor {
+	inner, done := exec.slot.use()
+	defer done()
+	return inner.(*fakeExecutor)
+}
+
+// TestReload verifies changed components are swapped in and the old ones
+// stopped, while unchanged settings keep the components in use
+func TestReload(t *testing.T) {
+	cfg := &settings{setter: "swaybg", delivery: "file"}
+	reporter := &fakeReporter{}
+	m, proc, exec := newPipeline(cfg, reporter)
+	exec.Start()
+	old := current(exec)
+
+	m.Reload(context.Background())
+	if current(exec) != old {
+		t.Error("expected the setter to be kept when its settings did not change")
+	}
+
+	cfg.setter = "swww"
+	m.Reload(context.Background())
+	if name, _ := exec.Setter(); name != "swww" {
+		t.Errorf("expected swww in use, got %s", name)
+	}
+	if !old.stopped {
+		t.Error("expected the old setter to be stopped")
+	}
+	if !current(exec).started {
+		t.Error("expected the new setter to be started")
+	}
+	if modes := proc.Modes(); modes[0] != "file" {
+		t.Errorf("expected the processor to be kept, got one built for %s", modes[0])
+	}
+	if len(reporter.setters) != 1 || reporter.setters[0] != "swww" {
+		t.Errorf("expected swww to be reported, got %v", reporter.setters)
+	}
+}
+
+// TestReload_BuildFailure verifies no component is swapped when one of them
+// cannot be rebuilt, and the reload is retried with the next change
+func TestReload_BuildFailure(t *testing.T) {
+	cfg := &settings{setter: "swaybg", delivery: "file"}
+	m, proc, exec := newPipeline(cfg, &fakeReporter{})
+
+	cfg.delivery, cfg.fail = "memfd", true
+	m.Reload(context.Background())
+	if modes := proc.Modes(); modes[0] != "file" {
+		t.Errorf("expected the processor to be kept, got one built for %s", modes[0])
+	}
+	if name, _ := exec.Setter(); name != "swaybg" {
+		t.Errorf("expected swaybg to be kept, got %s", name)
+	}
+
+	cfg.fail = false
+	m.Reload(context.Background())
+	if modes := proc.Modes(); modes[0] != "memfd" {
+		t.Errorf("expected a processor built for memfd, got %s", modes[0])
+	}
+}
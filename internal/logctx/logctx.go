@@ -0,0 +1,35 @@
+// Package logctx carries a request-scoped logger through context.Context, so that
+// every component taking part in a pipeline run logs with the same run fields.
+package logctx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"go.uber.org/zap"
+)
+
+type loggerKey struct{}
+
+// WithLogger returns a copy of ctx carrying logger
+func WithLogger(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// Logger returns the logger carried by ctx, or fallback when there is none
+func Logger(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey{}).(*zap.Logger); ok {
+			return logger
+		}
+	}
+	return fallback
+}
+
+// NewRunID returns a short random identifier for a pipeline run
+func NewRunID() string {
+	var b [4]byte
+	_, _ = rand.Read(b[:]) // crypto/rand.Read never returns an error
+	return hex.EncodeToString(b[:])
+}
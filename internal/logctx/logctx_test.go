@@ -0,0 +1,36 @@
+package logctx
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogger(t *testing.T) {
+	fallback := zap.NewNop()
+	if got := Logger(context.Background(), fallback); got != fallback {
+		t.Error("expected fallback logger for a context without logger")
+	}
+
+	core, logs := observer.New(zap.InfoLevel)
+	runLogger := zap.New(core).With(zap.String("run", "abc"))
+	ctx := WithLogger(context.Background(), runLogger)
+
+	Logger(ctx, fallback).Info("step")
+	entries := logs.All()
+	if len(entries) != 1 || entries[0].ContextMap()["run"] != "abc" {
+		t.Errorf("expected one entry with run field, got %+v", entries)
+	}
+}
+
+func TestNewRunID(t *testing.T) {
+	a, b := NewRunID(), NewRunID()
+	if len(a) != 8 {
+		t.Errorf("expected 8 hex characters, got %q", a)
+	}
+	if a == b {
+		t.Errorf("expected distinct run IDs, got %q twice", a)
+	}
+}
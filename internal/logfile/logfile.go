@@ -0,0 +1,103 @@
+// Package logfile writes log lines to a file rotated by size. Rotated files are
+// kept as <name>.1 (the newest) to <name>.N, older ones are removed.
+package logfile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/genricoloni/synest/internal/paths"
+)
+
+// Writer implements zapcore.WriteSyncer for a rotated log file
+type Writer struct {
+	path    string
+	maxSize int64 // Bytes written before the file is rotated
+	backups int   // Rotated files kept
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// Open opens the log file for appending, creating it and its directory if needed
+func Open(path string, maxSize int64, backups int) (*Writer, error) {
+	if err := paths.Ensure(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	w := &Writer{path: path, maxSize: maxSize, backups: backups}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write appends p, rotating the file first when p would take it past the size limit.
+// A single line larger than the limit still lands in a file of its own.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Sync flushes the file to disk
+func (w *Writer) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Sync()
+}
+
+// open opens the current file, picking up the size of what it already holds
+func (w *Writer) open() error {
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	w.file, w.size = file, info.Size()
+	return nil
+}
+
+// rotate shifts the backups by one, dropping the oldest, and starts a new file
+func (w *Writer) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	if w.backups == 0 {
+		if err := os.Remove(w.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return w.open()
+	}
+
+	for i := w.backups - 1; i >= 1; i-- {
+		if err := os.Rename(w.backup(i), w.backup(i+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.Rename(w.path, w.backup(1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return w.open()
+}
+
+// backup returns the path of the n-th rotated file, 1 being the newest
+func (w *Writer) backup(n int) string {
+	return fmt.Sprintf("%s.%d", w.path, n)
+}
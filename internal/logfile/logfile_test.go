@@ -0,0 +1,69 @@
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestWriter_Rotate verifies the file rotates past the size limit and only the
+// configured number of backups is kept, newest first
+func TestWriter_Rotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "synest.log")
+	w, err := Open(path, 10, 2)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	if err := w.Sync(); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+
+	for file, want := range map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	} {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("expected %s: %v", file, err)
+		}
+		if string(data) != want {
+			t.Errorf("expected %q in %s, got %q", want, file, data)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected the oldest backup to be dropped, got %v", err)
+	}
+}
+
+// TestWriter_Append verifies an existing file counts toward the limit and that
+// without backups the file is simply started over
+func TestWriter_Append(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "synest.log")
+	if err := os.WriteFile(path, []byte("previous\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := Open(path, 12, 0)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	if _, err := w.Write([]byte("next\n")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "next\n" {
+		t.Errorf("expected the file to start over, got %q", data)
+	}
+	if matches, _ := filepath.Glob(path + ".*"); len(matches) != 0 {
+		t.Errorf("expected no backups, got %s", strings.Join(matches, ", "))
+	}
+}
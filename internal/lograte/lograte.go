@@ -0,0 +1,116 @@
+// Package lograte rate-limits repetitive warnings and errors, so a persistent
+// failure (an unreachable artwork host, a broken setter) does not flood journald.
+package lograte
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxKeys bounds the number of tracked messages, expired ones are pruned beyond it
+const maxKeys = 1024
+
+// limiter is shared by a core and every core derived from it with With
+type limiter struct {
+	window time.Duration
+	clock  clock.Clock
+
+	mu      sync.Mutex
+	entries map[string]*occurrence
+}
+
+// occurrence tracks one repeated message within the current window
+type occurrence struct {
+	until      time.Time // End of the window opened by the last logged entry
+	suppressed int       // Entries dropped since then
+}
+
+// core drops warnings and errors that repeat within the window. Entries are
+// considered the same when logger name, level, message and call-site fields
+// match; fields added with With (such as run IDs) are ignored.
+type core struct {
+	zapcore.Core
+	limiter *limiter
+}
+
+// NewCore wraps inner so each distinct warning or error is written at most once
+// per window. The first entry after a quiet window reports how many were dropped.
+func NewCore(inner zapcore.Core, window time.Duration, clk clock.Clock) zapcore.Core {
+	return &core{
+		Core:    inner,
+		limiter: &limiter{window: window, clock: clk, entries: make(map[string]*occurrence)},
+	}
+}
+
+// WrapCore returns a zap option applying NewCore to a logger
+func WrapCore(window time.Duration, clk clock.Clock) zap.Option {
+	return zap.WrapCore(func(inner zapcore.Core) zapcore.Core {
+		return NewCore(inner, window, clk)
+	})
+}
+
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	return &core{Core: c.Core.With(fields), limiter: c.limiter}
+}
+
+func (c *core) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if entry.Level < zapcore.WarnLevel || entry.Level > zapcore.ErrorLevel {
+		return c.Core.Write(entry, fields)
+	}
+
+	suppressed, ok := c.limiter.allow(key(entry, fields))
+	if !ok {
+		return nil
+	}
+	if suppressed > 0 {
+		fields = append(fields[:len(fields):len(fields)], zap.Int("suppressed", suppressed))
+	}
+	return c.Core.Write(entry, fields)
+}
+
+// allow reports whether an entry with the given key may be written, and how many
+// identical entries were dropped since the last one that was
+func (l *limiter) allow(key string) (suppressed int, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	if o, found := l.entries[key]; found && now.Before(o.until) {
+		o.suppressed++
+		return 0, false
+	} else if found {
+		suppressed = o.suppressed
+	}
+
+	if len(l.entries) >= maxKeys {
+		for k, o := range l.entries {
+			if !now.Before(o.until) {
+				delete(l.entries, k)
+			}
+		}
+	}
+	l.entries[key] = &occurrence{until: now.Add(l.window)}
+	return suppressed, true
+}
+
+// key identifies repetitions of the same entry
+func key(entry zapcore.Entry, fields []zapcore.Field) string {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	// fmt prints maps with sorted keys, so the key does not depend on field order
+	return fmt.Sprintf("%s|%s|%s|%v", entry.LoggerName, entry.Level, entry.Message, enc.Fields)
+}
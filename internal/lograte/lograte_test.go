@@ -0,0 +1,43 @@
+package lograte
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestCore(t *testing.T) {
+	inner, logs := observer.New(zap.DebugLevel)
+	clk := clock.NewFake(time.Unix(0, 0))
+	logger := zap.New(NewCore(inner, time.Minute, clk))
+
+	fetchErr := errors.New("GET https://example.com/a.jpg: connection refused")
+	for i := 0; i < 5; i++ {
+		// Run fields differ on every call and must not defeat the limit
+		logger.With(zap.Int("run", i)).Warn("Failed to fetch artwork", zap.Error(fetchErr))
+	}
+	logger.Warn("Failed to fetch artwork", zap.Error(errors.New("other host")))
+	logger.Info("Processing wallpaper")
+	logger.Info("Processing wallpaper")
+
+	if got := logs.FilterMessage("Failed to fetch artwork").Len(); got != 2 {
+		t.Errorf("expected one entry per distinct error, got %d", got)
+	}
+	if got := logs.FilterMessage("Processing wallpaper").Len(); got != 2 {
+		t.Errorf("expected info entries to pass through, got %d", got)
+	}
+
+	// After the window the entry is logged again with the number dropped meanwhile
+	clk.Advance(time.Minute)
+	logger.Warn("Failed to fetch artwork", zap.Error(fetchErr))
+
+	entries := logs.FilterMessage("Failed to fetch artwork").All()
+	last := entries[len(entries)-1]
+	if len(entries) != 3 || last.ContextMap()["suppressed"] != int64(4) {
+		t.Errorf("expected a third entry reporting 4 suppressed, got %+v", last.ContextMap())
+	}
+}
@@ -0,0 +1,158 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// Monitor backends selectable with SYNEST_MONITOR
+const (
+	// BackendAuto picks the first backend of autoBackends
+	BackendAuto = "auto"
+	// BackendMpris listens to MPRIS players on the D-Bus session bus
+	BackendMpris = "mpris"
+	// BackendSMTC follows the current Windows media session, on Windows only
+	BackendSMTC = "smtc"
+	// BackendSpotify polls the playback state of a Spotify account through the
+	// Web API, for playback on other devices or without a session bus
+	BackendSpotify = "spotify"
+	// BackendMPD follows a Music Player Daemon over its protocol
+	BackendMPD = "mpd"
+	// BackendReplay plays back the events recorded to SYNEST_MONITOR_RECORD
+	BackendReplay = "replay"
+)
+
+// backendFunc constructs a monitor backend
+type backendFunc func(logger *zap.Logger, cfg domain.Config, clk clock.Clock) (domain.Monitor, error)
+
+var (
+	// backends maps backend names to their constructors
+	backends = map[string]backendFunc{
+		BackendMpris: func(logger *zap.Logger, cfg domain.Config, clk clock.Clock) (domain.Monitor, error) {
+			return NewMprisMonitor(logger, cfg, clk), nil
+		},
+		BackendSpotify: func(logger *zap.Logger, cfg domain.Config, clk clock.Clock) (domain.Monitor, error) {
+			return NewSpotifyMonitor(logger, cfg, clk)
+		},
+		BackendMPD: func(logger *zap.Logger, cfg domain.Config, clk clock.Clock) (domain.Monitor, error) {
+			return NewMPDMonitor(logger, cfg, clk), nil
+		},
+		BackendReplay: func(logger *zap.Logger, cfg domain.Config, clk clock.Clock) (domain.Monitor, error) {
+			return NewReplayMonitor(logger, cfg, clk)
+		},
+	}
+
+	// backendAliases maps other accepted names to their backend
+	backendAliases = map[string]string{
+		"spotify-api": BackendSpotify,
+	}
+
+	// autoBackends is the preference order of BackendAuto, platforms may replace it
+	autoBackends = []string{BackendMpris}
+)
+
+// NewMonitor constructs the monitor backend selected in the configuration. A
+// comma-separated list runs several backends together in a MultiMonitor. Its
+// events are recorded when a recording file is configured.
+func NewMonitor(logger *zap.Logger, cfg domain.Config, clk clock.Clock) (domain.Monitor, error) {
+	mon, err := newMonitor(logger, cfg, clk)
+	if err != nil || cfg.GetMonitorRecord() == "" {
+		return mon, err
+	}
+	return NewRecorder(logger, cfg, clk, mon)
+}
+
+// newMonitor constructs the backends selected in the configuration
+func newMonitor(logger *zap.Logger, cfg domain.Config, clk clock.Clock) (domain.Monitor, error) {
+	names := strings.Split(cfg.GetMonitorBackend(), ",")
+	if len(names) == 1 {
+		return newBackend(logger, cfg, clk, names[0])
+	}
+
+	sources := make([]Source, 0, len(names))
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = backendName(name)
+		if name == BackendAuto {
+			return nil, fmt.Errorf("monitor backend %q cannot be combined with others", BackendAuto)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("monitor backend %q listed twice", name)
+		}
+		seen[name] = true
+
+		mon, err := newBackend(logger, cfg, clk, name)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, Source{Name: name, Monitor: mon})
+	}
+	return NewMultiMonitor(logger, sources), nil
+}
+
+// newBackend constructs a single supervised backend by name
+func newBackend(logger *zap.Logger, cfg domain.Config, clk clock.Clock, name string) (domain.Monitor, error) {
+	name = backendName(name)
+	if name == BackendAuto {
+		name = autoBackends[0]
+	}
+
+	newBackend, ok := backends[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown monitor backend %q (available: %s)", name, strings.Join(BackendNames(), ", "))
+	}
+
+	logger.Info("Monitor backend selected",
+		zap.String("backend", name),
+		zap.String("configured", cfg.GetMonitorBackend()))
+	mon, err := newBackend(logger, cfg, clk)
+	if err != nil {
+		return nil, err
+	}
+	// A failing backend is replaced by a new one built the same way
+	return NewSupervisor(logger, clk, name, mon, func() (domain.Monitor, error) {
+		return newBackend(logger, cfg, clk)
+	}), nil
+}
+
+// backendName trims a configured backend name and resolves its aliases
+func backendName(name string) string {
+	name = strings.TrimSpace(name)
+	if alias, ok := backendAliases[name]; ok {
+		return alias
+	}
+	return name
+}
+
+// NewPlayerController returns the playback control of the monitor backend,
+// or one that refuses every command when the backend has none
+func NewPlayerController(mon domain.Monitor) domain.PlayerController {
+	if ctrl, ok := mon.(domain.PlayerController); ok {
+		return ctrl
+	}
+	return noPlayerControl{}
+}
+
+// noPlayerControl is the playback control of backends that cannot send commands
+type noPlayerControl struct{}
+
+func (noPlayerControl) Control(ctx context.Context, command string) error {
+	return errors.New("the monitor backend cannot control players")
+}
+
+// BackendNames returns the selectable backend names, sorted
+func BackendNames() []string {
+	names := []string{BackendAuto}
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,50 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"go.uber.org/zap"
+)
+
+func TestNewMonitor(t *testing.T) {
+	tests := []struct {
+		name          string
+		backend       string
+		expectedError string
+	}{
+		{name: "Auto", backend: BackendAuto},
+		{name: "MPRIS", backend: BackendMpris},
+		{name: "Unknown", backend: "winamp", expectedError: `unknown monitor backend "winamp" (available: auto, mpd, mpris, replay, spotify)`},
+		{name: "UnknownInList", backend: "mpris,winamp", expectedError: `unknown monitor backend "winamp"`},
+		{name: "AutoInList", backend: "auto,mpris", expectedError: `"auto" cannot be combined with others`},
+		{name: "Duplicate", backend: "mpris, mpris", expectedError: `"mpris" listed twice`},
+		{name: "SpotifyWithoutAccount", backend: BackendSpotify, expectedError: "needs SYNEST_SPOTIFY_CLIENT_ID"},
+		{name: "SpotifyAPIAlias", backend: "spotify-api", expectedError: "needs SYNEST_SPOTIFY_CLIENT_ID"},
+		{name: "ReplayWithoutRecording", backend: BackendReplay, expectedError: "needs SYNEST_MONITOR_REPLAY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mon, err := NewMonitor(zap.NewNop(), &mockConfig{backend: tt.backend}, clock.New())
+
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing '%s', got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			supervisor, ok := mon.(*Supervisor)
+			if !ok {
+				t.Fatalf("expected a supervised monitor, got %T", mon)
+			}
+			if _, ok := supervisor.Backend().(*MprisMonitor); !ok {
+				t.Errorf("expected an MPRIS monitor, got %T", supervisor.Backend())
+			}
+		})
+	}
+}
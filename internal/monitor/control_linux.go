@@ -0,0 +1,45 @@
+//go:build linux
+// +build linux
+
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// playerMethods maps playback commands to the MPRIS methods carrying them out
+var playerMethods = map[string]string{
+	domain.PlayerPlayPause: "PlayPause",
+	domain.PlayerNext:      "Next",
+	domain.PlayerPrevious:  "Previous",
+}
+
+// Control implements domain.PlayerController: it forwards a playback command
+// to the player whose track is on screen, playing or paused
+func (m *MprisMonitor) Control(ctx context.Context, command string) error {
+	method, ok := playerMethods[command]
+	if !ok {
+		return fmt.Errorf("unknown player command %q", command)
+	}
+
+	m.mu.RLock()
+	player, conn := m.playback.player, m.conn
+	m.mu.RUnlock()
+	if conn == nil {
+		return errors.New("not connected to the session bus")
+	}
+	if player == "" {
+		return errors.New("no player is driving the wallpaper")
+	}
+
+	m.logger.Debug("Forwarding player command", zap.String("player", player), zap.String("method", method))
+	if err := conn.Call(ctx, player, "/org/mpris/MediaPlayer2", "org.mpris.MediaPlayer2.Player."+method); err != nil {
+		return fmt.Errorf("%s failed on %s: %w", method, player, err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+//go:build linux
+// +build linux
+
+package monitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// TestControl verifies playback commands reach the player whose track is on
+// screen, by its well-known name, and are refused without one
+func TestControl(t *testing.T) {
+	client := &controlDBusClient{}
+	mon := NewMprisMonitor(zap.NewNop(), &mockConfig{}, clock.New())
+	mon.conn = client
+	setPlayerNames(mon, map[string]string{":1.1": "org.mpris.MediaPlayer2.spotify"})
+
+	if err := mon.Control(context.Background(), domain.PlayerNext); err == nil {
+		t.Error("expected an error before any player reported a track")
+	}
+
+	mon.emit(":1.1", "org.mpris.MediaPlayer2.spotify", domain.MediaMetadata{Title: "Song", Status: domain.StatusPaused})
+	<-mon.Events()
+	if err := mon.Control(context.Background(), domain.PlayerPlayPause); err != nil {
+		t.Fatalf("control failed: %v", err)
+	}
+	expected := "org.mpris.MediaPlayer2.spotify org.mpris.MediaPlayer2.Player.PlayPause"
+	if len(client.calls) != 1 || client.calls[0] != expected {
+		t.Errorf("expected %q, got %v", expected, client.calls)
+	}
+
+	if err := mon.Control(context.Background(), "shuffle"); err == nil {
+		t.Error("expected unknown commands to be refused")
+	}
+}
+
+// controlDBusClient records the methods called on players
+type controlDBusClient struct {
+	noopDBusClient
+	calls []string
+}
+
+func (c *controlDBusClient) Call(_ context.Context, player, _, method string) error {
+	c.calls = append(c.calls, player+" "+method)
+	return nil
+}
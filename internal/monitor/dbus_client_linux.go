@@ -4,6 +4,8 @@
 package monitor
 
 import (
+	"context"
+
 	"github.com/godbus/dbus/v5"
 )
 
@@ -32,6 +34,10 @@ type DBusClient interface {
 	// path: The object path (e.g., "/org/mpris/MediaPlayer2")
 	// prop: The property name (e.g., "org.mpris.MediaPlayer2.Player.Metadata")
 	GetProperty(player, path, prop string) (dbus.Variant, error)
+
+	// Call invokes a method without arguments on a D-Bus object
+	// method: The full method name (e.g., "org.mpris.MediaPlayer2.Player.Next")
+	Call(ctx context.Context, player, path, method string) error
 }
 
 // StdDBusClient is the real implementation using godbus
@@ -82,3 +88,8 @@ func (c *StdDBusClient) GetProperty(player, path, prop string) (dbus.Variant, er
 	obj := c.conn.Object(player, dbus.ObjectPath(path))
 	return obj.GetProperty(prop)
 }
+
+// Call invokes a method without arguments on a D-Bus object
+func (c *StdDBusClient) Call(ctx context.Context, player, path, method string) error {
+	return c.conn.Object(player, dbus.ObjectPath(path)).CallWithContext(ctx, method, 0).Err
+}
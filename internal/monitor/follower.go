@@ -0,0 +1,201 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/monitor/players"
+	"github.com/genricoloni/synest/internal/monitor/quirks"
+	"github.com/genricoloni/synest/internal/normalize"
+	"github.com/genricoloni/synest/internal/privacy"
+	"go.uber.org/zap"
+)
+
+// follower is shared by the monitors of a single player that read its whole
+// state at once, the Spotify Web API and MPD. It runs the goroutine reading
+// the state, remembers the last reported track and emits its changes.
+type follower struct {
+	logger     *zap.Logger
+	cfg        domain.Config
+	clock      clock.Clock
+	player     string // Player ID and name of the events
+	label      string // Names the monitor in logs
+	events     *eventQueue
+	players    *players.Filter       // Players allowed to drive the wallpaper, by player ID
+	normalizer *normalize.Normalizer // Player-independent text cleanup
+	separator  string                // Joins all artists into MediaMetadata.ArtistDisplay
+
+	mu       sync.RWMutex
+	running  bool
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	last     domain.MediaMetadata // Last emitted track, zero when nothing plays
+	playback playback             // Position of the last reported track
+}
+
+// newFollower creates the shared part of the monitor of player, named label in logs
+func newFollower(logger *zap.Logger, cfg domain.Config, clk clock.Clock, player, label string) *follower {
+	normalizer, err := normalize.New(cfg.GetNormalizeRules())
+	if err != nil {
+		logger.Warn("Invalid normalization rules, using defaults", zap.Error(err))
+		normalizer, _ = normalize.New(nil)
+	}
+
+	filter, err := players.NewFilter(cfg.GetPlayerAllow(), cfg.GetPlayerDeny())
+	if err != nil {
+		logger.Warn("Invalid player allow/deny lists, following all players", zap.Error(err))
+		filter = &players.Filter{}
+	}
+
+	return &follower{
+		logger:     logger,
+		cfg:        cfg,
+		clock:      clk,
+		player:     player,
+		label:      label,
+		events:     newEventQueue(logger),
+		players:    filter,
+		normalizer: normalizer,
+		separator:  cfg.GetArtistSeparator(),
+	}
+}
+
+// start runs run in its own goroutine until ctx is cancelled or stop is called.
+// It returns once the monitor stopped.
+func (f *follower) start(ctx context.Context, run func(context.Context), fields ...zap.Field) error {
+	f.mu.Lock()
+	if f.running {
+		f.mu.Unlock()
+		return nil
+	}
+	f.running = true
+
+	monitorCtx, cancel := context.WithCancel(ctx)
+	f.cancel = cancel
+	f.mu.Unlock()
+
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		run(monitorCtx)
+	}()
+
+	f.logger.Info(f.label+" monitor started", fields...)
+	<-monitorCtx.Done()
+
+	f.logger.Info(f.label + " monitor stopped")
+	return monitorCtx.Err()
+}
+
+// stop cancels the goroutine started by start and closes the events channel
+func (f *follower) stop() error {
+	f.mu.Lock()
+	if !f.running {
+		f.mu.Unlock()
+		return nil
+	}
+	if f.cancel != nil {
+		f.cancel()
+	}
+	f.running = false
+	f.mu.Unlock()
+
+	// The goroutine is the only producer, the channel is closed once it returned
+	f.wg.Wait()
+	f.events.close()
+
+	f.logger.Info(f.label + " monitor shutdown complete")
+	return nil
+}
+
+// Events returns a read-only channel that emits MediaMetadata
+func (f *follower) Events() <-chan domain.MediaMetadata {
+	return f.events.Events()
+}
+
+// EventStats implements domain.EventCounter
+func (f *follower) EventStats() domain.EventStats {
+	return f.events.EventStats()
+}
+
+// Position implements domain.PositionSource from the position of the last read state
+func (f *follower) Position() (position, length time.Duration, ok bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.playback.player == "" {
+		return 0, 0, false
+	}
+	return f.playback.estimate(f.clock.Now()), f.playback.length, true
+}
+
+// playing records the track the player is at, at position, and emits it when
+// it is another track or status than the last one
+func (f *follower) playing(meta domain.MediaMetadata, position time.Duration) {
+	if !f.players.Allowed(f.player) {
+		f.logger.Debug("Ignoring the playback, the player is not followed", zap.String("player", f.player))
+		f.stopped()
+		return
+	}
+
+	meta = f.clean(meta)
+	meta.Position = position
+
+	f.mu.Lock()
+	last := f.last
+	f.playback = playback{
+		player:  f.player,
+		track:   trackKey(meta),
+		offset:  meta.Position,
+		at:      f.clock.Now(),
+		playing: meta.Status == domain.StatusPlaying,
+		length:  meta.Length,
+	}
+	f.last = meta
+	f.mu.Unlock()
+
+	if trackKey(meta) != trackKey(last) || meta.Status != last.Status {
+		f.emit(meta)
+	}
+}
+
+// stopped records nothing plays, and reports the last track as stopped
+func (f *follower) stopped() {
+	f.mu.Lock()
+	last := f.last
+	f.last = domain.MediaMetadata{}
+	f.playback = playback{}
+	f.mu.Unlock()
+
+	if last.Player != "" {
+		// Playback ended, its track is over
+		last.Status = domain.StatusStopped
+		f.emit(last)
+	}
+}
+
+// clean applies the generic text normalization, joins the resulting artists for
+// captions and classifies the media
+func (f *follower) clean(meta domain.MediaMetadata) domain.MediaMetadata {
+	meta = f.normalizer.Apply(meta)
+	meta.ArtistDisplay = strings.Join(meta.AllArtists(), f.separator)
+	meta.Kind = quirks.KindOf(meta)
+	return meta
+}
+
+// emit sends a metadata event to the consumer, replacing the one still waiting
+// while the consumer is busy
+func (f *follower) emit(meta domain.MediaMetadata) {
+	f.events.push(meta)
+	fields := []zap.Field{zap.String("player", meta.PlayerName)}
+	if privacy.ModeFor(f.cfg, meta) == domain.PrivateOff {
+		fields = append(fields,
+			zap.String("title", meta.Title),
+			zap.String("artist", meta.Artist))
+	}
+	f.logger.Info("Media change detected",
+		append(fields, zap.String("status", string(meta.Status)))...)
+}
@@ -0,0 +1,152 @@
+//go:build linux
+// +build linux
+
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	heartbeatTimeout    = 2 * time.Second // Max wait for a player to answer a probe
+	maxMissedHeartbeats = 2               // Unanswered probes before the player is demoted
+)
+
+// heartbeat periodically probes the active player until ctx is cancelled
+func (m *MprisMonitor) heartbeat(ctx context.Context) {
+	defer m.wg.Done()
+
+	timer := m.clock.NewTimer(m.heartbeatInterval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C():
+			m.checkActive()
+			timer.Reset(m.heartbeatInterval)
+		}
+	}
+}
+
+// checkActive probes the active player. A player that crashed without releasing
+// its bus name stops answering: after maxMissedHeartbeats it is demoted and
+// another playing player, if any, takes over.
+func (m *MprisMonitor) checkActive() {
+	m.mu.RLock()
+	active := m.active
+	m.mu.RUnlock()
+	if active == "" {
+		return
+	}
+
+	err := m.ping(active)
+
+	m.mu.Lock()
+	if err == nil {
+		m.missed = 0
+		m.mu.Unlock()
+		return
+	}
+	m.missed++
+	missed := m.missed
+	m.mu.Unlock()
+
+	m.logger.Warn("Active player did not answer heartbeat",
+		zap.String("player", m.getPlayerName(active)),
+		zap.Int("missed", missed),
+		zap.Error(err))
+	if missed < maxMissedHeartbeats {
+		return
+	}
+
+	m.mu.Lock()
+	m.demoted[active] = true
+	m.anyDemoted.Store(true)
+	m.active = ""
+	m.missed = 0
+	m.mu.Unlock()
+
+	m.logger.Warn("Demoting unresponsive player", zap.String("player", m.getPlayerName(active)))
+	m.selectActive()
+}
+
+// ping reads the playback status of a player, giving up after heartbeatTimeout
+func (m *MprisMonitor) ping(busName string) error {
+	errc := make(chan error, 1)
+	go func() {
+		_, err := m.conn.GetProperty(busName, "/org/mpris/MediaPlayer2", "org.mpris.MediaPlayer2.Player.PlaybackStatus")
+		errc <- err
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-time.After(heartbeatTimeout):
+		return fmt.Errorf("no reply within %s", heartbeatTimeout)
+	}
+}
+
+// selectActive emits the metadata of the first other player that is playing, the
+// pinned or best-ranked one first, so the wallpaper follows it instead of staying
+// on the demoted player's track.
+// It reports whether a player took over.
+func (m *MprisMonitor) selectActive() bool {
+	names := m.snapshot().names
+	m.mu.RLock()
+	candidates := make([]string, 0, len(names))
+	for busName, name := range names {
+		if !m.demoted[busName] && !m.demoted[name] {
+			candidates = append(candidates, busName)
+		}
+	}
+	pinned := m.pinnedRunning()
+	m.mu.RUnlock()
+	sort.Strings(candidates)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return m.rank(m.getPlayerName(candidates[i])) < m.rank(m.getPlayerName(candidates[j]))
+	})
+
+	for _, busName := range candidates {
+		meta, err := m.readPlayerMetadata(busName)
+		if err != nil || meta.Status != domain.StatusPlaying {
+			continue
+		}
+		playerName := m.getPlayerName(busName)
+		if pinned && !m.pin.Allowed(playerName) {
+			continue
+		}
+		m.logger.Info("Switching to playing player", zap.String("player", playerName))
+		m.emit(busName, playerName, m.clean(m.quirks.For(playerName), meta))
+		return true
+	}
+
+	m.logger.Info("No other player is playing")
+	return false
+}
+
+// restore clears the demotion of a player that is responding again. It runs
+// for every signal, so the lock is only taken while some player is demoted.
+func (m *MprisMonitor) restore(busName, playerName string) {
+	if !m.anyDemoted.Load() {
+		return
+	}
+
+	m.mu.Lock()
+	demoted := m.demoted[busName] || m.demoted[playerName]
+	delete(m.demoted, busName)
+	delete(m.demoted, playerName)
+	m.anyDemoted.Store(len(m.demoted) > 0)
+	m.mu.Unlock()
+
+	if demoted {
+		m.logger.Info("Demoted player is responding again", zap.String("player", playerName))
+	}
+}
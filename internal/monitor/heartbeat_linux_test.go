@@ -0,0 +1,76 @@
+//go:build linux
+// +build linux
+
+package monitor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/godbus/dbus/v5"
+	"go.uber.org/zap"
+)
+
+// TestCheckActive_DemotesStuckPlayer verifies a player that stops answering is
+// demoted after repeated missed heartbeats and another playing player takes over
+func TestCheckActive_DemotesStuckPlayer(t *testing.T) {
+	client := &heartbeatDBusClient{dead: map[string]bool{":1.1": true}}
+	mon := NewMprisMonitor(zap.NewNop(), &mockConfig{}, clock.New())
+	mon.conn = client
+	setPlayerNames(mon, map[string]string{
+		":1.1": "org.mpris.MediaPlayer2.crashed",
+		":1.2": "org.mpris.MediaPlayer2.vlc",
+	})
+	mon.active = ":1.1"
+
+	mon.checkActive()
+	select {
+	case event := <-mon.Events():
+		t.Fatalf("player demoted after a single missed heartbeat: %+v", event)
+	default:
+	}
+
+	mon.checkActive()
+	select {
+	case event := <-mon.Events():
+		if event.Title != ":1.2" || event.Status != domain.StatusPlaying {
+			t.Errorf("expected the playing track of :1.2, got %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timeout: no event from the remaining player")
+	}
+
+	if mon.active != ":1.2" {
+		t.Errorf("expected :1.2 to become active, got %q", mon.active)
+	}
+	if !mon.demoted[":1.1"] {
+		t.Error("expected :1.1 to be demoted")
+	}
+
+	// A signal from the demoted player means it recovered
+	mon.restore(":1.1", "org.mpris.MediaPlayer2.crashed")
+	if mon.demoted[":1.1"] || mon.anyDemoted.Load() {
+		t.Error("expected :1.1 to be restored")
+	}
+}
+
+// heartbeatDBusClient reports every live player as playing a track named after its bus name
+type heartbeatDBusClient struct {
+	noopDBusClient
+	dead map[string]bool
+}
+
+func (h *heartbeatDBusClient) GetProperty(player, _, property string) (dbus.Variant, error) {
+	if h.dead[player] {
+		return dbus.Variant{}, fmt.Errorf("no reply")
+	}
+	if property == "org.mpris.MediaPlayer2.Player.PlaybackStatus" {
+		return dbus.MakeVariant("Playing"), nil
+	}
+	return dbus.MakeVariant(map[string]dbus.Variant{
+		"xesam:title": dbus.MakeVariant(player),
+	}), nil
+}
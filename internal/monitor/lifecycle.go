@@ -0,0 +1,21 @@
+package monitor
+
+import (
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// lifecycleBuffer bounds the player lifecycle events waiting for the consumer
+const lifecycleBuffer = 16
+
+// sendPlayerEvent hands ev to the consumer of ch without blocking, the event
+// is dropped when the consumer lags
+func sendPlayerEvent(logger *zap.Logger, ch chan<- domain.PlayerEvent, ev domain.PlayerEvent) {
+	select {
+	case ch <- ev:
+	default:
+		logger.Warn("Player lifecycle event dropped, the consumer lags",
+			zap.String("event", ev.Kind),
+			zap.String("player", ev.PlayerName))
+	}
+}
@@ -10,6 +10,7 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
 
 	dbus "github.com/godbus/dbus/v5"
@@ -58,6 +59,20 @@ func (mr *MockDBusClientMockRecorder) AddMatchSignal(options ...any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMatchSignal", reflect.TypeOf((*MockDBusClient)(nil).AddMatchSignal), options...)
 }
 
+// Call mocks base method.
+func (m *MockDBusClient) Call(ctx context.Context, player, path, method string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Call", ctx, player, path, method)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Call indicates an expected call of Call.
+func (mr *MockDBusClientMockRecorder) Call(ctx, player, path, method any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Call", reflect.TypeOf((*MockDBusClient)(nil).Call), ctx, player, path, method)
+}
+
 // Close mocks base method.
 func (m *MockDBusClient) Close() error {
 	m.ctrl.T.Helper()
@@ -5,36 +5,145 @@ package monitor
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/genricoloni/synest/internal/clock"
 	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/monitor/players"
+	"github.com/genricoloni/synest/internal/monitor/quirks"
+	"github.com/genricoloni/synest/internal/normalize"
+	"github.com/genricoloni/synest/internal/privacy"
 	"github.com/godbus/dbus/v5"
 	"go.uber.org/zap"
 )
 
+// noTrack is the track ID MPRIS reserves for "no track"
+const noTrack = "/org/mpris/MediaPlayer2/TrackList/NoTrack"
+
+// beatInterval is how often the signal loop reports it is alive while no
+// signal arrives, well within the stall timeout of the Supervisor
+const beatInterval = stallCheck
+
 // MprisMonitor monitors media playback via D-Bus MPRIS interface
 type MprisMonitor struct {
-	logger          *zap.Logger
-	events          chan domain.MediaMetadata
-	mu              sync.RWMutex
-	running         bool
-	cancel          context.CancelFunc
-	conn            DBusClient        // Interface for testability
-	lastDropWarning time.Time         // Rate limiting for "channel full" warnings
-	wg              sync.WaitGroup    // Tracks active producer goroutines
-	playerNames     map[string]string // Maps unique bus names (:1.45) to well-known names (org.mpris.MediaPlayer2.spotify)
+	logger  *zap.Logger
+	cfg     domain.Config
+	clock   clock.Clock // Drives settle delays, the heartbeat and restart grace
+	events  *eventQueue // Hands the events over without blocking on the consumer
+	mu      sync.RWMutex
+	running bool
+	cancel  context.CancelFunc
+	conn    DBusClient                  // Interface for testability
+	wg      sync.WaitGroup              // Tracks active producer goroutines
+	table   atomic.Pointer[playerTable] // Names, identities and settings of the players, read without m.mu
+
+	quirks     *quirks.Registry        // Player-specific metadata workarounds
+	players    *players.Filter         // Players allowed to drive the wallpaper
+	only       string                  // Bus name of the only player subscribed to, empty for every player
+	policy     string                  // How the driving player is chosen among playing ones
+	priority   *players.Priority       // Player ranks for the priority policy
+	pin        *players.Filter         // Player driving the wallpaper alone while it runs, nil when none
+	states     map[string]playerState  // Last reported state of each player, by bus name
+	normalizer *normalize.Normalizer   // Player-independent text cleanup
+	separator  string                  // Joins all artists into MediaMetadata.ArtistDisplay
+	settleGen  map[string]uint64       // Latest pending settle per sender, older ones are dropped
+	done       chan struct{}           // Closed on Stop to cancel pending settles
+	failed     chan error              // Receives the error ending the signal loop early
+	lifecycle  chan domain.PlayerEvent // Players appearing and vanishing, dropped while the consumer lags
+	lastBeat   atomic.Int64            // Unix nanoseconds of the last pass of the signal loop, 0 before it runs
+
+	heartbeatInterval time.Duration   // How often the active player is probed, 0 disables
+	active            string          // Bus name of the player that last reported playing
+	missed            int             // Consecutive heartbeats the active player did not answer
+	demoted           map[string]bool // Unresponsive players, until they send a signal again
+	anyDemoted        atomic.Bool     // Whether demoted may be non-empty, read without m.mu
+
+	restartGrace time.Duration // How long a vanished active player may take to reappear
+	vanished     string        // Well-known name of the active player awaiting its restart
+	graceGen     uint64        // Latest pending restart grace, older ones are dropped
+
+	positionInterval time.Duration // How often the position of the playing player is polled, 0 disables
+	playback         playback      // Position of the last reported track
 }
 
 // NewMprisMonitor creates a new MPRIS monitor instance
-func NewMprisMonitor(logger *zap.Logger) *MprisMonitor {
-	return &MprisMonitor{
-		logger:      logger,
-		events:      make(chan domain.MediaMetadata, 10),
-		playerNames: make(map[string]string),
+func NewMprisMonitor(logger *zap.Logger, cfg domain.Config, clk clock.Clock) *MprisMonitor {
+	registry, err := quirks.NewRegistry(cfg.GetPlayerQuirks(), cfg.GetArtSettleDelays())
+	if err != nil {
+		logger.Warn("Invalid player quirks configuration, using defaults", zap.Error(err))
+		registry, _ = quirks.NewRegistry(nil, cfg.GetArtSettleDelays())
+	}
+
+	normalizer, err := normalize.New(cfg.GetNormalizeRules())
+	if err != nil {
+		logger.Warn("Invalid normalization rules, using defaults", zap.Error(err))
+		normalizer, _ = normalize.New(nil)
+	}
+
+	filter, err := players.NewFilter(cfg.GetPlayerAllow(), cfg.GetPlayerDeny())
+	if err != nil {
+		// The config rejects invalid patterns, so this only guards custom configs
+		logger.Warn("Invalid player allow/deny lists, following all players", zap.Error(err))
+		filter = &players.Filter{}
+	}
+
+	priority, err := players.NewPriority(cfg.GetPlayerPriority())
+	if err != nil {
+		logger.Warn("Invalid player priority, ranking all players equally", zap.Error(err))
+		priority = &players.Priority{}
+	}
+	var pin *players.Filter
+	if pattern := cfg.GetPlayerPin(); pattern != "" {
+		if pin, err = players.NewFilter([]string{pattern}, nil); err != nil {
+			logger.Warn("Invalid pinned player, following the policy", zap.Error(err))
+			pin = nil
+		}
+	}
+
+	m := &MprisMonitor{
+		logger:     logger,
+		cfg:        cfg,
+		clock:      clk,
+		events:     newEventQueue(logger),
+		quirks:     registry,
+		players:    filter,
+		only:       cfg.GetMonitorPlayer(),
+		policy:     cfg.GetPlayerPolicy(),
+		priority:   priority,
+		pin:        pin,
+		states:     make(map[string]playerState),
+		normalizer: normalizer,
+		separator:  cfg.GetArtistSeparator(),
+		settleGen:  make(map[string]uint64),
+		done:       make(chan struct{}),
+		failed:     make(chan error, 1),
+		lifecycle:  make(chan domain.PlayerEvent, lifecycleBuffer),
+
+		heartbeatInterval: cfg.GetHeartbeatInterval(),
+		demoted:           make(map[string]bool),
+
+		restartGrace: cfg.GetRestartGrace(),
+
+		positionInterval: cfg.GetPositionInterval(),
 	}
+	m.table.Store(newPlayerTable())
+	return m
+}
+
+// Ready implements domain.Readier: it succeeds once the session bus accepts connections
+func (m *MprisMonitor) Ready(ctx context.Context) error {
+	conn, err := dbus.ConnectSessionBus(dbus.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("session bus connection failed: %w", err)
+	}
+	return conn.Close()
 }
 
 // Start begins monitoring for media events
@@ -92,11 +201,14 @@ func (m *MprisMonitor) Start(ctx context.Context) error {
 
 	// Add match rule for PropertiesChanged signals on MPRIS interface
 	matchRule := "type='signal',interface='org.freedesktop.DBus.Properties',member='PropertiesChanged',path='/org/mpris/MediaPlayer2'"
-	if err := conn.AddMatchSignal(
+	if m.only != "" {
+		matchRule += ",sender='" + m.only + "'"
+	}
+	if err := conn.AddMatchSignal(m.matchOptions(
 		dbus.WithMatchObjectPath("/org/mpris/MediaPlayer2"),
 		dbus.WithMatchInterface("org.freedesktop.DBus.Properties"),
 		dbus.WithMatchMember("PropertiesChanged"),
-	); err != nil {
+	)...); err != nil {
 		m.logger.Error("Failed to add match signal", zap.Error(err))
 		return fmt.Errorf("failed to add match signal: %w", err)
 	}
@@ -104,22 +216,53 @@ func (m *MprisMonitor) Start(ctx context.Context) error {
 	m.logger.Info("D-Bus match rule added", zap.String("rule", matchRule))
 
 	// Add match rule for NameOwnerChanged to track new/removed players dynamically
-	if err := conn.AddMatchSignal(
+	nameOwnerChanged := []dbus.MatchOption{
 		dbus.WithMatchInterface("org.freedesktop.DBus"),
 		dbus.WithMatchMember("NameOwnerChanged"),
-	); err != nil {
+	}
+	if m.only != "" {
+		nameOwnerChanged = append(nameOwnerChanged, dbus.WithMatchArg(0, m.only))
+	}
+	if err := conn.AddMatchSignal(nameOwnerChanged...); err != nil {
 		m.logger.Warn("Failed to add NameOwnerChanged match signal", zap.Error(err))
 		// Non-fatal, continue without dynamic tracking
 	} else {
 		m.logger.Info("Dynamic player tracking enabled via NameOwnerChanged")
 	}
 
+	// Players signal jumps in the playback position, the regular progress is estimated
+	if err := conn.AddMatchSignal(m.matchOptions(
+		dbus.WithMatchObjectPath("/org/mpris/MediaPlayer2"),
+		dbus.WithMatchInterface("org.mpris.MediaPlayer2.Player"),
+		dbus.WithMatchMember("Seeked"),
+	)...); err != nil {
+		m.logger.Warn("Failed to add Seeked match signal", zap.Error(err))
+		// Non-fatal, the position polls still correct the estimate
+	}
+
 	// Start signal monitoring goroutine
 	m.wg.Add(1)
 	go m.monitorSignals(monitorCtx)
 
-	// Block until context is cancelled
-	<-monitorCtx.Done()
+	// Crashed players may keep their bus name, probe the active one periodically
+	if m.heartbeatInterval > 0 {
+		m.wg.Add(1)
+		go m.heartbeat(monitorCtx)
+	}
+
+	if m.positionInterval > 0 {
+		m.wg.Add(1)
+		go m.pollPosition(monitorCtx)
+	}
+
+	// Block until context is cancelled or the signal loop fails
+	select {
+	case <-monitorCtx.Done():
+	case err := <-m.failed:
+		cancel()
+		m.logger.Error("MPRIS monitor failed", zap.Error(err))
+		return err
+	}
 
 	m.logger.Info("MPRIS monitor stopped")
 	return monitorCtx.Err()
@@ -139,6 +282,7 @@ func (m *MprisMonitor) Stop(ctx context.Context) error {
 	}
 
 	m.running = false
+	close(m.done)
 	m.mu.Unlock()
 
 	// Wait for all producer goroutines to terminate before closing channel
@@ -147,7 +291,7 @@ func (m *MprisMonitor) Stop(ctx context.Context) error {
 	m.wg.Wait()
 
 	// Now safe to close the channel
-	close(m.events)
+	m.events.close()
 
 	// Close D-Bus connection
 	m.mu.Lock()
@@ -164,7 +308,12 @@ func (m *MprisMonitor) Stop(ctx context.Context) error {
 
 // Events returns a read-only channel that emits MediaMetadata
 func (m *MprisMonitor) Events() <-chan domain.MediaMetadata {
-	return m.events
+	return m.events.Events()
+}
+
+// EventStats implements domain.EventCounter
+func (m *MprisMonitor) EventStats() domain.EventStats {
+	return m.events.EventStats()
 }
 
 // detectExistingPlayers queries D-Bus for currently running MPRIS players
@@ -178,6 +327,10 @@ func (m *MprisMonitor) detectExistingPlayers() error {
 	playerCount := 0
 	for _, name := range names {
 		if strings.HasPrefix(name, "org.mpris.MediaPlayer2.") {
+			if !m.subscribed(name) {
+				m.logger.Debug("Ignoring filtered MPRIS player", zap.String("name", name))
+				continue
+			}
 			playerCount++
 			m.logger.Info("Detected MPRIS player", zap.String("name", name))
 
@@ -185,14 +338,16 @@ func (m *MprisMonitor) detectExistingPlayers() error {
 			uniqueName, err := m.conn.GetNameOwner(name)
 			if err == nil {
 				m.mu.Lock()
-				m.playerNames[uniqueName] = name
+				count := len(m.updateTable(func(t *playerTable) { t.names[uniqueName] = name }).names)
 				m.mu.Unlock()
 				m.logger.Debug("Mapped player name",
 					zap.String("unique", uniqueName),
 					zap.String("wellKnown", name))
+				m.announce(domain.PlayerAppeared, name, count)
 			}
 
 			// Fetch initial metadata for this player
+			m.identify(name)
 			if err := m.fetchPlayerMetadata(name); err != nil {
 				m.logger.Warn("Failed to fetch initial metadata",
 					zap.String("player", name),
@@ -232,23 +387,39 @@ func (m *MprisMonitor) fetchPlayerMetadata(playerName string) error {
 	}
 
 	// Parse metadata into domain model
-	mediaMeta := m.parseMetadata(metadata, status)
+	mediaMeta := m.clean(m.quirks.For(playerName), m.parseMetadata(metadata, status))
+	mediaMeta.Player = quirks.PlayerID(playerName)
+	mediaMeta.PlayerName = playerName
 
-	// Emit event (non-blocking)
-	// NOTE: For wallpaper generation, dropping intermediate events during rapid
-	// track changes is acceptable and acts as implicit debouncing. The consumer
-	// should implement proper debouncing to avoid unnecessary wallpaper regeneration.
-	select {
-	case m.events <- mediaMeta:
-		m.logger.Debug("Emitted initial metadata", zap.String("title", mediaMeta.Title))
-	default:
-		m.logChannelFullWarning()
+	if !m.admit(playerName, playerName, &mediaMeta) {
+		m.logIgnored(playerName)
+		return nil
 	}
+	m.describe(playerName, &mediaMeta)
+
+	// Emit event, never blocking on a busy consumer
+	m.events.push(mediaMeta)
+	m.logger.Debug("Emitted initial metadata", zap.String("title", mediaMeta.Title))
 
 	return nil
 }
 
-// monitorSignals listens for D-Bus signals and processes them
+// LastBeat returns when the signal loop last handled a signal or reported it
+// is alive, so a Supervisor restarts the monitor when the loop is stuck
+func (m *MprisMonitor) LastBeat() time.Time {
+	if nanos := m.lastBeat.Load(); nanos != 0 {
+		return time.Unix(0, nanos)
+	}
+	return time.Time{}
+}
+
+// beat records that the signal loop is alive
+func (m *MprisMonitor) beat() {
+	m.lastBeat.Store(m.clock.Now().UnixNano())
+}
+
+// monitorSignals listens for D-Bus signals and processes them. It ends early,
+// failing Start, when the connection to the bus is lost.
 func (m *MprisMonitor) monitorSignals(ctx context.Context) {
 	defer m.wg.Done() // Signal completion when goroutine exits
 
@@ -257,25 +428,59 @@ func (m *MprisMonitor) monitorSignals(ctx context.Context) {
 
 	m.logger.Info("Signal monitoring goroutine started")
 
+	beat := m.clock.NewTimer(beatInterval)
+	defer beat.Stop()
+	m.beat()
+
 	for {
 		select {
 		case <-ctx.Done():
 			m.logger.Info("Signal monitoring goroutine stopped")
 			return
-		case sig := <-signals:
+		case <-beat.C():
+			m.beat()
+			beat.Reset(beatInterval)
+		case sig, ok := <-signals:
+			if !ok {
+				// The connection closes the channel once the bus is gone
+				select {
+				case m.failed <- errors.New("D-Bus connection lost"):
+				default:
+				}
+				return
+			}
+			m.beat()
 			if sig == nil {
 				continue
 			}
 			// Handle different signal types
-			if sig.Name == "org.freedesktop.DBus.NameOwnerChanged" {
+			switch sig.Name {
+			case "org.freedesktop.DBus.NameOwnerChanged":
 				m.handleNameOwnerChanged(sig)
-			} else {
+			case "org.mpris.MediaPlayer2.Player.Seeked":
+				m.handleSeeked(sig)
+			default:
 				m.handleSignal(sig)
 			}
 		}
 	}
 }
 
+// matchOptions restricts the match rule of a player signal to the only player
+// subscribed to, if any. The bus delivers the signals of that player alone.
+func (m *MprisMonitor) matchOptions(options ...dbus.MatchOption) []dbus.MatchOption {
+	if m.only == "" {
+		return options
+	}
+	return append(options, dbus.WithMatchSender(m.only))
+}
+
+// subscribed reports whether events of the player with a well-known name are
+// handled: it must be the player subscribed to, if any, and pass the filter
+func (m *MprisMonitor) subscribed(name string) bool {
+	return (m.only == "" || name == m.only) && m.players.Allowed(name)
+}
+
 // handleNameOwnerChanged processes NameOwnerChanged signals to track player lifecycle
 func (m *MprisMonitor) handleNameOwnerChanged(sig *dbus.Signal) {
 	if len(sig.Body) < 3 {
@@ -290,17 +495,31 @@ func (m *MprisMonitor) handleNameOwnerChanged(sig *dbus.Signal) {
 	oldOwner, _ := sig.Body[1].(string)
 	newOwner, _ := sig.Body[2].(string)
 
+	if !m.subscribed(name) {
+		m.logger.Debug("Ignoring filtered MPRIS player", zap.String("player", name))
+		return
+	}
+
 	if newOwner != "" && oldOwner == "" {
 		// New player appeared
 		m.mu.Lock()
-		m.playerNames[newOwner] = name
+		count := len(m.updateTable(func(t *playerTable) { t.names[newOwner] = name }).names)
+		restarted := m.vanished == name
+		if restarted {
+			// Cancel the pending stop, the re-emitted track is deduplicated downstream
+			m.vanished = ""
+			m.graceGen++
+		}
 		m.mu.Unlock()
+		m.announce(domain.PlayerAppeared, name, count)
 
 		m.logger.Info("New MPRIS player detected",
 			zap.String("player", name),
-			zap.String("unique", newOwner))
+			zap.String("unique", newOwner),
+			zap.Bool("restarted", restarted))
 
 		// Fetch initial metadata for the new player
+		m.identify(name)
 		if err := m.fetchPlayerMetadata(name); err != nil {
 			m.logger.Warn("Failed to fetch metadata from new player",
 				zap.String("player", name),
@@ -309,18 +528,36 @@ func (m *MprisMonitor) handleNameOwnerChanged(sig *dbus.Signal) {
 	} else if newOwner == "" && oldOwner != "" {
 		// Player disappeared
 		m.mu.Lock()
-		delete(m.playerNames, oldOwner)
+		count := len(m.updateTable(func(t *playerTable) {
+			delete(t.names, oldOwner)
+			delete(t.identities, name)
+			delete(t.options, name)
+		}).names)
+		delete(m.demoted, oldOwner)
+		delete(m.states, oldOwner)
+		delete(m.states, name)
+		wasActive := m.active == oldOwner || m.active == name
+		if wasActive {
+			m.active = ""
+		}
 		m.mu.Unlock()
 
 		m.logger.Info("MPRIS player removed",
 			zap.String("player", name),
 			zap.String("unique", oldOwner))
+		m.announce(domain.PlayerVanished, name, count)
+
+		if wasActive {
+			m.awaitRestart(name)
+		}
 	}
 	// If both oldOwner and newOwner are set, it's a transfer (rare), we update the mapping
 	if newOwner != "" && oldOwner != "" {
 		m.mu.Lock()
-		delete(m.playerNames, oldOwner)
-		m.playerNames[newOwner] = name
+		m.updateTable(func(t *playerTable) {
+			delete(t.names, oldOwner)
+			t.names[newOwner] = name
+		})
 		m.mu.Unlock()
 
 		m.logger.Debug("MPRIS player ownership changed",
@@ -330,6 +567,71 @@ func (m *MprisMonitor) handleNameOwnerChanged(sig *dbus.Signal) {
 	}
 }
 
+// announce sends a player lifecycle event, count being the players left running
+func (m *MprisMonitor) announce(kind, name string, count int) {
+	sendPlayerEvent(m.logger, m.lifecycle, domain.PlayerEvent{Kind: kind, PlayerName: name, Players: count})
+}
+
+// PlayerEvents implements domain.PlayerEventSource
+func (m *MprisMonitor) PlayerEvents() <-chan domain.PlayerEvent {
+	return m.lifecycle
+}
+
+// awaitRestart gives a vanished active player restartGrace to reappear under the
+// same name before playback counts as stopped, so a crash and restart does not
+// flicker the wallpaper
+func (m *MprisMonitor) awaitRestart(name string) {
+	m.mu.Lock()
+	m.vanished = name
+	m.graceGen++
+	gen := m.graceGen
+	m.mu.Unlock()
+
+	if m.restartGrace <= 0 {
+		m.stopIfGone(name, gen)
+		return
+	}
+
+	m.logger.Debug("Waiting for vanished player to restart",
+		zap.String("player", name),
+		zap.Duration("grace", m.restartGrace))
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		timer := m.clock.NewTimer(m.restartGrace)
+		defer timer.Stop()
+
+		select {
+		case <-m.done:
+			return
+		case <-timer.C():
+		}
+
+		m.stopIfGone(name, gen)
+	}()
+}
+
+// stopIfGone hands over to another playing player, or reports playback as stopped,
+// unless the vanished player came back or another player took over meanwhile
+func (m *MprisMonitor) stopIfGone(name string, gen uint64) {
+	m.mu.Lock()
+	if m.graceGen != gen || m.active != "" {
+		m.mu.Unlock()
+		return
+	}
+	m.vanished = ""
+	m.mu.Unlock()
+
+	if m.selectActive() {
+		return
+	}
+
+	m.logger.Info("Player did not come back, playback stopped", zap.String("player", name))
+	m.emit("", name, domain.MediaMetadata{Status: domain.StatusStopped})
+}
+
 // handleSignal processes a D-Bus signal
 func (m *MprisMonitor) handleSignal(sig *dbus.Signal) {
 	// PropertiesChanged signal has 3 arguments:
@@ -358,12 +660,22 @@ func (m *MprisMonitor) handleSignal(sig *dbus.Signal) {
 	// Resolve player name from unique bus name for better logging and future
 	// player-specific logic (e.g., priority-based selection)
 	playerName := m.getPlayerName(sig.Sender)
+	if !m.subscribed(playerName) {
+		return
+	}
 
 	m.logger.Debug("Received PropertiesChanged signal",
 		zap.String("sender", sig.Sender),
 		zap.String("player", playerName),
 		zap.Int("properties", len(changedProps)))
 
+	// A signal proves a demoted player is alive again
+	m.restore(sig.Sender, playerName)
+
+	// Volume, shuffle and loop changes alone do not change the wallpaper, they
+	// go out with the next event
+	m.updateOptions(playerName, changedProps)
+
 	// Check if Metadata or PlaybackStatus changed
 	metadataVariant, hasMetadata := changedProps["Metadata"]
 	statusVariant, hasStatus := changedProps["PlaybackStatus"]
@@ -412,23 +724,177 @@ func (m *MprisMonitor) handleSignal(sig *dbus.Signal) {
 		}
 	}
 
-	// Parse and emit
-	mediaMeta := m.parseMetadata(metadata, status)
+	// Parse, apply player quirks and emit
+	adapter := m.quirks.For(playerName)
+	mediaMeta := m.clean(adapter, m.parseMetadata(metadata, status))
 
-	// Non-blocking send: Prevents monitor from blocking on slow consumers.
-	// The consumer (engine/processor) should implement debouncing to handle
-	// rapid track changes gracefully (e.g., only process the last event within
-	// a time window). Dropping intermediate events here is intentional.
-	select {
-	case m.events <- mediaMeta:
-		m.logger.Info("Media change detected",
-			zap.String("player", playerName),
+	// Players with a settle quirk may still report the previous track's artwork,
+	// wait for it to settle and re-read the metadata before emitting
+	if hasMetadata && adapter.SettleDelay > 0 {
+		m.settle(sig.Sender, playerName, mediaMeta, adapter.SettleDelay)
+		return
+	}
+
+	m.emit(sig.Sender, playerName, mediaMeta)
+}
+
+// clean applies the player's quirks adapter, then the generic text normalization,
+// joins the resulting artists for captions and names the source and kind of the media
+func (m *MprisMonitor) clean(adapter quirks.Adapter, meta domain.MediaMetadata) domain.MediaMetadata {
+	meta = m.normalizer.Apply(adapter.Apply(meta))
+	meta.ArtistDisplay = strings.Join(meta.AllArtists(), m.separator)
+	meta.Source = quirks.SourceOf(meta.URL)
+	meta.Kind = quirks.KindOf(meta)
+	return meta
+}
+
+// emit sends a metadata event from the player on the given bus name to the consumer
+func (m *MprisMonitor) emit(busName, playerName string, mediaMeta domain.MediaMetadata) {
+	mediaMeta.Player = quirks.PlayerID(playerName)
+	if strings.HasPrefix(playerName, "org.mpris.MediaPlayer2.") {
+		mediaMeta.PlayerName = playerName // Unmapped senders only have a unique name
+	}
+
+	if !m.admit(busName, playerName, &mediaMeta) {
+		m.logIgnored(playerName)
+		return
+	}
+	m.describe(playerName, &mediaMeta)
+
+	// Never blocks on a slow consumer: while it is busy, only the latest event
+	// of each player waits for it, the consumer still debounces rapid changes
+	m.events.push(mediaMeta)
+	fields := []zap.Field{zap.String("player", playerName)}
+	if privacy.ModeFor(m.cfg, mediaMeta) == domain.PrivateOff {
+		fields = append(fields,
 			zap.String("title", mediaMeta.Title),
-			zap.String("artist", mediaMeta.Artist),
-			zap.String("status", string(mediaMeta.Status)))
-	default:
-		m.logChannelFullWarning()
+			zap.String("artist", mediaMeta.Artist))
+	}
+	m.logger.Info("Media change detected",
+		append(fields, zap.String("status", string(mediaMeta.Status)))...)
+}
+
+// settle waits for delay, then re-fetches the metadata from the player and emits it.
+// A newer change from the same sender supersedes a pending settle. If the re-fetch
+// fails, the metadata from the original signal is emitted instead.
+func (m *MprisMonitor) settle(sender, playerName string, fallback domain.MediaMetadata, delay time.Duration) {
+	m.mu.Lock()
+	m.settleGen[sender]++
+	gen := m.settleGen[sender]
+	m.mu.Unlock()
+
+	m.logger.Debug("Delaying metadata until player settles",
+		zap.String("player", playerName),
+		zap.Duration("delay", delay))
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		timer := m.clock.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-m.done:
+			return
+		case <-timer.C():
+		}
+
+		m.mu.RLock()
+		current := m.settleGen[sender] == gen
+		m.mu.RUnlock()
+		if !current {
+			return // A newer change is settling
+		}
+
+		mediaMeta := fallback
+		if refreshed, err := m.readPlayerMetadata(sender); err != nil {
+			m.logger.Debug("Failed to re-fetch settled metadata, using original",
+				zap.String("player", playerName),
+				zap.Error(err))
+		} else {
+			mediaMeta = m.clean(m.quirks.For(playerName), refreshed)
+		}
+
+		m.emit(sender, playerName, mediaMeta)
+	}()
+}
+
+// playerIdentity is how a player presents itself on the org.mpris.MediaPlayer2
+// interface
+type playerIdentity struct {
+	name         string // Identity, e.g. "Spotify"
+	desktopEntry string // DesktopEntry, e.g. "spotify" for spotify.desktop
+}
+
+// identify reads the friendly name, desktop entry and playback settings of a
+// player that appeared on the bus, kept until it leaves
+func (m *MprisMonitor) identify(playerName string) {
+	id := m.readIdentity(playerName)
+	opts := m.readOptions(playerName)
+	m.mu.Lock()
+	m.updateTable(func(t *playerTable) {
+		t.identities[playerName] = id
+		t.options[playerName] = opts
+	})
+	m.mu.Unlock()
+}
+
+// describe fills the friendly name, desktop entry and playback settings of the
+// player of an event
+func (m *MprisMonitor) describe(playerName string, meta *domain.MediaMetadata) {
+	t := m.snapshot()
+	id, opts := t.identities[playerName], t.options[playerName]
+	meta.PlayerIdentity, meta.PlayerDesktopEntry = id.name, id.desktopEntry
+	meta.Volume, meta.Shuffle, meta.Loop = opts.volume, opts.shuffle, opts.loop
+}
+
+// readIdentity queries the optional Identity and DesktopEntry properties of a
+// player, players without them get an empty identity
+func (m *MprisMonitor) readIdentity(busName string) playerIdentity {
+	read := func(property string) string {
+		variant, err := m.conn.GetProperty(busName, "/org/mpris/MediaPlayer2", "org.mpris.MediaPlayer2."+property)
+		if err != nil {
+			m.logger.Debug("Player property unavailable",
+				zap.String("player", busName),
+				zap.String("property", property),
+				zap.Error(err))
+			return ""
+		}
+		value, _ := variant.Value().(string)
+		return sanitizeText(value)
+	}
+
+	id := playerIdentity{name: read("Identity")}
+	// The spec asks for the basename without .desktop, some players send it anyway
+	entry := strings.TrimSuffix(read("DesktopEntry"), ".desktop")
+	if !strings.ContainsAny(entry, `/\`) {
+		id.desktopEntry = entry
 	}
+	return id
+}
+
+// readPlayerMetadata queries the current metadata and playback status of a player
+func (m *MprisMonitor) readPlayerMetadata(busName string) (domain.MediaMetadata, error) {
+	variant, err := m.conn.GetProperty(busName, "/org/mpris/MediaPlayer2", "org.mpris.MediaPlayer2.Player.Metadata")
+	if err != nil {
+		return domain.MediaMetadata{}, fmt.Errorf("failed to get metadata: %w", err)
+	}
+	metadata, ok := variant.Value().(map[string]dbus.Variant)
+	if !ok {
+		return domain.MediaMetadata{}, fmt.Errorf("invalid metadata format")
+	}
+
+	statusVariant, err := m.conn.GetProperty(busName, "/org/mpris/MediaPlayer2", "org.mpris.MediaPlayer2.Player.PlaybackStatus")
+	if err != nil {
+		return domain.MediaMetadata{}, fmt.Errorf("failed to get playback status: %w", err)
+	}
+	status, ok := statusVariant.Value().(string)
+	if !ok {
+		return domain.MediaMetadata{}, fmt.Errorf("invalid playback status format")
+	}
+
+	return m.parseMetadata(metadata, status), nil
 }
 
 // parseMetadata converts MPRIS metadata to domain model
@@ -454,7 +920,7 @@ func (m *MprisMonitor) parseMetadata(metadata map[string]dbus.Variant, status st
 	// Extract title
 	if titleVar, ok := metadata["xesam:title"]; ok {
 		if title, ok := titleVar.Value().(string); ok {
-			meta.Title = title
+			meta.Title = sanitizeText(title)
 		}
 	}
 
@@ -462,11 +928,17 @@ func (m *MprisMonitor) parseMetadata(metadata map[string]dbus.Variant, status st
 	if artistVar, ok := metadata["xesam:artist"]; ok {
 		switch artists := artistVar.Value().(type) {
 		case []string:
-			if len(artists) > 0 {
-				meta.Artist = artists[0]
+			// Some players pad the array with empty entries, keep only real names
+			for _, artist := range artists {
+				if artist = sanitizeText(artist); artist != "" {
+					meta.Artists = append(meta.Artists, artist)
+				}
+			}
+			if len(meta.Artists) > 0 {
+				meta.Artist = meta.Artists[0]
 			}
 		case string:
-			meta.Artist = artists
+			meta.Artist = sanitizeText(artists)
 		default:
 			// Some non-compliant players may use unexpected types
 			m.logger.Debug("Unexpected artist type in metadata",
@@ -477,7 +949,60 @@ func (m *MprisMonitor) parseMetadata(metadata map[string]dbus.Variant, status st
 	// Extract album
 	if albumVar, ok := metadata["xesam:album"]; ok {
 		if album, ok := albumVar.Value().(string); ok {
-			meta.Album = album
+			meta.Album = sanitizeText(album)
+		}
+	}
+
+	// Extract album artist and genres, lists per the spec but plain strings in some browsers
+	if albumArtistVar, ok := metadata["xesam:albumArtist"]; ok {
+		if albumArtists := xesamStrings(albumArtistVar.Value()); len(albumArtists) > 0 {
+			meta.AlbumArtist = albumArtists[0]
+		}
+	}
+	if genreVar, ok := metadata["xesam:genre"]; ok {
+		meta.Genres = xesamStrings(genreVar.Value())
+	}
+
+	// Extract release year from the creation date, e.g. "2019-05-03T00:00:00Z" or "2019"
+	if createdVar, ok := metadata["xesam:contentCreated"]; ok {
+		if created, ok := createdVar.Value().(string); ok {
+			meta.Year = releaseYear(created)
+		}
+	}
+
+	// Extract disc and track numbers, integers per the spec but strings in some browsers
+	if discVar, ok := metadata["xesam:discNumber"]; ok {
+		meta.DiscNumber = xesamNumber(discVar.Value())
+	}
+	if trackNumberVar, ok := metadata["xesam:trackNumber"]; ok {
+		meta.TrackNumber = xesamNumber(trackNumberVar.Value())
+	}
+
+	// Extract track ID, an object path per the spec but a string in some players
+	if trackVar, ok := metadata["mpris:trackid"]; ok {
+		var trackID string
+		switch id := trackVar.Value().(type) {
+		case dbus.ObjectPath:
+			trackID = string(id)
+		case string:
+			trackID = id
+		}
+		if trackID != noTrack {
+			meta.TrackID = sanitizeText(trackID)
+		}
+	}
+
+	// Extract media URL (local file or stream location)
+	if urlVar, ok := metadata["xesam:url"]; ok {
+		if mediaURL, ok := urlVar.Value().(string); ok {
+			meta.URL = mediaURL
+		}
+	}
+
+	// Extract length in microseconds
+	if lengthVar, ok := metadata["mpris:length"]; ok {
+		if length, ok := microseconds(lengthVar.Value()); ok {
+			meta.Length = length
 		}
 	}
 
@@ -498,31 +1023,70 @@ func (m *MprisMonitor) parseMetadata(metadata map[string]dbus.Variant, status st
 	return meta
 }
 
-// getPlayerName returns the well-known player name for a unique bus name
-// Falls back to the unique name if no mapping exists
-func (m *MprisMonitor) getPlayerName(uniqueName string) string {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+// xesamStrings reads a list of names, sent as a list, a single string or a list
+// of variants depending on the player. Empty entries are dropped.
+func xesamStrings(value any) []string {
+	var values []string
+	switch v := value.(type) {
+	case []string:
+		values = v
+	case string:
+		values = []string{v}
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	case []dbus.Variant:
+		for _, item := range v {
+			if s, ok := item.Value().(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
 
-	if wellKnown, ok := m.playerNames[uniqueName]; ok {
-		return wellKnown
+	var names []string
+	for _, name := range values {
+		if name = sanitizeText(name); name != "" {
+			names = append(names, name)
+		}
 	}
-	return uniqueName
+	return names
 }
 
-// logChannelFullWarning logs a warning about channel being full, but rate-limited
-// to avoid log spam during rapid track changes (e.g., fast skipping)
-func (m *MprisMonitor) logChannelFullWarning() {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	// Rate limit to max one warning per 5 seconds
-	const warningInterval = 5 * time.Second
-	now := time.Now()
+// xesamNumber reads a disc or track number, sent as any integer type or as a
+// string such as "3" or "3/12". It returns 0 when there is none.
+func xesamNumber(value any) int {
+	var n int64
+	switch v := value.(type) {
+	case int32:
+		n = int64(v)
+	case int64:
+		n = v
+	case uint32:
+		n = int64(v)
+	case uint64:
+		n = int64(min(v, math.MaxInt64))
+	case int:
+		n = int64(v)
+	case float64:
+		n = int64(v)
+	case string:
+		number, _, _ := strings.Cut(strings.TrimSpace(v), "/")
+		n, _ = strconv.ParseInt(number, 10, 32)
+	}
+	if n <= 0 || n > math.MaxInt32 {
+		return 0
+	}
+	return int(n)
+}
 
-	if now.Sub(m.lastDropWarning) >= warningInterval {
-		m.logger.Warn("Events channel full, dropping metadata (consumer may be slow or fast track changes occurring)",
-			zap.String("note", "This is expected during rapid track skipping. Consumer should implement debouncing."))
-		m.lastDropWarning = now
+// getPlayerName returns the well-known player name for a unique bus name
+// Falls back to the unique name if no mapping exists
+func (m *MprisMonitor) getPlayerName(uniqueName string) string {
+	if wellKnown, ok := m.snapshot().names[uniqueName]; ok {
+		return wellKnown
 	}
+	return uniqueName
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/genricoloni/synest/internal/clock"
 	"github.com/genricoloni/synest/internal/domain"
 	"github.com/genricoloni/synest/internal/monitor/mocks"
 	"github.com/godbus/dbus/v5"
@@ -75,7 +76,7 @@ func TestFetchPlayerMetadata(t *testing.T) {
 			mockClient := mocks.NewMockDBusClient(ctrl)
 			tt.setupMock(mockClient)
 
-			mon := NewMprisMonitor(zap.NewNop())
+			mon := NewMprisMonitor(zap.NewNop(), &mockConfig{}, clock.New())
 			mon.conn = mockClient
 			mon.running = true
 
@@ -135,20 +136,35 @@ func TestDetectExistingPlayers(t *testing.T) {
 				m.EXPECT().GetNameOwner("org.mpris.MediaPlayer2.spotify").Return(":1.100", nil)
 				m.EXPECT().GetNameOwner("org.mpris.MediaPlayer2.vlc").Return(":1.200", nil)
 
-				// 3. Fetch Metadata for Spotify
+				// 3. Identity and playback settings of both players, VLC reports none
+				m.EXPECT().GetProperty("org.mpris.MediaPlayer2.spotify", gomock.Any(), "org.mpris.MediaPlayer2.Identity").
+					Return(dbus.MakeVariant("Spotify"), nil)
+				m.EXPECT().GetProperty("org.mpris.MediaPlayer2.spotify", gomock.Any(), "org.mpris.MediaPlayer2.DesktopEntry").
+					Return(dbus.MakeVariant("spotify.desktop"), nil)
+				m.EXPECT().GetProperty("org.mpris.MediaPlayer2.spotify", gomock.Any(), "org.mpris.MediaPlayer2.Player.Volume").
+					Return(dbus.MakeVariant(0.5), nil)
+				m.EXPECT().GetProperty("org.mpris.MediaPlayer2.spotify", gomock.Any(), "org.mpris.MediaPlayer2.Player.Shuffle").
+					Return(dbus.MakeVariant(true), nil)
+				m.EXPECT().GetProperty("org.mpris.MediaPlayer2.spotify", gomock.Any(), "org.mpris.MediaPlayer2.Player.LoopStatus").
+					Return(dbus.MakeVariant("Playlist"), nil)
+				m.EXPECT().GetProperty("org.mpris.MediaPlayer2.vlc", gomock.Any(), gomock.Any()).
+					Return(dbus.Variant{}, fmt.Errorf("no such property")).Times(5)
+
+				// 4. Fetch Metadata for Spotify
 				m.EXPECT().GetProperty("org.mpris.MediaPlayer2.spotify", gomock.Any(), gomock.Any()).
 					Return(dbus.MakeVariant(map[string]dbus.Variant{"xesam:title": dbus.MakeVariant("Song A")}), nil)
 				m.EXPECT().GetProperty("org.mpris.MediaPlayer2.spotify", gomock.Any(), gomock.Any()).
 					Return(dbus.MakeVariant("Playing"), nil)
 
-				// 4. Fetch Metadata for VLC
+				// 5. Fetch Metadata for VLC
 				m.EXPECT().GetProperty("org.mpris.MediaPlayer2.vlc", gomock.Any(), gomock.Any()).
 					Return(dbus.MakeVariant(map[string]dbus.Variant{"xesam:title": dbus.MakeVariant("Video B")}), nil)
 				m.EXPECT().GetProperty("org.mpris.MediaPlayer2.vlc", gomock.Any(), gomock.Any()).
 					Return(dbus.MakeVariant("Paused"), nil)
 			},
-			expectError:     false,
-			expectedPlayers: 2,
+			expectError: false,
+			// VLC is paused while Spotify plays, so only Spotify drives the wallpaper
+			expectedPlayers: 1,
 			expectedMappings: map[string]string{
 				":1.100": "org.mpris.MediaPlayer2.spotify",
 				":1.200": "org.mpris.MediaPlayer2.vlc",
@@ -172,7 +188,7 @@ func TestDetectExistingPlayers(t *testing.T) {
 			mockClient := mocks.NewMockDBusClient(ctrl)
 			tt.setupMock(mockClient)
 
-			mon := NewMprisMonitor(zap.NewNop())
+			mon := NewMprisMonitor(zap.NewNop(), &mockConfig{}, clock.New())
 			mon.conn = mockClient
 			mon.running = true
 
@@ -187,12 +203,13 @@ func TestDetectExistingPlayers(t *testing.T) {
 			}
 
 			// Check Mappings
-			if len(mon.playerNames) != len(tt.expectedMappings) {
-				t.Errorf("Mapping count mismatch: want %d, got %d", len(tt.expectedMappings), len(mon.playerNames))
+			names := mon.snapshot().names
+			if len(names) != len(tt.expectedMappings) {
+				t.Errorf("Mapping count mismatch: want %d, got %d", len(tt.expectedMappings), len(names))
 			}
 			for k, v := range tt.expectedMappings {
-				if mon.playerNames[k] != v {
-					t.Errorf("Mapping mismatch for %s: want %s, got %s", k, v, mon.playerNames[k])
+				if names[k] != v {
+					t.Errorf("Mapping mismatch for %s: want %s, got %s", k, v, names[k])
 				}
 			}
 
@@ -201,7 +218,13 @@ func TestDetectExistingPlayers(t *testing.T) {
 				eventsFound := 0
 				// Drain channel
 				for len(mon.Events()) > 0 {
-					<-mon.Events()
+					event := <-mon.Events()
+					if event.Player == "spotify" && (event.PlayerIdentity != "Spotify" || event.PlayerDesktopEntry != "spotify") {
+						t.Errorf("Expected Spotify from spotify.desktop, got %q from %q", event.PlayerIdentity, event.PlayerDesktopEntry)
+					}
+					if event.Player == "spotify" && (event.Volume == nil || *event.Volume != 0.5 || event.Shuffle == nil || !*event.Shuffle || event.Loop != domain.LoopPlaylist) {
+						t.Errorf("Expected volume 0.5, shuffle and playlist loop, got %v, %v and %q", event.Volume, event.Shuffle, event.Loop)
+					}
 					eventsFound++
 				}
 				if eventsFound != tt.expectedPlayers {
@@ -5,8 +5,11 @@ package monitor
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/genricoloni/synest/internal/clock"
 	"github.com/genricoloni/synest/internal/domain"
 	"go.uber.org/zap"
 )
@@ -17,13 +20,13 @@ type MprisMonitor struct {
 }
 
 // NewMprisMonitor creates a stub monitor that returns an error on non-Linux platforms
-func NewMprisMonitor(logger *zap.Logger) *MprisMonitor {
+func NewMprisMonitor(logger *zap.Logger, cfg domain.Config, clk clock.Clock) *MprisMonitor {
 	return &MprisMonitor{logger: logger}
 }
 
 // Start returns an error indicating MPRIS monitoring is not supported on this platform
 func (m *MprisMonitor) Start(ctx context.Context) error {
-	return fmt.Errorf("MPRIS monitoring is only supported on Linux systems")
+	return fmt.Errorf("MPRIS monitoring is only supported on Linux systems: %w", errors.ErrUnsupported)
 }
 
 // Events returns a closed channel since monitoring is not available
@@ -33,7 +36,17 @@ func (m *MprisMonitor) Events() <-chan domain.MediaMetadata {
 	return ch
 }
 
+// Position reports no track, playback is not followed on non-Linux platforms
+func (m *MprisMonitor) Position() (position, length time.Duration, ok bool) {
+	return 0, 0, false
+}
+
+// Control returns an error indicating players cannot be controlled on this platform
+func (m *MprisMonitor) Control(ctx context.Context, command string) error {
+	return fmt.Errorf("player control is only supported on Linux systems")
+}
+
 // Stop is a no-op on non-Linux platforms
-func (m *MprisMonitor) Stop() error {
+func (m *MprisMonitor) Stop(ctx context.Context) error {
 	return nil
 }
@@ -1,10 +1,16 @@
 package monitor
 
 import (
+	"context"
 	"fmt"
+	"maps"
+	"strings"
 	"testing"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/genricoloni/synest/internal/clock"
 	"github.com/genricoloni/synest/internal/domain"
 	"github.com/godbus/dbus/v5"
 	"go.uber.org/zap"
@@ -13,10 +19,11 @@ import (
 // TestHandleSignal_HappyPath verifies the standard scenario: a valid signal produces a valid event.
 func TestHandleSignal_HappyPath(t *testing.T) {
 	logger := zap.NewNop()
-	mon := NewMprisMonitor(logger)
+	// Disable the Spotify settle delay so the signal is emitted immediately
+	mon := NewMprisMonitor(logger, &mockConfig{settleDelays: map[string]time.Duration{"spotify": 0}}, clock.New())
 	mon.conn = &noopDBusClient{} // Prevent panic if code tries to call DBus
 	mon.running = true
-	mon.playerNames = map[string]string{":1.100": "org.mpris.MediaPlayer2.spotify"}
+	setPlayerNames(mon, map[string]string{":1.100": "org.mpris.MediaPlayer2.spotify"})
 
 	expectedTitle := "Bohemian Rhapsody"
 	expectedArtist := "Queen"
@@ -53,11 +60,116 @@ func TestHandleSignal_HappyPath(t *testing.T) {
 		if event.Status != domain.StatusPlaying {
 			t.Errorf("Status: expected Playing, got %v", event.Status)
 		}
+		if event.PlayerName != "org.mpris.MediaPlayer2.spotify" {
+			t.Errorf("PlayerName: expected the bus name, got '%s'", event.PlayerName)
+		}
 	case <-time.After(1 * time.Second):
 		t.Fatal("Timeout: Event was not emitted")
 	}
 }
 
+// TestHandleSignal_PlaybackOptions verifies a change of the playback settings
+// alone emits no event and goes out with the next one of the player
+func TestHandleSignal_PlaybackOptions(t *testing.T) {
+	mon := NewMprisMonitor(zap.NewNop(), &mockConfig{settleDelays: map[string]time.Duration{"spotify": 0}}, clock.New())
+	mon.conn = &noopDBusClient{}
+	mon.running = true
+	setPlayerNames(mon, map[string]string{":1.100": "org.mpris.MediaPlayer2.spotify"})
+	mon.updateTable(func(t *playerTable) { t.options["org.mpris.MediaPlayer2.spotify"] = playbackOptions{} })
+
+	signal := func(changed map[string]dbus.Variant) *dbus.Signal {
+		return &dbus.Signal{
+			Name:   "org.freedesktop.DBus.Properties.PropertiesChanged",
+			Sender: ":1.100",
+			Body:   []interface{}{"org.mpris.MediaPlayer2.Player", changed, []string{}},
+		}
+	}
+
+	mon.handleSignal(signal(map[string]dbus.Variant{
+		"Volume":     dbus.MakeVariant(0.3),
+		"LoopStatus": dbus.MakeVariant("Track"),
+	}))
+	select {
+	case event := <-mon.Events():
+		t.Fatalf("expected no event for a settings change, got %+v", event)
+	default:
+	}
+
+	go mon.handleSignal(signal(map[string]dbus.Variant{
+		"Metadata": dbus.MakeVariant(map[string]dbus.Variant{
+			"xesam:title":  dbus.MakeVariant("Song"),
+			"mpris:artUrl": dbus.MakeVariant("https://example.com/cover.jpg"),
+		}),
+		"PlaybackStatus": dbus.MakeVariant("Playing"),
+	}))
+	select {
+	case event := <-mon.Events():
+		if event.Volume == nil || *event.Volume != 0.3 {
+			t.Errorf("expected volume 0.3, got %v", event.Volume)
+		}
+		if event.Loop != domain.LoopTrack {
+			t.Errorf("expected loop Track, got %q", event.Loop)
+		}
+		if event.Shuffle != nil {
+			t.Errorf("expected unknown shuffle, got %v", *event.Shuffle)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timeout: Event was not emitted")
+	}
+}
+
+// TestHandleSignal_LockFree verifies signals that emit no event, the bulk of
+// what chatty players send, are handled without taking m.mu
+func TestHandleSignal_LockFree(t *testing.T) {
+	mon := NewMprisMonitor(zap.NewNop(), &mockConfig{}, clock.New())
+	mon.conn = &noopDBusClient{}
+	setPlayerNames(mon, map[string]string{":1.100": "org.mpris.MediaPlayer2.spotify"})
+	mon.updateTable(func(t *playerTable) { t.options["org.mpris.MediaPlayer2.spotify"] = playbackOptions{} })
+
+	mon.mu.Lock()
+	defer mon.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		mon.handleSignal(&dbus.Signal{
+			Name:   "org.freedesktop.DBus.Properties.PropertiesChanged",
+			Sender: ":1.100",
+			Body:   []interface{}{"org.mpris.MediaPlayer2.Player", map[string]dbus.Variant{"Rate": dbus.MakeVariant(1.0)}, []string{}},
+		})
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("signal handling waited for the monitor lock")
+	}
+}
+
+// TestClean_ArtistDisplay verifies every credited artist ends up in the display
+// string, whether the player sends a list or a single collaboration string
+func TestClean_ArtistDisplay(t *testing.T) {
+	mon := NewMprisMonitor(zap.NewNop(), &mockConfig{}, clock.New())
+
+	tests := []struct {
+		name     string
+		artist   interface{}
+		expected string
+	}{
+		{"list", []string{"Daft Punk", "", "Pharrell Williams"}, "Daft Punk & Pharrell Williams"},
+		{"featuring string", "Daft Punk feat. Pharrell Williams", "Daft Punk & Pharrell Williams"},
+		{"single", "Queen", "Queen"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta := mon.clean(mon.quirks.For("org.mpris.MediaPlayer2.vlc"), mon.parseMetadata(map[string]dbus.Variant{
+				"xesam:artist": dbus.MakeVariant(tt.artist),
+			}, "Playing"))
+			if meta.ArtistDisplay != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, meta.ArtistDisplay)
+			}
+		})
+	}
+}
+
 // TestHandleSignal_EdgeCases consolidates all invalid/ignored scenarios into a table test.
 func TestHandleSignal_EdgeCases(t *testing.T) {
 	tests := []struct {
@@ -111,7 +223,7 @@ func TestHandleSignal_EdgeCases(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mon := NewMprisMonitor(zap.NewNop())
+			mon := NewMprisMonitor(zap.NewNop(), &mockConfig{}, clock.New())
 			mon.conn = &noopDBusClient{}
 			mon.running = true
 
@@ -164,6 +276,101 @@ func TestHandleSignal_DataVariations(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "Media URL",
+			props: map[string]dbus.Variant{
+				"Metadata": dbus.MakeVariant(map[string]dbus.Variant{
+					"xesam:url": dbus.MakeVariant("file:///home/user/Music/song.mp3"),
+				}),
+				"PlaybackStatus": dbus.MakeVariant("Playing"),
+			},
+			check: func(t *testing.T, e domain.MediaMetadata) {
+				if e.URL != "file:///home/user/Music/song.mp3" {
+					t.Errorf("Expected media URL, got '%s'", e.URL)
+				}
+			},
+		},
+		{
+			name: "Track ID",
+			props: map[string]dbus.Variant{
+				"Metadata": dbus.MakeVariant(map[string]dbus.Variant{
+					"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/com/spotify/track/4uLU6hMCjMI75M1A2tKUQC")),
+				}),
+				"PlaybackStatus": dbus.MakeVariant("Playing"),
+			},
+			check: func(t *testing.T, e domain.MediaMetadata) {
+				if e.TrackID != "/com/spotify/track/4uLU6hMCjMI75M1A2tKUQC" {
+					t.Errorf("Expected track ID, got '%s'", e.TrackID)
+				}
+			},
+		},
+		{
+			name: "No Track ID",
+			props: map[string]dbus.Variant{
+				"Metadata": dbus.MakeVariant(map[string]dbus.Variant{
+					"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath(noTrack)),
+				}),
+				"PlaybackStatus": dbus.MakeVariant("Playing"),
+			},
+			check: func(t *testing.T, e domain.MediaMetadata) {
+				if e.TrackID != "" {
+					t.Errorf("Expected no track ID, got '%s'", e.TrackID)
+				}
+			},
+		},
+		{
+			name: "Genres",
+			props: map[string]dbus.Variant{
+				"Metadata": dbus.MakeVariant(map[string]dbus.Variant{
+					"xesam:genre": dbus.MakeVariant([]string{"Ambient", "", "Electronic"}),
+				}),
+				"PlaybackStatus": dbus.MakeVariant("Playing"),
+			},
+			check: func(t *testing.T, e domain.MediaMetadata) {
+				if len(e.Genres) != 2 || e.Genres[0] != "Ambient" || e.Genres[1] != "Electronic" {
+					t.Errorf("Expected [Ambient Electronic], got %v", e.Genres)
+				}
+			},
+		},
+		{
+			name: "Album Details",
+			props: map[string]dbus.Variant{
+				"Metadata": dbus.MakeVariant(map[string]dbus.Variant{
+					"xesam:albumArtist":    dbus.MakeVariant([]string{"", "Various Artists"}),
+					"xesam:contentCreated": dbus.MakeVariant("2019-05-03T00:00:00Z"),
+					"xesam:discNumber":     dbus.MakeVariant(int32(2)),
+					"xesam:trackNumber":    dbus.MakeVariant(uint64(7)),
+				}),
+				"PlaybackStatus": dbus.MakeVariant("Playing"),
+			},
+			check: func(t *testing.T, e domain.MediaMetadata) {
+				if e.AlbumArtist != "Various Artists" || e.Year != 2019 || e.DiscNumber != 2 || e.TrackNumber != 7 {
+					t.Errorf("Expected Various Artists, 2019, disc 2, track 7, got %q, %d, disc %d, track %d",
+						e.AlbumArtist, e.Year, e.DiscNumber, e.TrackNumber)
+				}
+			},
+		},
+		{
+			// Browsers send strings where the spec asks for lists and integers
+			name: "Album Details From A Browser",
+			props: map[string]dbus.Variant{
+				"Metadata": dbus.MakeVariant(map[string]dbus.Variant{
+					"xesam:albumArtist":    dbus.MakeVariant("Band"),
+					"xesam:genre":          dbus.MakeVariant([]dbus.Variant{dbus.MakeVariant("Rock")}),
+					"xesam:contentCreated": dbus.MakeVariant("n/a"),
+					"xesam:trackNumber":    dbus.MakeVariant("3/12"),
+				}),
+				"PlaybackStatus": dbus.MakeVariant("Playing"),
+			},
+			check: func(t *testing.T, e domain.MediaMetadata) {
+				if e.AlbumArtist != "Band" || len(e.Genres) != 1 || e.Genres[0] != "Rock" {
+					t.Errorf("Expected Band and [Rock], got %q and %v", e.AlbumArtist, e.Genres)
+				}
+				if e.Year != 0 || e.TrackNumber != 3 {
+					t.Errorf("Expected no year and track 3, got %d and track %d", e.Year, e.TrackNumber)
+				}
+			},
+		},
 		{
 			name: "Status Paused",
 			props: map[string]dbus.Variant{
@@ -190,7 +397,7 @@ func TestHandleSignal_DataVariations(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mon := NewMprisMonitor(zap.NewNop())
+			mon := NewMprisMonitor(zap.NewNop(), &mockConfig{}, clock.New())
 			mon.conn = &noopDBusClient{}
 			mon.running = true
 
@@ -256,12 +463,12 @@ func TestHandleNameOwnerChanged(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mon := NewMprisMonitor(zap.NewNop())
+			mon := NewMprisMonitor(zap.NewNop(), &mockConfig{}, clock.New())
 			mon.conn = &noopDBusClient{} // Stub to avoid fetch panic
 
 			// Pre-populate if testing disappearance
 			if !tt.expectMapped && tt.targetUnique != "" {
-				mon.playerNames[tt.targetUnique] = "org.mpris.MediaPlayer2.spotify"
+				setPlayerNames(mon, map[string]string{tt.targetUnique: "org.mpris.MediaPlayer2.spotify"})
 			}
 
 			signal := &dbus.Signal{
@@ -271,9 +478,7 @@ func TestHandleNameOwnerChanged(t *testing.T) {
 
 			mon.handleNameOwnerChanged(signal)
 
-			mon.mu.RLock()
-			val, exists := mon.playerNames[tt.targetUnique]
-			mon.mu.RUnlock()
+			val, exists := mon.snapshot().names[tt.targetUnique]
 
 			if tt.expectMapped {
 				if !exists {
@@ -295,11 +500,116 @@ func TestHandleNameOwnerChanged(t *testing.T) {
 	}
 }
 
+// TestHandleNameOwnerChanged_PlayerEvents verifies players appearing and
+// vanishing are reported with the players left running
+func TestHandleNameOwnerChanged_PlayerEvents(t *testing.T) {
+	mon := NewMprisMonitor(zap.NewNop(), &mockConfig{}, clock.New())
+	mon.conn = &noopDBusClient{}
+
+	changes := [][]interface{}{
+		{"org.mpris.MediaPlayer2.spotify", "", ":1.50"},
+		{"org.mpris.MediaPlayer2.vlc", "", ":1.51"},
+		{"com.example.service", "", ":1.52"},
+		{"org.mpris.MediaPlayer2.spotify", ":1.50", ""},
+	}
+	for _, body := range changes {
+		mon.handleNameOwnerChanged(&dbus.Signal{Name: "org.freedesktop.DBus.NameOwnerChanged", Body: body})
+	}
+
+	expected := []domain.PlayerEvent{
+		{Kind: domain.PlayerAppeared, PlayerName: "org.mpris.MediaPlayer2.spotify", Players: 1},
+		{Kind: domain.PlayerAppeared, PlayerName: "org.mpris.MediaPlayer2.vlc", Players: 2},
+		{Kind: domain.PlayerVanished, PlayerName: "org.mpris.MediaPlayer2.spotify", Players: 1},
+	}
+	for _, want := range expected {
+		select {
+		case got := <-mon.PlayerEvents():
+			if got != want {
+				t.Errorf("expected %+v, got %+v", want, got)
+			}
+		default:
+			t.Fatalf("expected %+v, got no event", want)
+		}
+	}
+	select {
+	case got := <-mon.PlayerEvents():
+		t.Errorf("expected no more events, got %+v", got)
+	default:
+	}
+}
+
+// TestHandleNameOwnerChanged_RestartGrace verifies a vanished active player counts
+// as stopped only if it does not come back within the grace period
+func TestHandleNameOwnerChanged_RestartGrace(t *testing.T) {
+	const spotify = "org.mpris.MediaPlayer2.spotify"
+	ownerChanged := func(oldOwner, newOwner string) *dbus.Signal {
+		return &dbus.Signal{
+			Name: "org.freedesktop.DBus.NameOwnerChanged",
+			Body: []interface{}{spotify, oldOwner, newOwner},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		restart     bool
+		expectEvent bool
+	}{
+		{name: "Player Restarts Within Grace", restart: true, expectEvent: false},
+		{name: "Player Stays Gone", restart: false, expectEvent: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.NewFake(time.Unix(0, 0))
+			mon := NewMprisMonitor(zap.NewNop(), &mockConfig{restartGrace: time.Minute}, clk)
+			mon.conn = &noopDBusClient{}
+			mon.running = true
+			setPlayerNames(mon, map[string]string{":1.50": spotify})
+			mon.active = ":1.50"
+
+			mon.handleNameOwnerChanged(ownerChanged(":1.50", ""))
+			if tt.restart {
+				mon.handleNameOwnerChanged(ownerChanged("", ":1.51"))
+			}
+			clk.BlockUntil(1)
+			clk.Advance(time.Minute)
+
+			if !tt.expectEvent {
+				// Stop waits for the grace goroutine, then closes the channel
+				if err := mon.Stop(context.Background()); err != nil {
+					t.Fatalf("Stop failed: %v", err)
+				}
+				if event, ok := <-mon.Events(); ok {
+					t.Fatalf("unexpected event: %+v", event)
+				}
+				return
+			}
+
+			select {
+			case event := <-mon.Events():
+				if event.Status != domain.StatusStopped || event.Player != "spotify" {
+					t.Errorf("expected stopped event from spotify, got %+v", event)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("expected a stopped event after the grace period")
+			}
+		})
+	}
+}
+
+// setPlayerNames maps unique bus names to the well-known names of players, as
+// if they had appeared on the bus
+func setPlayerNames(mon *MprisMonitor, names map[string]string) {
+	mon.mu.Lock()
+	defer mon.mu.Unlock()
+	mon.updateTable(func(t *playerTable) { maps.Copy(t.names, names) })
+}
+
 func TestGetPlayerName(t *testing.T) {
-	mon := NewMprisMonitor(zap.NewNop())
-	mon.playerNames = map[string]string{
+	mon := NewMprisMonitor(zap.NewNop(), &mockConfig{}, clock.New())
+	setPlayerNames(mon, map[string]string{
 		":1.100": "org.mpris.MediaPlayer2.spotify",
-	}
+	})
 
 	tests := []struct {
 		input    string
@@ -316,7 +626,244 @@ func TestGetPlayerName(t *testing.T) {
 	}
 }
 
+// TestHandleSignal_FilteredPlayer verifies that signals from denied players are dropped
+func TestHandleSignal_FilteredPlayer(t *testing.T) {
+	mon := NewMprisMonitor(zap.NewNop(), &mockConfig{
+		settleDelays: map[string]time.Duration{"spotify": 0},
+		playerDeny:   []string{"firefox"},
+	}, clock.New())
+	mon.running = true
+	setPlayerNames(mon, map[string]string{
+		":1.100": "org.mpris.MediaPlayer2.spotify",
+		":1.200": "org.mpris.MediaPlayer2.firefox.instance_1_42",
+	})
+
+	signal := func(sender, title string) *dbus.Signal {
+		return &dbus.Signal{
+			Name:   "org.freedesktop.DBus.Properties.PropertiesChanged",
+			Sender: sender,
+			Body: []interface{}{
+				"org.mpris.MediaPlayer2.Player",
+				map[string]dbus.Variant{
+					"Metadata": dbus.MakeVariant(map[string]dbus.Variant{
+						"xesam:title": dbus.MakeVariant(title),
+					}),
+					"PlaybackStatus": dbus.MakeVariant("Playing"),
+				},
+				[]string{},
+			},
+		}
+	}
+
+	mon.handleSignal(signal(":1.200", "Video"))
+	mon.handleSignal(signal(":1.100", "Song"))
+
+	select {
+	case event := <-mon.Events():
+		if event.Title != "Song" {
+			t.Errorf("expected only the allowed player's event, got %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected an event from the allowed player")
+	}
+
+	select {
+	case event := <-mon.Events():
+		t.Errorf("unexpected event: %+v", event)
+	default:
+	}
+}
+
+// TestHandleSignal_SubscribedPlayer verifies that only the player subscribed to
+// is followed, and its match rules name it as the sender
+func TestHandleSignal_SubscribedPlayer(t *testing.T) {
+	mon := NewMprisMonitor(zap.NewNop(), &mockConfig{
+		settleDelays: map[string]time.Duration{"spotify": 0},
+		player:       "org.mpris.MediaPlayer2.spotify",
+	}, clock.New())
+	mon.running = true
+	setPlayerNames(mon, map[string]string{
+		":1.100": "org.mpris.MediaPlayer2.spotify",
+		":1.200": "org.mpris.MediaPlayer2.chromium.instance7",
+	})
+
+	options := mon.matchOptions(dbus.WithMatchMember("PropertiesChanged"))
+	if len(options) != 2 || options[1] != dbus.WithMatchSender("org.mpris.MediaPlayer2.spotify") {
+		t.Errorf("expected the match rule restricted to the player, got %v", options)
+	}
+
+	for sender, title := range map[string]string{":1.200": "Video", ":1.100": "Song"} {
+		mon.handleSignal(&dbus.Signal{
+			Name:   "org.freedesktop.DBus.Properties.PropertiesChanged",
+			Sender: sender,
+			Body: []interface{}{
+				"org.mpris.MediaPlayer2.Player",
+				map[string]dbus.Variant{
+					"Metadata":       dbus.MakeVariant(map[string]dbus.Variant{"xesam:title": dbus.MakeVariant(title)}),
+					"PlaybackStatus": dbus.MakeVariant("Playing"),
+				},
+				[]string{},
+			},
+		})
+	}
+
+	select {
+	case event := <-mon.Events():
+		if event.Title != "Song" {
+			t.Errorf("expected only the subscribed player's event, got %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected an event from the subscribed player")
+	}
+	select {
+	case event := <-mon.Events():
+		t.Errorf("unexpected event: %+v", event)
+	default:
+	}
+}
 
+// TestHandleSignal_SettleDelay verifies that players with a settle quirk emit the
+// re-fetched metadata once, after the delay, instead of the stale signal contents
+func TestHandleSignal_SettleDelay(t *testing.T) {
+	client := &settleDBusClient{artUrl: "https://example.com/new.jpg"}
+	mon := NewMprisMonitor(zap.NewNop(), &mockConfig{
+		settleDelays: map[string]time.Duration{"spotify": 50 * time.Millisecond},
+	}, clock.New())
+	mon.conn = client
+	mon.running = true
+	setPlayerNames(mon, map[string]string{":1.100": "org.mpris.MediaPlayer2.spotify"})
+
+	signal := func(title string) *dbus.Signal {
+		return &dbus.Signal{
+			Name:   "org.freedesktop.DBus.Properties.PropertiesChanged",
+			Sender: ":1.100",
+			Body: []interface{}{
+				"org.mpris.MediaPlayer2.Player",
+				map[string]dbus.Variant{
+					"Metadata": dbus.MakeVariant(map[string]dbus.Variant{
+						"xesam:title":  dbus.MakeVariant(title),
+						"mpris:artUrl": dbus.MakeVariant("https://example.com/old.jpg"),
+					}),
+					"PlaybackStatus": dbus.MakeVariant("Playing"),
+				},
+				[]string{},
+			},
+		}
+	}
+
+	// Two quick changes: only the latest one should settle and be emitted
+	mon.handleSignal(signal("First"))
+	mon.handleSignal(signal("Second"))
+
+	select {
+	case event := <-mon.Events():
+		t.Fatalf("event emitted before settle delay: %+v", event)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	select {
+	case event := <-mon.Events():
+		if event.ArtUrl != client.artUrl {
+			t.Errorf("ArtUrl: expected re-fetched '%s', got '%s'", client.artUrl, event.ArtUrl)
+		}
+		if event.Status != domain.StatusPlaying {
+			t.Errorf("Status: expected Playing, got %v", event.Status)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("Timeout: settled event was not emitted")
+	}
+
+	select {
+	case event := <-mon.Events():
+		t.Errorf("superseded change was emitted: %+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if err := mon.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+}
+
+// TestStop_CancelsPendingSettle verifies Stop does not wait for or emit pending settles
+func TestStop_CancelsPendingSettle(t *testing.T) {
+	mon := NewMprisMonitor(zap.NewNop(), &mockConfig{
+		settleDelays: map[string]time.Duration{"spotify": time.Hour},
+	}, clock.New())
+	mon.conn = &noopDBusClient{}
+	mon.running = true
+
+	mon.settle(":1.100", "org.mpris.MediaPlayer2.spotify", domain.MediaMetadata{Title: "Pending"}, time.Hour)
+
+	done := make(chan error, 1)
+	go func() { done <- mon.Stop(context.Background()) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Stop failed: %v", err)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("Stop blocked on pending settle")
+	}
+
+	if event, ok := <-mon.Events(); ok {
+		t.Errorf("pending settle was emitted: %+v", event)
+	}
+}
+
+// FuzzParseMetadata feeds arbitrary strings and variant types through parseMetadata.
+// Run with: go test ./internal/monitor -fuzz FuzzParseMetadata
+func FuzzParseMetadata(f *testing.F) {
+	f.Add("Song", "Artist", "Album", "https://example.com/a.jpg", "Playing", uint8(0))
+	f.Add("", "", "", "", "", uint8(1))
+	f.Add("\xff\xfe", "a\x00b", "\n\t", "file:///tmp/%zz", "paused", uint8(2))
+	f.Add(strings.Repeat("é", 600), "x", "y", "z", "Stopped", uint8(3))
+
+	mon := NewMprisMonitor(zap.NewNop(), &mockConfig{}, clock.New())
+
+	f.Fuzz(func(t *testing.T, title, artist, album, artURL, status string, artistKind uint8) {
+		// Players disagree on the artist type, cover the variants seen in the wild
+		var artistVariant dbus.Variant
+		switch artistKind % 5 {
+		case 0:
+			artistVariant = dbus.MakeVariant([]string{artist, title})
+		case 1:
+			artistVariant = dbus.MakeVariant(artist)
+		case 2:
+			artistVariant = dbus.MakeVariant([]string{})
+		case 3:
+			artistVariant = dbus.MakeVariant(int32(len(artist)))
+		default:
+			artistVariant = dbus.Variant{} // Zero variant holds a nil value
+		}
+
+		meta := mon.parseMetadata(map[string]dbus.Variant{
+			"xesam:title":  dbus.MakeVariant(title),
+			"xesam:artist": artistVariant,
+			"xesam:album":  dbus.MakeVariant(album),
+			"mpris:artUrl": dbus.MakeVariant(artURL),
+			"xesam:url":    dbus.MakeVariant([]byte(artURL)), // Wrong type must be ignored
+		}, status)
+
+		for _, text := range []string{meta.Title, meta.Artist, meta.Album} {
+			if !utf8.ValidString(text) {
+				t.Errorf("text is not valid UTF-8: %q", text)
+			}
+			if len(text) > maxMetadataTextLen {
+				t.Errorf("text exceeds %d bytes: %d", maxMetadataTextLen, len(text))
+			}
+			if strings.IndexFunc(text, unicode.IsControl) != -1 {
+				t.Errorf("text contains control characters: %q", text)
+			}
+		}
+
+		switch meta.Status {
+		case domain.StatusPlaying, domain.StatusPaused, domain.StatusStopped:
+		default:
+			t.Errorf("unexpected status %q", meta.Status)
+		}
+	})
+}
 
 // noopDBusClient is a stub to prevent panics during unit tests where
 // we don't want to use full mocks but code calls GetProperty/ListNames.
@@ -330,3 +877,135 @@ func (n *noopDBusClient) GetNameOwner(string) (string, error)      { return "",
 func (n *noopDBusClient) GetProperty(string, string, string) (dbus.Variant, error) {
 	return dbus.MakeVariant(""), fmt.Errorf("noop")
 }
+func (n *noopDBusClient) Call(context.Context, string, string, string) error {
+	return fmt.Errorf("noop")
+}
+
+// settleDBusClient reports a playing track whose artwork has already been corrected
+type settleDBusClient struct {
+	noopDBusClient
+	artUrl string
+}
+
+func (s *settleDBusClient) GetProperty(_, _, property string) (dbus.Variant, error) {
+	if property == "org.mpris.MediaPlayer2.Player.PlaybackStatus" {
+		return dbus.MakeVariant("Playing"), nil
+	}
+	return dbus.MakeVariant(map[string]dbus.Variant{
+		"xesam:title":  dbus.MakeVariant("Second"),
+		"mpris:artUrl": dbus.MakeVariant(s.artUrl),
+	}), nil
+}
+
+// mockConfig implements the parts of domain.Config used by the monitor.
+// Other getters are promoted from the nil embedded interface and must not be called.
+type mockConfig struct {
+	domain.Config
+	playerQuirks map[string]string
+	settleDelays map[string]time.Duration
+	backend      string
+	playerDeny   []string
+	restartGrace time.Duration
+	policy       string
+	priority     []string
+	pin          string
+	player       string
+	spotify      [3]string // Client ID, client secret and refresh token
+	spotifyPoll  time.Duration
+	mpd          domain.MPD
+	record       string
+	replay       string
+	replaySpeed  int
+}
+
+func (m *mockConfig) GetPlayerQuirks() map[string]string {
+	return m.playerQuirks
+}
+
+func (m *mockConfig) GetNormalizeRules() []string {
+	return nil
+}
+
+func (m *mockConfig) GetArtistSeparator() string {
+	return " & "
+}
+
+func (m *mockConfig) GetArtSettleDelays() map[string]time.Duration {
+	return m.settleDelays
+}
+
+func (m *mockConfig) GetMonitorBackend() string {
+	return m.backend
+}
+
+func (m *mockConfig) GetMonitorPlayer() string {
+	return m.player
+}
+
+func (m *mockConfig) GetMonitorRecord() string {
+	return m.record
+}
+
+func (m *mockConfig) GetMonitorReplay() string {
+	return m.replay
+}
+
+func (m *mockConfig) GetReplaySpeed() int {
+	return m.replaySpeed
+}
+
+func (m *mockConfig) GetHeartbeatInterval() time.Duration {
+	return 0
+}
+
+func (m *mockConfig) GetRestartGrace() time.Duration {
+	return m.restartGrace
+}
+
+func (m *mockConfig) GetPositionInterval() time.Duration {
+	return 0
+}
+
+func (m *mockConfig) GetPrivateMode() string {
+	return domain.PrivateOff
+}
+
+func (m *mockConfig) GetPrivatePlayers() []string {
+	return nil
+}
+
+func (m *mockConfig) GetPlayerAllow() []string {
+	return nil
+}
+
+func (m *mockConfig) GetPlayerDeny() []string {
+	return m.playerDeny
+}
+
+func (m *mockConfig) GetPlayerPolicy() string {
+	return m.policy
+}
+
+func (m *mockConfig) GetPlayerPriority() []string {
+	return m.priority
+}
+
+func (m *mockConfig) GetPlayerPin() string {
+	return m.pin
+}
+
+func (m *mockConfig) GetSpotifyCredentials() (clientID, clientSecret string) {
+	return m.spotify[0], m.spotify[1]
+}
+
+func (m *mockConfig) GetSpotifyRefreshToken() string {
+	return m.spotify[2]
+}
+
+func (m *mockConfig) GetSpotifyPollInterval() time.Duration {
+	return m.spotifyPoll
+}
+
+func (m *mockConfig) GetMPD() domain.MPD {
+	return m.mpd
+}
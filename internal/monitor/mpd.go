@@ -0,0 +1,346 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/monitor/quirks"
+	"go.uber.org/zap"
+)
+
+const (
+	mpdPlayerName = "mpd"           // Player ID and name of the events
+	mpdTimeout    = 5 * time.Second // Bounds connecting and every command but idle
+	mpdRetry      = 5 * time.Second // Wait before connecting again to a lost server
+)
+
+// mpdCovers are the artwork files looked for next to a local track, in order
+var mpdCovers = []string{"cover.jpg", "cover.png", "folder.jpg", "folder.png", "front.jpg", "front.png"}
+
+// mpdField is a line of a response. Keys repeat, e.g. for every artist of a track.
+type mpdField struct {
+	key   string
+	value string
+}
+
+// mpdConn is a connection speaking the MPD text protocol
+type mpdConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// dialMPD connects to the server, over a unix socket when the address is a path,
+// and authenticates when a password is set
+func dialMPD(ctx context.Context, settings domain.MPD) (*mpdConn, error) {
+	network := "tcp"
+	if strings.HasPrefix(settings.Address, "/") {
+		network = "unix"
+	}
+	dialer := net.Dialer{Timeout: mpdTimeout}
+	conn, err := dialer.DialContext(ctx, network, settings.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &mpdConn{conn: conn, r: bufio.NewReader(conn)}
+	if err := c.greet(settings.Password); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// greet checks the server announces the MPD protocol and sends the password
+func (c *mpdConn) greet(password string) error {
+	if err := c.conn.SetDeadline(time.Now().Add(mpdTimeout)); err != nil {
+		return err
+	}
+	greeting, err := c.r.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read the greeting: %w", err)
+	}
+	if !strings.HasPrefix(greeting, "OK MPD ") {
+		return fmt.Errorf("not an MPD server: %q", strings.TrimSpace(greeting))
+	}
+	if password == "" {
+		return nil
+	}
+	if _, err := c.command("password "+mpdQuote(password), mpdTimeout); err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+	return nil
+}
+
+// command sends cmd and reads its response, failing on an ACK. A zero timeout
+// waits as long as the server takes, for idle.
+func (c *mpdConn) command(cmd string, timeout time.Duration) ([]mpdField, error) {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(c.conn, cmd+"\n"); err != nil {
+		return nil, err
+	}
+
+	var fields []mpdField
+	for {
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "OK" {
+			return fields, nil
+		}
+		if ack, ok := strings.CutPrefix(line, "ACK "); ok {
+			name, _, _ := strings.Cut(cmd, " ")
+			return nil, fmt.Errorf("%s: %s", name, ack)
+		}
+		if key, value, ok := strings.Cut(line, ": "); ok {
+			fields = append(fields, mpdField{key: key, value: value})
+		}
+	}
+}
+
+// mpdQuote quotes an argument of a command
+func mpdQuote(arg string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
+
+// mpdValue returns the first value of key, empty when there is none
+func mpdValue(fields []mpdField, key string) string {
+	for _, f := range fields {
+		if f.key == key {
+			return f.value
+		}
+	}
+	return ""
+}
+
+// mpdSeconds converts a time in seconds, such as "183.250"
+func mpdSeconds(value string) time.Duration {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// mpdNumber reads a track or disc number such as "3" or "3/12", 0 when there is none
+func mpdNumber(value string) int {
+	number, _, _ := strings.Cut(value, "/")
+	n, err := strconv.Atoi(strings.TrimSpace(number))
+	if err != nil || n <= 0 {
+		return 0
+	}
+	return n
+}
+
+// MPDMonitor follows a Music Player Daemon. It waits for changes of the player
+// with the idle command, and connects again when the server goes away.
+type MPDMonitor struct {
+	*follower
+	settings domain.MPD
+	failing  bool // The last connection failed, only used by the run goroutine
+}
+
+// NewMPDMonitor creates a monitor of the MPD server configured in cfg
+func NewMPDMonitor(logger *zap.Logger, cfg domain.Config, clk clock.Clock) *MPDMonitor {
+	return &MPDMonitor{
+		follower: newFollower(logger, cfg, clk, mpdPlayerName, "MPD"),
+		settings: cfg.GetMPD(),
+	}
+}
+
+// Start follows the server until ctx is cancelled. A lost or unreachable
+// server is logged and connected to again after a while.
+func (m *MPDMonitor) Start(ctx context.Context) error {
+	return m.start(ctx, m.run, zap.String("address", m.settings.Address))
+}
+
+// Stop gracefully stops the monitor
+func (m *MPDMonitor) Stop(ctx context.Context) error {
+	return m.stop()
+}
+
+// run follows the server until ctx is cancelled, connecting again after mpdRetry
+// whenever the connection fails
+func (m *MPDMonitor) run(ctx context.Context) {
+	for {
+		err := m.follow(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if m.failing {
+			m.logger.Debug("MPD server unavailable", zap.Error(err))
+		} else {
+			m.logger.Warn("MPD server unavailable, retrying", zap.String("address", m.settings.Address), zap.Error(err))
+		}
+		m.failing = true
+
+		timer := m.clock.NewTimer(mpdRetry)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+	}
+}
+
+// follow connects to the server, then reads the player state every time the
+// server reports a change, until the connection fails or ctx is cancelled
+func (m *MPDMonitor) follow(ctx context.Context) error {
+	c, err := dialMPD(ctx, m.settings)
+	if err != nil {
+		return err
+	}
+	defer c.conn.Close()
+	// Closing the connection ends a pending idle
+	stop := context.AfterFunc(ctx, func() { c.conn.Close() })
+	defer stop()
+
+	if m.failing {
+		m.logger.Info("MPD server available again")
+		m.failing = false
+	}
+	for {
+		if err := m.poll(c); err != nil {
+			return err
+		}
+		// Returns on a change of the track, the state or the position
+		if _, err := c.command("idle player", 0); err != nil {
+			return err
+		}
+	}
+}
+
+// poll reads the player state and emits it when it shows another track or status
+func (m *MPDMonitor) poll(c *mpdConn) error {
+	status, err := c.command("status", mpdTimeout)
+	if err != nil {
+		return err
+	}
+	song, err := c.command("currentsong", mpdTimeout)
+	if err != nil {
+		return err
+	}
+
+	state := mpdValue(status, "state")
+	if (state != "play" && state != "pause") || len(song) == 0 {
+		m.stopped()
+		return nil
+	}
+	m.playing(m.metadata(song, state), mpdSeconds(mpdValue(status, "elapsed")))
+	return nil
+}
+
+// metadata converts the current song to the event emitted for it. Local
+// tracks get a file URL and the artwork found next to them when the music
+// directory is known.
+func (m *MPDMonitor) metadata(song []mpdField, state string) domain.MediaMetadata {
+	meta := domain.MediaMetadata{
+		Player:         mpdPlayerName,
+		PlayerName:     mpdPlayerName,
+		PlayerIdentity: "Music Player Daemon",
+		Status:         domain.StatusPaused,
+	}
+	if state == "play" {
+		meta.Status = domain.StatusPlaying
+	}
+
+	var file, name string
+	for _, f := range song {
+		value := sanitizeText(f.value)
+		switch f.key {
+		case "file":
+			file = f.value
+		case "Id":
+			meta.TrackID = value
+		case "Title":
+			meta.Title = value
+		case "Name":
+			name = value // Streams name their station
+		case "Artist":
+			if value != "" {
+				meta.Artists = append(meta.Artists, value)
+			}
+		case "AlbumArtist":
+			if meta.AlbumArtist == "" {
+				meta.AlbumArtist = value
+			}
+		case "Album":
+			meta.Album = value
+		case "Genre":
+			if value != "" {
+				meta.Genres = append(meta.Genres, value)
+			}
+		case "Date":
+			if meta.Year == 0 {
+				meta.Year = releaseYear(value)
+			}
+		case "Track":
+			meta.TrackNumber = mpdNumber(value)
+		case "Disc":
+			meta.DiscNumber = mpdNumber(value)
+		case "duration":
+			meta.Length = mpdSeconds(value)
+		case "Time":
+			// Whole seconds only, from servers without duration
+			if meta.Length == 0 {
+				meta.Length = mpdSeconds(value)
+			}
+		}
+	}
+	if len(meta.Artists) > 0 {
+		meta.Artist = meta.Artists[0]
+	}
+	if meta.Title == "" {
+		meta.Title = name
+	}
+	if meta.Title == "" {
+		meta.Title = sanitizeText(strings.TrimSuffix(path.Base(file), path.Ext(file)))
+	}
+
+	switch {
+	case strings.Contains(file, "://"):
+		meta.URL = file
+	case file != "" && m.settings.MusicDir != "":
+		local := filepath.Join(m.settings.MusicDir, filepath.FromSlash(file))
+		meta.URL = fileURL(local)
+		meta.ArtUrl = m.cover(filepath.Dir(local))
+	}
+	meta.Source = quirks.SourceOf(meta.URL)
+	return meta
+}
+
+// cover returns the file URL of the artwork in dir, empty when there is none
+func (m *MPDMonitor) cover(dir string) string {
+	for _, name := range mpdCovers {
+		local := filepath.Join(dir, name)
+		if info, err := os.Stat(local); err == nil && info.Mode().IsRegular() {
+			return fileURL(local)
+		}
+	}
+	return ""
+}
+
+// fileURL returns the file:// URL of a local path, percent-encoded
+func fileURL(local string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(local)}).String()
+}
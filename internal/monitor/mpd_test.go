@@ -0,0 +1,246 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+const mpdSong = `file: Band/Album/04 Song.flac
+Artist: Band
+Artist: Guest
+AlbumArtist: Band
+Album: Album
+Title: Song
+Track: 4/12
+Disc: 1
+Date: 1981-12-15
+Genre: Rock
+Time: 180
+duration: 180.250
+Id: 7`
+
+// fakeMPD serves the commands of the MPD protocol used by the monitor. Idle
+// returns when the state changes.
+type fakeMPD struct {
+	listener net.Listener
+	password string
+
+	mu      sync.Mutex
+	state   string // play, pause or stop
+	song    string // Fields of currentsong
+	version int    // Counts the changes
+	conns   []net.Conn
+	changed chan struct{}
+}
+
+func newFakeMPD(t *testing.T, password string) *fakeMPD {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeMPD{listener: listener, password: password, state: "stop", changed: make(chan struct{})}
+	go f.accept()
+	t.Cleanup(func() {
+		listener.Close()
+		f.drop()
+	})
+	return f
+}
+
+func (f *fakeMPD) accept() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.conns = append(f.conns, conn)
+		f.mu.Unlock()
+		go f.serve(conn)
+	}
+}
+
+func (f *fakeMPD) serve(conn net.Conn) {
+	defer conn.Close()
+	fmt.Fprint(conn, "OK MPD 0.23.5\n")
+
+	// Like MPD, idle reports the changes since the state was last read
+	seen := 0
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+
+		f.mu.Lock()
+		state, song, version, changed := f.state, f.song, f.version, f.changed
+		f.mu.Unlock()
+
+		switch cmd {
+		case "password":
+			if arg != mpdQuote(f.password) {
+				fmt.Fprint(conn, "ACK [3@0] {password} incorrect password\n")
+				continue
+			}
+		case "status":
+			seen = version
+			fmt.Fprintf(conn, "volume: 100\nstate: %s\nelapsed: 30.000\n", state)
+		case "currentsong":
+			if song != "" {
+				fmt.Fprint(conn, song+"\n")
+			}
+		case "idle":
+			if version == seen {
+				<-changed
+			}
+			fmt.Fprint(conn, "changed: player\n")
+		}
+		fmt.Fprint(conn, "OK\n")
+	}
+}
+
+// set changes the state and ends the pending idle
+func (f *fakeMPD) set(state, song string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.state, f.song = state, song
+	f.version++
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+// drop closes the open connections, as a restarting server does
+func (f *fakeMPD) drop() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, conn := range f.conns {
+		conn.Close()
+	}
+	f.conns = nil
+}
+
+// TestMPDMonitor verifies the playing track is emitted with its local file and
+// artwork, the end of playback is reported as stopped, and a lost server is
+// connected to again
+func TestMPDMonitor(t *testing.T) {
+	musicDir := t.TempDir()
+	album := filepath.Join(musicDir, "Band", "Album")
+	if err := os.MkdirAll(album, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(album, "folder.png"), []byte("png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	server := newFakeMPD(t, "secret")
+	server.set("play", mpdSong)
+
+	clk := clock.NewFake(time.Unix(1000, 0))
+	cfg := &mockConfig{mpd: domain.MPD{Address: server.listener.Addr().String(), Password: "secret", MusicDir: musicDir}}
+	mon := NewMPDMonitor(zap.NewNop(), cfg, clk)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go mon.Start(ctx)
+
+	next := func(step string) domain.MediaMetadata {
+		t.Helper()
+		select {
+		case meta := <-mon.Events():
+			return meta
+		case <-time.After(time.Second):
+			t.Fatalf("%s: no event", step)
+			return domain.MediaMetadata{}
+		}
+	}
+
+	meta := next("playing track")
+	if meta.Player != "mpd" || meta.Title != "Song" || meta.Artist != "Band" || meta.ArtistDisplay != "Band & Guest" {
+		t.Errorf("unexpected track %q by %q (%q) from %q", meta.Title, meta.Artist, meta.ArtistDisplay, meta.Player)
+	}
+	if meta.AlbumArtist != "Band" || meta.Year != 1981 || meta.DiscNumber != 1 || meta.TrackNumber != 4 || meta.TrackID != "7" {
+		t.Errorf("unexpected album artist %q, year %d, disc %d, track %d or ID %q", meta.AlbumArtist, meta.Year, meta.DiscNumber, meta.TrackNumber, meta.TrackID)
+	}
+	if want := fileURL(filepath.Join(album, "04 Song.flac")); meta.URL != want {
+		t.Errorf("expected URL %q, got %q", want, meta.URL)
+	}
+	if want := fileURL(filepath.Join(album, "folder.png")); meta.ArtUrl != want || meta.Status != domain.StatusPlaying {
+		t.Errorf("expected artwork %q playing, got %q (%s)", want, meta.ArtUrl, meta.Status)
+	}
+	if position, length, ok := mon.Position(); !ok || position != 30*time.Second || length != 180250*time.Millisecond {
+		t.Errorf("expected 30s of 3m0.25s, got %s of %s (%v)", position, length, ok)
+	}
+
+	server.set("pause", mpdSong)
+	if meta := next("paused track"); meta.Title != "Song" || meta.Status != domain.StatusPaused {
+		t.Errorf("expected Song paused, got %q %s", meta.Title, meta.Status)
+	}
+
+	server.set("stop", "")
+	if meta := next("end of playback"); meta.Title != "Song" || meta.Status != domain.StatusStopped {
+		t.Errorf("expected Song stopped, got %q %s", meta.Title, meta.Status)
+	}
+	if _, _, ok := mon.Position(); ok {
+		t.Error("expected no position once playback ended")
+	}
+
+	// A restarted server is connected to again after a while
+	server.drop()
+	server.set("play", mpdSong)
+	clk.BlockUntil(1)
+	clk.Advance(mpdRetry)
+	if meta := next("after reconnecting"); meta.Title != "Song" || meta.Status != domain.StatusPlaying {
+		t.Errorf("expected Song playing, got %q %s", meta.Title, meta.Status)
+	}
+
+	if err := mon.Stop(context.Background()); err != nil {
+		t.Fatalf("unexpected stop error: %v", err)
+	}
+	if _, ok := <-mon.Events(); ok {
+		t.Error("expected the events channel to be closed")
+	}
+}
+
+// TestMPDMonitor_WrongPassword verifies a rejected password fails the connection
+func TestMPDMonitor_WrongPassword(t *testing.T) {
+	server := newFakeMPD(t, "secret")
+
+	_, err := dialMPD(context.Background(), domain.MPD{Address: server.listener.Addr().String(), Password: "wrong"})
+	if err == nil || !strings.Contains(err.Error(), "incorrect password") {
+		t.Fatalf("expected an authentication error, got %v", err)
+	}
+}
+
+// TestMPDMonitor_Metadata verifies streams are named after their station and
+// remote tracks keep their URL without artwork
+func TestMPDMonitor_Metadata(t *testing.T) {
+	mon := NewMPDMonitor(zap.NewNop(), &mockConfig{mpd: domain.MPD{MusicDir: t.TempDir()}}, clock.New())
+
+	stream := mon.metadata([]mpdField{
+		{key: "file", value: "https://radio.example/live.mp3"},
+		{key: "Name", value: "Radio Example"},
+	}, "play")
+	if stream.Title != "Radio Example" || stream.URL != "https://radio.example/live.mp3" || stream.ArtUrl != "" {
+		t.Errorf("unexpected stream %q at %q with %q", stream.Title, stream.URL, stream.ArtUrl)
+	}
+
+	untagged := mon.metadata([]mpdField{{key: "file", value: "Misc/untitled.mp3"}}, "pause")
+	if untagged.Title != "untitled" || untagged.Status != domain.StatusPaused {
+		t.Errorf("expected the file name as a paused title, got %q (%s)", untagged.Title, untagged.Status)
+	}
+}
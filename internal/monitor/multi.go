@@ -0,0 +1,250 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// Source is a monitor backend run by a MultiMonitor
+type Source struct {
+	Name    string // Backend name, tagged on its events
+	Monitor domain.Monitor
+}
+
+// MultiMonitor fans in the events of several backends into one channel. Each
+// event is tagged with its backend. While the backend of the last event plays,
+// another one only takes over by playing a track itself, and reports of the
+// track already on screen are dropped, for players seen by two backends.
+type MultiMonitor struct {
+	logger  *zap.Logger
+	sources []Source
+	events  *eventQueue
+	players chan domain.PlayerEvent // Lifecycle events of every backend
+	done    chan struct{}           // Closed on Stop, ends the forwarders
+	wg      sync.WaitGroup
+
+	mu      sync.Mutex
+	started bool
+	stopped bool
+	active  int                  // Index of the source of the last forwarded event, -1 before the first
+	last    domain.MediaMetadata // Last forwarded event
+	running []int                // Players running per source, from their lifecycle events
+}
+
+// NewMultiMonitor runs the given backends as a single monitor
+func NewMultiMonitor(logger *zap.Logger, sources []Source) *MultiMonitor {
+	return &MultiMonitor{
+		logger:  logger,
+		sources: sources,
+		events:  newEventQueue(logger),
+		players: make(chan domain.PlayerEvent, lifecycleBuffer),
+		done:    make(chan struct{}),
+		active:  -1,
+		running: make([]int, len(sources)),
+	}
+}
+
+// Ready implements domain.Readier: it succeeds once every backend that depends
+// on an external service can use it
+func (m *MultiMonitor) Ready(ctx context.Context) error {
+	for _, s := range m.sources {
+		if r, ok := s.Monitor.(domain.Readier); ok {
+			if err := r.Ready(ctx); err != nil {
+				return fmt.Errorf("%s: %w", s.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Start runs every backend until ctx is cancelled. A failing backend does not
+// stop the others; Start only fails when all of them did.
+func (m *MultiMonitor) Start(ctx context.Context) error {
+	m.mu.Lock()
+	if m.started || m.stopped {
+		m.mu.Unlock()
+		return nil
+	}
+	m.started = true
+	m.mu.Unlock()
+
+	for i, s := range m.sources {
+		m.wg.Add(1)
+		go m.forward(i, s)
+	}
+
+	errs := make(chan error, len(m.sources))
+	for _, s := range m.sources {
+		go func() {
+			err := s.Monitor.Start(ctx)
+			if err != nil && ctx.Err() == nil {
+				m.logger.Error("Monitor backend stopped with error", zap.String("backend", s.Name), zap.Error(err))
+				errs <- fmt.Errorf("%s: %w", s.Name, err)
+				return
+			}
+			errs <- nil
+		}()
+	}
+
+	var failed []error
+	for range m.sources {
+		if err := <-errs; err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return errors.Join(failed...)
+}
+
+// Stop stops every backend, then closes the events channel
+func (m *MultiMonitor) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	if m.stopped {
+		m.mu.Unlock()
+		return nil
+	}
+	m.stopped = true
+	close(m.done)
+	m.mu.Unlock()
+
+	var errs []error
+	for _, s := range m.sources {
+		if err := s.Monitor.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.Name, err))
+		}
+	}
+
+	m.wg.Wait()
+	m.events.close()
+	return errors.Join(errs...)
+}
+
+// Events returns a read-only channel that emits the MediaMetadata of all backends
+func (m *MultiMonitor) Events() <-chan domain.MediaMetadata {
+	return m.events.Events()
+}
+
+// EventStats implements domain.EventCounter with the events of every backend
+func (m *MultiMonitor) EventStats() domain.EventStats {
+	stats := m.events.EventStats()
+	for _, s := range m.sources {
+		if counter, ok := s.Monitor.(domain.EventCounter); ok {
+			backend := counter.EventStats()
+			stats.Coalesced += backend.Coalesced
+			stats.Dropped += backend.Dropped
+		}
+	}
+	return stats
+}
+
+// PlayerEvents implements domain.PlayerEventSource with the events of every backend
+func (m *MultiMonitor) PlayerEvents() <-chan domain.PlayerEvent {
+	return m.players
+}
+
+// Position implements domain.PositionSource with the backend of the last event
+func (m *MultiMonitor) Position() (position, length time.Duration, ok bool) {
+	if src, found := m.current().(domain.PositionSource); found {
+		return src.Position()
+	}
+	return 0, 0, false
+}
+
+// Control implements domain.PlayerController: the command goes to the backend of
+// the last event
+func (m *MultiMonitor) Control(ctx context.Context, command string) error {
+	mon := m.current()
+	if mon == nil {
+		return errors.New("no player is driving the wallpaper")
+	}
+	return NewPlayerController(mon).Control(ctx, command)
+}
+
+// current returns the backend of the last forwarded event, nil before the first
+func (m *MultiMonitor) current() domain.Monitor {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.active < 0 {
+		return nil
+	}
+	return m.sources[m.active].Monitor
+}
+
+// forward tags the events of source i and passes the admitted ones on, with
+// its player lifecycle events, until the source closes its channel or the
+// monitor stops
+func (m *MultiMonitor) forward(i int, s Source) {
+	defer m.wg.Done()
+
+	events := s.Monitor.Events()
+	var lifecycle <-chan domain.PlayerEvent
+	if src, ok := s.Monitor.(domain.PlayerEventSource); ok {
+		lifecycle = src.PlayerEvents()
+	}
+	for {
+		select {
+		case <-m.done:
+			return
+		case ev := <-lifecycle:
+			ev.Backend = s.Name
+			ev.Players = m.count(i, ev.Players)
+			sendPlayerEvent(m.logger, m.players, ev)
+		case meta, ok := <-events:
+			if !ok {
+				return
+			}
+			meta.Backend = s.Name
+			if !m.admit(i, meta) {
+				m.logger.Debug("Ignoring event, another backend drives the wallpaper",
+					zap.String("backend", s.Name),
+					zap.String("player", meta.Player))
+				continue
+			}
+			m.events.push(meta)
+		}
+	}
+}
+
+// count records the players running for source i and returns the players
+// running across every source
+func (m *MultiMonitor) count(i, players int) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.running[i] = players
+	total := 0
+	for _, n := range m.running {
+		total += n
+	}
+	return total
+}
+
+// admit decides whether an event of source i may drive the wallpaper, and
+// records it as the last one when it does
+func (m *MultiMonitor) admit(i int, meta domain.MediaMetadata) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.active >= 0 && m.active != i {
+		if sameTrack(meta, m.last) && meta.Status == m.last.Status {
+			return false
+		}
+		if m.last.Status == domain.StatusPlaying && meta.Status != domain.StatusPlaying {
+			return false
+		}
+	}
+	m.active, m.last = i, meta
+	return true
+}
+
+// sameTrack compares tracks by title, artist and album, as backends disagree on track IDs
+func sameTrack(a, b domain.MediaMetadata) bool {
+	return a.Title == b.Title && a.Artist == b.Artist && a.Album == b.Album
+}
@@ -0,0 +1,165 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// fakeMonitor is a backend whose events are sent by the test
+type fakeMonitor struct {
+	events  chan domain.MediaMetadata
+	err     error // Returned by Start instead of blocking
+	stopped bool
+}
+
+func newFakeMonitor() *fakeMonitor {
+	return &fakeMonitor{events: make(chan domain.MediaMetadata, 10)}
+}
+
+func (f *fakeMonitor) Start(ctx context.Context) error {
+	if f.err != nil {
+		return f.err
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (f *fakeMonitor) Stop(ctx context.Context) error {
+	if !f.stopped {
+		f.stopped = true
+		close(f.events)
+	}
+	return nil
+}
+
+func (f *fakeMonitor) Events() <-chan domain.MediaMetadata {
+	return f.events
+}
+
+// lifecycleMonitor is a backend also reporting the player lifecycle events sent by the test
+type lifecycleMonitor struct {
+	*fakeMonitor
+	players chan domain.PlayerEvent
+}
+
+func (l *lifecycleMonitor) PlayerEvents() <-chan domain.PlayerEvent {
+	return l.players
+}
+
+// TestMultiMonitor_PlayerEvents verifies lifecycle events are tagged with their
+// backend and count the players running across every backend
+func TestMultiMonitor_PlayerEvents(t *testing.T) {
+	mpris := &lifecycleMonitor{fakeMonitor: newFakeMonitor(), players: make(chan domain.PlayerEvent, 1)}
+	other := &lifecycleMonitor{fakeMonitor: newFakeMonitor(), players: make(chan domain.PlayerEvent, 1)}
+	mon := NewMultiMonitor(zap.NewNop(), []Source{{Name: "mpris", Monitor: mpris}, {Name: "other", Monitor: other}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go mon.Start(ctx)
+
+	steps := []struct {
+		source  *lifecycleMonitor
+		event   domain.PlayerEvent
+		backend string
+		players int
+	}{
+		{mpris, domain.PlayerEvent{Kind: domain.PlayerAppeared, Players: 2}, "mpris", 2},
+		{other, domain.PlayerEvent{Kind: domain.PlayerAppeared, Players: 1}, "other", 3},
+		{mpris, domain.PlayerEvent{Kind: domain.PlayerVanished, Players: 0}, "mpris", 1},
+		{other, domain.PlayerEvent{Kind: domain.PlayerVanished, Players: 0}, "other", 0},
+	}
+	for i, step := range steps {
+		step.source.players <- step.event
+		select {
+		case ev := <-mon.PlayerEvents():
+			if ev.Backend != step.backend || ev.Players != step.players {
+				t.Errorf("step %d: expected %d players from %s, got %d from %s", i, step.players, step.backend, ev.Players, ev.Backend)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("step %d: no event", i)
+		}
+	}
+}
+
+// TestMultiMonitor verifies events are tagged with their backend, that a playing
+// backend keeps the wallpaper until another one plays, and that the same track
+// reported by a second backend is dropped
+func TestMultiMonitor(t *testing.T) {
+	mpris, other := newFakeMonitor(), newFakeMonitor()
+	mon := NewMultiMonitor(zap.NewNop(), []Source{{Name: "mpris", Monitor: mpris}, {Name: "other", Monitor: other}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- mon.Start(ctx) }()
+
+	expect := func(step, backend, title string) {
+		t.Helper()
+		select {
+		case event := <-mon.Events():
+			if event.Backend != backend || event.Title != title {
+				t.Errorf("%s: expected %q from %s, got %q from %s", step, title, backend, event.Title, event.Backend)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s: no event", step)
+		}
+	}
+	expectNone := func(step string) {
+		t.Helper()
+		select {
+		case event := <-mon.Events():
+			t.Errorf("%s: expected no event, got %q from %s", step, event.Title, event.Backend)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	song := domain.MediaMetadata{Title: "Song", Artist: "Band", Status: domain.StatusPlaying}
+	mpris.events <- song
+	expect("first event", "mpris", "Song")
+
+	duplicate := song
+	duplicate.TrackID = "spotify:track:1"
+	other.events <- duplicate
+	expectNone("same track from another backend")
+
+	other.events <- domain.MediaMetadata{Title: "Paused", Status: domain.StatusPaused}
+	expectNone("paused track while mpris plays")
+
+	other.events <- domain.MediaMetadata{Title: "Other", Status: domain.StatusPlaying}
+	expect("takeover", "other", "Other")
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected Start to end with the context, got %v", err)
+	}
+	if err := mon.Stop(context.Background()); err != nil {
+		t.Fatalf("unexpected stop error: %v", err)
+	}
+	if _, ok := <-mon.Events(); ok {
+		t.Error("expected the events channel to be closed")
+	}
+	if !mpris.stopped || !other.stopped {
+		t.Error("expected every backend to be stopped")
+	}
+}
+
+// TestMultiMonitor_StartFailsWhenAllBackendsFail verifies one failing backend is
+// tolerated but all failing is reported
+func TestMultiMonitor_StartFailsWhenAllBackendsFail(t *testing.T) {
+	first, second := newFakeMonitor(), newFakeMonitor()
+	first.err, second.err = errors.New("no bus"), errors.New("no server")
+	mon := NewMultiMonitor(zap.NewNop(), []Source{{Name: "first", Monitor: first}, {Name: "second", Monitor: second}})
+
+	err := mon.Start(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "first: no bus") || !strings.Contains(err.Error(), "second: no server") {
+		t.Fatalf("expected both failures, got %v", err)
+	}
+	if err := mon.Stop(context.Background()); err != nil {
+		t.Fatalf("unexpected stop error: %v", err)
+	}
+}
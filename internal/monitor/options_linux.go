@@ -0,0 +1,94 @@
+//go:build linux
+// +build linux
+
+package monitor
+
+import (
+	"slices"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/godbus/dbus/v5"
+	"go.uber.org/zap"
+)
+
+// optionProperties are the optional playback settings of the Player interface
+// carried by every event of a player
+var optionProperties = []string{"Volume", "Shuffle", "LoopStatus"}
+
+// playbackOptions holds the last reported playback settings of a player
+type playbackOptions struct {
+	volume  *float64
+	shuffle *bool
+	loop    domain.LoopStatus
+}
+
+// update applies the playback settings found among props, others are kept
+func (o *playbackOptions) update(props map[string]dbus.Variant) {
+	if variant, ok := props["Volume"]; ok {
+		if volume, ok := variant.Value().(float64); ok {
+			volume = max(volume, 0) // The spec treats negative values as 0
+			o.volume = &volume
+		}
+	}
+	if variant, ok := props["Shuffle"]; ok {
+		if shuffle, ok := variant.Value().(bool); ok {
+			o.shuffle = &shuffle
+		}
+	}
+	if variant, ok := props["LoopStatus"]; ok {
+		if loop, ok := variant.Value().(string); ok {
+			switch status := domain.LoopStatus(loop); status {
+			case domain.LoopNone, domain.LoopTrack, domain.LoopPlaylist:
+				o.loop = status
+			}
+		}
+	}
+}
+
+// readOptions queries the playback settings of a player, the ones it does not
+// implement stay unknown
+func (m *MprisMonitor) readOptions(busName string) playbackOptions {
+	props := make(map[string]dbus.Variant, len(optionProperties))
+	for _, property := range optionProperties {
+		variant, err := m.conn.GetProperty(busName, "/org/mpris/MediaPlayer2", "org.mpris.MediaPlayer2.Player."+property)
+		if err != nil {
+			m.logger.Debug("Player property unavailable",
+				zap.String("player", busName),
+				zap.String("property", property),
+				zap.Error(err))
+			continue
+		}
+		props[property] = variant
+	}
+
+	var opts playbackOptions
+	opts.update(props)
+	return opts
+}
+
+// updateOptions records the playback settings changed by a PropertiesChanged
+// signal, they go out with the next event of the player. Players never seen
+// appearing on the bus are not tracked. m.mu is only taken when a setting of a
+// tracked player changed.
+func (m *MprisMonitor) updateOptions(playerName string, changed map[string]dbus.Variant) {
+	changesOptions := slices.ContainsFunc(optionProperties, func(property string) bool {
+		_, ok := changed[property]
+		return ok
+	})
+	if !changesOptions {
+		return
+	}
+	if _, ok := m.snapshot().options[playerName]; !ok {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.updateTable(func(t *playerTable) {
+		// The player may have left since the check
+		if opts, ok := t.options[playerName]; ok {
+			opts.update(changed)
+			t.options[playerName] = opts
+		}
+	})
+}
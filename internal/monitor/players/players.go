@@ -0,0 +1,134 @@
+// Package players decides which MPRIS players may drive the wallpaper.
+package players
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/genricoloni/synest/internal/monitor/quirks"
+)
+
+// pattern matches a bus name, or the player ID derived from it
+type pattern func(s string) bool
+
+// Filter holds the allow and deny patterns. The zero value allows every player.
+type Filter struct {
+	allow []pattern
+	deny  []pattern
+}
+
+// NewFilter compiles allow and deny patterns. A pattern between slashes is a
+// regular expression ("/^chrom(e|ium)/"), anything else is a glob
+// ("org.mpris.MediaPlayer2.firefox.*", "spotify").
+func NewFilter(allow, deny []string) (*Filter, error) {
+	f := &Filter{}
+	var err error
+	if f.allow, err = compile(allow); err != nil {
+		return nil, fmt.Errorf("invalid allowed player: %w", err)
+	}
+	if f.deny, err = compile(deny); err != nil {
+		return nil, fmt.Errorf("invalid denied player: %w", err)
+	}
+	return f, nil
+}
+
+// Validate reports the first invalid pattern of a list
+func Validate(patterns []string) error {
+	_, err := compile(patterns)
+	return err
+}
+
+// mprisPrefix starts the bus name of every MPRIS player
+const mprisPrefix = "org.mpris.MediaPlayer2."
+
+// busNameRe matches the elements of a well-known bus name after mprisPrefix
+var busNameRe = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)*$`)
+
+// BusName returns the MPRIS bus name of a player, given in full
+// ("org.mpris.MediaPlayer2.spotify") or by its ID ("spotify"). Empty stays empty.
+func BusName(player string) (string, error) {
+	if player == "" {
+		return "", nil
+	}
+	name := strings.TrimPrefix(player, mprisPrefix)
+	if !busNameRe.MatchString(name) {
+		return "", fmt.Errorf("%q is not a player bus name", player)
+	}
+	return mprisPrefix + name, nil
+}
+
+// Allowed reports whether a player may be followed. Deny wins over allow, and
+// an empty allow list allows every player that is not denied.
+func (f *Filter) Allowed(busName string) bool {
+	id := quirks.PlayerID(busName)
+	if matchAny(f.deny, busName, id) {
+		return false
+	}
+	return len(f.allow) == 0 || matchAny(f.allow, busName, id)
+}
+
+// Priority ranks players by a list of patterns, most preferred first
+type Priority struct {
+	patterns []pattern
+}
+
+// NewPriority compiles a priority list, with the same patterns as NewFilter
+func NewPriority(list []string) (*Priority, error) {
+	patterns, err := compile(list)
+	if err != nil {
+		return nil, fmt.Errorf("invalid player priority: %w", err)
+	}
+	return &Priority{patterns: patterns}, nil
+}
+
+// Rank returns the position of the first pattern matching a player. Unlisted
+// players rank after every listed one, all equal.
+func (p *Priority) Rank(busName string) int {
+	id := quirks.PlayerID(busName)
+	for i, match := range p.patterns {
+		if match(busName) || match(id) {
+			return i
+		}
+	}
+	return len(p.patterns)
+}
+
+func matchAny(patterns []pattern, busName, id string) bool {
+	for _, match := range patterns {
+		if match(busName) || match(id) {
+			return true
+		}
+	}
+	return false
+}
+
+func compile(values []string) ([]pattern, error) {
+	patterns := make([]pattern, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		if len(value) > 2 && strings.HasPrefix(value, "/") && strings.HasSuffix(value, "/") {
+			re, err := regexp.Compile(value[1 : len(value)-1])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", value, err)
+			}
+			patterns = append(patterns, re.MatchString)
+			continue
+		}
+
+		if _, err := path.Match(value, ""); err != nil {
+			return nil, fmt.Errorf("%s: %w", value, err)
+		}
+		glob := value
+		patterns = append(patterns, func(s string) bool {
+			ok, _ := path.Match(glob, s)
+			return ok
+		})
+	}
+	return patterns, nil
+}
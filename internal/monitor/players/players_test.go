@@ -0,0 +1,99 @@
+package players
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilter_Allowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		allow    []string
+		deny     []string
+		busName  string
+		expected bool
+	}{
+		{"No Rules", nil, nil, "org.mpris.MediaPlayer2.spotify", true},
+		{"Allowed By ID", []string{"spotify"}, nil, "org.mpris.MediaPlayer2.spotify", true},
+		{"Not In Allow List", []string{"spotify"}, nil, "org.mpris.MediaPlayer2.vlc", false},
+		{"Allowed By Glob", []string{"org.mpris.MediaPlayer2.firefox.*"}, nil, "org.mpris.MediaPlayer2.firefox.instance_1_42", true},
+		{"Denied By ID", nil, []string{"firefox"}, "org.mpris.MediaPlayer2.firefox.instance_1_42", false},
+		{"Denied By Regex", nil, []string{"/^org\\.mpris\\.MediaPlayer2\\.chrom(e|ium)/"}, "org.mpris.MediaPlayer2.chromium.instance99", false},
+		{"Regex Does Not Match", nil, []string{"/chrom(e|ium)/"}, "org.mpris.MediaPlayer2.mpv", true},
+		{"Deny Wins Over Allow", []string{"*"}, []string{"firefox"}, "org.mpris.MediaPlayer2.firefox", false},
+		{"Unmapped Unique Name", []string{"spotify"}, nil, ":1.42", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewFilter(tt.allow, tt.deny)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := filter.Allowed(tt.busName); got != tt.expected {
+				t.Errorf("Allowed(%q): expected %v, got %v", tt.busName, tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBusName(t *testing.T) {
+	tests := []struct {
+		player   string
+		expected string
+		valid    bool
+	}{
+		{"", "", true},
+		{"spotify", "org.mpris.MediaPlayer2.spotify", true},
+		{"org.mpris.MediaPlayer2.spotify", "org.mpris.MediaPlayer2.spotify", true},
+		{"vlc.instance42", "org.mpris.MediaPlayer2.vlc.instance42", true},
+		{"firefox.*", "", false},
+		{"org.mpris.MediaPlayer2.", "", false},
+	}
+
+	for _, tt := range tests {
+		got, err := BusName(tt.player)
+		if (err == nil) != tt.valid || got != tt.expected {
+			t.Errorf("BusName(%q): expected %q (valid %v), got %q, %v", tt.player, tt.expected, tt.valid, got, err)
+		}
+	}
+}
+
+func TestNewFilter_InvalidPattern(t *testing.T) {
+	tests := []struct {
+		name  string
+		allow []string
+		deny  []string
+		err   string
+	}{
+		{"Bad Regex", nil, []string{"/chrom(/"}, "invalid denied player: /chrom(/"},
+		{"Bad Glob", []string{"spot[ify"}, nil, "invalid allowed player: spot[ify"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFilter(tt.allow, tt.deny)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestPriority_Rank(t *testing.T) {
+	priority, err := NewPriority([]string{"spotify", "org.mpris.MediaPlayer2.firefox.*"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for busName, expected := range map[string]int{
+		"org.mpris.MediaPlayer2.spotify":              0,
+		"org.mpris.MediaPlayer2.firefox.instance_1_7": 1,
+		"org.mpris.MediaPlayer2.mpv":                  2,
+		"org.mpris.MediaPlayer2.vlc":                  2,
+	} {
+		if rank := priority.Rank(busName); rank != expected {
+			t.Errorf("Rank(%q): expected %d, got %d", busName, expected, rank)
+		}
+	}
+}
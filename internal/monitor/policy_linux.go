@@ -0,0 +1,109 @@
+//go:build linux
+// +build linux
+
+package monitor
+
+import (
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// playerState is the last reported state of a player, by bus name
+type playerState struct {
+	track   string // trackKey of the last reported track
+	playing bool
+}
+
+// admit decides whether an event about to be emitted may drive the wallpaper,
+// and if so sets its position and tracks its player as the one on screen while
+// it plays. It is the only step of an emitted signal taking m.mu.
+func (m *MprisMonitor) admit(busName, playerName string, meta *domain.MediaMetadata) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.follows(busName, playerName, *meta) {
+		return false
+	}
+	meta.Position = m.anchor(busName, *meta)
+	if meta.Status == domain.StatusPlaying && m.active != busName {
+		m.active = busName
+		m.missed = 0
+	}
+	return true
+}
+
+// follows records the state reported by a player and decides whether the event
+// may drive the wallpaper. While the pinned player runs, only it does. Otherwise
+// the active player keeps the wallpaper while it plays, unless another player
+// takes over under the policy: the recent policy hands over to a player that
+// starts playing or changes track, the priority policy only to a better-ranked
+// one (players of the same rank follow the recent policy). Must be called with m.mu held.
+func (m *MprisMonitor) follows(busName, playerName string, meta domain.MediaMetadata) bool {
+	state := playerState{
+		track:   trackKey(meta),
+		playing: meta.Status == domain.StatusPlaying,
+	}
+	prev, known := m.states[busName]
+	if busName != "" {
+		m.states[busName] = state
+	}
+
+	if m.pinnedRunning() {
+		return m.pin.Allowed(playerName)
+	}
+	// Initial metadata is keyed by the well-known name, later signals by the unique one
+	if m.active == "" || busName == "" || m.active == busName || m.active == m.wellKnown(busName) ||
+		!m.states[m.active].playing {
+		return true
+	}
+	if !state.playing {
+		return false
+	}
+	if m.policy == domain.PlayerPolicyPriority {
+		rank, activeRank := m.priority.Rank(playerName), m.priority.Rank(m.wellKnown(m.active))
+		if rank != activeRank {
+			return rank < activeRank
+		}
+	}
+	return !known || prev != state
+}
+
+// pinnedRunning reports whether the pinned player is on the bus
+func (m *MprisMonitor) pinnedRunning() bool {
+	if m.pin == nil {
+		return false
+	}
+	for _, name := range m.snapshot().names {
+		if m.pin.Allowed(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// rank orders the candidates taking over from a demoted or vanished player:
+// the pinned player first, then by priority under the priority policy
+func (m *MprisMonitor) rank(playerName string) int {
+	if m.pin != nil && m.pin.Allowed(playerName) {
+		return -1
+	}
+	if m.policy == domain.PlayerPolicyPriority {
+		return m.priority.Rank(playerName)
+	}
+	return 0
+}
+
+// wellKnown returns the well-known name of a unique bus name
+func (m *MprisMonitor) wellKnown(busName string) string {
+	return m.getPlayerName(busName)
+}
+
+// logIgnored notes an event left out because another player drives the wallpaper
+func (m *MprisMonitor) logIgnored(playerName string) {
+	m.mu.RLock()
+	active := m.wellKnown(m.active)
+	m.mu.RUnlock()
+	m.logger.Debug("Ignoring player, another one drives the wallpaper",
+		zap.String("player", playerName),
+		zap.String("active", active))
+}
@@ -0,0 +1,105 @@
+//go:build linux
+// +build linux
+
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/godbus/dbus/v5"
+	"go.uber.org/zap"
+)
+
+// TestFollows verifies only one player drives the wallpaper while several are
+// playing, under each policy and with a pinned player
+func TestFollows(t *testing.T) {
+	type step struct {
+		sender, title, status string
+		emitted               bool
+	}
+	tests := []struct {
+		name  string
+		cfg   *mockConfig
+		steps []step
+	}{
+		{
+			name: "Recent",
+			cfg:  &mockConfig{policy: domain.PlayerPolicyRecent},
+			steps: []step{
+				{":1.100", "Song", "Playing", true},
+				{":1.200", "Video", "Playing", true},       // Started playing, takes over
+				{":1.100", "Song", "Playing", false},       // Repeats its track, no flapping
+				{":1.100", "Next Song", "Playing", true},   // Changed track, takes over
+				{":1.200", "Video", "Paused", false},       // Not the active player
+				{":1.100", "Next Song", "Paused", true},    // The active player pauses
+				{":1.200", "Other Video", "Playing", true}, // Nobody else is playing
+			},
+		},
+		{
+			name: "Priority",
+			cfg:  &mockConfig{policy: domain.PlayerPolicyPriority, priority: []string{"spotify"}},
+			steps: []step{
+				{":1.200", "Video", "Playing", true},
+				{":1.100", "Song", "Playing", true},         // Ranked first, takes over
+				{":1.200", "Other Video", "Playing", false}, // Ranked lower
+				{":1.100", "Song", "Stopped", true},
+				{":1.200", "Third Video", "Playing", true}, // Spotify stopped playing
+			},
+		},
+		{
+			name: "Pinned",
+			cfg:  &mockConfig{pin: "spotify"},
+			steps: []step{
+				{":1.200", "Video", "Playing", false},
+				{":1.100", "Song", "Paused", true},
+				{":1.200", "Other Video", "Playing", false},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.settleDelays = map[string]time.Duration{"spotify": 0}
+			mon := NewMprisMonitor(zap.NewNop(), tt.cfg, clock.New())
+			mon.conn = &noopDBusClient{}
+			mon.running = true
+			setPlayerNames(mon, map[string]string{
+				":1.100": "org.mpris.MediaPlayer2.spotify",
+				":1.200": "org.mpris.MediaPlayer2.mpv",
+			})
+
+			for i, s := range tt.steps {
+				mon.handleSignal(&dbus.Signal{
+					Name:   "org.freedesktop.DBus.Properties.PropertiesChanged",
+					Sender: s.sender,
+					Body: []interface{}{
+						"org.mpris.MediaPlayer2.Player",
+						map[string]dbus.Variant{
+							"Metadata": dbus.MakeVariant(map[string]dbus.Variant{
+								"xesam:title": dbus.MakeVariant(s.title),
+							}),
+							"PlaybackStatus": dbus.MakeVariant(s.status),
+						},
+						[]string{},
+					},
+				})
+
+				select {
+				case event := <-mon.Events():
+					if !s.emitted {
+						t.Errorf("step %d: unexpected event %+v", i, event)
+					} else if event.Title != s.title {
+						t.Errorf("step %d: expected %q, got %q", i, s.title, event.Title)
+					}
+				default:
+					if s.emitted {
+						t.Errorf("step %d: expected %q from %s to be emitted", i, s.title, s.sender)
+					}
+				}
+			}
+		})
+	}
+}
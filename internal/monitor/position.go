@@ -0,0 +1,35 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+// playback anchors the position of the last reported track: it was at offset
+// at the given time and advances from there while playing
+type playback struct {
+	player  string // Well-known name of the player, empty before the first event
+	track   string // trackKey of the track
+	offset  time.Duration
+	at      time.Time
+	playing bool
+	length  time.Duration
+}
+
+// estimate returns the position at now, never past the end of the track
+func (p playback) estimate(now time.Time) time.Duration {
+	position := p.offset
+	if p.playing {
+		position += now.Sub(p.at)
+	}
+	if p.length > 0 {
+		position = min(position, p.length)
+	}
+	return max(position, 0)
+}
+
+// trackKey identifies a track by its ID, title, artist and album
+func trackKey(meta domain.MediaMetadata) string {
+	return meta.TrackID + "\x00" + meta.Title + "\x00" + meta.Artist + "\x00" + meta.Album
+}
@@ -0,0 +1,125 @@
+//go:build linux
+// +build linux
+
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/godbus/dbus/v5"
+	"go.uber.org/zap"
+)
+
+// Position implements domain.PositionSource from the last emitted event,
+// Seeked signals and position polls
+func (m *MprisMonitor) Position() (position, length time.Duration, ok bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.playback.player == "" {
+		return 0, 0, false
+	}
+	return m.playback.estimate(m.clock.Now()), m.playback.length, true
+}
+
+// anchor moves the position to an event about to be emitted and returns the
+// position of the event: 0 for a new track, the estimate otherwise. Must be
+// called with m.mu held.
+func (m *MprisMonitor) anchor(busName string, meta domain.MediaMetadata) time.Duration {
+	now := m.clock.Now()
+	player, track := m.wellKnown(busName), trackKey(meta)
+	var offset time.Duration
+	if m.playback.player == player && m.playback.track == track && meta.Status != domain.StatusStopped {
+		offset = m.playback.estimate(now)
+	}
+	m.playback = playback{
+		player:  player,
+		track:   track,
+		offset:  offset,
+		at:      now,
+		playing: meta.Status == domain.StatusPlaying,
+		length:  meta.Length,
+	}
+	return offset
+}
+
+// handleSeeked moves the position to the one carried by a Seeked signal of the
+// player on screen. Players only signal jumps, not the regular progress.
+func (m *MprisMonitor) handleSeeked(sig *dbus.Signal) {
+	if len(sig.Body) < 1 {
+		return
+	}
+	position, ok := microseconds(sig.Body[0])
+	if !ok {
+		return
+	}
+	m.setPosition(m.getPlayerName(sig.Sender), position)
+}
+
+// setPosition anchors the position of the player on screen, other players are ignored
+func (m *MprisMonitor) setPosition(player string, position time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.playback.player == "" || m.playback.player != player {
+		return
+	}
+	m.playback.offset = position
+	m.playback.at = m.clock.Now()
+}
+
+// pollPosition reads the position of the playing player on screen every
+// positionInterval until ctx is cancelled, correcting the estimate for drift
+// and seeks of players that do not signal them
+func (m *MprisMonitor) pollPosition(ctx context.Context) {
+	defer m.wg.Done()
+
+	timer := m.clock.NewTimer(m.positionInterval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C():
+			if err := m.refreshPosition(); err != nil {
+				m.logger.Debug("Failed to read playback position", zap.Error(err))
+			}
+			timer.Reset(m.positionInterval)
+		}
+	}
+}
+
+// refreshPosition reads the Position property of the player on screen while it plays
+func (m *MprisMonitor) refreshPosition() error {
+	m.mu.RLock()
+	player, playing := m.playback.player, m.playback.playing
+	m.mu.RUnlock()
+	if player == "" || !playing {
+		return nil
+	}
+
+	variant, err := m.conn.GetProperty(player, "/org/mpris/MediaPlayer2", "org.mpris.MediaPlayer2.Player.Position")
+	if err != nil {
+		return fmt.Errorf("failed to get position of %s: %w", player, err)
+	}
+	position, ok := microseconds(variant.Value())
+	if !ok {
+		return fmt.Errorf("invalid position format from %s", player)
+	}
+	m.setPosition(player, position)
+	return nil
+}
+
+// microseconds converts an MPRIS time in microseconds, players disagree on the integer type
+func microseconds(value any) (time.Duration, bool) {
+	switch v := value.(type) {
+	case int64:
+		return time.Duration(min(max(v, 0), math.MaxInt64/int64(time.Microsecond))) * time.Microsecond, true
+	case uint64:
+		return time.Duration(min(v, math.MaxInt64/uint64(time.Microsecond))) * time.Microsecond, true
+	}
+	return 0, false
+}
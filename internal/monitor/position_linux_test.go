@@ -0,0 +1,86 @@
+//go:build linux
+// +build linux
+
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/godbus/dbus/v5"
+	"go.uber.org/zap"
+)
+
+// TestPosition verifies the position advances while playing, freezes on pause,
+// follows Seeked signals and position polls, and restarts with a new track
+func TestPosition(t *testing.T) {
+	clk := clock.NewFake(time.Unix(0, 0))
+	client := &positionDBusClient{position: 90 * time.Second}
+	mon := NewMprisMonitor(zap.NewNop(), &mockConfig{}, clk)
+	mon.conn = client
+	setPlayerNames(mon, map[string]string{":1.1": "org.mpris.MediaPlayer2.spotify"})
+
+	expect := func(step string, want time.Duration) {
+		t.Helper()
+		got, length, ok := mon.Position()
+		if !ok || got != want || length != 3*time.Minute {
+			t.Errorf("%s: expected %s of 3m0s, got %s of %s (ok=%v)", step, want, got, length, ok)
+		}
+	}
+	track := domain.MediaMetadata{Title: "Song", Length: 3 * time.Minute, Status: domain.StatusPlaying}
+
+	if _, _, ok := mon.Position(); ok {
+		t.Error("expected no position before the first event")
+	}
+
+	mon.emit(":1.1", "org.mpris.MediaPlayer2.spotify", track)
+	if event := <-mon.Events(); event.Position != 0 {
+		t.Errorf("expected a new track to start at 0, got %s", event.Position)
+	}
+	clk.Advance(10 * time.Second)
+	expect("playing", 10*time.Second)
+
+	paused := track
+	paused.Status = domain.StatusPaused
+	mon.emit(":1.1", "org.mpris.MediaPlayer2.spotify", paused)
+	if event := <-mon.Events(); event.Position != 10*time.Second {
+		t.Errorf("expected the paused event at 10s, got %s", event.Position)
+	}
+	clk.Advance(time.Minute)
+	expect("paused", 10*time.Second)
+
+	mon.emit(":1.1", "org.mpris.MediaPlayer2.spotify", track)
+	<-mon.Events()
+	mon.handleSeeked(&dbus.Signal{Sender: ":1.1", Body: []interface{}{int64(time.Minute / time.Microsecond)}})
+	clk.Advance(5 * time.Second)
+	expect("seeked", 65*time.Second)
+
+	if err := mon.refreshPosition(); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	expect("polled", 90*time.Second)
+
+	clk.Advance(10 * time.Minute)
+	expect("ended", 3*time.Minute)
+
+	next := track
+	next.Title = "Next"
+	mon.emit(":1.1", "org.mpris.MediaPlayer2.spotify", next)
+	<-mon.Events()
+	expect("next track", 0)
+}
+
+// positionDBusClient reports a fixed playback position
+type positionDBusClient struct {
+	noopDBusClient
+	position time.Duration
+}
+
+func (p *positionDBusClient) GetProperty(_, _, property string) (dbus.Variant, error) {
+	if property == "org.mpris.MediaPlayer2.Player.Position" {
+		return dbus.MakeVariant(int64(p.position / time.Microsecond)), nil
+	}
+	return p.noopDBusClient.GetProperty("", "", property)
+}
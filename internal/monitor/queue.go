@@ -0,0 +1,162 @@
+package monitor
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// maxWaitingPlayers bounds the players with an event waiting for the consumer
+const maxWaitingPlayers = 10
+
+// waiting is the latest event of a player not handed to the consumer yet
+type waiting struct {
+	key  string
+	meta domain.MediaMetadata
+	seq  uint64 // Changes whenever a newer event replaces meta
+}
+
+// eventQueue hands the events of a monitor to the consumer without ever
+// blocking the monitor. While the consumer is busy, each player keeps only its
+// latest event: an older one still waiting is replaced (coalesced), and once
+// maxWaitingPlayers players wait the oldest event is dropped.
+type eventQueue struct {
+	logger *zap.Logger
+	out    chan domain.MediaMetadata // Holds one event, later ones wait in pending
+	wake   chan struct{}             // Signaled when pending changes
+	done   chan struct{}             // Closed by close, ends the pump
+
+	mu      sync.Mutex
+	pending []waiting // Oldest first, one per player
+	seq     uint64
+	closed  bool
+
+	coalesced atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+// newEventQueue creates a queue and starts handing its events over
+func newEventQueue(logger *zap.Logger) *eventQueue {
+	q := &eventQueue{
+		logger: logger,
+		out:    make(chan domain.MediaMetadata, 1),
+		wake:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	go q.pump()
+	return q
+}
+
+// Events returns the channel delivering the events, closed after close
+func (q *eventQueue) Events() <-chan domain.MediaMetadata {
+	return q.out
+}
+
+// EventStats returns how many events were coalesced and dropped so far
+func (q *eventQueue) EventStats() domain.EventStats {
+	return domain.EventStats{Coalesced: q.coalesced.Load(), Dropped: q.dropped.Load()}
+}
+
+// push queues an event. It is handed over right away when nothing waits and
+// the consumer has room, otherwise it replaces the waiting event of its player.
+func (q *eventQueue) push(meta domain.MediaMetadata) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return
+	}
+
+	if len(q.pending) == 0 {
+		select {
+		case q.out <- meta:
+			return
+		default:
+		}
+	}
+
+	key := queueKey(meta)
+	for i, w := range q.pending {
+		if w.key == key {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			q.coalesced.Add(1)
+			q.logger.Debug("Replacing a waiting event with a newer one of the same player",
+				zap.String("player", meta.Player))
+			break
+		}
+	}
+	if len(q.pending) >= maxWaitingPlayers {
+		q.pending = q.pending[1:]
+		q.logger.Warn("Too many players waiting for the consumer, dropping the oldest event",
+			zap.Uint64("dropped", q.dropped.Add(1)))
+	}
+	q.seq++
+	q.pending = append(q.pending, waiting{key: key, meta: meta, seq: q.seq})
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// close stops the queue: waiting events are discarded and the channel closed.
+// It must be called once the monitor stopped pushing.
+func (q *eventQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return
+	}
+	q.closed = true
+	close(q.done)
+}
+
+// pump hands the oldest waiting event over whenever the consumer has room. An
+// event replaced while the pump waits is taken again from pending.
+func (q *eventQueue) pump() {
+	defer func() {
+		q.mu.Lock()
+		close(q.out)
+		q.mu.Unlock()
+	}()
+
+	for {
+		q.mu.Lock()
+		if len(q.pending) == 0 {
+			q.mu.Unlock()
+			select {
+			case <-q.wake:
+				continue
+			case <-q.done:
+				return
+			}
+		}
+		next := q.pending[0]
+		q.mu.Unlock()
+
+		select {
+		case q.out <- next.meta:
+			q.mu.Lock()
+			for i, w := range q.pending {
+				if w.seq == next.seq {
+					q.pending = append(q.pending[:i], q.pending[i+1:]...)
+					break
+				}
+			}
+			q.mu.Unlock()
+		case <-q.wake:
+		case <-q.done:
+			return
+		}
+	}
+}
+
+// queueKey identifies the player of an event, per backend when several run
+func queueKey(meta domain.MediaMetadata) string {
+	player := meta.PlayerName
+	if player == "" {
+		player = meta.Player
+	}
+	return meta.Backend + "\x00" + player
+}
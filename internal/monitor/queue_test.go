@@ -0,0 +1,97 @@
+package monitor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// receive takes the next event of q, failing the test when none arrives
+func receive(t *testing.T, q *eventQueue) domain.MediaMetadata {
+	t.Helper()
+	select {
+	case meta := <-q.Events():
+		return meta
+	case <-time.After(time.Second):
+		t.Fatal("no event")
+		return domain.MediaMetadata{}
+	}
+}
+
+// TestEventQueue_Coalesce verifies a busy consumer gets only the latest event
+// of each player once it reads again, in the order the players last changed
+func TestEventQueue_Coalesce(t *testing.T) {
+	q := newEventQueue(zap.NewNop())
+	defer q.close()
+
+	q.push(domain.MediaMetadata{PlayerName: "spotify", Title: "First"})
+	q.push(domain.MediaMetadata{PlayerName: "vlc", Title: "Video"})
+	q.push(domain.MediaMetadata{PlayerName: "spotify", Title: "Skipped"})
+	q.push(domain.MediaMetadata{PlayerName: "spotify", Title: "Latest"})
+
+	for _, want := range []string{"First", "Video", "Latest"} {
+		if got := receive(t, q).Title; got != want {
+			t.Errorf("expected %s, got %s", want, got)
+		}
+	}
+	if stats := q.EventStats(); stats.Coalesced != 1 || stats.Dropped != 0 {
+		t.Errorf("expected 1 coalesced and none dropped, got %+v", stats)
+	}
+}
+
+// TestEventQueue_Drop verifies the oldest waiting event is dropped once too many
+// players wait
+func TestEventQueue_Drop(t *testing.T) {
+	q := newEventQueue(zap.NewNop())
+	defer q.close()
+
+	q.push(domain.MediaMetadata{PlayerName: "first"})
+	for i := range maxWaitingPlayers + 1 {
+		q.push(domain.MediaMetadata{PlayerName: fmt.Sprintf("player%d", i)})
+	}
+
+	if got := receive(t, q).PlayerName; got != "first" {
+		t.Errorf("expected the event handed over first, got %s", got)
+	}
+	// The pump may already hold the dropped event, the others follow in order
+	last := fmt.Sprintf("player%d", maxWaitingPlayers)
+	for i := 0; ; i++ {
+		got := receive(t, q).PlayerName
+		if got == last {
+			break
+		}
+		if i == maxWaitingPlayers {
+			t.Fatalf("expected %s last, got %s", last, got)
+		}
+	}
+	if stats := q.EventStats(); stats.Dropped != 1 {
+		t.Errorf("expected 1 dropped event, got %d", stats.Dropped)
+	}
+}
+
+// TestEventQueue_Close verifies the channel is closed and later events ignored
+func TestEventQueue_Close(t *testing.T) {
+	q := newEventQueue(zap.NewNop())
+	q.push(domain.MediaMetadata{PlayerName: "spotify"})
+	q.push(domain.MediaMetadata{PlayerName: "vlc"})
+	q.close()
+	q.push(domain.MediaMetadata{PlayerName: "mpv"})
+
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case meta, ok := <-q.Events():
+			if !ok {
+				return
+			}
+			if meta.PlayerName == "mpv" {
+				t.Error("expected no event after close")
+			}
+		case <-deadline:
+			t.Fatal("expected the events channel to be closed")
+		}
+	}
+}
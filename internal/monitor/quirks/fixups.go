@@ -0,0 +1,92 @@
+package quirks
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+var (
+	// featuringPattern splits a featured-artist suffix: "Artist feat. Other", "Artist ft. Other"
+	featuringPattern = regexp.MustCompile(`(?i)^(.+?)\s+(?:feat\.?|ft\.|featuring)\s+(.+)$`)
+
+	// videoSuffixPattern matches video-site decorations: "Song (Official Video)", "Song [Lyrics]"
+	videoSuffixPattern = regexp.MustCompile(`(?i)\s*[(\[](?:official\s+)?(?:music\s+|lyric\s+)?(?:video|audio|visualizer|lyrics)[)\]]`)
+
+	// audioExtensions are stripped when players fall back to the file name as title
+	audioExtensions = map[string]bool{
+		".mp3": true, ".flac": true, ".ogg": true, ".opus": true, ".m4a": true,
+		".wav": true, ".aac": true, ".wma": true, ".mka": true, ".webm": true, ".mp4": true,
+	}
+)
+
+// splitFeaturing keeps only the main artist in Artist when featured artists are
+// appended to it, moving the featured ones to Artists
+func splitFeaturing(meta domain.MediaMetadata) domain.MediaMetadata {
+	match := featuringPattern.FindStringSubmatch(meta.Artist)
+	if match == nil {
+		return meta
+	}
+
+	meta.Artist = match[1]
+	if len(meta.Artists) <= 1 {
+		meta.Artists = []string{match[1], match[2]}
+	}
+	return meta
+}
+
+// stripVideoSuffix removes "(Official Video)" style decorations from the title
+func stripVideoSuffix(meta domain.MediaMetadata) domain.MediaMetadata {
+	if title := strings.TrimSpace(videoSuffixPattern.ReplaceAllString(meta.Title, "")); title != "" {
+		meta.Title = title
+	}
+	return meta
+}
+
+// stripFileExtension cleans titles that are really file names ("01 Song.flac")
+func stripFileExtension(meta domain.MediaMetadata) domain.MediaMetadata {
+	ext := path.Ext(meta.Title)
+	if audioExtensions[strings.ToLower(ext)] && len(ext) < len(meta.Title) {
+		meta.Title = strings.TrimSuffix(meta.Title, ext)
+	}
+	return meta
+}
+
+// rewriteSpotifyArt points legacy open.spotify.com image links, which no longer
+// serve images, to the Spotify CDN
+func rewriteSpotifyArt(meta domain.MediaMetadata) domain.MediaMetadata {
+	if id, ok := strings.CutPrefix(meta.ArtUrl, "https://open.spotify.com/image/"); ok && id != "" {
+		meta.ArtUrl = "https://i.scdn.co/image/" + id
+	}
+	return meta
+}
+
+// youtubeThumbnail derives artwork for YouTube streams that are played without an artUrl
+func youtubeThumbnail(meta domain.MediaMetadata) domain.MediaMetadata {
+	if meta.ArtUrl != "" {
+		return meta
+	}
+	if id := youtubeVideoID(meta.URL); id != "" {
+		meta.ArtUrl = "https://i.ytimg.com/vi/" + url.PathEscape(id) + "/hqdefault.jpg"
+	}
+	return meta
+}
+
+// youtubeVideoID extracts the video ID from youtube.com/watch?v= and youtu.be links
+func youtubeVideoID(mediaURL string) string {
+	u, err := url.Parse(mediaURL)
+	if err != nil {
+		return ""
+	}
+
+	switch strings.TrimPrefix(u.Host, "www.") {
+	case "youtube.com", "m.youtube.com", "music.youtube.com":
+		return u.Query().Get("v")
+	case "youtu.be":
+		return strings.Trim(u.Path, "/")
+	}
+	return ""
+}
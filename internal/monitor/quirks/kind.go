@@ -0,0 +1,39 @@
+package quirks
+
+import (
+	"strings"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+// AdTitle is the title Spotify reports for ads, with no artist
+const AdTitle = "Advertisement"
+
+// KindOf classifies the media of an event: ads by their Spotify track ID or
+// their title without an artist, podcasts by their track ID, URL or genre
+func KindOf(meta domain.MediaMetadata) string {
+	id := spotifyURI(meta.TrackID)
+	switch {
+	case strings.HasPrefix(id, "spotify:ad:"),
+		meta.Artist == "" && strings.EqualFold(strings.TrimSpace(meta.Title), AdTitle):
+		return domain.KindAd
+	case strings.HasPrefix(id, "spotify:episode:"),
+		SourceOf(meta.URL) == "spotify" && strings.Contains(meta.URL, "/episode/"):
+		return domain.KindPodcast
+	}
+	for _, genre := range meta.Genres {
+		if strings.Contains(strings.ToLower(genre), "podcast") {
+			return domain.KindPodcast
+		}
+	}
+	return domain.KindMusic
+}
+
+// spotifyURI turns the MPRIS track IDs of Spotify ("/com/spotify/ad/1f2e")
+// into Spotify URIs ("spotify:ad:1f2e"), other IDs are returned as they are
+func spotifyURI(trackID string) string {
+	if path, ok := strings.CutPrefix(trackID, "/com/spotify/"); ok {
+		return "spotify:" + strings.ReplaceAll(path, "/", ":")
+	}
+	return trackID
+}
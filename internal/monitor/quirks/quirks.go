@@ -0,0 +1,113 @@
+// Package quirks contains player-specific workarounds for MPRIS players that
+// send incomplete, noisy or stale metadata.
+package quirks
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+// None disables quirks for a player when used as adapter name in the configuration
+const None = "none"
+
+// Fixup rewrites metadata reported by a player
+type Fixup func(meta domain.MediaMetadata) domain.MediaMetadata
+
+// Adapter groups the workarounds applied to one family of players
+type Adapter struct {
+	Name        string
+	SettleDelay time.Duration // Wait before trusting a metadata change (stale artwork)
+	Fixups      []Fixup
+}
+
+// Apply runs the adapter's fix-ups in order
+func (a Adapter) Apply(meta domain.MediaMetadata) domain.MediaMetadata {
+	for _, fixup := range a.Fixups {
+		meta = fixup(meta)
+	}
+	return meta
+}
+
+// builtin lists the known adapters, keyed by name
+var builtin = map[string]Adapter{
+	"spotify": {
+		Name: "spotify",
+		// Spotify sends the previous track's artUrl first and corrects it about a second later
+		SettleDelay: time.Second,
+		Fixups:      []Fixup{rewriteSpotifyArt, splitFeaturing},
+	},
+	"vlc": {
+		Name:   "vlc",
+		Fixups: []Fixup{stripFileExtension, splitFeaturing},
+	},
+	"firefox": {
+		Name:   "firefox",
+		Fixups: []Fixup{stripVideoSuffix, splitFeaturing},
+	},
+	"mpv": {
+		Name:   "mpv",
+		Fixups: []Fixup{stripFileExtension, stripVideoSuffix, youtubeThumbnail, splitFeaturing},
+	},
+}
+
+// Registry resolves the adapter to use for each player
+type Registry struct {
+	selection       map[string]string        // Player ID -> adapter name
+	settleOverrides map[string]time.Duration // Player ID -> settle delay
+}
+
+// NewRegistry creates a registry. By default a player uses the adapter with the same
+// name as its ID; selection maps player IDs to another adapter (or None), and
+// settleOverrides replaces the settle delay of the selected adapter.
+func NewRegistry(selection map[string]string, settleOverrides map[string]time.Duration) (*Registry, error) {
+	for player, name := range selection {
+		if _, ok := builtin[name]; !ok && name != None {
+			return nil, fmt.Errorf("unknown quirks adapter %q for player %s (available: %s)",
+				name, player, strings.Join(Names(), ", "))
+		}
+	}
+
+	return &Registry{
+		selection:       selection,
+		settleOverrides: settleOverrides,
+	}, nil
+}
+
+// For returns the adapter for an MPRIS bus name. Players without quirks get a
+// no-op adapter.
+func (r *Registry) For(busName string) Adapter {
+	id := PlayerID(busName)
+
+	name, ok := r.selection[id]
+	if !ok {
+		name = id
+	}
+
+	adapter := builtin[name] // Zero value is a no-op adapter
+	if delay, ok := r.settleOverrides[id]; ok {
+		adapter.SettleDelay = delay
+	}
+	return adapter
+}
+
+// Names returns the names of the built-in adapters, sorted
+func Names() []string {
+	names := make([]string, 0, len(builtin))
+	for name := range builtin {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PlayerID extracts the lowercase player identifier from an MPRIS bus name
+// (org.mpris.MediaPlayer2.vlc.instance42 -> "vlc")
+func PlayerID(busName string) string {
+	id := strings.TrimPrefix(busName, "org.mpris.MediaPlayer2.")
+	id, _, _ = strings.Cut(id, ".")
+	return strings.ToLower(id)
+}
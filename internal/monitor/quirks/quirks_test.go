@@ -0,0 +1,169 @@
+package quirks
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+func TestRegistry_For(t *testing.T) {
+	registry, err := NewRegistry(
+		map[string]string{"chromium": "firefox", "vlc": None},
+		map[string]time.Duration{"spotify": 250 * time.Millisecond, "chromium": time.Second},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		busName     string
+		adapter     string
+		settleDelay time.Duration
+	}{
+		{"org.mpris.MediaPlayer2.spotify", "spotify", 250 * time.Millisecond},
+		{"org.mpris.MediaPlayer2.chromium.instance1234", "firefox", time.Second},
+		{"org.mpris.MediaPlayer2.firefox.instance_1_42", "firefox", 0},
+		{"org.mpris.MediaPlayer2.vlc", "", 0},
+		{"org.mpris.MediaPlayer2.rhythmbox", "", 0},
+		{":1.100", "", 0},
+	}
+
+	for _, tt := range tests {
+		adapter := registry.For(tt.busName)
+		if adapter.Name != tt.adapter {
+			t.Errorf("%s: expected adapter %q, got %q", tt.busName, tt.adapter, adapter.Name)
+		}
+		if adapter.SettleDelay != tt.settleDelay {
+			t.Errorf("%s: expected settle delay %v, got %v", tt.busName, tt.settleDelay, adapter.SettleDelay)
+		}
+	}
+}
+
+func TestNewRegistry_UnknownAdapter(t *testing.T) {
+	_, err := NewRegistry(map[string]string{"spotify": "winamp"}, nil)
+	if err == nil || !strings.Contains(err.Error(), `unknown quirks adapter "winamp"`) {
+		t.Fatalf("expected unknown adapter error, got %v", err)
+	}
+}
+
+func TestAdapters_Apply(t *testing.T) {
+	tests := []struct {
+		name     string
+		adapter  string
+		input    domain.MediaMetadata
+		expected domain.MediaMetadata
+	}{
+		{
+			name:     "Spotify - Legacy Art URL And Featuring",
+			adapter:  "spotify",
+			input:    domain.MediaMetadata{Artist: "Daft Punk feat. Pharrell Williams", ArtUrl: "https://open.spotify.com/image/ab67616d"},
+			expected: domain.MediaMetadata{Artist: "Daft Punk", Artists: []string{"Daft Punk", "Pharrell Williams"}, ArtUrl: "https://i.scdn.co/image/ab67616d"},
+		},
+		{
+			name:     "Spotify - CDN Art URL Untouched",
+			adapter:  "spotify",
+			input:    domain.MediaMetadata{Artist: "Queen", ArtUrl: "https://i.scdn.co/image/ab67616d"},
+			expected: domain.MediaMetadata{Artist: "Queen", ArtUrl: "https://i.scdn.co/image/ab67616d"},
+		},
+		{
+			name:     "Firefox - Official Video",
+			adapter:  "firefox",
+			input:    domain.MediaMetadata{Title: "Never Gonna Give You Up (Official Music Video)", Artist: "Rick Astley"},
+			expected: domain.MediaMetadata{Title: "Never Gonna Give You Up", Artist: "Rick Astley"},
+		},
+		{
+			name:     "Firefox - Lyrics And Ft",
+			adapter:  "firefox",
+			input:    domain.MediaMetadata{Title: "Stay [Lyrics]", Artist: "Rihanna ft. Mikky Ekko"},
+			expected: domain.MediaMetadata{Title: "Stay", Artist: "Rihanna", Artists: []string{"Rihanna", "Mikky Ekko"}},
+		},
+		{
+			name:     "VLC - File Name Title",
+			adapter:  "vlc",
+			input:    domain.MediaMetadata{Title: "01 - Intro.FLAC"},
+			expected: domain.MediaMetadata{Title: "01 - Intro"},
+		},
+		{
+			name:     "VLC - Dotted Title Untouched",
+			adapter:  "vlc",
+			input:    domain.MediaMetadata{Title: "Mr. Blue Sky"},
+			expected: domain.MediaMetadata{Title: "Mr. Blue Sky"},
+		},
+		{
+			name:     "MPV - YouTube Thumbnail",
+			adapter:  "mpv",
+			input:    domain.MediaMetadata{Title: "Song (Official Audio)", URL: "https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=1"},
+			expected: domain.MediaMetadata{Title: "Song", URL: "https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=1", ArtUrl: "https://i.ytimg.com/vi/dQw4w9WgXcQ/hqdefault.jpg"},
+		},
+		{
+			name:     "MPV - Short Link",
+			adapter:  "mpv",
+			input:    domain.MediaMetadata{URL: "https://youtu.be/dQw4w9WgXcQ"},
+			expected: domain.MediaMetadata{URL: "https://youtu.be/dQw4w9WgXcQ", ArtUrl: "https://i.ytimg.com/vi/dQw4w9WgXcQ/hqdefault.jpg"},
+		},
+		{
+			name:     "MPV - Existing Art Kept",
+			adapter:  "mpv",
+			input:    domain.MediaMetadata{URL: "https://youtu.be/x", ArtUrl: "file:///tmp/cover.jpg"},
+			expected: domain.MediaMetadata{URL: "https://youtu.be/x", ArtUrl: "file:///tmp/cover.jpg"},
+		},
+		{
+			name:     "Decoration Only Title Kept",
+			adapter:  "firefox",
+			input:    domain.MediaMetadata{Title: "(Official Video)"},
+			expected: domain.MediaMetadata{Title: "(Official Video)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := builtin[tt.adapter].Apply(tt.input); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSourceOf(t *testing.T) {
+	tests := map[string]string{
+		"spotify:track:4uLU6hMCjMI75M1A2tKUQC":              "spotify",
+		"https://open.spotify.com/track/4uLU6hMCjMI75M1A2t": "spotify",
+		"https://artist.bandcamp.com/track/song":            "bandcamp",
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ":       "youtube",
+		"https://music.youtube.com/watch?v=dQw4w9WgXcQ":     "youtube",
+		"file:///home/user/Music/song.flac":                 "file",
+		"https://www.example.org/stream.mp3":                "example.org",
+		"":                                                  "",
+		"rtsp://radio.example/live":                         "",
+	}
+	for mediaURL, expected := range tests {
+		if got := SourceOf(mediaURL); got != expected {
+			t.Errorf("SourceOf(%q): expected %q, got %q", mediaURL, expected, got)
+		}
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		meta     domain.MediaMetadata
+		expected string
+	}{
+		{"Track", domain.MediaMetadata{TrackID: "/com/spotify/track/4uLU6hMCjMI75M1A2tKUQC", Title: "Song", Artist: "Band"}, domain.KindMusic},
+		{"AdPath", domain.MediaMetadata{TrackID: "/com/spotify/ad/1f2e3d", Title: "Spotify", Artist: "Spotify"}, domain.KindAd},
+		{"AdURI", domain.MediaMetadata{TrackID: "spotify:ad:1f2e3d"}, domain.KindAd},
+		{"AdTitle", domain.MediaMetadata{Title: "Advertisement"}, domain.KindAd},
+		{"SongTitledAdvertisement", domain.MediaMetadata{Title: "Advertisement", Artist: "Band"}, domain.KindMusic},
+		{"EpisodePath", domain.MediaMetadata{TrackID: "/com/spotify/episode/5Xt5DXGzch68nYYamXrNxZ"}, domain.KindPodcast},
+		{"EpisodeURL", domain.MediaMetadata{URL: "https://open.spotify.com/episode/5Xt5DXGzch68nYYamXrNxZ"}, domain.KindPodcast},
+		{"Genre", domain.MediaMetadata{Title: "Episode 12", Genres: []string{"Podcast"}}, domain.KindPodcast},
+	}
+	for _, tt := range tests {
+		if got := KindOf(tt.meta); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package quirks
+
+import (
+	"net/url"
+	"strings"
+)
+
+// sourceHosts maps the hosts of well-known services to their source name.
+// Subdomains match too, so artist pages like name.bandcamp.com are covered.
+var sourceHosts = map[string]string{
+	"spotify.com":    "spotify",
+	"bandcamp.com":   "bandcamp",
+	"youtube.com":    "youtube",
+	"youtu.be":       "youtube",
+	"soundcloud.com": "soundcloud",
+	"deezer.com":     "deezer",
+	"tidal.com":      "tidal",
+	"twitch.tv":      "twitch",
+}
+
+// SourceOf names the origin of a media URL (xesam:url): a service such as
+// "spotify" or "bandcamp", "file" for local files, or else the host without
+// "www.". Returns an empty string when the URL is empty or unparseable.
+func SourceOf(mediaURL string) string {
+	u, err := url.Parse(strings.TrimSpace(mediaURL))
+	if err != nil {
+		return ""
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "spotify":
+		return "spotify"
+	case "file":
+		return "file"
+	case "http", "https":
+	default:
+		return ""
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	for domain, source := range sourceHosts {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return source
+		}
+	}
+	return host
+}
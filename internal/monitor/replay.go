@@ -0,0 +1,302 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/paths"
+	"github.com/genricoloni/synest/internal/privacy"
+	"go.uber.org/zap"
+)
+
+const (
+	maxRecordLine = 1 << 20     // Longest line of a recording, in bytes
+	maxReplayWait = time.Minute // Longest wait between two replayed events, bridging the gaps between recording sessions
+)
+
+// recordedEvent is one line of a recording
+type recordedEvent struct {
+	At    time.Time            `json:"at"`
+	Event domain.MediaMetadata `json:"event"`
+}
+
+// Recorder appends every event of a monitor to a JSONL file before handing it
+// on, for the replay backend to play back. Events of private tracks are not
+// recorded.
+type Recorder struct {
+	logger    *zap.Logger
+	cfg       domain.Config
+	clock     clock.Clock
+	mon       domain.Monitor
+	file      *os.File
+	events    *eventQueue
+	forwarded chan struct{} // Closed once the events of mon were all handed on
+	once      sync.Once
+}
+
+// NewRecorder records the events of mon to the file of SYNEST_MONITOR_RECORD,
+// appending to an existing recording
+func NewRecorder(logger *zap.Logger, cfg domain.Config, clk clock.Clock, mon domain.Monitor) (*Recorder, error) {
+	path := cfg.GetMonitorRecord()
+	if err := paths.Ensure(filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("creating the recording directory: %w", err)
+	}
+	// Recordings hold the listening history, like the state directory
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("opening the recording: %w", err)
+	}
+
+	r := &Recorder{
+		logger:    logger,
+		cfg:       cfg,
+		clock:     clk,
+		mon:       mon,
+		file:      file,
+		events:    newEventQueue(logger),
+		forwarded: make(chan struct{}),
+	}
+	go r.forward()
+	logger.Info("Recording monitor events", zap.String("path", path))
+	return r, nil
+}
+
+// forward records the events of the monitor and hands them on until its
+// channel is closed
+func (r *Recorder) forward() {
+	defer close(r.forwarded)
+	enc := json.NewEncoder(r.file)
+	for meta := range r.mon.Events() {
+		if privacy.ModeFor(r.cfg, meta) == domain.PrivateOff {
+			if err := enc.Encode(recordedEvent{At: r.clock.Now(), Event: meta}); err != nil {
+				r.logger.Warn("Failed to record monitor event", zap.Error(err))
+			}
+		}
+		r.events.push(meta)
+	}
+}
+
+// Start runs the recorded monitor until ctx is cancelled
+func (r *Recorder) Start(ctx context.Context) error {
+	return r.mon.Start(ctx)
+}
+
+// Stop stops the recorded monitor, then closes the events channel and the recording
+func (r *Recorder) Stop(ctx context.Context) error {
+	var err error
+	r.once.Do(func() {
+		err = r.mon.Stop(ctx)
+		<-r.forwarded
+		r.events.close()
+		err = errors.Join(err, r.file.Close())
+	})
+	return err
+}
+
+// Events returns a read-only channel that emits the MediaMetadata of the recorded monitor
+func (r *Recorder) Events() <-chan domain.MediaMetadata {
+	return r.events.Events()
+}
+
+// Ready implements domain.Readier with the recorded monitor
+func (r *Recorder) Ready(ctx context.Context) error {
+	if ready, ok := r.mon.(domain.Readier); ok {
+		return ready.Ready(ctx)
+	}
+	return nil
+}
+
+// PlayerEvents implements domain.PlayerEventSource with the recorded monitor,
+// the channel never delivers when it reports no lifecycle events
+func (r *Recorder) PlayerEvents() <-chan domain.PlayerEvent {
+	if src, ok := r.mon.(domain.PlayerEventSource); ok {
+		return src.PlayerEvents()
+	}
+	return nil
+}
+
+// EventStats implements domain.EventCounter with the recorded monitor
+func (r *Recorder) EventStats() domain.EventStats {
+	stats := r.events.EventStats()
+	if counter, ok := r.mon.(domain.EventCounter); ok {
+		inner := counter.EventStats()
+		stats.Coalesced += inner.Coalesced
+		stats.Dropped += inner.Dropped
+	}
+	return stats
+}
+
+// Position implements domain.PositionSource with the recorded monitor
+func (r *Recorder) Position() (position, length time.Duration, ok bool) {
+	if src, found := r.mon.(domain.PositionSource); found {
+		return src.Position()
+	}
+	return 0, 0, false
+}
+
+// Control implements domain.PlayerController with the recorded monitor
+func (r *Recorder) Control(ctx context.Context, command string) error {
+	return NewPlayerController(r.mon).Control(ctx, command)
+}
+
+// ReplayMonitor plays a recording back as if its events just happened, for
+// debugging user reports and demos without a running player. The waits between
+// events are shortened by the replay speed.
+type ReplayMonitor struct {
+	logger    *zap.Logger
+	clock     clock.Clock
+	speed     int // Times faster than recorded, 0 plays without waiting
+	recording []recordedEvent
+	events    *eventQueue
+
+	mu      sync.Mutex
+	running bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewReplayMonitor loads the recording of SYNEST_MONITOR_REPLAY
+func NewReplayMonitor(logger *zap.Logger, cfg domain.Config, clk clock.Clock) (*ReplayMonitor, error) {
+	path := cfg.GetMonitorReplay()
+	if path == "" {
+		return nil, errors.New("the replay monitor needs SYNEST_MONITOR_REPLAY")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening the recording: %w", err)
+	}
+	defer f.Close()
+
+	recording, err := loadRecording(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &ReplayMonitor{
+		logger:    logger,
+		clock:     clk,
+		speed:     cfg.GetReplaySpeed(),
+		recording: recording,
+		events:    newEventQueue(logger),
+	}, nil
+}
+
+// loadRecording reads a recording, one event per line
+func loadRecording(r io.Reader) ([]recordedEvent, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxRecordLine)
+
+	var recording []recordedEvent
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec recordedEvent
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		recording = append(recording, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(recording) == 0 {
+		return nil, errors.New("no events recorded")
+	}
+	return recording, nil
+}
+
+// Start plays the recording back, then waits until ctx is cancelled
+func (m *ReplayMonitor) Start(ctx context.Context) error {
+	m.mu.Lock()
+	if m.running {
+		m.mu.Unlock()
+		return nil
+	}
+	m.running = true
+
+	monitorCtx, cancel := context.WithCancel(ctx)
+	m.cancel = cancel
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go m.run(monitorCtx)
+
+	m.logger.Info("Replay monitor started",
+		zap.Int("events", len(m.recording)),
+		zap.Int("speed", m.speed))
+	<-monitorCtx.Done()
+
+	m.logger.Info("Replay monitor stopped")
+	return monitorCtx.Err()
+}
+
+// Stop stops the playback
+func (m *ReplayMonitor) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	if !m.running {
+		m.mu.Unlock()
+		return nil
+	}
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.running = false
+	m.mu.Unlock()
+
+	// The playback goroutine is the only producer, the channel is closed once it returned
+	m.wg.Wait()
+	m.events.close()
+	return nil
+}
+
+// Events returns a read-only channel that emits the replayed MediaMetadata
+func (m *ReplayMonitor) Events() <-chan domain.MediaMetadata {
+	return m.events.Events()
+}
+
+// EventStats implements domain.EventCounter
+func (m *ReplayMonitor) EventStats() domain.EventStats {
+	return m.events.EventStats()
+}
+
+// run emits the recorded events, waiting between them like they were recorded
+func (m *ReplayMonitor) run(ctx context.Context) {
+	defer m.wg.Done()
+
+	for i, rec := range m.recording {
+		if i > 0 {
+			if wait := m.wait(m.recording[i-1].At, rec.At); wait > 0 {
+				timer := m.clock.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-timer.C():
+				}
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		m.events.push(rec.Event)
+	}
+	m.logger.Info("Recording played back", zap.Int("events", len(m.recording)))
+}
+
+// wait returns how long to wait between events recorded at prev and next
+func (m *ReplayMonitor) wait(prev, next time.Time) time.Duration {
+	if m.speed == 0 {
+		return 0
+	}
+	return min(max(next.Sub(prev)/time.Duration(m.speed), 0), maxReplayWait)
+}
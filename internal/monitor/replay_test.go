@@ -0,0 +1,71 @@
+package monitor
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// TestRecordReplay verifies recorded events are handed on, and played back in
+// order with the recorded waits shortened by the replay speed
+func TestRecordReplay(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	path := filepath.Join(t.TempDir(), "recordings", "events.jsonl")
+	cfg := &mockConfig{record: path, replay: path, replaySpeed: 2}
+
+	expect := func(events <-chan domain.MediaMetadata, title string) {
+		t.Helper()
+		select {
+		case meta := <-events:
+			if meta.Title != title {
+				t.Errorf("expected %s, got %s", title, meta.Title)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no event, expected %s", title)
+		}
+	}
+
+	inner := newFakeMonitor()
+	rec, err := NewRecorder(zap.NewNop(), cfg, clk, inner)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+	inner.events <- domain.MediaMetadata{Title: "First", Status: domain.StatusPlaying}
+	expect(rec.Events(), "First")
+	clk.Advance(10 * time.Second)
+	inner.events <- domain.MediaMetadata{Title: "Second", Status: domain.StatusPlaying}
+	expect(rec.Events(), "Second")
+	if err := rec.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	replay, err := NewReplayMonitor(zap.NewNop(), cfg, clk)
+	if err != nil {
+		t.Fatalf("NewReplayMonitor failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go replay.Start(ctx)
+
+	expect(replay.Events(), "First")
+	clk.BlockUntil(1)
+	clk.Advance(5 * time.Second)
+	expect(replay.Events(), "Second")
+}
+
+// TestLoadRecording_Invalid verifies broken recordings are rejected with their line
+func TestLoadRecording_Invalid(t *testing.T) {
+	_, err := loadRecording(strings.NewReader(`{"at":"2026-01-02T15:04:05Z","event":{"Title":"Song"}}` + "\n\nnot json\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("expected an error on line 3, got %v", err)
+	}
+	if _, err := loadRecording(strings.NewReader("\n")); err == nil {
+		t.Error("expected an empty recording to be rejected")
+	}
+}
@@ -0,0 +1,50 @@
+package monitor
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxMetadataTextLen caps title/artist/album length (bytes) accepted from players
+const maxMetadataTextLen = 512
+
+// sanitizeText makes player-provided text safe for logs, file names and overlays:
+// invalid UTF-8 is replaced, control characters are dropped, surrounding whitespace
+// is trimmed and the length is capped
+func sanitizeText(s string) string {
+	s = strings.ToValidUTF8(s, "\uFFFD")
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+	s = strings.TrimSpace(s)
+
+	if len(s) > maxMetadataTextLen {
+		// Cut on a rune boundary so the result stays valid UTF-8
+		cut := maxMetadataTextLen
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		s = s[:cut]
+	}
+
+	return s
+}
+
+// releaseYear returns the year a release date starts with, such as "2019",
+// "2019-05" or "2019-05-03T00:00:00Z", 0 when it starts with none
+func releaseYear(date string) int {
+	date = strings.TrimSpace(date)
+	if len(date) < 4 {
+		return 0
+	}
+	year, err := strconv.Atoi(date[:4])
+	if err != nil || year <= 0 {
+		return 0
+	}
+	return year
+}
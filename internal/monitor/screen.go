@@ -1,17 +1,84 @@
 package monitor
 
 import (
+	"context"
+	"errors"
+	"image"
+	"time"
+
+	"github.com/genricoloni/synest/internal/clock"
 	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/readiness"
 	"github.com/kbinani/screenshot"
 	"go.uber.org/zap"
 )
 
-// NewScreenResolution detects the primary screen resolution at startup
-func NewScreenResolution(logger *zap.Logger) *domain.ScreenResolution {
+// displayRetryInterval is how often a display is looked for after startup found none
+const displayRetryInterval = 30 * time.Second
+
+// NewScreenResolution detects the primary screen resolution at startup,
+// waiting up to the ready timeout for the display to come up.
+// With the span layout the resolution covers the whole virtual desktop instead.
+// Without a display it falls back to 1920x1080, see AwaitDisplay.
+func NewScreenResolution(logger *zap.Logger, cfg domain.Config) *domain.ScreenResolution {
+	display := readiness.Check{Name: "display", Ready: func(ctx context.Context) error {
+		if screenshot.NumActiveDisplays() <= 0 {
+			return errors.New("no active displays")
+		}
+		return nil
+	}}
+	if err := readiness.Wait(context.Background(), logger, cfg.GetReadyTimeout(), display); err == nil {
+		if res, ok := detectResolution(logger, cfg); ok {
+			return res
+		}
+	}
+
+	logger.Warn("No active displays detected, falling back to 1920x1080 until one is found")
+	return &domain.ScreenResolution{Width: 1920, Height: 1080, Fallback: true}
+}
+
+// AwaitDisplay looks for a display every displayRetryInterval after startup fell
+// back to the default size, until one is found or ctx is cancelled. The detected
+// resolution is handed to resize, so wallpapers are rendered at the right size again.
+func AwaitDisplay(ctx context.Context, logger *zap.Logger, cfg domain.Config, clk clock.Clock, resize func(domain.ScreenResolution)) {
+	timer := clk.NewTimer(displayRetryInterval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C():
+			if res, ok := detectResolution(logger, cfg); ok {
+				resize(*res)
+				return
+			}
+			timer.Reset(displayRetryInterval)
+		}
+	}
+}
+
+// detectResolution reads the size of the primary display, or of the virtual
+// desktop with the span layout. It reports false without an active display.
+func detectResolution(logger *zap.Logger, cfg domain.Config) (*domain.ScreenResolution, bool) {
 	n := screenshot.NumActiveDisplays()
 	if n <= 0 {
-		logger.Warn("No active displays detected, falling back to 1920x1080")
-		return &domain.ScreenResolution{Width: 1920, Height: 1080}
+		return nil, false
+	}
+
+	if n > 1 && cfg.GetMultiDisplay() == domain.MultiDisplaySpan {
+		displays := make([]image.Rectangle, n)
+		for i := range displays {
+			displays[i] = screenshot.GetDisplayBounds(i)
+		}
+		res := spanResolution(displays)
+
+		logger.Info("Spanning wallpaper across displays",
+			zap.Int("displays", n),
+			zap.Int("width", res.Width),
+			zap.Int("height", res.Height))
+
+		return res, true
 	}
 
 	// Use primary monitor (index 0)
@@ -25,5 +92,26 @@ func NewScreenResolution(logger *zap.Logger) *domain.ScreenResolution {
 		zap.Int("width", res.Width),
 		zap.Int("height", res.Height))
 
-	return res
+	return res, true
+}
+
+// spanResolution sizes the image to the bounding box of all displays and records
+// where each one lies within it. Displays can have negative origins (e.g. a monitor
+// placed left of the primary one), so outputs are shifted by the box origin.
+func spanResolution(displays []image.Rectangle) *domain.ScreenResolution {
+	var desktop image.Rectangle
+	for _, d := range displays {
+		desktop = desktop.Union(d)
+	}
+
+	outputs := make([]image.Rectangle, len(displays))
+	for i, d := range displays {
+		outputs[i] = d.Sub(desktop.Min)
+	}
+
+	return &domain.ScreenResolution{
+		Width:   desktop.Dx(),
+		Height:  desktop.Dy(),
+		Outputs: outputs,
+	}
 }
@@ -0,0 +1,27 @@
+package monitor
+
+import (
+	"image"
+	"reflect"
+	"testing"
+)
+
+// TestSpanResolution verifies displays left of or above the primary one are
+// shifted into the spanned image
+func TestSpanResolution(t *testing.T) {
+	res := spanResolution([]image.Rectangle{
+		image.Rect(0, 0, 1920, 1080),
+		image.Rect(-1280, 200, 0, 1224),
+	})
+
+	if res.Width != 3200 || res.Height != 1224 {
+		t.Errorf("expected 3200x1224, got %dx%d", res.Width, res.Height)
+	}
+	expected := []image.Rectangle{
+		image.Rect(1280, 0, 3200, 1080),
+		image.Rect(0, 200, 1280, 1224),
+	}
+	if !reflect.DeepEqual(res.Outputs, expected) {
+		t.Errorf("expected outputs %v, got %v", expected, res.Outputs)
+	}
+}
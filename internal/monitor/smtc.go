@@ -0,0 +1,83 @@
+package monitor
+
+import (
+	"strings"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+// GlobalSystemMediaTransportControlsSessionPlaybackStatus values
+const (
+	smtcClosed int32 = iota
+	smtcOpened
+	smtcChanging
+	smtcStopped
+	smtcPlaying
+	smtcPaused
+)
+
+// smtcSession is a snapshot of the current Windows media session
+type smtcSession struct {
+	app         string // AppUserModelID of the player, e.g. "Spotify.exe"
+	title       string
+	artist      string
+	albumArtist string
+	album       string
+	status      int32
+	length      time.Duration
+	position    time.Duration // Position at updated
+	updated     time.Time
+}
+
+// metadata converts the session to the event emitted for it. Players that
+// only fill the album artist, such as some browsers, use it as the artist.
+func (s smtcSession) metadata() domain.MediaMetadata {
+	artist := sanitizeText(s.artist)
+	if artist == "" {
+		artist = sanitizeText(s.albumArtist)
+	}
+	return domain.MediaMetadata{
+		Player:      smtcPlayerID(s.app),
+		PlayerName:  s.app,
+		Title:       sanitizeText(s.title),
+		Artist:      artist,
+		Album:       sanitizeText(s.album),
+		AlbumArtist: sanitizeText(s.albumArtist),
+		Length:      s.length,
+		Status:      smtcStatus(s.status),
+	}
+}
+
+// changed reports whether s shows another track or playback status than before
+func (s smtcSession) changed(before smtcSession) bool {
+	return s.app != before.app || s.title != before.title || s.artist != before.artist ||
+		s.albumArtist != before.albumArtist || s.album != before.album ||
+		smtcStatus(s.status) != smtcStatus(before.status)
+}
+
+// smtcPlayerID derives a player ID from an AppUserModelID: "Spotify.exe" and
+// "SpotifyAB.SpotifyMusic_zpdnekdrzrea0!Spotify" both give "spotify"
+func smtcPlayerID(app string) string {
+	if _, entry, ok := strings.Cut(app, "!"); ok {
+		app = entry
+	}
+	app = strings.TrimSuffix(strings.ToLower(app), ".exe")
+	if i := strings.LastIndex(app, "."); i >= 0 {
+		app = app[i+1:]
+	}
+	return app
+}
+
+// smtcStatus maps a session playback status to a player status. Opening and
+// changing sessions are reported as stopped until they start playing.
+func smtcStatus(status int32) domain.PlayerStatus {
+	switch status {
+	case smtcPlaying:
+		return domain.StatusPlaying
+	case smtcPaused:
+		return domain.StatusPaused
+	default:
+		return domain.StatusStopped
+	}
+}
@@ -0,0 +1,70 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+func TestSmtcPlayerID(t *testing.T) {
+	tests := map[string]string{
+		"Spotify.exe": "spotify",
+		"SpotifyAB.SpotifyMusic_zpdnekdrzrea0!Spotify":          "spotify",
+		"Microsoft.ZuneMusic_8wekyb3d8bbwe!Microsoft.ZuneMusic": "zunemusic",
+		"MSEdge":           "msedge",
+		"308046B0AF4A39CB": "308046b0af4a39cb",
+		"":                 "",
+	}
+	for app, want := range tests {
+		if got := smtcPlayerID(app); got != want {
+			t.Errorf("smtcPlayerID(%q) = %q, expected %q", app, got, want)
+		}
+	}
+}
+
+// TestSmtcSessionMetadata verifies the event built from a session, falling
+// back to the album artist and mapping transitional states to stopped
+func TestSmtcSessionMetadata(t *testing.T) {
+	session := smtcSession{
+		app:         "Spotify.exe",
+		title:       " Song\n",
+		albumArtist: "Band",
+		album:       "Album",
+		status:      smtcPlaying,
+		length:      3 * time.Minute,
+	}
+
+	meta := session.metadata()
+	if meta.Player != "spotify" || meta.PlayerName != "Spotify.exe" {
+		t.Errorf("expected player spotify from Spotify.exe, got %q from %q", meta.Player, meta.PlayerName)
+	}
+	if meta.Title != "Song" || meta.Artist != "Band" || meta.Album != "Album" {
+		t.Errorf("unexpected track %q by %q on %q", meta.Title, meta.Artist, meta.Album)
+	}
+	if meta.Length != 3*time.Minute || meta.Status != domain.StatusPlaying {
+		t.Errorf("expected 3m0s playing, got %s %s", meta.Length, meta.Status)
+	}
+
+	progressed := session
+	progressed.position, progressed.updated = time.Minute, time.Unix(60, 0)
+	if progressed.changed(session) {
+		t.Error("expected the progress of the same track not to be a change")
+	}
+	paused := session
+	paused.status = smtcPaused
+	if !paused.changed(session) {
+		t.Error("expected a pause to be a change")
+	}
+
+	for status, want := range map[int32]domain.PlayerStatus{
+		smtcPaused:   domain.StatusPaused,
+		smtcChanging: domain.StatusStopped,
+		smtcClosed:   domain.StatusStopped,
+	} {
+		session.status = status
+		if got := session.metadata().Status; got != want {
+			t.Errorf("status %d: expected %s, got %s", status, want, got)
+		}
+	}
+}
@@ -0,0 +1,423 @@
+//go:build windows
+// +build windows
+
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/monitor/players"
+	"github.com/genricoloni/synest/internal/monitor/quirks"
+	"github.com/genricoloni/synest/internal/normalize"
+	"github.com/genricoloni/synest/internal/privacy"
+	"go.uber.org/zap"
+	"golang.org/x/sys/windows"
+)
+
+// smtcPollInterval is how often the current session is read. The session manager
+// raises change events, but receiving them needs COM callbacks; a snapshot per
+// interval keeps every call on the monitor's own thread.
+const smtcPollInterval = time.Second
+
+// smtcTimeout bounds each asynchronous session call
+const smtcTimeout = 5 * time.Second
+
+func init() {
+	backends[BackendSMTC] = func(logger *zap.Logger, cfg domain.Config, clk clock.Clock) (domain.Monitor, error) {
+		return NewSmtcMonitor(logger, cfg, clk), nil
+	}
+	autoBackends = []string{BackendSMTC}
+}
+
+// smtcMethods maps playback commands to the session methods carrying them out
+var smtcMethods = map[string]int{
+	domain.PlayerPlayPause: slotTryTogglePlayPauseAsync,
+	domain.PlayerNext:      slotTrySkipNextAsync,
+	domain.PlayerPrevious:  slotTrySkipPreviousAsync,
+}
+
+// smtcCommand is a playback command handed to the thread owning the session manager
+type smtcCommand struct {
+	command string
+	done    chan error
+}
+
+// SmtcMonitor follows the current Windows media session, the one shown by the
+// media flyout, through the GlobalSystemMediaTransportControlsSessionManager
+type SmtcMonitor struct {
+	logger     *zap.Logger
+	cfg        domain.Config
+	clock      clock.Clock
+	events     *eventQueue
+	players    *players.Filter       // Players allowed to drive the wallpaper, by player ID
+	normalizer *normalize.Normalizer // Player-independent text cleanup
+	separator  string                // Joins all artists into MediaMetadata.ArtistDisplay
+	commands   chan smtcCommand      // Playback commands for the session thread
+
+	mu       sync.RWMutex
+	running  bool
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	last     smtcSession // Last emitted session, zero when none is followed
+	playback playback    // Position of the last reported track
+}
+
+// NewSmtcMonitor creates a new Windows media session monitor
+func NewSmtcMonitor(logger *zap.Logger, cfg domain.Config, clk clock.Clock) *SmtcMonitor {
+	normalizer, err := normalize.New(cfg.GetNormalizeRules())
+	if err != nil {
+		logger.Warn("Invalid normalization rules, using defaults", zap.Error(err))
+		normalizer, _ = normalize.New(nil)
+	}
+
+	filter, err := players.NewFilter(cfg.GetPlayerAllow(), cfg.GetPlayerDeny())
+	if err != nil {
+		logger.Warn("Invalid player allow/deny lists, following all players", zap.Error(err))
+		filter = &players.Filter{}
+	}
+
+	return &SmtcMonitor{
+		logger:     logger,
+		cfg:        cfg,
+		clock:      clk,
+		events:     newEventQueue(logger),
+		players:    filter,
+		normalizer: normalizer,
+		separator:  cfg.GetArtistSeparator(),
+		commands:   make(chan smtcCommand),
+	}
+}
+
+// Start follows the current session until ctx is cancelled. It fails when the
+// session manager is unavailable, before Windows 10 1809.
+func (m *SmtcMonitor) Start(ctx context.Context) error {
+	m.mu.Lock()
+	if m.running {
+		m.mu.Unlock()
+		return nil
+	}
+	m.running = true
+
+	monitorCtx, cancel := context.WithCancel(ctx)
+	m.cancel = cancel
+	m.mu.Unlock()
+
+	ready := make(chan error, 1)
+	m.wg.Add(1)
+	go m.run(monitorCtx, ready)
+	if err := <-ready; err != nil {
+		cancel()
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.running = false
+		m.cancel = nil
+		return fmt.Errorf("media session manager unavailable: %w", err)
+	}
+
+	m.logger.Info("Windows media session monitor started")
+	<-monitorCtx.Done()
+
+	m.logger.Info("Windows media session monitor stopped")
+	return monitorCtx.Err()
+}
+
+// Stop gracefully stops the monitor
+func (m *SmtcMonitor) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	if !m.running {
+		m.mu.Unlock()
+		return nil
+	}
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.running = false
+	m.mu.Unlock()
+
+	// The session thread is the only producer, the channel is closed once it returned
+	m.wg.Wait()
+	m.events.close()
+
+	m.logger.Info("Windows media session monitor shutdown complete")
+	return nil
+}
+
+// Events returns a read-only channel that emits MediaMetadata
+func (m *SmtcMonitor) Events() <-chan domain.MediaMetadata {
+	return m.events.Events()
+}
+
+// EventStats implements domain.EventCounter
+func (m *SmtcMonitor) EventStats() domain.EventStats {
+	return m.events.EventStats()
+}
+
+// Position implements domain.PositionSource from the timeline of the session
+func (m *SmtcMonitor) Position() (position, length time.Duration, ok bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.playback.player == "" {
+		return 0, 0, false
+	}
+	return m.playback.estimate(m.clock.Now()), m.playback.length, true
+}
+
+// Control implements domain.PlayerController: it sends a playback command to
+// the current session on the session thread
+func (m *SmtcMonitor) Control(ctx context.Context, command string) error {
+	if _, ok := smtcMethods[command]; !ok {
+		return fmt.Errorf("unknown player command %q", command)
+	}
+
+	m.mu.RLock()
+	running := m.running
+	m.mu.RUnlock()
+	if !running {
+		return errors.New("the media session monitor is not running")
+	}
+
+	cmd := smtcCommand{command: command, done: make(chan error, 1)}
+	select {
+	case m.commands <- cmd:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-cmd.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run owns the session manager: it reports on ready whether it was obtained,
+// then reads the session every smtcPollInterval and carries out playback
+// commands until ctx is cancelled
+func (m *SmtcMonitor) run(ctx context.Context, ready chan<- error) {
+	defer m.wg.Done()
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := windows.CoInitializeEx(0, windows.COINIT_MULTITHREADED); err != nil && err != syscall.Errno(windows.S_FALSE) {
+		ready <- fmt.Errorf("COM initialization failed: %w", err)
+		return
+	}
+	defer windows.CoUninitialize()
+
+	manager, err := requestSessionManager()
+	ready <- err
+	if err != nil {
+		return
+	}
+	defer manager.release()
+
+	m.poll(manager)
+	timer := m.clock.NewTimer(smtcPollInterval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case cmd := <-m.commands:
+			cmd.done <- m.control(manager, cmd.command)
+		case <-timer.C():
+			m.poll(manager)
+			timer.Reset(smtcPollInterval)
+		}
+	}
+}
+
+// requestSessionManager activates the session manager of the current user
+func requestSessionManager() (*comObject, error) {
+	statics, err := activationFactory(sessionManagerClass, &iidSessionManagerStatics)
+	if err != nil {
+		return nil, fmt.Errorf("failed to activate %s: %w", sessionManagerClass, err)
+	}
+	defer statics.release()
+
+	var manager *comObject
+	if err := statics.await(slotRequestAsync, smtcTimeout, unsafe.Pointer(&manager)); err != nil {
+		return nil, fmt.Errorf("failed to request the session manager: %w", err)
+	}
+	if manager == nil {
+		return nil, errors.New("no session manager returned")
+	}
+	return manager, nil
+}
+
+// poll reads the current session and emits it when it shows another track or status
+func (m *SmtcMonitor) poll(manager *comObject) {
+	session, err := readSession(manager)
+	if err != nil {
+		m.logger.Debug("Failed to read the media session", zap.Error(err))
+		return
+	}
+
+	if session.app != "" && !m.players.Allowed(smtcPlayerID(session.app)) {
+		m.logger.Debug("Ignoring media session of unfollowed player", zap.String("player", session.app))
+		session = smtcSession{}
+	}
+
+	m.mu.Lock()
+	last := m.last
+	m.last = session
+	m.mu.Unlock()
+
+	switch {
+	case session.app != "":
+		m.follow(session, session.changed(last))
+	case last.app != "":
+		// The session closed, its track is over
+		m.mu.Lock()
+		m.playback = playback{}
+		m.mu.Unlock()
+		meta := m.clean(last.metadata())
+		meta.Status = domain.StatusStopped
+		m.emit(meta)
+	}
+}
+
+// follow anchors the position to the timeline of a followed session and emits
+// the session when it changed
+func (m *SmtcMonitor) follow(session smtcSession, changed bool) {
+	meta := m.clean(session.metadata())
+
+	m.mu.Lock()
+	at := session.updated
+	if at.IsZero() {
+		at = m.clock.Now()
+	}
+	m.playback = playback{
+		player:  session.app,
+		track:   trackKey(meta),
+		offset:  session.position,
+		at:      at,
+		playing: meta.Status == domain.StatusPlaying,
+		length:  session.length,
+	}
+	meta.Position = m.playback.estimate(m.clock.Now())
+	m.mu.Unlock()
+
+	if changed {
+		m.emit(meta)
+	}
+}
+
+// clean applies the generic text normalization, joins the resulting artists for
+// captions and classifies the media
+func (m *SmtcMonitor) clean(meta domain.MediaMetadata) domain.MediaMetadata {
+	meta = m.normalizer.Apply(meta)
+	meta.ArtistDisplay = strings.Join(meta.AllArtists(), m.separator)
+	meta.Kind = quirks.KindOf(meta)
+	return meta
+}
+
+// emit sends a metadata event to the consumer, replacing the one still waiting
+// while the consumer is busy
+func (m *SmtcMonitor) emit(meta domain.MediaMetadata) {
+	m.events.push(meta)
+	fields := []zap.Field{zap.String("player", meta.PlayerName)}
+	if privacy.ModeFor(m.cfg, meta) == domain.PrivateOff {
+		fields = append(fields,
+			zap.String("title", meta.Title),
+			zap.String("artist", meta.Artist))
+	}
+	m.logger.Info("Media change detected",
+		append(fields, zap.String("status", string(meta.Status)))...)
+}
+
+// control sends a playback command to the current session
+func (m *SmtcMonitor) control(manager *comObject, command string) error {
+	session, err := manager.getObject(slotGetCurrentSession)
+	if err != nil {
+		return fmt.Errorf("failed to get the current session: %w", err)
+	}
+	if session == nil {
+		return errors.New("no media session is active")
+	}
+	defer session.release()
+
+	var accepted bool
+	if err := session.await(smtcMethods[command], smtcTimeout, unsafe.Pointer(&accepted)); err != nil {
+		return fmt.Errorf("%s failed: %w", command, err)
+	}
+	if !accepted {
+		return fmt.Errorf("the player refused %s", command)
+	}
+	return nil
+}
+
+// readSession takes a snapshot of the current session, the zero session when there is none
+func readSession(manager *comObject) (smtcSession, error) {
+	var s smtcSession
+	session, err := manager.getObject(slotGetCurrentSession)
+	if err != nil || session == nil {
+		return s, err
+	}
+	defer session.release()
+
+	if s.app, err = session.getString(slotSourceAppUserModelID); err != nil {
+		return s, fmt.Errorf("failed to read the player of the session: %w", err)
+	}
+
+	var props *comObject
+	if err := session.await(slotTryGetMediaPropertiesAsync, smtcTimeout, unsafe.Pointer(&props)); err != nil {
+		return s, fmt.Errorf("failed to read media properties of %s: %w", s.app, err)
+	}
+	if props != nil {
+		defer props.release()
+		for slot, field := range map[int]*string{
+			slotTitle:       &s.title,
+			slotArtist:      &s.artist,
+			slotAlbumArtist: &s.albumArtist,
+			slotAlbumTitle:  &s.album,
+		} {
+			if *field, err = props.getString(slot); err != nil {
+				return s, fmt.Errorf("failed to read media properties of %s: %w", s.app, err)
+			}
+		}
+	}
+
+	info, err := session.getObject(slotGetPlaybackInfo)
+	if err != nil {
+		return s, fmt.Errorf("failed to read playback info of %s: %w", s.app, err)
+	}
+	if info != nil {
+		defer info.release()
+		if err := info.get(slotPlaybackStatus, unsafe.Pointer(&s.status)); err != nil {
+			return s, fmt.Errorf("failed to read playback status of %s: %w", s.app, err)
+		}
+	}
+
+	// The timeline is optional, players that do not publish one have no position
+	timeline, err := session.getObject(slotGetTimelineProperties)
+	if err != nil || timeline == nil {
+		return s, nil
+	}
+	defer timeline.release()
+	var ticks [4]int64
+	for i, slot := range []int{slotStartTime, slotEndTime, slotPosition, slotLastUpdatedTime} {
+		if ticks[i], err = timeline.getTicks(slot); err != nil {
+			return s, nil
+		}
+	}
+	start, end, position, updated := ticks[0], ticks[1], ticks[2], ticks[3]
+	if end > start {
+		s.length = time.Duration(end-start) * 100
+		s.position = time.Duration(max(position-start, 0)) * 100
+		s.updated = dateTime(updated)
+	}
+	return s, nil
+}
@@ -0,0 +1,315 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/monitor/quirks"
+	"go.uber.org/zap"
+)
+
+const (
+	spotifyTokenURL   = "https://accounts.spotify.com/api/token"
+	spotifyAPIURL     = "https://api.spotify.com/v1"
+	spotifyTokenSlop  = 30 * time.Second // Refresh access tokens slightly before they expire
+	spotifyTimeout    = 5 * time.Second  // Bounds each Web API request
+	spotifyPlayerName = "spotify"        // Player ID and name of the events
+)
+
+// spotifyPlayback is the response of /me/player/currently-playing
+type spotifyPlayback struct {
+	IsPlaying  bool         `json:"is_playing"`
+	ProgressMs int64        `json:"progress_ms"`
+	Type       string       `json:"currently_playing_type"` // track, episode, ad or unknown
+	Item       *spotifyItem `json:"item"`                   // Nil when nothing plays, or for ads
+}
+
+// spotifyItem is a track or an episode
+type spotifyItem struct {
+	URI        string `json:"uri"`
+	Name       string `json:"name"`
+	DurationMs int64  `json:"duration_ms"`
+	Artists    []struct {
+		Name string `json:"name"`
+	} `json:"artists"`
+	Album struct {
+		Name    string `json:"name"`
+		Artists []struct {
+			Name string `json:"name"`
+		} `json:"artists"`
+		ReleaseDate string         `json:"release_date"` // "1981-12-15", or only "1981-12" or "1981"
+		Images      []spotifyImage `json:"images"`
+	} `json:"album"`
+	DiscNumber   int `json:"disc_number"`
+	TrackNumber  int `json:"track_number"`
+	ExternalURLs struct {
+		Spotify string `json:"spotify"`
+	} `json:"external_urls"`
+
+	// Episodes have a show and images of their own instead of artists and an album
+	Images []spotifyImage `json:"images"`
+	Show   struct {
+		Name      string `json:"name"`
+		Publisher string `json:"publisher"`
+	} `json:"show"`
+}
+
+// spotifyImage is an artwork size, the widest comes first
+type spotifyImage struct {
+	URL string `json:"url"`
+}
+
+// playing reports whether the state shows something playing or paused: a track,
+// an episode or an ad, which comes without an item
+func (p spotifyPlayback) playing() bool {
+	return p.Item != nil || p.Type == "ad"
+}
+
+// metadata converts the playback state to the event emitted for it. Episodes
+// are credited to their publisher, on the album of their show.
+func (p spotifyPlayback) metadata() domain.MediaMetadata {
+	meta := domain.MediaMetadata{
+		Player:             spotifyPlayerName,
+		PlayerName:         spotifyPlayerName,
+		PlayerIdentity:     "Spotify",
+		PlayerDesktopEntry: "spotify",
+		Status:             domain.StatusPaused,
+	}
+	if p.IsPlaying {
+		meta.Status = domain.StatusPlaying
+	}
+	item := p.Item
+	if item == nil {
+		meta.Title = quirks.AdTitle
+		return meta
+	}
+
+	meta.TrackID = item.URI
+	meta.Title = sanitizeText(item.Name)
+	meta.URL = item.ExternalURLs.Spotify
+	meta.Source = quirks.SourceOf(item.URI)
+	meta.Length = time.Duration(item.DurationMs) * time.Millisecond
+
+	artists, album, images := make([]string, 0, len(item.Artists)), item.Album.Name, item.Album.Images
+	for _, artist := range item.Artists {
+		artists = append(artists, artist.Name)
+	}
+	if p.Type == "episode" {
+		artists, album, images = []string{item.Show.Publisher}, item.Show.Name, item.Images
+	}
+
+	for _, artist := range artists {
+		if name := sanitizeText(artist); name != "" {
+			meta.Artists = append(meta.Artists, name)
+		}
+	}
+	if len(meta.Artists) > 0 {
+		meta.Artist = meta.Artists[0]
+	}
+	meta.Album = sanitizeText(album)
+	if p.Type != "episode" {
+		if len(item.Album.Artists) > 0 {
+			meta.AlbumArtist = sanitizeText(item.Album.Artists[0].Name)
+		}
+		meta.Year = releaseYear(item.Album.ReleaseDate)
+		meta.DiscNumber, meta.TrackNumber = item.DiscNumber, item.TrackNumber
+	}
+	if len(images) > 0 {
+		meta.ArtUrl = images[0].URL
+	}
+	return meta
+}
+
+// SpotifyMonitor follows the playback of a Spotify account on any of its devices
+// by polling the Web API. It authorizes with a refresh token of the account.
+type SpotifyMonitor struct {
+	*follower
+	client       *http.Client
+	clientID     string
+	clientSecret string
+	tokenURL     string
+	apiURL       string
+	interval     time.Duration
+
+	// Authorization, only used by the polling goroutine
+	refreshToken string // Replaced when Spotify rotates it
+	token        string
+	tokenExpiry  time.Time
+	retryAt      time.Time // No request before this, after a rate limit
+	failing      bool      // The last poll failed, further failures are logged at debug level
+}
+
+// NewSpotifyMonitor creates a Spotify Web API monitor. It fails without the
+// client credentials and the refresh token of the account.
+func NewSpotifyMonitor(logger *zap.Logger, cfg domain.Config, clk clock.Clock) (*SpotifyMonitor, error) {
+	clientID, clientSecret := cfg.GetSpotifyCredentials()
+	refreshToken := cfg.GetSpotifyRefreshToken()
+	if clientID == "" || clientSecret == "" || refreshToken == "" {
+		return nil, errors.New("the spotify monitor needs SYNEST_SPOTIFY_CLIENT_ID, SYNEST_SPOTIFY_CLIENT_SECRET and SYNEST_SPOTIFY_REFRESH_TOKEN")
+	}
+
+	return &SpotifyMonitor{
+		follower:     newFollower(logger, cfg, clk, spotifyPlayerName, "Spotify"),
+		client:       &http.Client{Timeout: spotifyTimeout},
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		tokenURL:     spotifyTokenURL,
+		apiURL:       spotifyAPIURL,
+		interval:     cfg.GetSpotifyPollInterval(),
+		refreshToken: refreshToken,
+	}, nil
+}
+
+// Start polls the playback state until ctx is cancelled. Failed polls are
+// logged and retried at the next interval.
+func (m *SpotifyMonitor) Start(ctx context.Context) error {
+	return m.start(ctx, m.run, zap.Duration("interval", m.interval))
+}
+
+// Stop gracefully stops the monitor
+func (m *SpotifyMonitor) Stop(ctx context.Context) error {
+	return m.stop()
+}
+
+// run polls at once, then every interval until ctx is cancelled
+func (m *SpotifyMonitor) run(ctx context.Context) {
+	m.poll(ctx)
+	timer := m.clock.NewTimer(m.interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C():
+			m.poll(ctx)
+			timer.Reset(m.interval)
+		}
+	}
+}
+
+// poll reads the playback state and emits it when it shows another track or status
+func (m *SpotifyMonitor) poll(ctx context.Context) {
+	if m.clock.Now().Before(m.retryAt) {
+		return
+	}
+
+	state, err := m.currentlyPlaying(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		if m.failing {
+			m.logger.Debug("Failed to read the Spotify playback state", zap.Error(err))
+		} else {
+			m.logger.Warn("Failed to read the Spotify playback state, retrying", zap.Error(err))
+		}
+		m.failing = true
+		return
+	}
+	if m.failing {
+		m.logger.Info("Spotify playback state available again")
+		m.failing = false
+	}
+
+	if !state.playing() {
+		m.stopped()
+		return
+	}
+	m.playing(state.metadata(), time.Duration(state.ProgressMs)*time.Millisecond)
+}
+
+// currentlyPlaying requests the playback state, the zero state when nothing plays
+func (m *SpotifyMonitor) currentlyPlaying(ctx context.Context) (spotifyPlayback, error) {
+	var state spotifyPlayback
+	token, err := m.accessToken(ctx)
+	if err != nil {
+		return state, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.apiURL+"/me/player/currently-playing?additional_types=episode", nil)
+	if err != nil {
+		return state, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return state, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNoContent:
+		return state, nil // Nothing plays
+	case http.StatusUnauthorized:
+		m.token = ""
+		return state, errors.New("spotify rejected access token")
+	case http.StatusTooManyRequests:
+		retry, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		m.retryAt = m.clock.Now().Add(time.Duration(retry) * time.Second)
+		return state, fmt.Errorf("rate limited, retrying after %ds", retry)
+	default:
+		return state, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&state); err != nil {
+		return state, fmt.Errorf("failed to decode playback state: %w", err)
+	}
+	return state, nil
+}
+
+// accessToken returns a cached access token, exchanging the refresh token for
+// a new one when it expired
+func (m *SpotifyMonitor) accessToken(ctx context.Context) (string, error) {
+	if m.token != "" && m.clock.Now().Before(m.tokenExpiry) {
+		return m.token, nil
+	}
+
+	form := url.Values{"grant_type": {"refresh_token"}, "refresh_token": {m.refreshToken}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.SetBasicAuth(m.clientID, m.clientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request failed with status code: %d", resp.StatusCode)
+	}
+
+	var body struct {
+		AccessToken  string `json:"access_token"`
+		ExpiresIn    int    `json:"expires_in"`
+		RefreshToken string `json:"refresh_token"` // Set when Spotify rotates the refresh token
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if body.AccessToken == "" {
+		return "", errors.New("token response did not contain an access token")
+	}
+
+	m.token = body.AccessToken
+	m.tokenExpiry = m.clock.Now().Add(time.Duration(body.ExpiresIn)*time.Second - spotifyTokenSlop)
+	if body.RefreshToken != "" {
+		m.refreshToken = body.RefreshToken
+	}
+	return m.token, nil
+}
@@ -0,0 +1,184 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/monitor/quirks"
+	"go.uber.org/zap"
+)
+
+const spotifyTrack = `{
+	"is_playing": true,
+	"progress_ms": 30000,
+	"currently_playing_type": "track",
+	"item": {
+		"uri": "spotify:track:4uLU6hMCjMI75M1A2tKUQC",
+		"name": "Song",
+		"duration_ms": 180000,
+		"artists": [{"name": "Band"}, {"name": "Guest"}],
+		"album": {"name": "Album", "artists": [{"name": "Band"}], "release_date": "1981-12", "images": [{"url": "https://i.scdn.co/image/640"}, {"url": "https://i.scdn.co/image/300"}]},
+		"disc_number": 1,
+		"track_number": 4,
+		"external_urls": {"spotify": "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC"}
+	}
+}`
+
+// fakeSpotify serves the token and currently-playing endpoints of the Web API
+type fakeSpotify struct {
+	mu           sync.Mutex
+	playing      string // Body of currently-playing, empty for 204
+	reject       bool   // Reject the next access token once
+	tokens       int    // Token requests
+	refreshToken string // Refresh token of the last token request
+}
+
+func (f *fakeSpotify) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/token":
+		if id, secret, _ := r.BasicAuth(); id != "id" || secret != "secret" || r.FormValue("grant_type") != "refresh_token" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.tokens++
+		f.refreshToken = r.FormValue("refresh_token")
+		w.Write([]byte(`{"access_token": "access", "expires_in": 3600, "refresh_token": "rotated"}`))
+	case "/me/player/currently-playing":
+		if r.Header.Get("Authorization") != "Bearer access" || f.reject {
+			f.reject = false
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if f.playing == "" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Write([]byte(f.playing))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeSpotify) set(playing string, reject bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.playing, f.reject = playing, reject
+}
+
+// TestSpotifyMonitor verifies the playing track is emitted with its position,
+// a rejected access token is refreshed with the rotated refresh token, and the
+// end of playback is reported as stopped
+func TestSpotifyMonitor(t *testing.T) {
+	api := &fakeSpotify{playing: spotifyTrack}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	clk := clock.NewFake(time.Unix(1000, 0))
+	cfg := &mockConfig{spotify: [3]string{"id", "secret", "initial"}, spotifyPoll: 5 * time.Second}
+	mon, err := NewSpotifyMonitor(zap.NewNop(), cfg, clk)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mon.tokenURL, mon.apiURL = server.URL+"/token", server.URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go mon.Start(ctx)
+
+	next := func(step string) domain.MediaMetadata {
+		t.Helper()
+		select {
+		case meta := <-mon.Events():
+			return meta
+		case <-time.After(time.Second):
+			t.Fatalf("%s: no event", step)
+			return domain.MediaMetadata{}
+		}
+	}
+
+	meta := next("playing track")
+	if meta.Player != "spotify" || meta.Title != "Song" || meta.Artist != "Band" || meta.ArtistDisplay != "Band & Guest" {
+		t.Errorf("unexpected track %q by %q (%q) from %q", meta.Title, meta.Artist, meta.ArtistDisplay, meta.Player)
+	}
+	if meta.ArtUrl != "https://i.scdn.co/image/640" || meta.Source != "spotify" || meta.Status != domain.StatusPlaying {
+		t.Errorf("unexpected artwork %q, source %q or status %s", meta.ArtUrl, meta.Source, meta.Status)
+	}
+	if meta.AlbumArtist != "Band" || meta.Year != 1981 || meta.DiscNumber != 1 || meta.TrackNumber != 4 {
+		t.Errorf("unexpected album artist %q, year %d, disc %d or track %d", meta.AlbumArtist, meta.Year, meta.DiscNumber, meta.TrackNumber)
+	}
+	if position, length, ok := mon.Position(); !ok || position != 30*time.Second || length != 3*time.Minute {
+		t.Errorf("expected 30s of 3m0s, got %s of %s (%v)", position, length, ok)
+	}
+
+	// The same track again is not a change, a rejected token is refreshed
+	api.set(spotifyTrack, true)
+	clk.BlockUntil(1)
+	clk.Advance(5 * time.Second)
+	clk.BlockUntil(1)
+	clk.Advance(5 * time.Second)
+
+	clk.BlockUntil(1)
+	api.set("", false)
+	clk.Advance(5 * time.Second)
+	if meta := next("end of playback"); meta.Title != "Song" || meta.Status != domain.StatusStopped {
+		t.Errorf("expected Song stopped, got %q %s", meta.Title, meta.Status)
+	}
+	if _, _, ok := mon.Position(); ok {
+		t.Error("expected no position once playback ended")
+	}
+
+	api.mu.Lock()
+	if api.tokens != 2 || api.refreshToken != "rotated" {
+		t.Errorf("expected a second token request with the rotated refresh token, got %d with %q", api.tokens, api.refreshToken)
+	}
+	api.mu.Unlock()
+
+	if err := mon.Stop(context.Background()); err != nil {
+		t.Fatalf("unexpected stop error: %v", err)
+	}
+	if _, ok := <-mon.Events(); ok {
+		t.Error("expected the events channel to be closed")
+	}
+}
+
+// TestSpotifyPlaybackMetadata verifies ads, which come without an item, and
+// episodes, credited to their publisher on the album of their show
+func TestSpotifyPlaybackMetadata(t *testing.T) {
+	var ad spotifyPlayback
+	if err := json.Unmarshal([]byte(`{"is_playing": true, "currently_playing_type": "ad", "item": null}`), &ad); err != nil {
+		t.Fatal(err)
+	}
+	if meta := ad.metadata(); !ad.playing() || quirks.KindOf(meta) != domain.KindAd || meta.Status != domain.StatusPlaying {
+		t.Errorf("expected a playing ad, got %q (%s)", meta.Title, meta.Status)
+	}
+
+	var episode spotifyPlayback
+	if err := json.Unmarshal([]byte(`{
+		"currently_playing_type": "episode",
+		"item": {
+			"uri": "spotify:episode:5Xt5DXGzch68nYYamXrNxZ",
+			"name": "Episode 12",
+			"images": [{"url": "https://i.scdn.co/image/show"}],
+			"show": {"name": "The Show", "publisher": "Host"}
+		}
+	}`), &episode); err != nil {
+		t.Fatal(err)
+	}
+	meta := episode.metadata()
+	if meta.Title != "Episode 12" || meta.Artist != "Host" || meta.Album != "The Show" || meta.ArtUrl != "https://i.scdn.co/image/show" {
+		t.Errorf("unexpected episode %q by %q on %q with %q", meta.Title, meta.Artist, meta.Album, meta.ArtUrl)
+	}
+	if quirks.KindOf(meta) != domain.KindPodcast || meta.Status != domain.StatusPaused {
+		t.Errorf("expected a paused podcast, got %q (%s)", quirks.KindOf(meta), meta.Status)
+	}
+}
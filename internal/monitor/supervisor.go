@@ -0,0 +1,329 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	maxRestarts       = 5                // Restarts in a row before a backend is given up
+	restartBackoff    = time.Second      // Wait before the first restart, doubled for each following one
+	maxRestartBackoff = time.Minute      // Longest wait between two restarts
+	stableAfter       = 10 * time.Minute // Run time after which a restarted backend counts as recovered
+	stallTimeout      = time.Minute      // Silence of an event loop after which its backend is restarted
+	stallCheck        = 15 * time.Second // How often the event loop of a backend is checked
+	stopTimeout       = 5 * time.Second  // Wait for a failed backend to stop before it is abandoned
+)
+
+// beater is implemented by backends whose event loop reports it is alive
+type beater interface {
+	// LastBeat returns when the event loop last reported, zero before it runs
+	LastBeat() time.Time
+}
+
+// Supervisor runs a monitor backend and replaces it with a new one, without
+// restarting the daemon, when its Start returns early or its event loop stops
+// beating. Restarts wait longer each time and are given up after maxRestarts
+// in a row. Events of every backend it ran go through one channel.
+type Supervisor struct {
+	logger  *zap.Logger
+	clock   clock.Clock
+	name    string
+	build   func() (domain.Monitor, error) // Constructs a replacement backend
+	events  *eventQueue
+	players chan domain.PlayerEvent // Lifecycle events of every backend run
+
+	mu      sync.Mutex
+	current *session
+	retired domain.EventStats // Events coalesced and dropped by replaced backends
+	started bool
+	stopped bool
+	done    chan struct{} // Closed on Stop, ends a restart wait
+}
+
+// session is a backend and the forwarding of its events
+type session struct {
+	mon       domain.Monitor
+	quit      chan struct{} // Closed to end the forwarding
+	forwarded chan struct{} // Closed once the forwarding ended
+	once      sync.Once
+}
+
+// NewSupervisor supervises the backend mon, build constructs its replacements
+func NewSupervisor(logger *zap.Logger, clk clock.Clock, name string, mon domain.Monitor, build func() (domain.Monitor, error)) *Supervisor {
+	s := &Supervisor{
+		logger:  logger,
+		clock:   clk,
+		name:    name,
+		build:   build,
+		events:  newEventQueue(logger),
+		players: make(chan domain.PlayerEvent, lifecycleBuffer),
+		done:    make(chan struct{}),
+	}
+	s.current = s.forward(mon)
+	return s
+}
+
+// Backend returns the backend in use
+func (s *Supervisor) Backend() domain.Monitor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current.mon
+}
+
+// Ready implements domain.Readier with the backend in use
+func (s *Supervisor) Ready(ctx context.Context) error {
+	if r, ok := s.Backend().(domain.Readier); ok {
+		return r.Ready(ctx)
+	}
+	return nil
+}
+
+// Start runs the backend until ctx is cancelled, restarting it when it fails
+func (s *Supervisor) Start(ctx context.Context) error {
+	s.mu.Lock()
+	if s.started || s.stopped {
+		s.mu.Unlock()
+		return nil
+	}
+	s.started = true
+	current := s.current
+	s.mu.Unlock()
+
+	restarts := 0
+	for {
+		began := s.clock.Now()
+		err := s.run(ctx, current.mon)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if s.isStopped() {
+			return nil
+		}
+		s.retire(ctx, current)
+
+		if errors.Is(err, errors.ErrUnsupported) {
+			return err
+		}
+		if s.clock.Now().Sub(began) >= stableAfter {
+			restarts = 0
+		}
+		if restarts == maxRestarts {
+			s.logger.Error("Monitor backend keeps failing, giving up",
+				zap.String("backend", s.name), zap.Int("restarts", restarts), zap.Error(err))
+			return fmt.Errorf("%s monitor failed %d times in a row: %w", s.name, restarts+1, err)
+		}
+		restarts++
+		delay := min(restartBackoff<<(restarts-1), maxRestartBackoff)
+		s.logger.Warn("Monitor backend failed, restarting it",
+			zap.String("backend", s.name),
+			zap.Int("attempt", restarts),
+			zap.Duration("delay", delay),
+			zap.Error(err))
+
+		if !s.sleep(ctx, delay) {
+			return ctx.Err()
+		}
+		mon, err := s.build()
+		if err != nil {
+			return fmt.Errorf("rebuilding the %s monitor: %w", s.name, err)
+		}
+
+		s.mu.Lock()
+		if s.stopped {
+			s.mu.Unlock()
+			return nil
+		}
+		current = s.forward(mon)
+		s.current = current
+		s.mu.Unlock()
+	}
+}
+
+// run starts mon and returns once it failed or ctx is cancelled
+func (s *Supervisor) run(ctx context.Context, mon domain.Monitor) error {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errc := make(chan error, 1)
+	go func() { errc <- mon.Start(runCtx) }()
+
+	// Backends without an event loop of their own are only watched for exits
+	b, beats := mon.(beater)
+	if !beats {
+		return exitError(<-errc)
+	}
+
+	timer := s.clock.NewTimer(stallCheck)
+	defer timer.Stop()
+	for {
+		select {
+		case err := <-errc:
+			return exitError(err)
+		case <-timer.C():
+			if last := b.LastBeat(); !last.IsZero() && s.clock.Now().Sub(last) > stallTimeout {
+				return fmt.Errorf("event loop silent for %s", s.clock.Now().Sub(last).Round(time.Second))
+			}
+			timer.Reset(stallCheck)
+		}
+	}
+}
+
+// exitError describes why Start of a backend returned
+func exitError(err error) error {
+	if err == nil {
+		return errors.New("stopped on its own")
+	}
+	return err
+}
+
+// retire stops a failed backend and ends the forwarding of its events. A
+// backend stuck in its event loop may not stop, it is abandoned after stopTimeout.
+func (s *Supervisor) retire(ctx context.Context, old *session) {
+	stopped := make(chan error, 1)
+	go func() { stopped <- old.mon.Stop(ctx) }()
+
+	timer := s.clock.NewTimer(stopTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-stopped:
+		if err != nil {
+			s.logger.Warn("Failed to stop the failed monitor backend", zap.String("backend", s.name), zap.Error(err))
+		}
+		// Hand over the events it sent before failing
+		select {
+		case <-old.forwarded:
+		case <-timer.C():
+		}
+	case <-timer.C():
+		s.logger.Warn("Failed monitor backend did not stop, abandoning it", zap.String("backend", s.name))
+	}
+	old.close()
+
+	if counter, ok := old.mon.(domain.EventCounter); ok {
+		stats := counter.EventStats()
+		s.mu.Lock()
+		s.retired.Coalesced += stats.Coalesced
+		s.retired.Dropped += stats.Dropped
+		s.mu.Unlock()
+	}
+}
+
+// sleep waits for d, it reports false when ctx was cancelled or s stopped first
+func (s *Supervisor) sleep(ctx context.Context, d time.Duration) bool {
+	timer := s.clock.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return true
+	case <-ctx.Done():
+		return false
+	case <-s.done:
+		return false
+	}
+}
+
+// isStopped reports whether Stop was called
+func (s *Supervisor) isStopped() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stopped
+}
+
+// forward hands the events of mon over, with its player lifecycle events when
+// it reports them, until its channel is closed or the session ends
+func (s *Supervisor) forward(mon domain.Monitor) *session {
+	sess := &session{mon: mon, quit: make(chan struct{}), forwarded: make(chan struct{})}
+	go func() {
+		defer close(sess.forwarded)
+		events := mon.Events()
+		var lifecycle <-chan domain.PlayerEvent
+		if src, ok := mon.(domain.PlayerEventSource); ok {
+			lifecycle = src.PlayerEvents()
+		}
+		for {
+			select {
+			case meta, ok := <-events:
+				if !ok {
+					return
+				}
+				s.events.push(meta)
+			case ev := <-lifecycle:
+				sendPlayerEvent(s.logger, s.players, ev)
+			case <-sess.quit:
+				return
+			}
+		}
+	}()
+	return sess
+}
+
+// close ends the forwarding of the session and waits for it
+func (sess *session) close() {
+	sess.once.Do(func() { close(sess.quit) })
+	<-sess.forwarded
+}
+
+// Stop stops the backend in use, then closes the events channel
+func (s *Supervisor) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return nil
+	}
+	s.stopped = true
+	close(s.done)
+	current := s.current
+	s.mu.Unlock()
+
+	err := current.mon.Stop(ctx)
+	current.close()
+	s.events.close()
+	return err
+}
+
+// Events returns a read-only channel that emits the MediaMetadata of every backend run
+func (s *Supervisor) Events() <-chan domain.MediaMetadata {
+	return s.events.Events()
+}
+
+// PlayerEvents implements domain.PlayerEventSource with the events of every backend run
+func (s *Supervisor) PlayerEvents() <-chan domain.PlayerEvent {
+	return s.players
+}
+
+// EventStats implements domain.EventCounter with the events of every backend run
+func (s *Supervisor) EventStats() domain.EventStats {
+	stats := s.events.EventStats()
+	s.mu.Lock()
+	stats.Coalesced += s.retired.Coalesced
+	stats.Dropped += s.retired.Dropped
+	current := s.current.mon
+	s.mu.Unlock()
+	if counter, ok := current.(domain.EventCounter); ok {
+		backend := counter.EventStats()
+		stats.Coalesced += backend.Coalesced
+		stats.Dropped += backend.Dropped
+	}
+	return stats
+}
+
+// Position implements domain.PositionSource with the backend in use
+func (s *Supervisor) Position() (position, length time.Duration, ok bool) {
+	if src, found := s.Backend().(domain.PositionSource); found {
+		return src.Position()
+	}
+	return 0, 0, false
+}
+
+// Control implements domain.PlayerController with the backend in use
+func (s *Supervisor) Control(ctx context.Context, command string) error {
+	return NewPlayerController(s.Backend()).Control(ctx, command)
+}
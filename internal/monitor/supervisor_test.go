@@ -0,0 +1,214 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// stallingMonitor is a backend whose event loop beat once and then hung
+type stallingMonitor struct {
+	*fakeMonitor
+	beat time.Time
+}
+
+func (s *stallingMonitor) LastBeat() time.Time { return s.beat }
+
+// stuckMonitor is a failed backend whose Stop never returns
+type stuckMonitor struct {
+	*fakeMonitor
+}
+
+func (s *stuckMonitor) Stop(ctx context.Context) error {
+	select {}
+}
+
+// awaitLogs waits until n entries with msg were logged. The backend is retired
+// and its stop timer gone once the restart is announced, so the next timer
+// the test advances is the backoff.
+func awaitLogs(t *testing.T, logs *observer.ObservedLogs, msg string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for logs.FilterMessage(msg).Len() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d %q logs, got %d", n, msg, logs.FilterMessage(msg).Len())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// replacements builds the fake backends a Supervisor restarts, failing each
+// of them when fail is set
+type replacements struct {
+	mu    sync.Mutex
+	built []*fakeMonitor
+	fail  error
+}
+
+func (b *replacements) build() (domain.Monitor, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	mon := newFakeMonitor()
+	mon.err = b.fail
+	b.built = append(b.built, mon)
+	return mon, nil
+}
+
+func (b *replacements) count() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.built)
+}
+
+// TestSupervisor_Restart verifies a backend whose Start returned is replaced
+// after the backoff and the events of both reach the consumer
+func TestSupervisor_Restart(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	failing := newFakeMonitor()
+	failing.err = errors.New("bus gone")
+	failing.events <- domain.MediaMetadata{Title: "Before"}
+	b := &replacements{}
+	core, logs := observer.New(zap.WarnLevel)
+	s := NewSupervisor(zap.New(core), clk, "fake", failing, b.build)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Start(ctx) }()
+
+	expect := func(title string) {
+		t.Helper()
+		select {
+		case meta := <-s.Events():
+			if meta.Title != title {
+				t.Errorf("expected %s, got %s", title, meta.Title)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no event, expected %s", title)
+		}
+	}
+	expect("Before")
+
+	awaitLogs(t, logs, "Monitor backend failed, restarting it", 1)
+	clk.BlockUntil(1)
+	clk.Advance(restartBackoff)
+	for b.count() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if !failing.stopped {
+		t.Error("expected the failed backend to be stopped")
+	}
+	b.built[0].events <- domain.MediaMetadata{Title: "After"}
+	expect("After")
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected Start to end with the context, got %v", err)
+	}
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if _, ok := <-s.Events(); ok {
+		t.Error("expected the events channel to be closed")
+	}
+}
+
+// TestSupervisor_Stall verifies a backend whose event loop stopped beating is
+// restarted although its Start never returned
+func TestSupervisor_Stall(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	stalled := &stallingMonitor{fakeMonitor: newFakeMonitor(), beat: clk.Now()}
+	b := &replacements{}
+	core, logs := observer.New(zap.WarnLevel)
+	s := NewSupervisor(zap.New(core), clk, "fake", stalled, b.build)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Start(ctx)
+
+	// The loop counts as stalled on the first check after stallTimeout
+	for range stallTimeout/stallCheck + 1 {
+		clk.BlockUntil(1)
+		clk.Advance(stallCheck)
+	}
+	awaitLogs(t, logs, "Monitor backend failed, restarting it", 1)
+	clk.BlockUntil(1)
+	clk.Advance(restartBackoff)
+	for b.count() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if !stalled.stopped {
+		t.Error("expected the stalled backend to be stopped")
+	}
+	if s.Backend() != domain.Monitor(b.built[0]) {
+		t.Error("expected the new backend in use")
+	}
+}
+
+// TestSupervisor_GiveUp verifies restarts stop after maxRestarts failures in a row
+func TestSupervisor_GiveUp(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	b := &replacements{fail: errors.New("bus gone")}
+	first, _ := b.build()
+	core, logs := observer.New(zap.WarnLevel)
+	s := NewSupervisor(zap.New(core), clk, "fake", first, b.build)
+
+	done := make(chan error, 1)
+	go func() { done <- s.Start(context.Background()) }()
+
+	for i := range maxRestarts {
+		awaitLogs(t, logs, "Monitor backend failed, restarting it", i+1)
+		clk.BlockUntil(1)
+		clk.Advance(maxRestartBackoff)
+	}
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "failed 6 times in a row: bus gone") {
+			t.Errorf("expected Start to give up, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected Start to give up")
+	}
+	if got := b.count(); got != maxRestarts+1 {
+		t.Errorf("expected %d backends, got %d", maxRestarts+1, got)
+	}
+}
+
+// TestSupervisor_Abandon verifies a failed backend that does not stop is
+// abandoned once stopTimeout passed on the clock, and replaced
+func TestSupervisor_Abandon(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	stuck := &stuckMonitor{fakeMonitor: newFakeMonitor()}
+	stuck.err = errors.New("bus gone")
+	b := &replacements{}
+	core, logs := observer.New(zap.WarnLevel)
+	s := NewSupervisor(zap.New(core), clk, "fake", stuck, b.build)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Start(ctx)
+
+	clk.BlockUntil(1)
+	if got := logs.FilterMessage("Failed monitor backend did not stop, abandoning it").Len(); got != 0 {
+		t.Fatalf("expected the backend to get stopTimeout to stop, abandoned %d times", got)
+	}
+	clk.Advance(stopTimeout)
+	awaitLogs(t, logs, "Failed monitor backend did not stop, abandoning it", 1)
+
+	awaitLogs(t, logs, "Monitor backend failed, restarting it", 1)
+	clk.BlockUntil(1)
+	clk.Advance(restartBackoff)
+	for b.count() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if s.Backend() != domain.Monitor(b.built[0]) {
+		t.Error("expected the new backend in use")
+	}
+}
@@ -0,0 +1,44 @@
+//go:build linux
+// +build linux
+
+package monitor
+
+import "maps"
+
+// playerTable holds the per-player state read on every signal. A published
+// table is never modified: writers copy it with m.mu held and swap the copy in,
+// so the signal path reads it without locking.
+type playerTable struct {
+	names      map[string]string          // Maps unique bus names (:1.45) to well-known names (org.mpris.MediaPlayer2.spotify)
+	identities map[string]playerIdentity  // Friendly name and desktop entry of each player, by well-known name
+	options    map[string]playbackOptions // Volume, shuffle and loop settings of each player, by well-known name
+}
+
+// newPlayerTable returns an empty table
+func newPlayerTable() *playerTable {
+	return &playerTable{
+		names:      make(map[string]string),
+		identities: make(map[string]playerIdentity),
+		options:    make(map[string]playbackOptions),
+	}
+}
+
+// snapshot returns the current player table, which must not be modified
+func (m *MprisMonitor) snapshot() *playerTable {
+	return m.table.Load()
+}
+
+// updateTable publishes a copy of the player table changed by change and
+// returns it. Must be called with m.mu held, so concurrent writers do not
+// lose each other's changes.
+func (m *MprisMonitor) updateTable(change func(t *playerTable)) *playerTable {
+	current := m.table.Load()
+	next := &playerTable{
+		names:      maps.Clone(current.names),
+		identities: maps.Clone(current.identities),
+		options:    maps.Clone(current.options),
+	}
+	change(next)
+	m.table.Store(next)
+	return next
+}
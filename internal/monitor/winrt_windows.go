@@ -0,0 +1,236 @@
+//go:build windows
+// +build windows
+
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// The Windows media session API is WinRT. Without generated bindings its
+// interfaces are called through their method tables, at the slots below.
+
+var (
+	combase = windows.NewLazySystemDLL("combase.dll")
+
+	procRoGetActivationFactory    = combase.NewProc("RoGetActivationFactory")
+	procWindowsCreateString       = combase.NewProc("WindowsCreateString")
+	procWindowsDeleteString       = combase.NewProc("WindowsDeleteString")
+	procWindowsGetStringRawBuffer = combase.NewProc("WindowsGetStringRawBuffer")
+)
+
+// sessionManagerClass is the runtime class activated for the session manager
+const sessionManagerClass = "Windows.Media.Control.GlobalSystemMediaTransportControlsSessionManager"
+
+var (
+	// IGlobalSystemMediaTransportControlsSessionManagerStatics
+	iidSessionManagerStatics = windows.GUID{Data1: 0x2050c4ee, Data2: 0x11a0, Data3: 0x57de,
+		Data4: [8]byte{0xae, 0xd7, 0xc9, 0x7c, 0x70, 0x33, 0x82, 0x45}}
+	// IAsyncInfo
+	iidAsyncInfo = windows.GUID{Data1: 0x00000036, Data2: 0x0000, Data3: 0x0000,
+		Data4: [8]byte{0xc0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46}}
+)
+
+// Method table slots. Every WinRT interface starts with the three IUnknown
+// and three IInspectable methods, its own methods follow in declaration order.
+const (
+	slotQueryInterface = 0
+	slotRelease        = 2
+
+	// IGlobalSystemMediaTransportControlsSessionManagerStatics
+	slotRequestAsync = 6
+	// IGlobalSystemMediaTransportControlsSessionManager
+	slotGetCurrentSession = 6
+
+	// IGlobalSystemMediaTransportControlsSession
+	slotSourceAppUserModelID       = 6
+	slotTryGetMediaPropertiesAsync = 7
+	slotGetTimelineProperties      = 8
+	slotGetPlaybackInfo            = 9
+	slotTrySkipNextAsync           = 16
+	slotTrySkipPreviousAsync       = 17
+	slotTryTogglePlayPauseAsync    = 20
+
+	// IGlobalSystemMediaTransportControlsSessionMediaProperties
+	slotTitle       = 6
+	slotAlbumArtist = 8
+	slotArtist      = 9
+	slotAlbumTitle  = 10
+
+	// IGlobalSystemMediaTransportControlsSessionPlaybackInfo
+	slotPlaybackStatus = 7
+
+	// IGlobalSystemMediaTransportControlsSessionTimelineProperties
+	slotStartTime       = 6
+	slotEndTime         = 7
+	slotPosition        = 10
+	slotLastUpdatedTime = 11
+
+	// IAsyncInfo
+	slotAsyncStatus = 7
+	// IAsyncOperation<T>
+	slotGetResults = 8
+)
+
+// AsyncStatus values
+const (
+	asyncStarted   int32 = 0
+	asyncCompleted int32 = 1
+)
+
+// asyncPollInterval is how often a pending asynchronous operation is checked
+const asyncPollInterval = 10 * time.Millisecond
+
+// fileTimeEpoch is the Unix time of the WinRT DateTime epoch, 1601-01-01, in 100 ns ticks
+const fileTimeEpoch = 116444736000000000
+
+// hresult is the status code of a failed COM call
+type hresult uint32
+
+func (hr hresult) Error() string {
+	return fmt.Sprintf("HRESULT 0x%08X", uint32(hr))
+}
+
+func check(hr uintptr) error {
+	if int32(hr) < 0 {
+		return hresult(hr)
+	}
+	return nil
+}
+
+// comObject is a COM interface pointer: its first word points to the method table
+type comObject struct {
+	vtbl *[64]uintptr
+}
+
+// get calls a method whose only parameter points to its result
+func (o *comObject) get(slot int, result unsafe.Pointer) error {
+	hr, _, _ := syscall.SyscallN(o.vtbl[slot], uintptr(unsafe.Pointer(o)), uintptr(result))
+	return check(hr)
+}
+
+// getObject calls a method returning an interface, nil when it returns none
+func (o *comObject) getObject(slot int) (*comObject, error) {
+	var obj *comObject
+	if err := o.get(slot, unsafe.Pointer(&obj)); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// getString calls a method returning an HSTRING
+func (o *comObject) getString(slot int) (string, error) {
+	var h uintptr
+	if err := o.get(slot, unsafe.Pointer(&h)); err != nil {
+		return "", err
+	}
+	defer procWindowsDeleteString.Call(h)
+	return hstringValue(h), nil
+}
+
+// getTicks calls a method returning a TimeSpan or DateTime, in 100 ns ticks
+func (o *comObject) getTicks(slot int) (int64, error) {
+	var ticks int64
+	err := o.get(slot, unsafe.Pointer(&ticks))
+	return ticks, err
+}
+
+func (o *comObject) queryInterface(iid *windows.GUID) (*comObject, error) {
+	var obj *comObject
+	hr, _, _ := syscall.SyscallN(o.vtbl[slotQueryInterface], uintptr(unsafe.Pointer(o)),
+		uintptr(unsafe.Pointer(iid)), uintptr(unsafe.Pointer(&obj)))
+	if err := check(hr); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (o *comObject) release() {
+	syscall.SyscallN(o.vtbl[slotRelease], uintptr(unsafe.Pointer(o)))
+}
+
+// await waits up to timeout for the IAsyncOperation returned by slot, then
+// stores its result. The operation is released.
+func (o *comObject) await(slot int, timeout time.Duration, result unsafe.Pointer) error {
+	op, err := o.getObject(slot)
+	if err != nil {
+		return err
+	}
+	if op == nil {
+		return errors.New("no asynchronous operation returned")
+	}
+	defer op.release()
+
+	info, err := op.queryInterface(&iidAsyncInfo)
+	if err != nil {
+		return fmt.Errorf("not an asynchronous operation: %w", err)
+	}
+	defer info.release()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		var status int32
+		if err := info.get(slotAsyncStatus, unsafe.Pointer(&status)); err != nil {
+			return err
+		}
+		switch status {
+		case asyncCompleted:
+			return op.get(slotGetResults, result)
+		case asyncStarted:
+			if time.Now().After(deadline) {
+				return fmt.Errorf("asynchronous operation timed out after %s", timeout)
+			}
+			time.Sleep(asyncPollInterval)
+		default:
+			return fmt.Errorf("asynchronous operation ended with status %d", status)
+		}
+	}
+}
+
+// activationFactory returns the factory of a runtime class, as the interface iid
+func activationFactory(class string, iid *windows.GUID) (*comObject, error) {
+	name, err := windows.UTF16FromString(class)
+	if err != nil {
+		return nil, err
+	}
+	var h uintptr
+	hr, _, _ := procWindowsCreateString.Call(uintptr(unsafe.Pointer(&name[0])), uintptr(len(name)-1), uintptr(unsafe.Pointer(&h)))
+	if err := check(hr); err != nil {
+		return nil, err
+	}
+	defer procWindowsDeleteString.Call(h)
+
+	var factory *comObject
+	hr, _, _ = procRoGetActivationFactory.Call(h, uintptr(unsafe.Pointer(iid)), uintptr(unsafe.Pointer(&factory)))
+	if err := check(hr); err != nil {
+		return nil, err
+	}
+	return factory, nil
+}
+
+// hstringValue copies an HSTRING, the null HSTRING being the empty string
+func hstringValue(h uintptr) string {
+	if h == 0 {
+		return ""
+	}
+	var n uint32
+	raw, _, _ := procWindowsGetStringRawBuffer.Call(h, uintptr(unsafe.Pointer(&n)))
+	if raw == 0 {
+		return ""
+	}
+	return windows.UTF16ToString(unsafe.Slice(*(**uint16)(unsafe.Pointer(&raw)), n))
+}
+
+// dateTime converts a WinRT DateTime, 0 being unknown
+func dateTime(ticks int64) time.Time {
+	if ticks == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, (ticks-fileTimeEpoch)*100)
+}
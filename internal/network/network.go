@@ -0,0 +1,46 @@
+// Package network detects when synest should keep downloads to a minimum, e.g.
+// on a mobile hotspot or a capped connection.
+package network
+
+import (
+	"context"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// Detector implements domain.MeteredSource. Whether the connection is metered
+// is read from NetworkManager.
+type Detector struct {
+	logger *zap.Logger
+	cfg    domain.Config
+	query  func(ctx context.Context) (bool, error) // Reports whether NetworkManager sees a metered connection
+}
+
+// NewDetector creates a metered connection detector
+func NewDetector(logger *zap.Logger, cfg domain.Config) *Detector {
+	return &Detector{
+		logger: logger,
+		cfg:    cfg,
+		query:  queryNetworkManager,
+	}
+}
+
+// Metered reports whether the metered mode applies now: always or never when
+// forced by configuration, otherwise while the connection is metered. An
+// unknown connection counts as unmetered.
+func (d *Detector) Metered(ctx context.Context) bool {
+	switch d.cfg.GetMetered() {
+	case domain.MeteredOn:
+		return true
+	case domain.MeteredOff:
+		return false
+	}
+
+	metered, err := d.query(ctx)
+	if err != nil {
+		d.logger.Debug("Connection cost unknown, assuming unmetered", zap.Error(err))
+		return false
+	}
+	return metered
+}
@@ -0,0 +1,48 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+func TestMetered(t *testing.T) {
+	metered := func(ctx context.Context) (bool, error) { return true, nil }
+	noNetworkManager := func(ctx context.Context) (bool, error) { return false, errors.New("not running") }
+
+	tests := []struct {
+		name     string
+		mode     string
+		query    func(ctx context.Context) (bool, error)
+		expected bool
+	}{
+		{name: "Auto Metered", mode: domain.MeteredAuto, query: metered, expected: true},
+		{name: "Forced Off", mode: domain.MeteredOff, query: metered, expected: false},
+		{name: "Forced On", mode: domain.MeteredOn, query: noNetworkManager, expected: true},
+		{name: "Unknown Connection", mode: domain.MeteredAuto, query: noNetworkManager, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDetector(zap.NewNop(), &mockConfig{mode: tt.mode})
+			d.query = tt.query
+
+			if got := d.Metered(context.Background()); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// mockConfig implements the parts of domain.Config used by the detector
+type mockConfig struct {
+	domain.Config
+	mode string
+}
+
+func (m *mockConfig) GetMetered() string {
+	return m.mode
+}
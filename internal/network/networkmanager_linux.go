@@ -0,0 +1,43 @@
+//go:build linux
+// +build linux
+
+package network
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	networkManagerName = "org.freedesktop.NetworkManager"
+	networkManagerPath = "/org/freedesktop/NetworkManager"
+)
+
+// NMMetered values reported as metered: yes and guess-yes
+const (
+	meteredYes      = 1
+	meteredGuessYes = 3
+)
+
+// queryNetworkManager reads the Metered property of NetworkManager on the system bus
+func queryNetworkManager(ctx context.Context) (bool, error) {
+	conn, err := dbus.SystemBus() // Shared connection, kept open for later queries
+	if err != nil {
+		return false, fmt.Errorf("system bus connection failed: %w", err)
+	}
+
+	var value dbus.Variant
+	err = conn.Object(networkManagerName, networkManagerPath).
+		CallWithContext(ctx, "org.freedesktop.DBus.Properties.Get", 0, networkManagerName, "Metered").
+		Store(&value)
+	if err != nil {
+		return false, fmt.Errorf("failed to query NetworkManager: %w", err)
+	}
+	metered, ok := value.Value().(uint32)
+	if !ok {
+		return false, fmt.Errorf("unexpected NetworkManager Metered value %v", value)
+	}
+	return metered == meteredYes || metered == meteredGuessYes, nil
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package network
+
+import (
+	"context"
+	"errors"
+)
+
+// queryNetworkManager returns an error, NetworkManager is only available on Linux
+func queryNetworkManager(ctx context.Context) (bool, error) {
+	return false, errors.New("NetworkManager is only supported on Linux systems")
+}
@@ -0,0 +1,141 @@
+// Package normalize cleans up artist, title and album text reported by players
+// and streaming services before it is used for enrichment, captions and logs.
+package normalize
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+// Rule rewrites track metadata
+type Rule func(meta domain.MediaMetadata) domain.MediaMetadata
+
+// DefaultRules is the rule set used when none is configured, in order of application
+var DefaultRules = []string{"channel", "remaster", "brackets", "artists"}
+
+var rules = map[string]Rule{
+	"channel":  stripChannelSuffix,
+	"remaster": stripRemaster,
+	"brackets": stripBracketJunk,
+	"artists":  splitArtists,
+}
+
+var (
+	// channelPattern matches auto-generated channel names: "Queen - Topic", "QueenVEVO"
+	channelPattern = regexp.MustCompile(`(?:\s+-\s+Topic|VEVO)$`)
+
+	// remasterPattern matches remaster notes: "(Remastered 2011)", "[2009 Remaster]", "- 2011 Remaster"
+	remasterPattern = regexp.MustCompile(`(?i)\s*(?:[(\[][^)\]]*\bremaster(?:ed)?\b[^)\]]*[)\]]|\s-\s[^-]*\bremaster(?:ed)?\b[^-]*$)`)
+
+	// bracketJunkPattern matches bracketed tags that say nothing about the song itself
+	bracketJunkPattern = regexp.MustCompile(`(?i)\s*[(\[](?:explicit|clean|hd|hq|4k|official(?:\s+music|\s+lyric)?\s+(?:video|audio)|lyrics?(?:\s+video)?|audio|video|visualizer)[)\]]`)
+
+	// artistSeparatorPattern splits multi-artist strings. Commas and "&" are left alone
+	// because they are part of many band names ("Earth, Wind & Fire", "Tyler, The Creator").
+	artistSeparatorPattern = regexp.MustCompile(`(?i)\s*;\s*|\s+/\s+|\s+(?:x|vs\.?|feat\.?|ft\.|featuring)\s+`)
+)
+
+// Normalizer applies an ordered list of rules
+type Normalizer struct {
+	rules []Rule
+}
+
+// New creates a normalizer applying the named rules in order.
+// A nil list selects DefaultRules, an empty list disables normalization.
+func New(names []string) (*Normalizer, error) {
+	if names == nil {
+		names = DefaultRules
+	}
+
+	n := &Normalizer{}
+	for _, name := range names {
+		rule, ok := rules[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown normalization rule %q (available: %s)",
+				name, strings.Join(Names(), ", "))
+		}
+		n.rules = append(n.rules, rule)
+	}
+	return n, nil
+}
+
+// Apply runs all rules on the metadata
+func (n *Normalizer) Apply(meta domain.MediaMetadata) domain.MediaMetadata {
+	for _, rule := range n.rules {
+		meta = rule(meta)
+	}
+	return meta
+}
+
+// Names returns the names of the available rules, sorted
+func Names() []string {
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// stripChannelSuffix removes YouTube auto-generated channel suffixes from artists
+func stripChannelSuffix(meta domain.MediaMetadata) domain.MediaMetadata {
+	meta.Artist = replaceKeepingText(channelPattern, meta.Artist)
+	if len(meta.Artists) > 0 {
+		// Copy so the caller's slice is not modified
+		artists := make([]string, len(meta.Artists))
+		for i, artist := range meta.Artists {
+			artists[i] = replaceKeepingText(channelPattern, artist)
+		}
+		meta.Artists = artists
+	}
+	return meta
+}
+
+// stripRemaster removes remaster notes from titles and albums
+func stripRemaster(meta domain.MediaMetadata) domain.MediaMetadata {
+	meta.Title = replaceKeepingText(remasterPattern, meta.Title)
+	meta.Album = replaceKeepingText(remasterPattern, meta.Album)
+	return meta
+}
+
+// stripBracketJunk removes tags like "[Explicit]" or "(Official Video)" from titles and albums
+func stripBracketJunk(meta domain.MediaMetadata) domain.MediaMetadata {
+	meta.Title = replaceKeepingText(bracketJunkPattern, meta.Title)
+	meta.Album = replaceKeepingText(bracketJunkPattern, meta.Album)
+	return meta
+}
+
+// splitArtists fills Artists from a multi-artist string and keeps only the
+// first one in Artist. Players that already report a list are left untouched.
+func splitArtists(meta domain.MediaMetadata) domain.MediaMetadata {
+	if len(meta.Artists) > 1 || meta.Artist == "" {
+		return meta
+	}
+
+	var artists []string
+	for _, artist := range artistSeparatorPattern.Split(meta.Artist, -1) {
+		if artist = strings.TrimSpace(artist); artist != "" {
+			artists = append(artists, artist)
+		}
+	}
+	if len(artists) == 0 {
+		return meta
+	}
+
+	meta.Artist = artists[0]
+	meta.Artists = artists
+	return meta
+}
+
+// replaceKeepingText removes pattern matches, keeping the original text when
+// nothing would be left (a track really called "(Live)" stays as is)
+func replaceKeepingText(pattern *regexp.Regexp, text string) string {
+	if cleaned := strings.TrimSpace(pattern.ReplaceAllString(text, "")); cleaned != "" {
+		return cleaned
+	}
+	return text
+}
@@ -0,0 +1,118 @@
+package normalize
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+func TestNormalizer_Apply(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    domain.MediaMetadata
+		expected domain.MediaMetadata
+	}{
+		{
+			name:     "YouTube Music Topic Channel",
+			input:    domain.MediaMetadata{Title: "Bohemian Rhapsody (Remastered 2011)", Artist: "Queen - Topic"},
+			expected: domain.MediaMetadata{Title: "Bohemian Rhapsody", Artist: "Queen", Artists: []string{"Queen"}},
+		},
+		{
+			name:     "YouTube VEVO Channel",
+			input:    domain.MediaMetadata{Title: "Hello [Official Video]", Artist: "AdeleVEVO"},
+			expected: domain.MediaMetadata{Title: "Hello", Artist: "Adele", Artists: []string{"Adele"}},
+		},
+		{
+			name:     "Spotify Dash Remaster",
+			input:    domain.MediaMetadata{Title: "Here Comes The Sun - Remastered 2009", Album: "Abbey Road (Remastered)", Artist: "The Beatles"},
+			expected: domain.MediaMetadata{Title: "Here Comes The Sun", Album: "Abbey Road", Artist: "The Beatles", Artists: []string{"The Beatles"}},
+		},
+		{
+			name:     "Spotify Year First Remaster",
+			input:    domain.MediaMetadata{Title: "Time - 2011 Remaster", Artist: "Pink Floyd"},
+			expected: domain.MediaMetadata{Title: "Time", Artist: "Pink Floyd", Artists: []string{"Pink Floyd"}},
+		},
+		{
+			name:     "Apple Music Explicit Tag",
+			input:    domain.MediaMetadata{Title: "HUMBLE. [Explicit]", Album: "DAMN. (Explicit)", Artist: "Kendrick Lamar"},
+			expected: domain.MediaMetadata{Title: "HUMBLE.", Album: "DAMN.", Artist: "Kendrick Lamar", Artists: []string{"Kendrick Lamar"}},
+		},
+		{
+			name:     "Meaningful Brackets Kept",
+			input:    domain.MediaMetadata{Title: "Song 2 (Live at Wembley)", Artist: "Blur"},
+			expected: domain.MediaMetadata{Title: "Song 2 (Live at Wembley)", Artist: "Blur", Artists: []string{"Blur"}},
+		},
+		{
+			name:     "SoundCloud Collaboration",
+			input:    domain.MediaMetadata{Title: "Track", Artist: "Skrillex x Diplo"},
+			expected: domain.MediaMetadata{Title: "Track", Artist: "Skrillex", Artists: []string{"Skrillex", "Diplo"}},
+		},
+		{
+			name:     "Semicolon And Featuring",
+			input:    domain.MediaMetadata{Artist: "Calvin Harris; Rihanna feat. Someone"},
+			expected: domain.MediaMetadata{Artist: "Calvin Harris", Artists: []string{"Calvin Harris", "Rihanna", "Someone"}},
+		},
+		{
+			name:     "Band Names With Comma And Ampersand Kept",
+			input:    domain.MediaMetadata{Artist: "Earth, Wind & Fire"},
+			expected: domain.MediaMetadata{Artist: "Earth, Wind & Fire", Artists: []string{"Earth, Wind & Fire"}},
+		},
+		{
+			name:     "Artist List From Player Untouched",
+			input:    domain.MediaMetadata{Artist: "Simon x Garfunkel", Artists: []string{"Simon x Garfunkel", "Other - Topic"}},
+			expected: domain.MediaMetadata{Artist: "Simon x Garfunkel", Artists: []string{"Simon x Garfunkel", "Other"}},
+		},
+		{
+			name:     "Junk Only Title Kept",
+			input:    domain.MediaMetadata{Title: "(Explicit)"},
+			expected: domain.MediaMetadata{Title: "(Explicit)"},
+		},
+	}
+
+	normalizer, err := New(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizer.Apply(tt.input); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	disabled, err := New([]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	input := domain.MediaMetadata{Title: "Song [Explicit]", Artist: "Queen - Topic"}
+	if got := disabled.Apply(input); !reflect.DeepEqual(got, input) {
+		t.Errorf("empty rule list should not change metadata, got %+v", got)
+	}
+
+	only, err := New([]string{"channel"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := only.Apply(input); got.Artist != "Queen" || got.Title != input.Title {
+		t.Errorf("expected only the channel rule to apply, got %+v", got)
+	}
+
+	if _, err := New([]string{"lowercase"}); err == nil || !strings.Contains(err.Error(), `unknown normalization rule "lowercase"`) {
+		t.Errorf("expected unknown rule error, got %v", err)
+	}
+}
+
+// TestStripChannelSuffix_DoesNotModifyInput guards against writing through the shared Artists slice
+func TestStripChannelSuffix_DoesNotModifyInput(t *testing.T) {
+	artists := []string{"Queen - Topic"}
+	stripChannelSuffix(domain.MediaMetadata{Artists: artists})
+	if artists[0] != "Queen - Topic" {
+		t.Errorf("input slice was modified: %v", artists)
+	}
+}
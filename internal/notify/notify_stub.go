@@ -0,0 +1,26 @@
+//go:build !linux
+// +build !linux
+
+package notify
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// DesktopNotifier stub for platforms without freedesktop notifications
+type DesktopNotifier struct {
+	logger *zap.Logger
+}
+
+// NewDesktopNotifier creates a stub notifier
+func NewDesktopNotifier(logger *zap.Logger) *DesktopNotifier {
+	return &DesktopNotifier{logger: logger}
+}
+
+// Notify returns an error indicating notifications are not supported on this platform
+func (n *DesktopNotifier) Notify(ctx context.Context, summary, body, image string) error {
+	return fmt.Errorf("desktop notifications are only supported on Linux systems")
+}
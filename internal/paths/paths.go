@@ -0,0 +1,58 @@
+// Package paths resolves the per-user directories of synest following the XDG
+// Base Directory specification, with the platform defaults as fallbacks.
+package paths
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// appName is the subdirectory synest uses in every base directory
+const appName = "synest"
+
+// ConfigDir returns the config directory: $XDG_CONFIG_HOME/synest, ~/.config/synest
+// when unset, or the platform config directory outside Linux and BSD
+func ConfigDir() string {
+	return dir(os.UserConfigDir)
+}
+
+// CacheDir returns the cache directory for generated wallpapers:
+// $XDG_CACHE_HOME/synest, ~/.cache/synest when unset, or the platform cache directory
+func CacheDir() string {
+	return dir(os.UserCacheDir)
+}
+
+// StateDir returns the state directory for the daemon status:
+// $XDG_STATE_HOME/synest, or ~/.local/state/synest when unset
+func StateDir() string {
+	return dir(func() (string, error) {
+		// Relative paths are invalid and must be ignored per the specification
+		if base := os.Getenv("XDG_STATE_HOME"); filepath.IsAbs(base) {
+			return base, nil
+		}
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, ".local", "state"), nil
+	})
+}
+
+// Ensure creates dir and its parents, readable only by the user
+func Ensure(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	return nil
+}
+
+// dir joins the synest subdirectory to a base directory. Without a usable home
+// directory it falls back to a per-user directory under the system temp dir.
+func dir(base func() (string, error)) string {
+	path, err := base()
+	if err != nil || path == "" {
+		return filepath.Join(os.TempDir(), fmt.Sprintf("%s-%d", appName, os.Getuid()))
+	}
+	return filepath.Join(path, appName)
+}
@@ -0,0 +1,57 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestDirs(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG variables are only honored on Linux")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tests := []struct {
+		name     string
+		env      string
+		value    string
+		get      func() string
+		expected string
+	}{
+		{name: "Config From XDG", env: "XDG_CONFIG_HOME", value: "/xdg/config", get: ConfigDir, expected: "/xdg/config/synest"},
+		{name: "Config Fallback", env: "XDG_CONFIG_HOME", get: ConfigDir, expected: filepath.Join(home, ".config", "synest")},
+		{name: "Cache From XDG", env: "XDG_CACHE_HOME", value: "/xdg/cache", get: CacheDir, expected: "/xdg/cache/synest"},
+		{name: "Cache Fallback", env: "XDG_CACHE_HOME", get: CacheDir, expected: filepath.Join(home, ".cache", "synest")},
+		{name: "State From XDG", env: "XDG_STATE_HOME", value: "/xdg/state", get: StateDir, expected: "/xdg/state/synest"},
+		{name: "State Fallback", env: "XDG_STATE_HOME", get: StateDir, expected: filepath.Join(home, ".local", "state", "synest")},
+		{name: "Relative State Ignored", env: "XDG_STATE_HOME", value: "state", get: StateDir, expected: filepath.Join(home, ".local", "state", "synest")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(tt.env, tt.value)
+			if got := tt.get(); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEnsure(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "a", "synest")
+	if err := Ensure(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0700 {
+		t.Errorf("expected 0700, got %v", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,226 @@
+// Package phone pushes each wallpaper put on screen to a phone, through KDE
+// Connect or an ntfy notification, so its lock screen follows the desktop.
+package phone
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/disintegration/imaging"
+	"github.com/genricoloni/synest/internal/breaker"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/latest"
+	"github.com/genricoloni/synest/internal/paths"
+	"github.com/genricoloni/synest/internal/privacy"
+	"go.uber.org/zap"
+)
+
+const (
+	// Filename is the pushed image, written to the output directory for KDE Connect
+	Filename = "phone_wallpaper.jpg"
+
+	pushTimeout = 30 * time.Second // Per push, slow links and sleeping phones give up on it
+)
+
+// job is a wallpaper waiting to be pushed
+type job struct {
+	settings  domain.PhonePush
+	wallpaper []byte
+	title     string // Empty lines are left out of the notification
+	message   string
+}
+
+// Pusher implements domain.Reporter: it forwards every outcome to the wrapped
+// reporter and pushes each wallpaper confirmed on screen in the background.
+// Only the latest wallpaper waits for a slow push, older ones are skipped.
+type Pusher struct {
+	domain.Reporter
+	logger   *zap.Logger
+	cfg      domain.Config
+	metered  domain.MeteredSource
+	breakers *breaker.Set
+	client   *http.Client
+	share    func(ctx context.Context, device, path string) error // Shares a file through KDE Connect
+	worker   *latest.Worker[job]
+}
+
+// NewPusher creates a pusher following the phone settings of cfg, with a
+// breaker per service
+func NewPusher(logger *zap.Logger, cfg domain.Config, inner domain.Reporter, metered domain.MeteredSource, breakers *breaker.Set) *Pusher {
+	p := &Pusher{
+		Reporter: inner,
+		logger:   logger,
+		cfg:      cfg,
+		metered:  metered,
+		breakers: breakers,
+		client:   &http.Client{Timeout: pushTimeout},
+		share:    kdeConnectShare,
+	}
+	p.worker = latest.New(logger, "phone push", p.run)
+	return p
+}
+
+// Success records the update with the wrapped reporter, then queues the push
+// of the wallpaper. Private tracks, scenes written instead of a wallpaper and
+// wallpapers on metered connections are not pushed.
+func (p *Pusher) Success(ctx context.Context, wallpaperPath string, meta domain.MediaMetadata) {
+	p.Reporter.Success(ctx, wallpaperPath, meta)
+
+	settings := p.cfg.GetPhonePush()
+	if settings.Kind == domain.PushOff || privacy.Private(ctx) || p.cfg.GetScene() == domain.SceneOnly || p.metered.Metered(ctx) {
+		return
+	}
+	data, err := os.ReadFile(wallpaperPath)
+	if err != nil {
+		p.logger.Warn("Failed to read the wallpaper to push", zap.Error(err))
+		return
+	}
+	p.worker.Queue(job{settings: settings, wallpaper: data, title: meta.Title, message: meta.DisplayArtist()})
+}
+
+// run pushes a queued wallpaper, in the worker
+func (p *Pusher) run(ctx context.Context, j job) {
+	if err := p.push(ctx, j); err != nil && ctx.Err() == nil {
+		p.logger.Warn("Failed to push the wallpaper to the phone",
+			zap.String("push", j.settings.Kind),
+			zap.Error(err))
+	}
+}
+
+// push sends the wallpaper, cropped to the phone aspect, to the configured service
+func (p *Pusher) push(ctx context.Context, j job) error {
+	data, err := p.crop(j.wallpaper, j.settings.Aspect)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, pushTimeout)
+	defer cancel()
+
+	switch j.settings.Kind {
+	case domain.PushKDEConnect:
+		return p.breakers.Get("push kdeconnect").Call(ctx, func() error {
+			return p.shareFile(ctx, j.settings.Target, data)
+		})
+	case domain.PushNtfy:
+		target, err := url.Parse(j.settings.Target)
+		if err != nil {
+			return err
+		}
+		return p.breakers.Get("push "+target.Host).Call(ctx, func() error {
+			return p.publish(ctx, j, data)
+		})
+	}
+	return nil
+}
+
+// crop center-crops the wallpaper to aspect, width over height. The wallpaper
+// is returned as is without an aspect, or when it already has it.
+func (p *Pusher) crop(data []byte, aspect float64) ([]byte, error) {
+	if aspect <= 0 {
+		return data, nil
+	}
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the wallpaper: %w", err)
+	}
+	width, height := cropSize(img.Bounds(), aspect)
+	if width == img.Bounds().Dx() && height == img.Bounds().Dy() {
+		return data, nil
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, imaging.CropCenter(img, width, height), &jpeg.Options{Quality: p.cfg.GetJPEGQuality()}); err != nil {
+		return nil, fmt.Errorf("failed to encode the phone wallpaper: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// cropSize returns the largest size of the given aspect fitting in bounds
+func cropSize(bounds image.Rectangle, aspect float64) (width, height int) {
+	width, height = bounds.Dx(), bounds.Dy()
+	if float64(width) > float64(height)*aspect {
+		return max(int(math.Round(float64(height)*aspect)), 1), height
+	}
+	return width, max(int(math.Round(float64(width)/aspect)), 1)
+}
+
+// shareFile writes the image to the output directory and shares it with the device
+func (p *Pusher) shareFile(ctx context.Context, device string, data []byte) error {
+	dir := p.cfg.GetOutputDir()
+	if err := paths.Ensure(dir); err != nil {
+		return err
+	}
+	path, err := filepath.Abs(filepath.Join(dir, Filename))
+	if err != nil {
+		return err
+	}
+	// Replaced atomically, a share in flight keeps reading the previous image
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return p.share(ctx, device, path)
+}
+
+// kdeConnectShare shares the file at path with the paired device named device
+func kdeConnectShare(ctx context.Context, device, path string) error {
+	output, err := exec.CommandContext(ctx, "kdeconnect-cli", "--name", device, "--share", path).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("kdeconnect-cli: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// publish sends the image as the attachment of a silent ntfy notification
+// naming the track
+func (p *Pusher) publish(ctx context.Context, j job, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, j.settings.Target, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "synestDaemon/1.0")
+	req.Header.Set("Filename", Filename)
+	// A notification for every track change, the phone should not buzz each time
+	req.Header.Set("Priority", "min")
+	if j.title != "" {
+		req.Header.Set("Title", mime.QEncoding.Encode("utf-8", j.title))
+	}
+	if j.message != "" {
+		req.Header.Set("Message", mime.QEncoding.Encode("utf-8", j.message))
+	}
+	if j.settings.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+j.settings.Token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("ntfy: %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	return nil
+}
+
+// Stop ends the push in flight and discards the queued one
+func (p *Pusher) Stop(ctx context.Context) error {
+	return p.worker.Stop(ctx)
+}
@@ -0,0 +1,170 @@
+package phone
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/jpeg"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/breaker"
+	"github.com/genricoloni/synest/internal/clock"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/privacy"
+	"go.uber.org/zap"
+)
+
+// TestPusher_Ntfy verifies the wallpaper is published cropped to the phone
+// aspect, with the track as a silent notification
+func TestPusher_Ntfy(t *testing.T) {
+	type request struct {
+		header http.Header
+		body   []byte
+	}
+	requests := make(chan request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- request{header: r.Header, body: body}
+	}))
+	defer server.Close()
+
+	cfg := &mockConfig{push: domain.PhonePush{Kind: domain.PushNtfy, Target: server.URL + "/synest", Token: "tk_secret", Aspect: 0.5}}
+	p := NewPusher(zap.NewNop(), cfg, &fakeReporter{}, &fakeMetered{}, breaker.NewSet(zap.NewNop(), clock.New()))
+	defer p.Stop(context.Background())
+
+	p.Success(privacy.WithPrivate(context.Background()), writeWallpaper(t, 200, 100), domain.MediaMetadata{Title: "Secret"})
+	p.Success(context.Background(), writeWallpaper(t, 200, 100), domain.MediaMetadata{Title: "Café", Artist: "Artist"})
+
+	select {
+	case req := <-requests:
+		dec := new(mime.WordDecoder)
+		if title, _ := dec.DecodeHeader(req.header.Get("Title")); title != "Café" {
+			t.Errorf("expected the track title, got %q", title)
+		}
+		if req.header.Get("Authorization") != "Bearer tk_secret" || req.header.Get("Priority") != "min" || req.header.Get("Filename") != Filename {
+			t.Errorf("unexpected headers: %v", req.header)
+		}
+		img, err := jpeg.Decode(bytes.NewReader(req.body))
+		if err != nil {
+			t.Fatalf("pushed image does not decode: %v", err)
+		}
+		if img.Bounds().Dx() != 50 || img.Bounds().Dy() != 100 {
+			t.Errorf("expected a 50x100 crop, got %v", img.Bounds())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("nothing pushed")
+	}
+	select {
+	case req := <-requests:
+		t.Errorf("expected a single push, got another one titled %q", req.header.Get("Title"))
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestPusher_KDEConnect verifies the wallpaper is written to the output
+// directory and shared with the device
+func TestPusher_KDEConnect(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &mockConfig{push: domain.PhonePush{Kind: domain.PushKDEConnect, Target: "Pixel"}, outputDir: dir}
+	p := NewPusher(zap.NewNop(), cfg, &fakeReporter{}, &fakeMetered{}, breaker.NewSet(zap.NewNop(), clock.New()))
+	defer p.Stop(context.Background())
+
+	shared := make(chan [2]string, 1)
+	p.share = func(ctx context.Context, device, path string) error {
+		shared <- [2]string{device, path}
+		return nil
+	}
+	p.Success(context.Background(), writeWallpaper(t, 200, 100), domain.MediaMetadata{Title: "Song"})
+
+	select {
+	case got := <-shared:
+		if got != [2]string{"Pixel", filepath.Join(dir, Filename)} {
+			t.Errorf("unexpected share: %v", got)
+		}
+		if _, err := os.Stat(got[1]); err != nil {
+			t.Errorf("expected the shared wallpaper written: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("nothing shared")
+	}
+}
+
+func TestCropSize(t *testing.T) {
+	tests := []struct {
+		bounds        image.Rectangle
+		aspect        float64
+		width, height int
+	}{
+		{image.Rect(0, 0, 1920, 1080), 9 / 19.5, 498, 1080},
+		{image.Rect(0, 0, 1080, 1920), 16.0 / 9, 1080, 608},
+		{image.Rect(0, 0, 100, 200), 0.5, 100, 200},
+	}
+	for _, tt := range tests {
+		if width, height := cropSize(tt.bounds, tt.aspect); width != tt.width || height != tt.height {
+			t.Errorf("cropSize(%v, %v) = %dx%d, expected %dx%d", tt.bounds, tt.aspect, width, height, tt.width, tt.height)
+		}
+	}
+}
+
+// writeWallpaper writes a JPEG wallpaper of the given size and returns its path
+func writeWallpaper(t *testing.T, width, height int) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)), nil); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "wallpaper.jpg")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// fakeReporter implements domain.Reporter without recording anything
+type fakeReporter struct{}
+
+func (f *fakeReporter) Success(ctx context.Context, wallpaperPath string, meta domain.MediaMetadata) {
+}
+
+func (f *fakeReporter) Failure(ctx context.Context, step string, err error) {}
+
+func (f *fakeReporter) SetterSelected(name, reason string) {}
+
+// fakeMetered reports a fixed metered mode
+type fakeMetered struct {
+	metered bool
+}
+
+func (f *fakeMetered) Metered(ctx context.Context) bool {
+	return f.metered
+}
+
+// mockConfig implements the parts of domain.Config used by the pusher
+type mockConfig struct {
+	domain.Config
+	push      domain.PhonePush
+	outputDir string
+}
+
+func (m *mockConfig) GetPhonePush() domain.PhonePush {
+	return m.push
+}
+
+func (m *mockConfig) GetScene() string {
+	return domain.SceneOff
+}
+
+func (m *mockConfig) GetOutputDir() string {
+	return m.outputDir
+}
+
+func (m *mockConfig) GetJPEGQuality() int {
+	return 90
+}
@@ -0,0 +1,97 @@
+// Package power detects when synest should save power, e.g. on a laptop
+// running on battery.
+package power
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// sysfsPowerSupply is the kernel's power supply class directory
+const sysfsPowerSupply = "/sys/class/power_supply"
+
+// Detector implements domain.PowerSource. The power source is read from UPower,
+// or from sysfs when UPower is not running.
+type Detector struct {
+	logger *zap.Logger
+	cfg    domain.Config
+	sysfs  string                                  // Power supply class directory
+	upower func(ctx context.Context) (bool, error) // Reports whether UPower sees the machine on battery
+}
+
+// NewDetector creates a power source detector
+func NewDetector(logger *zap.Logger, cfg domain.Config) *Detector {
+	return &Detector{
+		logger: logger,
+		cfg:    cfg,
+		sysfs:  sysfsPowerSupply,
+		upower: queryUPower,
+	}
+}
+
+// SavePower reports whether the battery saver applies now: always or never when
+// forced by configuration, otherwise while running on battery. An unknown power
+// source counts as mains power.
+func (d *Detector) SavePower(ctx context.Context) bool {
+	switch d.cfg.GetBatterySaver() {
+	case domain.BatterySaverOn:
+		return true
+	case domain.BatterySaverOff:
+		return false
+	}
+
+	onBattery, err := d.onBattery(ctx)
+	if err != nil {
+		d.logger.Debug("Power source unknown, assuming mains power", zap.Error(err))
+		return false
+	}
+	return onBattery
+}
+
+// onBattery asks UPower, falling back to sysfs
+func (d *Detector) onBattery(ctx context.Context) (bool, error) {
+	onBattery, err := d.upower(ctx)
+	if err == nil {
+		return onBattery, nil
+	}
+	d.logger.Debug("UPower unavailable, reading sysfs", zap.Error(err))
+	return readSysfs(d.sysfs)
+}
+
+// readSysfs reports running on battery when the machine has a power adapter
+// and none is online. Machines without an adapter, like most desktops, run on
+// mains power.
+func readSysfs(dir string) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false, fmt.Errorf("failed to read power supplies: %w", err)
+	}
+
+	adapters := 0
+	for _, entry := range entries {
+		kind := readValue(filepath.Join(dir, entry.Name(), "type"))
+		if kind != "Mains" && kind != "USB" {
+			continue // Batteries and peripherals
+		}
+		adapters++
+		if readValue(filepath.Join(dir, entry.Name(), "online")) == "1" {
+			return false, nil
+		}
+	}
+	return adapters > 0, nil
+}
+
+// readValue returns the trimmed content of a sysfs attribute, empty when unreadable
+func readValue(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,113 @@
+package power
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+func TestReadSysfs(t *testing.T) {
+	tests := []struct {
+		name     string
+		supplies map[string]map[string]string
+		expected bool
+	}{
+		{
+			name:     "Desktop",
+			supplies: map[string]map[string]string{},
+			expected: false,
+		},
+		{
+			name: "Plugged In",
+			supplies: map[string]map[string]string{
+				"AC":   {"type": "Mains", "online": "1"},
+				"BAT0": {"type": "Battery"},
+			},
+			expected: false,
+		},
+		{
+			name: "On Battery",
+			supplies: map[string]map[string]string{
+				"AC":   {"type": "Mains", "online": "0"},
+				"BAT0": {"type": "Battery"},
+			},
+			expected: true,
+		},
+		{
+			name: "USB-C Charger",
+			supplies: map[string]map[string]string{
+				"AC":   {"type": "Mains", "online": "0"},
+				"ucsi": {"type": "USB", "online": "1"},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, attributes := range tt.supplies {
+				if err := os.Mkdir(filepath.Join(dir, name), 0755); err != nil {
+					t.Fatal(err)
+				}
+				for attribute, value := range attributes {
+					if err := os.WriteFile(filepath.Join(dir, name, attribute), []byte(value+"\n"), 0644); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			onBattery, err := readSysfs(dir)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if onBattery != tt.expected {
+				t.Errorf("expected on battery %v, got %v", tt.expected, onBattery)
+			}
+		})
+	}
+}
+
+func TestSavePower(t *testing.T) {
+	onBattery := func(ctx context.Context) (bool, error) { return true, nil }
+	noUPower := func(ctx context.Context) (bool, error) { return false, errors.New("not running") }
+
+	tests := []struct {
+		name     string
+		saver    string
+		upower   func(ctx context.Context) (bool, error)
+		expected bool
+	}{
+		{name: "Auto On Battery", saver: domain.BatterySaverAuto, upower: onBattery, expected: true},
+		{name: "Forced Off", saver: domain.BatterySaverOff, upower: onBattery, expected: false},
+		{name: "Forced On", saver: domain.BatterySaverOn, upower: noUPower, expected: true},
+		{name: "Unknown Source", saver: domain.BatterySaverAuto, upower: noUPower, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDetector(zap.NewNop(), &mockConfig{saver: tt.saver})
+			d.upower = tt.upower
+			d.sysfs = filepath.Join(t.TempDir(), "missing")
+
+			if got := d.SavePower(context.Background()); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// mockConfig implements the parts of domain.Config used by the detector
+type mockConfig struct {
+	domain.Config
+	saver string
+}
+
+func (m *mockConfig) GetBatterySaver() string {
+	return m.saver
+}
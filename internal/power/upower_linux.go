@@ -0,0 +1,37 @@
+//go:build linux
+// +build linux
+
+package power
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	upowerName = "org.freedesktop.UPower"
+	upowerPath = "/org/freedesktop/UPower"
+)
+
+// queryUPower reads the OnBattery property of UPower on the system bus
+func queryUPower(ctx context.Context) (bool, error) {
+	conn, err := dbus.SystemBus() // Shared connection, kept open for later queries
+	if err != nil {
+		return false, fmt.Errorf("system bus connection failed: %w", err)
+	}
+
+	var value dbus.Variant
+	err = conn.Object(upowerName, upowerPath).
+		CallWithContext(ctx, "org.freedesktop.DBus.Properties.Get", 0, upowerName, "OnBattery").
+		Store(&value)
+	if err != nil {
+		return false, fmt.Errorf("failed to query UPower: %w", err)
+	}
+	onBattery, ok := value.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("unexpected UPower OnBattery value %v", value)
+	}
+	return onBattery, nil
+}
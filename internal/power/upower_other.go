@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package power
+
+import (
+	"context"
+	"errors"
+)
+
+// queryUPower returns an error, UPower is only available on Linux
+func queryUPower(ctx context.Context) (bool, error) {
+	return false, errors.New("UPower is only supported on Linux systems")
+}
@@ -0,0 +1,38 @@
+// Package privacy decides which tracks are played in private mode and marks their
+// pipeline runs, so components can leave them out of notifications and exports.
+package privacy
+
+import (
+	"context"
+	"slices"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+type privateKey struct{}
+
+// WithPrivate returns a copy of ctx marking the pipeline run as private
+func WithPrivate(ctx context.Context) context.Context {
+	return context.WithValue(ctx, privateKey{}, true)
+}
+
+// Private reports whether ctx belongs to a private pipeline run
+func Private(ctx context.Context) bool {
+	private, _ := ctx.Value(privateKey{}).(bool)
+	return private
+}
+
+// ModeFor returns the private mode that applies to a track: the configured mode
+// when no players are listed or its player or source is one of them, PrivateOff otherwise
+func ModeFor(cfg domain.Config, meta domain.MediaMetadata) string {
+	mode := cfg.GetPrivateMode()
+	if mode == "" || mode == domain.PrivateOff {
+		return domain.PrivateOff
+	}
+	players := cfg.GetPrivatePlayers()
+	if len(players) > 0 && !slices.Contains(players, meta.Player) &&
+		(meta.Source == "" || !slices.Contains(players, meta.Source)) {
+		return domain.PrivateOff
+	}
+	return mode
+}
@@ -0,0 +1,60 @@
+package privacy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+func TestModeFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		players  []string
+		player   string
+		source   string
+		expected string
+	}{
+		{name: "Unset", player: "spotify", expected: domain.PrivateOff},
+		{name: "Global", mode: domain.PrivateOn, player: "spotify", expected: domain.PrivateOn},
+		{name: "Listed Player", mode: domain.PrivateFreeze, players: []string{"firefox"}, player: "firefox", expected: domain.PrivateFreeze},
+		{name: "Other Player", mode: domain.PrivateOn, players: []string{"firefox"}, player: "spotify", expected: domain.PrivateOff},
+		{name: "Listed Source", mode: domain.PrivateOn, players: []string{"youtube"}, player: "firefox", source: "youtube", expected: domain.PrivateOn},
+		{name: "Other Source", mode: domain.PrivateOn, players: []string{"youtube"}, player: "firefox", source: "bandcamp", expected: domain.PrivateOff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ModeFor(&mockConfig{mode: tt.mode, players: tt.players}, domain.MediaMetadata{Player: tt.player, Source: tt.source})
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPrivate(t *testing.T) {
+	if Private(context.Background()) {
+		t.Error("expected runs to be public by default")
+	}
+	if !Private(WithPrivate(context.Background())) {
+		t.Error("expected a marked run to be private")
+	}
+}
+
+// mockConfig implements the parts of domain.Config used by ModeFor.
+// Other getters are promoted from the nil embedded interface and must not be called.
+type mockConfig struct {
+	domain.Config
+	mode    string
+	players []string
+}
+
+func (m *mockConfig) GetPrivateMode() string {
+	return m.mode
+}
+
+func (m *mockConfig) GetPrivatePlayers() []string {
+	return m.players
+}
@@ -0,0 +1,105 @@
+package processor
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/disintegration/imaging"
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+// maxBorderFraction is the largest share of a side a single border may take.
+// Past it the uniform area is most likely part of the picture, such as a night sky.
+const maxBorderFraction = 0.25
+
+// shapeCover prepares artwork for compositing: solid borders are trimmed first when
+// enabled, then the result is center-cropped to a square or left as is
+func shapeCover(img image.Image, cfg ProcessorConfig) image.Image {
+	if cfg.TrimBorders || cfg.CoverAspect == domain.CoverAspectTrim {
+		if content := trimBounds(img, cfg.TrimTolerance); content != img.Bounds() {
+			img = imaging.Crop(img, content)
+		}
+	}
+
+	b := img.Bounds()
+	if cfg.CoverAspect == domain.CoverAspectSquare && b.Dx() != b.Dy() {
+		side := min(b.Dx(), b.Dy())
+		return imaging.CropCenter(img, side, side)
+	}
+	return img
+}
+
+// trimBounds returns the bounds of img without its solid borders. Each edge is
+// trimmed while its outer line stays within tolerance of the corner color;
+// borders wider than maxBorderFraction of the side are kept.
+func trimBounds(img image.Image, tolerance int) image.Rectangle {
+	b := img.Bounds()
+	maxRows := int(float64(b.Dy()) * maxBorderFraction)
+	maxCols := int(float64(b.Dx()) * maxBorderFraction)
+
+	topRef, bottomRef := img.At(b.Min.X, b.Min.Y), img.At(b.Min.X, b.Max.Y-1)
+	top := borderWidth(maxRows, func(i int) bool {
+		return rowMatches(img, b.Min.Y+i, b.Min.X, b.Max.X, topRef, tolerance)
+	})
+	bottom := borderWidth(maxRows, func(i int) bool {
+		return rowMatches(img, b.Max.Y-1-i, b.Min.X, b.Max.X, bottomRef, tolerance)
+	})
+
+	// Side borders are measured between the top and bottom ones
+	y0, y1 := b.Min.Y+top, b.Max.Y-bottom
+	leftRef, rightRef := img.At(b.Min.X, y0), img.At(b.Max.X-1, y0)
+	left := borderWidth(maxCols, func(i int) bool {
+		return columnMatches(img, b.Min.X+i, y0, y1, leftRef, tolerance)
+	})
+	right := borderWidth(maxCols, func(i int) bool {
+		return columnMatches(img, b.Max.X-1-i, y0, y1, rightRef, tolerance)
+	})
+
+	return image.Rect(b.Min.X+left, y0, b.Max.X-right, y1)
+}
+
+// borderWidth counts the lines from an edge for which matches holds,
+// or returns 0 when there are more than limit of them
+func borderWidth(limit int, matches func(i int) bool) int {
+	n := 0
+	for n <= limit && matches(n) {
+		n++
+	}
+	if n > limit {
+		return 0
+	}
+	return n
+}
+
+// rowMatches reports whether every pixel of row y in [x0, x1) is close to ref
+func rowMatches(img image.Image, y, x0, x1 int, ref color.Color, tolerance int) bool {
+	for x := x0; x < x1; x++ {
+		if !similar(img.At(x, y), ref, tolerance) {
+			return false
+		}
+	}
+	return true
+}
+
+// columnMatches reports whether every pixel of column x in [y0, y1) is close to ref
+func columnMatches(img image.Image, x, y0, y1 int, ref color.Color, tolerance int) bool {
+	for y := y0; y < y1; y++ {
+		if !similar(img.At(x, y), ref, tolerance) {
+			return false
+		}
+	}
+	return true
+}
+
+// similar reports whether two colors differ by at most tolerance levels in every channel
+func similar(a, b color.Color, tolerance int) bool {
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	for _, d := range [][2]uint32{{ar, br}, {ag, bg}, {ab, bb}, {aa, ba}} {
+		diff := int(d[0]>>8) - int(d[1]>>8)
+		if diff < -tolerance || diff > tolerance {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package processor
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+// TestShapeCover verifies the aspect settings: letterboxed art loses its bars
+// when trimmed, wide art is center-cropped to a square and preserve keeps it whole
+func TestShapeCover(t *testing.T) {
+	// 120x80 art: a red 60x60 picture between black bars, with a JPEG-like speck of noise
+	art := image.NewNRGBA(image.Rect(0, 0, 120, 80))
+	draw.Draw(art, art.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	draw.Draw(art, image.Rect(30, 10, 90, 70), image.NewUniform(color.RGBA{R: 220, A: 255}), image.Point{}, draw.Src)
+	art.Set(5, 5, color.RGBA{R: 10, G: 10, B: 10, A: 255})
+
+	tests := []struct {
+		name string
+		cfg  ProcessorConfig
+		want image.Point
+	}{
+		{"preserve", ProcessorConfig{CoverAspect: domain.CoverAspectPreserve}, image.Pt(120, 80)},
+		{"square", ProcessorConfig{CoverAspect: domain.CoverAspectSquare}, image.Pt(80, 80)},
+		{"trim", ProcessorConfig{CoverAspect: domain.CoverAspectTrim, TrimTolerance: 24}, image.Pt(60, 60)},
+		{"trim borders", ProcessorConfig{CoverAspect: domain.CoverAspectPreserve, TrimBorders: true, TrimTolerance: 24}, image.Pt(60, 60)},
+		{"strict tolerance", ProcessorConfig{CoverAspect: domain.CoverAspectPreserve, TrimBorders: true}, image.Pt(85, 65)},
+	}
+	for _, tt := range tests {
+		if got := shapeCover(art, tt.cfg).Bounds().Size(); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+// TestTrimBounds verifies uniform areas too large to be borders are kept
+func TestTrimBounds(t *testing.T) {
+	uniform := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	if got := trimBounds(uniform, 24); got != uniform.Bounds() {
+		t.Errorf("expected a uniform image to be kept whole, got %v", got)
+	}
+
+	// A dark sky over the top half of the cover is part of the picture
+	sky := image.NewNRGBA(image.Rect(0, 0, 40, 40))
+	draw.Draw(sky, image.Rect(0, 20, 40, 40), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(sky, image.Rect(0, 0, 40, 20), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	if got := trimBounds(sky, 24); got != sky.Bounds() {
+		t.Errorf("expected the sky to be kept, got %v", got)
+	}
+}
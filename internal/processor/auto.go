@@ -0,0 +1,116 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"math"
+	"strings"
+
+	"github.com/disintegration/imaging"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/logctx"
+	"go.uber.org/zap"
+)
+
+const (
+	autoSampleSize    = 32    // Artwork is downscaled to this size before measuring
+	autoMinVariance   = 0.08  // Below this color spread the cover is nearly a single color
+	autoMinDetail     = 0.005 // Below this the cover has no edges or texture at all
+	autoMinBrightness = 0.08  // Below this mean luma a blurred cover is a black screen
+)
+
+// artworkStats describes the artwork characteristics the auto mode decides on,
+// all in [0, 1]
+type artworkStats struct {
+	Brightness float64 // Mean luma
+	Variance   float64 // Standard deviation of the color channels
+	Detail     float64 // Mean luma difference between neighboring pixels
+}
+
+// measureArtwork computes the statistics of a downscaled copy of img
+func measureArtwork(img image.Image) artworkStats {
+	thumb := imaging.Resize(img, autoSampleSize, autoSampleSize, imaging.Box)
+	w, h := thumb.Bounds().Dx(), thumb.Bounds().Dy()
+
+	luma := make([]float64, w*h)
+	var sum, sumSq [3]float64
+	for i := range luma {
+		px := thumb.Pix[i*4 : i*4+3]
+		for c := range 3 {
+			v := float64(px[c]) / 255
+			sum[c] += v
+			sumSq[c] += v * v
+		}
+		luma[i] = (0.299*float64(px[0]) + 0.587*float64(px[1]) + 0.114*float64(px[2])) / 255
+	}
+
+	n := float64(len(luma))
+	var stats artworkStats
+	var variance float64
+	for c := range 3 {
+		mean := sum[c] / n
+		variance += max(sumSq[c]/n-mean*mean, 0)
+	}
+	stats.Variance = math.Sqrt(variance / 3)
+
+	var lumaSum, detail float64
+	var edges int
+	for y := range h {
+		for x := range w {
+			v := luma[y*w+x]
+			lumaSum += v
+			if x+1 < w {
+				detail += math.Abs(v - luma[y*w+x+1])
+				edges++
+			}
+			if y+1 < h {
+				detail += math.Abs(v - luma[(y+1)*w+x])
+				edges++
+			}
+		}
+	}
+	stats.Brightness = lumaSum / n
+	if edges > 0 {
+		stats.Detail = detail / float64(edges)
+	}
+	return stats
+}
+
+// chooseMode picks the mode for a track: a genre rule wins, otherwise very dark or
+// flat covers get generative art and everything else the blur mode.
+// It also returns the reason, for the decision log.
+func chooseMode(src image.Image, meta domain.MediaMetadata, genres map[string]string) (mode, reason string) {
+	if src == nil {
+		return domain.ModeGenerative, "no artwork"
+	}
+	for _, genre := range meta.Genres {
+		if mode, ok := genres[strings.ToLower(strings.TrimSpace(genre))]; ok {
+			return mode, "genre " + strings.ToLower(genre)
+		}
+	}
+
+	stats := measureArtwork(src)
+	switch {
+	case stats.Brightness < autoMinBrightness:
+		return domain.ModeGenerative, fmt.Sprintf("dark artwork (brightness %.2f)", stats.Brightness)
+	case stats.Variance < autoMinVariance || stats.Detail < autoMinDetail:
+		return domain.ModeGenerative, fmt.Sprintf("flat artwork (variance %.2f, detail %.3f)", stats.Variance, stats.Detail)
+	default:
+		return domain.ModeBlur, fmt.Sprintf("detailed artwork (variance %.2f, detail %.3f, brightness %.2f)",
+			stats.Variance, stats.Detail, stats.Brightness)
+	}
+}
+
+// renderAuto renders the track in the mode picked by chooseMode
+func (p *BlurProcessor) renderAuto(ctx context.Context, src image.Image, meta domain.MediaMetadata) (image.Image, error) {
+	mode, reason := chooseMode(src, meta, p.appCfg.GetAutoGenres())
+	logctx.Logger(ctx, p.logger).Info("Auto mode selected",
+		zap.String("mode", mode),
+		zap.String("reason", reason))
+
+	if _, ok := p.modes[mode]; !ok || mode == domain.ModeAuto {
+		return nil, fmt.Errorf("auto mode selected unknown wallpaper mode: %q", mode)
+	}
+	return p.renderCapped(ctx, src, meta, mode)
+}
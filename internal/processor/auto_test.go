@@ -0,0 +1,79 @@
+package processor
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+func TestChooseMode(t *testing.T) {
+	solid := func(c color.NRGBA) image.Image {
+		img := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+		for i := 0; i < len(img.Pix); i += 4 {
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+		}
+		return img
+	}
+	dark := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			v := uint8((x/4 + y/4) % 2 * 30) // Faint checkerboard on black
+			dark.SetNRGBA(x, y, color.NRGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+	genres := map[string]string{"ambient": domain.ModeWaveform}
+
+	tests := []struct {
+		name     string
+		src      image.Image
+		genres   []string
+		expected string
+		reason   string
+	}{
+		{"No Artwork", nil, nil, domain.ModeGenerative, "no artwork"},
+		{"Detailed Artwork", goldenArtwork(), nil, domain.ModeBlur, "detailed artwork"},
+		{"Flat Artwork", solid(color.NRGBA{R: 200, G: 40, B: 40, A: 255}), nil, domain.ModeGenerative, "flat artwork"},
+		{"Dark Artwork", dark, nil, domain.ModeGenerative, "dark artwork"},
+		{"Genre Rule", goldenArtwork(), []string{"Electronic", "Ambient"}, domain.ModeWaveform, "genre ambient"},
+		{"Genre Without Rule", goldenArtwork(), []string{"Jazz"}, domain.ModeBlur, "detailed artwork"},
+		{"No Artwork Ignores Genre", nil, []string{"Ambient"}, domain.ModeGenerative, "no artwork"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode, reason := chooseMode(tt.src, domain.MediaMetadata{Genres: tt.genres}, genres)
+			if mode != tt.expected {
+				t.Errorf("expected mode %s, got %s (%s)", tt.expected, mode, reason)
+			}
+			if !strings.HasPrefix(reason, tt.reason) {
+				t.Errorf("expected reason %q, got %q", tt.reason, reason)
+			}
+		})
+	}
+}
+
+func TestRenderAuto(t *testing.T) {
+	res := &domain.ScreenResolution{Width: 64, Height: 36}
+	processor := NewBlurProcessor(zap.NewNop(), res, &mockConfig{deterministic: true})
+
+	got, err := processor.render(context.Background(), goldenArtwork(), domain.MediaMetadata{}, domain.ModeAuto)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	want, err := processor.render(context.Background(), goldenArtwork(), domain.MediaMetadata{}, domain.ModeBlur)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if score := ssim(want, got); score < 0.999 {
+		t.Errorf("expected auto mode to render the blur wallpaper, SSIM %.4f", score)
+	}
+
+	if _, err := processor.render(context.Background(), nil, domain.MediaMetadata{}, domain.ModeAuto); err != nil {
+		t.Errorf("auto mode without artwork should render generative art: %v", err)
+	}
+}
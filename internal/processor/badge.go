@@ -0,0 +1,82 @@
+package processor
+
+import (
+	"context"
+	"image"
+	"os"
+	"sync"
+
+	"github.com/disintegration/imaging"
+	"github.com/genricoloni/synest/internal/desktop"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/logctx"
+	"go.uber.org/zap"
+)
+
+// badgeCache keeps the last player icon scaled for the badge, the player
+// rarely changes between tracks
+type badgeCache struct {
+	mu   sync.Mutex
+	path string
+	size int
+	icon image.Image
+}
+
+// addBadge draws the icon of the player in the configured corner of the
+// wallpaper, so screenshots show where the music came from. The wallpaper is
+// left as is when the badge is off or the player has no PNG icon.
+func (p *BlurProcessor) addBadge(ctx context.Context, img image.Image, meta domain.MediaMetadata) image.Image {
+	corner := p.appCfg.GetPlayerBadge()
+	if corner == domain.BadgeOff || corner == "" || meta.PlayerDesktopEntry == "" {
+		return img
+	}
+
+	size := p.appCfg.GetPlayerBadgeSize()
+	icon, err := p.badge.load(desktop.IconFile(meta.PlayerDesktopEntry, size), size)
+	if err != nil {
+		logctx.Logger(ctx, p.logger).Debug("No player icon for the badge",
+			zap.String("desktopEntry", meta.PlayerDesktopEntry), zap.Error(err))
+		return img
+	}
+	return drawBadge(img, icon, corner)
+}
+
+// load returns the icon at path scaled to fit size pixels
+func (c *badgeCache) load(path string, size int) (image.Image, error) {
+	if path == "" {
+		return nil, os.ErrNotExist
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.icon != nil && c.path == path && c.size == size {
+		return c.icon, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	icon, err := decodeImage(data)
+	if err != nil {
+		return nil, err
+	}
+	c.path, c.size, c.icon = path, size, imaging.Fit(icon, size, size, imaging.Lanczos)
+	return c.icon, nil
+}
+
+// drawBadge blends icon into the given corner of img, half its size away from
+// the edges
+func drawBadge(img, icon image.Image, corner string) image.Image {
+	b, size := img.Bounds(), icon.Bounds().Size()
+	margin := max(size.X, size.Y) / 2
+
+	x, y := b.Min.X+margin, b.Min.Y+margin
+	if corner == domain.BadgeTopRight || corner == domain.BadgeBottomRight {
+		x = b.Max.X - margin - size.X
+	}
+	if corner == domain.BadgeBottomLeft || corner == domain.BadgeBottomRight {
+		y = b.Max.Y - margin - size.Y
+	}
+	return imaging.Overlay(img, icon, image.Pt(x, y), 1)
+}
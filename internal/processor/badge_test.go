@@ -0,0 +1,64 @@
+package processor
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// TestAddBadge verifies the player icon is drawn in the configured corner
+// only, and the wallpaper left as is for players without an icon
+func TestAddBadge(t *testing.T) {
+	data := t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", data)
+	t.Setenv("XDG_DATA_DIRS", data)
+
+	apps := filepath.Join(data, "applications")
+	icons := filepath.Join(data, "icons", "hicolor", "64x64", "apps")
+	for _, dir := range []string{apps, icons} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(apps, "spotify.desktop"), []byte("[Desktop Entry]\nIcon=spotify-client\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	red := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	draw.Draw(red, red.Bounds(), image.NewUniform(color.NRGBA{R: 255, A: 255}), image.Point{}, draw.Src)
+	file, err := os.Create(filepath.Join(icons, "spotify-client.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(file, red); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	res := &domain.ScreenResolution{Width: 320, Height: 240}
+	p := NewBlurProcessor(zap.NewNop(), res, &mockConfig{playerBadge: domain.BadgeBottomRight})
+	wallpaper := image.NewNRGBA(image.Rect(0, 0, res.Width, res.Height))
+	meta := domain.MediaMetadata{PlayerDesktopEntry: "spotify"}
+
+	badged := p.addBadge(context.Background(), wallpaper, meta)
+	// The 48px badge sits 24px away from the bottom and right edges
+	if got := color.NRGBAModel.Convert(badged.At(320-24-24, 240-24-24)).(color.NRGBA); got.R < 200 || got.G > 50 {
+		t.Errorf("expected the icon in the bottom-right corner, got %+v", got)
+	}
+	if got := color.NRGBAModel.Convert(badged.At(48, 48)).(color.NRGBA); got.R != 0 {
+		t.Errorf("expected the top-left corner untouched, got %+v", got)
+	}
+
+	meta.PlayerDesktopEntry = "mpv"
+	if p.addBadge(context.Background(), wallpaper, meta) != image.Image(wallpaper) {
+		t.Error("expected the wallpaper as is for a player without an icon")
+	}
+}
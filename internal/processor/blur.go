@@ -10,118 +10,350 @@ import (
 	_ "image/png"  // PNG format support
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
 
 	"github.com/disintegration/imaging"
 	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/hook"
+	"github.com/genricoloni/synest/internal/logctx"
+	"github.com/genricoloni/synest/internal/paths"
+	"github.com/genricoloni/synest/internal/privacy"
 	"go.uber.org/zap"
 )
 
 const (
-	defaultBlurRadius = 15.0
-	coverHeightRatio  = 0.40 // Cover size as percentage of screen height
 	wallpaperFilename = "current_wallpaper.jpg"
+	maxImageDimension = 10000      // Largest accepted artwork side, in pixels
+	maxImagePixels    = 40_000_000 // Largest accepted artwork area (decompression bomb guard)
 )
 
-// ProcessorConfig holds configuration for image processing
+// ProcessorConfig holds the image processing settings shared by all modes,
+// the per-mode ones come from domain.ModeConfig
 type ProcessorConfig struct {
-	BlurRadius       float64
-	CoverSizePercent float64 // Cover size as percentage of screen height (0.0-1.0)
+	JPEGQuality      int                    // Quality of the encoded wallpaper (1-100)
+	Deterministic    bool                   // Ignore the environment, e.g. the battery, for byte-identical output
+	BackgroundFilter imaging.ResampleFilter // Scales the cover to fill the background
+	CoverFilter      imaging.ResampleFilter // Scales the sharp cover and its thumbnails
+	CoverAspect      string                 // Shapes non-square artwork before compositing
+	TrimBorders      bool                   // Trim solid borders baked into the artwork
+	TrimTolerance    int                    // Channel levels a border pixel may stray from the border color
 }
 
-// BlurProcessor applies Gaussian blur and resizing to album art images
+// resampleFilters maps the filter names of domain.Filters to their implementation
+var resampleFilters = map[string]imaging.ResampleFilter{
+	domain.FilterNearest:    imaging.NearestNeighbor,
+	domain.FilterBox:        imaging.Box,
+	domain.FilterLinear:     imaging.Linear,
+	domain.FilterCatmullRom: imaging.CatmullRom,
+	domain.FilterLanczos:    imaging.Lanczos,
+}
+
+// resampleFilter returns the named filter, Lanczos for unknown names
+func resampleFilter(name string) imaging.ResampleFilter {
+	if filter, ok := resampleFilters[name]; ok {
+		return filter
+	}
+	return imaging.Lanczos
+}
+
+// renderFunc produces the final wallpaper image for a single generation mode.
+// src is nil when no artwork is available.
+type renderFunc func(ctx context.Context, src image.Image, meta domain.MediaMetadata) (image.Image, error)
+
+// BlurProcessor applies Gaussian blur and resizing to album art images.
+// It also dispatches Generate calls to the other registered modes.
 type BlurProcessor struct {
 	logger *zap.Logger
 	res    *domain.ScreenResolution // Injected automatically by Fx
-	config ProcessorConfig
-	appCfg domain.Config // Application configuration for output dir
+	appCfg domain.Config            // Application configuration, read on every render so reloads apply
+	modes  map[string]renderFunc    // Registered wallpaper modes keyed by name
+
+	variations map[string]int // Distinct renderings per mode for long tracks, 1 when absent
+	maxHeights map[string]int // Render height caps declared by expensive modes, the user setting overrides them
+
+	outputs []*BlurProcessor // One per display when a single image spans several, see span.go
+
+	delivery string // Read once, a reload changing it rebuilds the processor with the setter
+	memfdMu  sync.Mutex
+	memfd    *os.File // In-memory wallpaper on screen with memfd delivery
+
+	paletteMu sync.Mutex
+	palette   []string // Dominant colors of the last generated wallpaper
+
+	badge badgeCache // Player icon drawn when the badge is enabled
 }
 
 // NewBlurProcessor creates a new blur-based image processor
 func NewBlurProcessor(logger *zap.Logger, res *domain.ScreenResolution, appCfg domain.Config) *BlurProcessor {
-	return &BlurProcessor{
+	p := &BlurProcessor{
 		logger: logger,
 		res:    res,
 		appCfg: appCfg,
-		config: ProcessorConfig{
-			BlurRadius:       defaultBlurRadius,
-			CoverSizePercent: coverHeightRatio,
-		},
+
+		delivery: appCfg.GetDelivery(),
+	}
+
+	p.modes = map[string]renderFunc{
+		domain.ModeBlur:       p.renderBlur,
+		domain.ModeGenerative: p.renderGenerative,
+		domain.ModeWaveform:   p.renderWaveform,
+		domain.ModeAuto:       p.renderAuto,
+	}
+
+	p.variations = map[string]int{
+		domain.ModeBlur:       len(blurAnchors),
+		domain.ModeGenerative: generativeVariations,
+		domain.ModeAuto:       max(len(blurAnchors), generativeVariations),
+	}
+
+	// Soft gradients lose nothing when scaled up, while the flow field cost grows with the area
+	p.maxHeights = map[string]int{
+		domain.ModeGenerative: generativeMaxHeight,
+	}
+
+	if len(res.Outputs) > 1 {
+		p.outputs = spanOutputs(logger, res, appCfg)
+	}
+
+	return p
+}
+
+// SettingsKey identifies the settings a BlurProcessor reads once, it is rebuilt
+// when they change
+func SettingsKey(cfg domain.Config) string {
+	return cfg.GetDelivery()
+}
+
+// Stop closes the in-memory wallpaper of a processor replaced after a reload
+func (p *BlurProcessor) Stop() {
+	p.memfdMu.Lock()
+	defer p.memfdMu.Unlock()
+	if p.memfd != nil {
+		_ = p.memfd.Close()
+		p.memfd = nil
+	}
+}
+
+// Resize implements domain.Resizer: later wallpapers are rendered at res
+func (p *BlurProcessor) Resize(res domain.ScreenResolution) {
+	*p.res = res
+	p.outputs = nil
+	if len(res.Outputs) > 1 {
+		p.outputs = spanOutputs(p.logger, p.res, p.appCfg)
 	}
 }
 
 // Process transforms image data by creating a blurred background with centered original cover
 func (p *BlurProcessor) Process(ctx context.Context, imageData []byte) ([]byte, error) {
 	// 1. Decode image from bytes
-	img, _, err := image.Decode(bytes.NewReader(imageData))
+	img, err := decodeIsolated(imageData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode image: %w", err)
+		return nil, err
 	}
+	img = shapeCover(img, p.config())
 
-	// Validate image dimensions to prevent division by zero
-	bounds := img.Bounds()
-	if bounds.Dy() == 0 || bounds.Dx() == 0 {
-		return nil, fmt.Errorf("invalid image dimensions: %dx%d", bounds.Dx(), bounds.Dy())
+	// 2. Render blurred composition
+	result, err := p.renderBlur(ctx, img, domain.MediaMetadata{})
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Encode result to JPEG (in-memory buffer)
+	data, err := encodeIsolated(result, p.config().JPEGQuality)
+	if err != nil {
+		return nil, err
+	}
+
+	logctx.Logger(ctx, p.logger).Debug("Image processed successfully", zap.Int("bytes", len(data)))
+	return data, nil
+}
+
+// renderBlur composites the sharp cover at the center of a blurred, screen-filling copy of itself.
+// Variations crop the background from a different part of the cover.
+func (p *BlurProcessor) renderBlur(ctx context.Context, img image.Image, meta domain.MediaMetadata) (image.Image, error) {
+	if img == nil {
+		return nil, fmt.Errorf("%s mode requires artwork", domain.ModeBlur)
 	}
+	logger := logctx.Logger(ctx, p.logger)
+	cfg := p.appCfg.GetModeConfig().Blur
+	filters := p.config()
 
-	// 2. Create blurred background
+	// 1. Create blurred background
 	// Resize (Fill) to cover entire resolution and apply blur
-	p.logger.Debug("Creating blurred background", zap.Int("w", p.res.Width), zap.Int("h", p.res.Height))
-	background := imaging.Fill(img, p.res.Width, p.res.Height, imaging.Center, imaging.Lanczos)
-	background = imaging.Blur(background, p.config.BlurRadius)
+	logger.Debug("Creating blurred background", zap.Int("w", p.res.Width), zap.Int("h", p.res.Height), zap.Bool("lowPower", meta.LowPower))
+	anchor := blurAnchors[variationIndex(meta.Variation, len(blurAnchors))]
+	background := blurredFill(img, p.res.Width, p.res.Height, anchor, cfg.BlurRadius, filters.BackgroundFilter, meta.LowPower)
 
-	// 3. Calculate centered cover dimensions (configurable % of screen height, maintaining aspect ratio)
-	coverHeight := int(float64(p.res.Height) * p.config.CoverSizePercent)
-	coverWidth := coverHeight * bounds.Dx() / bounds.Dy()
+	// 2. Calculate centered cover dimensions (configurable % of screen height, maintaining aspect ratio)
+	coverWidth, coverHeight := p.coverSize(img.Bounds(), cfg.CoverSize)
 
 	// Resize original cover (sharp, no blur)
-	p.logger.Debug("Resizing centered cover", zap.Int("w", coverWidth), zap.Int("h", coverHeight))
-	cover := imaging.Resize(img, coverWidth, coverHeight, imaging.Lanczos)
+	logger.Debug("Resizing centered cover", zap.Int("w", coverWidth), zap.Int("h", coverHeight))
+	cover := imaging.Resize(img, coverWidth, coverHeight, filters.CoverFilter)
 
-	// 4. Composite: paste sharp cover at center of blurred background
+	// 3. Composite: paste sharp cover at center of blurred background
 	centerX := (p.res.Width - coverWidth) / 2
 	centerY := (p.res.Height - coverHeight) / 2
-	result := imaging.Paste(background, cover, image.Pt(centerX, centerY))
+	return imaging.Paste(background, cover, image.Pt(centerX, centerY)), nil
+}
 
-	// 5. Encode result to JPEG (in-memory buffer)
-	buf := new(bytes.Buffer)
-	err = jpeg.Encode(buf, result, &jpeg.Options{Quality: 90})
+// coverSize returns the size of the sharp cover for artwork with the given bounds:
+// the given fraction of the screen height, keeping the aspect ratio but never wider than the screen
+func (p *BlurProcessor) coverSize(bounds image.Rectangle, fraction float64) (width, height int) {
+	height = int(float64(p.res.Height) * fraction)
+	width = height * bounds.Dx() / bounds.Dy()
+
+	// Panoramic artwork would otherwise produce a cover far wider than the screen
+	if width > p.res.Width {
+		width = p.res.Width
+		height = width * bounds.Dy() / bounds.Dx()
+	}
+
+	return max(width, 1), max(height, 1)
+}
+
+// config returns the current image processing parameters
+func (p *BlurProcessor) config() ProcessorConfig {
+	return ProcessorConfig{
+		JPEGQuality:      p.appCfg.GetJPEGQuality(),
+		Deterministic:    p.appCfg.GetDeterministic(),
+		BackgroundFilter: resampleFilter(p.appCfg.GetBackgroundFilter()),
+		CoverFilter:      resampleFilter(p.appCfg.GetCoverFilter()),
+		CoverAspect:      p.appCfg.GetCoverAspect(),
+		TrimBorders:      p.appCfg.GetTrimBorders(),
+		TrimTolerance:    p.appCfg.GetTrimTolerance(),
+	}
+}
+
+// Modes returns the names of the registered wallpaper modes, sorted
+func (p *BlurProcessor) Modes() []string {
+	names := make([]string, 0, len(p.modes))
+	for name := range p.modes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render decodes the artwork, if any, and renders it in the given mode
+// without encoding or delivering the result
+func (p *BlurProcessor) Render(ctx context.Context, imgData []byte, meta domain.MediaMetadata, mode string) (image.Image, error) {
+	src, err := p.decodeArtwork(imgData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to encode result: %w", err)
+		return nil, err
 	}
 
-	p.logger.Debug("Image processed successfully", zap.Int("bytes", buf.Len()))
-	return buf.Bytes(), nil
+	result, err := p.render(ctx, src, meta, mode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process image: %w", err)
+	}
+	return result, nil
+}
+
+// decodeArtwork decodes the artwork if present, some modes can render without it,
+// and shapes it according to the cover aspect setting
+func (p *BlurProcessor) decodeArtwork(imgData []byte) (image.Image, error) {
+	if len(imgData) == 0 {
+		return nil, nil
+	}
+	img, err := decodeIsolated(imgData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process image: %w", err)
+	}
+	return shapeCover(img, p.config()), nil
 }
 
 // Generate creates a wallpaper from album art data and saves it to disk
 // This method satisfies the domain.Processor interface
-func (p *BlurProcessor) Generate(imgData []byte, mode string) (string, error) {
-	// 1. Process image (existing logic)
-	processedData, err := p.Process(context.Background(), imgData)
+func (p *BlurProcessor) Generate(ctx context.Context, imgData []byte, meta domain.MediaMetadata, mode string) (string, error) {
+	// 1. Decode the artwork and render the selected mode
+	src, err := p.decodeArtwork(imgData)
+	if err != nil {
+		return "", err
+	}
+	scene := p.appCfg.GetScene()
+	if scene == domain.SceneOnly {
+		return p.generateScene(ctx, src, meta, mode)
+	}
+	result, err := p.render(ctx, src, meta, mode)
+	if err != nil {
+		return "", fmt.Errorf("failed to process image: %w", err)
+	}
+
+	// Encoder parameters are constant, so deterministic renders stay byte-identical
+	processedData, err := encodeIsolated(result, p.config().JPEGQuality)
 	if err != nil {
 		return "", fmt.Errorf("failed to process image: %w", err)
 	}
 
-	// 2. Ensure output directory exists
+	// User hooks, e.g. a watermark, get the encoded image before its metadata is embedded
+	processedData = hook.ProcessImage(ctx, p.logger, p.appCfg.GetPostProcessHooks(), processedData, meta)
+
+	palette := hexPalette(result, wallpaperColors)
+	p.paletteMu.Lock()
+	p.palette = palette
+	p.paletteMu.Unlock()
+
+	// Like thumbnails, the scene next to the wallpaper never fails it
+	if scene == domain.SceneAlongside {
+		if _, err := p.writeScene(ctx, src, meta, mode, palette); err != nil {
+			logctx.Logger(ctx, p.logger).Warn("Failed to write the scene", zap.Error(err))
+		}
+	}
+
+	// Thumbnails are a convenience for other tools, they never fail the wallpaper
+	if p.appCfg.GetCoverThumbnails() {
+		cfg := p.config()
+		if err := exportThumbnails(src, p.appCfg.GetOutputDir(), cfg.JPEGQuality, cfg.CoverFilter); err != nil {
+			logctx.Logger(ctx, p.logger).Warn("Failed to export cover thumbnails", zap.Error(err))
+		}
+	}
+
+	// Record the provenance so gallery tools can tell where the wallpaper came from,
+	// unless the track is private
+	if !privacy.Private(ctx) {
+		if annotated, err := embedXMP(processedData, newProvenance(palette, meta, mode)); err != nil {
+			logctx.Logger(ctx, p.logger).Warn("Failed to embed wallpaper metadata", zap.Error(err))
+		} else {
+			processedData = annotated
+		}
+	}
+
+	// Hand the wallpaper over in memory when configured, falling back to disk
+	if p.delivery == domain.DeliveryMemfd {
+		memfdPath, err := p.writeMemfd(processedData)
+		if err == nil {
+			logctx.Logger(ctx, p.logger).Info("Wallpaper generated successfully",
+				zap.String("path", memfdPath),
+				zap.Int("size", len(processedData)),
+				zap.String("mode", mode))
+			return memfdPath, nil
+		}
+		logctx.Logger(ctx, p.logger).Warn("In-memory delivery failed, writing wallpaper to disk", zap.Error(err))
+	}
+
+	// 3. Ensure output directory exists
 	outputDir := p.appCfg.GetOutputDir()
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create output directory: %w", err)
+	if err := paths.Ensure(outputDir); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w: %w", domain.ErrOutputUnavailable, err)
 	}
 
-	// 3. Generate output file path
+	// 4. Generate output file path
 	outputPath := filepath.Join(outputDir, wallpaperFilename)
 
-	// 4. Write processed image to disk
+	// 5. Write processed image to disk
 	if err := os.WriteFile(outputPath, processedData, 0644); err != nil {
-		return "", fmt.Errorf("failed to write wallpaper file: %w", err)
+		return "", fmt.Errorf("failed to write wallpaper file: %w: %w", domain.ErrOutputUnavailable, err)
 	}
 
-	p.logger.Info("Wallpaper generated successfully",
+	logctx.Logger(ctx, p.logger).Info("Wallpaper generated successfully",
 		zap.String("path", outputPath),
 		zap.Int("size", len(processedData)),
 		zap.String("mode", mode))
 
-	// 5. Return absolute path
+	// 6. Return absolute path
 	absPath, err := filepath.Abs(outputPath)
 	if err != nil {
 		return outputPath, nil // Return relative path if abs fails
@@ -129,3 +361,109 @@ func (p *BlurProcessor) Generate(imgData []byte, mode string) (string, error) {
 
 	return absPath, nil
 }
+
+// generateScene writes the scene of the track instead of rendering it, and
+// returns its path for the custom setter command
+func (p *BlurProcessor) generateScene(ctx context.Context, src image.Image, meta domain.MediaMetadata, mode string) (string, error) {
+	if _, ok := p.modes[mode]; !ok {
+		return "", fmt.Errorf("unknown wallpaper mode: %q", mode)
+	}
+	palette := hexPalette(src, wallpaperColors)
+	if len(palette) == 0 {
+		_, _, colors := generativePalette(src, meta)
+		palette = hexColors(colors)
+	}
+	scenePath, err := p.writeScene(ctx, src, meta, mode, palette)
+	if err != nil {
+		return "", err
+	}
+	p.paletteMu.Lock()
+	p.palette = palette
+	p.paletteMu.Unlock()
+
+	logctx.Logger(ctx, p.logger).Info("Scene generated successfully",
+		zap.String("path", scenePath),
+		zap.String("mode", mode))
+	return scenePath, nil
+}
+
+// Palette returns the dominant colors of the last generated wallpaper as #rrggbb,
+// most frequent first. It implements domain.PaletteSource.
+func (p *BlurProcessor) Palette() []string {
+	p.paletteMu.Lock()
+	defer p.paletteMu.Unlock()
+	return slices.Clone(p.palette)
+}
+
+// render runs the given mode followed by the post-processing steps shared by all modes
+func (p *BlurProcessor) render(ctx context.Context, src image.Image, meta domain.MediaMetadata, mode string) (image.Image, error) {
+	// The battery state is not part of the input, deterministic renders ignore it
+	if p.config().Deterministic {
+		meta.LowPower = false
+	}
+
+	if len(p.outputs) > 0 {
+		return p.renderSpan(ctx, src, meta, mode)
+	}
+
+	if _, ok := p.modes[mode]; !ok {
+		return nil, fmt.Errorf("unknown wallpaper mode: %q", mode)
+	}
+
+	result, err := p.renderCapped(ctx, src, meta, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mood grading is applied on top of every mode when features are known
+	if meta.Features != nil {
+		result = applyMood(result, meta.Features)
+	}
+
+	// Paused tracks are only rendered when the pause behavior dims the wallpaper
+	if meta.Status == domain.StatusPaused {
+		result = applyPause(result)
+	}
+
+	// Quiet hours ask for a darker rendering
+	if meta.Quiet {
+		result = applyQuiet(result)
+	}
+
+	// The badge keeps its colors whatever the grading
+	return p.addBadge(ctx, result, meta), nil
+}
+
+// decodeImage decodes raw image bytes and rejects images without usable or with excessive dimensions
+func decodeImage(imageData []byte) (image.Image, error) {
+	// Check the header first so oversized images are rejected before allocating pixels
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(imageData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	if cfg.Width > maxImageDimension || cfg.Height > maxImageDimension || cfg.Width*cfg.Height > maxImagePixels {
+		return nil, fmt.Errorf("image too large: %dx%d", cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(imageData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	// Validate image dimensions to prevent division by zero
+	bounds := img.Bounds()
+	if bounds.Dy() == 0 || bounds.Dx() == 0 {
+		return nil, fmt.Errorf("invalid image dimensions: %dx%d", bounds.Dx(), bounds.Dy())
+	}
+
+	return img, nil
+}
+
+// encodeJPEG encodes the rendered wallpaper into an in-memory JPEG
+func encodeJPEG(img image.Image, quality int) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := jpeg.Encode(buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	return buf.Bytes(), nil
+}
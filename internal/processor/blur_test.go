@@ -3,12 +3,19 @@ package processor
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
 	"image"
 	"image/color"
 	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/disintegration/imaging"
 	"github.com/genricoloni/synest/internal/domain"
 	"go.uber.org/zap"
 )
@@ -89,6 +96,27 @@ func TestBlurProcessor_Process(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:       "Edge Case - Panoramic Image Fits Screen",
+			imageData:  createTestJPEG(2000, 10, color.RGBA{R: 0, G: 0, B: 255, A: 255}),
+			resolution: &domain.ScreenResolution{Width: 800, Height: 600},
+			validateFunc: func(t *testing.T, result []byte) {
+				img, _, err := image.Decode(bytes.NewReader(result))
+				if err != nil {
+					t.Errorf("failed to decode result: %v", err)
+				}
+				bounds := img.Bounds()
+				if bounds.Dx() != 800 || bounds.Dy() != 600 {
+					t.Errorf("expected 800x600, got %dx%d", bounds.Dx(), bounds.Dy())
+				}
+			},
+		},
+		{
+			name:          "Error - Decompression Bomb",
+			imageData:     createPNGHeader(100_000, 100_000),
+			resolution:    &domain.ScreenResolution{Width: 1920, Height: 1080},
+			expectedError: "image too large",
+		},
 		{
 			name:       "Edge Case - 4K Resolution",
 			imageData:  createTestJPEG(100, 100, color.RGBA{R: 255, G: 255, B: 0, A: 255}),
@@ -159,6 +187,115 @@ func TestBlurProcessor_Process_ContextCancellation(t *testing.T) {
 	}
 }
 
+// TestGenerate_OutputUnavailable verifies write failures are recognizable by the engine
+func TestGenerate_OutputUnavailable(t *testing.T) {
+	// A regular file where the output directory should be makes MkdirAll fail
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	res := &domain.ScreenResolution{Width: 64, Height: 36}
+	processor := NewBlurProcessor(zap.NewNop(), res, &mockConfig{outputDir: filepath.Join(blocker, "out")})
+	_, err := processor.Generate(context.Background(), createTestJPEG(8, 8, color.RGBA{R: 255, A: 255}), domain.MediaMetadata{}, domain.ModeBlur)
+	if !errors.Is(err, domain.ErrOutputUnavailable) {
+		t.Errorf("expected ErrOutputUnavailable, got %v", err)
+	}
+}
+
+// TestBlurProcessor_Generate_Deterministic verifies identical inputs produce
+// byte-identical files, and that deterministic mode ignores the battery state
+func TestBlurProcessor_Generate_Deterministic(t *testing.T) {
+	res := &domain.ScreenResolution{Width: 160, Height: 90}
+	var cover bytes.Buffer
+	if err := jpeg.Encode(&cover, goldenArtwork(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	generate := func(deterministic, lowPower bool) []byte {
+		processor := NewBlurProcessor(zap.NewNop(), res, &mockConfig{
+			outputDir:     t.TempDir(),
+			deterministic: deterministic,
+		})
+		meta := domain.MediaMetadata{Artist: "Air", Title: "La Femme d'Argent", LowPower: lowPower}
+		path, err := processor.Generate(context.Background(), cover.Bytes(), meta, domain.ModeBlur)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read wallpaper: %v", err)
+		}
+		return data
+	}
+
+	if !bytes.Equal(generate(false, false), generate(false, false)) {
+		t.Error("identical inputs should render byte-identical wallpapers")
+	}
+	if bytes.Equal(generate(false, false), generate(false, true)) {
+		t.Error("expected the low power render to differ by default")
+	}
+	if !bytes.Equal(generate(true, false), generate(true, true)) {
+		t.Error("deterministic renders should ignore the battery state")
+	}
+}
+
+// FuzzDecodeAndRender feeds arbitrary bytes through the decode path and the blur mode.
+// Run with: go test ./internal/processor -fuzz FuzzDecodeAndRender
+func FuzzDecodeAndRender(f *testing.F) {
+	f.Add(createTestJPEG(8, 8, color.RGBA{R: 255, A: 255}))
+	f.Add(createTestJPEG(64, 1, color.RGBA{G: 255, A: 255})) // Panoramic
+	f.Add(createTestPNG(1, 64))                              // Tall
+	f.Add([]byte{0xFF, 0xD8, 0xFF, 0x00, 0x00})              // Truncated JPEG
+	f.Add(createPNGHeader(100_000, 100_000))                 // Decompression bomb
+
+	res := &domain.ScreenResolution{Width: 64, Height: 36}
+	processor := NewBlurProcessor(zap.NewNop(), res, &mockConfig{})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		img, err := decodeImage(data)
+		if err != nil {
+			return
+		}
+
+		bounds := img.Bounds()
+		if bounds.Dx() > maxImageDimension || bounds.Dy() > maxImageDimension {
+			t.Fatalf("accepted oversized image %dx%d", bounds.Dx(), bounds.Dy())
+		}
+
+		result, err := processor.renderBlur(context.Background(), img, domain.MediaMetadata{})
+		if err != nil {
+			t.Fatalf("render failed on decodable image: %v", err)
+		}
+		if result.Bounds().Dx() != res.Width || result.Bounds().Dy() != res.Height {
+			t.Errorf("expected %dx%d, got %v", res.Width, res.Height, result.Bounds())
+		}
+	})
+}
+
+// createTestPNG generates a simple PNG image for testing
+func createTestPNG(width, height int) []byte {
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		panic("failed to create test PNG: " + err.Error())
+	}
+	return buf.Bytes()
+}
+
+// createPNGHeader returns only the signature and IHDR chunk of a PNG declaring
+// the given size, enough for DecodeConfig to report dimensions
+func createPNGHeader(width, height uint32) []byte {
+	ihdr := []byte("IHDR")
+	ihdr = binary.BigEndian.AppendUint32(ihdr, width)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, height)
+	ihdr = append(ihdr, 8, 2, 0, 0, 0) // 8-bit RGB, default compression/filter/interlace
+
+	buf := []byte("\x89PNG\r\n\x1a\n")
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(ihdr)-4))
+	buf = append(buf, ihdr...)
+	return binary.BigEndian.AppendUint32(buf, crc32.ChecksumIEEE(ihdr))
+}
+
 // createTestJPEG generates a simple JPEG image for testing
 func createTestJPEG(width, height int, col color.Color) []byte {
 	img := image.NewRGBA(image.Rect(0, 0, width, height))
@@ -176,10 +313,33 @@ func createTestJPEG(width, height int, col color.Color) []byte {
 	return buf.Bytes()
 }
 
-// mockConfig is a simple mock implementation of domain.Config for testing
+// mockConfig is a simple mock implementation of domain.Config for testing.
+// Getters the processor does not use are promoted from the nil embedded interface.
 type mockConfig struct {
-	outputDir string
-	mode      string
+	domain.Config
+	outputDir     string
+	mode          string
+	deterministic bool
+	delivery      string
+	autoGenres    map[string]string
+	thumbnails    bool
+	coverFilter   string
+	coverAspect   string
+	playerBadge   string
+	postProcess   []string
+	maxHeight     int // Render height cap of the blur mode
+	scene         string
+}
+
+func (m *mockConfig) GetScene() string {
+	if m.scene == "" {
+		return domain.SceneOff
+	}
+	return m.scene
+}
+
+func (m *mockConfig) GetPostProcessHooks() []string {
+	return m.postProcess
 }
 
 func (m *mockConfig) GetOutputDir() string {
@@ -192,3 +352,120 @@ func (m *mockConfig) GetMode() string {
 	}
 	return m.mode
 }
+
+func (m *mockConfig) GetAutoGenres() map[string]string {
+	return m.autoGenres
+}
+
+func (m *mockConfig) GetDeterministic() bool {
+	return m.deterministic
+}
+
+func (m *mockConfig) GetDelivery() string {
+	return m.delivery
+}
+
+// Rendering parameters match the config defaults the goldens were rendered with
+
+func (m *mockConfig) GetModeConfig() domain.ModeConfig {
+	return domain.ModeConfig{
+		Blur:       domain.BlurConfig{BlurRadius: 15, CoverSize: 0.40, MaxHeight: m.maxHeight},
+		Generative: domain.GenerativeConfig{Particles: 1200, Shapes: 7},
+		Waveform:   domain.WaveformConfig{Bars: 160, Height: 0.70, BlurRadius: 15, CoverSize: 0.40},
+	}
+}
+
+func (m *mockConfig) GetJPEGQuality() int {
+	return 90
+}
+
+func (m *mockConfig) GetCoverThumbnails() bool {
+	return m.thumbnails
+}
+
+func (m *mockConfig) GetBackgroundFilter() string {
+	return domain.FilterLanczos
+}
+
+func (m *mockConfig) GetCoverFilter() string {
+	if m.coverFilter == "" {
+		return domain.FilterLanczos
+	}
+	return m.coverFilter
+}
+
+func (m *mockConfig) GetCoverAspect() string {
+	if m.coverAspect == "" {
+		return domain.CoverAspectPreserve
+	}
+	return m.coverAspect
+}
+
+func (m *mockConfig) GetTrimBorders() bool {
+	return false
+}
+
+func (m *mockConfig) GetTrimTolerance() int {
+	return 24
+}
+
+func (m *mockConfig) GetPlayerBadge() string {
+	if m.playerBadge == "" {
+		return domain.BadgeOff
+	}
+	return m.playerBadge
+}
+
+func (m *mockConfig) GetPlayerBadgeSize() int {
+	return 48
+}
+
+// TestResampleFilters verifies every configurable filter is available and that
+// the cover filter changes the rendering
+func TestResampleFilters(t *testing.T) {
+	for _, name := range domain.Filters {
+		if _, ok := resampleFilters[name]; !ok {
+			t.Errorf("filter %s has no implementation", name)
+		}
+	}
+
+	res := &domain.ScreenResolution{Width: 192, Height: 108}
+	render := func(filter string) []byte {
+		processor := NewBlurProcessor(zap.NewNop(), res, &mockConfig{deterministic: true, coverFilter: filter})
+		img, err := processor.render(context.Background(), goldenArtwork(), domain.MediaMetadata{}, domain.ModeBlur)
+		if err != nil {
+			t.Fatalf("%s: render failed: %v", filter, err)
+		}
+		return imaging.Clone(img).Pix
+	}
+	if bytes.Equal(render(domain.FilterNearest), render(domain.FilterLanczos)) {
+		t.Error("expected the nearest filter to render a different cover than lanczos")
+	}
+}
+
+// TestVariations verifies each blur variation renders a different wallpaper
+func TestVariations(t *testing.T) {
+	res := &domain.ScreenResolution{Width: 192, Height: 108}
+	processor := NewBlurProcessor(zap.NewNop(), res, &mockConfig{deterministic: true})
+
+	if n := processor.Variations(domain.ModeWaveform); n != 1 {
+		t.Errorf("expected waveform to have no variations, got %d", n)
+	}
+
+	n := processor.Variations(domain.ModeBlur)
+	if n < 2 {
+		t.Fatalf("expected blur variations, got %d", n)
+	}
+
+	seen := map[uint64]bool{}
+	for v := 0; v < n; v++ {
+		img, err := processor.render(context.Background(), goldenArtwork(), domain.MediaMetadata{Variation: v}, domain.ModeBlur)
+		if err != nil {
+			t.Fatalf("variation %d: render failed: %v", v, err)
+		}
+		seen[differenceHash(img)] = true
+	}
+	if len(seen) != n {
+		t.Errorf("expected %d distinct renderings, got %d", n, len(seen))
+	}
+}
@@ -0,0 +1,56 @@
+package processor
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/disintegration/imaging"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	sheetMargin      = 16 // Space around and between tiles, in pixels
+	sheetLabelHeight = 24 // Height of the caption under each tile
+)
+
+var (
+	sheetBackground = color.NRGBA{R: 24, G: 24, B: 24, A: 255}
+	sheetLabelColor = color.NRGBA{R: 230, G: 230, B: 230, A: 255}
+)
+
+// Tile is one captioned image of a contact sheet
+type Tile struct {
+	Label string
+	Image image.Image
+}
+
+// ContactSheet lays the tiles out side by side, each scaled to tileWidth and
+// captioned with its label, so renderings can be compared at a glance
+func ContactSheet(tiles []Tile, tileWidth int) image.Image {
+	tileHeight := 0
+	scaled := make([]image.Image, len(tiles))
+	for i, tile := range tiles {
+		scaled[i] = imaging.Resize(tile.Image, tileWidth, 0, imaging.Lanczos)
+		tileHeight = max(tileHeight, scaled[i].Bounds().Dy())
+	}
+
+	width := sheetMargin + len(tiles)*(tileWidth+sheetMargin)
+	height := 2*sheetMargin + tileHeight + sheetLabelHeight
+	sheet := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(sheet, sheet.Bounds(), image.NewUniform(sheetBackground), image.Point{}, draw.Src)
+
+	face := basicfont.Face7x13
+	for i, tile := range tiles {
+		x := sheetMargin + i*(tileWidth+sheetMargin)
+		draw.Draw(sheet, scaled[i].Bounds().Add(image.Pt(x, sheetMargin)), scaled[i], scaled[i].Bounds().Min, draw.Src)
+
+		drawer := &font.Drawer{Dst: sheet, Src: image.NewUniform(sheetLabelColor), Face: face}
+		labelX := x + (tileWidth-drawer.MeasureString(tile.Label).Round())/2
+		drawer.Dot = fixed.P(labelX, sheetMargin+tileHeight+sheetLabelHeight-face.Descent-4)
+		drawer.DrawString(tile.Label)
+	}
+	return sheet
+}
@@ -0,0 +1,32 @@
+package processor
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestContactSheet(t *testing.T) {
+	red := image.NewNRGBA(image.Rect(0, 0, 160, 90))
+	for i := 0; i < len(red.Pix); i += 4 {
+		red.Pix[i], red.Pix[i+3] = 255, 255
+	}
+
+	sheet := ContactSheet([]Tile{{Label: "blur", Image: red}, {Label: "generative", Image: red}}, 80)
+
+	wantWidth := sheetMargin + 2*(80+sheetMargin)
+	wantHeight := 2*sheetMargin + 45 + sheetLabelHeight
+	if size := sheet.Bounds().Size(); size.X != wantWidth || size.Y != wantHeight {
+		t.Fatalf("expected %dx%d, got %v", wantWidth, wantHeight, size)
+	}
+
+	// Both tiles are placed, separated by the background
+	for _, x := range []int{sheetMargin + 40, 2*sheetMargin + 80 + 40} {
+		if c := color.NRGBAModel.Convert(sheet.At(x, sheetMargin+20)).(color.NRGBA); c.R != 255 || c.G != 0 {
+			t.Errorf("expected tile pixel at x=%d, got %+v", x, c)
+		}
+	}
+	if c := color.NRGBAModel.Convert(sheet.At(sheetMargin+80+sheetMargin/2, sheetMargin+20)); c != sheetBackground {
+		t.Errorf("expected background between tiles, got %+v", c)
+	}
+}
@@ -0,0 +1,244 @@
+package processor
+
+import (
+	"context"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"math"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/logctx"
+	"go.uber.org/zap"
+)
+
+const (
+	generativeColors   = 5    // Number of palette entries used by the generative mode
+	generativeSteps    = 220  // Steps walked by each stroke
+	generativeRefWidth = 1920 // Width at which stroke sizes are tuned
+)
+
+// renderGenerative renders deterministic procedural art (gradient, soft shapes and
+// a flow field) seeded by the track's artist and title. The artwork is never drawn;
+// when available it only provides the color palette. Variations change the seed.
+func (p *BlurProcessor) renderGenerative(ctx context.Context, src image.Image, meta domain.MediaMetadata) (image.Image, error) {
+	seed, rng, colors := generativePalette(src, meta)
+
+	logctx.Logger(ctx, p.logger).Debug("Rendering generative wallpaper",
+		zap.Uint64("seed", seed),
+		zap.Int("colors", len(colors)),
+		zap.Bool("artwork", src != nil))
+
+	cfg := p.appCfg.GetModeConfig().Generative
+	w, h := p.res.Width, p.res.Height
+	canvas := image.NewNRGBA(image.Rect(0, 0, w, h))
+	scale := math.Max(float64(w)/generativeRefWidth, 0.25)
+
+	// 1. Linear gradient between the two dominant colors along a seeded angle
+	drawGradient(canvas, colors[0], colors[1], rng.Float64()*2*math.Pi)
+
+	// 2. Soft shapes tinted by the remaining palette entries
+	for i := 0; i < cfg.Shapes; i++ {
+		c := colors[rng.IntN(len(colors))]
+		cx := rng.Float64() * float64(w)
+		cy := rng.Float64() * float64(h)
+		radius := (0.1 + rng.Float64()*0.25) * float64(h)
+		fillCircle(canvas, cx, cy, radius, c, 0.12+rng.Float64()*0.12)
+	}
+
+	// 3. Flow field: particles follow a smooth angle field derived from the seed
+	field := newFlowField(rng)
+	stroke := math.Max(1, math.Round(scale))
+	stepLen := 2 * scale
+	for i := 0; i < cfg.Particles; i++ {
+		c := colors[1+rng.IntN(len(colors)-1)]
+		x := rng.Float64() * float64(w)
+		y := rng.Float64() * float64(h)
+		for s := 0; s < generativeSteps; s++ {
+			fillSquare(canvas, x, y, stroke, c, 0.18)
+			angle := field.angle(x/float64(w), y/float64(h))
+			x += math.Cos(angle) * stepLen
+			y += math.Sin(angle) * stepLen
+			if x < 0 || y < 0 || x >= float64(w) || y >= float64(h) {
+				break
+			}
+		}
+	}
+
+	return canvas, nil
+}
+
+// generativePalette returns the seed of the track variation, the random source
+// seeded with it and the colors of the generative mode: the artwork palette, or
+// a seeded one without usable artwork
+func generativePalette(src image.Image, meta domain.MediaMetadata) (uint64, *rand.Rand, []color.RGBA) {
+	seed := trackSeed(meta) ^ uint64(variationIndex(meta.Variation, generativeVariations))*variationSalt
+	// Art generation needs reproducibility, not unpredictability
+	rng := rand.New(rand.NewPCG(seed, seed>>32)) //nolint:gosec
+
+	colors := extractPalette(src, generativeColors)
+	if len(colors) < 2 {
+		colors = seededPalette(rng, generativeColors)
+	}
+	return seed, rng, colors
+}
+
+// trackSeed hashes the artists and title into a stable seed for procedural rendering.
+// A single artist hashes like before multi-artist support, keeping those seeds stable.
+func trackSeed(meta domain.MediaMetadata) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(strings.Join(meta.AllArtists(), "\x01")))
+	_, _ = h.Write([]byte{0}) // Separator so "ab"+"c" and "a"+"bc" differ
+	_, _ = h.Write([]byte(meta.Title))
+	return h.Sum64()
+}
+
+// seededPalette builds an analogous color scheme around a seeded base hue,
+// used when no artwork is available to extract a palette from
+func seededPalette(rng *rand.Rand, n int) []color.RGBA {
+	baseHue := rng.Float64()
+	palette := make([]color.RGBA, n)
+	for i := range palette {
+		hue := math.Mod(baseHue+float64(i)*0.07, 1)
+		lightness := 0.25 + 0.1*float64(i)
+		palette[i] = hslToRGB(hue, 0.55, lightness)
+	}
+	return palette
+}
+
+// flowField is a smooth pseudo-random angle field built from summed sinusoids
+type flowField struct {
+	fx, fy, fxy    float64
+	px, py, pxy    float64
+	turbulenceSign float64
+}
+
+func newFlowField(rng *rand.Rand) flowField {
+	sign := 1.0
+	if rng.IntN(2) == 0 {
+		sign = -1
+	}
+	return flowField{
+		fx:             2 + rng.Float64()*4,
+		fy:             2 + rng.Float64()*4,
+		fxy:            1 + rng.Float64()*3,
+		px:             rng.Float64() * 2 * math.Pi,
+		py:             rng.Float64() * 2 * math.Pi,
+		pxy:            rng.Float64() * 2 * math.Pi,
+		turbulenceSign: sign,
+	}
+}
+
+// angle returns the flow direction at normalized coordinates (0..1)
+func (f flowField) angle(x, y float64) float64 {
+	v := math.Sin(x*f.fx+f.px) + math.Cos(y*f.fy+f.py) + f.turbulenceSign*math.Sin((x+y)*f.fxy+f.pxy)
+	return v * math.Pi / 1.5
+}
+
+// drawGradient fills the canvas with a linear gradient from c0 to c1 along angle
+func drawGradient(canvas *image.NRGBA, c0, c1 color.RGBA, angle float64) {
+	b := canvas.Bounds()
+	dx, dy := math.Cos(angle), math.Sin(angle)
+
+	// Project the corners to normalize the gradient parameter to 0..1
+	minP, maxP := math.Inf(1), math.Inf(-1)
+	for _, corner := range [][2]float64{{0, 0}, {float64(b.Dx()), 0}, {0, float64(b.Dy())}, {float64(b.Dx()), float64(b.Dy())}} {
+		proj := corner[0]*dx + corner[1]*dy
+		minP = math.Min(minP, proj)
+		maxP = math.Max(maxP, proj)
+	}
+	span := maxP - minP
+	if span == 0 {
+		span = 1
+	}
+
+	for y := 0; y < b.Dy(); y++ {
+		row := canvas.Pix[y*canvas.Stride:]
+		for x := 0; x < b.Dx(); x++ {
+			t := ((float64(x)*dx + float64(y)*dy) - minP) / span
+			i := x * 4
+			row[i] = lerp8(c0.R, c1.R, t)
+			row[i+1] = lerp8(c0.G, c1.G, t)
+			row[i+2] = lerp8(c0.B, c1.B, t)
+			row[i+3] = 255
+		}
+	}
+}
+
+// fillCircle blends a filled circle of color c into the canvas
+func fillCircle(canvas *image.NRGBA, cx, cy, radius float64, c color.RGBA, alpha float64) {
+	b := canvas.Bounds()
+	x0, x1 := max(int(cx-radius), 0), min(int(cx+radius)+1, b.Dx())
+	y0, y1 := max(int(cy-radius), 0), min(int(cy+radius)+1, b.Dy())
+	r2 := radius * radius
+
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			ddx, ddy := float64(x)-cx, float64(y)-cy
+			if ddx*ddx+ddy*ddy <= r2 {
+				blendPixel(canvas, x, y, c, alpha)
+			}
+		}
+	}
+}
+
+// fillSquare blends a size×size square centered on (cx, cy) into the canvas
+func fillSquare(canvas *image.NRGBA, cx, cy, size float64, c color.RGBA, alpha float64) {
+	b := canvas.Bounds()
+	half := size / 2
+	x0, x1 := max(int(cx-half), 0), min(int(cx+half)+1, b.Dx())
+	y0, y1 := max(int(cy-half), 0), min(int(cy+half)+1, b.Dy())
+
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			blendPixel(canvas, x, y, c, alpha)
+		}
+	}
+}
+
+// blendPixel alpha-blends c over the opaque canvas pixel at (x, y)
+func blendPixel(canvas *image.NRGBA, x, y int, c color.RGBA, alpha float64) {
+	i := y*canvas.Stride + x*4
+	canvas.Pix[i] = lerp8(canvas.Pix[i], c.R, alpha)
+	canvas.Pix[i+1] = lerp8(canvas.Pix[i+1], c.G, alpha)
+	canvas.Pix[i+2] = lerp8(canvas.Pix[i+2], c.B, alpha)
+}
+
+// lerp8 linearly interpolates between two channel values
+func lerp8(a, b uint8, t float64) uint8 {
+	return uint8(math.Round(float64(a) + (float64(b)-float64(a))*t))
+}
+
+// hslToRGB converts a color from HSL (all components 0..1) to RGB
+func hslToRGB(h, s, l float64) color.RGBA {
+	q := l * (1 + s)
+	if l >= 0.5 {
+		q = l + s - l*s
+	}
+	p := 2*l - q
+
+	hueToChannel := func(t float64) uint8 {
+		t = math.Mod(t+1, 1)
+		var v float64
+		switch {
+		case t < 1.0/6:
+			v = p + (q-p)*6*t
+		case t < 0.5:
+			v = q
+		case t < 2.0/3:
+			v = p + (q-p)*(2.0/3-t)*6
+		default:
+			v = p
+		}
+		return uint8(math.Round(v * 255))
+	}
+
+	return color.RGBA{
+		R: hueToChannel(h + 1.0/3),
+		G: hueToChannel(h),
+		B: hueToChannel(h - 1.0/3),
+		A: 255,
+	}
+}
@@ -0,0 +1,128 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"os"
+	"testing"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+func TestBlurProcessor_Generate_Generative(t *testing.T) {
+	res := &domain.ScreenResolution{Width: 320, Height: 180}
+	meta := domain.MediaMetadata{Artist: "Boards of Canada", Title: "Roygbiv"}
+
+	tests := []struct {
+		name      string
+		imageData []byte
+		meta      domain.MediaMetadata
+	}{
+		{
+			name: "Without Artwork",
+			meta: meta,
+		},
+		{
+			name:      "With Artwork Palette",
+			imageData: createTestJPEG(64, 64, color.RGBA{R: 200, G: 40, B: 40, A: 255}),
+			meta:      meta,
+		},
+		{
+			name: "Empty Metadata",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCfg := &mockConfig{outputDir: t.TempDir()}
+			processor := NewBlurProcessor(zap.NewNop(), res, mockCfg)
+
+			path, err := processor.Generate(context.Background(), tt.imageData, tt.meta, domain.ModeGenerative)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read wallpaper: %v", err)
+			}
+			img, _, err := image.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("result is not a valid image: %v", err)
+			}
+			if bounds := img.Bounds(); bounds.Dx() != 320 || bounds.Dy() != 180 {
+				t.Errorf("expected 320x180, got %dx%d", bounds.Dx(), bounds.Dy())
+			}
+		})
+	}
+}
+
+// TestRenderGenerative_Deterministic verifies that the same track always renders
+// the same art and that different tracks render different art
+func TestRenderGenerative_Deterministic(t *testing.T) {
+	res := &domain.ScreenResolution{Width: 160, Height: 90}
+	processor := NewBlurProcessor(zap.NewNop(), res, &mockConfig{})
+
+	render := func(meta domain.MediaMetadata) []byte {
+		img, err := processor.renderGenerative(context.Background(), nil, meta)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		nrgba, ok := img.(*image.NRGBA)
+		if !ok {
+			t.Fatalf("expected *image.NRGBA, got %T", img)
+		}
+		return nrgba.Pix
+	}
+
+	a := render(domain.MediaMetadata{Artist: "Queen", Title: "Bohemian Rhapsody"})
+	b := render(domain.MediaMetadata{Artist: "Queen", Title: "Bohemian Rhapsody"})
+	c := render(domain.MediaMetadata{Artist: "Queen", Title: "Under Pressure"})
+
+	if !bytes.Equal(a, b) {
+		t.Error("same metadata should render identical pixels")
+	}
+	if bytes.Equal(a, c) {
+		t.Error("different metadata should render different pixels")
+	}
+}
+
+func TestTrackSeed_FieldBoundary(t *testing.T) {
+	a := trackSeed(domain.MediaMetadata{Artist: "ab", Title: "c"})
+	b := trackSeed(domain.MediaMetadata{Artist: "a", Title: "bc"})
+	if a == b {
+		t.Error("seed should depend on the artist/title boundary")
+	}
+}
+
+func TestExtractPalette(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			// Three quarters red, one quarter blue
+			if x < 30 {
+				img.Set(x, y, color.RGBA{R: 220, A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{B: 220, A: 255})
+			}
+		}
+	}
+
+	palette := extractPalette(img, 2)
+	if len(palette) != 2 {
+		t.Fatalf("expected 2 colors, got %d", len(palette))
+	}
+	if palette[0].R < 200 || palette[0].B > 20 {
+		t.Errorf("expected red as dominant color, got %+v", palette[0])
+	}
+	if palette[1].B < 200 || palette[1].R > 20 {
+		t.Errorf("expected blue as second color, got %+v", palette[1])
+	}
+
+	if got := extractPalette(nil, 3); got != nil {
+		t.Errorf("expected nil palette for nil image, got %v", got)
+	}
+}
@@ -0,0 +1,229 @@
+package processor
+
+import (
+	"context"
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/disintegration/imaging"
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// Run `go test ./internal/processor -run TestGolden -update` to regenerate the goldens
+// after an intentional visual change, then review the PNGs before committing them.
+var update = flag.Bool("update", false, "regenerate golden images in testdata/golden")
+
+const (
+	goldenDir          = "testdata/golden"
+	goldenMinSSIM      = 0.97 // Structural similarity required to pass (1.0 = identical)
+	goldenMaxHashDelta = 4    // Max differing bits of the 64-bit difference hash
+	ssimBlockSize      = 8
+)
+
+// goldenCase renders one mode with fixed inputs
+type goldenCase struct {
+	name     string
+	mode     string
+	artwork  bool
+	meta     domain.MediaMetadata
+	features *domain.AudioFeatures
+}
+
+func TestGolden(t *testing.T) {
+	wavPath, err := filepath.Abs("testdata/tone.wav")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []goldenCase{
+		{name: "blur", mode: domain.ModeBlur, artwork: true},
+		{name: "blur_mood_warm", mode: domain.ModeBlur, artwork: true,
+			features: &domain.AudioFeatures{Energy: 0.9, Valence: 0.9, Tempo: 140}},
+		{name: "generative_seeded", mode: domain.ModeGenerative,
+			meta: domain.MediaMetadata{Artist: "Aphex Twin", Title: "Xtal"}},
+		{name: "generative_palette", mode: domain.ModeGenerative, artwork: true,
+			meta: domain.MediaMetadata{Artist: "Aphex Twin", Title: "Xtal"}},
+		{name: "waveform", mode: domain.ModeWaveform, artwork: true,
+			meta: domain.MediaMetadata{URL: "file://" + wavPath}},
+	}
+
+	res := &domain.ScreenResolution{Width: 192, Height: 108}
+	processor := NewBlurProcessor(zap.NewNop(), res, &mockConfig{deterministic: true})
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var src image.Image
+			if tc.artwork {
+				src = goldenArtwork()
+			}
+
+			meta := tc.meta
+			meta.Features = tc.features
+			got, err := processor.render(context.Background(), src, meta, tc.mode)
+			if err != nil {
+				t.Fatalf("render failed: %v", err)
+			}
+
+			path := filepath.Join(goldenDir, tc.name+".png")
+			if *update {
+				writeGolden(t, path, got)
+				return
+			}
+
+			want := readGolden(t, path)
+			assertSimilar(t, want, got)
+		})
+	}
+}
+
+// goldenArtwork builds a deterministic cover with gradients and hard edges,
+// so that both blur and sharp-cover regressions are visible
+func goldenArtwork() image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			c := color.NRGBA{R: uint8(x * 4), G: uint8(y * 4), B: 90, A: 255}
+			if (x-32)*(x-32)+(y-32)*(y-32) < 144 {
+				c = color.NRGBA{R: 250, G: 220, B: 40, A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func writeGolden(t *testing.T, path string, img image.Image) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create golden dir: %v", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create golden: %v", err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatalf("failed to encode golden: %v", err)
+	}
+	t.Logf("updated golden %s", path)
+}
+
+func readGolden(t *testing.T, path string) image.Image {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("missing golden %s (run with -update to create it): %v", path, err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("failed to decode golden %s: %v", path, err)
+	}
+	return img
+}
+
+// assertSimilar fails the test when two images differ perceptually. Exact pixel
+// equality is not required, so harmless floating point or resampling differences
+// between platforms do not break the suite.
+func assertSimilar(t *testing.T, want, got image.Image) {
+	t.Helper()
+
+	if want.Bounds().Size() != got.Bounds().Size() {
+		t.Fatalf("size mismatch: want %v, got %v", want.Bounds().Size(), got.Bounds().Size())
+	}
+
+	if delta := bits.OnesCount64(differenceHash(want) ^ differenceHash(got)); delta > goldenMaxHashDelta {
+		t.Errorf("perceptual hash differs by %d bits (max %d)", delta, goldenMaxHashDelta)
+	}
+
+	if score := ssim(want, got); score < goldenMinSSIM {
+		t.Errorf("SSIM %.4f below threshold %.4f", score, goldenMinSSIM)
+	}
+}
+
+// differenceHash computes a 64-bit dHash: each bit tells whether a pixel of the
+// 9x8 grayscale thumbnail is brighter than its right neighbor
+func differenceHash(img image.Image) uint64 {
+	thumb := imaging.Grayscale(imaging.Resize(img, 9, 8, imaging.Box))
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			left := thumb.Pix[y*thumb.Stride+x*4]
+			right := thumb.Pix[y*thumb.Stride+(x+1)*4]
+			hash <<= 1
+			if left > right {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// ssim returns the mean structural similarity of the luma channels,
+// computed over non-overlapping blocks
+func ssim(a, b image.Image) float64 {
+	const (
+		c1 = (0.01 * 255) * (0.01 * 255)
+		c2 = (0.03 * 255) * (0.03 * 255)
+	)
+
+	ga, gb := imaging.Grayscale(a), imaging.Grayscale(b)
+	w, h := ga.Bounds().Dx(), ga.Bounds().Dy()
+
+	var total float64
+	var blocks int
+	for by := 0; by+ssimBlockSize <= h; by += ssimBlockSize {
+		for bx := 0; bx+ssimBlockSize <= w; bx += ssimBlockSize {
+			var sumA, sumB, sumAA, sumBB, sumAB float64
+			for y := by; y < by+ssimBlockSize; y++ {
+				for x := bx; x < bx+ssimBlockSize; x++ {
+					va := float64(ga.Pix[y*ga.Stride+x*4])
+					vb := float64(gb.Pix[y*gb.Stride+x*4])
+					sumA += va
+					sumB += vb
+					sumAA += va * va
+					sumBB += vb * vb
+					sumAB += va * vb
+				}
+			}
+
+			n := float64(ssimBlockSize * ssimBlockSize)
+			meanA, meanB := sumA/n, sumB/n
+			varA := sumAA/n - meanA*meanA
+			varB := sumBB/n - meanB*meanB
+			cov := sumAB/n - meanA*meanB
+
+			total += ((2*meanA*meanB + c1) * (2*cov + c2)) /
+				((meanA*meanA + meanB*meanB + c1) * (varA + varB + c2))
+			blocks++
+		}
+	}
+
+	if blocks == 0 {
+		return math.NaN()
+	}
+	return total / float64(blocks)
+}
+
+func TestGoldenHelpers(t *testing.T) {
+	img := goldenArtwork()
+	if score := ssim(img, img); math.Abs(score-1) > 1e-9 {
+		t.Errorf("identical images should have SSIM 1, got %f", score)
+	}
+
+	inverted := imaging.Invert(img)
+	if score := ssim(img, inverted); score > 0.5 {
+		t.Errorf("inverted image should not be similar, got SSIM %f", score)
+	}
+	if delta := bits.OnesCount64(differenceHash(img) ^ differenceHash(inverted)); delta < 16 {
+		t.Errorf("inverted image should have a distant hash, got %d bits", delta)
+	}
+}
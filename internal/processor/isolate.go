@@ -0,0 +1,87 @@
+package processor
+
+import (
+	"fmt"
+	"image"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	codecTimeout    = 10 * time.Second // Deadline for decoding or encoding a single image
+	maxStuckWorkers = 4                // Abandoned workers tolerated before refusing new work
+)
+
+// Worker states, moved out of workerRunning by whichever side finishes first
+const (
+	workerRunning int32 = iota
+	workerDone
+	workerAbandoned
+)
+
+// stuckWorkers counts workers that missed their deadline and are still running
+var stuckWorkers atomic.Int32
+
+// isolate runs fn in its own goroutine, turning panics into errors and giving up
+// once the timeout expires. A goroutine cannot be killed, so a runaway decoder
+// keeps running in the background, but the pipeline is no longer blocked by it.
+// Work is refused while too many abandoned workers are still running.
+func isolate[T any](timeout time.Duration, what string, fn func() (T, error)) (T, error) {
+	var zero T
+	if stuckWorkers.Load() >= maxStuckWorkers {
+		return zero, fmt.Errorf("%s refused: %d earlier image workers are still stuck", what, maxStuckWorkers)
+	}
+
+	type result struct {
+		value T
+		err   error
+	}
+	// Buffered so an abandoned worker can always deliver its result and exit
+	done := make(chan result, 1)
+	var state atomic.Int32
+
+	go func() {
+		var r result
+		defer func() {
+			if p := recover(); p != nil {
+				r = result{err: fmt.Errorf("%s panicked: %v", what, p)}
+			}
+			done <- r
+			if !state.CompareAndSwap(workerRunning, workerDone) {
+				stuckWorkers.Add(-1) // Abandoned earlier, no longer stuck
+			}
+		}()
+		r.value, r.err = fn()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-timer.C:
+		if !state.CompareAndSwap(workerRunning, workerAbandoned) {
+			// The worker finished just in time, its result is already buffered
+			r := <-done
+			return r.value, r.err
+		}
+		stuckWorkers.Add(1)
+		return zero, fmt.Errorf("%s timed out after %s", what, timeout)
+	}
+}
+
+// decodeIsolated decodes artwork in an isolated worker, so a pathological image
+// cannot hang or crash the pipeline
+func decodeIsolated(imageData []byte) (image.Image, error) {
+	return isolate(codecTimeout, "image decode", func() (image.Image, error) {
+		return decodeImage(imageData)
+	})
+}
+
+// encodeIsolated encodes the wallpaper in an isolated worker
+func encodeIsolated(img image.Image, quality int) ([]byte, error) {
+	return isolate(codecTimeout, "image encode", func() ([]byte, error) {
+		return encodeJPEG(img, quality)
+	})
+}
@@ -0,0 +1,98 @@
+package processor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsolate(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	tests := []struct {
+		name          string
+		fn            func() (int, error)
+		expected      int
+		expectedError string
+	}{
+		{
+			name:     "Success",
+			fn:       func() (int, error) { return 42, nil },
+			expected: 42,
+		},
+		{
+			name:          "Error",
+			fn:            func() (int, error) { return 0, errors.New("corrupt data") },
+			expectedError: "corrupt data",
+		},
+		{
+			name:          "Panic",
+			fn:            func() (int, error) { panic("index out of range") },
+			expectedError: "decode panicked: index out of range",
+		},
+		{
+			name:          "Timeout",
+			fn:            func() (int, error) { <-release; return 1, nil },
+			expectedError: "decode timed out after 20ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := isolate(20*time.Millisecond, "decode", tt.fn)
+
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing '%s', got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestIsolate_StuckWorkers verifies new work is refused while too many abandoned
+// workers are running, and accepted again once they finish
+func TestIsolate_StuckWorkers(t *testing.T) {
+	waitForStuckWorkers(t)
+
+	release := make(chan struct{})
+	block := func() (int, error) { <-release; return 0, nil }
+
+	for i := 0; i < maxStuckWorkers; i++ {
+		if _, err := isolate(time.Millisecond, "decode", block); err == nil {
+			t.Fatal("expected timeout")
+		}
+	}
+
+	_, err := isolate(time.Second, "decode", func() (int, error) { return 1, nil })
+	if err == nil || !strings.Contains(err.Error(), "refused") {
+		t.Fatalf("expected work to be refused, got %v", err)
+	}
+
+	close(release)
+	waitForStuckWorkers(t)
+	if _, err := isolate(time.Second, "decode", func() (int, error) { return 1, nil }); err != nil {
+		t.Errorf("expected work to be accepted after workers finished, got %v", err)
+	}
+}
+
+// waitForStuckWorkers waits until workers abandoned by earlier tests have finished
+func waitForStuckWorkers(t *testing.T) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for stuckWorkers.Load() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d workers still stuck", stuckWorkers.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,21 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/disintegration/imaging"
+)
+
+// TestBlurredFill_LowPower verifies the cheap blur keeps the size and looks
+// close to the full-resolution one
+func TestBlurredFill_LowPower(t *testing.T) {
+	full := blurredFill(goldenArtwork(), 384, 216, imaging.Center, 15, imaging.Lanczos, false)
+	cheap := blurredFill(goldenArtwork(), 384, 216, imaging.Center, 15, imaging.Lanczos, true)
+
+	if cheap.Bounds() != full.Bounds() {
+		t.Fatalf("expected %v, got %v", full.Bounds(), cheap.Bounds())
+	}
+	if score := ssim(full, cheap); score < 0.9 {
+		t.Errorf("expected the cheap blur to resemble the full one, SSIM %.3f", score)
+	}
+}
@@ -0,0 +1,37 @@
+//go:build linux
+// +build linux
+
+package processor
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// writeMemfd stores the wallpaper in a new anonymous in-memory file and returns a
+// path other processes of the user can open. The previous file is closed, since
+// setters that support this delivery read the image only once.
+func (p *BlurProcessor) writeMemfd(data []byte) (string, error) {
+	fd, err := unix.MemfdCreate("synest-wallpaper", unix.MFD_CLOEXEC)
+	if err != nil {
+		return "", fmt.Errorf("failed to create memfd: %w", err)
+	}
+	file := os.NewFile(uintptr(fd), "synest-wallpaper")
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return "", fmt.Errorf("failed to write memfd: %w", err)
+	}
+
+	p.memfdMu.Lock()
+	previous := p.memfd
+	p.memfd = file
+	p.memfdMu.Unlock()
+	if previous != nil {
+		_ = previous.Close()
+	}
+
+	// The setter runs in another process, where /proc/self would point at its own fds
+	return fmt.Sprintf("/proc/%d/fd/%d", os.Getpid(), fd), nil
+}
@@ -0,0 +1,54 @@
+//go:build linux
+// +build linux
+
+package processor
+
+import (
+	"bytes"
+	"context"
+	"image/color"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// TestGenerate_Memfd verifies memfd delivery writes nothing to disk and keeps
+// only the wallpaper on screen open
+func TestGenerate_Memfd(t *testing.T) {
+	outputDir := t.TempDir()
+	res := &domain.ScreenResolution{Width: 64, Height: 36}
+	processor := NewBlurProcessor(zap.NewNop(), res, &mockConfig{outputDir: outputDir, delivery: domain.DeliveryMemfd})
+	cover := createTestJPEG(8, 8, color.RGBA{G: 255, A: 255})
+
+	first, err := processor.Generate(context.Background(), cover, domain.MediaMetadata{Title: "A"}, domain.ModeBlur)
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+	if !strings.HasPrefix(first, "/proc/") {
+		t.Fatalf("expected a /proc fd path, got %s", first)
+	}
+	data, err := os.ReadFile(first)
+	if err != nil || !bytes.HasPrefix(data, []byte{0xFF, 0xD8}) {
+		t.Fatalf("expected a readable JPEG at %s, got err %v", first, err)
+	}
+
+	second, err := processor.Generate(context.Background(), cover, domain.MediaMetadata{Title: "B"}, domain.ModeBlur)
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+	if _, err := os.ReadFile(second); err != nil {
+		t.Errorf("expected the new wallpaper to be readable: %v", err)
+	}
+	if first != second {
+		if _, err := os.Stat(first); err == nil {
+			t.Error("expected the previous in-memory wallpaper to be closed")
+		}
+	}
+
+	if entries, _ := os.ReadDir(outputDir); len(entries) != 0 {
+		t.Errorf("expected nothing written to the output directory, got %d entries", len(entries))
+	}
+}
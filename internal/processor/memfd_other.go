@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package processor
+
+import "fmt"
+
+// writeMemfd is not supported outside Linux, the executor rejects memfd delivery there
+func (p *BlurProcessor) writeMemfd(data []byte) (string, error) {
+	return "", fmt.Errorf("memfd delivery is only supported on Linux")
+}
@@ -0,0 +1,59 @@
+package processor
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/disintegration/imaging"
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+const (
+	moodMaxTint       = 18.0  // Max channel shift (0-255) applied for very happy/sad tracks
+	moodMaxContrast   = 15.0  // Contrast adjustment (percent) at the energy extremes
+	moodMaxSaturation = 20.0  // Saturation adjustment (percent) at the energy extremes
+	moodNeutralTempo  = 120.0 // Tempo (BPM) that leaves saturation untouched
+	moodTempoWeight   = 0.1   // Saturation percent added per BPM above the neutral tempo
+)
+
+// applyMood grades the wallpaper using the track's audio features:
+// valence shifts the color temperature (happy = warm, sad = cool),
+// energy raises or lowers contrast and saturation, and faster tempos
+// add a little extra saturation.
+func applyMood(img image.Image, features *domain.AudioFeatures) image.Image {
+	if features == nil {
+		return img
+	}
+
+	// Map 0..1 features to -1..1 so 0.5 is neutral
+	valence := clamp(features.Valence*2-1, -1, 1)
+	energy := clamp(features.Energy*2-1, -1, 1)
+
+	tint := valence * moodMaxTint
+	graded := imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+		return color.NRGBA{
+			R: shiftChannel(c.R, tint),
+			G: shiftChannel(c.G, tint*0.3),
+			B: shiftChannel(c.B, -tint),
+			A: c.A,
+		}
+	})
+
+	saturation := energy * moodMaxSaturation
+	if features.Tempo > 0 {
+		saturation += clamp((features.Tempo-moodNeutralTempo)*moodTempoWeight, -5, 5)
+	}
+
+	graded = imaging.AdjustContrast(graded, energy*moodMaxContrast)
+	return imaging.AdjustSaturation(graded, saturation)
+}
+
+// shiftChannel adds delta to a channel value, clamping to the valid range
+func shiftChannel(v uint8, delta float64) uint8 {
+	return uint8(clamp(math.Round(float64(v)+delta), 0, 255))
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
@@ -0,0 +1,68 @@
+package processor
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+func TestApplyMood(t *testing.T) {
+	gray := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for i := 0; i < len(gray.Pix); i += 4 {
+		gray.Pix[i], gray.Pix[i+1], gray.Pix[i+2], gray.Pix[i+3] = 128, 128, 128, 255
+	}
+
+	pixel := func(img image.Image) color.NRGBA {
+		return color.NRGBAModel.Convert(img.At(1, 1)).(color.NRGBA)
+	}
+
+	tests := []struct {
+		name     string
+		features *domain.AudioFeatures
+		check    func(t *testing.T, c color.NRGBA)
+	}{
+		{
+			name:     "Happy Track Is Warm",
+			features: &domain.AudioFeatures{Energy: 0.5, Valence: 1, Tempo: 120},
+			check: func(t *testing.T, c color.NRGBA) {
+				if c.R <= c.B {
+					t.Errorf("expected red > blue for happy track, got %+v", c)
+				}
+			},
+		},
+		{
+			name:     "Sad Track Is Cool",
+			features: &domain.AudioFeatures{Energy: 0.5, Valence: 0, Tempo: 120},
+			check: func(t *testing.T, c color.NRGBA) {
+				if c.B <= c.R {
+					t.Errorf("expected blue > red for sad track, got %+v", c)
+				}
+			},
+		},
+		{
+			name:     "Neutral Track Is Unchanged",
+			features: &domain.AudioFeatures{Energy: 0.5, Valence: 0.5, Tempo: 120},
+			check: func(t *testing.T, c color.NRGBA) {
+				if c.R != 128 || c.G != 128 || c.B != 128 {
+					t.Errorf("expected unchanged gray, got %+v", c)
+				}
+			},
+		},
+		{
+			name: "No Features Returns Input",
+			check: func(t *testing.T, c color.NRGBA) {
+				if c.R != 128 || c.B != 128 {
+					t.Errorf("expected unchanged gray, got %+v", c)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check(t, pixel(applyMood(gray, tt.features)))
+		})
+	}
+}
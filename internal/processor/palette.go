@@ -0,0 +1,96 @@
+package processor
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sort"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	paletteSampleSize = 32 // Artwork is downscaled to this size before sampling
+	paletteBucketBits = 4  // Bits kept per channel when grouping similar colors
+	wallpaperColors   = 5  // Colors of a generated wallpaper recorded in its metadata and published to themes
+)
+
+// paletteBucket accumulates the pixels that fall into one quantized color cell
+type paletteBucket struct {
+	key     int
+	count   int
+	r, g, b int
+}
+
+// extractPalette returns up to n dominant colors of img, most frequent first.
+// Colors are averaged within coarse RGB buckets, so the result is stable for
+// near-identical inputs (e.g. the same cover re-encoded at another quality).
+func extractPalette(img image.Image, n int) []color.RGBA {
+	if img == nil || n <= 0 {
+		return nil
+	}
+
+	thumb := imaging.Resize(img, paletteSampleSize, paletteSampleSize, imaging.Box)
+	shift := 8 - paletteBucketBits
+
+	buckets := make(map[int]*paletteBucket)
+	for i := 0; i+3 < len(thumb.Pix); i += 4 {
+		r, g, b, a := int(thumb.Pix[i]), int(thumb.Pix[i+1]), int(thumb.Pix[i+2]), thumb.Pix[i+3]
+		if a == 0 {
+			continue // Fully transparent pixels carry no color information
+		}
+
+		key := (r>>shift)<<(2*paletteBucketBits) | (g>>shift)<<paletteBucketBits | b>>shift
+		bucket, ok := buckets[key]
+		if !ok {
+			bucket = &paletteBucket{key: key}
+			buckets[key] = bucket
+		}
+		bucket.count++
+		bucket.r += r
+		bucket.g += g
+		bucket.b += b
+	}
+
+	sorted := make([]*paletteBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		sorted = append(sorted, bucket)
+	}
+	// Ties are broken by key so map iteration order never leaks into the result
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].count != sorted[j].count {
+			return sorted[i].count > sorted[j].count
+		}
+		return sorted[i].key < sorted[j].key
+	})
+
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+
+	palette := make([]color.RGBA, 0, len(sorted))
+	for _, bucket := range sorted {
+		palette = append(palette, color.RGBA{
+			R: uint8(bucket.r / bucket.count),
+			G: uint8(bucket.g / bucket.count),
+			B: uint8(bucket.b / bucket.count),
+			A: 255,
+		})
+	}
+
+	return palette
+}
+
+// hexPalette returns up to n dominant colors of img as #rrggbb, most frequent first
+func hexPalette(img image.Image, n int) []string {
+	return hexColors(extractPalette(img, n))
+}
+
+// hexColors formats colors as #rrggbb
+func hexColors(colors []color.RGBA) []string {
+	var hex []string
+	for _, c := range colors {
+		hex = append(hex, fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B))
+	}
+	return hex
+}
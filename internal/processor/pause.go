@@ -0,0 +1,24 @@
+package processor
+
+import (
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	pauseBrightness = -35.0 // Brightness adjustment (percent) while paused
+	pauseSaturation = -60.0 // Saturation adjustment (percent) while paused
+	quietBrightness = -50.0 // Brightness adjustment (percent) during quiet hours
+)
+
+// applyPause dims and desaturates the wallpaper of a paused track,
+// so the screen hints that playback stopped without losing the artwork
+func applyPause(img image.Image) image.Image {
+	return imaging.AdjustBrightness(imaging.AdjustSaturation(img, pauseSaturation), pauseBrightness)
+}
+
+// applyQuiet darkens the wallpaper during quiet hours, keeping its colors
+func applyQuiet(img image.Image) image.Image {
+	return imaging.AdjustBrightness(img, quietBrightness)
+}
@@ -0,0 +1,38 @@
+package processor
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestApplyPause(t *testing.T) {
+	img := goldenArtwork()
+	paused := applyPause(img)
+
+	before := color.NRGBAModel.Convert(img.At(32, 32)).(color.NRGBA)
+	after := color.NRGBAModel.Convert(paused.At(32, 32)).(color.NRGBA)
+
+	if int(after.R)+int(after.G)+int(after.B) >= int(before.R)+int(before.G)+int(before.B) {
+		t.Errorf("expected paused pixel to be darker: before %+v, after %+v", before, after)
+	}
+	if spread(after) >= spread(before) {
+		t.Errorf("expected paused pixel to be less saturated: before %+v, after %+v", before, after)
+	}
+}
+
+func TestApplyQuiet(t *testing.T) {
+	img := goldenArtwork()
+	quiet := applyQuiet(img)
+
+	before := color.NRGBAModel.Convert(img.At(32, 32)).(color.NRGBA)
+	after := color.NRGBAModel.Convert(quiet.At(32, 32)).(color.NRGBA)
+
+	if int(after.R)+int(after.G)+int(after.B) >= int(before.R)+int(before.G)+int(before.B) {
+		t.Errorf("expected quiet pixel to be darker: before %+v, after %+v", before, after)
+	}
+}
+
+// spread is the difference between the strongest and weakest channel
+func spread(c color.NRGBA) int {
+	return int(max(c.R, c.G, c.B)) - int(min(c.R, c.G, c.B))
+}
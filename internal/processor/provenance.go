@@ -0,0 +1,95 @@
+package processor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/genricoloni/synest/internal/domain"
+)
+
+const (
+	xmpNamespace     = "https://github.com/genricoloni/synest/ns/1.0/"
+	xmpHeader        = "http://ns.adobe.com/xap/1.0/\x00" // Identifies an XMP APP1 segment
+	maxSegmentLength = 0xFFFF                             // JPEG segment length field, including itself
+)
+
+// provenance describes where a wallpaper came from
+type provenance struct {
+	Title   string
+	Artist  string
+	ArtURL  string
+	URL     string // Location of the media itself, a link back to the source
+	Mode    string
+	Palette []string // Dominant colors of the wallpaper as #rrggbb, most frequent first
+}
+
+// newProvenance collects the provenance of a rendered wallpaper with its palette
+func newProvenance(palette []string, meta domain.MediaMetadata, mode string) provenance {
+	return provenance{
+		Title:   meta.Title,
+		Artist:  meta.DisplayArtist(),
+		ArtURL:  meta.ArtUrl,
+		URL:     meta.URL,
+		Mode:    mode,
+		Palette: palette,
+	}
+}
+
+// xmpPacket serializes the provenance as an XMP packet in the synest namespace
+func (p provenance) xmpPacket() []byte {
+	var b strings.Builder
+	b.WriteString("<?xpacket begin=\"\ufeff\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>")
+	b.WriteString(`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">`)
+	b.WriteString(`<rdf:Description rdf:about="" xmlns:synest="` + xmpNamespace + `">`)
+
+	for _, field := range []struct{ name, value string }{
+		{"Title", p.Title},
+		{"Artist", p.Artist},
+		{"ArtURL", p.ArtURL},
+		{"URL", p.URL},
+		{"Mode", p.Mode},
+	} {
+		if field.value == "" {
+			continue
+		}
+		b.WriteString("<synest:" + field.name + ">")
+		_ = xml.EscapeText(&b, []byte(field.value))
+		b.WriteString("</synest:" + field.name + ">")
+	}
+
+	if len(p.Palette) > 0 {
+		b.WriteString("<synest:Palette><rdf:Seq>")
+		for _, c := range p.Palette {
+			b.WriteString("<rdf:li>" + c + "</rdf:li>")
+		}
+		b.WriteString("</rdf:Seq></synest:Palette>")
+	}
+
+	b.WriteString(`</rdf:Description></rdf:RDF></x:xmpmeta><?xpacket end="w"?>`)
+	return []byte(b.String())
+}
+
+// embedXMP inserts the provenance as an XMP APP1 segment right after the
+// start-of-image marker of an encoded JPEG
+func embedXMP(jpegData []byte, p provenance) ([]byte, error) {
+	if len(jpegData) < 2 || jpegData[0] != 0xFF || jpegData[1] != 0xD8 {
+		return nil, fmt.Errorf("not a JPEG image")
+	}
+
+	payload := append([]byte(xmpHeader), p.xmpPacket()...)
+	if len(payload)+2 > maxSegmentLength {
+		return nil, fmt.Errorf("XMP metadata too large: %d bytes", len(payload))
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(len(jpegData) + len(payload) + 4)
+	buf.Write(jpegData[:2])
+	buf.Write([]byte{0xFF, 0xE1})
+	_ = binary.Write(&buf, binary.BigEndian, uint16(len(payload)+2))
+	buf.Write(payload)
+	buf.Write(jpegData[2:])
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,67 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+func TestEmbedXMP(t *testing.T) {
+	p := provenance{Title: "Rock & <Roll>", Artist: "Band", Mode: domain.ModeBlur, Palette: []string{"#ff0000"}}
+
+	annotated, err := embedXMP(createTestJPEG(16, 16, color.RGBA{R: 255, A: 255}), p)
+	if err != nil {
+		t.Fatalf("embed failed: %v", err)
+	}
+	if _, _, err := image.Decode(bytes.NewReader(annotated)); err != nil {
+		t.Fatalf("annotated JPEG no longer decodes: %v", err)
+	}
+
+	for _, want := range []string{xmpHeader, "<synest:Title>Rock &amp; &lt;Roll&gt;</synest:Title>", "<rdf:li>#ff0000</rdf:li>"} {
+		if !bytes.Contains(annotated, []byte(want)) {
+			t.Errorf("expected metadata to contain %q", want)
+		}
+	}
+	if bytes.Contains(annotated, []byte("synest:ArtURL")) {
+		t.Error("expected empty fields to be omitted")
+	}
+
+	if _, err := embedXMP(createTestPNG(4, 4), p); err == nil {
+		t.Error("expected an error for a non-JPEG image")
+	}
+}
+
+// TestGenerate_Provenance verifies generated wallpapers carry their provenance
+func TestGenerate_Provenance(t *testing.T) {
+	res := &domain.ScreenResolution{Width: 192, Height: 108}
+	processor := NewBlurProcessor(zap.NewNop(), res, &mockConfig{outputDir: t.TempDir()})
+
+	meta := domain.MediaMetadata{Title: "Song", Artist: "Artist", ArtUrl: "https://example.com/a.jpg"}
+	path, err := processor.Generate(context.Background(), createTestJPEG(32, 32, color.RGBA{B: 255, A: 255}), meta, domain.ModeBlur)
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"<synest:Artist>Artist</synest:Artist>", "<synest:ArtURL>https://example.com/a.jpg</synest:ArtURL>", "<synest:Mode>blur</synest:Mode>"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected wallpaper metadata to contain %q", want)
+		}
+	}
+
+	// The same palette is published to themes
+	palette := processor.Palette()
+	if len(palette) == 0 || !strings.Contains(string(data), "<rdf:li>"+palette[0]+"</rdf:li>") {
+		t.Errorf("expected the wallpaper palette %v in its metadata", palette)
+	}
+}
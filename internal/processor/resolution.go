@@ -0,0 +1,55 @@
+package processor
+
+import (
+	"context"
+	"image"
+
+	"github.com/disintegration/imaging"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/logctx"
+	"go.uber.org/zap"
+)
+
+// generativeMaxHeight is the render height cap declared by the generative mode
+const generativeMaxHeight = 1440
+
+// maxHeight returns the render height cap of mode: the user setting, else the
+// cap declared by the mode, 0 when there is none
+func (p *BlurProcessor) maxHeight(mode string) int {
+	cfg := p.appCfg.GetModeConfig()
+	var limit int
+	switch mode {
+	case domain.ModeBlur:
+		limit = cfg.Blur.MaxHeight
+	case domain.ModeGenerative:
+		limit = cfg.Generative.MaxHeight
+	case domain.ModeWaveform:
+		limit = cfg.Waveform.MaxHeight
+	}
+	if limit > 0 {
+		return limit
+	}
+	return p.maxHeights[mode]
+}
+
+// renderCapped renders mode at the screen resolution, or below it when the mode
+// is capped, scaling the result up to the screen so later steps, such as the
+// badge, stay sharp
+func (p *BlurProcessor) renderCapped(ctx context.Context, src image.Image, meta domain.MediaMetadata, mode string) (image.Image, error) {
+	limit := p.maxHeight(mode)
+	if limit <= 0 || p.res.Height <= limit {
+		return p.modes[mode](ctx, src, meta)
+	}
+
+	res := &domain.ScreenResolution{Width: max(p.res.Width*limit/p.res.Height, 1), Height: limit}
+	logctx.Logger(ctx, p.logger).Debug("Rendering below the screen resolution",
+		zap.String("mode", mode),
+		zap.Int("w", res.Width),
+		zap.Int("h", res.Height))
+	small := NewBlurProcessor(p.logger, res, p.appCfg)
+	result, err := small.modes[mode](ctx, src, meta)
+	if err != nil {
+		return nil, err
+	}
+	return imaging.Resize(result, p.res.Width, p.res.Height, imaging.CatmullRom), nil
+}
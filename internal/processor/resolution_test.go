@@ -0,0 +1,36 @@
+package processor
+
+import (
+	"context"
+	"image/color"
+	"testing"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// TestRenderCapped verifies a capped mode is rendered below the screen
+// resolution and scaled up, and the user setting overrides the declared caps
+func TestRenderCapped(t *testing.T) {
+	res := &domain.ScreenResolution{Width: 640, Height: 360}
+	p := NewBlurProcessor(zap.NewNop(), res, &mockConfig{maxHeight: 90})
+
+	caps := map[string]int{
+		domain.ModeBlur:       90,
+		domain.ModeGenerative: generativeMaxHeight,
+		domain.ModeWaveform:   0,
+	}
+	for mode, expected := range caps {
+		if got := p.maxHeight(mode); got != expected {
+			t.Errorf("expected a %s cap of %d, got %d", mode, expected, got)
+		}
+	}
+
+	img, err := p.Render(context.Background(), createTestJPEG(64, 64, color.RGBA{R: 255, A: 255}), domain.MediaMetadata{}, domain.ModeBlur)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != res.Width || b.Dy() != res.Height {
+		t.Errorf("expected the capped render scaled to %dx%d, got %dx%d", res.Width, res.Height, b.Dx(), b.Dy())
+	}
+}
@@ -0,0 +1,154 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/logctx"
+	"github.com/genricoloni/synest/internal/paths"
+	"github.com/genricoloni/synest/internal/privacy"
+	"go.uber.org/zap"
+)
+
+const (
+	sceneFilename        = "scene.json"
+	sceneArtworkFilename = "scene_artwork.jpg"
+	sceneVersion         = 1 // Bumped when fields change meaning or go away
+)
+
+// Background kinds of a scene
+const (
+	sceneBlurredArtwork = "blurred_artwork" // The artwork filling the screen, blurred
+	sceneGradient       = "gradient"        // A linear gradient through the colors
+)
+
+// scene describes a composition for renderers of their own, e.g. a shader in a
+// wallpaper engine, which draw it instead of synest
+type scene struct {
+	Version    int             `json:"version"`
+	Width      int             `json:"width"`
+	Height     int             `json:"height"`
+	Mode       string          `json:"mode"`              // Mode rendered, auto resolved to the one it picked
+	Artwork    string          `json:"artwork,omitempty"` // Path of the shaped artwork, absent without one
+	Background sceneBackground `json:"background"`
+	Cover      *sceneRect      `json:"cover,omitempty"` // Sharp cover, absent for modes without one
+	Palette    []string        `json:"palette"`         // Dominant colors as #rrggbb, most frequent first
+	Text       []sceneText     `json:"text,omitempty"`  // Absent for private tracks
+	Dimmed     bool            `json:"dimmed"`          // Paused track or quiet hours asking for a darker rendering
+}
+
+// sceneBackground describes what fills the screen behind the cover
+type sceneBackground struct {
+	Kind       string   `json:"kind"`
+	BlurRadius float64  `json:"blur_radius,omitempty"`
+	Colors     []string `json:"colors,omitempty"` // Gradient stops
+}
+
+// sceneRect places an element on the screen, in pixels from the top-left corner
+type sceneRect struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// sceneText is a line of track information, laid out by the renderer
+type sceneText struct {
+	Role string `json:"role"` // title, artist or album
+	Text string `json:"text"`
+}
+
+// newScene describes the composition of mode for the track. The palette is
+// the one of the rendered wallpaper, or of the artwork when nothing is rendered.
+func (p *BlurProcessor) newScene(ctx context.Context, src image.Image, meta domain.MediaMetadata, mode string, palette []string) scene {
+	if mode == domain.ModeAuto {
+		mode, _ = chooseMode(src, meta, p.appCfg.GetAutoGenres())
+	}
+	if palette == nil {
+		palette = []string{}
+	}
+	s := scene{
+		Version: sceneVersion,
+		Width:   p.res.Width,
+		Height:  p.res.Height,
+		Mode:    mode,
+		Palette: palette,
+		Dimmed:  meta.Status == domain.StatusPaused || meta.Quiet,
+	}
+
+	modes := p.appCfg.GetModeConfig()
+	var coverSize float64
+	switch {
+	case mode == domain.ModeGenerative || src == nil:
+		_, _, colors := generativePalette(src, meta)
+		s.Background = sceneBackground{Kind: sceneGradient, Colors: hexColors(colors)}
+	case mode == domain.ModeWaveform:
+		s.Background = sceneBackground{Kind: sceneBlurredArtwork, BlurRadius: modes.Waveform.BlurRadius}
+		coverSize = modes.Waveform.CoverSize
+	default:
+		s.Background = sceneBackground{Kind: sceneBlurredArtwork, BlurRadius: modes.Blur.BlurRadius}
+		coverSize = modes.Blur.CoverSize
+	}
+	if coverSize > 0 {
+		width, height := p.coverSize(src.Bounds(), coverSize)
+		s.Cover = &sceneRect{X: (p.res.Width - width) / 2, Y: (p.res.Height - height) / 2, Width: width, Height: height}
+	}
+
+	if !privacy.Private(ctx) {
+		for _, line := range []sceneText{
+			{Role: "title", Text: meta.Title},
+			{Role: "artist", Text: meta.DisplayArtist()},
+			{Role: "album", Text: meta.Album},
+		} {
+			if line.Text != "" {
+				s.Text = append(s.Text, line)
+			}
+		}
+	}
+	return s
+}
+
+// writeScene writes the scene of the track and its artwork to the output
+// directory, and returns the absolute path of the scene. Without artwork the
+// previous one is removed.
+func (p *BlurProcessor) writeScene(ctx context.Context, src image.Image, meta domain.MediaMetadata, mode string, palette []string) (string, error) {
+	outputDir := p.appCfg.GetOutputDir()
+	if err := paths.Ensure(outputDir); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w: %w", domain.ErrOutputUnavailable, err)
+	}
+	if abs, err := filepath.Abs(outputDir); err == nil {
+		outputDir = abs
+	}
+
+	s := p.newScene(ctx, src, meta, mode, palette)
+	artworkPath := filepath.Join(outputDir, sceneArtworkFilename)
+	if src != nil {
+		data, err := encodeIsolated(src, p.config().JPEGQuality)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode scene artwork: %w", err)
+		}
+		if err := replaceFile(artworkPath, data); err != nil {
+			return "", fmt.Errorf("failed to write scene artwork: %w: %w", domain.ErrOutputUnavailable, err)
+		}
+		s.Artwork = artworkPath
+	} else if err := os.Remove(artworkPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		logctx.Logger(ctx, p.logger).Warn("Failed to remove the previous scene artwork", zap.Error(err))
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode scene: %w", err)
+	}
+	scenePath := filepath.Join(outputDir, sceneFilename)
+	if err := replaceFile(scenePath, data); err != nil {
+		return "", fmt.Errorf("failed to write scene: %w: %w", domain.ErrOutputUnavailable, err)
+	}
+	return scenePath, nil
+}
@@ -0,0 +1,88 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/privacy"
+	"go.uber.org/zap"
+)
+
+// TestGenerate_Scene verifies the scene is written next to the wallpaper, or
+// instead of it, and keeps private tracks out of its text
+func TestGenerate_Scene(t *testing.T) {
+	res := &domain.ScreenResolution{Width: 200, Height: 100}
+	meta := domain.MediaMetadata{Title: "Song", Artist: "Artist", Status: domain.StatusPlaying}
+	art := createTestJPEG(64, 64, color.RGBA{R: 200, A: 255})
+
+	read := func(t *testing.T, path string) scene {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("scene not written: %v", err)
+		}
+		var s scene
+		if err := json.Unmarshal(data, &s); err != nil {
+			t.Fatalf("scene does not decode: %v", err)
+		}
+		return s
+	}
+
+	t.Run("Alongside", func(t *testing.T) {
+		dir := t.TempDir()
+		p := NewBlurProcessor(zap.NewNop(), res, &mockConfig{outputDir: dir, scene: domain.SceneAlongside})
+		path, err := p.Generate(context.Background(), art, meta, domain.ModeBlur)
+		if err != nil {
+			t.Fatalf("generate failed: %v", err)
+		}
+		if filepath.Base(path) != wallpaperFilename {
+			t.Errorf("expected the wallpaper path, got %s", path)
+		}
+
+		s := read(t, filepath.Join(dir, sceneFilename))
+		if s.Background.Kind != sceneBlurredArtwork || s.Background.BlurRadius != 15 {
+			t.Errorf("expected a blurred artwork background, got %+v", s.Background)
+		}
+		// The 40% cover of a 100px high screen is centered
+		if s.Cover == nil || *s.Cover != (sceneRect{X: 80, Y: 30, Width: 40, Height: 40}) {
+			t.Errorf("expected a centered 40x40 cover, got %+v", s.Cover)
+		}
+		if len(s.Palette) == 0 || len(s.Text) != 2 || s.Text[0] != (sceneText{Role: "title", Text: "Song"}) {
+			t.Errorf("expected the palette and the track lines, got %+v and %+v", s.Palette, s.Text)
+		}
+		if _, err := os.Stat(s.Artwork); err != nil {
+			t.Errorf("expected the scene artwork written: %v", err)
+		}
+	})
+
+	t.Run("Only", func(t *testing.T) {
+		dir := t.TempDir()
+		p := NewBlurProcessor(zap.NewNop(), res, &mockConfig{outputDir: dir, scene: domain.SceneOnly})
+		path, err := p.Generate(privacy.WithPrivate(context.Background()), nil, meta, domain.ModeGenerative)
+		if err != nil {
+			t.Fatalf("generate failed: %v", err)
+		}
+		if filepath.Base(path) != sceneFilename {
+			t.Errorf("expected the scene path, got %s", path)
+		}
+		if _, err := os.Stat(filepath.Join(dir, wallpaperFilename)); !os.IsNotExist(err) {
+			t.Errorf("expected no wallpaper rendered, got %v", err)
+		}
+
+		s := read(t, path)
+		if s.Background.Kind != sceneGradient || len(s.Background.Colors) < 2 || s.Cover != nil {
+			t.Errorf("expected a gradient without cover, got %+v and %+v", s.Background, s.Cover)
+		}
+		if s.Text != nil || s.Artwork != "" {
+			t.Errorf("expected no track lines nor artwork, got %+v and %q", s.Text, s.Artwork)
+		}
+		if len(p.Palette()) == 0 {
+			t.Error("expected the palette published without artwork")
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package processor
+
+import (
+	"context"
+	"image"
+	"image/draw"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// spanOutputs creates a processor per display of a spanned resolution,
+// each rendering at the size of its display
+func spanOutputs(logger *zap.Logger, res *domain.ScreenResolution, appCfg domain.Config) []*BlurProcessor {
+	outputs := make([]*BlurProcessor, len(res.Outputs))
+	for i, out := range res.Outputs {
+		outputs[i] = NewBlurProcessor(logger, &domain.ScreenResolution{Width: out.Dx(), Height: out.Dy()}, appCfg)
+	}
+	return outputs
+}
+
+// renderSpan composes one image covering the whole virtual desktop, rendering the
+// mode separately inside each display so the cover never straddles a seam.
+// Areas outside every display stay black.
+func (p *BlurProcessor) renderSpan(ctx context.Context, src image.Image, meta domain.MediaMetadata, mode string) (image.Image, error) {
+	canvas := image.NewNRGBA(image.Rect(0, 0, p.res.Width, p.res.Height))
+	draw.Draw(canvas, canvas.Bounds(), image.Black, image.Point{}, draw.Src)
+
+	for i, out := range p.outputs {
+		img, err := out.render(ctx, src, meta, mode)
+		if err != nil {
+			return nil, err
+		}
+		draw.Draw(canvas, p.res.Outputs[i], img, img.Bounds().Min, draw.Src)
+	}
+
+	return canvas, nil
+}
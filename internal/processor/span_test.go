@@ -0,0 +1,78 @@
+package processor
+
+import (
+	"context"
+	"image"
+	"testing"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// TestRenderSpan verifies each display of a spanned image gets its own
+// composition and the gap between displays stays black
+func TestRenderSpan(t *testing.T) {
+	// A large display next to a smaller one, bottom-aligned
+	res := &domain.ScreenResolution{
+		Width:  320,
+		Height: 108,
+		Outputs: []image.Rectangle{
+			image.Rect(0, 0, 192, 108),
+			image.Rect(192, 36, 320, 108),
+		},
+	}
+	cfg := &mockConfig{deterministic: true}
+	processor := NewBlurProcessor(zap.NewNop(), res, cfg)
+
+	img, err := processor.render(context.Background(), goldenArtwork(), domain.MediaMetadata{}, domain.ModeBlur)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if img.Bounds() != image.Rect(0, 0, 320, 108) {
+		t.Fatalf("expected the virtual desktop size, got %v", img.Bounds())
+	}
+
+	for i, out := range res.Outputs {
+		single := NewBlurProcessor(zap.NewNop(), &domain.ScreenResolution{Width: out.Dx(), Height: out.Dy()}, cfg)
+		expected, err := single.render(context.Background(), goldenArtwork(), domain.MediaMetadata{}, domain.ModeBlur)
+		if err != nil {
+			t.Fatalf("output %d: render failed: %v", i, err)
+		}
+		center := image.Pt(out.Dx()/2, out.Dy()/2)
+		if got, want := img.At(out.Min.X+center.X, out.Min.Y+center.Y), expected.At(center.X, center.Y); got != want {
+			t.Errorf("output %d: expected the cover centered on the display (%v), got %v", i, want, got)
+		}
+	}
+
+	if r, g, b, _ := img.At(250, 10).RGBA(); r|g|b != 0 {
+		t.Errorf("expected black outside the displays, got %v", img.At(250, 10))
+	}
+}
+
+// TestResize verifies a processor started at the fallback size renders at the
+// detected resolution, spanned or not
+func TestResize(t *testing.T) {
+	cfg := &mockConfig{deterministic: true}
+	processor := NewBlurProcessor(zap.NewNop(), &domain.ScreenResolution{Width: 192, Height: 108, Fallback: true}, cfg)
+
+	processor.Resize(domain.ScreenResolution{
+		Width:   320,
+		Height:  108,
+		Outputs: []image.Rectangle{image.Rect(0, 0, 192, 108), image.Rect(192, 0, 320, 108)},
+	})
+	img, err := processor.render(context.Background(), goldenArtwork(), domain.MediaMetadata{}, domain.ModeBlur)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if img.Bounds() != image.Rect(0, 0, 320, 108) || len(processor.outputs) != 2 {
+		t.Errorf("expected a spanned 320x108 image, got %v with %d outputs", img.Bounds(), len(processor.outputs))
+	}
+
+	processor.Resize(domain.ScreenResolution{Width: 160, Height: 90})
+	if img, err = processor.render(context.Background(), goldenArtwork(), domain.MediaMetadata{}, domain.ModeBlur); err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if img.Bounds() != image.Rect(0, 0, 160, 90) || processor.outputs != nil {
+		t.Errorf("expected a single 160x90 image, got %v", img.Bounds())
+	}
+}
@@ -0,0 +1,62 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/disintegration/imaging"
+	"github.com/genricoloni/synest/internal/paths"
+)
+
+// thumbnailSizes are the sides of the exported cover thumbnails, in pixels
+var thumbnailSizes = []int{64, 128, 256, 512}
+
+// thumbnailPath returns where the cover thumbnail of the given size is written,
+// e.g. cover_128.jpg next to the wallpaper
+func thumbnailPath(outputDir string, size int) string {
+	return filepath.Join(outputDir, fmt.Sprintf("cover_%d.jpg", size))
+}
+
+// exportThumbnails writes square center crops of the raw cover, scaled with
+// filter, for bars, notification daemons and widgets. Each file is replaced
+// atomically so readers never see a partial image. Without a cover the previous
+// thumbnails are removed rather than left showing another track.
+func exportThumbnails(cover image.Image, outputDir string, quality int, filter imaging.ResampleFilter) error {
+	if cover == nil {
+		var errs []error
+		for _, size := range thumbnailSizes {
+			if err := os.Remove(thumbnailPath(outputDir, size)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	if err := paths.Ensure(outputDir); err != nil {
+		return err
+	}
+	for _, size := range thumbnailSizes {
+		data, err := encodeIsolated(imaging.Fill(cover, size, size, imaging.Center, filter), quality)
+		if err != nil {
+			return err
+		}
+		if err := replaceFile(thumbnailPath(outputDir, size), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceFile writes data to path atomically, readers see the old or the new
+// content but never a partial one
+func replaceFile(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
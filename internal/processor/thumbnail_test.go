@@ -0,0 +1,49 @@
+package processor
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"os"
+	"testing"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+// TestGenerate_Thumbnails verifies square cover crops are exported with the
+// wallpaper and removed once a track has no cover
+func TestGenerate_Thumbnails(t *testing.T) {
+	dir := t.TempDir()
+	res := &domain.ScreenResolution{Width: 192, Height: 108}
+	processor := NewBlurProcessor(zap.NewNop(), res, &mockConfig{outputDir: dir, thumbnails: true})
+
+	meta := domain.MediaMetadata{Title: "Song", Artist: "Artist"}
+	if _, err := processor.Generate(context.Background(), createTestJPEG(96, 48, color.RGBA{G: 255, A: 255}), meta, domain.ModeBlur); err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+
+	for _, size := range thumbnailSizes {
+		f, err := os.Open(thumbnailPath(dir, size))
+		if err != nil {
+			t.Fatalf("thumbnail %d not exported: %v", size, err)
+		}
+		cfg, _, err := image.DecodeConfig(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("thumbnail %d does not decode: %v", size, err)
+		}
+		if cfg.Width != size || cfg.Height != size {
+			t.Errorf("expected a %dx%d thumbnail, got %dx%d", size, size, cfg.Width, cfg.Height)
+		}
+	}
+
+	if _, err := processor.Generate(context.Background(), nil, meta, domain.ModeGenerative); err != nil {
+		t.Fatalf("generate without cover failed: %v", err)
+	}
+	for _, size := range thumbnailSizes {
+		if _, err := os.Stat(thumbnailPath(dir, size)); !os.IsNotExist(err) {
+			t.Errorf("expected thumbnail %d to be removed without a cover, got %v", size, err)
+		}
+	}
+}
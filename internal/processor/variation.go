@@ -0,0 +1,29 @@
+package processor
+
+import (
+	"github.com/disintegration/imaging"
+)
+
+const (
+	generativeVariations = 4                  // Seeds rotated through by the generative mode
+	variationSalt        = 0x9e3779b97f4a7c15 // Spreads variation indexes across the seed space
+)
+
+// blurAnchors are the background crops the blur mode rotates through
+var blurAnchors = []imaging.Anchor{imaging.Center, imaging.TopLeft, imaging.BottomRight}
+
+// Variations returns how many distinct renderings of the same track a mode offers
+func (p *BlurProcessor) Variations(mode string) int {
+	if n, ok := p.variations[mode]; ok {
+		return n
+	}
+	return 1
+}
+
+// variationIndex maps any variation number onto [0, n)
+func variationIndex(variation, n int) int {
+	if n <= 1 {
+		return 0
+	}
+	return ((variation % n) + n) % n
+}
@@ -0,0 +1,100 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"time"
+
+	"github.com/disintegration/imaging"
+	"github.com/genricoloni/synest/internal/audio"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/logctx"
+	"go.uber.org/zap"
+)
+
+const (
+	waveformBarFill       = 0.6              // Fraction of each bar slot that is painted
+	waveformDimPercent    = -35              // Background brightness adjustment
+	waveformDecodeTimeout = 10 * time.Second // Upper bound for decoding one audio file
+)
+
+// renderWaveform draws the track's waveform over a dimmed, blurred copy of the cover and
+// places the sharp cover on top. Streams and unsupported files fall back to the blur mode.
+func (p *BlurProcessor) renderWaveform(ctx context.Context, src image.Image, meta domain.MediaMetadata) (image.Image, error) {
+	if src == nil {
+		return nil, fmt.Errorf("%s mode requires artwork", domain.ModeWaveform)
+	}
+	logger := logctx.Logger(ctx, p.logger)
+
+	path, err := audio.LocalPath(meta.URL)
+	if err != nil {
+		logger.Debug("Track is not a local file, falling back to blur",
+			zap.String("url", meta.URL),
+			zap.Error(err))
+		return p.renderBlur(ctx, src, meta)
+	}
+
+	decodeCtx, cancel := context.WithTimeout(ctx, waveformDecodeTimeout)
+	defer cancel()
+
+	cfg := p.appCfg.GetModeConfig().Waveform
+	peaks, err := audio.Peaks(decodeCtx, path, cfg.Bars)
+	if err != nil {
+		logger.Warn("Failed to decode waveform, falling back to blur",
+			zap.String("path", path),
+			zap.Error(err))
+		return p.renderBlur(ctx, src, meta)
+	}
+
+	w, h := p.res.Width, p.res.Height
+	filters := p.config()
+
+	// 1. Dimmed blurred background so the waveform stays readable
+	background := blurredFill(src, w, h, imaging.Center, cfg.BlurRadius, filters.BackgroundFilter, meta.LowPower)
+	background = imaging.AdjustBrightness(background, waveformDimPercent)
+
+	// 2. Mirrored bars around the horizontal center, tinted by the dominant cover color
+	barColor := color.RGBA{R: 235, G: 235, B: 235, A: 255}
+	if palette := extractPalette(src, 1); len(palette) > 0 {
+		barColor = lighten(palette[0], 0.55)
+	}
+
+	slot := float64(w) / float64(len(peaks))
+	barWidth := max(slot*waveformBarFill, 1)
+	maxHalf := float64(h) * cfg.Height / 2
+	centerY := float64(h) / 2
+	for i, peak := range peaks {
+		half := max(peak*maxHalf, 1)
+		x0 := int(float64(i)*slot + (slot-barWidth)/2)
+		rect := image.Rect(x0, int(centerY-half), x0+int(barWidth), int(centerY+half))
+		fillRect(background, rect, barColor, 0.85)
+	}
+
+	// 3. Sharp cover on top, same geometry as the blur mode
+	coverWidth, coverHeight := p.coverSize(src.Bounds(), cfg.CoverSize)
+	cover := imaging.Resize(src, coverWidth, coverHeight, filters.CoverFilter)
+
+	return imaging.Paste(background, cover, image.Pt((w-coverWidth)/2, (h-coverHeight)/2)), nil
+}
+
+// fillRect blends a rectangle of color c into the canvas, clipped to its bounds
+func fillRect(canvas *image.NRGBA, rect image.Rectangle, c color.RGBA, alpha float64) {
+	rect = rect.Intersect(canvas.Bounds())
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			blendPixel(canvas, x, y, c, alpha)
+		}
+	}
+}
+
+// lighten moves c towards white by the given amount (0..1)
+func lighten(c color.RGBA, amount float64) color.RGBA {
+	return color.RGBA{
+		R: lerp8(c.R, 255, amount),
+		G: lerp8(c.G, 255, amount),
+		B: lerp8(c.B, 255, amount),
+		A: 255,
+	}
+}
@@ -0,0 +1,57 @@
+package processor
+
+import (
+	"context"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+func TestRenderWaveform(t *testing.T) {
+	res := &domain.ScreenResolution{Width: 320, Height: 180}
+	cover := createTestJPEG(50, 50, color.RGBA{R: 30, G: 120, B: 200, A: 255})
+
+	tests := []struct {
+		name          string
+		imageData     []byte
+		url           string
+		expectedError string
+	}{
+		{
+			name:      "Stream Falls Back To Blur",
+			imageData: cover,
+			url:       "https://stream.example.com/live.mp3",
+		},
+		{
+			name:      "Missing Local File Falls Back To Blur",
+			imageData: cover,
+			url:       "file:///nonexistent/track.wav",
+		},
+		{
+			name:          "Error - No Artwork",
+			url:           "file:///nonexistent/track.wav",
+			expectedError: "requires artwork",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := NewBlurProcessor(zap.NewNop(), res, &mockConfig{outputDir: t.TempDir()})
+			meta := domain.MediaMetadata{Title: "Song", URL: tt.url}
+
+			_, err := processor.Generate(context.Background(), tt.imageData, meta, domain.ModeWaveform)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing '%s', got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
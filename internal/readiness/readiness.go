@@ -0,0 +1,78 @@
+// Package readiness waits for external services that may come up after synest
+// at login, such as the session bus or the wallpaper setter daemon.
+package readiness
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	initialInterval = 250 * time.Millisecond
+	maxInterval     = 2 * time.Second
+)
+
+// Check is an external dependency the daemon waits for
+type Check struct {
+	Name  string
+	Ready func(ctx context.Context) error
+}
+
+// Of returns a check named after the dependency when component implements
+// domain.Readier, and no check otherwise
+func Of(name string, component any) []Check {
+	if r, ok := component.(domain.Readier); ok {
+		return []Check{{Name: name, Ready: r.Ready}}
+	}
+	return nil
+}
+
+// Wait retries every check until it succeeds, backing off between attempts.
+// It fails once timeout has elapsed, naming the dependency that is still missing.
+// A zero timeout disables waiting.
+func Wait(ctx context.Context, logger *zap.Logger, timeout time.Duration, checks ...Check) error {
+	if timeout <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for _, check := range checks {
+		if err := wait(ctx, logger, check); err != nil {
+			return fmt.Errorf("%s not ready after %s: %w", check.Name, timeout, err)
+		}
+	}
+	return nil
+}
+
+// wait retries a single check until it succeeds or ctx is done
+func wait(ctx context.Context, logger *zap.Logger, check Check) error {
+	start := time.Now()
+	interval := initialInterval
+	for attempt := 1; ; attempt++ {
+		err := check.Ready(ctx)
+		if err == nil {
+			if attempt > 1 {
+				logger.Info("Dependency ready",
+					zap.String("dependency", check.Name),
+					zap.Duration("waited", time.Since(start)))
+			}
+			return nil
+		}
+		if attempt == 1 {
+			logger.Info("Waiting for dependency", zap.String("dependency", check.Name), zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(interval):
+		}
+		interval = min(interval*2, maxInterval)
+	}
+}
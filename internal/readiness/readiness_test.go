@@ -0,0 +1,56 @@
+package readiness
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestWait(t *testing.T) {
+	errNotReady := errors.New("connection refused")
+
+	tests := []struct {
+		name          string
+		timeout       time.Duration
+		readyAfter    int // Attempts failing before the check succeeds, -1 never succeeds
+		expectedCalls int
+		expectedError string
+	}{
+		{name: "Ready Immediately", timeout: time.Second, readyAfter: 0, expectedCalls: 1},
+		{name: "Ready After Retries", timeout: 5 * time.Second, readyAfter: 2, expectedCalls: 3},
+		{name: "Never Ready", timeout: 300 * time.Millisecond, readyAfter: -1, expectedError: "session bus not ready after 300ms: connection refused"},
+		{name: "Disabled", timeout: 0, readyAfter: -1, expectedCalls: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			check := Check{Name: "session bus", Ready: func(ctx context.Context) error {
+				calls++
+				if tt.readyAfter < 0 || calls <= tt.readyAfter {
+					return errNotReady
+				}
+				return nil
+			}}
+
+			err := Wait(context.Background(), zap.NewNop(), tt.timeout, check)
+
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing '%s', got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if calls != tt.expectedCalls {
+				t.Errorf("expected %d attempts, got %d", tt.expectedCalls, calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+// Package screensaver tells when the session is locked or the screen saver is
+// active, so no wallpaper is rendered while nobody can see it.
+package screensaver
+
+// service is a screen saver interface and the path of its object
+type service struct {
+	iface string
+	path  string
+}
+
+// services are the screen saver interfaces watched. Desktops implement one or
+// both, GNOME only signals on its own.
+var services = []service{
+	{iface: "org.freedesktop.ScreenSaver", path: "/org/freedesktop/ScreenSaver"},
+	{iface: "org.gnome.ScreenSaver", path: "/org/gnome/ScreenSaver"},
+}
+
+// lockState merges the screen saver services: the session counts as locked
+// while any of them is active. It is used by a single goroutine.
+type lockState struct {
+	active map[string]bool
+	locked bool
+	notify func(locked bool) // Called when the merged state changes
+}
+
+func newLockState(notify func(locked bool)) *lockState {
+	return &lockState{active: make(map[string]bool), notify: notify}
+}
+
+// set records the state of the service iface
+func (s *lockState) set(iface string, active bool) {
+	s.active[iface] = active
+	locked := false
+	for _, a := range s.active {
+		locked = locked || a
+	}
+	if locked != s.locked {
+		s.locked = locked
+		s.notify(locked)
+	}
+}
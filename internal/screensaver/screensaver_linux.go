@@ -0,0 +1,80 @@
+//go:build linux
+// +build linux
+
+package screensaver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+	"go.uber.org/zap"
+)
+
+// Watcher follows the ActiveChanged signals of the screen saver services on
+// the session bus
+type Watcher struct {
+	logger *zap.Logger
+	conn   *dbus.Conn
+}
+
+// NewWatcher creates a screen saver watcher. Nothing is watched until Start.
+func NewWatcher(logger *zap.Logger) *Watcher {
+	return &Watcher{logger: logger}
+}
+
+// Start reads the current state of the screen saver services, then hands every
+// change of the session lock to setLocked until Stop
+func (w *Watcher) Start(ctx context.Context, setLocked func(locked bool)) error {
+	conn, err := dbus.ConnectSessionBus(dbus.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("session bus connection failed: %w", err)
+	}
+	for _, s := range services {
+		if err := conn.AddMatchSignal(dbus.WithMatchInterface(s.iface), dbus.WithMatchMember("ActiveChanged")); err != nil {
+			_ = conn.Close()
+			return fmt.Errorf("failed to subscribe to %s: %w", s.iface, err)
+		}
+	}
+	signals := make(chan *dbus.Signal, 8)
+	conn.Signal(signals)
+
+	// Services that are not running are simply never active
+	state := newLockState(setLocked)
+	for _, s := range services {
+		var active bool
+		err := conn.Object(s.iface, dbus.ObjectPath(s.path)).CallWithContext(ctx, s.iface+".GetActive", 0).Store(&active)
+		if err != nil {
+			w.logger.Debug("Screen saver service unavailable", zap.String("service", s.iface), zap.Error(err))
+			continue
+		}
+		state.set(s.iface, active)
+	}
+
+	w.conn = conn
+	go w.run(signals, state)
+	w.logger.Info("Watching the screen saver", zap.Bool("locked", state.locked))
+	return nil
+}
+
+// run applies the ActiveChanged signals until the connection is closed
+func (w *Watcher) run(signals <-chan *dbus.Signal, state *lockState) {
+	for sig := range signals {
+		for _, s := range services {
+			if sig.Name != s.iface+".ActiveChanged" || len(sig.Body) != 1 {
+				continue
+			}
+			if active, ok := sig.Body[0].(bool); ok {
+				state.set(s.iface, active)
+			}
+		}
+	}
+}
+
+// Stop stops watching the screen saver
+func (w *Watcher) Stop() error {
+	if w.conn == nil {
+		return nil
+	}
+	return w.conn.Close()
+}
@@ -0,0 +1,31 @@
+//go:build !linux
+// +build !linux
+
+package screensaver
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+)
+
+// Watcher stub for platforms without a session bus
+type Watcher struct {
+	logger *zap.Logger
+}
+
+// NewWatcher creates a stub watcher
+func NewWatcher(logger *zap.Logger) *Watcher {
+	return &Watcher{logger: logger}
+}
+
+// Start returns an error, the screen saver services are only available on Linux
+func (w *Watcher) Start(ctx context.Context, setLocked func(locked bool)) error {
+	return errors.New("screen saver detection is only supported on Linux systems")
+}
+
+// Stop does nothing
+func (w *Watcher) Stop() error {
+	return nil
+}
@@ -0,0 +1,23 @@
+package screensaver
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestLockState verifies the session stays locked until every active service
+// is inactive again, and only changes are reported
+func TestLockState(t *testing.T) {
+	var changes []bool
+	state := newLockState(func(locked bool) { changes = append(changes, locked) })
+
+	state.set("org.freedesktop.ScreenSaver", false)
+	state.set("org.gnome.ScreenSaver", true)
+	state.set("org.freedesktop.ScreenSaver", true)
+	state.set("org.gnome.ScreenSaver", false)
+	state.set("org.freedesktop.ScreenSaver", false)
+
+	if expected := []bool{true, false}; !slices.Equal(changes, expected) {
+		t.Errorf("expected changes %v, got %v", expected, changes)
+	}
+}
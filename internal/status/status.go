@@ -0,0 +1,217 @@
+// Package status records the outcome of wallpaper updates in a status file and
+// surfaces repeated failures to the user.
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/genricoloni/synest/internal/desktop"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/paths"
+	"github.com/genricoloni/synest/internal/privacy"
+	"go.uber.org/zap"
+)
+
+const (
+	// FileName is the status file written to the output directory
+	FileName = "status.json"
+
+	// notifyThreshold is the number of consecutive failures before the user is notified
+	notifyThreshold = 3
+	notifyTimeout   = 5 * time.Second
+)
+
+// Status is the persisted daemon status
+type Status struct {
+	UpdatedAt           time.Time  `json:"updated_at"`
+	LastSuccess         *time.Time `json:"last_success,omitempty"`
+	Wallpaper           string     `json:"wallpaper,omitempty"`
+	Track               *Track     `json:"track,omitempty"`
+	LastError           *Error     `json:"last_error,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	Setter              *Setter    `json:"setter,omitempty"`
+	Providers           []Provider `json:"providers,omitempty"`
+}
+
+// Setter describes the wallpaper setter in use
+type Setter struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// Provider describes the circuit breaker of an external provider
+type Provider struct {
+	Name     string     `json:"name"`
+	State    string     `json:"state"` // closed, open or half-open
+	Failures int        `json:"failures,omitempty"`
+	RetryAt  *time.Time `json:"retry_at,omitempty"`
+}
+
+// Track describes the track the wallpaper on screen was rendered from
+type Track struct {
+	Player      string `json:"player,omitempty"` // Friendly name of the player, e.g. "Spotify"
+	Title       string `json:"title,omitempty"`
+	Artist      string `json:"artist,omitempty"`
+	Album       string `json:"album,omitempty"`
+	AlbumArtist string `json:"album_artist,omitempty"`
+	Year        int    `json:"year,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Source      string `json:"source,omitempty"`
+}
+
+// Error describes the most recent pipeline failure
+type Error struct {
+	Time    time.Time `json:"time"`
+	Step    string    `json:"step"`
+	Message string    `json:"message"`
+}
+
+// Path returns the status file location for a state directory
+func Path(stateDir string) string {
+	return filepath.Join(stateDir, FileName)
+}
+
+// Load reads a status file
+func Load(path string) (*Status, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status: %w", err)
+	}
+
+	var s Status
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to decode status: %w", err)
+	}
+	return &s, nil
+}
+
+// Reporter implements domain.Reporter: it keeps the status file up to date and,
+// when enabled, sends a desktop notification once failures keep repeating
+type Reporter struct {
+	logger    *zap.Logger
+	notifier  domain.Notifier
+	providers domain.ProviderSource
+	notify    bool
+	path      string
+
+	mu     sync.Mutex
+	status Status
+}
+
+// NewReporter creates a reporter writing to the status file in the output
+// directory, with the state of the external providers
+func NewReporter(logger *zap.Logger, cfg domain.Config, notifier domain.Notifier, providers domain.ProviderSource) *Reporter {
+	return &Reporter{
+		logger:    logger,
+		notifier:  notifier,
+		providers: providers,
+		notify:    cfg.GetNotifyErrors(),
+		path:      Path(cfg.GetStateDir()),
+	}
+}
+
+// Success records a completed wallpaper update and resets the failure streak.
+// Private tracks are left out of the status file.
+func (r *Reporter) Success(ctx context.Context, wallpaperPath string, meta domain.MediaMetadata) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.status.LastSuccess = &now
+	r.status.Wallpaper = wallpaperPath
+	r.status.Track = nil
+	if !privacy.Private(ctx) {
+		r.status.Track = &Track{
+			Player:      meta.PlayerDisplay(),
+			Title:       meta.Title,
+			Artist:      meta.DisplayArtist(),
+			Album:       meta.Album,
+			AlbumArtist: meta.AlbumArtist,
+			Year:        meta.Year,
+			URL:         meta.URL,
+			Source:      meta.Source,
+		}
+	}
+	r.status.ConsecutiveFailures = 0
+	r.save()
+}
+
+// SetterSelected records the wallpaper setter chosen at startup
+func (r *Reporter) SetterSelected(name, reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.status.Setter = &Setter{Name: name, Reason: reason}
+	r.save()
+}
+
+// Failure records a failed pipeline step. The user is notified when the same
+// streak of failures reaches the threshold, not on every failure.
+func (r *Reporter) Failure(ctx context.Context, step string, err error) {
+	r.mu.Lock()
+	r.status.LastError = &Error{Time: time.Now(), Step: step, Message: err.Error()}
+	r.status.ConsecutiveFailures++
+	failures := r.status.ConsecutiveFailures
+	r.save()
+	r.mu.Unlock()
+
+	// Private runs are still recorded, but never surface on the desktop
+	if !r.notify || failures != notifyThreshold || privacy.Private(ctx) {
+		return
+	}
+
+	// Detached from the pipeline context, which may already be cancelled
+	notifyCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), notifyTimeout)
+	defer cancel()
+
+	// The player of the track is named, with its icon when its desktop entry has one
+	summary := fmt.Sprintf("Synest failed to update the wallpaper %d times", failures)
+	body := fmt.Sprintf("Step %q failed: %v", step, err)
+	var image string
+	if track, ok := domain.TrackOf(ctx); ok && track.PlayerDisplay() != "" {
+		body = fmt.Sprintf("Step %q failed while %s was playing: %v", step, track.PlayerDisplay(), err)
+		image = desktop.Icon(track.PlayerDesktopEntry)
+	}
+	if notifyErr := r.notifier.Notify(notifyCtx, summary, body, image); notifyErr != nil {
+		r.logger.Warn("Failed to send error notification", zap.Error(notifyErr))
+	}
+}
+
+// save writes the status file atomically, so readers never see a partial file.
+// Must be called with r.mu held.
+func (r *Reporter) save() {
+	r.status.UpdatedAt = time.Now()
+	r.status.Providers = nil
+	for _, p := range r.providers.Providers() {
+		provider := Provider{Name: p.Name, State: p.State, Failures: p.Failures}
+		if !p.RetryAt.IsZero() {
+			provider.RetryAt = &p.RetryAt
+		}
+		r.status.Providers = append(r.status.Providers, provider)
+	}
+
+	data, err := json.MarshalIndent(r.status, "", "  ")
+	if err != nil {
+		r.logger.Warn("Failed to encode status", zap.Error(err))
+		return
+	}
+
+	if err := paths.Ensure(filepath.Dir(r.path)); err != nil {
+		r.logger.Warn("Failed to create status directory", zap.Error(err))
+		return
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		r.logger.Warn("Failed to write status", zap.Error(err))
+		return
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		r.logger.Warn("Failed to replace status", zap.Error(err))
+	}
+}
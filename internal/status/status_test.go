@@ -0,0 +1,167 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/privacy"
+	"go.uber.org/zap"
+)
+
+func TestReporter(t *testing.T) {
+	tests := []struct {
+		name            string
+		notifyErrors    bool
+		private         bool
+		failures        int
+		success         bool
+		expectedNotices int
+		expectedStreak  int
+	}{
+		{name: "Below Threshold", notifyErrors: true, failures: 2, expectedNotices: 0, expectedStreak: 2},
+		{name: "Notifies Once At Threshold", notifyErrors: true, failures: 5, expectedNotices: 1, expectedStreak: 5},
+		{name: "Notifications Disabled", notifyErrors: false, failures: 5, expectedNotices: 0, expectedStreak: 5},
+		{name: "Private Runs Do Not Notify", notifyErrors: true, private: true, failures: 5, expectedNotices: 0, expectedStreak: 5},
+		{name: "Success Resets Streak", notifyErrors: true, failures: 2, success: true, expectedNotices: 0, expectedStreak: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			notifier := &fakeNotifier{}
+			reporter := NewReporter(zap.NewNop(), &mockConfig{stateDir: dir, notifyErrors: tt.notifyErrors}, notifier, fakeProviders{})
+
+			ctx := context.Background()
+			if tt.private {
+				ctx = privacy.WithPrivate(ctx)
+			}
+			for i := 0; i < tt.failures; i++ {
+				reporter.Failure(ctx, "set", errors.New("no wallpaper setter found"))
+			}
+			if tt.success {
+				reporter.Success(context.Background(), "/tmp/wallpaper.jpg", domain.MediaMetadata{
+					Title:  "Song",
+					URL:    "https://artist.bandcamp.com/track/song",
+					Source: "bandcamp",
+				})
+			}
+
+			if notifier.calls != tt.expectedNotices {
+				t.Errorf("expected %d notifications, got %d", tt.expectedNotices, notifier.calls)
+			}
+
+			s, err := Load(Path(dir))
+			if err != nil {
+				t.Fatalf("failed to load status: %v", err)
+			}
+			if s.ConsecutiveFailures != tt.expectedStreak {
+				t.Errorf("expected %d consecutive failures, got %d", tt.expectedStreak, s.ConsecutiveFailures)
+			}
+			if s.LastError == nil || s.LastError.Step != "set" || s.LastError.Message != "no wallpaper setter found" {
+				t.Errorf("unexpected last error: %+v", s.LastError)
+			}
+			if tt.success && (s.LastSuccess == nil || s.Wallpaper != "/tmp/wallpaper.jpg") {
+				t.Errorf("success not recorded: %+v", s)
+			}
+			if tt.success && (s.Track == nil || s.Track.Source != "bandcamp" || s.Track.URL == "") {
+				t.Errorf("track not recorded: %+v", s.Track)
+			}
+		})
+	}
+}
+
+// TestReporter_NotifiesPlayer verifies the failure notification names the
+// player of the track with the icon of its desktop entry
+func TestReporter_NotifiesPlayer(t *testing.T) {
+	data := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", data)
+	if err := os.MkdirAll(filepath.Join(data, "applications"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(data, "applications", "spotify.desktop"), []byte("[Desktop Entry]\nIcon=spotify-client\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	notifier := &fakeNotifier{}
+	reporter := NewReporter(zap.NewNop(), &mockConfig{stateDir: t.TempDir(), notifyErrors: true}, notifier, fakeProviders{})
+	ctx := domain.WithTrack(context.Background(), domain.MediaMetadata{
+		Player:             "spotify",
+		PlayerIdentity:     "Spotify",
+		PlayerDesktopEntry: "spotify",
+	})
+	for range notifyThreshold {
+		reporter.Failure(ctx, "set", errors.New("no wallpaper setter found"))
+	}
+
+	if !strings.Contains(notifier.body, "while Spotify was playing") || notifier.image != "spotify-client" {
+		t.Errorf("expected Spotify and its icon, got %q with %q", notifier.body, notifier.image)
+	}
+}
+
+// TestReporter_Providers verifies the state of the external providers is
+// written with the status
+func TestReporter_Providers(t *testing.T) {
+	dir := t.TempDir()
+	retryAt := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	providers := fakeProviders{
+		{Name: "artwork i.scdn.co", State: domain.BreakerOpen, Failures: 3, RetryAt: retryAt},
+		{Name: "spotify audio features", State: domain.BreakerClosed},
+	}
+	reporter := NewReporter(zap.NewNop(), &mockConfig{stateDir: dir}, &fakeNotifier{}, providers)
+	reporter.Failure(context.Background(), "fetch", errors.New("unexpected status code: 503"))
+
+	s, err := Load(Path(dir))
+	if err != nil {
+		t.Fatalf("failed to load status: %v", err)
+	}
+	if len(s.Providers) != 2 {
+		t.Fatalf("expected 2 providers, got %+v", s.Providers)
+	}
+	if open := s.Providers[0]; open.State != domain.BreakerOpen || open.Failures != 3 || open.RetryAt == nil || !open.RetryAt.Equal(retryAt) {
+		t.Errorf("unexpected open provider: %+v", open)
+	}
+	if closed := s.Providers[1]; closed.State != domain.BreakerClosed || closed.RetryAt != nil {
+		t.Errorf("unexpected closed provider: %+v", closed)
+	}
+}
+
+// fakeProviders is a fixed list of provider states
+type fakeProviders []domain.ProviderState
+
+func (f fakeProviders) Providers() []domain.ProviderState {
+	return f
+}
+
+type fakeNotifier struct {
+	calls int
+	body  string // Body of the last notification
+	image string // Image of the last notification
+}
+
+func (f *fakeNotifier) Notify(ctx context.Context, summary, body, image string) error {
+	f.calls++
+	f.body, f.image = body, image
+	return nil
+}
+
+// mockConfig implements the parts of domain.Config used by the reporter.
+// Other getters are promoted from the nil embedded interface and must not be called.
+type mockConfig struct {
+	domain.Config
+	stateDir     string
+	notifyErrors bool
+}
+
+func (m *mockConfig) GetStateDir() string {
+	return m.stateDir
+}
+
+func (m *mockConfig) GetNotifyErrors() bool {
+	return m.notifyErrors
+}
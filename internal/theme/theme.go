@@ -0,0 +1,181 @@
+// Package theme publishes the colors of the wallpaper on screen, so player themes
+// (e.g. Spicetify or ncspot) can follow the wallpaper instead of the other way around.
+package theme
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/paths"
+	"github.com/genricoloni/synest/internal/privacy"
+	"go.uber.org/zap"
+)
+
+const (
+	// FileName is the theme file written to the state directory
+	FileName = "theme.json"
+	// Version identifies the layout of the theme file and D-Bus properties. It only
+	// changes on incompatible changes, new fields may be added at any time.
+	Version = 1
+)
+
+// Theme describes the wallpaper on screen. Field names are a stable API.
+type Theme struct {
+	Version   int       `json:"version"`
+	Wallpaper string    `json:"wallpaper"` // Path of the wallpaper image
+	Palette   []string  `json:"palette"`   // Dominant colors as #rrggbb, most frequent first
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Applied describes a wallpaper confirmed on screen, announced with the
+// WallpaperApplied signal of the control interface
+type Applied struct {
+	Wallpaper string
+	Mode      string   // Wallpaper mode the track was rendered with
+	Palette   []string // Dominant colors as #rrggbb, most frequent first
+	Private   bool     // Track is left empty for private tracks
+	Track     domain.MediaMetadata
+	At        time.Time
+}
+
+// appliedBuffer bounds the announcements waiting for the control interface,
+// the oldest ones are dropped beyond it
+const appliedBuffer = 16
+
+// Path returns the theme file location for a state directory
+func Path(stateDir string) string {
+	return filepath.Join(stateDir, FileName)
+}
+
+// Publisher implements domain.Reporter: it forwards every outcome to the wrapped
+// reporter and publishes the theme of each wallpaper confirmed on screen
+type Publisher struct {
+	domain.Reporter
+	logger   *zap.Logger
+	palettes domain.PaletteSource
+	path     string
+	changes  chan struct{}
+	applied  chan Applied
+
+	mu    sync.Mutex
+	theme Theme
+}
+
+// NewPublisher creates a publisher writing to the theme file in the state directory
+func NewPublisher(logger *zap.Logger, cfg domain.Config, inner domain.Reporter, palettes domain.PaletteSource) *Publisher {
+	return &Publisher{
+		Reporter: inner,
+		logger:   logger,
+		palettes: palettes,
+		path:     Path(cfg.GetStateDir()),
+		changes:  make(chan struct{}, 1),
+		applied:  make(chan Applied, appliedBuffer),
+		theme:    Theme{Version: Version, Palette: []string{}},
+	}
+}
+
+// Success records the update with the wrapped reporter, then publishes the
+// palette of the new wallpaper. The theme carries no track information, so
+// private tracks are published too; their announcement leaves the track out.
+func (p *Publisher) Success(ctx context.Context, wallpaperPath string, meta domain.MediaMetadata) {
+	p.Reporter.Success(ctx, wallpaperPath, meta)
+
+	palette := p.palettes.Palette()
+	if palette == nil {
+		palette = []string{}
+	}
+
+	now := time.Now()
+	applied := Applied{
+		Wallpaper: wallpaperPath,
+		Mode:      domain.ModeOf(ctx),
+		Palette:   slices.Clone(palette),
+		Private:   privacy.Private(ctx),
+		At:        now,
+	}
+	if !applied.Private {
+		applied.Track = meta
+	}
+
+	p.mu.Lock()
+	p.theme = Theme{
+		Version:   Version,
+		Wallpaper: wallpaperPath,
+		Palette:   palette,
+		UpdatedAt: now,
+	}
+	p.save()
+	p.announce(applied)
+	p.mu.Unlock()
+
+	// A pending signal already reports the latest theme
+	select {
+	case p.changes <- struct{}{}:
+	default:
+	}
+}
+
+// announce queues an applied wallpaper, dropping the oldest announcement when
+// nobody reads them. Must be called with p.mu held.
+func (p *Publisher) announce(applied Applied) {
+	for {
+		select {
+		case p.applied <- applied:
+			return
+		default:
+		}
+		select {
+		case <-p.applied:
+			p.logger.Debug("Dropped a wallpaper announcement, nobody is listening")
+		default:
+		}
+	}
+}
+
+// Current returns a copy of the published theme. It is safe to call from any goroutine.
+func (p *Publisher) Current() Theme {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	t := p.theme
+	t.Palette = slices.Clone(t.Palette)
+	return t
+}
+
+// Changes is signaled after every new theme
+func (p *Publisher) Changes() <-chan struct{} {
+	return p.changes
+}
+
+// Applied delivers every wallpaper confirmed on screen, in order
+func (p *Publisher) Applied() <-chan Applied {
+	return p.applied
+}
+
+// save writes the theme file atomically, so readers never see a partial file.
+// Must be called with p.mu held.
+func (p *Publisher) save() {
+	data, err := json.MarshalIndent(p.theme, "", "  ")
+	if err != nil {
+		p.logger.Warn("Failed to encode theme", zap.Error(err))
+		return
+	}
+
+	if err := paths.Ensure(filepath.Dir(p.path)); err != nil {
+		p.logger.Warn("Failed to create theme directory", zap.Error(err))
+		return
+	}
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		p.logger.Warn("Failed to write theme", zap.Error(err))
+		return
+	}
+	if err := os.Rename(tmp, p.path); err != nil {
+		p.logger.Warn("Failed to replace theme", zap.Error(err))
+	}
+}
@@ -0,0 +1,121 @@
+package theme
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/privacy"
+	"go.uber.org/zap"
+)
+
+func TestPublisher(t *testing.T) {
+	dir := t.TempDir()
+	inner := &fakeReporter{}
+	palettes := &fakePalettes{palette: []string{"#102030", "#405060"}}
+	publisher := NewPublisher(zap.NewNop(), &mockConfig{stateDir: dir}, inner, palettes)
+
+	if current := publisher.Current(); current.Version != Version || current.Palette == nil {
+		t.Errorf("expected an empty theme before the first wallpaper, got %+v", current)
+	}
+
+	// Private tracks are published too, the theme carries no track information
+	ctx := privacy.WithPrivate(context.Background())
+	publisher.Success(ctx, "/tmp/wallpaper.jpg", domain.MediaMetadata{Title: "Song"})
+
+	if inner.successes != 1 {
+		t.Errorf("expected the success to reach the wrapped reporter, got %d", inner.successes)
+	}
+	select {
+	case <-publisher.Changes():
+	default:
+		t.Error("expected a change signal")
+	}
+
+	data, err := os.ReadFile(Path(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved Theme
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.Version != Version || saved.Wallpaper != "/tmp/wallpaper.jpg" || !slices.Equal(saved.Palette, palettes.palette) {
+		t.Errorf("unexpected theme file: %+v", saved)
+	}
+
+	// The announcement leaves the private track out
+	select {
+	case applied := <-publisher.Applied():
+		if !applied.Private || applied.Track.Title != "" || applied.Wallpaper != "/tmp/wallpaper.jpg" {
+			t.Errorf("expected a private announcement without the track, got %+v", applied)
+		}
+	default:
+		t.Error("expected an announcement")
+	}
+
+	// Callers get a copy they may modify
+	publisher.Current().Palette[0] = "#000000"
+	if publisher.Current().Palette[0] != "#102030" {
+		t.Error("expected Current to return a copy of the palette")
+	}
+}
+
+// TestPublisher_Applied verifies every wallpaper is announced in order with its
+// mode and track, and that unread announcements do not pile up
+func TestPublisher_Applied(t *testing.T) {
+	palettes := &fakePalettes{palette: []string{"#102030"}}
+	publisher := NewPublisher(zap.NewNop(), &mockConfig{stateDir: t.TempDir()}, &fakeReporter{}, palettes)
+
+	ctx := domain.WithMode(context.Background(), domain.ModeWaveform)
+	for i := range appliedBuffer + 2 {
+		publisher.Success(ctx, "/tmp/wallpaper.jpg", domain.MediaMetadata{Title: fmt.Sprintf("Song %d", i), Artist: "Artist"})
+	}
+
+	first := <-publisher.Applied()
+	if first.Track.Title != "Song 2" || first.Track.Artist != "Artist" || first.Mode != domain.ModeWaveform || first.Private {
+		t.Errorf("expected the oldest kept announcement for Song 2 in waveform mode, got %+v", first)
+	}
+	if !slices.Equal(first.Palette, palettes.palette) {
+		t.Errorf("expected the palette %v, got %v", palettes.palette, first.Palette)
+	}
+	if pending := len(publisher.Applied()); pending != appliedBuffer-1 {
+		t.Errorf("expected %d more announcements, got %d", appliedBuffer-1, pending)
+	}
+}
+
+// fakeReporter counts the outcomes forwarded by the publisher
+type fakeReporter struct {
+	successes int
+}
+
+func (f *fakeReporter) Success(ctx context.Context, wallpaperPath string, meta domain.MediaMetadata) {
+	f.successes++
+}
+
+func (f *fakeReporter) Failure(ctx context.Context, step string, err error) {}
+
+func (f *fakeReporter) SetterSelected(name, reason string) {}
+
+// fakePalettes returns a fixed palette
+type fakePalettes struct {
+	palette []string
+}
+
+func (f *fakePalettes) Palette() []string {
+	return f.palette
+}
+
+// mockConfig implements the parts of domain.Config used by the publisher
+type mockConfig struct {
+	domain.Config
+	stateDir string
+}
+
+func (m *mockConfig) GetStateDir() string {
+	return m.stateDir
+}
@@ -0,0 +1,261 @@
+// Package upload mirrors each wallpaper put on screen, and the track it was
+// rendered from, to S3-compatible storage or a WebDAV collection, for remote
+// dashboards and phones following the desktop.
+package upload
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/genricoloni/synest/internal/breaker"
+	"github.com/genricoloni/synest/internal/domain"
+	"github.com/genricoloni/synest/internal/latest"
+	"github.com/genricoloni/synest/internal/privacy"
+	"go.uber.org/zap"
+)
+
+const (
+	// WallpaperName is the uploaded wallpaper, next to the base URL
+	WallpaperName = "wallpaper.jpg"
+	// NowPlayingName describes the uploaded wallpaper and its track
+	NowPlayingName = "now-playing.json"
+	// Version identifies the layout of the now-playing file. It only changes on
+	// incompatible changes, new fields may be added at any time.
+	Version = 1
+
+	uploadTimeout = 30 * time.Second // Per upload, slow links give up on it
+	maxMkcolDepth = 8                // Missing WebDAV collections created above the base URL
+)
+
+// NowPlaying is the uploaded track description. Field names are a stable API.
+type NowPlaying struct {
+	Version   int       `json:"version"`
+	Title     string    `json:"title"`
+	Artist    string    `json:"artist"`
+	Album     string    `json:"album,omitempty"`
+	Player    string    `json:"player,omitempty"`
+	Mode      string    `json:"mode"`                // Wallpaper mode the track was rendered with
+	Wallpaper string    `json:"wallpaper,omitempty"` // Name of the uploaded wallpaper, absent when it was not uploaded
+	Palette   []string  `json:"palette"`             // Dominant colors as #rrggbb, most frequent first
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// job is an update waiting to be uploaded
+type job struct {
+	settings   domain.Upload
+	wallpaper  []byte // Nil when only the track is uploaded
+	nowPlaying []byte
+}
+
+// Uploader implements domain.Reporter: it forwards every outcome to the wrapped
+// reporter and uploads each wallpaper confirmed on screen in the background.
+// Only the latest update waits for a slow upload, older ones are skipped.
+type Uploader struct {
+	domain.Reporter
+	logger   *zap.Logger
+	cfg      domain.Config
+	palettes domain.PaletteSource
+	metered  domain.MeteredSource
+	breakers *breaker.Set
+	client   *http.Client
+	worker   *latest.Worker[job]
+}
+
+// NewUploader creates an uploader following the upload settings of cfg, with a
+// breaker per storage host
+func NewUploader(logger *zap.Logger, cfg domain.Config, inner domain.Reporter, palettes domain.PaletteSource, metered domain.MeteredSource, breakers *breaker.Set) *Uploader {
+	u := &Uploader{
+		Reporter: inner,
+		logger:   logger,
+		cfg:      cfg,
+		palettes: palettes,
+		metered:  metered,
+		breakers: breakers,
+		client:   &http.Client{Timeout: uploadTimeout},
+	}
+	u.worker = latest.New(logger, "upload", u.run)
+	return u
+}
+
+// Success records the update with the wrapped reporter, then queues its upload.
+// Private tracks are not uploaded. Scenes written instead of a wallpaper, and
+// wallpapers on metered connections, leave the uploaded wallpaper as it was.
+func (u *Uploader) Success(ctx context.Context, wallpaperPath string, meta domain.MediaMetadata) {
+	u.Reporter.Success(ctx, wallpaperPath, meta)
+
+	settings := u.cfg.GetUpload()
+	if settings.Kind == domain.UploadOff || privacy.Private(ctx) {
+		return
+	}
+
+	var wallpaper []byte
+	if u.cfg.GetScene() != domain.SceneOnly && !u.metered.Metered(ctx) {
+		// Read now, the next update overwrites the file
+		data, err := os.ReadFile(wallpaperPath)
+		if err != nil {
+			u.logger.Warn("Failed to read the wallpaper to upload", zap.Error(err))
+		}
+		wallpaper = data
+	}
+
+	palette := slices.Clone(u.palettes.Palette())
+	if palette == nil {
+		palette = []string{}
+	}
+	np := NowPlaying{
+		Version:   Version,
+		Title:     meta.Title,
+		Artist:    meta.DisplayArtist(),
+		Album:     meta.Album,
+		Player:    meta.Player,
+		Mode:      domain.ModeOf(ctx),
+		Palette:   palette,
+		UpdatedAt: time.Now(),
+	}
+	if wallpaper != nil {
+		np.Wallpaper = WallpaperName
+	}
+	data, err := json.MarshalIndent(np, "", "  ")
+	if err != nil {
+		u.logger.Warn("Failed to encode the track to upload", zap.Error(err))
+		return
+	}
+	u.worker.Queue(job{settings: settings, wallpaper: wallpaper, nowPlaying: data})
+}
+
+// run uploads a queued update, in the worker
+func (u *Uploader) run(ctx context.Context, j job) {
+	if err := u.upload(ctx, j); err != nil && ctx.Err() == nil {
+		u.logger.Warn("Failed to upload the wallpaper",
+			zap.String("upload", j.settings.Kind),
+			zap.Error(err))
+	}
+}
+
+// upload puts the wallpaper, then the track describing it
+func (u *Uploader) upload(ctx context.Context, j job) error {
+	base, err := url.Parse(j.settings.URL)
+	if err != nil {
+		return err
+	}
+	b := u.breakers.Get("upload " + base.Host)
+	if j.wallpaper != nil {
+		if err := b.Call(ctx, func() error {
+			return u.put(ctx, j.settings, base, WallpaperName, "image/jpeg", j.wallpaper)
+		}); err != nil {
+			return fmt.Errorf("%s: %w", WallpaperName, err)
+		}
+	}
+	if err := b.Call(ctx, func() error {
+		return u.put(ctx, j.settings, base, NowPlayingName, "application/json", j.nowPlaying)
+	}); err != nil {
+		return fmt.Errorf("%s: %w", NowPlayingName, err)
+	}
+	u.logger.Debug("Uploaded the wallpaper",
+		zap.String("upload", j.settings.Kind),
+		zap.Bool("image", j.wallpaper != nil))
+	return nil
+}
+
+// put uploads data as name under base. WebDAV collections missing above it are
+// created once, then the upload is retried.
+func (u *Uploader) put(ctx context.Context, settings domain.Upload, base *url.URL, name, contentType string, data []byte) error {
+	target := child(base, name)
+	status, err := u.do(ctx, settings, http.MethodPut, target, contentType, data)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusConflict && settings.Kind == domain.UploadWebDAV {
+		if err := u.mkcol(ctx, settings, base, maxMkcolDepth); err != nil {
+			return err
+		}
+		if status, err = u.do(ctx, settings, http.MethodPut, target, contentType, data); err != nil {
+			return err
+		}
+	}
+	if status < 200 || status > 299 {
+		return fmt.Errorf("PUT %s: %d %s", target.Redacted(), status, http.StatusText(status))
+	}
+	return nil
+}
+
+// mkcol creates the WebDAV collection dir, and its missing parents up to depth
+func (u *Uploader) mkcol(ctx context.Context, settings domain.Upload, dir *url.URL, depth int) error {
+	status, err := u.do(ctx, settings, "MKCOL", dir, "", nil)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusConflict && depth > 1 {
+		parent := *dir
+		parent.Path = path.Dir(strings.TrimSuffix(dir.Path, "/"))
+		parent.RawPath = ""
+		if parent.Path != dir.Path && parent.Path != "/" {
+			if err := u.mkcol(ctx, settings, &parent, depth-1); err != nil {
+				return err
+			}
+			status, err = u.do(ctx, settings, "MKCOL", dir, "", nil)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	// Method Not Allowed answers collections that already exist
+	if status != http.StatusCreated && status != http.StatusMethodNotAllowed {
+		return fmt.Errorf("MKCOL %s: %d %s", dir.Redacted(), status, http.StatusText(status))
+	}
+	return nil
+}
+
+// do sends an authenticated request and returns the response status
+func (u *Uploader) do(ctx context.Context, settings domain.Upload, method string, target *url.URL, contentType string, body []byte) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, uploadTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "synestDaemon/1.0")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	switch settings.Kind {
+	case domain.UploadS3:
+		signV4(req, body, settings, time.Now())
+	case domain.UploadWebDAV:
+		if settings.User != "" {
+			req.SetBasicAuth(settings.User, settings.Secret)
+		}
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	return resp.StatusCode, nil
+}
+
+// child returns the URL of name inside the collection or prefix base
+func child(base *url.URL, name string) *url.URL {
+	u := *base
+	u.Path = strings.TrimSuffix(base.Path, "/") + "/" + name
+	u.RawPath = ""
+	return &u
+}
+
+// Stop ends the upload in flight and discards the queued one
+func (u *Uploader) Stop(ctx context.Context) error {
+	return u.worker.Stop(ctx)
+}